@@ -7,6 +7,7 @@ type Options struct {
 	Caplet        *string
 	AutoStart     *string
 	Debug         *bool
+	NoRecover     *bool
 	Silent        *bool
 	NoColors      *bool
 	NoHistory     *bool
@@ -22,6 +23,7 @@ func ParseOptions() (Options, error) {
 		AutoStart:     flag.String("autostart", "events.stream, net.recon", "Comma separated list of modules to auto start."),
 		Caplet:        flag.String("caplet", "", "Read commands from this file and execute them in the interactive session."),
 		Debug:         flag.Bool("debug", false, "Print debug messages."),
+		NoRecover:     flag.Bool("no-recover", false, "Do not recover from modules panics, let them crash the process (for debugging)."),
 		Silent:        flag.Bool("silent", false, "Suppress all logs which are not errors."),
 		NoColors:      flag.Bool("no-colors", false, "Disable output color effects."),
 		NoHistory:     flag.Bool("no-history", false, "Disable interactive session history file."),
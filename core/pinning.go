@@ -0,0 +1,84 @@
+package core
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ParsePins parses a comma separated list of SHA256 hashes of certificates
+// SubjectPublicKeyInfo, either hex or base64 encoded (optionally prefixed
+// with "sha256/" as in HPKP headers).
+func ParsePins(list string) (error, [][]byte) {
+	pins := make([][]byte, 0)
+	for _, pin := range CommaSplit(list) {
+		pin = strings.TrimPrefix(pin, "sha256/")
+
+		raw, err := hex.DecodeString(pin)
+		if err != nil {
+			if raw, err = base64.StdEncoding.DecodeString(pin); err != nil {
+				return fmt.Errorf("invalid pin '%s': not hex or base64", pin), nil
+			}
+		}
+
+		if len(raw) != sha256.Size {
+			return fmt.Errorf("invalid pin '%s': not a SHA256 hash", pin), nil
+		}
+		pins = append(pins, raw)
+	}
+	return nil, pins
+}
+
+// SPKIHash returns the SHA256 of the certificate SubjectPublicKeyInfo.
+func SPKIHash(cert *x509.Certificate) []byte {
+	hash := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return hash[:]
+}
+
+// VerifyPins returns a tls.Config.VerifyPeerCertificate callback that fails
+// unless at least one of the certificates of the verified chains matches one
+// of the pins, the standard verification still takes place before it.
+func VerifyPins(pins [][]byte) func([][]byte, [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+		for _, chain := range verifiedChains {
+			for _, cert := range chain {
+				hash := SPKIHash(cert)
+				for _, pin := range pins {
+					if bytes.Equal(hash, pin) {
+						return nil
+					}
+				}
+			}
+		}
+
+		subject := "unknown"
+		if len(verifiedChains) > 0 && len(verifiedChains[0]) > 0 {
+			subject = verifiedChains[0][0].Subject.CommonName
+		}
+		return fmt.Errorf("certificate pinning failed for %s: none of the presented keys match the configured pins", subject)
+	}
+}
+
+// PinnedClient returns an http client that only accepts TLS connections to
+// servers with a certificate matching one of the pins, if no pins are given
+// the default client is returned.
+func PinnedClient(pins [][]byte) *http.Client {
+	if len(pins) == 0 {
+		return http.DefaultClient
+	}
+
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{
+				VerifyPeerCertificate: VerifyPins(pins),
+			},
+		},
+	}
+}
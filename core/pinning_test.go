@@ -0,0 +1,73 @@
+package core
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func testCertificate(t *testing.T) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "pinning.test"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	raw, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestParsePins(t *testing.T) {
+	hash := make([]byte, 32)
+	hash[0] = 0xaa
+
+	list := hex.EncodeToString(hash) + ", sha256/" + base64.StdEncoding.EncodeToString(hash)
+	if err, pins := ParsePins(list); err != nil {
+		t.Fatal(err)
+	} else if len(pins) != 2 {
+		t.Fatalf("expected 2 pins, got %d", len(pins))
+	}
+
+	if err, pins := ParsePins(""); err != nil || len(pins) != 0 {
+		t.Fatalf("expected no pins, got %v (%v)", pins, err)
+	}
+
+	if err, _ := ParsePins("deadbeef"); err == nil {
+		t.Fatal("expected an error for a short pin")
+	}
+}
+
+func TestVerifyPins(t *testing.T) {
+	cert := testCertificate(t)
+	chains := [][]*x509.Certificate{{cert}}
+
+	if err := VerifyPins([][]byte{SPKIHash(cert)})(nil, chains); err != nil {
+		t.Fatalf("expected matching pin to pass, got %s", err)
+	}
+
+	other := testCertificate(t)
+	if err := VerifyPins([][]byte{SPKIHash(other)})(nil, chains); err == nil {
+		t.Fatal("expected mismatching pin to fail")
+	}
+}
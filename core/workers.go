@@ -0,0 +1,88 @@
+package core
+
+import (
+	"sync"
+)
+
+type Job func()
+
+// WorkerPool runs background jobs with a cap on how many of them can run
+// at the same time, jobs exceeding it are queued; a cap <= 0 means that
+// every job gets its own goroutine right away.
+type WorkerPool struct {
+	sync.Mutex
+	max     int
+	running int
+	queue   []Job
+}
+
+// Workers is the pool shared by every background job of the session
+// (reverse DNS lookups, hostname resolution, ...).
+var Workers = NewWorkerPool(0)
+
+func NewWorkerPool(max int) *WorkerPool {
+	return &WorkerPool{
+		max:   max,
+		queue: make([]Job, 0),
+	}
+}
+
+func (p *WorkerPool) canRun() bool {
+	return p.max <= 0 || p.running < p.max
+}
+
+func (p *WorkerPool) Submit(job Job) {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.canRun() {
+		p.running++
+		go p.worker(job)
+	} else {
+		p.queue = append(p.queue, job)
+	}
+}
+
+func (p *WorkerPool) worker(job Job) {
+	for job != nil {
+		job()
+
+		p.Lock()
+		// this worker is still counted as running, so make room for it
+		p.running--
+		if len(p.queue) > 0 && p.canRun() {
+			job = p.queue[0]
+			p.queue = p.queue[1:]
+			p.running++
+		} else {
+			job = nil
+		}
+		p.Unlock()
+	}
+}
+
+func (p *WorkerPool) SetMax(max int) {
+	p.Lock()
+	defer p.Unlock()
+
+	p.max = max
+	// if the cap has been raised, start the jobs that can now run
+	for len(p.queue) > 0 && p.canRun() {
+		job := p.queue[0]
+		p.queue = p.queue[1:]
+		p.running++
+		go p.worker(job)
+	}
+}
+
+func (p *WorkerPool) Max() int {
+	p.Lock()
+	defer p.Unlock()
+	return p.max
+}
+
+func (p *WorkerPool) Stats() (running int, queued int) {
+	p.Lock()
+	defer p.Unlock()
+	return p.running, len(p.queue)
+}
@@ -0,0 +1,69 @@
+package core
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWorkerPoolCap(t *testing.T) {
+	pool := NewWorkerPool(2)
+	wg := sync.WaitGroup{}
+	lock := sync.Mutex{}
+	running := 0
+	peak := 0
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		pool.Submit(func() {
+			defer wg.Done()
+
+			lock.Lock()
+			running++
+			if running > peak {
+				peak = running
+			}
+			lock.Unlock()
+
+			time.Sleep(10 * time.Millisecond)
+
+			lock.Lock()
+			running--
+			lock.Unlock()
+		})
+	}
+
+	wg.Wait()
+
+	if peak > 2 {
+		t.Fatalf("expected at most 2 concurrent jobs, got %d", peak)
+	} else if r, q := pool.Stats(); r != 0 || q != 0 {
+		t.Fatalf("expected an idle pool, got %d running and %d queued", r, q)
+	}
+}
+
+func TestWorkerPoolSetMax(t *testing.T) {
+	pool := NewWorkerPool(1)
+	block := make(chan bool)
+	wg := sync.WaitGroup{}
+
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		pool.Submit(func() {
+			defer wg.Done()
+			<-block
+		})
+	}
+
+	if r, q := pool.Stats(); r != 1 || q != 2 {
+		t.Fatalf("expected 1 running and 2 queued, got %d and %d", r, q)
+	}
+
+	pool.SetMax(0)
+	if r, q := pool.Stats(); r != 3 || q != 0 {
+		t.Fatalf("expected 3 running and 0 queued, got %d and %d", r, q)
+	}
+
+	close(block)
+	wg.Wait()
+}
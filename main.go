@@ -33,19 +33,25 @@ func main() {
 	fmt.Printf("%s (type '%s' for a list of commands)\n\n", core.Bold(appName), core.Bold("help"))
 
 	sess.Register(modules.NewEventsStream(sess))
+	sess.Register(modules.NewMQTTEvents(sess))
 	sess.Register(modules.NewTicker(sess))
+	sess.Register(modules.NewKnockModule(sess))
 	sess.Register(modules.NewUpdateModule(sess))
 	sess.Register(modules.NewCapletsModule(sess))
 	sess.Register(modules.NewMacChanger(sess))
 	sess.Register(modules.NewProber(sess))
 	sess.Register(modules.NewDiscovery(sess))
+	sess.Register(modules.NewMonitor(sess))
 	sess.Register(modules.NewArpSpoofer(sess))
 	sess.Register(modules.NewDHCP6Spoofer(sess))
 	sess.Register(modules.NewDNSSpoofer(sess))
+	sess.Register(modules.NewNameSpoofer(sess))
+	sess.Register(modules.NewDNSLogger(sess))
 	sess.Register(modules.NewSniffer(sess))
 	sess.Register(modules.NewPacketProxy(sess))
 	sess.Register(modules.NewAnyProxy(sess))
 	sess.Register(modules.NewTcpProxy(sess))
+	sess.Register(modules.NewSocksProxy(sess))
 	sess.Register(modules.NewHttpProxy(sess))
 	sess.Register(modules.NewHttpsProxy(sess))
 	sess.Register(modules.NewHttpServer(sess))
@@ -53,9 +59,11 @@ func main() {
 	sess.Register(modules.NewWOL(sess))
 	sess.Register(modules.NewWiFiModule(sess))
 	sess.Register(modules.NewBLERecon(sess))
+	sess.Register(modules.NewCorrelator(sess))
 	sess.Register(modules.NewSynScanner(sess))
 	sess.Register(modules.NewGPS(sess))
 	sess.Register(modules.NewMySQLServer(sess))
+	sess.Register(modules.NewPacketReplay(sess))
 
 	if err = sess.Start(); err != nil {
 		log.Fatal("%s", err)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/bettercap/bettercap/core"
@@ -17,15 +18,24 @@ import (
 
 type RestAPI struct {
 	session.SessionModule
-	server       *http.Server
-	username     string
-	password     string
-	certFile     string
-	keyFile      string
-	allowOrigin  string
-	useWebsocket bool
-	upgrader     websocket.Upgrader
-	quit         chan bool
+	server        *http.Server
+	username      string
+	password      string
+	certFile      string
+	keyFile       string
+	allowOrigin   string
+	useWebsocket  bool
+	wsBuffer      int
+	wsClients     int32
+	wsDropped     uint32
+	methods       []methodRule
+	filesDir      string
+	metrics       *apiMetrics
+	slowThreshold time.Duration
+	specAuth      bool
+	access        *apiAccessLog
+	upgrader      websocket.Upgrader
+	quit          chan bool
 }
 
 func NewRestAPI(s *session.Session) *RestAPI {
@@ -35,6 +45,7 @@ func NewRestAPI(s *session.Session) *RestAPI {
 		quit:          make(chan bool),
 		useWebsocket:  false,
 		allowOrigin:   "*",
+		access:        &apiAccessLog{},
 		upgrader: websocket.Upgrader{
 			ReadBufferSize:  1024,
 			WriteBufferSize: 1024,
@@ -81,6 +92,42 @@ func NewRestAPI(s *session.Session) *RestAPI {
 		"false",
 		"If true the /api/events route will be available as a websocket endpoint instead of HTTPS."))
 
+	api.AddParam(session.NewIntParameter("api.rest.websocket.buffer",
+		"1024",
+		"Maximum number of events waiting to be sent to each websocket client, a client falling further behind is disconnected."))
+
+	api.AddParam(session.NewStringParameter("api.rest.methods",
+		"",
+		"",
+		"Comma separated list of ROUTE:METHOD|METHOD rules restricting the HTTP methods allowed on each route, a route ending with * matches every path with that prefix and the most specific rule wins (example: /api/session:GET|POST,/api/session/*:GET), empty to allow every method."))
+
+	api.AddParam(session.NewStringParameter("api.rest.files.dir",
+		"",
+		"",
+		"If not empty, the files in this folder (captures, logs, etc) can be listed and downloaded from /api/session/files."))
+
+	api.AddParam(session.NewIntParameter("api.rest.slowlog.threshold",
+		"1000",
+		"Requests taking longer than this number of milliseconds will be logged, 0 to disable."))
+
+	api.AddParam(session.NewBoolParameter("api.rest.spec.auth",
+		"true",
+		"If false, the OpenAPI description of the API at /api/spec and /api/openapi.json can be fetched without authentication."))
+
+	api.AddParam(session.NewStringParameter("api.rest.access.log",
+		"",
+		"",
+		"If not empty, every API request will be logged to this file."))
+
+	api.AddParam(session.NewStringParameter("api.rest.access.format",
+		defaultAccessFormat,
+		"",
+		"Format of the api.rest.access.log lines, available fields are {time}, {event}, {remote}, {user}, {method}, {path}, {status} and {latency}."))
+
+	api.AddParam(session.NewBoolParameter("api.rest.access.events",
+		"false",
+		"If true, every API request will also be emitted as an api.rest.request event."))
+
 	api.AddHandler(session.NewModuleHandler("api.rest on", "",
 		"Start REST API server.",
 		func(args []string) error {
@@ -124,6 +171,8 @@ func (api *RestAPI) Configure() error {
 	var err error
 	var ip string
 	var port int
+	var methods string
+	var slowThreshold int
 
 	if api.Running() {
 		return session.ErrAlreadyStarted
@@ -147,6 +196,35 @@ func (api *RestAPI) Configure() error {
 		return err
 	} else if err, api.useWebsocket = api.BoolParam("api.rest.websocket"); err != nil {
 		return err
+	} else if err, api.wsBuffer = api.IntParam("api.rest.websocket.buffer"); err != nil {
+		return err
+	} else if api.wsBuffer < 1 {
+		return fmt.Errorf("api.rest.websocket.buffer must be greater than 0")
+	} else if err, methods = api.StringParam("api.rest.methods"); err != nil {
+		return err
+	} else if err, api.methods = parseMethodRules(methods); err != nil {
+		return err
+	} else if err, api.filesDir = api.StringParam("api.rest.files.dir"); err != nil {
+		return err
+	} else if api.filesDir, err = core.ExpandPath(api.filesDir); err != nil {
+		return err
+	} else if err, slowThreshold = api.IntParam("api.rest.slowlog.threshold"); err != nil {
+		return err
+	} else if err, api.specAuth = api.BoolParam("api.rest.spec.auth"); err != nil {
+		return err
+	} else if err = api.openAccessLog(); err != nil {
+		return err
+	}
+
+	api.slowThreshold = time.Duration(slowThreshold) * time.Millisecond
+	api.metrics = newAPIMetrics()
+
+	if api.filesDir != "" {
+		if info, err := os.Stat(api.filesDir); err != nil {
+			return err
+		} else if !info.IsDir() {
+			return fmt.Errorf("api.rest.files.dir %s is not a folder", api.filesDir)
+		}
 	}
 
 	if api.isTLS() {
@@ -172,22 +250,13 @@ func (api *RestAPI) Configure() error {
 
 	router := mux.NewRouter()
 
-	router.HandleFunc("/api/events", api.eventsRoute)
-	router.HandleFunc("/api/session", api.sessionRoute)
-	router.HandleFunc("/api/session/ble", api.sessionRoute)
-	router.HandleFunc("/api/session/ble/{mac}", api.sessionRoute)
-	router.HandleFunc("/api/session/env", api.sessionRoute)
-	router.HandleFunc("/api/session/gateway", api.sessionRoute)
-	router.HandleFunc("/api/session/interface", api.sessionRoute)
-	router.HandleFunc("/api/session/lan", api.sessionRoute)
-	router.HandleFunc("/api/session/lan/{mac}", api.sessionRoute)
-	router.HandleFunc("/api/session/options", api.sessionRoute)
-	router.HandleFunc("/api/session/packets", api.sessionRoute)
-	router.HandleFunc("/api/session/started-at", api.sessionRoute)
-	router.HandleFunc("/api/session/wifi", api.sessionRoute)
-	router.HandleFunc("/api/session/wifi/{mac}", api.sessionRoute)
-
-	api.server.Handler = router
+	router.Use(api.metricsMiddleware)
+
+	for _, route := range api.routes() {
+		router.HandleFunc(route.Path, route.Handler)
+	}
+
+	api.server.Handler = api.accessLogger(api.methodsFilter(router))
 
 	if api.username == "" || api.password == "" {
 		log.Warning("api.rest.username and/or api.rest.password parameters are empty, authentication is disabled.")
@@ -229,5 +298,6 @@ func (api *RestAPI) Stop() error {
 		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
 		defer cancel()
 		api.server.Shutdown(ctx)
+		api.access.Close()
 	})
 }
@@ -0,0 +1,220 @@
+package modules
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bettercap/bettercap/core"
+
+	"github.com/gorilla/websocket"
+)
+
+const defaultAccessFormat = "{time} {remote} {user} {method} {path} {status} {latency}"
+
+// query parameters whose values are not logged
+var accessSecretParams = []string{"pass", "pwd", "secret", "token", "key", "auth", "session"}
+
+// APIRequest is the payload of api.rest.request events, Event is "request"
+// for plain requests and "connect" or "disconnect" for websockets.
+type APIRequest struct {
+	Event   string        `json:"event"`
+	Time    time.Time     `json:"time"`
+	Method  string        `json:"method"`
+	Path    string        `json:"path"`
+	Remote  string        `json:"remote"`
+	User    string        `json:"user"`
+	Status  int           `json:"status"`
+	Latency time.Duration `json:"latency"`
+}
+
+// Format replaces the {field} placeholders of format with the values of
+// the request.
+func (r APIRequest) Format(format string) string {
+	return strings.NewReplacer(
+		"{event}", r.Event,
+		"{time}", r.Time.Format(time.RFC3339),
+		"{method}", r.Method,
+		"{path}", r.Path,
+		"{remote}", r.Remote,
+		"{user}", r.User,
+		"{status}", fmt.Sprintf("%d", r.Status),
+		"{latency}", r.Latency.String(),
+	).Replace(format)
+}
+
+// apiAccessLog writes the access log lines, it's safe to use from every
+// request handler.
+type apiAccessLog struct {
+	sync.Mutex
+	format string
+	events bool
+	file   *os.File
+}
+
+// Open closes the previous log file, if any, and starts logging to
+// fileName, or just sends the events if it's empty.
+func (l *apiAccessLog) Open(fileName string, format string, events bool) (err error) {
+	l.Lock()
+	defer l.Unlock()
+
+	l.close()
+	l.format = format
+	l.events = events
+	if fileName != "" {
+		l.file, err = os.OpenFile(fileName, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	}
+	return
+}
+
+// Enabled returns true if requests are either logged or sent as events.
+func (l *apiAccessLog) Enabled() bool {
+	l.Lock()
+	defer l.Unlock()
+	return l.file != nil || l.events
+}
+
+// Write logs the request to the file, if any, and returns true if it must
+// also be sent as an event.
+func (l *apiAccessLog) Write(req APIRequest) bool {
+	l.Lock()
+	defer l.Unlock()
+	if l.file != nil {
+		fmt.Fprintln(l.file, req.Format(l.format))
+	}
+	return l.events
+}
+
+func (l *apiAccessLog) Close() {
+	l.Lock()
+	defer l.Unlock()
+	l.close()
+}
+
+func (l *apiAccessLog) close() {
+	if l.file != nil {
+		l.file.Close()
+		l.file = nil
+	}
+	l.events = false
+}
+
+// statusRecorder keeps track of the status code sent by the handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// redactQuery returns the path and the query of u with the values of the
+// parameters named like secrets replaced.
+func redactQuery(u *url.URL) string {
+	if u.RawQuery == "" {
+		return u.Path
+	}
+
+	query := u.Query()
+	for name := range query {
+		lower := strings.ToLower(name)
+		for _, secret := range accessSecretParams {
+			if strings.Contains(lower, secret) {
+				query[name] = []string{"REDACTED"}
+				break
+			}
+		}
+	}
+	return u.Path + "?" + query.Encode()
+}
+
+func (api *RestAPI) openAccessLog() error {
+	var err error
+	var fileName string
+	var format string
+	var events bool
+
+	api.access.Close()
+	if err, fileName = api.StringParam("api.rest.access.log"); err != nil {
+		return err
+	} else if err, format = api.StringParam("api.rest.access.format"); err != nil {
+		return err
+	} else if err, events = api.BoolParam("api.rest.access.events"); err != nil {
+		return err
+	} else if fileName != "" {
+		if fileName, err = core.ExpandPath(fileName); err != nil {
+			return err
+		}
+	}
+
+	return api.access.Open(fileName, format, events)
+}
+
+// requestUser returns the name of the authenticated user, if any.
+func (api *RestAPI) requestUser(r *http.Request) string {
+	if api.username == "" || api.password == "" || !api.checkAuth(r) {
+		return "-"
+	}
+	return api.username
+}
+
+func (api *RestAPI) logAccess(req APIRequest) {
+	if api.access.Write(req) {
+		api.Session.Events.Add("api.rest.request", req)
+	}
+}
+
+// accessLogger wraps the server handler and logs every request, including
+// the ones rejected before reaching the router.
+func (api *RestAPI) accessLogger(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !api.access.Enabled() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		remote := r.RemoteAddr
+		if host, _, err := net.SplitHostPort(remote); err == nil {
+			remote = host
+		}
+
+		req := APIRequest{
+			Event:  "request",
+			Time:   time.Now(),
+			Method: r.Method,
+			Path:   redactQuery(r.URL),
+			Remote: remote,
+			User:   api.requestUser(r),
+		}
+
+		if websocket.IsWebSocketUpgrade(r) {
+			// the connection is hijacked, so only log when it's opened and
+			// when it's closed
+			req.Event = "connect"
+			req.Status = http.StatusSwitchingProtocols
+			api.logAccess(req)
+
+			next.ServeHTTP(w, r)
+
+			req.Event = "disconnect"
+			req.Latency = time.Since(req.Time)
+			req.Time = time.Now()
+			api.logAccess(req)
+			return
+		}
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		req.Status = rec.status
+		req.Latency = time.Since(req.Time)
+		api.logAccess(req)
+	})
+}
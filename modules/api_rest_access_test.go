@@ -0,0 +1,86 @@
+package modules
+
+import (
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRedactQuery(t *testing.T) {
+	cases := []struct {
+		raw      string
+		expected string
+	}{
+		{"/api/session", "/api/session"},
+		{"/api/events?n=10", "/api/events?n=10"},
+		{"/api/session?token=abc&n=1", "/api/session?n=1&token=REDACTED"},
+		{"/api/session?Api_Key=abc", "/api/session?Api_Key=REDACTED"},
+		{"/api/session?password=a&password=b", "/api/session?password=REDACTED"},
+	}
+
+	for _, c := range cases {
+		u, err := url.Parse(c.raw)
+		if err != nil {
+			t.Fatal(err)
+		} else if got := redactQuery(u); got != c.expected {
+			t.Fatalf("%s: expected '%s', got '%s'", c.raw, c.expected, got)
+		}
+	}
+}
+
+func TestAPIRequestFormat(t *testing.T) {
+	req := APIRequest{
+		Event:   "request",
+		Time:    time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC),
+		Method:  "GET",
+		Path:    "/api/session",
+		Remote:  "127.0.0.1",
+		User:    "user",
+		Status:  200,
+		Latency: time.Millisecond,
+	}
+
+	expected := "2018-01-02T03:04:05Z 127.0.0.1 user GET /api/session 200 1ms"
+	if got := req.Format(defaultAccessFormat); got != expected {
+		t.Fatalf("expected '%s', got '%s'", expected, got)
+	}
+}
+
+func TestAPIAccessLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bettercap-access")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	l := &apiAccessLog{}
+	if l.Enabled() {
+		t.Fatal("expected a new access log to be disabled")
+	}
+
+	fileName := filepath.Join(dir, "access.log")
+	if err := l.Open(fileName, "{method} {path}", true); err != nil {
+		t.Fatal(err)
+	} else if !l.Enabled() {
+		t.Fatal("expected the access log to be enabled")
+	} else if !l.Write(APIRequest{Method: "GET", Path: "/api/session"}) {
+		t.Fatal("expected the request to be sent as an event")
+	}
+
+	l.Close()
+	if l.Enabled() {
+		t.Fatal("expected a closed access log to be disabled")
+	} else if l.Write(APIRequest{Method: "GET", Path: "/api/events"}) {
+		t.Fatal("expected a closed access log not to send events")
+	}
+
+	if data, err := ioutil.ReadFile(fileName); err != nil {
+		t.Fatal(err)
+	} else if lines := strings.TrimSpace(string(data)); lines != "GET /api/session" {
+		t.Fatalf("unexpected log contents '%s'", lines)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"sync/atomic"
 
 	"github.com/bettercap/bettercap/log"
 	"github.com/bettercap/bettercap/session"
@@ -67,9 +68,9 @@ func (api *RestAPI) showBle(w http.ResponseWriter, r *http.Request) {
 	mac := strings.ToLower(params["mac"])
 
 	if mac == "" {
-		toJSON(w, session.I.BLE)
+		toProjectedJSON(w, r, session.I.BLE, "devices")
 	} else if dev, found := session.I.BLE.Get(mac); found {
-		toJSON(w, dev)
+		toProjectedJSON(w, r, dev, "")
 	} else {
 		http.Error(w, "Not Found", 404)
 	}
@@ -87,14 +88,18 @@ func (api *RestAPI) showInterface(w http.ResponseWriter, r *http.Request) {
 	toJSON(w, session.I.Interface)
 }
 
+func (api *RestAPI) showInterfaceStats(w http.ResponseWriter, r *http.Request) {
+	toJSON(w, session.I.IfaceStats)
+}
+
 func (api *RestAPI) showLan(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	mac := strings.ToLower(params["mac"])
 
 	if mac == "" {
-		toJSON(w, session.I.Lan)
+		toProjectedJSON(w, r, session.I.Lan, "hosts")
 	} else if host, found := session.I.Lan.Get(mac); found {
-		toJSON(w, host)
+		toProjectedJSON(w, r, host, "")
 	} else {
 		http.Error(w, "Not Found", 404)
 	}
@@ -108,6 +113,41 @@ func (api *RestAPI) showPackets(w http.ResponseWriter, r *http.Request) {
 	toJSON(w, session.I.Queue)
 }
 
+type APIEventStats struct {
+	session.EventStats
+	WebsocketClients int32  `json:"ws_clients"`
+	WebsocketDropped uint32 `json:"ws_dropped"`
+}
+
+func (api *RestAPI) showEventStats(w http.ResponseWriter, r *http.Request) {
+	toJSON(w, APIEventStats{
+		EventStats:       session.I.Events.Stats(),
+		WebsocketClients: atomic.LoadInt32(&api.wsClients),
+		WebsocketDropped: atomic.LoadUint32(&api.wsDropped),
+	})
+}
+
+func (api *RestAPI) showSniffTop(w http.ResponseWriter, r *http.Request) {
+	err, mod := session.I.Module("net.sniff")
+	if err != nil {
+		http.Error(w, "Not Found", 404)
+		return
+	}
+
+	sniff, ok := mod.(*Sniffer)
+	if !ok || sniff.Top == nil {
+		http.Error(w, "Not Found", 404)
+		return
+	}
+
+	limit := 0
+	if n, err := strconv.Atoi(r.URL.Query().Get("n")); err == nil && n > 0 {
+		limit = n
+	}
+
+	toJSON(w, sniff.Top.Report(r.URL.Query().Get("by") == "bytes", limit))
+}
+
 func (api *RestAPI) showStartedAt(w http.ResponseWriter, r *http.Request) {
 	toJSON(w, session.I.StartedAt)
 }
@@ -117,16 +157,48 @@ func (api *RestAPI) showWiFi(w http.ResponseWriter, r *http.Request) {
 	mac := strings.ToLower(params["mac"])
 
 	if mac == "" {
-		toJSON(w, session.I.WiFi)
+		toProjectedJSON(w, r, session.I.WiFi, "aps")
 	} else if station, found := session.I.WiFi.Get(mac); found {
-		toJSON(w, station)
+		toProjectedJSON(w, r, station, "")
 	} else if client, found := session.I.WiFi.GetClient(mac); found {
-		toJSON(w, client)
+		toProjectedJSON(w, r, client, "")
 	} else {
 		http.Error(w, "Not Found", 404)
 	}
 }
 
+func (api *RestAPI) showWiFiChannels(w http.ResponseWriter, r *http.Request) {
+	err, mod := session.I.Module("wifi")
+	if err != nil {
+		http.Error(w, "Not Found", 404)
+		return
+	}
+
+	wifi, ok := mod.(*WiFiModule)
+	if !ok {
+		http.Error(w, "Not Found", 404)
+		return
+	}
+
+	toJSON(w, wifi.Channels.Report(session.I.WiFi.List()))
+}
+
+func (api *RestAPI) showWiFiDeauthTargets(w http.ResponseWriter, r *http.Request) {
+	err, mod := session.I.Module("wifi")
+	if err != nil {
+		http.Error(w, "Not Found", 404)
+		return
+	}
+
+	wifi, ok := mod.(*WiFiModule)
+	if !ok {
+		http.Error(w, "Not Found", 404)
+		return
+	}
+
+	toJSON(w, wifi.DeauthTargets.List())
+}
+
 func (api *RestAPI) runSessionCommand(w http.ResponseWriter, r *http.Request) {
 	var err error
 	var cmd CommandRequest
@@ -199,12 +271,18 @@ func (api *RestAPI) sessionRoute(w http.ResponseWriter, r *http.Request) {
 	case path == "/api/session/env":
 		api.showEnv(w, r)
 
+	case path == "/api/session/events":
+		api.showEventStats(w, r)
+
 	case path == "/api/session/gateway":
 		api.showGateway(w, r)
 
 	case path == "/api/session/interface":
 		api.showInterface(w, r)
 
+	case path == "/api/session/interface/stats":
+		api.showInterfaceStats(w, r)
+
 	case strings.HasPrefix(path, "/api/session/lan"):
 		api.showLan(w, r)
 
@@ -214,12 +292,21 @@ func (api *RestAPI) sessionRoute(w http.ResponseWriter, r *http.Request) {
 	case path == "/api/session/packets":
 		api.showPackets(w, r)
 
+	case strings.HasPrefix(path, "/api/session/sniff/top"):
+		api.showSniffTop(w, r)
+
 	case path == "/api/session/started-at":
 		api.showStartedAt(w, r)
 
 	case strings.HasPrefix(path, "/api/session/ble"):
 		api.showBle(w, r)
 
+	case strings.HasPrefix(path, "/api/session/wifi/channels"):
+		api.showWiFiChannels(w, r)
+
+	case strings.HasPrefix(path, "/api/session/wifi/deauth"):
+		api.showWiFiDeauthTargets(w, r)
+
 	case strings.HasPrefix(path, "/api/session/wifi"):
 		api.showWiFi(w, r)
 
@@ -0,0 +1,109 @@
+package modules
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/bettercap/bettercap/log"
+)
+
+// requestedFields returns the list of fields selected with the
+// ?fields=a,b,c query parameter, or nil if no selection was made.
+func requestedFields(r *http.Request) []string {
+	fields := make([]string, 0)
+	for _, value := range r.URL.Query()["fields"] {
+		for _, field := range strings.Split(value, ",") {
+			if field = strings.TrimSpace(field); field != "" {
+				fields = append(fields, field)
+			}
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+	return fields
+}
+
+func projectObject(obj map[string]interface{}, fields []string, seen map[string]bool) map[string]interface{} {
+	projected := make(map[string]interface{})
+	for _, field := range fields {
+		if value, found := obj[field]; found {
+			projected[field] = value
+			seen[field] = true
+		}
+	}
+	return projected
+}
+
+// projectFields encodes o and keeps only the selected fields of each object
+// of the listKey array, or of the object itself if it has no such array;
+// it also returns the selected fields that none of the objects had.
+func projectFields(o interface{}, listKey string, fields []string) (error, interface{}, []string) {
+	raw, err := json.Marshal(o)
+	if err != nil {
+		return err, nil, nil
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return err, nil, nil
+	}
+
+	seen := make(map[string]bool)
+	checked := false
+	var projected interface{}
+
+	if list, ok := doc[listKey].([]interface{}); ok {
+		for i, item := range list {
+			if obj, ok := item.(map[string]interface{}); ok {
+				list[i] = projectObject(obj, fields, seen)
+				checked = true
+			}
+		}
+		doc[listKey] = list
+		projected = doc
+	} else {
+		projected = projectObject(doc, fields, seen)
+		checked = true
+	}
+
+	unknown := make([]string, 0)
+	// with an empty list there's nothing to tell known and unknown fields apart
+	if checked {
+		for _, field := range fields {
+			if !seen[field] {
+				unknown = append(unknown, field)
+			}
+		}
+		sort.Strings(unknown)
+	}
+
+	return nil, projected, unknown
+}
+
+// toProjectedJSON works like toJSON but honors the ?fields= selection of
+// the request, unknown fields are reported with a Warning header.
+func toProjectedJSON(w http.ResponseWriter, r *http.Request, o interface{}, listKey string) {
+	fields := requestedFields(r)
+	if fields == nil {
+		toJSON(w, o)
+		return
+	}
+
+	err, projected, unknown := projectFields(o, listKey, fields)
+	if err != nil {
+		log.Error("error while projecting object fields: %v", err)
+		http.Error(w, "Internal Server Error", 500)
+		return
+	}
+
+	if len(unknown) > 0 {
+		w.Header().Set("Warning", fmt.Sprintf(`299 - "unknown fields: %s"`, strings.Join(unknown, ",")))
+	}
+
+	toJSON(w, projected)
+}
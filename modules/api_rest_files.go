@@ -0,0 +1,140 @@
+package modules
+
+import (
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bettercap/bettercap/log"
+
+	"github.com/gorilla/mux"
+)
+
+// content types of the files bettercap itself produces
+var captureContentTypes = map[string]string{
+	".pcap":   "application/vnd.tcpdump.pcap",
+	".pcapng": "application/x-pcapng",
+	".cap":    "application/vnd.tcpdump.pcap",
+	".hccapx": "application/octet-stream",
+	".22000":  "text/plain; charset=utf-8",
+	".json":   "application/json",
+	".csv":    "text/csv; charset=utf-8",
+	".log":    "text/plain; charset=utf-8",
+}
+
+type APIFile struct {
+	Name     string    `json:"name"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+}
+
+func fileContentType(name string) string {
+	ext := strings.ToLower(filepath.Ext(name))
+	if cType, found := captureContentTypes[ext]; found {
+		return cType
+	} else if cType := mime.TypeByExtension(ext); cType != "" {
+		return cType
+	}
+	return "application/octet-stream"
+}
+
+// filePath resolves name inside api.rest.files.dir, making sure it doesn't
+// point anywhere else (including through symlinks).
+func (api *RestAPI) filePath(name string) (error, string) {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, "/\\") || filepath.Base(name) != name {
+		return fmt.Errorf("invalid file name"), ""
+	}
+
+	root, err := filepath.EvalSymlinks(api.filesDir)
+	if err != nil {
+		return err, ""
+	}
+
+	path, err := filepath.EvalSymlinks(filepath.Join(root, name))
+	if err != nil {
+		return err, ""
+	} else if filepath.Dir(path) != root {
+		return fmt.Errorf("%s is outside of %s", name, api.filesDir), ""
+	}
+
+	return nil, path
+}
+
+func (api *RestAPI) listFiles(w http.ResponseWriter, r *http.Request) {
+	entries, err := ioutil.ReadDir(api.filesDir)
+	if err != nil {
+		log.Error("error reading %s: %s", api.filesDir, err)
+		http.Error(w, "Internal Server Error", 500)
+		return
+	}
+
+	files := make([]APIFile, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Mode().IsRegular() {
+			files = append(files, APIFile{
+				Name:     entry.Name(),
+				Size:     entry.Size(),
+				Modified: entry.ModTime(),
+			})
+		}
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Modified.After(files[j].Modified)
+	})
+
+	toJSON(w, files)
+}
+
+func (api *RestAPI) downloadFile(w http.ResponseWriter, r *http.Request, name string) {
+	err, path := api.filePath(name)
+	if err != nil {
+		log.Debug("refusing to serve '%s': %s", name, err)
+		http.Error(w, "Not Found", 404)
+		return
+	}
+
+	fp, err := os.Open(path)
+	if err != nil {
+		http.Error(w, "Not Found", 404)
+		return
+	}
+	defer fp.Close()
+
+	info, err := fp.Stat()
+	if err != nil || !info.Mode().IsRegular() {
+		http.Error(w, "Not Found", 404)
+		return
+	}
+
+	w.Header().Set("Content-Type", fileContentType(name))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	http.ServeContent(w, r, name, info.ModTime(), fp)
+}
+
+func (api *RestAPI) filesRoute(w http.ResponseWriter, r *http.Request) {
+	api.setSecurityHeaders(w)
+
+	if !api.checkAuth(r) {
+		setAuthFailed(w, r)
+		return
+	} else if r.Method != "GET" {
+		http.Error(w, "Bad Request", 400)
+		return
+	} else if api.filesDir == "" {
+		http.Error(w, "Not Found", 404)
+		return
+	}
+
+	if name := mux.Vars(r)["name"]; name == "" {
+		api.listFiles(w, r)
+	} else {
+		api.downloadFile(w, r, name)
+	}
+}
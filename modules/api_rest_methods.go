@@ -0,0 +1,108 @@
+package modules
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// methodRule restricts the HTTP methods allowed on a route, if prefix
+// is set every path starting with route is matched, otherwise only
+// the exact path.
+type methodRule struct {
+	route   string
+	prefix  bool
+	methods map[string]bool
+	allow   string
+}
+
+func (rule methodRule) matches(path string) bool {
+	if rule.prefix {
+		return strings.HasPrefix(path, rule.route)
+	}
+	return path == rule.route
+}
+
+// parseMethodRules parses a comma separated list of ROUTE:METHOD|METHOD
+// rules, a route ending with * matches every path with that prefix.
+func parseMethodRules(spec string) (error, []methodRule) {
+	rules := make([]methodRule, 0)
+	for _, part := range strings.Split(spec, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+
+		idx := strings.LastIndex(part, ":")
+		if idx <= 0 || idx == len(part)-1 {
+			return fmt.Errorf("invalid method rule '%s', expected ROUTE:METHOD|METHOD", part), nil
+		}
+
+		rule := methodRule{
+			route:   strings.TrimSpace(part[:idx]),
+			methods: make(map[string]bool),
+		}
+
+		if strings.HasSuffix(rule.route, "*") {
+			rule.prefix = true
+			rule.route = strings.TrimSuffix(rule.route, "*")
+		}
+
+		allowed := make([]string, 0)
+		for _, method := range strings.Split(part[idx+1:], "|") {
+			if method = strings.ToUpper(strings.TrimSpace(method)); method == "" {
+				continue
+			} else if !rule.methods[method] {
+				rule.methods[method] = true
+				allowed = append(allowed, method)
+			}
+		}
+
+		if len(allowed) == 0 {
+			return fmt.Errorf("method rule '%s' does not allow any method", part), nil
+		}
+		rule.allow = strings.Join(allowed, ", ")
+
+		rules = append(rules, rule)
+	}
+
+	// exact routes first, then the longest prefixes
+	sort.SliceStable(rules, func(i, j int) bool {
+		if rules[i].prefix != rules[j].prefix {
+			return !rules[i].prefix
+		}
+		return len(rules[i].route) > len(rules[j].route)
+	})
+
+	return nil, rules
+}
+
+// methodRule returns the most specific rule matching path, if any.
+func (api *RestAPI) methodRule(path string) (methodRule, bool) {
+	for _, rule := range api.methods {
+		if rule.matches(path) {
+			return rule, true
+		}
+	}
+	return methodRule{}, false
+}
+
+func (api *RestAPI) methodAllowed(path string, method string) bool {
+	rule, found := api.methodRule(path)
+	return !found || rule.methods[method]
+}
+
+// methodsFilter wraps the router and rejects requests whose method is
+// not allowed by the most specific rule matching their path, paths not
+// matched by any rule are not restricted.
+func (api *RestAPI) methodsFilter(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rule, found := api.methodRule(r.URL.Path); found && !rule.methods[r.Method] {
+			api.setSecurityHeaders(w)
+			w.Header().Set("Allow", rule.allow)
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
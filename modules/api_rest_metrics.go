@@ -0,0 +1,163 @@
+package modules
+
+import (
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bettercap/bettercap/log"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+)
+
+// number of most recent samples per route used to compute the 95th percentile
+const apiMetricsSamples = 1024
+
+type APIRouteMetrics struct {
+	Route    string  `json:"route"`
+	Requests uint64  `json:"requests"`
+	Min      float64 `json:"min_ms"`
+	Avg      float64 `json:"avg_ms"`
+	Max      float64 `json:"max_ms"`
+	P95      float64 `json:"p95_ms"`
+}
+
+type routeLatency struct {
+	requests uint64
+	total    time.Duration
+	min      time.Duration
+	max      time.Duration
+	samples  []time.Duration
+	next     int
+}
+
+func (l *routeLatency) Track(d time.Duration) {
+	if l.requests == 0 || d < l.min {
+		l.min = d
+	}
+	if d > l.max {
+		l.max = d
+	}
+	l.requests++
+	l.total += d
+
+	if len(l.samples) < apiMetricsSamples {
+		l.samples = append(l.samples, d)
+	} else {
+		l.samples[l.next] = d
+		l.next = (l.next + 1) % apiMetricsSamples
+	}
+}
+
+func (l *routeLatency) p95() time.Duration {
+	if len(l.samples) == 0 {
+		return 0
+	}
+
+	sorted := make([]time.Duration, len(l.samples))
+	copy(sorted, l.samples)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+
+	idx := int(math.Ceil(float64(len(sorted))*0.95)) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	return sorted[idx]
+}
+
+func toMillis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+type apiMetrics struct {
+	sync.Mutex
+	routes map[string]*routeLatency
+}
+
+func newAPIMetrics() *apiMetrics {
+	return &apiMetrics{
+		routes: make(map[string]*routeLatency),
+	}
+}
+
+func (m *apiMetrics) Track(route string, d time.Duration) {
+	m.Lock()
+	defer m.Unlock()
+
+	l, found := m.routes[route]
+	if !found {
+		l = &routeLatency{}
+		m.routes[route] = l
+	}
+	l.Track(d)
+}
+
+func (m *apiMetrics) Report() []APIRouteMetrics {
+	m.Lock()
+	defer m.Unlock()
+
+	report := make([]APIRouteMetrics, 0, len(m.routes))
+	for route, l := range m.routes {
+		report = append(report, APIRouteMetrics{
+			Route:    route,
+			Requests: l.requests,
+			Min:      toMillis(l.min),
+			Avg:      toMillis(l.total / time.Duration(l.requests)),
+			Max:      toMillis(l.max),
+			P95:      toMillis(l.p95()),
+		})
+	}
+
+	sort.Slice(report, func(i, j int) bool {
+		return report[i].Route < report[j].Route
+	})
+
+	return report
+}
+
+// metricsMiddleware times every request handled by the router, the long
+// lived websocket connections are not considered.
+func (api *RestAPI) metricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if websocket.IsWebSocketUpgrade(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		route := r.URL.Path
+		if current := mux.CurrentRoute(r); current != nil {
+			if tpl, err := current.GetPathTemplate(); err == nil {
+				route = tpl
+			}
+		}
+
+		started := time.Now()
+		next.ServeHTTP(w, r)
+		took := time.Since(started)
+
+		api.metrics.Track(route, took)
+
+		if api.slowThreshold > 0 && took >= api.slowThreshold {
+			log.Warning("slow api request: %s %s from %s took %s", r.Method, r.URL.Path, r.RemoteAddr, took)
+		}
+	})
+}
+
+func (api *RestAPI) metricsRoute(w http.ResponseWriter, r *http.Request) {
+	api.setSecurityHeaders(w)
+
+	if !api.checkAuth(r) {
+		setAuthFailed(w, r)
+		return
+	} else if r.Method != "GET" {
+		http.Error(w, "Bad Request", 400)
+		return
+	}
+
+	toJSON(w, api.metrics.Report())
+}
@@ -0,0 +1,130 @@
+package modules
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/bettercap/bettercap/core"
+	"github.com/bettercap/bettercap/network"
+	"github.com/bettercap/bettercap/packets"
+	"github.com/bettercap/bettercap/session"
+)
+
+// apiParam is a query parameter of an API operation, path parameters are
+// taken from the route template.
+type apiParam struct {
+	Name        string
+	Type        string
+	Description string
+}
+
+// apiOperation describes what a method of a route does, Body and Response
+// are zero values of the request and response JSON documents, a nil Response
+// means the operation answers with no body, a non empty ContentType that it
+// doesn't answer with JSON.
+type apiOperation struct {
+	Method      string
+	Summary     string
+	Query       []apiParam
+	Body        interface{}
+	Response    interface{}
+	ContentType string
+}
+
+type apiRoute struct {
+	Path       string
+	Handler    http.HandlerFunc
+	Operations []apiOperation
+}
+
+var apiFieldsParam = apiParam{
+	Name:        "fields",
+	Type:        "string",
+	Description: "Comma separated list of fields to return, dot separated for nested ones.",
+}
+
+// routes is the list of the routes of the API server, both the router and
+// the /api/spec document are built from it.
+func (api *RestAPI) routes() []apiRoute {
+	return []apiRoute{
+		{"/api/events", api.eventsRoute, []apiOperation{
+			{Method: "GET", Summary: "Get the most recent events, or stream them if api.rest.websocket is true.", Query: []apiParam{
+				{"n", "integer", "Maximum number of events to return."},
+			}, Response: []session.Event{}},
+			{Method: "DELETE", Summary: "Clear the events buffer."},
+		}},
+		{"/api/metrics", api.metricsRoute, []apiOperation{
+			{Method: "GET", Summary: "Get the number of requests and the latency of each route.", Response: []APIRouteMetrics{}},
+		}},
+		{"/api/session", api.sessionRoute, []apiOperation{
+			{Method: "GET", Summary: "Get the whole session.", Response: &session.Session{}},
+			{Method: "POST", Summary: "Run a command in the session.", Body: CommandRequest{}, Response: APIResponse{}},
+		}},
+		{"/api/session/ble", api.sessionRoute, []apiOperation{
+			{Method: "GET", Summary: "Get the discovered BLE devices.", Query: []apiParam{apiFieldsParam}, Response: &network.BLE{}},
+		}},
+		{"/api/session/ble/{mac}", api.sessionRoute, []apiOperation{
+			{Method: "GET", Summary: "Get a discovered BLE device.", Query: []apiParam{apiFieldsParam}, Response: &network.BLEDevice{}},
+		}},
+		{"/api/session/env", api.sessionRoute, []apiOperation{
+			{Method: "GET", Summary: "Get the session variables.", Response: &session.Environment{}},
+		}},
+		{"/api/session/files", api.filesRoute, []apiOperation{
+			{Method: "GET", Summary: "List the files in api.rest.files.dir, newest first.", Response: []APIFile{}},
+		}},
+		{"/api/session/files/{name}", api.filesRoute, []apiOperation{
+			{Method: "GET", Summary: "Download a file from api.rest.files.dir.", ContentType: "application/octet-stream"},
+		}},
+		{"/api/session/events", api.sessionRoute, []apiOperation{
+			{Method: "GET", Summary: "Get the events buffer statistics.", Response: APIEventStats{}},
+		}},
+		{"/api/session/gateway", api.sessionRoute, []apiOperation{
+			{Method: "GET", Summary: "Get the gateway.", Response: &network.Endpoint{}},
+		}},
+		{"/api/session/interface", api.sessionRoute, []apiOperation{
+			{Method: "GET", Summary: "Get the network interface.", Response: &network.Endpoint{}},
+		}},
+		{"/api/session/interface/stats", api.sessionRoute, []apiOperation{
+			{Method: "GET", Summary: "Get the traffic statistics of the network interface.", Response: &session.IfaceStats{}},
+		}},
+		{"/api/session/lan", api.sessionRoute, []apiOperation{
+			{Method: "GET", Summary: "Get the discovered hosts.", Query: []apiParam{apiFieldsParam}, Response: &network.LAN{}},
+		}},
+		{"/api/session/lan/{mac}", api.sessionRoute, []apiOperation{
+			{Method: "GET", Summary: "Get a discovered host.", Query: []apiParam{apiFieldsParam}, Response: &network.Endpoint{}},
+		}},
+		{"/api/session/options", api.sessionRoute, []apiOperation{
+			{Method: "GET", Summary: "Get the command line options.", Response: core.Options{}},
+		}},
+		{"/api/session/packets", api.sessionRoute, []apiOperation{
+			{Method: "GET", Summary: "Get the packets and traffic statistics.", Response: &packets.Queue{}},
+		}},
+		{"/api/session/sniff/top", api.sessionRoute, []apiOperation{
+			{Method: "GET", Summary: "Get the top talkers, protocols and ports seen by net.sniff.", Query: []apiParam{
+				{"n", "integer", "Maximum number of entries of each list."},
+				{"by", "string", "Sort by bytes instead of packets if set to bytes."},
+			}, Response: TopReport{}},
+		}},
+		{"/api/session/started-at", api.sessionRoute, []apiOperation{
+			{Method: "GET", Summary: "Get the time the session started.", Response: time.Time{}},
+		}},
+		{"/api/session/wifi", api.sessionRoute, []apiOperation{
+			{Method: "GET", Summary: "Get the discovered access points.", Query: []apiParam{apiFieldsParam}, Response: &network.WiFi{}},
+		}},
+		{"/api/session/wifi/channels", api.sessionRoute, []apiOperation{
+			{Method: "GET", Summary: "Get the usage of each WiFi channel.", Response: []ChannelUsage{}},
+		}},
+		{"/api/session/wifi/deauth", api.sessionRoute, []apiOperation{
+			{Method: "GET", Summary: "Get the clients currently targeted by the deauth attacks.", Response: []WiFiDeauthTarget{}},
+		}},
+		{"/api/session/wifi/{mac}", api.sessionRoute, []apiOperation{
+			{Method: "GET", Summary: "Get a discovered access point, or the client station with this address.", Query: []apiParam{apiFieldsParam}, Response: &network.AccessPoint{}},
+		}},
+		{"/api/spec", api.specRoute, []apiOperation{
+			{Method: "GET", Summary: "Get the OpenAPI description of this API.", Response: map[string]interface{}{}},
+		}},
+		{"/api/openapi.json", api.specRoute, []apiOperation{
+			{Method: "GET", Summary: "Same as /api/spec.", Response: map[string]interface{}{}},
+		}},
+	}
+}
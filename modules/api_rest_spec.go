@@ -0,0 +1,319 @@
+package modules
+
+import (
+	"encoding"
+	"encoding/json"
+	"net/http"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/bettercap/bettercap/core"
+	"github.com/bettercap/bettercap/network"
+	"github.com/bettercap/bettercap/session"
+)
+
+var (
+	reRouteParam = regexp.MustCompile(`{([^}]+)}`)
+	reNonWord    = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+
+	timeType          = reflect.TypeOf(time.Time{})
+	durationType      = reflect.TypeOf(time.Duration(0))
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// the documents the types with a custom MarshalJSON are encoded as, these
+// must be kept in sync with their implementations
+var apiSpecDocuments = map[reflect.Type]interface{}{
+	reflect.TypeOf(network.LAN{}): struct {
+		Hosts []*network.Endpoint `json:"hosts"`
+	}{},
+	reflect.TypeOf(network.WiFi{}): struct {
+		AccessPoints []*network.AccessPoint `json:"aps"`
+	}{},
+	reflect.TypeOf(network.AccessPoint{}): struct {
+		*network.Station
+		Clients []*network.Station `json:"clients"`
+	}{},
+	reflect.TypeOf(network.BLE{}): struct {
+		Devices []*network.BLEDevice `json:"devices"`
+	}{},
+	reflect.TypeOf(network.BLEDevice{}): struct {
+		FirstSeen time.Time     `json:"first_seen"`
+		LastSeen  time.Time     `json:"last_seen"`
+		Name      string        `json:"name"`
+		MAC       string        `json:"mac"`
+		Vendor    string        `json:"vendor"`
+		RSSI      int           `json:"rssi"`
+		Meta      *network.Meta `json:"meta"`
+	}{},
+	reflect.TypeOf(network.WiFiRates{}): struct {
+		Dominant string            `json:"dominant"`
+		Frames   map[string]uint64 `json:"frames"`
+	}{},
+	reflect.TypeOf(network.Meta{}): struct {
+		Values map[string]interface{} `json:"values"`
+	}{},
+	reflect.TypeOf(network.MulticastGroups{}): []string{},
+	reflect.TypeOf(session.ModuleList{}):      []session.JSONModule{},
+	reflect.TypeOf(session.IfaceStats{}): struct {
+		Interface string                `json:"interface"`
+		Source    string                `json:"source"`
+		Samples   []session.IfaceSample `json:"samples"`
+	}{},
+}
+
+// apiSpec builds the JSON schemas of the API documents, every named struct
+// is only described once in the components of the spec.
+type apiSpec struct {
+	schemas map[string]interface{}
+}
+
+func schemaName(t reflect.Type) string {
+	return path.Base(t.PkgPath()) + "." + t.Name()
+}
+
+func (s *apiSpec) component(t reflect.Type, doc reflect.Type) map[string]interface{} {
+	name := schemaName(t)
+	if _, found := s.schemas[name]; !found {
+		// mark it first, the type might be recursive
+		s.schemas[name] = nil
+		if doc.Kind() == reflect.Struct {
+			s.schemas[name] = s.structSchema(doc)
+		} else {
+			s.schemas[name] = s.schemaOf(doc)
+		}
+	}
+	return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+}
+
+func (s *apiSpec) structSchema(t reflect.Type) map[string]interface{} {
+	props := make(map[string]interface{})
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := strings.Split(field.Tag.Get("json"), ",")
+		name := tag[0]
+		if name == "-" {
+			continue
+		}
+
+		ftype := field.Type
+		if ftype.Kind() == reflect.Ptr {
+			ftype = ftype.Elem()
+		}
+
+		if field.Anonymous && name == "" && ftype.Kind() == reflect.Struct {
+			// the fields of embedded structs are promoted
+			if embedded, ok := s.structSchema(ftype)["properties"].(map[string]interface{}); ok {
+				for k, v := range embedded {
+					props[k] = v
+				}
+			}
+			continue
+		} else if field.PkgPath != "" || ftype.Kind() == reflect.Chan || ftype.Kind() == reflect.Func {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		if len(tag) > 1 && tag[1] == "string" {
+			props[name] = map[string]interface{}{"type": "string"}
+		} else {
+			props[name] = s.schemaOf(field.Type)
+		}
+	}
+
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": props,
+	}
+}
+
+func (s *apiSpec) schemaOf(t reflect.Type) map[string]interface{} {
+	if t.Kind() == reflect.Ptr {
+		return s.schemaOf(t.Elem())
+	} else if doc, found := apiSpecDocuments[t]; found {
+		return s.component(t, reflect.TypeOf(doc))
+	} else if t == timeType {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	} else if t == durationType {
+		return map[string]interface{}{"type": "integer", "format": "int64", "description": "nanoseconds"}
+	} else if t.Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(jsonMarshalerType) {
+		return map[string]interface{}{}
+	} else if t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType) {
+		return map[string]interface{}{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+		return map[string]interface{}{"type": "array", "items": s.schemaOf(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": s.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.structSchema(t)
+		}
+		return s.component(t, t)
+	}
+
+	// interfaces can be anything
+	return map[string]interface{}{}
+}
+
+// operationID turns GET /api/session/lan/{mac} into get_api_session_lan_mac.
+func operationID(method string, route string) string {
+	return strings.ToLower(method) + strings.TrimRight(reNonWord.ReplaceAllString(route, "_"), "_")
+}
+
+func (s *apiSpec) operation(route apiRoute, op apiOperation, secured bool) map[string]interface{} {
+	params := make([]interface{}, 0)
+	for _, m := range reRouteParam.FindAllStringSubmatch(route.Path, -1) {
+		params = append(params, map[string]interface{}{
+			"name":     m[1],
+			"in":       "path",
+			"required": true,
+			"schema":   map[string]interface{}{"type": "string"},
+		})
+	}
+	for _, p := range op.Query {
+		params = append(params, map[string]interface{}{
+			"name":        p.Name,
+			"in":          "query",
+			"description": p.Description,
+			"schema":      map[string]interface{}{"type": p.Type},
+		})
+	}
+
+	ok := map[string]interface{}{"description": "OK"}
+	if op.ContentType != "" {
+		ok["content"] = map[string]interface{}{
+			op.ContentType: map[string]interface{}{
+				"schema": map[string]interface{}{"type": "string", "format": "binary"},
+			},
+		}
+	} else if op.Response != nil {
+		ok["content"] = map[string]interface{}{
+			"application/json": map[string]interface{}{
+				"schema": s.schemaOf(reflect.TypeOf(op.Response)),
+			},
+		}
+	}
+
+	responses := map[string]interface{}{"200": ok}
+	if secured {
+		responses["401"] = map[string]interface{}{"description": "Unauthorized"}
+	}
+	if len(params) > 0 {
+		responses["404"] = map[string]interface{}{"description": "Not Found"}
+	}
+
+	doc := map[string]interface{}{
+		"summary":     op.Summary,
+		"operationId": operationID(op.Method, route.Path),
+		"parameters":  params,
+		"responses":   responses,
+	}
+
+	if op.Body != nil {
+		doc["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{
+					"schema": s.schemaOf(reflect.TypeOf(op.Body)),
+				},
+			},
+		}
+		responses["400"] = map[string]interface{}{"description": "Bad Request"}
+	}
+
+	if !secured {
+		doc["security"] = []interface{}{}
+	}
+
+	return doc
+}
+
+// Spec returns the OpenAPI 3 description of the API served at baseURL.
+func (api *RestAPI) Spec(baseURL string) map[string]interface{} {
+	s := &apiSpec{schemas: make(map[string]interface{})}
+	auth := api.username != "" && api.password != ""
+
+	paths := make(map[string]interface{})
+	for _, route := range api.routes() {
+		ops := make(map[string]interface{})
+		for _, op := range route.Operations {
+			if !api.methodAllowed(route.Path, op.Method) {
+				continue
+			}
+
+			secured := auth && (api.specAuth || (route.Path != "/api/spec" && route.Path != "/api/openapi.json"))
+			ops[strings.ToLower(op.Method)] = s.operation(route, op, secured)
+		}
+		if len(ops) > 0 {
+			paths[route.Path] = ops
+		}
+	}
+
+	doc := map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   core.Name + " REST API",
+			"version": core.Version,
+		},
+		"servers": []interface{}{
+			map[string]interface{}{"url": baseURL},
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": s.schemas,
+		},
+	}
+
+	if auth {
+		doc["components"].(map[string]interface{})["securitySchemes"] = map[string]interface{}{
+			"basic": map[string]interface{}{"type": "http", "scheme": "basic"},
+		}
+		doc["security"] = []interface{}{
+			map[string]interface{}{"basic": []string{}},
+		}
+	}
+
+	return doc
+}
+
+func (api *RestAPI) specRoute(w http.ResponseWriter, r *http.Request) {
+	api.setSecurityHeaders(w)
+
+	if api.specAuth && !api.checkAuth(r) {
+		setAuthFailed(w, r)
+		return
+	} else if r.Method != "GET" {
+		http.Error(w, "Bad Request", 400)
+		return
+	}
+
+	scheme := "http"
+	if api.isTLS() {
+		scheme = "https"
+	}
+
+	toJSON(w, api.Spec(scheme+"://"+r.Host))
+}
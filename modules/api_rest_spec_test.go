@@ -0,0 +1,110 @@
+package modules
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type specTestNode struct {
+	Name     string          `json:"name"`
+	Count    int             `json:"count,string"`
+	Seen     time.Time       `json:"seen"`
+	Data     []byte          `json:"data"`
+	Tags     map[string]bool `json:"tags"`
+	Children []*specTestNode `json:"children"`
+	Hidden   string          `json:"-"`
+	private  string
+}
+
+func TestOperationID(t *testing.T) {
+	var units = []struct {
+		method string
+		route  string
+		exp    string
+	}{
+		{"GET", "/api/session", "get_api_session"},
+		{"DELETE", "/api/events", "delete_api_events"},
+		{"GET", "/api/session/lan/{mac}", "get_api_session_lan_mac"},
+	}
+
+	for _, u := range units {
+		if got := operationID(u.method, u.route); got != u.exp {
+			t.Fatalf("expected '%s', got '%s'", u.exp, got)
+		}
+	}
+}
+
+func TestAPISpecSchemaOf(t *testing.T) {
+	s := &apiSpec{schemas: make(map[string]interface{})}
+
+	ref := s.schemaOf(reflect.TypeOf(&specTestNode{}))
+	if exp := "#/components/schemas/modules.specTestNode"; ref["$ref"] != exp {
+		t.Fatalf("expected a reference to %s, got %v", exp, ref)
+	}
+
+	schema, ok := s.schemas["modules.specTestNode"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected the component to be defined, got %v", s.schemas)
+	}
+
+	props := schema["properties"].(map[string]interface{})
+	var units = []struct {
+		name string
+		exp  interface{}
+	}{
+		{"name", map[string]interface{}{"type": "string"}},
+		{"count", map[string]interface{}{"type": "string"}},
+		{"seen", map[string]interface{}{"type": "string", "format": "date-time"}},
+		{"data", map[string]interface{}{"type": "string", "format": "byte"}},
+		{"tags", map[string]interface{}{"type": "object", "additionalProperties": map[string]interface{}{"type": "boolean"}}},
+		{"children", map[string]interface{}{"type": "array", "items": ref}},
+	}
+
+	for _, u := range units {
+		if !reflect.DeepEqual(props[u.name], u.exp) {
+			t.Fatalf("expected %s to be %v, got %v", u.name, u.exp, props[u.name])
+		}
+	}
+
+	if len(props) != len(units) {
+		t.Fatalf("expected %d properties, got %v", len(units), props)
+	}
+}
+
+func TestRestAPISpec(t *testing.T) {
+	err, rules := parseMethodRules("/api/events:GET")
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := &RestAPI{methods: rules}
+
+	doc := api.Spec("http://127.0.0.1:8081")
+	if _, err := json.Marshal(doc); err != nil {
+		t.Fatalf("the spec can't be serialized: %s", err)
+	} else if _, found := doc["security"]; found {
+		t.Fatal("expected no security requirement without credentials")
+	}
+
+	paths := doc["paths"].(map[string]interface{})
+	events, found := paths["/api/events"].(map[string]interface{})
+	if !found {
+		t.Fatalf("expected /api/events to be documented, got %v", paths)
+	} else if _, found = events["get"]; !found {
+		t.Fatal("expected GET /api/events to be documented")
+	} else if _, found = events["delete"]; found {
+		t.Fatal("expected DELETE /api/events to be hidden by api.rest.methods")
+	}
+
+	api.username, api.password = "user", "pass"
+	doc = api.Spec("http://127.0.0.1:8081")
+	if _, found := doc["security"]; !found {
+		t.Fatal("expected a security requirement with credentials")
+	}
+
+	get := doc["paths"].(map[string]interface{})["/api/events"].(map[string]interface{})["get"].(map[string]interface{})
+	if _, found := get["responses"].(map[string]interface{})["401"]; !found {
+		t.Fatal("expected secured operations to document the 401 response")
+	}
+}
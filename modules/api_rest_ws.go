@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/bettercap/bettercap/log"
@@ -51,6 +52,14 @@ func (api *RestAPI) sendPing(ws *websocket.Conn) error {
 func (api *RestAPI) streamWriter(ws *websocket.Conn, w http.ResponseWriter, r *http.Request) {
 	defer ws.Close()
 
+	// let the client know if older events have been evicted
+	// from the buffer and won't be replayed
+	if stats := session.I.Events.Stats(); stats.Dropped > 0 {
+		if err := api.streamEvent(ws, session.NewEvent("events.dropped", stats)); err != nil {
+			return
+		}
+	}
+
 	// first we stream what we already have
 	events := session.I.Events.Sorted()
 	n := len(events)
@@ -67,20 +76,52 @@ func (api *RestAPI) streamWriter(ws *websocket.Conn, w http.ResponseWriter, r *h
 
 	log.Debug("Listening for events and streaming to ws endpoint ...")
 
+	atomic.AddInt32(&api.wsClients, 1)
+	defer atomic.AddInt32(&api.wsClients, -1)
+
 	pingTicker := time.NewTicker(pingPeriod)
+	defer pingTicker.Stop()
+
 	listener := session.I.Events.Listen()
 	defer session.I.Events.Unlisten(listener)
 
+	// the events pool blocks until every listener got the event, so this
+	// client's events are moved to its own buffer right away and if it can't
+	// keep up with them it's dropped instead of slowing down everybody else
+	queue := make(chan session.Event, api.wsBuffer)
+	full := make(chan bool)
+	go func() {
+		overflow := false
+		for event := range listener {
+			if overflow {
+				continue
+			}
+
+			select {
+			case queue <- event:
+			default:
+				overflow = true
+				close(full)
+			}
+		}
+	}()
+
 	for {
 		select {
 		case <-pingTicker.C:
 			if err := api.sendPing(ws); err != nil {
 				return
 			}
-		case event := <-listener:
+		case event := <-queue:
 			if err := api.streamEvent(ws, event); err != nil {
 				return
 			}
+		case <-full:
+			atomic.AddUint32(&api.wsDropped, 1)
+			log.Warning("websocket client %s is too slow, more than %d events are pending, dropping it.", r.RemoteAddr, api.wsBuffer)
+			msg := websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "client too slow, events buffer is full")
+			ws.WriteControl(websocket.CloseMessage, msg, time.Now().Add(writeWait))
+			return
 		case <-api.quit:
 			log.Info("Stopping websocket events streamer ...")
 			return
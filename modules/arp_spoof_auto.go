@@ -0,0 +1,174 @@
+package modules
+
+import (
+	"bytes"
+	"net"
+	"sync"
+
+	"github.com/bettercap/bettercap/network"
+	"github.com/bettercap/bettercap/session"
+)
+
+// arpAutoTargets are the hosts discovered by net.recon while arp.spoof.auto
+// is enabled, indexed by ip address.
+type arpAutoTargets struct {
+	sync.Mutex
+	hosts map[string]net.HardwareAddr
+}
+
+func newArpAutoTargets() *arpAutoTargets {
+	return &arpAutoTargets{
+		hosts: make(map[string]net.HardwareAddr),
+	}
+}
+
+func (t *arpAutoTargets) Add(ip string, mac net.HardwareAddr) bool {
+	t.Lock()
+	defer t.Unlock()
+
+	if prev, found := t.hosts[ip]; found && bytes.Equal(prev, mac) {
+		return false
+	}
+	t.hosts[ip] = mac
+	return true
+}
+
+func (t *arpAutoTargets) Has(ip string) bool {
+	t.Lock()
+	defer t.Unlock()
+	_, found := t.hosts[ip]
+	return found
+}
+
+func (t *arpAutoTargets) Len() int {
+	t.Lock()
+	defer t.Unlock()
+	return len(t.hosts)
+}
+
+func (t *arpAutoTargets) Remove(ip string) (net.HardwareAddr, bool) {
+	t.Lock()
+	defer t.Unlock()
+
+	mac, found := t.hosts[ip]
+	if found {
+		delete(t.hosts, ip)
+	}
+	return mac, found
+}
+
+func (t *arpAutoTargets) Each(cb func(ip string, mac net.HardwareAddr)) {
+	t.Lock()
+	hosts := make(map[string]net.HardwareAddr, len(t.hosts))
+	for ip, mac := range t.hosts {
+		hosts[ip] = mac
+	}
+	t.Unlock()
+
+	for ip, mac := range hosts {
+		cb(ip, mac)
+	}
+}
+
+func (t *arpAutoTargets) Clear() {
+	t.Lock()
+	defer t.Unlock()
+	t.hosts = make(map[string]net.HardwareAddr)
+}
+
+// autoAdd adds the endpoint to the spoofed hosts unless it's us, the
+// gateway or a whitelisted one.
+func (p *ArpSpoofer) autoAdd(e *network.Endpoint) bool {
+	if e == nil || e.IP == nil || e.HW == nil {
+		return false
+	} else if bytes.Equal(e.HW, p.Session.Interface.HW) || bytes.Equal(e.HW, p.Session.Gateway.HW) {
+		return false
+	} else if p.isWhitelisted(e.IpAddress, e.HW) {
+		return false
+	} else if p.autoTargets.Has(e.IpAddress) {
+		// already spoofed, maybe with another MAC, it doesn't count twice
+		return p.autoTargets.Add(e.IpAddress, e.HW)
+	}
+
+	nTargets := len(p.addresses) + len(p.macs) + p.autoTargets.Len() + 1
+	if err := p.Session.CheckTargets("arp.spoof", nTargets); err != nil {
+		// called while the events pool is locked, can't log synchronously
+		go p.Warning("arp.spoof.auto: not spoofing %s, %s", e.String(), err)
+		return false
+	}
+	return p.autoTargets.Add(e.IpAddress, e.HW)
+}
+
+func (p *ArpSpoofer) onAutoEvent(e session.Event) {
+	if e.Historical {
+		return
+	} else if changed, ok := e.Data.(network.EndpointIPChanged); ok {
+		// keep spoofing the host at its new address
+		if _, found := p.autoTargets.Remove(changed.Old); found && p.autoAdd(changed.Endpoint) {
+			go p.Info("arp.spoof.auto: host %s moved from %s to %s.", changed.Endpoint.HwAddress, changed.Old, changed.New)
+		}
+		return
+	}
+
+	endpoint, ok := e.Data.(*network.Endpoint)
+	if !ok {
+		return
+	}
+
+	// NOTE: events are delivered while the events pool is locked, so
+	// nothing in here can log or emit events synchronously.
+	if e.Tag == "endpoint.new" {
+		// the listener also receives the buffered events, only pick
+		// the hosts that are still around
+		if _, found := p.Session.Lan.Get(endpoint.HwAddress); found && p.autoAdd(endpoint) {
+			go p.Info("arp.spoof.auto: spoofing new host %s.", endpoint.String())
+		}
+	} else if e.Tag == "endpoint.lost" {
+		if mac, found := p.autoTargets.Remove(endpoint.IpAddress); found {
+			go func() {
+				p.Info("arp.spoof.auto: restoring and dropping lost host %s.", endpoint.String())
+				p.sendArpTo(p.Session.Gateway.IP, p.Session.Gateway.HW, endpoint.IpAddress, mac)
+			}()
+		}
+	}
+}
+
+func (p *ArpSpoofer) startAutoTargets() {
+	if err, mod := p.Session.Module("net.recon"); err != nil || !mod.Running() {
+		p.Warning("arp.spoof.auto only picks new hosts while net.recon is running.")
+	}
+
+	for _, e := range p.Session.Lan.List() {
+		p.autoAdd(e)
+	}
+
+	quit := make(chan bool)
+	p.autoQuit = quit
+
+	listener := p.Session.Events.Listen()
+	go func() {
+		for {
+			select {
+			case e := <-listener:
+				p.onAutoEvent(e)
+			case <-quit:
+				// keep draining so that nobody blocks while we unsubscribe
+				go func() {
+					for range listener {
+					}
+				}()
+				p.Session.Events.Unlisten(listener)
+				return
+			}
+		}
+	}()
+}
+
+func (p *ArpSpoofer) stopAutoTargets() {
+	// closing never blocks, even if the listener goroutine is busy
+	// delivering an event to onAutoEvent
+	if p.auto && p.autoQuit != nil {
+		close(p.autoQuit)
+		p.autoQuit = nil
+	}
+}
@@ -0,0 +1,184 @@
+package modules
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/bettercap/bettercap/packets"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcap"
+)
+
+// how long to wait for the replies to the verification probes
+const arpVerifyTimeout = 3 * time.Second
+
+// ArpSpoofVerification is the payload of arp.spoof.confirmed and
+// arp.spoof.failed events.
+type ArpSpoofVerification struct {
+	Address  string    `json:"address"`
+	MAC      string    `json:"mac"`
+	Poisoned bool      `json:"poisoned"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// arpVerifier tells if the targets are poisoned by looking for the frames
+// they send to the gateway using our MAC address: their traffic going
+// outside of the subnet and the replies to ICMP echo requests we send them
+// on behalf of the gateway.
+type arpVerifier struct {
+	sync.Mutex
+	handle *pcap.Handle
+	seen   map[string]time.Time
+	state  map[string]bool
+	quit   chan bool
+}
+
+func (v *arpVerifier) observe(address string, t time.Time) {
+	v.Lock()
+	defer v.Unlock()
+	v.seen[address] = t
+}
+
+func (v *arpVerifier) lastSeen(address string) time.Time {
+	v.Lock()
+	defer v.Unlock()
+	return v.seen[address]
+}
+
+// update returns true if the state of address changed since the previous
+// check.
+func (v *arpVerifier) update(address string, poisoned bool) bool {
+	v.Lock()
+	defer v.Unlock()
+
+	prev, found := v.state[address]
+	v.state[address] = poisoned
+	return !found || prev != poisoned
+}
+
+func (p *ArpSpoofer) poisonMAC() net.HardwareAddr {
+	if p.srcMAC != nil {
+		return p.srcMAC
+	}
+	return p.Session.Interface.HW
+}
+
+func (p *ArpSpoofer) startVerifier() error {
+	handle, err := pcap.OpenLive(p.Session.Interface.Name(), 128, true, pcap.BlockForever)
+	if err != nil {
+		return err
+	} else if err = handle.SetBPFFilter(fmt.Sprintf("ip and ether dst %s", p.poisonMAC())); err != nil {
+		handle.Close()
+		return err
+	}
+
+	v := &arpVerifier{
+		handle: handle,
+		seen:   make(map[string]time.Time),
+		state:  make(map[string]bool),
+		quit:   make(chan bool),
+	}
+	p.verifier = v
+
+	go p.verifyListener(v)
+	go p.verifyProber(v)
+
+	return nil
+}
+
+func (p *ArpSpoofer) stopVerifier() {
+	if p.verifier != nil {
+		close(p.verifier.quit)
+		p.verifier.handle.Close()
+		p.verifier = nil
+	}
+}
+
+// verifyListener records when each target sent us a frame meant for the
+// gateway.
+func (p *ArpSpoofer) verifyListener(v *arpVerifier) {
+	gwIP := p.Session.Gateway.IP
+	myIP := p.Session.Interface.IP
+	subnet := p.Session.Interface.Net
+
+	src := gopacket.NewPacketSource(v.handle, v.handle.LinkType())
+	for packet := range src.Packets() {
+		p.FrameReceived()
+		ip4, ok := packet.Layer(layers.LayerTypeIPv4).(*layers.IPv4)
+		if !ok || ip4.DstIP.Equal(myIP) {
+			continue
+		}
+
+		if ip4.DstIP.Equal(gwIP) || (subnet != nil && !subnet.Contains(ip4.DstIP)) {
+			v.observe(ip4.SrcIP.String(), time.Now())
+		}
+	}
+}
+
+func (p *ArpSpoofer) sendVerifyProbe(address string, mac net.HardwareAddr, seq uint16) {
+	err, pkt := packets.NewICMPEcho(p.Session.Gateway.IP, p.poisonMAC(), net.ParseIP(address), mac, 0xbc, seq)
+	if err != nil {
+		p.Error("error while creating the verification probe for %s: %s", address, err)
+	} else if err = p.Send(pkt); err != nil {
+		p.Debug("error while sending the verification probe to %s: %s", address, err)
+	}
+}
+
+func (p *ArpSpoofer) verifyProber(v *arpVerifier) {
+	seq := uint16(0)
+	for {
+		select {
+		case <-v.quit:
+			return
+		case <-time.After(p.verifyEvery):
+		}
+
+		// the targets are not poisoned anymore while nothing is sent,
+		// don't probe them nor report them as failed
+		if p.Session.Paused() {
+			continue
+		}
+
+		targets := make(map[string]net.HardwareAddr)
+		for ip, mac := range p.targets(false) {
+			if !p.isWhitelisted(ip, mac) {
+				targets[ip] = mac
+			}
+		}
+
+		// traffic seen during the whole round is as good as a reply
+		since := time.Now().Add(-p.verifyEvery)
+		seq++
+		for ip, mac := range targets {
+			p.sendVerifyProbe(ip, mac, seq)
+		}
+
+		select {
+		case <-v.quit:
+			return
+		case <-time.After(arpVerifyTimeout):
+		}
+
+		for ip, mac := range targets {
+			last := v.lastSeen(ip)
+			poisoned := last.After(since)
+			if v.update(ip, poisoned) {
+				tag := "arp.spoof.failed"
+				if poisoned {
+					tag = "arp.spoof.confirmed"
+				}
+
+				p.Session.Events.Add(tag, ArpSpoofVerification{
+					Address:  ip,
+					MAC:      mac.String(),
+					Poisoned: poisoned,
+					LastSeen: last,
+				})
+			}
+		}
+	}
+}
@@ -0,0 +1,219 @@
+// +build !windows
+// +build !darwin
+
+package modules
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bettercap/bettercap/core"
+	"github.com/bettercap/bettercap/log"
+	"github.com/bettercap/bettercap/network"
+	"github.com/bettercap/bettercap/session"
+
+	"github.com/bettercap/gatt"
+)
+
+// BLEEnumSummary is the payload of ble.enum.summary events.
+type BLEEnumSummary struct {
+	Enumerated []string      `json:"enumerated"`
+	Skipped    []string      `json:"skipped"`
+	Duration   time.Duration `json:"duration"`
+}
+
+// bleBulkEnum keeps track of the devices being enumerated in parallel by
+// ble.enum all, the gatt callbacks use it to tell which connections are
+// theirs.
+type bleBulkEnum struct {
+	sync.Mutex
+	pending map[string]chan error
+}
+
+func (b *bleBulkEnum) add(id string) chan error {
+	b.Lock()
+	defer b.Unlock()
+	done := make(chan error, 1)
+	b.pending[id] = done
+	return done
+}
+
+func (b *bleBulkEnum) has(id string) bool {
+	b.Lock()
+	defer b.Unlock()
+	_, found := b.pending[id]
+	return found
+}
+
+// finish signals the end of the enumeration of id, only the first call for
+// each device counts.
+func (b *bleBulkEnum) finish(id string, err error) {
+	b.Lock()
+	defer b.Unlock()
+	if done, found := b.pending[id]; found {
+		delete(b.pending, id)
+		done <- err
+	}
+}
+
+func (d *BLERecon) bulkEnumerating() bool {
+	d.bulkLock.Lock()
+	defer d.bulkLock.Unlock()
+	return d.bulk != nil
+}
+
+// bulkDevice returns the bulk enumeration p belongs to, if any.
+func (d *BLERecon) bulkDevice(p gatt.Peripheral) (*bleBulkEnum, string, bool) {
+	d.bulkLock.Lock()
+	bulk := d.bulk
+	d.bulkLock.Unlock()
+
+	if bulk == nil || p == nil {
+		return nil, "", false
+	}
+
+	id := network.NormalizeMac(p.ID())
+	return bulk, id, bulk.has(id)
+}
+
+func (d *BLERecon) onBulkConnected(bulk *bleBulkEnum, id string, p gatt.Peripheral, err error) {
+	if err != nil {
+		// most likely the controller refused one more connection
+		bulk.finish(id, err)
+		return
+	}
+
+	defer p.Device().CancelConnection(p)
+
+	if dev, found := d.Session.BLE.Get(id); found {
+		d.Session.Events.Add("ble.device.connected", dev)
+	}
+
+	if d.connMTU != 0 {
+		if err := p.SetMTU(uint16(d.connMTU)); err != nil {
+			log.Debug("%s rejected MTU %d: %s", id, d.connMTU, err)
+		}
+	}
+
+	services, err := p.DiscoverServices(nil)
+	if err == nil {
+		d.showServices(p, services)
+	}
+	bulk.finish(id, err)
+}
+
+func (d *BLERecon) enumDevice(bulk *bleBulkEnum, dev *network.BLEDevice, timeout time.Duration) error {
+	id := network.NormalizeMac(dev.Device.ID())
+	done := bulk.add(id)
+
+	log.Info("Connecting to %s ...", id)
+	d.gattDevice.Connect(dev.Device)
+
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(timeout):
+		bulk.finish(id, nil)
+		d.gattDevice.CancelConnection(dev.Device)
+		d.Session.Events.Add("ble.connection.timeout", dev)
+		return fmt.Errorf("timed out after %s", timeout)
+	}
+}
+
+func (d *BLERecon) enumAll() error {
+	var err error
+	var concurrency, timeout int
+
+	if d.isEnumerating() || d.bulkEnumerating() {
+		return fmt.Errorf("An enumeration is already running, please wait.")
+	} else if err = d.configureConnection(); err != nil {
+		return err
+	} else if err, concurrency = d.IntParam("ble.enum.concurrency"); err != nil {
+		return err
+	} else if err, timeout = d.IntParam("ble.enum.timeout"); err != nil {
+		return err
+	} else if concurrency < 1 {
+		return fmt.Errorf("ble.enum.concurrency must be greater than 0")
+	} else if timeout <= 0 {
+		return fmt.Errorf("ble.enum.timeout must be greater than 0")
+	} else if concurrency > bleMaxConnections {
+		log.Warning("the adapter is configured for up to %d connections, using ble.enum.concurrency %d.", bleMaxConnections, bleMaxConnections)
+		concurrency = bleMaxConnections
+	}
+
+	devices := d.Session.BLE.Devices()
+	if len(devices) == 0 {
+		return fmt.Errorf("No BLE devices to enumerate, run ble.recon on first.")
+	}
+
+	if err := d.Configure(); err != nil && err != session.ErrAlreadyStarted {
+		return err
+	} else if d.Running() {
+		d.gattDevice.StopScanning()
+	}
+
+	d.writeData = nil
+	d.writeUUID = nil
+
+	bulk := &bleBulkEnum{pending: make(map[string]chan error)}
+	d.bulkLock.Lock()
+	d.bulk = bulk
+	d.bulkLock.Unlock()
+
+	log.Info("Enumerating %d devices, %d at a time ...", len(devices), concurrency)
+
+	go func() {
+		started := time.Now()
+		summary := BLEEnumSummary{
+			Enumerated: make([]string, 0),
+			Skipped:    make([]string, 0),
+		}
+		lock := sync.Mutex{}
+		wg := sync.WaitGroup{}
+		slots := make(chan bool, concurrency)
+
+		for _, dev := range devices {
+			slots <- true
+			wg.Add(1)
+			go func(dev *network.BLEDevice) {
+				defer func() {
+					<-slots
+					wg.Done()
+				}()
+
+				id := network.NormalizeMac(dev.Device.ID())
+				err := d.enumDevice(bulk, dev, time.Duration(timeout)*time.Second)
+
+				lock.Lock()
+				defer lock.Unlock()
+				if err != nil {
+					log.Warning("Skipping %s: %s", id, err)
+					summary.Skipped = append(summary.Skipped, id)
+				} else {
+					summary.Enumerated = append(summary.Enumerated, id)
+				}
+			}(dev)
+		}
+		wg.Wait()
+
+		d.bulkLock.Lock()
+		d.bulk = nil
+		d.bulkLock.Unlock()
+
+		summary.Duration = time.Since(started)
+		d.Session.Events.Add("ble.enum.summary", summary)
+
+		log.Info("Enumerated %d devices in %s, %d skipped.", len(summary.Enumerated), summary.Duration.Round(time.Second), len(summary.Skipped))
+		if len(summary.Skipped) > 0 {
+			log.Info("Skipped devices: %s", core.Dim(fmt.Sprintf("%v", summary.Skipped)))
+		}
+
+		if d.Running() {
+			log.Info("Restoring BLE discovery.")
+			d.gattDevice.Scan([]gatt.UUID{}, true)
+		}
+	}()
+
+	return nil
+}
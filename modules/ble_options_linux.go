@@ -5,8 +5,11 @@ import (
 	// "github.com/bettercap/gatt/linux/cmd"
 )
 
+// maximum number of simultaneous connections the adapter is set up for
+const bleMaxConnections = 255
+
 var defaultBLEClientOptions = []gatt.Option{
-	gatt.LnxMaxConnections(255),
+	gatt.LnxMaxConnections(bleMaxConnections),
 	gatt.LnxDeviceID(-1, true),
 }
 
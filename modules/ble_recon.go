@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	golog "log"
+	"sync"
 	"time"
 
 	"github.com/bettercap/bettercap/log"
@@ -17,6 +18,12 @@ import (
 	"github.com/bettercap/gatt"
 )
 
+const (
+	// ATT MTU limits from the Bluetooth core specification
+	bleMinMTU = 23
+	bleMaxMTU = 517
+)
+
 type BLERecon struct {
 	session.SessionModule
 	gattDevice  gatt.Device
@@ -25,6 +32,10 @@ type BLERecon struct {
 	writeData   []byte
 	connected   bool
 	connTimeout time.Duration
+	connMTU     int
+	tracker     *rssiTracker
+	bulk        *bleBulkEnum
+	bulkLock    *sync.Mutex
 	quit        chan bool
 	done        chan bool
 }
@@ -36,10 +47,14 @@ func NewBLERecon(s *session.Session) *BLERecon {
 		quit:          make(chan bool),
 		done:          make(chan bool),
 		connTimeout:   time.Duration(10) * time.Second,
+		connMTU:       500,
 		currDevice:    nil,
 		connected:     false,
+		bulkLock:      &sync.Mutex{},
 	}
 
+	d.EmitsEvents("ble")
+
 	d.AddHandler(session.NewModuleHandler("ble.recon on", "",
 		"Start Bluetooth Low Energy devices discovery.",
 		func(args []string) error {
@@ -71,6 +86,12 @@ func NewBLERecon(s *session.Session) *BLERecon {
 			return d.enumAllTheThings(network.NormalizeMac(args[0]))
 		}))
 
+	d.AddHandler(session.NewModuleHandler("ble.enum all", "",
+		"Enumerate services and characteristics of every discovered BLE device, ble.enum.concurrency devices at a time, devices not done within ble.enum.timeout are skipped.",
+		func(args []string) error {
+			return d.enumAll()
+		}))
+
 	d.AddHandler(session.NewModuleHandler("ble.write MAC UUID HEX_DATA", "ble.write "+network.BLEMacValidator+" ([a-fA-F0-9]+) ([a-fA-F0-9]+)",
 		"Write the HEX_DATA buffer to the BLE device with the specified MAC address, to the characteristics with the given UUID.",
 		func(args []string) error {
@@ -87,6 +108,36 @@ func NewBLERecon(s *session.Session) *BLERecon {
 			return d.writeBuffer(mac, uuid, data)
 		}))
 
+	d.AddHandler(session.NewModuleHandler("ble.track MAC", "ble.track "+network.BLEMacValidator,
+		"Track the signal of the BLE device with the specified MAC address, a track.hot event is emitted when its average RSSI goes above ble.track.threshold and a track.cold one when it goes back below.",
+		func(args []string) error {
+			return d.Track(network.NormalizeMac(args[0]))
+		}))
+
+	d.AddHandler(session.NewModuleHandler("ble.track off", "",
+		"Stop tracking the signal of the device set with ble.track.",
+		func(args []string) error {
+			return d.StopTracking()
+		}))
+
+	addTrackParams(&d.SessionModule, "ble")
+
+	d.AddParam(session.NewIntParameter("ble.conn.mtu",
+		"500",
+		"ATT MTU to negotiate with the device after connecting (23 to 517), larger values make reads faster, 0 to keep the default one."))
+
+	d.AddParam(session.NewIntParameter("ble.conn.timeout",
+		"10",
+		"Number of seconds to wait for the device to accept the connection before giving up."))
+
+	d.AddParam(session.NewIntParameter("ble.enum.concurrency",
+		"1",
+		"How many devices ble.enum all connects to at the same time, adapters supporting multiple connections can enumerate several devices in parallel."))
+
+	d.AddParam(session.NewIntParameter("ble.enum.timeout",
+		"30",
+		"Number of seconds ble.enum all waits for each device to be connected and enumerated before skipping it."))
+
 	return d
 }
 
@@ -130,6 +181,25 @@ func (d *BLERecon) Configure() (err error) {
 	return nil
 }
 
+func (d *BLERecon) configureConnection() error {
+	var err error
+	var timeout int
+
+	if err, d.connMTU = d.IntParam("ble.conn.mtu"); err != nil {
+		return err
+	} else if d.connMTU != 0 && (d.connMTU < bleMinMTU || d.connMTU > bleMaxMTU) {
+		return fmt.Errorf("ble.conn.mtu must be 0 or between %d and %d", bleMinMTU, bleMaxMTU)
+	} else if err, timeout = d.IntParam("ble.conn.timeout"); err != nil {
+		return err
+	} else if timeout <= 0 {
+		return fmt.Errorf("ble.conn.timeout must be greater than 0")
+	}
+
+	d.connTimeout = time.Duration(timeout) * time.Second
+
+	return nil
+}
+
 func (d *BLERecon) Start() error {
 	if err := d.Configure(); err != nil {
 		return err
@@ -182,12 +252,18 @@ func (d *BLERecon) writeBuffer(mac string, uuid gatt.UUID, data []byte) error {
 
 func (d *BLERecon) enumAllTheThings(mac string) error {
 	dev, found := d.Session.BLE.Get(mac)
-	if !found || dev == nil {
+	if d.bulkEnumerating() {
+		return fmt.Errorf("ble.enum all is still running, please wait.")
+	} else if !found || dev == nil {
 		return fmt.Errorf("BLE device with address %s not found.", mac)
 	} else if d.Running() {
 		d.gattDevice.StopScanning()
 	}
 
+	if err := d.configureConnection(); err != nil {
+		return err
+	}
+
 	d.setCurrentDevice(dev)
 	if err := d.Configure(); err != nil && err != session.ErrAlreadyStarted {
 		return err
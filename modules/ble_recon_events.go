@@ -4,6 +4,8 @@
 package modules
 
 import (
+	"fmt"
+
 	"github.com/bettercap/bettercap/log"
 
 	"github.com/bettercap/gatt"
@@ -31,6 +33,14 @@ func (d *BLERecon) onPeriphDiscovered(p gatt.Peripheral, a *gatt.Advertisement,
 }
 
 func (d *BLERecon) onPeriphDisconnected(p gatt.Peripheral, err error) {
+	if bulk, id, found := d.bulkDevice(p); found {
+		bulk.finish(id, fmt.Errorf("disconnected"))
+		return
+	} else if d.bulkEnumerating() {
+		// discovery is restored once every device has been enumerated
+		return
+	}
+
 	if d.Running() {
 		// restore scanning
 		log.Info("Device disconnected, restoring BLE discovery.")
@@ -40,6 +50,11 @@ func (d *BLERecon) onPeriphDisconnected(p gatt.Peripheral, err error) {
 }
 
 func (d *BLERecon) onPeriphConnected(p gatt.Peripheral, err error) {
+	if bulk, id, found := d.bulkDevice(p); found {
+		d.onBulkConnected(bulk, id, p, err)
+		return
+	}
+
 	if err != nil {
 		log.Warning("Connected to %s but with error: %s", p.ID(), err)
 		return
@@ -58,8 +73,13 @@ func (d *BLERecon) onPeriphConnected(p gatt.Peripheral, err error) {
 
 	d.Session.Events.Add("ble.device.connected", d.currDevice)
 
-	if err := p.SetMTU(500); err != nil {
-		log.Warning("Failed to set MTU: %s", err)
+	if d.connMTU != 0 {
+		if err := p.SetMTU(uint16(d.connMTU)); err != nil {
+			// the device rejected the exchange, we keep going with the default one
+			log.Warning("%s rejected MTU %d, using the default of %d bytes: %s", p.ID(), d.connMTU, bleMinMTU, err)
+		} else {
+			log.Debug("negotiated MTU %d with %s", d.connMTU, p.ID())
+		}
 	}
 
 	log.Info("Connected, enumerating all the things for %s!", p.ID())
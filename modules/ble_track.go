@@ -0,0 +1,53 @@
+// +build !windows
+// +build !darwin
+
+package modules
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bettercap/bettercap/log"
+)
+
+func (d *BLERecon) deviceSignal(mac string) (int, time.Time, bool) {
+	if dev, found := d.Session.BLE.Get(mac); found {
+		return dev.RSSI, dev.LastSeen, true
+	}
+	return 0, time.Time{}, false
+}
+
+func (d *BLERecon) Track(mac string) error {
+	err, tracker := newTracker(&d.SessionModule, "ble", mac)
+	if err != nil {
+		return err
+	}
+
+	if d.tracker != nil {
+		d.tracker.Stop()
+	}
+	d.tracker = tracker
+
+	if _, _, found := d.deviceSignal(mac); !found {
+		log.Warning("%s has not been seen yet, it will be tracked as soon as it shows up.", mac)
+	}
+	log.Info("tracking %s, hot above %d dBm.", mac, tracker.threshold)
+
+	go tracker.Run(d.Session, func() (int, time.Time, bool) {
+		return d.deviceSignal(mac)
+	})
+
+	return nil
+}
+
+func (d *BLERecon) StopTracking() error {
+	if d.tracker == nil {
+		return fmt.Errorf("no device is being tracked")
+	}
+
+	log.Info("stopped tracking %s.", d.tracker.address)
+	d.tracker.Stop()
+	d.tracker = nil
+
+	return nil
+}
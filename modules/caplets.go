@@ -35,6 +35,11 @@ func NewCapletsModule(s *session.Session) *CapletsModule {
 			return c.Paths()
 		}))
 
+	c.AddParam(session.NewStringParameter("caplets.pins",
+		"",
+		"",
+		"Comma separated list of SHA256 hashes (hex or base64) of the certificate public keys to accept while downloading the caplets, empty to disable pinning."))
+
 	c.AddHandler(session.NewModuleHandler("caplets.update", "",
 		"Install/updates the caplets.",
 		func(args []string) error {
@@ -111,6 +116,17 @@ func (c *CapletsModule) Paths() error {
 }
 
 func (c *CapletsModule) Update() error {
+	err, pinList := c.StringParam("caplets.pins")
+	if err != nil {
+		return err
+	}
+
+	err, pins := core.ParsePins(pinList)
+	if err != nil {
+		return err
+	}
+	client := core.PinnedClient(pins)
+
 	if !core.Exists(caplets.InstallBase) {
 		log.Info("creating caplets install path %s ...", caplets.InstallBase)
 		if err := os.MkdirAll(caplets.InstallBase, os.ModePerm); err != nil {
@@ -126,12 +142,16 @@ func (c *CapletsModule) Update() error {
 
 	log.Info("downloading caplets from %s ...", caplets.InstallArchive)
 
-	resp, err := http.Get(caplets.InstallArchive)
+	resp, err := client.Get(caplets.InstallArchive)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s while downloading caplets", resp.Status)
+	}
+
 	if _, err := io.Copy(out, resp.Body); err != nil {
 		return err
 	}
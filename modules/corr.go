@@ -0,0 +1,407 @@
+package modules
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bettercap/bettercap/core"
+	"github.com/bettercap/bettercap/log"
+	"github.com/bettercap/bettercap/network"
+	"github.com/bettercap/bettercap/session"
+)
+
+const (
+	corrBLEMeta        = "corr:ble"
+	corrWiFiMeta       = "corr:wifi"
+	corrConfidenceMeta = "corr:confidence"
+	corrStatusMeta     = "corr:status"
+)
+
+// DeviceCorrelation is the payload of device.correlated events, a WiFi
+// station and a BLE device which are likely the same device.
+type DeviceCorrelation struct {
+	ID         int      `json:"id"`
+	WiFi       string   `json:"wifi"`
+	BLE        string   `json:"ble"`
+	BLEName    string   `json:"ble_name"`
+	Vendor     string   `json:"vendor"`
+	Confidence float64  `json:"confidence"`
+	Reasons    []string `json:"reasons"`
+	Confirmed  bool     `json:"confirmed"`
+
+	wifiMeta *network.Meta
+	bleMeta  *network.Meta
+}
+
+func (c *DeviceCorrelation) key() string {
+	return c.WiFi + "|" + c.BLE
+}
+
+// store tags both entries with the linkage.
+func (c *DeviceCorrelation) store() {
+	status := "suggested"
+	if c.Confirmed {
+		status = "confirmed"
+	}
+	confidence := fmt.Sprintf("%.2f", c.Confidence)
+
+	c.wifiMeta.Set(corrBLEMeta, c.BLE)
+	c.wifiMeta.Set(corrConfidenceMeta, confidence)
+	c.wifiMeta.Set(corrStatusMeta, status)
+	c.bleMeta.Set(corrWiFiMeta, c.WiFi)
+	c.bleMeta.Set(corrConfidenceMeta, confidence)
+	c.bleMeta.Set(corrStatusMeta, status)
+}
+
+func (c *DeviceCorrelation) forget() {
+	for _, meta := range []*network.Meta{c.wifiMeta, c.bleMeta} {
+		for _, name := range []string{corrBLEMeta, corrWiFiMeta, corrConfidenceMeta, corrStatusMeta} {
+			meta.Delete(name)
+		}
+	}
+}
+
+// corrDevice is what the heuristics need to know of a WiFi station or a
+// BLE device.
+type corrDevice struct {
+	MAC       string
+	Name      string
+	Vendor    string
+	FirstSeen time.Time
+	LastSeen  time.Time
+	Meta      *network.Meta
+}
+
+// macDistance returns how far apart the NIC specific parts of two addresses
+// with the same OUI are, many devices derive their radio addresses from a
+// single base one.
+func macDistance(a string, b string) (sameOUI bool, distance int) {
+	hwA, errA := net.ParseMAC(a)
+	hwB, errB := net.ParseMAC(b)
+	if errA != nil || errB != nil || len(hwA) != 6 || len(hwB) != 6 {
+		return false, -1
+	} else if hwA[0] != hwB[0] || hwA[1] != hwB[1] || hwA[2] != hwB[2] {
+		return false, -1
+	}
+
+	nicA := int(hwA[3])<<16 | int(hwA[4])<<8 | int(hwA[5])
+	nicB := int(hwB[3])<<16 | int(hwB[4])<<8 | int(hwB[5])
+	if distance = nicA - nicB; distance < 0 {
+		distance = -distance
+	}
+	return true, distance
+}
+
+// corrScore tells how likely wifi and ble are the same device and why,
+// co-observation alone is too weak so at least an address or a vendor
+// match is needed.
+func corrScore(wifi corrDevice, ble corrDevice, window time.Duration) (float64, []string) {
+	score := 0.0
+	reasons := make([]string, 0)
+
+	if sameOUI, distance := macDistance(wifi.MAC, ble.MAC); sameOUI && distance <= 2 {
+		score += 0.6
+		reasons = append(reasons, "adjacent addresses")
+	} else if sameOUI {
+		score += 0.3
+		reasons = append(reasons, "same OUI")
+	} else if wifi.Vendor != "" && strings.EqualFold(wifi.Vendor, ble.Vendor) {
+		score += 0.3
+		reasons = append(reasons, "same vendor")
+	}
+
+	if score == 0 {
+		return 0, nil
+	}
+
+	if within(wifi.FirstSeen, ble.FirstSeen, window) {
+		score += 0.2
+		reasons = append(reasons, "appeared together")
+	}
+	if within(wifi.LastSeen, ble.LastSeen, window) {
+		score += 0.2
+		reasons = append(reasons, "seen together")
+	}
+
+	if score > 1 {
+		score = 1
+	}
+	return score, reasons
+}
+
+func within(a time.Time, b time.Time, window time.Duration) bool {
+	if a.IsZero() || b.IsZero() {
+		return false
+	}
+	d := a.Sub(b)
+	return d <= window && d >= -window
+}
+
+type Correlator struct {
+	session.SessionModule
+	lock        *sync.Mutex
+	nextID      int
+	suggestions map[int]*DeviceCorrelation
+	rejected    map[string]bool
+}
+
+func NewCorrelator(s *session.Session) *Correlator {
+	c := &Correlator{
+		SessionModule: session.NewSessionModule("corr", s),
+		lock:          &sync.Mutex{},
+		nextID:        1,
+		suggestions:   make(map[int]*DeviceCorrelation),
+		rejected:      make(map[string]bool),
+	}
+
+	c.EmitsEvents("device.correlated")
+
+	c.AddParam(session.NewIntParameter("corr.confidence",
+		"50",
+		"Minimum confidence, in percent, of the suggested pairings of WiFi stations and BLE devices."))
+
+	c.AddParam(session.NewIntParameter("corr.window",
+		"60",
+		"Seconds within which a WiFi station and a BLE device have to be first or last seen to be considered co-observed."))
+
+	c.AddHandler(session.NewModuleHandler("corr", "",
+		"Suggest which WiFi stations and BLE devices are likely the same device, by vendor and co-observation timing.",
+		func(args []string) error {
+			return c.Correlate()
+		}))
+
+	c.AddHandler(session.NewModuleHandler("corr.confirm ID", `corr\.confirm\s+(\d+)`,
+		"Confirm the suggested pairing ID.",
+		func(args []string) error {
+			return c.Confirm(args[0])
+		}))
+
+	c.AddHandler(session.NewModuleHandler("corr.reject ID", `corr\.reject\s+(\d+)`,
+		"Reject the suggested pairing ID, it won't be suggested again.",
+		func(args []string) error {
+			return c.Reject(args[0])
+		}))
+
+	return c
+}
+
+func (c Correlator) Name() string {
+	return "corr"
+}
+
+func (c Correlator) Description() string {
+	return "Links the WiFi stations and the BLE devices which are likely the same device."
+}
+
+func (c Correlator) Author() string {
+	return "Simone Margaritelli <evilsocket@protonmail.com>"
+}
+
+func (c *Correlator) Configure() error {
+	return nil
+}
+
+func (c *Correlator) Start() error {
+	return nil
+}
+
+func (c *Correlator) Stop() error {
+	return nil
+}
+
+func (c *Correlator) wifiDevices() []corrDevice {
+	devices := make([]corrDevice, 0)
+	for _, ap := range c.Session.WiFi.List() {
+		for _, client := range ap.Clients() {
+			devices = append(devices, corrDevice{
+				MAC:       client.HwAddress,
+				Vendor:    client.Vendor,
+				FirstSeen: client.FirstSeen,
+				LastSeen:  client.LastSeen,
+				Meta:      client.Meta,
+			})
+		}
+	}
+	return devices
+}
+
+func (c *Correlator) findByKey(key string) *DeviceCorrelation {
+	for _, s := range c.suggestions {
+		if s.key() == key {
+			return s
+		}
+	}
+	return nil
+}
+
+// Correlate pairs each station with at most one BLE device and the other
+// way around, the most likely pairs first.
+func (c *Correlator) Correlate() error {
+	var err error
+	var percent, window int
+	var ble []corrDevice
+
+	if err, percent = c.IntParam("corr.confidence"); err != nil {
+		return err
+	} else if err, window = c.IntParam("corr.window"); err != nil {
+		return err
+	} else if err, ble = corrBLEDevices(c.Session); err != nil {
+		return err
+	}
+
+	wifi := c.wifiDevices()
+	if len(wifi) == 0 || len(ble) == 0 {
+		return fmt.Errorf("Both WiFi stations and BLE devices are needed, run wifi.recon and ble.recon first.")
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	threshold := float64(percent) / 100
+	candidates := make([]*DeviceCorrelation, 0)
+	for _, w := range wifi {
+		for _, b := range ble {
+			score, reasons := corrScore(w, b, time.Duration(window)*time.Second)
+			candidate := &DeviceCorrelation{
+				WiFi:       w.MAC,
+				BLE:        b.MAC,
+				BLEName:    b.Name,
+				Vendor:     w.Vendor,
+				Confidence: score,
+				Reasons:    reasons,
+				wifiMeta:   w.Meta,
+				bleMeta:    b.Meta,
+			}
+			if score >= threshold && score > 0 && !c.rejected[candidate.key()] {
+				candidates = append(candidates, candidate)
+			}
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Confidence > candidates[j].Confidence
+	})
+
+	// confirmed pairings keep their devices
+	used := make(map[string]bool)
+	for _, s := range c.suggestions {
+		if s.Confirmed {
+			used[s.WiFi] = true
+			used[s.BLE] = true
+		}
+	}
+
+	for _, candidate := range candidates {
+		if used[candidate.WiFi] || used[candidate.BLE] {
+			continue
+		}
+		used[candidate.WiFi] = true
+		used[candidate.BLE] = true
+
+		if s := c.findByKey(candidate.key()); s != nil {
+			s.Confidence, s.Reasons = candidate.Confidence, candidate.Reasons
+			s.wifiMeta, s.bleMeta = candidate.wifiMeta, candidate.bleMeta
+			s.store()
+			continue
+		}
+
+		candidate.ID = c.nextID
+		c.nextID++
+		c.suggestions[candidate.ID] = candidate
+		candidate.store()
+		c.Session.Events.Add("device.correlated", *candidate)
+	}
+
+	c.show()
+	return nil
+}
+
+func (c *Correlator) show() {
+	if len(c.suggestions) == 0 {
+		log.Info("no likely pairings of WiFi stations and BLE devices.")
+		return
+	}
+
+	list := make([]*DeviceCorrelation, 0, len(c.suggestions))
+	for _, s := range c.suggestions {
+		list = append(list, s)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ID < list[j].ID
+	})
+
+	rows := make([][]string, 0, len(list))
+	for _, s := range list {
+		status := core.Yellow("suggested")
+		if s.Confirmed {
+			status = core.Green("confirmed")
+		}
+
+		ble := s.BLE
+		if s.BLEName != "" {
+			ble = fmt.Sprintf("%s (%s)", s.BLE, s.BLEName)
+		}
+
+		rows = append(rows, []string{
+			fmt.Sprintf("%d", s.ID),
+			s.WiFi,
+			ble,
+			s.Vendor,
+			fmt.Sprintf("%.0f%%", s.Confidence*100),
+			core.Dim(strings.Join(s.Reasons, ", ")),
+			status,
+		})
+	}
+
+	fmt.Println()
+	core.AsTable(os.Stdout, []string{"ID", "WiFi", "BLE", "Vendor", "Confidence", "Reasons", "Status"}, rows)
+	fmt.Println()
+}
+
+func (c *Correlator) suggestion(id string) (error, *DeviceCorrelation) {
+	n, err := strconv.Atoi(id)
+	if err != nil {
+		return err, nil
+	} else if s, found := c.suggestions[n]; found {
+		return nil, s
+	}
+	return fmt.Errorf("No pairing with ID %s, run corr first.", id), nil
+}
+
+func (c *Correlator) Confirm(id string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	err, s := c.suggestion(id)
+	if err != nil {
+		return err
+	}
+
+	s.Confirmed = true
+	s.store()
+	log.Info("confirmed %s and %s are the same device.", core.Bold(s.WiFi), core.Bold(s.BLE))
+	c.Session.Events.Add("device.correlated", *s)
+	return nil
+}
+
+func (c *Correlator) Reject(id string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	err, s := c.suggestion(id)
+	if err != nil {
+		return err
+	}
+
+	s.forget()
+	c.rejected[s.key()] = true
+	delete(c.suggestions, s.ID)
+	log.Info("pairing of %s and %s rejected.", s.WiFi, s.BLE)
+	return nil
+}
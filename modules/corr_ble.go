@@ -0,0 +1,24 @@
+// +build !windows
+// +build !darwin
+
+package modules
+
+import (
+	"github.com/bettercap/bettercap/network"
+	"github.com/bettercap/bettercap/session"
+)
+
+func corrBLEDevices(s *session.Session) (error, []corrDevice) {
+	devices := make([]corrDevice, 0)
+	for _, dev := range s.BLE.Devices() {
+		devices = append(devices, corrDevice{
+			MAC:       network.NormalizeMac(dev.Device.ID()),
+			Name:      dev.Device.Name(),
+			Vendor:    dev.Vendor,
+			FirstSeen: dev.FirstSeen,
+			LastSeen:  dev.LastSeen,
+			Meta:      dev.Meta,
+		})
+	}
+	return nil, devices
+}
@@ -0,0 +1,13 @@
+// +build windows darwin
+
+package modules
+
+import (
+	"fmt"
+
+	"github.com/bettercap/bettercap/session"
+)
+
+func corrBLEDevices(s *session.Session) (error, []corrDevice) {
+	return fmt.Errorf("BLE is not supported on this OS."), nil
+}
@@ -0,0 +1,102 @@
+package modules
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMacDistance(t *testing.T) {
+	cases := []struct {
+		a        string
+		b        string
+		sameOUI  bool
+		distance int
+	}{
+		{"aa:bb:cc:00:00:01", "aa:bb:cc:00:00:01", true, 0},
+		{"aa:bb:cc:00:00:01", "AA:BB:CC:00:00:03", true, 2},
+		{"aa:bb:cc:00:00:03", "aa:bb:cc:00:00:01", true, 2},
+		{"aa:bb:cc:00:00:ff", "aa:bb:cc:00:01:00", true, 1},
+		{"aa:bb:cc:01:00:00", "aa:bb:cc:00:00:00", true, 65536},
+		{"aa:bb:cc:00:00:01", "aa:bb:cd:00:00:01", false, -1},
+		{"aa:bb:cc:00:00:01", "not a mac", false, -1},
+		{"aa:bb:cc:00:00:00:00:01", "aa:bb:cc:00:00:00:00:01", false, -1},
+	}
+
+	for _, c := range cases {
+		if sameOUI, distance := macDistance(c.a, c.b); sameOUI != c.sameOUI || distance != c.distance {
+			t.Fatalf("%s %s: expected (%v, %d), got (%v, %d)", c.a, c.b, c.sameOUI, c.distance, sameOUI, distance)
+		}
+	}
+}
+
+func TestCorrScore(t *testing.T) {
+	now := time.Now()
+	window := time.Minute
+	later := now.Add(10 * time.Minute)
+
+	cases := []struct {
+		name    string
+		wifi    corrDevice
+		ble     corrDevice
+		score   float64
+		reasons []string
+	}{
+		{
+			"adjacent and together",
+			corrDevice{MAC: "aa:bb:cc:00:00:01", FirstSeen: now, LastSeen: now},
+			corrDevice{MAC: "aa:bb:cc:00:00:02", FirstSeen: now.Add(time.Second), LastSeen: now},
+			1.0,
+			[]string{"adjacent addresses", "appeared together", "seen together"},
+		},
+		{
+			"same OUI, seen together",
+			corrDevice{MAC: "aa:bb:cc:00:00:01", FirstSeen: now, LastSeen: later},
+			corrDevice{MAC: "aa:bb:cc:00:10:00", FirstSeen: later, LastSeen: later},
+			0.5,
+			[]string{"same OUI", "seen together"},
+		},
+		{
+			"same vendor",
+			corrDevice{MAC: "aa:bb:cc:00:00:01", Vendor: "Apple"},
+			corrDevice{MAC: "11:22:33:00:00:01", Vendor: "apple"},
+			0.3,
+			[]string{"same vendor"},
+		},
+		{
+			"co-observation only",
+			corrDevice{MAC: "aa:bb:cc:00:00:01", FirstSeen: now, LastSeen: now},
+			corrDevice{MAC: "11:22:33:00:00:01", FirstSeen: now, LastSeen: now},
+			0,
+			nil,
+		},
+		{
+			"no vendor",
+			corrDevice{MAC: "aa:bb:cc:00:00:01"},
+			corrDevice{MAC: "11:22:33:00:00:01"},
+			0,
+			nil,
+		},
+	}
+
+	for _, c := range cases {
+		score, reasons := corrScore(c.wifi, c.ble, window)
+		if math.Abs(score-c.score) > 1e-9 {
+			t.Fatalf("%s: expected score %.2f, got %.2f", c.name, c.score, score)
+		} else if strings.Join(reasons, ",") != strings.Join(c.reasons, ",") {
+			t.Fatalf("%s: expected reasons %v, got %v", c.name, c.reasons, reasons)
+		}
+	}
+}
+
+func TestWithin(t *testing.T) {
+	now := time.Now()
+	if !within(now, now.Add(time.Minute), time.Minute) || !within(now.Add(time.Minute), now, time.Minute) {
+		t.Fatal("expected the window to be inclusive in both directions")
+	} else if within(now, now.Add(time.Minute+time.Second), time.Minute) {
+		t.Fatal("expected times outside the window not to match")
+	} else if within(time.Time{}, time.Time{}, time.Minute) {
+		t.Fatal("expected unknown times not to match")
+	}
+}
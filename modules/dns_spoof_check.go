@@ -0,0 +1,147 @@
+package modules
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bettercap/bettercap/core"
+	"github.com/bettercap/bettercap/log"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+// DNSSpoofCheck is the payload of dns.spoof.confirmed and dns.spoof.bypassed
+// events.
+type DNSSpoofCheck struct {
+	Target   string   `json:"target"`
+	Domain   string   `json:"domain"`
+	Spoofed  string   `json:"spoofed"`
+	Received []string `json:"received"`
+}
+
+// dnsSpoofChecks are the (target, domain) pairs dns.spoof.check is waiting
+// for an answer of.
+type dnsSpoofChecks struct {
+	sync.Mutex
+	pending map[string]net.IP
+}
+
+func newDNSSpoofChecks() *dnsSpoofChecks {
+	return &dnsSpoofChecks{
+		pending: make(map[string]net.IP),
+	}
+}
+
+func dnsCheckKey(target net.IP, domain string) string {
+	return target.String() + "|" + strings.TrimSuffix(strings.ToLower(domain), ".")
+}
+
+func (c *dnsSpoofChecks) Add(target net.IP, domain string, spoofed net.IP) {
+	c.Lock()
+	defer c.Unlock()
+	c.pending[dnsCheckKey(target, domain)] = spoofed
+}
+
+// Take returns the spoofed address of a pending check and forgets about it.
+func (c *dnsSpoofChecks) Take(target net.IP, domain string) (net.IP, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	key := dnsCheckKey(target, domain)
+	spoofed, found := c.pending[key]
+	if found {
+		delete(c.pending, key)
+	}
+	return spoofed, found
+}
+
+func (c *dnsSpoofChecks) Empty() bool {
+	c.Lock()
+	defer c.Unlock()
+	return len(c.pending) == 0
+}
+
+// Check waits for the next answer the target gets for domain and tells if it
+// is the spoofed one.
+func (s *DNSSpoofer) Check(target string, domain string) error {
+	var err error
+	var timeout int
+
+	ip := net.ParseIP(target)
+	if ip == nil {
+		return fmt.Errorf("'%s' is not a valid IP address", target)
+	} else if !s.Running() {
+		return fmt.Errorf("dns.spoof is not running")
+	} else if err, timeout = s.IntParam("dns.spoof.check.timeout"); err != nil {
+		return err
+	}
+
+	spoofed := s.Hosts.Resolve(domain)
+	if spoofed == nil {
+		return fmt.Errorf("%s doesn't match any of the dns.spoof rules", domain)
+	}
+
+	s.checks.Add(ip, domain, spoofed)
+	log.Info("[%s] waiting up to %ds for %s to resolve %s ...", core.Green("dns.spoof"), timeout, core.Bold(target), core.Yellow(domain))
+
+	go func() {
+		time.Sleep(time.Duration(timeout) * time.Second)
+		if _, found := s.checks.Take(ip, domain); found {
+			log.Warning("[%s] %s didn't resolve %s within %ds.", core.Green("dns.spoof"), target, domain, timeout)
+		}
+	}()
+
+	return nil
+}
+
+// onCheckPacket looks for the answers to the pending checks, only the first
+// one reaching the target counts as that's the one its resolver will use.
+func (s *DNSSpoofer) onCheckPacket(pkt gopacket.Packet) {
+	if s.checks.Empty() {
+		return
+	}
+
+	eth, isEth := pkt.Layer(layers.LayerTypeEthernet).(*layers.Ethernet)
+	dns, isDNS := pkt.Layer(layers.LayerTypeDNS).(*layers.DNS)
+	nlayer := pkt.NetworkLayer()
+	if !isEth || !isDNS || !dns.QR || nlayer == nil {
+		return
+	} else if bytes.Equal(eth.DstMAC, s.Session.Interface.HW) {
+		// the real answer on its way to us, not forwarded to the target yet
+		return
+	}
+
+	target := net.IP(nlayer.NetworkFlow().Dst().Raw())
+	for _, q := range dns.Questions {
+		spoofed, found := s.checks.Take(target, string(q.Name))
+		if !found {
+			continue
+		}
+
+		check := DNSSpoofCheck{
+			Target:   target.String(),
+			Domain:   string(q.Name),
+			Spoofed:  spoofed.String(),
+			Received: make([]string, 0),
+		}
+
+		confirmed := false
+		for _, a := range dns.Answers {
+			if a.IP != nil {
+				check.Received = append(check.Received, a.IP.String())
+				confirmed = confirmed || a.IP.Equal(spoofed)
+			}
+		}
+
+		if confirmed {
+			s.Session.Events.Add("dns.spoof.confirmed", check)
+		} else {
+			s.Session.Events.Add("dns.spoof.bypassed", check)
+		}
+	}
+}
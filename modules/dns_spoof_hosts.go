@@ -73,11 +73,19 @@ func HostsFromFile(filename string) (err error, entries []HostEntry) {
 	return
 }
 
-func (h Hosts) Resolve(host string) net.IP {
-	for _, entry := range h {
-		if entry.Matches(host) {
-			return entry.Address
+// Find returns the first entry matching host, or nil.
+func (h Hosts) Find(host string) *HostEntry {
+	for i := range h {
+		if h[i].Matches(host) {
+			return &h[i]
 		}
 	}
 	return nil
 }
+
+func (h Hosts) Resolve(host string) net.IP {
+	if entry := h.Find(host); entry != nil {
+		return entry.Address
+	}
+	return nil
+}
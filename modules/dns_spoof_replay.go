@@ -0,0 +1,115 @@
+package modules
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/bettercap/bettercap/core"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcap"
+)
+
+func dnsAnswersString(answers []layers.DNSResourceRecord) string {
+	parts := make([]string, 0, len(answers))
+	for _, a := range answers {
+		parts = append(parts, fmt.Sprintf("%s %s %s", string(a.Name), a.Type, a.IP))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Test runs the DNS queries found in fileName through the spoofing rules and
+// prints what the module would do with each one of them.
+func (s *DNSSpoofer) Test(fileName string) error {
+	fileName, err := core.ExpandPath(fileName)
+	if err != nil {
+		return err
+	}
+
+	// when running we don't want to swap the rules under its feet
+	if !s.Running() {
+		if err = s.loadRules(); err != nil {
+			return err
+		}
+	}
+
+	handle, err := pcap.OpenOffline(fileName)
+	if err != nil {
+		return err
+	}
+	defer handle.Close()
+
+	rows := make([][]string, 0)
+	queries, spoofed := 0, 0
+
+	src := gopacket.NewPacketSource(handle, handle.LinkType())
+	for {
+		pkt, err := src.NextPacket()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			// read errors are returned again on every call, never loop on them
+			return fmt.Errorf("error while reading %s: %s", fileName, err)
+		}
+
+		eth, _, dns, ok := parseDNSQuery(pkt)
+		if !ok {
+			continue
+		}
+
+		queries++
+
+		from := eth.SrcMAC.String()
+		if nlayer := pkt.NetworkLayer(); nlayer != nil {
+			from = net.IP(nlayer.NetworkFlow().Src().Raw()).String()
+		}
+
+		questions := make([]string, 0, len(dns.Questions))
+		for _, q := range dns.Questions {
+			questions = append(questions, fmt.Sprintf("%s %s", string(q.Name), q.Type))
+		}
+
+		rule := ""
+		action := core.Dim("forward")
+		answer := ""
+
+		if !s.inScope(pkt, eth) {
+			action = core.Dim("skip (not a target)")
+		} else if qName, address := s.resolveQuery(dns); address != nil {
+			rule = s.Hosts.Find(qName).Host
+			if s.All || bytes.Equal(eth.DstMAC, s.Session.Interface.HW) {
+				spoofed++
+				action = core.Red("spoof")
+				answer = dnsAnswersString(buildDNSReply(dns, address).Answers)
+			} else {
+				action = core.Yellow("skip (not for us, see dns.spoof.all)")
+			}
+		}
+
+		rows = append(rows, []string{
+			pkt.Metadata().Timestamp.Format("15:04:05"),
+			from,
+			strings.Join(questions, ", "),
+			rule,
+			action,
+			answer,
+		})
+	}
+
+	if queries == 0 {
+		return fmt.Errorf("no DNS queries found in %s", fileName)
+	}
+
+	fmt.Println()
+	core.AsTable(os.Stdout, []string{"Time", "Source", "Query", "Rule", "Action", "Answer"}, rows)
+	fmt.Printf("\n%d queries, %d would be spoofed.\n\n", queries, spoofed)
+
+	s.Session.Refresh()
+
+	return nil
+}
@@ -0,0 +1,458 @@
+package modules
+
+import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bettercap/bettercap/core"
+	"github.com/bettercap/bettercap/log"
+	"github.com/bettercap/bettercap/packets"
+	"github.com/bettercap/bettercap/session"
+)
+
+const (
+	mqttKeepAlive  = 60 * time.Second
+	mqttTimeout    = 10 * time.Second
+	mqttMinBackoff = 1 * time.Second
+	mqttMaxBackoff = 60 * time.Second
+)
+
+// MQTTEvents publishes the session events to an MQTT broker, as QoS 0
+// messages whose topic is the event tag with dots replaced by slashes.
+type MQTTEvents struct {
+	session.SessionModule
+	broker     string
+	topic      string
+	clientID   string
+	username   string
+	password   string
+	tlsConfig  *tls.Config
+	ignoreList *IgnoreList
+	queue      chan session.Event
+	dropped    uint64
+	quit       chan bool
+	waitGroup  *sync.WaitGroup
+}
+
+func NewMQTTEvents(s *session.Session) *MQTTEvents {
+	m := &MQTTEvents{
+		SessionModule: session.NewSessionModule("events.mqtt", s),
+		waitGroup:     &sync.WaitGroup{},
+	}
+
+	m.AddParam(session.NewStringParameter("events.mqtt.broker",
+		"",
+		"",
+		"Address of the MQTT broker as HOST:PORT, tcp://HOST:PORT or ssl://HOST:PORT."))
+
+	m.AddParam(session.NewStringParameter("events.mqtt.topic",
+		"bettercap",
+		"",
+		"Topic prefix, the tag of each event is appended to it with its dots replaced by slashes."))
+
+	m.AddParam(session.NewStringParameter("events.mqtt.client.id",
+		"",
+		"",
+		"MQTT client identifier, if empty bettercap-PID will be used."))
+
+	m.AddParam(session.NewStringParameter("events.mqtt.username",
+		"",
+		"",
+		"MQTT username, if empty no credentials will be sent."))
+
+	m.AddParam(session.NewStringParameter("events.mqtt.password",
+		"",
+		"",
+		"MQTT password."))
+
+	m.AddParam(session.NewBoolParameter("events.mqtt.tls",
+		"false",
+		"If true, connect to the broker over TLS (implied by a ssl:// broker)."))
+
+	m.AddParam(session.NewStringParameter("events.mqtt.tls.ca",
+		"",
+		"",
+		"PEM file of the certificate authority to verify the broker with, if empty the system ones are used."))
+
+	m.AddParam(session.NewStringParameter("events.mqtt.tls.certificate",
+		"",
+		"",
+		"PEM file of the client certificate, if the broker requires one."))
+
+	m.AddParam(session.NewStringParameter("events.mqtt.tls.key",
+		"",
+		"",
+		"PEM file of the client certificate key."))
+
+	m.AddParam(session.NewBoolParameter("events.mqtt.tls.insecure",
+		"false",
+		"If true, the certificate of the broker is not verified."))
+
+	m.AddParam(session.NewStringParameter("events.mqtt.ignore",
+		"sys.log",
+		"",
+		"Comma separated list of event tags, or tag prefixes, not to publish."))
+
+	m.AddParam(session.NewIntParameter("events.mqtt.queue",
+		"1000",
+		"How many events to keep while the broker is slow or unreachable, newer events are dropped when full."))
+
+	m.AddHandler(session.NewModuleHandler("events.mqtt on", "",
+		"Start publishing events to the MQTT broker.",
+		func(args []string) error {
+			return m.Start()
+		}))
+
+	m.AddHandler(session.NewModuleHandler("events.mqtt off", "",
+		"Stop publishing events to the MQTT broker.",
+		func(args []string) error {
+			return m.Stop()
+		}))
+
+	return m
+}
+
+func (m MQTTEvents) Name() string {
+	return "events.mqtt"
+}
+
+func (m MQTTEvents) Description() string {
+	return "Publishes the session events to an MQTT broker."
+}
+
+func (m MQTTEvents) Author() string {
+	return "Simone Margaritelli <evilsocket@protonmail.com>"
+}
+
+// parseMQTTBroker returns the address to dial and whether the scheme asks
+// for TLS, using the default ports if missing.
+func parseMQTTBroker(broker string) (error, string, bool) {
+	useTLS := false
+	if idx := strings.Index(broker, "://"); idx != -1 {
+		switch scheme := strings.ToLower(broker[:idx]); scheme {
+		case "tcp", "mqtt":
+		case "ssl", "tls", "mqtts":
+			useTLS = true
+		default:
+			return fmt.Errorf("unsupported MQTT broker scheme '%s'", scheme), "", false
+		}
+		broker = broker[idx+3:]
+	}
+
+	broker = strings.TrimRight(broker, "/")
+	if broker == "" {
+		return fmt.Errorf("events.mqtt.broker is empty"), "", false
+	} else if _, _, err := net.SplitHostPort(broker); err != nil {
+		port := "1883"
+		if useTLS {
+			port = "8883"
+		}
+		broker = net.JoinHostPort(broker, port)
+	}
+
+	return nil, broker, useTLS
+}
+
+// mqttTopic maps an event tag into the topic path, wildcards are not valid
+// in the topic of a published message.
+func mqttTopic(prefix string, tag string) string {
+	path := strings.NewReplacer(".", "/", "+", "_", "#", "_").Replace(tag)
+	if prefix = strings.Trim(prefix, "/"); prefix != "" {
+		return prefix + "/" + path
+	}
+	return path
+}
+
+func (m *MQTTEvents) configureTLS(address string) error {
+	var err error
+	var ca, cert, key string
+	var insecure bool
+
+	if err, ca = m.StringParam("events.mqtt.tls.ca"); err != nil {
+		return err
+	} else if err, cert = m.StringParam("events.mqtt.tls.certificate"); err != nil {
+		return err
+	} else if err, key = m.StringParam("events.mqtt.tls.key"); err != nil {
+		return err
+	} else if err, insecure = m.BoolParam("events.mqtt.tls.insecure"); err != nil {
+		return err
+	}
+
+	host, _, _ := net.SplitHostPort(address)
+	m.tlsConfig = &tls.Config{
+		ServerName:         host,
+		InsecureSkipVerify: insecure,
+	}
+
+	if ca != "" {
+		if ca, err = core.ExpandPath(ca); err != nil {
+			return err
+		}
+		raw, err := ioutil.ReadFile(ca)
+		if err != nil {
+			return err
+		}
+		m.tlsConfig.RootCAs = x509.NewCertPool()
+		if !m.tlsConfig.RootCAs.AppendCertsFromPEM(raw) {
+			return fmt.Errorf("no certificates found in %s", ca)
+		}
+	}
+
+	if cert != "" || key != "" {
+		if cert, err = core.ExpandPath(cert); err != nil {
+			return err
+		} else if key, err = core.ExpandPath(key); err != nil {
+			return err
+		}
+		pair, err := tls.LoadX509KeyPair(cert, key)
+		if err != nil {
+			return err
+		}
+		m.tlsConfig.Certificates = []tls.Certificate{pair}
+	}
+
+	return nil
+}
+
+func (m *MQTTEvents) Configure() error {
+	var err error
+	var broker, ignore string
+	var useTLS, schemeTLS bool
+	var queue int
+
+	if m.Running() {
+		return session.ErrAlreadyStarted
+	} else if err, broker = m.StringParam("events.mqtt.broker"); err != nil {
+		return err
+	} else if err, m.broker, schemeTLS = parseMQTTBroker(broker); err != nil {
+		return err
+	} else if err, m.topic = m.StringParam("events.mqtt.topic"); err != nil {
+		return err
+	} else if err, m.clientID = m.StringParam("events.mqtt.client.id"); err != nil {
+		return err
+	} else if err, m.username = m.StringParam("events.mqtt.username"); err != nil {
+		return err
+	} else if err, m.password = m.StringParam("events.mqtt.password"); err != nil {
+		return err
+	} else if err, useTLS = m.BoolParam("events.mqtt.tls"); err != nil {
+		return err
+	} else if err, ignore = m.StringParam("events.mqtt.ignore"); err != nil {
+		return err
+	} else if err, queue = m.IntParam("events.mqtt.queue"); err != nil {
+		return err
+	} else if queue < 1 {
+		return fmt.Errorf("events.mqtt.queue must be greater than 0")
+	}
+
+	m.tlsConfig = nil
+	if useTLS || schemeTLS {
+		if err = m.configureTLS(m.broker); err != nil {
+			return err
+		}
+	}
+
+	if m.clientID == "" {
+		m.clientID = fmt.Sprintf("bettercap-%d", os.Getpid())
+	}
+
+	m.ignoreList = NewIgnoreList()
+	for _, filter := range core.CommaSplit(ignore) {
+		if err = m.ignoreList.Add(filter); err != nil {
+			return err
+		}
+	}
+
+	m.queue = make(chan session.Event, queue)
+	m.quit = make(chan bool)
+	atomic.StoreUint64(&m.dropped, 0)
+
+	return nil
+}
+
+// connect dials the broker and waits for it to accept the session.
+func (m *MQTTEvents) connect() (net.Conn, error) {
+	var conn net.Conn
+	var err error
+
+	dialer := &net.Dialer{Timeout: mqttTimeout}
+	if m.tlsConfig != nil {
+		conn, err = tls.DialWithDialer(dialer, "tcp", m.broker, m.tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", m.broker)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err, raw := packets.NewMQTTConnect(m.clientID, m.username, m.password, uint16(mqttKeepAlive.Seconds()))
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	conn.SetDeadline(time.Now().Add(mqttTimeout))
+	if _, err = conn.Write(raw); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	err, kind, body := packets.ReadMQTTPacket(bufio.NewReader(conn))
+	if err == nil && kind != packets.MQTTConnAck {
+		err = fmt.Errorf("expected a CONNACK, got packet type %d", kind)
+	} else if err == nil {
+		err = packets.MQTTConnAckError(body)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	conn.SetDeadline(time.Time{})
+	return conn, nil
+}
+
+func (m *MQTTEvents) write(conn net.Conn, raw []byte) error {
+	conn.SetWriteDeadline(time.Now().Add(mqttTimeout))
+	_, err := conn.Write(raw)
+	return err
+}
+
+// publish sends the queued events until the connection fails or the
+// module is stopped.
+func (m *MQTTEvents) publish(conn net.Conn) error {
+	failed := make(chan error, 1)
+	go func() {
+		// the broker only sends us PINGRESPs, which are just a sign of life
+		reader := bufio.NewReader(conn)
+		for {
+			if err, _, _ := packets.ReadMQTTPacket(reader); err != nil {
+				failed <- err
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(mqttKeepAlive / 2)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-m.quit:
+			m.write(conn, packets.NewMQTTDisconnect())
+			return nil
+
+		case err := <-failed:
+			return err
+
+		case <-ping.C:
+			if err := m.write(conn, packets.NewMQTTPingReq()); err != nil {
+				return err
+			}
+
+		case e := <-m.queue:
+			payload, err := json.Marshal(e)
+			if err != nil {
+				log.Debug("could not encode event %s: %s", e.Tag, err)
+				continue
+			}
+
+			err, raw := packets.NewMQTTPublish(mqttTopic(m.topic, e.Tag), payload)
+			if err != nil {
+				log.Debug("could not publish event %s: %s", e.Tag, err)
+				continue
+			} else if err = m.write(conn, raw); err != nil {
+				// try again once reconnected
+				m.enqueue(e)
+				return err
+			}
+		}
+	}
+}
+
+func (m *MQTTEvents) publisher() {
+	defer m.waitGroup.Done()
+
+	backoff := mqttMinBackoff
+	for {
+		conn, err := m.connect()
+		if err != nil {
+			log.Warning("could not connect to the MQTT broker %s: %s, retrying in %s.", m.broker, err, backoff)
+			select {
+			case <-m.quit:
+				return
+			case <-time.After(backoff):
+			}
+
+			if backoff *= 2; backoff > mqttMaxBackoff {
+				backoff = mqttMaxBackoff
+			}
+			continue
+		}
+
+		log.Info("publishing events to the MQTT broker %s.", core.Bold(m.broker))
+		backoff = mqttMinBackoff
+		err = m.publish(conn)
+		conn.Close()
+
+		if err == nil {
+			return
+		}
+		log.Warning("lost the connection to the MQTT broker %s: %s", m.broker, err)
+	}
+}
+
+// enqueue never blocks the events pool, if the queue is full the event is
+// dropped.
+func (m *MQTTEvents) enqueue(e session.Event) {
+	select {
+	case m.queue <- e:
+	default:
+		if n := atomic.AddUint64(&m.dropped, 1); n%1000 == 1 {
+			log.Warning("the MQTT queue is full, %d events dropped so far.", n)
+		}
+	}
+}
+
+func (m *MQTTEvents) Start() error {
+	if err := m.Configure(); err != nil {
+		return err
+	}
+
+	return m.SetRunning(true, func() {
+		listener := m.Session.Events.Listen()
+		defer m.Session.Events.Unlisten(listener)
+
+		m.waitGroup.Add(1)
+		go m.publisher()
+
+		// the listener gets the buffered events first, only publish new ones
+		started := time.Now()
+		for {
+			select {
+			case e := <-listener:
+				if !e.Historical && !e.Time.Before(started) && !m.ignoreList.Ignored(e) {
+					m.enqueue(e)
+				}
+			case <-m.quit:
+				return
+			}
+		}
+	})
+}
+
+func (m *MQTTEvents) Stop() error {
+	return m.SetRunning(false, func() {
+		close(m.quit)
+		m.waitGroup.Wait()
+	})
+}
@@ -14,6 +14,7 @@ import (
 type EventsStream struct {
 	session.SessionModule
 	output        *os.File
+	format        string
 	ignoreList    *IgnoreList
 	waitFor       string
 	waitChan      chan *session.Event
@@ -25,6 +26,7 @@ func NewEventsStream(s *session.Session) *EventsStream {
 	stream := &EventsStream{
 		SessionModule: session.NewSessionModule("events.stream", s),
 		output:        os.Stdout,
+		format:        "text",
 		quit:          make(chan bool),
 		waitChan:      make(chan *session.Event),
 		waitFor:       "",
@@ -100,6 +102,18 @@ func NewEventsStream(s *session.Session) *EventsStream {
 			return nil
 		}))
 
+	stream.AddHandler(session.NewModuleHandler("events.save FILE", `events\.save ([^\s]+)`,
+		"Save the buffered events to FILE, as JSON lines unless events.save.format says otherwise.",
+		func(args []string) error {
+			return stream.save(args[0])
+		}))
+
+	stream.AddHandler(session.NewModuleHandler("events.load FILE", `events\.load ([^\s]+)`,
+		"Load events saved with events.save as historical events, they can be reviewed but won't trigger anything.",
+		func(args []string) error {
+			return stream.load(args[0])
+		}))
+
 	stream.AddHandler(session.NewModuleHandler("events.clear", "",
 		"Clear events stream.",
 		func(args []string) error {
@@ -112,6 +126,16 @@ func NewEventsStream(s *session.Session) *EventsStream {
 		"",
 		"If not empty, events will be written to this file instead of the standard output."))
 
+	stream.AddParam(session.NewStringParameter("events.stream.format",
+		"text",
+		"^(text|json|cef|leef)$",
+		"Format of the streamed events, text for the human readable output or one of json, cef (ArcSight) and leef (QRadar) for SIEM ingestion."))
+
+	stream.AddParam(session.NewStringParameter("events.save.format",
+		session.EventFormatJSON,
+		"^(json|cef|leef)$",
+		"Format of the files written by events.save, one of json, cef (ArcSight) or leef (QRadar), only json files can be loaded back with events.load."))
+
 	return stream
 }
 
@@ -130,6 +154,10 @@ func (s EventsStream) Author() string {
 func (s *EventsStream) Configure() (err error) {
 	var output string
 
+	if err, s.format = s.StringParam("events.stream.format"); err != nil {
+		return err
+	}
+
 	if err, output = s.StringParam("events.stream.output"); err == nil {
 		if output == "" {
 			s.output = os.Stdout
@@ -154,13 +182,13 @@ func (s *EventsStream) Start() error {
 			var e session.Event
 			select {
 			case e = <-s.eventListener:
-				if e.Tag == s.waitFor {
+				if e.Tag == s.waitFor && !e.Historical {
 					s.waitFor = ""
 					s.waitChan <- &e
 				}
 
 				if !s.ignoreList.Ignored(e) {
-					s.View(e, true)
+					s.emit(e)
 				} else {
 					log.Debug("skipping ignored event %v", e)
 				}
@@ -172,6 +200,17 @@ func (s *EventsStream) Start() error {
 	})
 }
 
+// emit writes the event to the stream output in the selected format.
+func (s *EventsStream) emit(e session.Event) {
+	if s.format == "text" {
+		s.View(e, true)
+	} else if err, line := e.Format(s.format); err != nil {
+		log.Debug("could not format event %s: %s", e.Tag, err)
+	} else {
+		fmt.Fprintln(s.output, line)
+	}
+}
+
 func (s *EventsStream) Show(limit int) error {
 	events := s.Session.Events.Sorted()
 	num := len(events)
@@ -195,6 +234,37 @@ func (s *EventsStream) Show(limit int) error {
 	return nil
 }
 
+func (s *EventsStream) save(fileName string) error {
+	fileName, err := core.ExpandPath(fileName)
+	if err != nil {
+		return err
+	}
+
+	err, format := s.StringParam("events.save.format")
+	if err != nil {
+		return err
+	}
+
+	err, n := s.Session.Events.SaveAs(fileName, format)
+	if err == nil {
+		log.Info("saved %d events to %s as %s.", n, core.Bold(fileName), format)
+	}
+	return err
+}
+
+func (s *EventsStream) load(fileName string) error {
+	fileName, err := core.ExpandPath(fileName)
+	if err != nil {
+		return err
+	}
+
+	err, n := s.Session.Events.Load(fileName)
+	if err == nil {
+		log.Info("loaded %d historical events from %s.", n, core.Bold(fileName))
+	}
+	return err
+}
+
 func (s *EventsStream) startWaitingFor(tag string, timeout int) error {
 	if timeout == 0 {
 		log.Info("waiting for event %s ...", core.Green(tag))
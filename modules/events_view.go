@@ -1,14 +1,17 @@
 package modules
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/bettercap/bettercap/core"
 	"github.com/bettercap/bettercap/network"
 	"github.com/bettercap/bettercap/session"
 
+	"github.com/dustin/go-humanize"
 	"github.com/google/go-github/github"
 )
 
@@ -23,7 +26,9 @@ func (s *EventsStream) viewLogEvent(e session.Event) {
 }
 
 func (s *EventsStream) viewWiFiEvent(e session.Event) {
-	if strings.HasPrefix(e.Tag, "wifi.ap.") {
+	if e.Tag == "wifi.ap.channel.changed" {
+		s.viewWiFiChannelChangeEvent(e)
+	} else if strings.HasPrefix(e.Tag, "wifi.ap.") {
 		ap := e.Data.(*network.AccessPoint)
 		vend := ""
 		if ap.Vendor != "" {
@@ -48,12 +53,70 @@ func (s *EventsStream) viewWiFiEvent(e session.Event) {
 				core.Green(e.Tag),
 				core.Red(ap.ESSID()),
 				ap.BSSID())
+		} else if e.Tag == "wifi.ap.uncloaked" {
+			fmt.Fprintf(s.output, "[%s] [%s] hidden wifi access point %s is %s.\n",
+				e.Time.Format(eventTimeFormat),
+				core.Green(e.Tag),
+				ap.BSSID(),
+				core.Bold(ap.ESSID()))
+		} else if e.Tag == "wifi.ap.crowded" {
+			fmt.Fprintf(s.output, "[%s] [%s] wifi access point %s (%s) now has %s clients.\n",
+				e.Time.Format(eventTimeFormat),
+				core.Green(e.Tag),
+				core.Bold(ap.ESSID()),
+				ap.BSSID(),
+				core.Yellow(fmt.Sprintf("%d", ap.NumClients())))
 		} else {
 			fmt.Fprintf(s.output, "[%s] [%s] %s\n",
 				e.Time.Format(eventTimeFormat),
 				core.Green(e.Tag),
 				ap.String())
 		}
+	} else if e.Tag == "wifi.deauth.stats" {
+		stats := e.Data.(WiFiDeauthStats)
+		fmt.Fprintf(s.output, "[%s] [%s] sent %d deauth frames in %s (%s frames/s, batch %d, %d errors)\n",
+			e.Time.Format(eventTimeFormat),
+			core.Green(e.Tag),
+			stats.Frames,
+			stats.Duration.Round(time.Millisecond),
+			core.Bold(fmt.Sprintf("%.1f", stats.FPS)),
+			stats.Batch,
+			stats.Errors)
+	} else if e.Tag == "wifi.deauth.targets" {
+		targets := e.Data.([]WiFiDeauthTarget)
+		aps := make(map[string]bool)
+		for _, t := range targets {
+			aps[t.AP] = true
+		}
+		fmt.Fprintf(s.output, "[%s] [%s] deauthing %s clients of %d access points\n",
+			e.Time.Format(eventTimeFormat),
+			core.Green(e.Tag),
+			core.Bold(fmt.Sprintf("%d", len(targets))),
+			len(aps))
+	} else if e.Tag == "wifi.deauth.progress" {
+		progress := e.Data.(WiFiDeauthProgress)
+		fmt.Fprintf(s.output, "[%s] [%s] burst %d on %s (%s, channel %d): %s of %d clients captured\n",
+			e.Time.Format(eventTimeFormat),
+			core.Green(e.Tag),
+			progress.Bursts,
+			core.Bold(progress.ESSID),
+			progress.AP,
+			progress.Channel,
+			core.Yellow(fmt.Sprintf("%d", progress.Captured)),
+			progress.Clients)
+	} else if e.Tag == "wifi.client.handshake" {
+		hs := e.Data.(WiFiHandshake)
+		what := "handshake"
+		if hs.PMKID {
+			what = "PMKID"
+		}
+		fmt.Fprintf(s.output, "[%s] [%s] captured the %s of station %s with %s (%s)\n",
+			e.Time.Format(eventTimeFormat),
+			core.Green(e.Tag),
+			what,
+			hs.Client.String(),
+			core.Bold(hs.ESSID),
+			hs.AP.String())
 	} else if e.Tag == "wifi.client.probe" {
 		probe := e.Data.(WiFiProbe)
 		desc := ""
@@ -74,6 +137,30 @@ func (s *EventsStream) viewWiFiEvent(e session.Event) {
 			core.Dim(desc),
 			core.Bold(probe.SSID),
 			core.Yellow(rssi))
+	} else if e.Tag == "wifi.client.identity" {
+		id := e.Data.(WiFiClientIdentity)
+		desc := ""
+		if id.ClientVendor != "" {
+			desc = fmt.Sprintf(" (%s)", id.ClientVendor)
+		}
+		identity := core.Bold(id.Identity)
+		if id.Anonymous {
+			identity = core.Dim("anonymous outer identity ") + identity
+		}
+		method := ""
+		if id.Method != "" {
+			method = fmt.Sprintf(" using %s", core.Yellow(id.Method))
+		}
+
+		fmt.Fprintf(s.output, "[%s] [%s] station %s%s sent EAP identity %s to %s (%s)%s\n",
+			e.Time.Format(eventTimeFormat),
+			core.Green(e.Tag),
+			id.Client.String(),
+			core.Dim(desc),
+			identity,
+			id.ESSID,
+			id.AP.String(),
+			method)
 	}
 }
 
@@ -100,6 +187,39 @@ func (s *EventsStream) viewendpointEvent(e session.Event) {
 			core.Dim(name),
 			core.Green(t.HwAddress),
 			core.Dim(vend))
+	} else if e.Tag == "net.recon.os" && t.OSGuess != nil {
+		fmt.Fprintf(s.output, "[%s] [%s] endpoint %s%s is probably running %s.\n",
+			e.Time.Format(eventTimeFormat),
+			core.Green(e.Tag),
+			core.Bold(t.IpAddress),
+			core.Dim(name),
+			core.Yellow(t.OSGuess.String()))
+	} else if e.Tag == "net.recon.classified" && t.DeviceClass != nil {
+		fmt.Fprintf(s.output, "[%s] [%s] endpoint %s%s is probably a %s %s.\n",
+			e.Time.Format(eventTimeFormat),
+			core.Green(e.Tag),
+			core.Bold(t.IpAddress),
+			core.Dim(name),
+			core.Yellow(t.DeviceClass.String()),
+			core.Dim("("+strings.Join(t.DeviceClass.Reasons, ", ")+")"))
+	} else if e.Tag == "net.recon.upnp" {
+		what := ""
+		if model := t.Meta.Get("upnp:modelName").(string); model != "" {
+			what = model
+			if manuf := t.Meta.Get("upnp:manufacturer").(string); manuf != "" {
+				what = manuf + " " + model
+			}
+		} else if server := t.Meta.Get("upnp:Server").(string); server != "" {
+			what = server
+		} else {
+			what = "UPnP device"
+		}
+		fmt.Fprintf(s.output, "[%s] [%s] endpoint %s%s is a %s.\n",
+			e.Time.Format(eventTimeFormat),
+			core.Green(e.Tag),
+			core.Bold(t.IpAddress),
+			core.Dim(name),
+			core.Yellow(what))
 	} else if e.Tag == "endpoint.lost" {
 		fmt.Fprintf(s.output, "[%s] [%s] endpoint %s%s lost.\n",
 			e.Time.Format(eventTimeFormat),
@@ -121,6 +241,98 @@ func (s *EventsStream) viewModuleEvent(e session.Event) {
 		e.Data)
 }
 
+func (s *EventsStream) viewDNSQueryEvent(e session.Event) {
+	q := e.Data.(DNSQuery)
+
+	who := q.ClientIP
+	if t, found := s.Session.Lan.Get(q.ClientMAC); found {
+		who = t.String()
+	}
+
+	fmt.Fprintf(s.output, "[%s] [%s] %s is resolving %s %s\n",
+		e.Time.Format(eventTimeFormat),
+		core.Green(e.Tag),
+		core.Bold(who),
+		core.Yellow(q.Name),
+		core.Dim(q.Type))
+}
+
+func (s *EventsStream) viewDNSSpoofCheckEvent(e session.Event) {
+	check := e.Data.(DNSSpoofCheck)
+
+	result := core.Green("the spoofed answer")
+	if e.Tag == "dns.spoof.bypassed" {
+		result = core.Red("the real answer")
+	}
+
+	fmt.Fprintf(s.output, "[%s] [%s] %s resolved %s with %s %s\n",
+		e.Time.Format(eventTimeFormat),
+		core.Green(e.Tag),
+		core.Bold(check.Target),
+		core.Yellow(check.Domain),
+		result,
+		core.Dim(fmt.Sprintf("%v", check.Received)))
+}
+
+func (s *EventsStream) viewWiFiChannelChangeEvent(e session.Event) {
+	change := e.Data.(WiFiChannelChange)
+
+	fmt.Fprintf(s.output, "[%s] [%s] %s (%s) moved from channel %d to %s %s\n",
+		e.Time.Format(eventTimeFormat),
+		core.Green(e.Tag),
+		core.Bold(change.ESSID),
+		core.Dim(change.BSSID),
+		change.From,
+		core.Bold(fmt.Sprintf("%d", change.To)),
+		core.Dim(change.Reason))
+}
+
+func (s *EventsStream) viewNameSpoofEvent(e session.Event) {
+	p := e.Data.(NameSpoofPoisoned)
+
+	fmt.Fprintf(s.output, "[%s] [%s] %s resolved %s to %s with %s\n",
+		e.Time.Format(eventTimeFormat),
+		core.Green(e.Tag),
+		core.Bold(p.Client),
+		core.Yellow(p.Name),
+		core.Red(p.Address.String()),
+		core.Dim(p.Protocol))
+}
+
+func (s *EventsStream) viewAPIRequestEvent(e session.Event) {
+	req := e.Data.(APIRequest)
+
+	fmt.Fprintf(s.output, "[%s] [%s] %s\n",
+		e.Time.Format(eventTimeFormat),
+		core.Green(e.Tag),
+		req.Format("{remote} {user} {event} {method} {path} {status} {latency}"))
+}
+
+func (s *EventsStream) viewSocksEvent(e session.Event) {
+	c := e.Data.(SocksConnection)
+
+	who := c.Client
+	if c.Username != "" {
+		who = fmt.Sprintf("%s@%s", c.Username, c.Client)
+	}
+
+	fmt.Fprintf(s.output, "[%s] [%s] %s connected to %s\n",
+		e.Time.Format(eventTimeFormat),
+		core.Green(e.Tag),
+		core.Bold(who),
+		core.Yellow(c.Destination))
+}
+
+func (s *EventsStream) viewModulePanicEvent(e session.Event) {
+	crash := e.Data.(session.ModulePanic)
+
+	fmt.Fprintf(s.output, "[%s] [%s] module %s crashed and has been stopped: %s\n",
+		e.Time.Format(eventTimeFormat),
+		core.Bold(core.Red(e.Tag)),
+		core.Bold(crash.Module),
+		crash.Error)
+}
+
 func (s *EventsStream) viewSnifferEvent(e session.Event) {
 	if strings.HasPrefix(e.Tag, "net.sniff.http.") {
 		s.viewHttpEvent(e)
@@ -141,6 +353,260 @@ func (s *EventsStream) viewSynScanEvent(e session.Event) {
 		core.Bold(se.Address))
 }
 
+func (s *EventsStream) viewSynScanSummaryEvent(e session.Event) {
+	sum := e.Data.(SynScanSummary)
+	fmt.Fprintf(s.output, "[%s] [%s] scanned %d ports on %d addresses: %s open, %d closed, %d filtered, %d needed up to %d retries (%d probes)\n",
+		e.Time.Format(eventTimeFormat),
+		core.Green(e.Tag),
+		sum.Ports,
+		sum.Addresses,
+		core.Bold(fmt.Sprintf("%d", sum.Open)),
+		sum.Closed,
+		sum.Filtered,
+		sum.Retried,
+		sum.Retries,
+		sum.Probes)
+}
+
+func (s *EventsStream) viewSynScanDiffEvent(e session.Event) {
+	diff := e.Data.(SynScanDiff)
+	opened, closed := 0, 0
+	for _, h := range diff.Hosts {
+		opened += len(h.Opened)
+		closed += len(h.Closed)
+	}
+
+	fmt.Fprintf(s.output, "[%s] [%s] %s -> %s: %d hosts changed, %s ports opened, %d closed\n",
+		e.Time.Format(eventTimeFormat),
+		core.Green(e.Tag),
+		diff.Old,
+		diff.New,
+		len(diff.Hosts),
+		core.Bold(fmt.Sprintf("%d", opened)),
+		closed)
+}
+
+func (s *EventsStream) viewArpSpoofVerifyEvent(e session.Event) {
+	v := e.Data.(ArpSpoofVerification)
+	status := core.Green("is poisoned")
+	if !v.Poisoned {
+		status = core.Red("is not poisoned")
+	}
+
+	fmt.Fprintf(s.output, "[%s] [%s] %s (%s) %s\n",
+		e.Time.Format(eventTimeFormat),
+		core.Green(e.Tag),
+		core.Bold(v.Address),
+		core.Dim(v.MAC),
+		status)
+}
+
+func (s *EventsStream) viewInspectEvent(e session.Event) {
+	report := e.Data.(InspectReport)
+	ports := make([]string, 0, len(report.OpenPorts))
+	for _, port := range report.OpenPorts {
+		ports = append(ports, fmt.Sprintf("%d", port))
+	}
+
+	open := "no open ports"
+	if len(ports) > 0 {
+		open = "open ports " + strings.Join(ports, ", ")
+	}
+
+	fmt.Fprintf(s.output, "[%s] [%s] inspected %s (%s): %s\n",
+		e.Time.Format(eventTimeFormat),
+		core.Green(e.Tag),
+		core.Bold(report.Endpoint.IpAddress),
+		core.Dim(report.Endpoint.HwAddress),
+		core.Yellow(open))
+}
+
+func (s *EventsStream) viewCaptiveEvent(e session.Event) {
+	client := e.Data.(CaptiveClient)
+	name := ""
+	if client.Endpoint != nil {
+		name = fmt.Sprintf(" (%s)", client.Endpoint.HwAddress)
+		if client.Endpoint.Hostname != "" {
+			name = fmt.Sprintf(" (%s)", client.Endpoint.Hostname)
+		}
+	}
+
+	fmt.Fprintf(s.output, "[%s] [%s] %s%s hit the captive portal requesting %s%s (%s)\n",
+		e.Time.Format(eventTimeFormat),
+		core.Green(e.Tag),
+		core.Bold(client.Address),
+		core.Dim(name),
+		core.Yellow(client.Host),
+		client.Path,
+		core.Dim(client.UserAgent))
+}
+
+func (s *EventsStream) viewTemplateEvent(e session.Event) {
+	served := e.Data.(TemplateServed)
+	name := ""
+	if served.Endpoint != nil {
+		name = fmt.Sprintf(" (%s)", served.Endpoint.HwAddress)
+		if served.Endpoint.Hostname != "" {
+			name = fmt.Sprintf(" (%s)", served.Endpoint.Hostname)
+		}
+	}
+
+	fmt.Fprintf(s.output, "[%s] [%s] %s%s got %s rendered from %s with token %s\n",
+		e.Time.Format(eventTimeFormat),
+		core.Green(e.Tag),
+		core.Bold(served.Address),
+		core.Dim(name),
+		core.Yellow(served.Path),
+		served.Template,
+		core.Dim(served.Token))
+}
+
+func (s *EventsStream) viewDryRunEvent(e session.Event) {
+	frame := e.Data.(session.DryRunFrame)
+
+	fmt.Fprintf(s.output, "[%s] [%s] not sending %d bytes: %s\n",
+		e.Time.Format(eventTimeFormat),
+		core.Yellow(e.Tag),
+		frame.Size,
+		frame.Summary)
+}
+
+func (s *EventsStream) viewTrackEvent(e session.Event) {
+	track := e.Data.(TrackEvent)
+	tag := core.Bold(core.Red(e.Tag))
+	state := "getting closer"
+	if e.Tag == "track.cold" {
+		tag = core.Blue(e.Tag)
+		state = "moving away"
+	}
+
+	fmt.Fprintf(s.output, "[%s] [%s] %s %s is %s, average signal %.1f dBm (threshold %d dBm)\n",
+		e.Time.Format(eventTimeFormat),
+		tag,
+		track.Module,
+		core.Bold(track.Address),
+		state,
+		track.RSSI,
+		track.Threshold)
+}
+
+func (s *EventsStream) viewMacChangedEvent(e session.Event) {
+	changed := e.Data.(MacChanged)
+
+	fmt.Fprintf(s.output, "[%s] [%s] %s address changed from %s to %s\n",
+		e.Time.Format(eventTimeFormat),
+		core.Green(e.Tag),
+		changed.Interface,
+		core.Dim(changed.Previous),
+		core.Bold(changed.Address))
+}
+
+func (s *EventsStream) viewEndpointIPChangedEvent(e session.Event) {
+	changed := e.Data.(network.EndpointIPChanged)
+
+	fmt.Fprintf(s.output, "[%s] [%s] endpoint %s moved from %s to %s.\n",
+		e.Time.Format(eventTimeFormat),
+		core.Green(e.Tag),
+		changed.Endpoint.HwAddress,
+		core.Dim(changed.Old),
+		core.Bold(changed.New))
+}
+
+func (s *EventsStream) viewCapsEvent(e session.Event) {
+	report := e.Data.(session.CapsReport)
+	missing := []string{}
+	for _, c := range report.Capabilities {
+		if !c.Available {
+			missing = append(missing, c.Name)
+		}
+	}
+
+	status := core.Green("everything available")
+	if len(missing) > 0 {
+		status = "missing " + core.Red(strings.Join(missing, ", "))
+	}
+
+	fmt.Fprintf(s.output, "[%s] [%s] %s on %s: %s\n",
+		e.Time.Format(eventTimeFormat),
+		core.Green(e.Tag),
+		report.Interface,
+		report.OS,
+		status)
+}
+
+func (s *EventsStream) viewCorrelationEvent(e session.Event) {
+	corr := e.Data.(DeviceCorrelation)
+	what := "likely"
+	if corr.Confirmed {
+		what = "confirmed"
+	}
+	fmt.Fprintf(s.output, "[%s] [%s] WiFi %s and BLE %s are %s the same %s device (%.0f%%, pairing %d)\n",
+		e.Time.Format(eventTimeFormat),
+		core.Green(e.Tag),
+		core.Bold(corr.WiFi),
+		core.Bold(corr.BLE),
+		what,
+		corr.Vendor,
+		corr.Confidence*100,
+		corr.ID)
+}
+
+func (s *EventsStream) viewMonitorEvent(e session.Event) {
+	if e.Tag == "monitor.anomaly" {
+		dev := e.Data.(MonitorAnomaly)
+		what := core.Bold(dev.Address)
+		if dev.Name != "" && dev.Name != dev.Address {
+			what += fmt.Sprintf(" (%s)", dev.Name)
+		}
+		if dev.Vendor != "" {
+			what += " " + core.Dim(dev.Vendor)
+		}
+		fmt.Fprintf(s.output, "[%s] [%s] new %s %s not in the baseline\n",
+			e.Time.Format(eventTimeFormat),
+			core.Red(e.Tag),
+			dev.Kind,
+			what)
+	} else {
+		summary := e.Data.(MonitorBaselineSummary)
+		total := 0
+		for _, n := range summary.Known {
+			total += n
+		}
+		fmt.Fprintf(s.output, "[%s] [%s] baseline of %d devices learned in %s\n",
+			e.Time.Format(eventTimeFormat),
+			core.Green(e.Tag),
+			total,
+			summary.Completed.Sub(summary.Started).Round(time.Second))
+	}
+}
+
+func (s *EventsStream) viewKnockEvent(e session.Event) {
+	knock := e.Data.(KnockEvent)
+	fmt.Fprintf(s.output, "[%s] [%s] %s knocked on %s, running %s\n",
+		e.Time.Format(eventTimeFormat),
+		core.Green(e.Tag),
+		core.Bold(knock.Source),
+		strings.Join(knock.Sequence, ","),
+		core.Yellow(strings.Join(knock.Commands, "; ")))
+}
+
+func (s *EventsStream) viewModulesStatsEvent(e session.Event) {
+	stats := e.Data.(session.ModulesStats)
+	running := 0
+	for _, m := range stats.Modules {
+		if m.Running {
+			running++
+		}
+	}
+
+	fmt.Fprintf(s.output, "[%s] [%s] %d modules running, %d goroutines, heap %s\n",
+		e.Time.Format(eventTimeFormat),
+		core.Green(e.Tag),
+		running,
+		stats.Goroutines,
+		humanize.Bytes(stats.HeapAlloc))
+}
+
 func (s *EventsStream) viewUpdateEvent(e session.Event) {
 	update := e.Data.(*github.RepositoryRelease)
 
@@ -151,23 +617,147 @@ func (s *EventsStream) viewUpdateEvent(e session.Event) {
 		*update.HTMLURL)
 }
 
+func (s *EventsStream) viewUpdateProgressEvent(e session.Event) {
+	progress := e.Data.(UpdateProgress)
+
+	fmt.Fprintf(s.output, "[%s] [%s] downloading %s: %d%% (%s of %s)\n",
+		e.Time.Format(eventTimeFormat),
+		core.Green(e.Tag),
+		progress.Asset,
+		progress.Percent,
+		humanize.Bytes(uint64(progress.Downloaded)),
+		humanize.Bytes(uint64(progress.Total)))
+}
+
+func (s *EventsStream) viewIGMPEvent(e session.Event) {
+	join := e.Data.(network.MulticastJoin)
+	group := join.Group
+	if name := network.GroupName(group); name != "" {
+		group = fmt.Sprintf("%s (%s)", group, name)
+	}
+
+	fmt.Fprintf(s.output, "[%s] [%s] endpoint %s joined multicast group %s.\n",
+		e.Time.Format(eventTimeFormat),
+		core.Green(e.Tag),
+		core.Bold(join.Endpoint.IpAddress),
+		core.Yellow(group))
+}
+
+func (s *EventsStream) viewBlocklistEvent(e session.Event) {
+	hit := e.Data.(BlocklistHit)
+	who := hit.Address
+	if hit.Endpoint != nil {
+		who = hit.Endpoint.String()
+	}
+
+	fmt.Fprintf(s.output, "[%s] [%s] %s contacted %s which matches blocklist entry %s.\n",
+		e.Time.Format(eventTimeFormat),
+		core.Bold(core.Red(e.Tag)),
+		core.Bold(who),
+		core.Yellow(hit.Destination),
+		core.Red(hit.Indicator))
+}
+
+func (s *EventsStream) viewIfaceEvent(e session.Event) {
+	iface := e.Data.(*network.Endpoint)
+	status := core.Red("lost")
+	if e.Tag == "iface.recovered" {
+		status = core.Green("recovered")
+	}
+
+	fmt.Fprintf(s.output, "[%s] [%s] interface %s %s.\n",
+		e.Time.Format(eventTimeFormat),
+		core.Green(e.Tag),
+		core.Bold(iface.Name()),
+		status)
+}
+
+// historical events are loaded from a file, so their payload is not typed
+// anymore and can't be rendered by the specific views
+func (s *EventsStream) viewHistoricalEvent(e session.Event) {
+	what := ""
+	if data, ok := e.Data.(map[string]interface{}); ok && e.Tag == "sys.log" {
+		what = fmt.Sprintf("%v", data["Message"])
+	} else if raw, err := json.Marshal(e.Data); err == nil {
+		what = string(raw)
+	}
+
+	fmt.Fprintf(s.output, "[%s] [%s] %s %s\n",
+		e.Time.Format(eventTimeFormat),
+		core.Green(e.Tag),
+		core.Dim("(historical)"),
+		what)
+}
+
 func (s *EventsStream) View(e session.Event, refresh bool) {
-	if e.Tag == "sys.log" {
+	if e.Historical {
+		s.viewHistoricalEvent(e)
+	} else if e.Tag == "sys.log" {
 		s.viewLogEvent(e)
-	} else if strings.HasPrefix(e.Tag, "endpoint.") {
+	} else if e.Tag == "sys.dryrun" {
+		s.viewDryRunEvent(e)
+	} else if e.Tag == "track.hot" || e.Tag == "track.cold" {
+		s.viewTrackEvent(e)
+	} else if e.Tag == "mac.changed" {
+		s.viewMacChangedEvent(e)
+	} else if e.Tag == "caps" {
+		s.viewCapsEvent(e)
+	} else if e.Tag == "device.correlated" {
+		s.viewCorrelationEvent(e)
+	} else if e.Tag == "monitor.anomaly" || e.Tag == "monitor.learned" {
+		s.viewMonitorEvent(e)
+	} else if e.Tag == "knock.success" {
+		s.viewKnockEvent(e)
+	} else if e.Tag == "modules.stats" {
+		s.viewModulesStatsEvent(e)
+	} else if e.Tag == "endpoint.ip.changed" {
+		s.viewEndpointIPChangedEvent(e)
+	} else if strings.HasPrefix(e.Tag, "endpoint.") || e.Tag == "net.recon.os" || e.Tag == "net.recon.classified" || e.Tag == "net.recon.upnp" {
 		s.viewendpointEvent(e)
 	} else if strings.HasPrefix(e.Tag, "wifi.") {
 		s.viewWiFiEvent(e)
 	} else if strings.HasPrefix(e.Tag, "ble.") {
 		s.viewBLEEvent(e)
+	} else if e.Tag == "module.panic" {
+		s.viewModulePanicEvent(e)
 	} else if strings.HasPrefix(e.Tag, "mod.") {
 		s.viewModuleEvent(e)
 	} else if strings.HasPrefix(e.Tag, "net.sniff.") {
 		s.viewSnifferEvent(e)
+	} else if e.Tag == "net.recon.igmp" {
+		s.viewIGMPEvent(e)
+	} else if e.Tag == "net.recon.blocklist.hit" {
+		s.viewBlocklistEvent(e)
+	} else if strings.HasPrefix(e.Tag, "iface.") {
+		s.viewIfaceEvent(e)
+	} else if e.Tag == "net.inspect" {
+		s.viewInspectEvent(e)
+	} else if e.Tag == "http.server.captive" {
+		s.viewCaptiveEvent(e)
+	} else if e.Tag == "http.server.template" {
+		s.viewTemplateEvent(e)
+	} else if e.Tag == "dns.spoof.confirmed" || e.Tag == "dns.spoof.bypassed" {
+		s.viewDNSSpoofCheckEvent(e)
+	} else if e.Tag == "name.spoof.poisoned" {
+		s.viewNameSpoofEvent(e)
+	} else if e.Tag == "dns.query" {
+		s.viewDNSQueryEvent(e)
+	} else if e.Tag == "api.rest.request" {
+		s.viewAPIRequestEvent(e)
+	} else if e.Tag == "socks.proxy.connect" {
+		s.viewSocksEvent(e)
 	} else if e.Tag == "syn.scan" {
 		s.viewSynScanEvent(e)
+	} else if e.Tag == "syn.scan.summary" {
+		s.viewSynScanSummaryEvent(e)
+	} else if e.Tag == "syn.scan.diff" {
+		s.viewSynScanDiffEvent(e)
+	} else if e.Tag == "arp.spoof.confirmed" || e.Tag == "arp.spoof.failed" {
+		s.viewArpSpoofVerifyEvent(e)
 	} else if e.Tag == "update.available" {
 		s.viewUpdateEvent(e)
+	} else if e.Tag == "update.progress" {
+		s.viewUpdateProgressEvent(e)
 	} else {
 		fmt.Fprintf(s.output, "[%s] [%s] %v\n", e.Time.Format(eventTimeFormat), core.Green(e.Tag), e)
 	}
@@ -5,6 +5,8 @@ package modules
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/bettercap/bettercap/core"
 	"github.com/bettercap/bettercap/network"
@@ -47,6 +49,19 @@ func (s *EventsStream) viewBLEEvent(e session.Event) {
 			name,
 			dev.Device.ID(),
 			vend)
+	} else if e.Tag == "ble.enum.summary" {
+		summary := e.Data.(BLEEnumSummary)
+		skipped := ""
+		if len(summary.Skipped) > 0 {
+			skipped = fmt.Sprintf(", skipped %s", strings.Join(summary.Skipped, ", "))
+		}
+
+		fmt.Fprintf(s.output, "[%s] [%s] enumerated %d BLE devices in %s%s.\n",
+			e.Time.Format(eventTimeFormat),
+			core.Green(e.Tag),
+			len(summary.Enumerated),
+			summary.Duration.Round(time.Second),
+			core.Yellow(skipped))
 	} /* else {
 		fmt.Fprintf(s.output,"[%s] [%s]\n",
 			e.Time.Format(eventTimeFormat),
@@ -0,0 +1,162 @@
+package modules
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/bettercap/bettercap/core"
+	"github.com/bettercap/bettercap/log"
+	"github.com/bettercap/bettercap/network"
+	"github.com/bettercap/bettercap/session"
+)
+
+// dotGraph is a minimal builder for Graphviz DOT undirected graphs.
+type dotGraph struct {
+	buf bytes.Buffer
+}
+
+func newDotGraph(name string) *dotGraph {
+	g := &dotGraph{}
+	fmt.Fprintf(&g.buf, "graph %s {\n", dotQuote(name))
+	g.buf.WriteString("  graph [overlap=false, splines=true];\n")
+	g.buf.WriteString("  node [fontname=\"Helvetica\", fontsize=10];\n")
+	return g
+}
+
+func dotQuote(s string) string {
+	s = strings.Replace(s, "\\", "\\\\", -1)
+	s = strings.Replace(s, "\"", "\\\"", -1)
+	return "\"" + s + "\""
+}
+
+// dotLabel joins the non empty lines of a node label.
+func dotLabel(lines ...string) string {
+	parts := make([]string, 0, len(lines))
+	for _, l := range lines {
+		if l = core.Trim(l); l != "" {
+			parts = append(parts, strings.Replace(l, "\\", "\\\\", -1))
+		}
+	}
+	label := strings.Replace(strings.Join(parts, "\n"), "\"", "\\\"", -1)
+	return "\"" + strings.Replace(label, "\n", "\\n", -1) + "\""
+}
+
+func (g *dotGraph) Node(id string, label string, attrs string) {
+	if attrs != "" {
+		attrs = ", " + attrs
+	}
+	fmt.Fprintf(&g.buf, "  %s [label=%s%s];\n", dotQuote(id), label, attrs)
+}
+
+func (g *dotGraph) Edge(from, to, label string) {
+	if label != "" {
+		fmt.Fprintf(&g.buf, "  %s -- %s [label=%s];\n", dotQuote(from), dotQuote(to), dotQuote(label))
+	} else {
+		fmt.Fprintf(&g.buf, "  %s -- %s;\n", dotQuote(from), dotQuote(to))
+	}
+}
+
+func (g *dotGraph) String() string {
+	return g.buf.String() + "}\n"
+}
+
+// writeGraph saves the graph to fileName, if its extension is .svg the
+// graph is rendered with the dot executable from graphviz.
+func writeGraph(fileName string, g *dotGraph) error {
+	fileName, err := core.ExpandPath(fileName)
+	if err != nil {
+		return err
+	} else if fileName == "" {
+		return fmt.Errorf("no output file specified")
+	}
+
+	if strings.ToLower(filepath.Ext(fileName)) != ".svg" {
+		if err := ioutil.WriteFile(fileName, []byte(g.String()), 0644); err != nil {
+			return err
+		}
+	} else {
+		path, err := exec.LookPath("dot")
+		if err != nil {
+			return fmt.Errorf("graphviz dot executable not found, install it or use a .dot output file")
+		}
+
+		cmd := exec.Command(path, "-Tsvg", "-o", fileName)
+		cmd.Stdin = strings.NewReader(g.String())
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("error rendering %s: %s %s", fileName, err, core.Trim(string(out)))
+		}
+	}
+
+	log.Info("graph saved to %s", core.Bold(fileName))
+	return nil
+}
+
+func endpointGraphLabel(e *network.Endpoint, title string) string {
+	name := e.Alias
+	if name == "" {
+		name = e.Hostname
+	}
+	return dotLabel(title, e.IpAddress, name, e.HwAddress, e.Vendor)
+}
+
+// lanGraph describes the gateway, the interface and every host on the LAN.
+func lanGraph(s *session.Session) *dotGraph {
+	g := newDotGraph("lan")
+
+	gw := s.Gateway
+	g.Node(gw.HwAddress, endpointGraphLabel(gw, "gateway"), "shape=diamond, style=filled, fillcolor=\"#ffe0b2\"")
+
+	iface := s.Interface
+	g.Node(iface.HwAddress, endpointGraphLabel(iface, iface.Name()), "shape=doublecircle, style=filled, fillcolor=\"#c8e6c9\"")
+	if iface.HwAddress != gw.HwAddress {
+		g.Edge(iface.HwAddress, gw.HwAddress, "")
+	}
+
+	for _, e := range s.Lan.List() {
+		if e.HwAddress == gw.HwAddress || e.HwAddress == iface.HwAddress {
+			continue
+		}
+
+		attrs := "shape=box"
+		if e.Suspicious {
+			attrs += ", color=red"
+		}
+		g.Node(e.HwAddress, endpointGraphLabel(e, ""), attrs)
+		g.Edge(e.HwAddress, gw.HwAddress, "")
+	}
+
+	return g
+}
+
+// wifiGraph describes every access point and its clients.
+func wifiGraph(s *session.Session) *dotGraph {
+	g := newDotGraph("wifi")
+
+	for _, ap := range s.WiFi.List() {
+		enc := ap.Encryption
+		if enc == "" {
+			enc = "OPEN"
+		}
+
+		g.Node(ap.HwAddress, dotLabel(
+			ap.ESSID(),
+			ap.HwAddress,
+			ap.Vendor,
+			fmt.Sprintf("ch %d, %d dBm", ap.Channel(), ap.RSSI),
+			enc), "shape=box, style=filled, fillcolor=\"#bbdefb\"")
+
+		for _, client := range ap.Clients() {
+			g.Node(client.HwAddress, dotLabel(
+				client.HwAddress,
+				client.Vendor,
+				fmt.Sprintf("%d dBm", client.RSSI)), "shape=ellipse")
+			g.Edge(client.HwAddress, ap.HwAddress, fmt.Sprintf("%d dBm", client.RSSI))
+		}
+	}
+
+	return g
+}
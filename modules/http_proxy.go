@@ -1,6 +1,8 @@
 package modules
 
 import (
+	"time"
+
 	"github.com/bettercap/bettercap/session"
 )
 
@@ -12,8 +14,8 @@ type HttpProxy struct {
 func NewHttpProxy(s *session.Session) *HttpProxy {
 	p := &HttpProxy{
 		SessionModule: session.NewSessionModule("http.proxy", s),
-		proxy:         NewHTTPProxy(s),
 	}
+	p.proxy = NewHTTPProxy(&p.SessionModule)
 
 	p.AddParam(session.NewIntParameter("http.port",
 		"80",
@@ -40,7 +42,28 @@ func NewHttpProxy(s *session.Session) *HttpProxy {
 
 	p.AddParam(session.NewBoolParameter("http.proxy.sslstrip",
 		"false",
-		"Enable or disable SSL stripping."))
+		"Enable or disable SSL stripping, HSTS headers are removed from stripped responses but domains on the browsers HSTS preload list can't be stripped."))
+
+	p.AddParam(session.NewBoolParameter("http.proxy.nocache",
+		"false",
+		"If true, caching headers are removed from HTML and javascript responses so that clients always fetch them through the proxy, static assets are not affected."))
+
+	p.AddParam(session.NewStringParameter("http.proxy.blacklist",
+		"",
+		"",
+		"Comma separated list of host globs to forward untouched, without rewriting them nor running the proxy script on them."))
+
+	p.AddParam(session.NewIntParameter("http.proxy.upstream.maxidle",
+		"100",
+		"Maximum number of idle connections to the upstream servers kept open to be reused, 0 for no limit."))
+
+	p.AddParam(session.NewIntParameter("http.proxy.upstream.maxconnsperhost",
+		"0",
+		"Maximum number of connections to each upstream server, requests wait for a free one when the limit is reached, 0 for no limit."))
+
+	p.AddParam(session.NewIntParameter("http.proxy.upstream.idletimeout",
+		"90",
+		"Number of seconds an idle connection to an upstream server is kept open, 0 to never close it."))
 
 	p.AddHandler(session.NewModuleHandler("http.proxy on", "",
 		"Start HTTP proxy.",
@@ -77,6 +100,9 @@ func (p *HttpProxy) Configure() error {
 	var scriptPath string
 	var stripSSL bool
 	var jsToInject string
+	var blacklist string
+	var idleTimeout int
+	var upstream ProxyUpstream
 
 	if p.Running() {
 		return session.ErrAlreadyStarted
@@ -90,10 +116,27 @@ func (p *HttpProxy) Configure() error {
 		return err
 	} else if err, stripSSL = p.BoolParam("http.proxy.sslstrip"); err != nil {
 		return err
+	} else if err, p.proxy.noCache = p.BoolParam("http.proxy.nocache"); err != nil {
+		return err
+	} else if err, blacklist = p.StringParam("http.proxy.blacklist"); err != nil {
+		return err
 	} else if err, jsToInject = p.StringParam("http.proxy.injectjs"); err != nil {
 		return err
+	} else if err, upstream.MaxIdle = p.IntParam("http.proxy.upstream.maxidle"); err != nil {
+		return err
+	} else if err, upstream.MaxConnsPerHost = p.IntParam("http.proxy.upstream.maxconnsperhost"); err != nil {
+		return err
+	} else if err, idleTimeout = p.IntParam("http.proxy.upstream.idletimeout"); err != nil {
+		return err
 	}
 
+	upstream.IdleTimeout = time.Duration(idleTimeout) * time.Second
+	if err = p.proxy.SetUpstream(upstream); err != nil {
+		return err
+	}
+
+	p.proxy.blacklist = parseProxyBlacklist(blacklist)
+
 	return p.proxy.Configure(address, proxyPort, httpPort, scriptPath, jsToInject, stripSSL)
 }
 
@@ -41,6 +41,9 @@ type HTTPProxy struct {
 	KeyFile     string
 
 	jsHook      string
+	noCache     bool
+	blacklist   []HostEntry
+	alpn        []string
 	isTLS       bool
 	isRunning   bool
 	stripper    *SSLStripper
@@ -56,12 +59,12 @@ func stripPort(s string) string {
 	return s[:ix]
 }
 
-func NewHTTPProxy(s *session.Session) *HTTPProxy {
+func NewHTTPProxy(m *session.SessionModule) *HTTPProxy {
 	p := &HTTPProxy{
 		Name:     "http.proxy",
 		Proxy:    goproxy.NewProxyHttpServer(),
-		sess:     s,
-		stripper: NewSSLStripper(s, false),
+		sess:     m.Session,
+		stripper: NewSSLStripper(m, false),
 		isTLS:    false,
 		Server:   nil,
 	}
@@ -71,7 +74,10 @@ func NewHTTPProxy(s *session.Session) *HTTPProxy {
 
 	p.Proxy.NonproxyHandler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		if p.doProxy(req) {
-			if !p.isTLS {
+			if p.isBypassed(req.Host) {
+				p.bypassRequest(w, req)
+				return
+			} else if !p.isTLS {
 				req.URL.Scheme = "http"
 			}
 			req.URL.Host = req.Host
@@ -79,7 +85,7 @@ func NewHTTPProxy(s *session.Session) *HTTPProxy {
 		}
 	})
 
-	p.Proxy.OnRequest().HandleConnect(goproxy.AlwaysMitm)
+	p.Proxy.OnRequest().HandleConnectFunc(p.onConnect)
 	p.Proxy.OnRequest().DoFunc(p.onRequestFilter)
 	p.Proxy.OnResponse().DoFunc(p.onResponseFilter)
 
@@ -173,7 +179,10 @@ func (p *HTTPProxy) Configure(address string, proxyPort int, httpPort int, scrip
 	return nil
 }
 
-func TLSConfigFromCA(ca *tls.Certificate) func(host string, ctx *goproxy.ProxyCtx) (*tls.Config, error) {
+// TLSConfigFromCA returns the TLS configuration to intercept the connections
+// to host with a certificate for it signed by ca, alpn are the application
+// protocols to negotiate with the clients, in order of preference.
+func TLSConfigFromCA(ca *tls.Certificate, alpn []string) func(host string, ctx *goproxy.ProxyCtx) (*tls.Config, error) {
 	return func(host string, ctx *goproxy.ProxyCtx) (c *tls.Config, err error) {
 		parts := strings.SplitN(host, ":", 2)
 		hostname := parts[0]
@@ -200,6 +209,7 @@ func TLSConfigFromCA(ca *tls.Certificate) func(host string, ctx *goproxy.ProxyCt
 		config := tls.Config{
 			InsecureSkipVerify: true,
 			Certificates:       []tls.Certificate{*cert},
+			NextProtos:         alpn,
 		}
 
 		return &config, nil
@@ -228,10 +238,10 @@ func (p *HTTPProxy) ConfigureTLS(address string, proxyPort int, httpPort int, sc
 	}
 
 	goproxy.GoproxyCa = ourCa
-	goproxy.OkConnect = &goproxy.ConnectAction{Action: goproxy.ConnectAccept, TLSConfig: TLSConfigFromCA(&ourCa)}
-	goproxy.MitmConnect = &goproxy.ConnectAction{Action: goproxy.ConnectMitm, TLSConfig: TLSConfigFromCA(&ourCa)}
-	goproxy.HTTPMitmConnect = &goproxy.ConnectAction{Action: goproxy.ConnectHTTPMitm, TLSConfig: TLSConfigFromCA(&ourCa)}
-	goproxy.RejectConnect = &goproxy.ConnectAction{Action: goproxy.ConnectReject, TLSConfig: TLSConfigFromCA(&ourCa)}
+	goproxy.OkConnect = &goproxy.ConnectAction{Action: goproxy.ConnectAccept, TLSConfig: TLSConfigFromCA(&ourCa, p.alpn)}
+	goproxy.MitmConnect = &goproxy.ConnectAction{Action: goproxy.ConnectMitm, TLSConfig: TLSConfigFromCA(&ourCa, p.alpn)}
+	goproxy.HTTPMitmConnect = &goproxy.ConnectAction{Action: goproxy.ConnectHTTPMitm, TLSConfig: TLSConfigFromCA(&ourCa, p.alpn)}
+	goproxy.RejectConnect = &goproxy.ConnectAction{Action: goproxy.ConnectReject, TLSConfig: TLSConfigFromCA(&ourCa, p.alpn)}
 
 	return nil
 }
@@ -298,6 +308,11 @@ func (p *HTTPProxy) httpsWorker() error {
 				return
 			}
 
+			if p.isBypassed(hostname) {
+				// the tunnel must outlive the handshake timeouts
+				c.SetDeadline(time.Time{})
+			}
+
 			log.Debug("[%s] proxying connection from %s to %s", core.Green("https.proxy"), core.Bold(stripPort(c.RemoteAddr().String())), core.Yellow(hostname))
 
 			req := &http.Request{
@@ -349,6 +364,7 @@ func (p *HTTPProxy) Stop() error {
 	}
 
 	p.sess.UnkCmdCallback = nil
+	p.closeUpstream()
 
 	if p.isTLS {
 		p.isRunning = false
@@ -0,0 +1,27 @@
+package modules
+
+import (
+	"strings"
+
+	"github.com/bettercap/bettercap/log"
+)
+
+// parseProxyALPN filters https.proxy.alpn, the intercepted requests are
+// parsed as HTTP/1.x so h2 can't be negotiated with the clients, they'll
+// fall back to http/1.1 if they offer it.
+func parseProxyALPN(protos []string) []string {
+	alpn := make([]string, 0)
+	for _, proto := range protos {
+		proto = strings.ToLower(proto)
+		if proto == "h2" || proto == "h2c" {
+			log.Warning("https.proxy can only parse HTTP/1.x requests, ignoring %s in https.proxy.alpn.", proto)
+			continue
+		}
+		alpn = append(alpn, proto)
+	}
+
+	if len(alpn) == 0 {
+		return nil
+	}
+	return alpn
+}
@@ -0,0 +1,102 @@
+package modules
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bettercap/bettercap/core"
+	"github.com/bettercap/bettercap/log"
+
+	"github.com/elazarl/goproxy"
+)
+
+// parseProxyBlacklist parses a comma separated list of host globs, entries
+// match like the ones of dns.spoof.domains.
+func parseProxyBlacklist(value string) []HostEntry {
+	blacklist := make([]HostEntry, 0)
+	for _, host := range core.CommaSplit(value) {
+		if host = strings.ToLower(host); host != "" {
+			blacklist = append(blacklist, NewHostEntry(host, nil))
+		}
+	}
+	return blacklist
+}
+
+// isBypassed returns true if host (with or without a port) must be
+// forwarded untouched.
+func (p *HTTPProxy) isBypassed(host string) bool {
+	host = strings.ToLower(stripPort(host))
+	for _, entry := range p.blacklist {
+		if entry.Matches(host) {
+			return true
+		}
+	}
+	return false
+}
+
+// onConnect lets the CONNECT requests to blacklisted hosts, including the
+// ones crafted from the SNI of transparent HTTPS connections, be tunneled
+// instead of intercepted.
+func (p *HTTPProxy) onConnect(host string, ctx *goproxy.ProxyCtx) (*goproxy.ConnectAction, string) {
+	if p.isBypassed(host) {
+		log.Debug("(%s) bypassing %s", core.Green(p.Name), host)
+		return goproxy.OkConnect, host
+	}
+	return goproxy.MitmConnect, host
+}
+
+// bypassRequest forwards a transparently proxied request and whatever else
+// the client sends on that connection to the original host, without going
+// through the filters.
+func (p *HTTPProxy) bypassRequest(w http.ResponseWriter, req *http.Request) {
+	hij, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "Bad Gateway", 502)
+		return
+	}
+
+	host := req.Host
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		host = net.JoinHostPort(host, "80")
+	}
+
+	log.Debug("(%s) bypassing %s %s%s for %s", core.Green(p.Name), req.Method, req.Host, req.URL.Path, req.RemoteAddr)
+
+	server, err := net.DialTimeout("tcp", host, httpReadTimeout)
+	if err != nil {
+		log.Warning("(%s) could not connect to %s: %s", p.Name, host, err)
+		http.Error(w, "Bad Gateway", 502)
+		return
+	}
+	defer server.Close()
+
+	client, buf, err := hij.Hijack()
+	if err != nil {
+		log.Warning("(%s) could not hijack connection from %s: %s", p.Name, req.RemoteAddr, err)
+		return
+	}
+	defer client.Close()
+
+	// the server timeouts would otherwise cut long downloads
+	client.SetDeadline(time.Time{})
+
+	// the body is streamed as it is read
+	if err = req.Write(server); err != nil {
+		log.Debug("(%s) error forwarding request to %s: %s", p.Name, host, err)
+		return
+	}
+
+	done := make(chan bool, 2)
+	go func() {
+		io.Copy(server, buf)
+		done <- true
+	}()
+	go func() {
+		io.Copy(client, server)
+		done <- true
+	}()
+	<-done
+}
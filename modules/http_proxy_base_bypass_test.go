@@ -0,0 +1,41 @@
+package modules
+
+import (
+	"testing"
+)
+
+func TestParseProxyBlacklist(t *testing.T) {
+	blacklist := parseProxyBlacklist("Bank.com, *.gov ,,")
+	if len(blacklist) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(blacklist))
+	} else if blacklist[0].Host != "bank.com" || blacklist[1].Host != "*.gov" {
+		t.Fatalf("unexpected entries %v", blacklist)
+	}
+
+	if blacklist = parseProxyBlacklist(""); len(blacklist) != 0 {
+		t.Fatalf("expected no entries, got %d", len(blacklist))
+	}
+}
+
+func TestHTTPProxyIsBypassed(t *testing.T) {
+	p := &HTTPProxy{blacklist: parseProxyBlacklist("bank.com,*.gov")}
+
+	var units = []struct {
+		host     string
+		bypassed bool
+	}{
+		{"bank.com", true},
+		{"BANK.com:443", true},
+		{"www.bank.com", true},
+		{"notbank.com", false},
+		{"irs.gov", true},
+		{"example.com", false},
+		{"example.com:8080", false},
+	}
+
+	for _, u := range units {
+		if got := p.isBypassed(u.host); got != u.bypassed {
+			t.Fatalf("expected isBypassed(%s) to be %v, got %v", u.host, u.bypassed, got)
+		}
+	}
+}
@@ -20,6 +20,10 @@ func (p *HTTPProxy) fixRequestHeaders(req *http.Request) {
 }
 
 func (p *HTTPProxy) onRequestFilter(req *http.Request, ctx *goproxy.ProxyCtx) (*http.Request, *http.Response) {
+	if p.isBypassed(req.Host) {
+		return req, nil
+	}
+
 	log.Debug("(%s) < %s %s %s%s", core.Green(p.Name), req.RemoteAddr, req.Method, req.Host, req.URL.Path)
 
 	p.fixRequestHeaders(req)
@@ -45,7 +49,7 @@ func (p *HTTPProxy) onRequestFilter(req *http.Request, ctx *goproxy.ProxyCtx) (*
 	} else if jsres != nil {
 		// a fake response has been returned by the script
 		p.logResponseAction(req, jsres)
-		return req, jsres.ToResponse(req)
+		return req, p.disableCaching(jsres.ToResponse(req))
 	}
 
 	return req, nil
@@ -70,6 +74,28 @@ func (p *HTTPProxy) fixResponseHeaders(res *http.Response) {
 	res.Header.Set("Access-Control-Allow-Headers", "*")
 }
 
+// isCacheSensitive returns true for the content types we might inject into,
+// static assets are left alone so they still get cached.
+func (p *HTTPProxy) isCacheSensitive(res *http.Response) bool {
+	cType := strings.ToLower(p.getHeader(res, "Content-Type"))
+	return strings.Contains(cType, "text/html") ||
+		strings.Contains(cType, "javascript") ||
+		strings.Contains(cType, "ecmascript")
+}
+
+// disableCaching makes sure the client won't serve the response from its
+// cache next time, so every page load goes through the proxy again.
+func (p *HTTPProxy) disableCaching(res *http.Response) *http.Response {
+	if p.noCache && res != nil && p.isCacheSensitive(res) {
+		res.Header.Del("ETag")
+		res.Header.Del("Last-Modified")
+		res.Header.Set("Cache-Control", "no-cache, no-store, must-revalidate")
+		res.Header.Set("Pragma", "no-cache")
+		res.Header.Set("Expires", "0")
+	}
+	return res
+}
+
 func (p *HTTPProxy) getHeader(res *http.Response, header string) string {
 	header = strings.ToLower(header)
 	for name, values := range res.Header {
@@ -123,6 +149,8 @@ func (p *HTTPProxy) onResponseFilter(res *http.Response, ctx *goproxy.ProxyCtx)
 	// sometimes it happens ¯\_(ツ)_/¯
 	if res == nil {
 		return nil
+	} else if res.Request != nil && p.isBypassed(res.Request.Host) {
+		return res
 	}
 
 	log.Debug("(%s) > %s %s %s%s", core.Green(p.Name), res.Request.RemoteAddr, res.Request.Method, res.Request.Host, res.Request.URL.Path)
@@ -137,7 +165,7 @@ func (p *HTTPProxy) onResponseFilter(res *http.Response, ctx *goproxy.ProxyCtx)
 		if jsres != nil {
 			// the response has been changed by the script
 			p.logResponseAction(res.Request, jsres)
-			return jsres.ToResponse(res.Request)
+			return p.disableCaching(jsres.ToResponse(res.Request))
 		}
 	}
 
@@ -146,11 +174,11 @@ func (p *HTTPProxy) onResponseFilter(res *http.Response, ctx *goproxy.ProxyCtx)
 		if err, injectedResponse := p.doScriptInjection(res, cType); err != nil {
 			log.Error("(%s) error while injecting javascript: %s", p.Name, err)
 		} else if injectedResponse != nil {
-			return injectedResponse
+			return p.disableCaching(injectedResponse)
 		}
 	}
 
-	return res
+	return p.disableCaching(res)
 }
 
 func (p *HTTPProxy) logRequestAction(req *http.Request, jsreq *JSRequest) {
@@ -4,6 +4,7 @@ import (
 	"net"
 	"sync"
 
+	"github.com/bettercap/bettercap/core"
 	"github.com/bettercap/bettercap/log"
 )
 
@@ -21,16 +22,16 @@ func NewHost(name string) *Host {
 	}
 
 	h.Resolved.Add(1)
-	go func(ph *Host) {
-		defer ph.Resolved.Done()
-		if addrs, err := net.LookupIP(ph.Hostname); err == nil && len(addrs) > 0 {
-			ph.Address = make(net.IP, len(addrs[0]))
-			copy(ph.Address, addrs[0])
+	core.Workers.Submit(func() {
+		defer h.Resolved.Done()
+		if addrs, err := net.LookupIP(h.Hostname); err == nil && len(addrs) > 0 {
+			h.Address = make(net.IP, len(addrs[0]))
+			copy(h.Address, addrs[0])
 		} else {
-			log.Error("Could not resolve %s: %s", ph.Hostname, err)
-			ph.Address = nil
+			log.Error("Could not resolve %s: %s", h.Hostname, err)
+			h.Address = nil
 		}
-	}(h)
+	})
 
 	return h
 }
@@ -49,6 +50,10 @@ func NewHostTracker() *HostTracker {
 func (t *HostTracker) Track(host, stripped string) {
 	t.Lock()
 	defer t.Unlock()
+	// don't resolve again hosts we're already tracking
+	if existing, found := t.hosts[stripped]; found && existing.Hostname == host {
+		return
+	}
 	t.hosts[stripped] = NewHost(host)
 }
 
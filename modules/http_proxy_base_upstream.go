@@ -0,0 +1,74 @@
+package modules
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// ProxyUpstream configures the pool of connections the proxy keeps open to
+// the servers it forwards the requests to.
+type ProxyUpstream struct {
+	MaxIdle         int
+	MaxConnsPerHost int
+	IdleTimeout     time.Duration
+}
+
+// net/http would fall back to 2 idle connections per host when we pass 0,
+// so this is used instead when neither limit is set.
+const unlimitedIdlePerHost = 1 << 16
+
+func (u ProxyUpstream) idlePerHost() int {
+	// with no per host limit, every idle connection can go to the same host
+	idlePerHost := u.MaxConnsPerHost
+	if u.MaxIdle > 0 && (idlePerHost <= 0 || idlePerHost > u.MaxIdle) {
+		idlePerHost = u.MaxIdle
+	}
+	if idlePerHost <= 0 {
+		idlePerHost = unlimitedIdlePerHost
+	}
+	return idlePerHost
+}
+
+func (u ProxyUpstream) transport() *http.Transport {
+	idlePerHost := u.idlePerHost()
+
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSClientConfig:       &tls.Config{InsecureSkipVerify: true},
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+		MaxIdleConns:          u.MaxIdle,
+		MaxIdleConnsPerHost:   idlePerHost,
+		MaxConnsPerHost:       u.MaxConnsPerHost,
+		IdleConnTimeout:       u.IdleTimeout,
+	}
+}
+
+// SetUpstream replaces the transport of the proxy with a new one, the
+// connections pooled by the previous one are closed.
+func (p *HTTPProxy) SetUpstream(u ProxyUpstream) error {
+	if u.MaxIdle < 0 {
+		return fmt.Errorf("the maximum number of idle upstream connections can't be negative")
+	} else if u.MaxConnsPerHost < 0 {
+		return fmt.Errorf("the maximum number of upstream connections per host can't be negative")
+	} else if u.IdleTimeout < 0 {
+		return fmt.Errorf("the upstream idle timeout can't be negative")
+	}
+
+	p.closeUpstream()
+	p.Proxy.Tr = u.transport()
+	return nil
+}
+
+func (p *HTTPProxy) closeUpstream() {
+	if p.Proxy.Tr != nil {
+		p.Proxy.Tr.CloseIdleConnections()
+	}
+}
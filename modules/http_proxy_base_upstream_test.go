@@ -0,0 +1,29 @@
+package modules
+
+import (
+	"testing"
+)
+
+func TestProxyUpstreamIdlePerHost(t *testing.T) {
+	cases := []struct {
+		maxIdle  int
+		perHost  int
+		expected int
+	}{
+		{100, 0, 100},
+		{100, 10, 10},
+		{10, 100, 10},
+		{0, 10, 10},
+		{0, 0, unlimitedIdlePerHost},
+	}
+
+	for _, c := range cases {
+		u := ProxyUpstream{MaxIdle: c.maxIdle, MaxConnsPerHost: c.perHost}
+		if got := u.idlePerHost(); got != c.expected {
+			t.Fatalf("maxidle=%d maxconnsperhost=%d: expected %d, got %d", c.maxIdle, c.perHost, c.expected, got)
+		}
+		if tr := u.transport(); tr.MaxIdleConnsPerHost != c.expected {
+			t.Fatalf("transport has MaxIdleConnsPerHost %d, expected %d", tr.MaxIdleConnsPerHost, c.expected)
+		}
+	}
+}
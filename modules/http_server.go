@@ -50,6 +50,39 @@ func NewHttpServer(s *session.Session) *HttpServer {
 		"",
 		"TLS key file, if not empty will configure this as a HTTPS server (will be auto generated if filled but not existing)."))
 
+	httpd.AddParam(session.NewBoolParameter("http.server.captive",
+		"false",
+		"If true, act as a captive portal: every request, regardless of host and path, will get the portal page or be redirected to http.server.captive.redirect (use with dns.spoof)."))
+
+	httpd.AddParam(session.NewStringParameter("http.server.captive.page",
+		"",
+		"",
+		"HTML file to serve as the captive portal page, if empty a default sign in page will be used."))
+
+	httpd.AddParam(session.NewBoolParameter("http.server.templates",
+		"false",
+		"If true, *.tmpl files under http.server.path are rendered as Go templates with the request metadata (client IP, MAC, hostname, timestamp and a random token), a request for page.html will also render page.html.tmpl if present."))
+
+	httpd.AddParam(session.NewStringParameter("http.server.captive.redirect",
+		"",
+		"",
+		"If not empty, redirect every request to this portal URL instead of serving http.server.captive.page, requests to the portal host are served from http.server.path."))
+
+	httpd.AddParam(session.NewStringParameter("http.server.headers",
+		"",
+		"",
+		"Headers to add to every response, as 'Name: value' entries separated by '|', for instance 'Content-Security-Policy: default-src 'self' | Set-Cookie: session=1'."))
+
+	httpd.AddParam(session.NewStringParameter("http.server.methods",
+		"GET,HEAD,POST,OPTIONS",
+		"",
+		"Comma separated list of allowed HTTP methods, other methods will get a 405 response, if empty every method is allowed."))
+
+	httpd.AddParam(session.NewStringParameter("http.server.vhosts",
+		"",
+		"",
+		"Comma separated list of 'host=path' entries to serve a different folder for each Host header (globs like *.example.com are supported), other hosts are served from http.server.path."))
+
 	tls.CertConfigToModule("http.server", &httpd.SessionModule, tls.DefaultLegitConfig)
 
 	httpd.AddHandler(session.NewModuleHandler("http.server on", "",
@@ -90,6 +123,8 @@ func (httpd *HttpServer) Configure() error {
 	var port int
 	var certFile string
 	var keyFile string
+	var captive bool
+	var templates bool
 
 	if httpd.Running() {
 		return session.ErrAlreadyStarted
@@ -100,11 +135,73 @@ func (httpd *HttpServer) Configure() error {
 	}
 
 	router := http.NewServeMux()
-	fileServer := http.FileServer(http.Dir(path))
+
+	if err, templates = httpd.BoolParam("http.server.templates"); err != nil {
+		return err
+	}
+
+	newRoot := func(root string) http.Handler {
+		var handler http.Handler = http.FileServer(http.Dir(root))
+		if templates {
+			handler = newTemplateServer(root, handler)
+		}
+		return handler
+	}
+
+	fileServer := newRoot(path)
+
+	var vhostsList string
+	var vhosts []serverVHost
+
+	if err, vhostsList = httpd.StringParam("http.server.vhosts"); err != nil {
+		return err
+	} else if err, vhosts = parseServerVHosts(vhostsList); err != nil {
+		return err
+	} else if len(vhosts) > 0 {
+		for _, vhost := range vhosts {
+			log.Info("(%s) serving %s from %s", core.Green("httpd"), core.Bold(vhost.host.Host), vhost.root)
+		}
+		fileServer = newVHostServer(vhosts, fileServer, newRoot)
+	}
+
+	var handler http.Handler = fileServer
+
+	if err, captive = httpd.BoolParam("http.server.captive"); err != nil {
+		return err
+	} else if captive {
+		var pageFile, redirect string
+		var portal *captivePortal
+
+		if err, pageFile = httpd.StringParam("http.server.captive.page"); err != nil {
+			return err
+		} else if pageFile, err = core.ExpandPath(pageFile); err != nil {
+			return err
+		} else if err, redirect = httpd.StringParam("http.server.captive.redirect"); err != nil {
+			return err
+		} else if err, portal = newCaptivePortal(pageFile, redirect, fileServer); err != nil {
+			return err
+		}
+
+		handler = portal
+	}
+
+	var headers string
+	var methods []string
+	var extra http.Header
+
+	if err, headers = httpd.StringParam("http.server.headers"); err != nil {
+		return err
+	} else if err, extra = parseServerHeaders(headers); err != nil {
+		return err
+	} else if err, methods = httpd.ListParam("http.server.methods"); err != nil {
+		return err
+	}
+
+	handler = newServerHeadersHandler(extra, methods, handler)
 
 	router.HandleFunc("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		log.Info("(%s) %s %s %s%s", core.Green("httpd"), core.Bold(strings.Split(r.RemoteAddr, ":")[0]), r.Method, r.Host, r.URL.Path)
-		fileServer.ServeHTTP(w, r)
+		handler.ServeHTTP(w, r)
 	}))
 
 	httpd.server.Handler = router
@@ -0,0 +1,132 @@
+package modules
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/bettercap/bettercap/core"
+	"github.com/bettercap/bettercap/log"
+	"github.com/bettercap/bettercap/network"
+	"github.com/bettercap/bettercap/session"
+)
+
+const defaultCaptivePage = `<!DOCTYPE html>
+<html>
+<head><title>Sign in to the network</title></head>
+<body><h1>Sign in to the network</h1><p>You need to sign in before accessing the internet.</p></body>
+</html>`
+
+// paths used by operating systems and browsers to check for connectivity,
+// any answer other than the expected one will make them show the portal UI.
+var captiveChecks = map[string]bool{
+	"/generate_204":              true, // android, chrome
+	"/gen_204":                   true,
+	"/hotspot-detect.html":       true, // apple
+	"/library/test/success.html": true,
+	"/connecttest.txt":           true, // windows
+	"/ncsi.txt":                  true,
+	"/redirect":                  true,
+	"/success.txt":               true, // firefox
+	"/canonical.html":            true,
+	"/check_network_status.txt":  true, // kde
+}
+
+type CaptiveClient struct {
+	Address   string            `json:"address"`
+	Endpoint  *network.Endpoint `json:"endpoint"`
+	Host      string            `json:"host"`
+	Path      string            `json:"path"`
+	UserAgent string            `json:"user_agent"`
+}
+
+type captivePortal struct {
+	sync.Mutex
+	page     []byte
+	redirect *url.URL
+	files    http.Handler
+	clients  map[string]bool
+}
+
+func newCaptivePortal(pageFile string, redirect string, files http.Handler) (error, *captivePortal) {
+	portal := &captivePortal{
+		page:    []byte(defaultCaptivePage),
+		files:   files,
+		clients: make(map[string]bool),
+	}
+
+	if pageFile != "" {
+		if raw, err := ioutil.ReadFile(pageFile); err != nil {
+			return err, nil
+		} else {
+			portal.page = raw
+		}
+	}
+
+	if redirect != "" {
+		if u, err := url.Parse(redirect); err != nil {
+			return err, nil
+		} else {
+			portal.redirect = u
+		}
+	}
+
+	return nil, portal
+}
+
+func (p *captivePortal) onClient(r *http.Request) {
+	address, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		address = r.RemoteAddr
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	if p.clients[address] {
+		return
+	}
+	p.clients[address] = true
+
+	session.I.Events.Add("http.server.captive", CaptiveClient{
+		Address:   address,
+		Endpoint:  session.I.Lan.GetByIp(address),
+		Host:      r.Host,
+		Path:      r.URL.Path,
+		UserAgent: r.UserAgent(),
+	})
+}
+
+func (p *captivePortal) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.onClient(r)
+
+	// make sure nothing caches the portal responses
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	w.Header().Set("Pragma", "no-cache")
+	w.Header().Set("Expires", "0")
+
+	if p.redirect != nil {
+		// requests for the portal itself are served from http.server.path
+		if r.Host == p.redirect.Host {
+			p.files.ServeHTTP(w, r)
+			return
+		}
+
+		log.Debug("(%s) redirecting %s%s to %s", core.Green("httpd"), r.Host, r.URL.Path, p.redirect)
+		http.Redirect(w, r, p.redirect.String(), http.StatusFound)
+		return
+	}
+
+	// a redirect is the most reliable way to trigger the portal UI
+	if captiveChecks[r.URL.Path] {
+		log.Debug("(%s) connectivity check %s%s", core.Green("httpd"), r.Host, r.URL.Path)
+		http.Redirect(w, r, "http://"+r.Host+"/", http.StatusFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(p.page)
+}
@@ -0,0 +1,67 @@
+package modules
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// parseServerHeaders parses http.server.headers, a list of 'Name: value'
+// entries separated by '|' since values like CSP policies contain commas
+// and semicolons.
+func parseServerHeaders(value string) (error, http.Header) {
+	headers := make(http.Header)
+	for _, entry := range strings.Split(value, "|") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, ":", 2)
+		name := strings.TrimSpace(parts[0])
+		if len(parts) != 2 || name == "" || strings.ContainsAny(name, " \t\r\n") {
+			return fmt.Errorf("invalid header '%s', expected 'Name: value'", entry), nil
+		}
+
+		headers.Add(name, strings.TrimSpace(parts[1]))
+	}
+	return nil, headers
+}
+
+type serverHeadersHandler struct {
+	headers http.Header
+	methods map[string]bool
+	allow   string
+	handler http.Handler
+}
+
+func newServerHeadersHandler(headers http.Header, methods []string, handler http.Handler) *serverHeadersHandler {
+	// an empty set means every method is allowed
+	allowed := make(map[string]bool)
+	for i := range methods {
+		methods[i] = strings.ToUpper(methods[i])
+		allowed[methods[i]] = true
+	}
+
+	return &serverHeadersHandler{
+		headers: headers,
+		methods: allowed,
+		allow:   strings.Join(methods, ", "),
+		handler: handler,
+	}
+}
+
+func (h *serverHeadersHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// set before anything is written so that every response, errors and
+	// redirects included, gets them
+	for name, values := range h.headers {
+		w.Header()[name] = values
+	}
+
+	if len(h.methods) > 0 && !h.methods[r.Method] {
+		w.Header().Set("Allow", h.allow)
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	h.handler.ServeHTTP(w, r)
+}
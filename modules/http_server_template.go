@@ -0,0 +1,171 @@
+package modules
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"html/template"
+	"io/ioutil"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/bettercap/bettercap/core"
+	"github.com/bettercap/bettercap/log"
+	"github.com/bettercap/bettercap/network"
+	"github.com/bettercap/bettercap/session"
+)
+
+const templateExt = ".tmpl"
+
+// TemplateContext is what templates under http.server.path are rendered with.
+type TemplateContext struct {
+	ClientIP  string
+	ClientMAC string
+	Hostname  string
+	Host      string
+	Method    string
+	Path      string
+	Query     url.Values
+	Headers   http.Header
+	UserAgent string
+	Time      time.Time
+	Token     string
+}
+
+type TemplateServed struct {
+	Address   string            `json:"address"`
+	Endpoint  *network.Endpoint `json:"endpoint"`
+	Path      string            `json:"path"`
+	Template  string            `json:"template"`
+	Token     string            `json:"token"`
+	UserAgent string            `json:"user_agent"`
+}
+
+// templateServer renders *.tmpl files found under root and falls back to
+// the static files handler for everything else.
+type templateServer struct {
+	root  http.Dir
+	files http.Handler
+}
+
+func newTemplateServer(root string, files http.Handler) *templateServer {
+	return &templateServer{
+		root:  http.Dir(root),
+		files: files,
+	}
+}
+
+func newTemplateToken() string {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(raw)
+}
+
+// lookup returns the template to render for the requested path, either the
+// path itself if it's a template or the same path with the template
+// extension (so that /login.html can be served from login.html.tmpl).
+func (t *templateServer) lookup(reqPath string) (string, []byte) {
+	if strings.HasSuffix(reqPath, "/") {
+		reqPath += "index.html"
+	}
+
+	candidates := []string{reqPath + templateExt}
+	if strings.HasSuffix(reqPath, templateExt) {
+		candidates = []string{reqPath}
+	}
+
+	for _, name := range candidates {
+		f, err := t.root.Open(name)
+		if err != nil {
+			continue
+		}
+
+		raw, err := ioutil.ReadAll(f)
+		f.Close()
+		if err == nil {
+			return name, raw
+		}
+	}
+
+	return "", nil
+}
+
+func (t *templateServer) context(r *http.Request) TemplateContext {
+	address, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		address = r.RemoteAddr
+	}
+
+	ctx := TemplateContext{
+		ClientIP:  address,
+		Host:      r.Host,
+		Method:    r.Method,
+		Path:      r.URL.Path,
+		Query:     r.URL.Query(),
+		Headers:   r.Header,
+		UserAgent: r.UserAgent(),
+		Time:      time.Now(),
+		Token:     newTemplateToken(),
+	}
+
+	if e := session.I.Lan.GetByIp(address); e != nil {
+		ctx.ClientMAC = e.HwAddress
+		ctx.Hostname = e.Hostname
+	}
+
+	return ctx
+}
+
+func (t *templateServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	reqPath := path.Clean("/" + r.URL.Path)
+	if strings.HasSuffix(r.URL.Path, "/") && reqPath != "/" {
+		reqPath += "/"
+	}
+
+	name, raw := t.lookup(reqPath)
+	if name == "" {
+		t.files.ServeHTTP(w, r)
+		return
+	}
+
+	tmpl, err := template.New(name).Parse(string(raw))
+	if err != nil {
+		log.Error("(%s) error parsing template %s: %s", core.Green("httpd"), name, err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	ctx := t.context(r)
+	buf := bytes.Buffer{}
+	if err = tmpl.Execute(&buf, ctx); err != nil {
+		log.Error("(%s) error rendering template %s: %s", core.Green("httpd"), name, err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	cType := mime.TypeByExtension(path.Ext(strings.TrimSuffix(name, templateExt)))
+	if cType == "" {
+		cType = "text/html; charset=utf-8"
+	}
+
+	w.Header().Set("Content-Type", cType)
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+
+	session.I.Events.Add("http.server.template", TemplateServed{
+		Address:   ctx.ClientIP,
+		Endpoint:  session.I.Lan.GetByIp(ctx.ClientIP),
+		Path:      r.URL.Path,
+		Template:  name,
+		Token:     ctx.Token,
+		UserAgent: ctx.UserAgent,
+	})
+}
@@ -0,0 +1,71 @@
+package modules
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/bettercap/bettercap/core"
+)
+
+type serverVHost struct {
+	host    HostEntry
+	root    string
+	handler http.Handler
+}
+
+// vhostServer routes the requests to the document root of the first virtual
+// host matching their Host header, falling back to http.server.path.
+type vhostServer struct {
+	vhosts   []serverVHost
+	fallback http.Handler
+}
+
+// parseServerVHosts parses http.server.vhosts, a comma separated list of
+// 'host=path' entries where host matches like the dns.spoof.domains ones.
+func parseServerVHosts(value string) (error, []serverVHost) {
+	vhosts := make([]serverVHost, 0)
+	for _, entry := range core.CommaSplit(value) {
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 || core.Trim(parts[0]) == "" || core.Trim(parts[1]) == "" {
+			return fmt.Errorf("invalid virtual host '%s', expected 'host=path'", entry), nil
+		}
+
+		host := strings.ToLower(core.Trim(parts[0]))
+		root, err := core.ExpandPath(core.Trim(parts[1]))
+		if err != nil {
+			return err, nil
+		} else if !core.Exists(root) {
+			return fmt.Errorf("document root %s of virtual host %s does not exist", root, host), nil
+		}
+
+		vhosts = append(vhosts, serverVHost{
+			host: NewHostEntry(host, nil),
+			root: root,
+		})
+	}
+	return nil, vhosts
+}
+
+// newVHostServer creates the document root handlers with newRoot, hosts are
+// checked in the order they were configured.
+func newVHostServer(vhosts []serverVHost, fallback http.Handler, newRoot func(root string) http.Handler) *vhostServer {
+	for i := range vhosts {
+		vhosts[i].handler = newRoot(vhosts[i].root)
+	}
+	return &vhostServer{
+		vhosts:   vhosts,
+		fallback: fallback,
+	}
+}
+
+func (v *vhostServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	host := strings.ToLower(stripPort(r.Host))
+	for _, vhost := range v.vhosts {
+		if vhost.host.Matches(host) {
+			vhost.handler.ServeHTTP(w, r)
+			return
+		}
+	}
+	v.fallback.ServeHTTP(w, r)
+}
@@ -0,0 +1,72 @@
+package modules
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestParseServerVHosts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bettercap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	err, vhosts := parseServerVHosts("Bank.com=" + dir + ", *.example.com = " + dir)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(vhosts) != 2 {
+		t.Fatalf("expected 2 virtual hosts, got %d", len(vhosts))
+	} else if vhosts[0].host.Host != "bank.com" || vhosts[0].root != dir {
+		t.Fatalf("unexpected virtual host %+v", vhosts[0])
+	} else if vhosts[1].host.Host != "*.example.com" || vhosts[1].root != dir {
+		t.Fatalf("unexpected virtual host %+v", vhosts[1])
+	}
+
+	for _, value := range []string{"bank.com", "bank.com=", "=" + dir, "bank.com=" + dir + "/missing"} {
+		if err, _ := parseServerVHosts(value); err == nil {
+			t.Fatalf("expected an error for '%s'", value)
+		}
+	}
+}
+
+func TestVHostServerRouting(t *testing.T) {
+	vhosts := []serverVHost{
+		{host: NewHostEntry("bank.com", nil), root: "bank"},
+		{host: NewHostEntry("*.example.com", nil), root: "example"},
+	}
+
+	fallback := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("fallback"))
+	})
+	server := newVHostServer(vhosts, fallback, func(root string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(root))
+		})
+	})
+
+	var units = []struct {
+		host string
+		exp  string
+	}{
+		{"bank.com", "bank"},
+		{"BANK.COM:8080", "bank"},
+		{"www.bank.com", "bank"},
+		{"www.example.com", "example"},
+		{"example.org", "fallback"},
+	}
+
+	for _, u := range units {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Host = u.host
+		rec := httptest.NewRecorder()
+
+		server.ServeHTTP(rec, req)
+		if got := rec.Body.String(); got != u.exp {
+			t.Fatalf("expected %s to be served by '%s', got '%s'", u.host, u.exp, got)
+		}
+	}
+}
@@ -15,8 +15,8 @@ type HttpsProxy struct {
 func NewHttpsProxy(s *session.Session) *HttpsProxy {
 	p := &HttpsProxy{
 		SessionModule: session.NewSessionModule("https.proxy", s),
-		proxy:         NewHTTPProxy(s),
 	}
+	p.proxy = NewHTTPProxy(&p.SessionModule)
 
 	p.AddParam(session.NewIntParameter("https.port",
 		"443",
@@ -33,7 +33,7 @@ func NewHttpsProxy(s *session.Session) *HttpsProxy {
 
 	p.AddParam(session.NewBoolParameter("https.proxy.sslstrip",
 		"false",
-		"Enable or disable SSL stripping."))
+		"Enable or disable SSL stripping, HSTS headers are removed from stripped responses but domains on the browsers HSTS preload list can't be stripped."))
 
 	p.AddParam(session.NewStringParameter("https.proxy.injectjs",
 		"",
@@ -57,6 +57,20 @@ func NewHttpsProxy(s *session.Session) *HttpsProxy {
 		"",
 		"Path of a proxy JS script."))
 
+	p.AddParam(session.NewBoolParameter("https.proxy.nocache",
+		"false",
+		"If true, caching headers are removed from HTML and javascript responses so that clients always fetch them through the proxy, static assets are not affected."))
+
+	p.AddParam(session.NewStringParameter("https.proxy.alpn",
+		"http/1.1",
+		"",
+		"Comma separated list of application protocols to negotiate via ALPN with the intercepted clients, in order of preference, the first one offered by the client is selected (h2 is not supported), if empty ALPN is not used."))
+
+	p.AddParam(session.NewStringParameter("https.proxy.blacklist",
+		"",
+		"",
+		"Comma separated list of host globs to forward untouched, without rewriting them nor running the proxy script on them."))
+
 	p.AddHandler(session.NewModuleHandler("https.proxy on", "",
 		"Start HTTPS proxy.",
 		func(args []string) error {
@@ -94,6 +108,8 @@ func (p *HttpsProxy) Configure() error {
 	var keyFile string
 	var stripSSL bool
 	var jsToInject string
+	var blacklist string
+	var alpn []string
 
 	if p.Running() {
 		return session.ErrAlreadyStarted
@@ -105,6 +121,12 @@ func (p *HttpsProxy) Configure() error {
 		return err
 	} else if err, stripSSL = p.BoolParam("https.proxy.sslstrip"); err != nil {
 		return err
+	} else if err, p.proxy.noCache = p.BoolParam("https.proxy.nocache"); err != nil {
+		return err
+	} else if err, blacklist = p.StringParam("https.proxy.blacklist"); err != nil {
+		return err
+	} else if err, alpn = p.ListParam("https.proxy.alpn"); err != nil {
+		return err
 	} else if err, certFile = p.StringParam("https.proxy.certificate"); err != nil {
 		return err
 	} else if certFile, err = core.ExpandPath(certFile); err != nil {
@@ -136,6 +158,9 @@ func (p *HttpsProxy) Configure() error {
 		log.Info("loading proxy certification authority TLS certificate from %s", certFile)
 	}
 
+	p.proxy.blacklist = parseProxyBlacklist(blacklist)
+	p.proxy.alpn = parseProxyALPN(alpn)
+
 	return p.proxy.ConfigureTLS(address, proxyPort, httpPort, scriptPath, certFile, keyFile, jsToInject, stripSSL)
 }
 
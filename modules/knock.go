@@ -0,0 +1,284 @@
+package modules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bettercap/bettercap/core"
+	"github.com/bettercap/bettercap/log"
+	"github.com/bettercap/bettercap/session"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcap"
+)
+
+// KnockEvent is the payload of knock.success events.
+type KnockEvent struct {
+	Source   string   `json:"source"`
+	Sequence []string `json:"sequence"`
+	Commands []string `json:"commands"`
+}
+
+type knockStep struct {
+	port  int
+	proto string
+}
+
+func (s knockStep) String() string {
+	return fmt.Sprintf("%d/%s", s.port, s.proto)
+}
+
+// parseKnockSequence parses a comma separated list of PORT or PORT/PROTO,
+// the protocol being tcp unless specified otherwise.
+func parseKnockSequence(list string) (error, []knockStep) {
+	steps := make([]knockStep, 0)
+	for _, part := range core.CommaSplit(list) {
+		step := knockStep{proto: "tcp"}
+		port := part
+		if idx := strings.Index(part, "/"); idx != -1 {
+			port = part[:idx]
+			step.proto = strings.ToLower(core.Trim(part[idx+1:]))
+		}
+
+		var err error
+		if step.port, err = strconv.Atoi(core.Trim(port)); err != nil || step.port < 1 || step.port > 65535 {
+			return fmt.Errorf("invalid knock port '%s'", part), nil
+		} else if step.proto != "tcp" && step.proto != "udp" {
+			return fmt.Errorf("invalid knock protocol '%s', use tcp or udp", step.proto), nil
+		}
+		steps = append(steps, step)
+	}
+	return nil, steps
+}
+
+// knockState is how far a source got into the sequence.
+type knockState struct {
+	next    int
+	started time.Time
+}
+
+type KnockModule struct {
+	session.SessionModule
+	Handle        *pcap.Handle
+	sequence      []knockStep
+	window        time.Duration
+	commands      []string
+	sources       map[string]*knockState
+	waitGroup     *sync.WaitGroup
+	pktSourceChan chan gopacket.Packet
+}
+
+func NewKnockModule(s *session.Session) *KnockModule {
+	k := &KnockModule{
+		SessionModule: session.NewSessionModule("knock", s),
+		sources:       make(map[string]*knockState),
+		waitGroup:     &sync.WaitGroup{},
+	}
+
+	k.EmitsEvents("knock.")
+
+	k.AddParam(session.NewStringParameter("knock.sequence",
+		"",
+		"",
+		"Comma separated list of PORT or PORT/PROTO (tcp or udp, default tcp) a source has to knock on, in order."))
+
+	k.AddParam(session.NewIntParameter("knock.window",
+		"10",
+		"Seconds a source has to complete the sequence from its first knock."))
+
+	k.AddParam(session.NewStringParameter("knock.commands",
+		"",
+		"",
+		"List of commands separated by a ; to run when a source completes the sequence."))
+
+	k.AddHandler(session.NewModuleHandler("knock on", "",
+		"Start waiting for the knock sequence.",
+		func(args []string) error {
+			return k.Start()
+		}))
+
+	k.AddHandler(session.NewModuleHandler("knock off", "",
+		"Stop waiting for the knock sequence.",
+		func(args []string) error {
+			return k.Stop()
+		}))
+
+	return k
+}
+
+func (k KnockModule) Name() string {
+	return "knock"
+}
+
+func (k KnockModule) Description() string {
+	return "Runs commands when a source knocks on a sequence of TCP or UDP ports of this computer."
+}
+
+func (k KnockModule) Author() string {
+	return "Simone Margaritelli <evilsocket@protonmail.com>"
+}
+
+func (k *KnockModule) Configure() error {
+	var err error
+	var sequence, commands string
+	var window int
+
+	if k.Running() {
+		return session.ErrAlreadyStarted
+	} else if err, sequence = k.StringParam("knock.sequence"); err != nil {
+		return err
+	} else if err, k.sequence = parseKnockSequence(sequence); err != nil {
+		return err
+	} else if len(k.sequence) == 0 {
+		return fmt.Errorf("knock.sequence is empty")
+	} else if err, window = k.IntParam("knock.window"); err != nil {
+		return err
+	} else if window <= 0 {
+		return fmt.Errorf("knock.window must be greater than 0")
+	} else if err, commands = k.StringParam("knock.commands"); err != nil {
+		return err
+	} else if k.commands = session.ParseCommands(commands); len(k.commands) == 0 {
+		return fmt.Errorf("knock.commands is empty")
+	} else if k.Handle, err = pcap.OpenLive(k.Session.Interface.Name(), 128, true, pcap.BlockForever); err != nil {
+		return err
+	}
+
+	// every connection attempt to us counts, so that a knock on a port
+	// which is not the next one resets the sequence
+	filter := fmt.Sprintf("dst host %s and ((tcp[tcpflags] & (tcp-syn|tcp-ack) == tcp-syn) or udp)", k.Session.Interface.IpAddress)
+	if err = k.Handle.SetBPFFilter(filter); err != nil {
+		k.Handle.Close()
+		return err
+	}
+
+	k.window = time.Duration(window) * time.Second
+	k.sources = make(map[string]*knockState)
+
+	return nil
+}
+
+func (k *KnockModule) sequenceNames() []string {
+	names := make([]string, 0)
+	for _, s := range k.sequence {
+		names = append(names, s.String())
+	}
+	return names
+}
+
+// expire forgets the sources which ran out of time.
+func (k *KnockModule) expire(now time.Time) {
+	for source, st := range k.sources {
+		if now.Sub(st.started) > k.window {
+			log.Debug("knock sequence of %s timed out.", source)
+			delete(k.sources, source)
+		}
+	}
+}
+
+// knock moves source along the sequence and returns true once completed.
+func (k *KnockModule) knock(source string, step knockStep, now time.Time) bool {
+	k.expire(now)
+
+	st, found := k.sources[source]
+	if found {
+		if step == k.sequence[st.next] {
+			st.next++
+		} else if step == k.sequence[st.next-1] {
+			// a retransmission of the last knock
+			return false
+		} else {
+			log.Debug("%s knocked on %s instead of %s, sequence reset.", source, step, k.sequence[st.next])
+			delete(k.sources, source)
+			found = false
+		}
+	}
+
+	if !found {
+		if step != k.sequence[0] {
+			return false
+		}
+		st = &knockState{next: 1, started: now}
+		k.sources[source] = st
+	}
+
+	if st.next < len(k.sequence) {
+		log.Debug("%s knocked on %s (%d/%d).", source, step, st.next, len(k.sequence))
+		return false
+	}
+
+	delete(k.sources, source)
+	return true
+}
+
+func (k *KnockModule) onPacket(pkt gopacket.Packet) {
+	ip4, ok := pkt.Layer(layers.LayerTypeIPv4).(*layers.IPv4)
+	if !ok {
+		return
+	}
+
+	step := knockStep{}
+	if tcp, ok := pkt.Layer(layers.LayerTypeTCP).(*layers.TCP); ok {
+		step.port, step.proto = int(tcp.DstPort), "tcp"
+	} else if udp, ok := pkt.Layer(layers.LayerTypeUDP).(*layers.UDP); ok {
+		step.port, step.proto = int(udp.DstPort), "udp"
+	} else {
+		return
+	}
+
+	source := ip4.SrcIP.String()
+	if !k.knock(source, step, time.Now()) {
+		return
+	}
+
+	log.Info("%s completed the knock sequence, running %d commands.", core.Bold(source), len(k.commands))
+	k.Session.Events.Add("knock.success", KnockEvent{
+		Source:   source,
+		Sequence: k.sequenceNames(),
+		Commands: k.commands,
+	})
+
+	// the commands run on their own so that they can stop this module too
+	go func(commands []string) {
+		for _, cmd := range commands {
+			if err := k.Session.Run(cmd); err != nil {
+				log.Error("%s", err)
+			}
+		}
+	}(k.commands)
+}
+
+func (k *KnockModule) Start() error {
+	if err := k.Configure(); err != nil {
+		return err
+	}
+
+	return k.SetRunning(true, func() {
+		k.waitGroup.Add(1)
+		defer k.waitGroup.Done()
+
+		log.Info("waiting for the knock sequence %s within %s.", core.Bold(strings.Join(k.sequenceNames(), ",")), k.window)
+
+		src := gopacket.NewPacketSource(k.Handle, k.Handle.LinkType())
+		k.pktSourceChan = src.Packets()
+		for packet := range k.pktSourceChan {
+			if !k.Running() {
+				break
+			}
+
+			k.FrameReceived()
+			k.onPacket(packet)
+		}
+	})
+}
+
+func (k *KnockModule) Stop() error {
+	return k.SetRunning(false, func() {
+		k.pktSourceChan <- nil
+		k.Handle.Close()
+		k.waitGroup.Wait()
+	})
+}
@@ -0,0 +1,115 @@
+package modules
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseKnockSequence(t *testing.T) {
+	cases := []struct {
+		list     string
+		expected []knockStep
+		fails    bool
+	}{
+		{"", []knockStep{}, false},
+		{"1000", []knockStep{{1000, "tcp"}}, false},
+		{"1000, 2000/udp ,3000/TCP", []knockStep{{1000, "tcp"}, {2000, "udp"}, {3000, "tcp"}}, false},
+		{"1000 / udp", []knockStep{{1000, "udp"}}, false},
+		{"0", nil, true},
+		{"65536", nil, true},
+		{"http", nil, true},
+		{"1000/icmp", nil, true},
+		{"1000,/udp", nil, true},
+	}
+
+	for _, c := range cases {
+		err, steps := parseKnockSequence(c.list)
+		if c.fails {
+			if err == nil {
+				t.Fatalf("'%s': expected an error, got %v", c.list, steps)
+			}
+			continue
+		} else if err != nil {
+			t.Fatalf("'%s': unexpected error %s", c.list, err)
+		} else if len(steps) != len(c.expected) {
+			t.Fatalf("'%s': expected %v, got %v", c.list, c.expected, steps)
+		}
+		for i := range steps {
+			if steps[i] != c.expected[i] {
+				t.Fatalf("'%s': expected %v, got %v", c.list, c.expected, steps)
+			}
+		}
+	}
+}
+
+func TestKnock(t *testing.T) {
+	testSession()
+
+	_, sequence := parseKnockSequence("1000,2000/udp,3000")
+	a, b, c := sequence[0], sequence[1], sequence[2]
+	other := knockStep{4000, "tcp"}
+	start := time.Now()
+	at := func(secs int) time.Time {
+		return start.Add(time.Duration(secs) * time.Second)
+	}
+
+	cases := []struct {
+		name     string
+		knocks   []knockStep
+		times    []int
+		complete bool
+	}{
+		{"in order", []knockStep{a, b, c}, []int{0, 1, 2}, true},
+		{"retransmissions", []knockStep{a, a, b, b, c}, []int{0, 0, 1, 1, 2}, true},
+		{"wrong protocol", []knockStep{a, {2000, "tcp"}, c}, []int{0, 1, 2}, false},
+		{"out of order", []knockStep{a, c, b}, []int{0, 1, 2}, false},
+		{"wrong port resets", []knockStep{a, other, b, c}, []int{0, 1, 2, 3}, false},
+		{"restart after reset", []knockStep{a, other, a, b, c}, []int{0, 1, 2, 3, 4}, true},
+		{"not from the start", []knockStep{b, c}, []int{0, 1}, false},
+		{"too slow", []knockStep{a, b, c}, []int{0, 5, 11}, false},
+		{"slow but in time", []knockStep{a, b, c}, []int{0, 5, 10}, true},
+	}
+
+	for _, tc := range cases {
+		k := &KnockModule{
+			sequence: sequence,
+			window:   10 * time.Second,
+			sources:  make(map[string]*knockState),
+		}
+
+		complete := false
+		for i, step := range tc.knocks {
+			if complete = k.knock("10.0.0.1", step, at(tc.times[i])); complete && i != len(tc.knocks)-1 {
+				t.Fatalf("%s: completed at knock %d", tc.name, i+1)
+			}
+		}
+
+		if complete != tc.complete {
+			t.Fatalf("%s: expected complete=%v, got %v", tc.name, tc.complete, complete)
+		} else if complete && len(k.sources) != 0 {
+			t.Fatalf("%s: expected the source to be forgotten once completed", tc.name)
+		}
+	}
+}
+
+func TestKnockSources(t *testing.T) {
+	testSession()
+
+	_, sequence := parseKnockSequence("1000,2000")
+	k := &KnockModule{
+		sequence: sequence,
+		window:   10 * time.Second,
+		sources:  make(map[string]*knockState),
+	}
+
+	now := time.Now()
+	k.knock("10.0.0.1", sequence[0], now)
+	k.knock("10.0.0.2", sequence[0], now)
+	if k.knock("10.0.0.3", sequence[1], now) {
+		t.Fatal("expected each source to have its own sequence")
+	} else if !k.knock("10.0.0.2", sequence[1], now) {
+		t.Fatal("expected 10.0.0.2 to complete")
+	} else if _, found := k.sources["10.0.0.1"]; !found {
+		t.Fatal("expected 10.0.0.1 to be still knocking")
+	}
+}
@@ -1,10 +1,13 @@
 package modules
 
 import (
+	"crypto/rand"
 	"fmt"
 	"net"
 	"runtime"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/bettercap/bettercap/core"
 	"github.com/bettercap/bettercap/log"
@@ -12,11 +15,24 @@ import (
 	"github.com/bettercap/bettercap/session"
 )
 
+// modules capturing on the interface, changing the address (and bringing
+// the interface down) while they run would make them drop packets
+var macRotationBlockers = []string{"wifi", "net.sniff"}
+
+// MacChanged is the payload of mac.changed events.
+type MacChanged struct {
+	Interface string `json:"interface"`
+	Address   string `json:"address"`
+	Previous  string `json:"previous"`
+}
+
 type MacChanger struct {
 	session.SessionModule
 	iface       string
 	originalMac net.HardwareAddr
 	fakeMac     net.HardwareAddr
+	rotate      time.Duration
+	lock        sync.Mutex
 }
 
 func NewMacChanger(s *session.Session) *MacChanger {
@@ -34,6 +50,10 @@ func NewMacChanger(s *session.Session) *MacChanger {
 		"[a-fA-F0-9]{2}:[a-fA-F0-9]{2}:[a-fA-F0-9]{2}:[a-fA-F0-9]{2}:[a-fA-F0-9]{2}:[a-fA-F0-9]{2}",
 		"Hardware address to apply to the interface."))
 
+	mc.AddParam(session.NewIntParameter("mac.changer.rotate.interval",
+		"0",
+		"If greater than 0, a new random address is applied every this many seconds while the module is running, rotation is paused while wifi or net.sniff are capturing."))
+
 	mc.AddHandler(session.NewModuleHandler("mac.changer on", "",
 		"Start mac changer module.",
 		func(args []string) error {
@@ -61,15 +81,30 @@ func (mc *MacChanger) Author() string {
 	return "Simone Margaritelli <evilsocket@protonmail.com>"
 }
 
+func randomMac() net.HardwareAddr {
+	hw := make([]byte, 6)
+	rand.Read(hw)
+	// unicast and locally administered
+	hw[0] = (hw[0] | 0x02) &^ 0x01
+	return net.HardwareAddr(hw)
+}
+
 func (mc *MacChanger) Configure() (err error) {
 	var changeTo string
+	var rotate int
 
 	if err, mc.iface = mc.StringParam("mac.changer.iface"); err != nil {
 		return err
 	} else if err, changeTo = mc.StringParam("mac.changer.address"); err != nil {
 		return err
+	} else if err, rotate = mc.IntParam("mac.changer.rotate.interval"); err != nil {
+		return err
+	} else if rotate < 0 {
+		return fmt.Errorf("mac.changer.rotate.interval can't be negative")
 	}
 
+	mc.rotate = time.Duration(rotate) * time.Second
+
 	changeTo = network.NormalizeMac(changeTo)
 	if mc.fakeMac, err = net.ParseMAC(changeTo); err != nil {
 		return err
@@ -93,13 +128,70 @@ func (mc *MacChanger) setMac(mac net.HardwareAddr) error {
 	}
 
 	_, err := core.Exec("ifconfig", args)
+	if err != nil && (os == "linux" || os == "android") {
+		// some drivers only accept a new address while the interface is down
+		if _, err = core.Exec("ifconfig", []string{mc.iface, "down"}); err == nil {
+			_, err = core.Exec("ifconfig", args)
+			if _, upErr := core.Exec("ifconfig", []string{mc.iface, "up"}); err == nil {
+				err = upErr
+			}
+		}
+	}
+
 	if err == nil {
+		prev := mc.Session.Interface.HW
 		mc.Session.Interface.HW = mac
+		mc.Session.Events.Add("mac.changed", MacChanged{
+			Interface: mc.iface,
+			Address:   mac.String(),
+			Previous:  prev.String(),
+		})
 	}
 
 	return err
 }
 
+// rotationBlocker returns the name of the first running module which
+// should not be disturbed by an address change, if any.
+func (mc *MacChanger) rotationBlocker() string {
+	for _, name := range macRotationBlockers {
+		if mc.Session.IsOn(name) {
+			return name
+		}
+	}
+	return ""
+}
+
+func (mc *MacChanger) rotator() {
+	last := time.Now()
+	for mc.Running() {
+		time.Sleep(1 * time.Second)
+		if !mc.Running() || mc.Session.Paused() || time.Since(last) < mc.rotate {
+			continue
+		}
+
+		if blocker := mc.rotationBlocker(); blocker != "" {
+			log.Debug("%s is running, postponing mac address rotation.", blocker)
+			continue
+		}
+
+		last = time.Now()
+		mac := randomMac()
+
+		// don't race with Stop restoring the original address
+		mc.lock.Lock()
+		if !mc.Running() {
+			mc.lock.Unlock()
+			break
+		} else if err := mc.setMac(mac); err != nil {
+			log.Error("Error while rotating mac address: %s", err)
+		} else {
+			log.Info("Interface mac address rotated to %s", core.Bold(mac.String()))
+		}
+		mc.lock.Unlock()
+	}
+}
+
 func (mc *MacChanger) Start() error {
 	if mc.Running() {
 		return session.ErrAlreadyStarted
@@ -111,11 +203,18 @@ func (mc *MacChanger) Start() error {
 
 	return mc.SetRunning(true, func() {
 		log.Info("Interface mac address set to %s", core.Bold(mc.fakeMac.String()))
+		if mc.rotate > 0 {
+			log.Info("Rotating the interface mac address every %s", mc.rotate)
+			mc.rotator()
+		}
 	})
 }
 
 func (mc *MacChanger) Stop() error {
 	return mc.SetRunning(false, func() {
+		mc.lock.Lock()
+		defer mc.lock.Unlock()
+
 		if err := mc.setMac(mc.originalMac); err == nil {
 			log.Info("Interface mac address restored to %s", core.Bold(mc.originalMac.String()))
 		} else {
@@ -0,0 +1,15 @@
+package modules
+
+import (
+	"github.com/bettercap/bettercap/session"
+)
+
+// testSession sets up the bare session the log functions need.
+func testSession() *session.Session {
+	if session.I == nil {
+		session.I = &session.Session{
+			Events: session.NewEventPool(false, true),
+		}
+	}
+	return session.I
+}
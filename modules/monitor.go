@@ -0,0 +1,365 @@
+package modules
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bettercap/bettercap/core"
+	"github.com/bettercap/bettercap/log"
+	"github.com/bettercap/bettercap/session"
+)
+
+const monitorPollPeriod = 1 * time.Second
+
+// kinds of devices, they also are the keys of the known baseline addresses
+var monitorKinds = []string{"lan", "wifi.ap", "wifi.ssid", "wifi.client", "ble"}
+
+// MonitorAnomaly is the payload of monitor.anomaly events.
+type MonitorAnomaly struct {
+	Kind    string `json:"kind"`
+	Address string `json:"address"`
+	Name    string `json:"name"`
+	Vendor  string `json:"vendor"`
+}
+
+// MonitorBaselineSummary is the payload of monitor.learned events.
+type MonitorBaselineSummary struct {
+	Started   time.Time      `json:"started"`
+	Completed time.Time      `json:"completed"`
+	Known     map[string]int `json:"known"`
+}
+
+// monitorBaseline is the set of addresses seen while learning, it is saved
+// every time new ones are learned too, so that a restart resumes it.
+type monitorBaseline struct {
+	Started   time.Time                  `json:"started"`
+	Completed time.Time                  `json:"completed"`
+	Known     map[string]map[string]bool `json:"known"`
+}
+
+func newMonitorBaseline() *monitorBaseline {
+	b := &monitorBaseline{
+		Started: time.Now(),
+		Known:   make(map[string]map[string]bool),
+	}
+	for _, kind := range monitorKinds {
+		b.Known[kind] = make(map[string]bool)
+	}
+	return b
+}
+
+func (b *monitorBaseline) learning() bool {
+	return b.Completed.IsZero()
+}
+
+func (b *monitorBaseline) has(dev MonitorAnomaly) bool {
+	return b.Known[dev.Kind][dev.Address]
+}
+
+// add returns true if the address wasn't known yet.
+func (b *monitorBaseline) add(dev MonitorAnomaly) bool {
+	if _, found := b.Known[dev.Kind]; !found {
+		b.Known[dev.Kind] = make(map[string]bool)
+	} else if b.Known[dev.Kind][dev.Address] {
+		return false
+	}
+	b.Known[dev.Kind][dev.Address] = true
+	return true
+}
+
+func (b *monitorBaseline) summary() MonitorBaselineSummary {
+	s := MonitorBaselineSummary{
+		Started:   b.Started,
+		Completed: b.Completed,
+		Known:     make(map[string]int),
+	}
+	for kind, addresses := range b.Known {
+		s.Known[kind] = len(addresses)
+	}
+	return s
+}
+
+func loadMonitorBaseline(fileName string) (error, *monitorBaseline) {
+	raw, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return err, nil
+	}
+
+	b := newMonitorBaseline()
+	if err = json.Unmarshal(raw, b); err != nil {
+		return fmt.Errorf("could not parse the baseline %s: %s", fileName, err), nil
+	}
+	return nil, b
+}
+
+func (b *monitorBaseline) save(fileName string) error {
+	raw, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(fileName, raw, 0644)
+}
+
+type Monitor struct {
+	session.SessionModule
+	lock     *sync.Mutex
+	baseline *monitorBaseline
+	duration time.Duration
+	fileName string
+	reported map[string]bool
+	quit     chan bool
+}
+
+func NewMonitor(s *session.Session) *Monitor {
+	m := &Monitor{
+		SessionModule: session.NewSessionModule("monitor", s),
+		lock:          &sync.Mutex{},
+		reported:      make(map[string]bool),
+	}
+
+	m.EmitsEvents("monitor.")
+
+	m.AddParam(session.NewIntParameter("monitor.baseline.duration",
+		"3600",
+		"Seconds to spend learning the hosts, access points, clients and BLE devices normally around before alerting on new ones."))
+
+	m.AddParam(session.NewStringParameter("monitor.baseline.file",
+		"~/bettercap-baseline.json",
+		"",
+		"File the baseline is saved to and loaded from, so that a restart doesn't learn it again."))
+
+	m.AddHandler(session.NewModuleHandler("monitor on", "",
+		"Start learning the baseline, or alerting on the devices it doesn't have if already learned.",
+		func(args []string) error {
+			return m.Start()
+		}))
+
+	m.AddHandler(session.NewModuleHandler("monitor off", "",
+		"Stop the monitor, the baseline is saved.",
+		func(args []string) error {
+			return m.Stop()
+		}))
+
+	m.AddHandler(session.NewModuleHandler("monitor.show", "",
+		"Show the status of the baseline.",
+		func(args []string) error {
+			return m.Show()
+		}))
+
+	m.AddHandler(session.NewModuleHandler("monitor.reset", "",
+		"Forget the baseline and learn it again.",
+		func(args []string) error {
+			return m.Reset()
+		}))
+
+	return m
+}
+
+func (m Monitor) Name() string {
+	return "monitor"
+}
+
+func (m Monitor) Description() string {
+	return "Learns the devices normally around and raises an event for every new one."
+}
+
+func (m Monitor) Author() string {
+	return "Simone Margaritelli <evilsocket@protonmail.com>"
+}
+
+func (m *Monitor) Configure() error {
+	var err error
+	var duration int
+
+	if m.Running() {
+		return session.ErrAlreadyStarted
+	} else if err, duration = m.IntParam("monitor.baseline.duration"); err != nil {
+		return err
+	} else if duration <= 0 {
+		return fmt.Errorf("monitor.baseline.duration must be greater than 0")
+	} else if err, m.fileName = m.StringParam("monitor.baseline.file"); err != nil {
+		return err
+	} else if m.fileName, err = core.ExpandPath(m.fileName); err != nil {
+		return err
+	}
+
+	m.duration = time.Duration(duration) * time.Second
+	m.quit = make(chan bool)
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if err, baseline := loadMonitorBaseline(m.fileName); err == nil {
+		m.baseline = baseline
+	} else if os.IsNotExist(err) {
+		m.baseline = newMonitorBaseline()
+	} else {
+		return err
+	}
+
+	return nil
+}
+
+// devices returns what the discovery tables currently hold.
+func (m *Monitor) devices() []MonitorAnomaly {
+	devices := make([]MonitorAnomaly, 0)
+
+	for _, e := range m.Session.Lan.List() {
+		devices = append(devices, MonitorAnomaly{Kind: "lan", Address: e.HwAddress, Name: e.Hostname, Vendor: e.Vendor})
+	}
+
+	for _, ap := range m.Session.WiFi.List() {
+		devices = append(devices, MonitorAnomaly{Kind: "wifi.ap", Address: ap.BSSID(), Name: ap.ESSID(), Vendor: ap.Vendor})
+		if essid := ap.ESSID(); essid != "" {
+			devices = append(devices, MonitorAnomaly{Kind: "wifi.ssid", Address: essid, Name: essid})
+		}
+		for _, client := range ap.Clients() {
+			devices = append(devices, MonitorAnomaly{Kind: "wifi.client", Address: client.HwAddress, Name: ap.ESSID(), Vendor: client.Vendor})
+		}
+	}
+
+	return append(devices, monitorBLEDevices(m.Session)...)
+}
+
+func (m *Monitor) check(devices []MonitorAnomaly) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.baseline.learning() {
+		learned := false
+		for _, dev := range devices {
+			if m.baseline.add(dev) {
+				learned = true
+			}
+		}
+
+		completed := time.Since(m.baseline.Started) >= m.duration
+		if completed {
+			m.baseline.Completed = time.Now()
+		}
+
+		if learned || completed {
+			if err := m.baseline.save(m.fileName); err != nil {
+				log.Error("could not save the baseline to %s: %s", m.fileName, err)
+			}
+		}
+
+		if completed {
+			summary := m.baseline.summary()
+			log.Info("baseline learned, saved to %s, alerting on new devices from now on.", core.Bold(m.fileName))
+			m.Session.Events.Add("monitor.learned", summary)
+		}
+		return
+	}
+
+	for _, dev := range devices {
+		key := dev.Kind + "|" + dev.Address
+		if !m.baseline.has(dev) && !m.reported[key] {
+			m.reported[key] = true
+			m.Session.Events.Add("monitor.anomaly", dev)
+		}
+	}
+}
+
+func (m *Monitor) Show() error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.baseline == nil {
+		return fmt.Errorf("No baseline loaded, start the module first.")
+	}
+
+	summary := m.baseline.summary()
+	fmt.Println()
+	if m.baseline.learning() {
+		left := m.duration - time.Since(m.baseline.Started)
+		if left < 0 {
+			left = 0
+		}
+		fmt.Printf("  %s, %s left.\n\n", core.Yellow("learning"), left.Round(time.Second))
+	} else {
+		fmt.Printf("  %s since %s, %d new devices reported.\n\n", core.Green("alerting"), summary.Completed.Format("2006-01-02 15:04:05"), len(m.reported))
+	}
+
+	kinds := make([]string, 0)
+	for kind := range summary.Known {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	rows := make([][]string, 0)
+	for _, kind := range kinds {
+		rows = append(rows, []string{kind, fmt.Sprintf("%d", summary.Known[kind])})
+	}
+	core.AsTable(os.Stdout, []string{"Kind", "Known"}, rows)
+	fmt.Println()
+
+	return nil
+}
+
+func (m *Monitor) Reset() error {
+	if err := m.Configure(); err != nil && err != session.ErrAlreadyStarted {
+		return err
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.baseline = newMonitorBaseline()
+	m.reported = make(map[string]bool)
+	if err := os.Remove(m.fileName); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	log.Info("baseline cleared, learning it again for %s.", m.duration)
+	return nil
+}
+
+func (m *Monitor) Start() error {
+	if err := m.Configure(); err != nil {
+		return err
+	}
+
+	return m.SetRunning(true, func() {
+		m.lock.Lock()
+		if m.baseline.learning() {
+			left := m.duration - time.Since(m.baseline.Started)
+			if left < 0 {
+				left = 0
+			}
+			log.Info("learning the baseline for %s ...", left.Round(time.Second))
+		} else {
+			log.Info("using the baseline learned on %s.", m.baseline.Completed.Format("2006-01-02 15:04:05"))
+		}
+		m.lock.Unlock()
+
+		ticker := time.NewTicker(monitorPollPeriod)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				m.check(m.devices())
+			case <-m.quit:
+				return
+			}
+		}
+	})
+}
+
+func (m *Monitor) Stop() error {
+	return m.SetRunning(false, func() {
+		m.quit <- true
+
+		m.lock.Lock()
+		defer m.lock.Unlock()
+		if err := m.baseline.save(m.fileName); err != nil {
+			log.Error("could not save the baseline to %s: %s", m.fileName, err)
+		}
+	})
+}
@@ -0,0 +1,22 @@
+// +build !windows
+// +build !darwin
+
+package modules
+
+import (
+	"github.com/bettercap/bettercap/network"
+	"github.com/bettercap/bettercap/session"
+)
+
+func monitorBLEDevices(s *session.Session) []MonitorAnomaly {
+	devices := make([]MonitorAnomaly, 0)
+	for _, dev := range s.BLE.Devices() {
+		devices = append(devices, MonitorAnomaly{
+			Kind:    "ble",
+			Address: network.NormalizeMac(dev.Device.ID()),
+			Name:    dev.Device.Name(),
+			Vendor:  dev.Vendor,
+		})
+	}
+	return devices
+}
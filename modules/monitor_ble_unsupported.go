@@ -0,0 +1,11 @@
+// +build windows darwin
+
+package modules
+
+import (
+	"github.com/bettercap/bettercap/session"
+)
+
+func monitorBLEDevices(s *session.Session) []MonitorAnomaly {
+	return nil
+}
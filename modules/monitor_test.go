@@ -0,0 +1,130 @@
+package modules
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bettercap/bettercap/session"
+)
+
+func countEvents(s *session.Session, tag string) int {
+	n := 0
+	for _, e := range s.Events.Sorted() {
+		if e.Tag == tag {
+			n++
+		}
+	}
+	return n
+}
+
+func newTestMonitor(fileName string) *Monitor {
+	m := &Monitor{
+		lock:     &sync.Mutex{},
+		baseline: newMonitorBaseline(),
+		duration: time.Hour,
+		fileName: fileName,
+		reported: make(map[string]bool),
+	}
+	m.Session = testSession()
+	return m
+}
+
+func TestMonitorBaseline(t *testing.T) {
+	b := newMonitorBaseline()
+	host := MonitorAnomaly{Kind: "lan", Address: "aa:bb:cc:dd:ee:ff"}
+
+	if !b.learning() {
+		t.Fatal("expected a new baseline to be learning")
+	} else if !b.add(host) {
+		t.Fatal("expected the host to be new")
+	} else if b.add(host) {
+		t.Fatal("expected the host to be known")
+	} else if !b.add(MonitorAnomaly{Kind: "unknown", Address: "x"}) {
+		t.Fatal("expected a new kind to be added")
+	} else if !b.has(host) || b.has(MonitorAnomaly{Kind: "ble", Address: host.Address}) {
+		t.Fatal("expected addresses to be known per kind")
+	}
+
+	summary := b.summary()
+	if summary.Known["lan"] != 1 || summary.Known["unknown"] != 1 || summary.Known["ble"] != 0 {
+		t.Fatalf("unexpected summary %v", summary.Known)
+	}
+}
+
+func TestMonitorBaselineSaveLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bettercap-monitor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	fileName := filepath.Join(dir, "baseline.json")
+	if err, _ := loadMonitorBaseline(fileName); !os.IsNotExist(err) {
+		t.Fatalf("expected a missing file error, got %v", err)
+	}
+
+	b := newMonitorBaseline()
+	b.add(MonitorAnomaly{Kind: "wifi.ap", Address: "00:11:22:33:44:55"})
+	if err := b.save(fileName); err != nil {
+		t.Fatal(err)
+	}
+
+	err, loaded := loadMonitorBaseline(fileName)
+	if err != nil {
+		t.Fatal(err)
+	} else if !loaded.learning() || !loaded.has(MonitorAnomaly{Kind: "wifi.ap", Address: "00:11:22:33:44:55"}) {
+		t.Fatalf("unexpected baseline %+v", loaded)
+	} else if len(loaded.Known["lan"]) != 0 || loaded.Known["lan"] == nil {
+		t.Fatal("expected the missing kinds to be initialized")
+	}
+
+	ioutil.WriteFile(fileName, []byte("{"), 0644)
+	if err, _ := loadMonitorBaseline(fileName); err == nil {
+		t.Fatal("expected a parse error")
+	}
+}
+
+func TestMonitorCheck(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bettercap-monitor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	fileName := filepath.Join(dir, "baseline.json")
+	m := newTestMonitor(fileName)
+	known := MonitorAnomaly{Kind: "lan", Address: "aa:bb:cc:dd:ee:ff"}
+	intruder := MonitorAnomaly{Kind: "lan", Address: "aa:bb:cc:dd:ee:00"}
+
+	// the baseline is saved as soon as something is learned
+	m.check([]MonitorAnomaly{known})
+	if err, saved := loadMonitorBaseline(fileName); err != nil {
+		t.Fatalf("expected the baseline to be saved while learning: %s", err)
+	} else if !saved.learning() || !saved.has(known) {
+		t.Fatalf("unexpected saved baseline %+v", saved)
+	}
+
+	learned := countEvents(m.Session, "monitor.learned")
+	m.baseline.Started = time.Now().Add(-2 * time.Hour)
+	m.check([]MonitorAnomaly{known})
+	if m.baseline.learning() {
+		t.Fatal("expected the baseline to be learned")
+	} else if n := countEvents(m.Session, "monitor.learned"); n != learned+1 {
+		t.Fatalf("expected a monitor.learned event, got %d", n-learned)
+	} else if err, saved := loadMonitorBaseline(fileName); err != nil || saved.learning() {
+		t.Fatalf("expected the completed baseline to be saved: %v", err)
+	}
+
+	anomalies := countEvents(m.Session, "monitor.anomaly")
+	m.check([]MonitorAnomaly{known, intruder})
+	m.check([]MonitorAnomaly{known, intruder})
+	if n := countEvents(m.Session, "monitor.anomaly"); n != anomalies+1 {
+		t.Fatalf("expected a single monitor.anomaly event, got %d", n-anomalies)
+	} else if m.baseline.has(intruder) {
+		t.Fatal("expected new devices not to be learned once alerting")
+	}
+}
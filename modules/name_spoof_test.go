@@ -0,0 +1,106 @@
+package modules
+
+import (
+	"net"
+	"testing"
+
+	"github.com/bettercap/bettercap/packets"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+func nameQuery(names ...string) *layers.DNS {
+	req := &layers.DNS{ID: 0x1234, OpCode: layers.DNSOpCodeQuery}
+	for _, name := range names {
+		req.Questions = append(req.Questions, layers.DNSQuestion{
+			Name:  []byte(name),
+			Type:  layers.DNSTypeA,
+			Class: layers.DNSClassIN,
+		})
+	}
+	req.QDCount = uint16(len(req.Questions))
+	return req
+}
+
+// decodeNameReply makes sure the reply is valid on the wire.
+func decodeNameReply(t *testing.T, reply *layers.DNS) *layers.DNS {
+	err, raw := packets.Serialize(reply)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded := &layers.DNS{}
+	if err = decoded.DecodeFromBytes(raw, gopacket.NilDecodeFeedback); err != nil {
+		t.Fatal(err)
+	}
+	return decoded
+}
+
+func TestBuildNameReply(t *testing.T) {
+	address := net.ParseIP("192.168.1.5").To4()
+
+	cases := []struct {
+		protocol  string
+		mdns      bool
+		legacy    bool
+		questions int
+		class     layers.DNSClass
+	}{
+		{"llmnr", false, false, 2, layers.DNSClassIN},
+		// LLMNR queries are always unicast answered so legacy is ignored
+		{"llmnr", false, true, 2, layers.DNSClassIN},
+		{"mdns", true, false, 0, layers.DNSClass(0x8001)},
+		{"mdns legacy unicast", true, true, 2, layers.DNSClassIN},
+	}
+
+	for _, c := range cases {
+		req := nameQuery("wpad", "filesrv.local")
+		reply := decodeNameReply(t, buildNameReply(req, []string{"wpad", "filesrv.local"}, address, c.mdns, c.legacy))
+
+		if reply.ID != req.ID || !reply.QR || reply.OpCode != layers.DNSOpCodeQuery {
+			t.Fatalf("%s: unexpected header %+v", c.protocol, reply)
+		} else if reply.AA != c.mdns {
+			t.Fatalf("%s: expected AA=%v", c.protocol, c.mdns)
+		} else if len(reply.Questions) != c.questions {
+			t.Fatalf("%s: expected %d questions, got %d", c.protocol, c.questions, len(reply.Questions))
+		} else if len(reply.Answers) != 2 {
+			t.Fatalf("%s: expected 2 answers, got %d", c.protocol, len(reply.Answers))
+		}
+
+		for i, answer := range reply.Answers {
+			if string(answer.Name) != string(req.Questions[i].Name) {
+				t.Fatalf("%s: unexpected answer name %s", c.protocol, answer.Name)
+			} else if answer.Type != layers.DNSTypeA || answer.Class != c.class || answer.TTL != 30 {
+				t.Fatalf("%s: unexpected answer %+v", c.protocol, answer)
+			} else if !answer.IP.Equal(address) {
+				t.Fatalf("%s: unexpected address %s", c.protocol, answer.IP)
+			}
+		}
+	}
+}
+
+func TestNameSpooferMatches(t *testing.T) {
+	address := net.ParseIP("192.168.1.5")
+	s := &NameSpoofer{}
+	if !s.matches("anything") {
+		t.Fatal("expected every name to match without name.spoof.names")
+	}
+
+	s.Names = Hosts{NewHostEntry("wpad", address), NewHostEntry("*.corp", address)}
+	cases := map[string]bool{
+		"wpad":           true,
+		"WPAD.":          true,
+		"wpad.local":     true,
+		"wpad.local.":    true,
+		"srv.corp":       true,
+		"srv.corp.local": true,
+		"filesrv":        false,
+		"wpad.lan":       false,
+	}
+	for name, expected := range cases {
+		if got := s.matches(name); got != expected {
+			t.Fatalf("%s: expected %v, got %v", name, expected, got)
+		}
+	}
+}
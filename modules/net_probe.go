@@ -1,13 +1,17 @@
 package modules
 
 import (
+	"fmt"
+	"net"
 	"sync"
 	"time"
 
 	"github.com/bettercap/bettercap/log"
 	"github.com/bettercap/bettercap/network"
+	"github.com/bettercap/bettercap/packets"
 	"github.com/bettercap/bettercap/session"
 
+	"github.com/google/gopacket/layers"
 	"github.com/malfunkt/iprange"
 )
 
@@ -97,7 +101,9 @@ func (p *Prober) Configure() error {
 }
 
 func (p *Prober) Start() error {
-	if err := p.Configure(); err != nil {
+	if p.Session.ReconPassive() {
+		return fmt.Errorf("net.recon.passive is enabled, net.probe would send packets to every host of the subnet.")
+	} else if err := p.Configure(); err != nil {
 		return err
 	}
 
@@ -120,7 +126,7 @@ func (p *Prober) Start() error {
 		addresses := list.Expand()
 		throttle := time.Duration(p.throttle) * time.Millisecond
 
-		for p.Running() {
+		for p.WaitIfPaused() {
 			if p.probes.MDNS {
 				p.sendProbeMDNS(from, from_hw)
 			}
@@ -134,7 +140,7 @@ func (p *Prober) Start() error {
 			}
 
 			for _, ip := range addresses {
-				if !p.Running() {
+				if !p.WaitIfPaused() {
 					return
 				} else if p.Session.Skip(ip) {
 					log.Debug("skipping address %s from probing.", ip)
@@ -155,3 +161,12 @@ func (p *Prober) Stop() error {
 		p.waitGroup.Wait()
 	})
 }
+
+// dryRunUDP returns true if a probe that would be sent through a UDP socket
+// must only be reported because main.dryrun is enabled.
+func dryRunUDP(s *session.Session, to net.IP, port int, payload []byte) bool {
+	if err, raw := packets.NewUDPDatagram(s.Interface.IP, to, port, payload); err == nil {
+		return s.Queue.DryRun(layers.LinkTypeRaw, raw)
+	}
+	return false
+}
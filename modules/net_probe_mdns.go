@@ -12,7 +12,7 @@ func (p *Prober) sendProbeMDNS(from net.IP, from_hw net.HardwareAddr) {
 	if err != nil {
 		log.Error("error while sending mdns probe: %v", err)
 		return
-	} else if err := p.Session.Queue.Send(raw); err != nil {
+	} else if err := p.Send(raw); err != nil {
 		log.Error("error sending mdns packet: %s", err)
 	} else {
 		log.Debug("sent %d bytes of MDNS probe", len(raw))
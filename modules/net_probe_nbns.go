@@ -10,7 +10,9 @@ import (
 
 func (p *Prober) sendProbeNBNS(from net.IP, from_hw net.HardwareAddr, ip net.IP) {
 	name := fmt.Sprintf("%s:%d", ip, packets.NBNSPort)
-	if addr, err := net.ResolveUDPAddr("udp", name); err != nil {
+	if dryRunUDP(p.Session, ip, packets.NBNSPort, packets.NBNSRequest) {
+		return
+	} else if addr, err := net.ResolveUDPAddr("udp", name); err != nil {
 		log.Debug("could not resolve %s.", name)
 	} else if con, err := net.DialUDP("udp", nil, addr); err != nil {
 		log.Debug("could not dial %s.", name)
@@ -6,21 +6,29 @@ import (
 
 	"github.com/bettercap/bettercap/log"
 	"github.com/bettercap/bettercap/packets"
+	"github.com/bettercap/bettercap/session"
 )
 
-func (p *Prober) sendProbeUPNP(from net.IP, from_hw net.HardwareAddr) {
+// sendUPNPDiscovery sends a SSDP M-SEARCH request, responses are parsed
+// by the packets queue and added to the endpoints meta.
+func sendUPNPDiscovery(s *session.Session) {
 	name := fmt.Sprintf("%s:%d", packets.UPNPDestIP, packets.UPNPPort)
-	if addr, err := net.ResolveUDPAddr("udp", name); err != nil {
+	if dryRunUDP(s, packets.UPNPDestIP, packets.UPNPPort, packets.UPNPDiscoveryPayload) {
+		return
+	} else if addr, err := net.ResolveUDPAddr("udp", name); err != nil {
 		log.Debug("could not resolve %s.", name)
 	} else if con, err := net.DialUDP("udp", nil, addr); err != nil {
 		log.Debug("could not dial %s.", name)
 	} else {
 		defer con.Close()
 		if wrote, _ := con.Write(packets.UPNPDiscoveryPayload); wrote > 0 {
-			p.Session.Queue.TrackSent(uint64(wrote))
+			s.Queue.TrackSent(uint64(wrote))
 		} else {
-			p.Session.Queue.TrackError()
+			s.Queue.TrackError()
 		}
 	}
+}
 
+func (p *Prober) sendProbeUPNP(from net.IP, from_hw net.HardwareAddr) {
+	sendUPNPDiscovery(p.Session)
 }
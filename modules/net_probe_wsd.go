@@ -10,7 +10,9 @@ import (
 
 func (p *Prober) sendProbeWSD(from net.IP, from_hw net.HardwareAddr) {
 	name := fmt.Sprintf("%s:%d", packets.WSDDestIP, packets.WSDPort)
-	if addr, err := net.ResolveUDPAddr("udp", name); err != nil {
+	if dryRunUDP(p.Session, packets.WSDDestIP, packets.WSDPort, packets.WSDDiscoveryPayload) {
+		return
+	} else if addr, err := net.ResolveUDPAddr("udp", name); err != nil {
 		log.Debug("could not resolve %s.", name)
 	} else if con, err := net.DialUDP("udp", nil, addr); err != nil {
 		log.Debug("could not dial %s.", name)
@@ -1,22 +1,52 @@
 package modules
 
 import (
+	"sync"
 	"time"
 
-	"github.com/bettercap/bettercap/log"
 	"github.com/bettercap/bettercap/network"
+	"github.com/bettercap/bettercap/packets"
 	"github.com/bettercap/bettercap/session"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/pcap"
 )
 
+// how long a host on a tagged VLAN is kept after it's been seen last
+const vlanHostTimeout = 5 * time.Minute
+
 type Discovery struct {
 	session.SessionModule
+	ssdp       bool
+	ssdpFetch  bool
+	ssdpSeen   map[string]bool
+	ssdpLock   *sync.Mutex
+	lastSearch time.Time
+	lastShown  map[string]endpointSnapshot
+	vlan       *packets.VLANFilter
+	arpRefresh int
+	passive    bool
+	arpProbed  map[string]time.Time
+
+	blocklist       *blocklist
+	blocklistHits   map[string]bool
+	blocklistLock   *sync.Mutex
+	blocklistHandle *pcap.Handle
+	blocklistChan   chan gopacket.Packet
 }
 
 func NewDiscovery(s *session.Session) *Discovery {
 	d := &Discovery{
 		SessionModule: session.NewSessionModule("net.recon", s),
+		ssdpSeen:      make(map[string]bool),
+		ssdpLock:      &sync.Mutex{},
+		arpProbed:     make(map[string]time.Time),
+		blocklistHits: make(map[string]bool),
+		blocklistLock: &sync.Mutex{},
 	}
 
+	d.EmitsEvents("endpoint", "net.recon")
+
 	d.AddHandler(session.NewModuleHandler("net.recon on", "",
 		"Start network hosts discovery.",
 		func(args []string) error {
@@ -29,6 +59,51 @@ func NewDiscovery(s *session.Session) *Discovery {
 			return d.Stop()
 		}))
 
+	d.AddLogParams()
+
+	d.AddParam(session.NewBoolParameter("net.recon.os.guess",
+		"false",
+		"If true, guess the operating system of hosts from the TTL and TCP window size of their SYN packets, this is just a heuristic."))
+
+	d.AddParam(session.NewBoolParameter("net.recon.classify",
+		"false",
+		"If true, guess the kind of device (phone, laptop, IoT or printer) of hosts from their DHCP requests, plain text HTTP User-Agent and announced mDNS services, this is just a heuristic."))
+
+	d.AddParam(session.NewStringParameter("net.recon.vlan",
+		"",
+		"",
+		"If set, comma separated list of 802.1Q VLAN IDs hosts are discovered on, 0 for untagged frames and OUTER.INNER for QinQ ones (a single ID matches the inner tag), hosts on tagged VLANs are discovered from their ARP traffic."))
+
+	d.AddParam(session.NewBoolParameter("net.recon.passive",
+		"false",
+		"If true, never send anything to discover or resolve hosts (ARP refresh, SSDP, reverse DNS, net.inspect and net.probe scans), the hosts are only discovered from the traffic seen on the wire."))
+
+	d.AddParam(session.NewIntParameter("net.recon.arp.refresh",
+		"0",
+		"If greater than 0, send an ARP request every this number of seconds to the hosts which haven't been seen on the wire yet or whose MAC address couldn't be resolved, until they answer."))
+
+	d.AddParam(session.NewBoolParameter("net.recon.ssdp",
+		"false",
+		"If true, periodically send SSDP discovery requests and enrich hosts with the UPnP information they announce."))
+
+	d.AddParam(session.NewBoolParameter("net.recon.ssdp.fetch",
+		"true",
+		"If true and net.recon.ssdp is enabled, fetch the UPnP description of each device to get its name, manufacturer and model."))
+
+	d.AddParam(session.NewStringParameter("net.recon.blocklist",
+		"",
+		"",
+		"If not empty, path of a file with one IP address, CIDR or domain per line, hosts talking to or resolving any of them are flagged as suspicious and a net.recon.blocklist.hit event is emitted, the file is reloaded when it changes."))
+
+	d.AddParam(session.NewStringParameter("net.inspect.ports",
+		defaultInspectPorts,
+		"",
+		"Comma separated list of TCP ports net.inspect will quickly SYN scan."))
+
+	d.AddParam(session.NewIntParameter("net.inspect.timeout",
+		"5",
+		"Maximum number of seconds net.inspect will spend actively probing the target."))
+
 	d.AddParam(session.NewBoolParameter("net.show.meta",
 		"true",
 		"If true, the net.show command will show all metadata collected about each endpoint."))
@@ -57,6 +132,51 @@ func NewDiscovery(s *session.Session) *Discovery {
 			return d.Show("rcvd", "")
 		}))
 
+	d.AddHandler(session.NewModuleHandler("net.show.diff", "",
+		"Show only the hosts that joined, changed or left since the last net.show or net.show.diff.",
+		func(args []string) error {
+			return d.ShowDiff()
+		}))
+
+	d.AddHandler(session.NewModuleHandler("net.inspect ADDRESS", `net\.inspect\s+((?:[0-9A-Fa-f]{2}[:-]){5}[0-9A-Fa-f]{2}|[\d\.]+)`,
+		"Quickly scan the most common ports of an IP or MAC address and show everything known about it.",
+		func(args []string) error {
+			return d.Inspect(args[0])
+		}))
+
+	d.AddHandler(session.NewModuleHandler("net.arp.dump", "",
+		"Show the neighbor table of the operating system for the current interface, correlated with the discovered hosts.",
+		func(args []string) error {
+			return d.ArpDump()
+		}))
+
+	d.AddHandler(session.NewModuleHandler("net.arp.import", "",
+		"Add the hosts in the neighbor table of the operating system to the discovered ones, they're shown as unconfirmed until seen on the wire.",
+		func(args []string) error {
+			return d.ArpImport()
+		}))
+
+	d.AddHandler(session.NewModuleHandler("net.recon.multicast", "",
+		"Show the multicast groups joined by each host, as detected from their IGMP membership reports.",
+		func(args []string) error {
+			return d.ShowMulticast()
+		}))
+
+	d.AddParam(session.NewStringParameter("net.graph.output",
+		"~/bettercap-lan.dot",
+		"",
+		"File net.graph will save the Graphviz DOT description of the LAN to, if its extension is .svg it will be rendered with the dot executable."))
+
+	d.AddHandler(session.NewModuleHandler("net.graph", "",
+		"Save the gateway and the discovered hosts as a Graphviz graph to net.graph.output.",
+		func(args []string) error {
+			if err, output := d.StringParam("net.graph.output"); err != nil {
+				return err
+			} else {
+				return writeGraph(output, lanGraph(d.Session))
+			}
+		}))
+
 	d.AddHandler(session.NewModuleHandler("net.show ADDRESS", `net.show ([\d\.]+)`,
 		"Show information about a specific address.",
 		func(args []string) error {
@@ -79,11 +199,20 @@ func (d Discovery) Author() string {
 }
 
 func (d *Discovery) runDiff(cache network.ArpTable) {
+	// the ARP cache of the interface only has hosts of the untagged network
+	untagged := d.vlan == nil || d.vlan.Untagged()
+
 	// check for endpoints who disappeared
 	var rem network.ArpTable = make(network.ArpTable)
 
 	d.Session.Lan.EachHost(func(mac string, e *network.Endpoint) {
-		if _, found := cache[mac]; !found {
+		if e.VLAN() != 0 {
+			// hosts on tagged VLANs are never in the ARP cache, they're
+			// gone when they're not seen on the wire anymore
+			if time.Since(e.LastSeen) > vlanHostTimeout {
+				rem[mac] = e.IpAddress
+			}
+		} else if _, found := cache[mac]; !found && untagged {
 			rem[mac] = e.IpAddress
 		}
 	})
@@ -92,13 +221,35 @@ func (d *Discovery) runDiff(cache network.ArpTable) {
 		d.Session.Lan.Remove(ip, mac)
 	}
 
+	if !untagged {
+		return
+	}
+
 	// now check for new friends ^_^
 	for ip, mac := range cache {
 		d.Session.Lan.AddIfNew(ip, mac)
 	}
 }
 
-func (d *Discovery) Configure() error {
+func (d *Discovery) Configure() (err error) {
+	if err, d.ssdp = d.BoolParam("net.recon.ssdp"); err != nil {
+		return
+	} else if err, d.ssdpFetch = d.BoolParam("net.recon.ssdp.fetch"); err != nil {
+		return
+	} else if err, d.arpRefresh = d.IntParam("net.recon.arp.refresh"); err != nil {
+		return
+	} else if err, d.passive = d.BoolParam("net.recon.passive"); err != nil {
+		return
+	} else if err = d.configureBlocklist(); err != nil {
+		return
+	}
+
+	var vlan string
+	if err, vlan = d.StringParam("net.recon.vlan"); err != nil {
+		return
+	} else if err, d.vlan = packets.ParseVLANFilter(vlan); err != nil {
+		return
+	}
 	return nil
 }
 
@@ -110,17 +261,55 @@ func (d *Discovery) Start() error {
 	return d.SetRunning(true, func() {
 		every := time.Duration(1) * time.Second
 		iface := d.Session.Interface.Name()
+
+		d.arpProbed = make(map[string]time.Time)
+		d.Session.Lan.SetResolve(!d.passive)
+		if d.passive {
+			d.Warning("passive mode, hosts are only discovered from the traffic seen on the wire and some of them might be missing or unresolved.")
+			if d.arpRefresh > 0 || d.ssdp {
+				d.Warning("net.recon.arp.refresh and net.recon.ssdp are disabled in passive mode.")
+			}
+		}
+		d.Session.Queue.SetVLANFilter(d.vlan)
+		_, classify := d.BoolParam("net.recon.classify")
+		d.Session.Queue.SetClassify(classify)
+		d.Session.Queue.SetARPReplies(d.arpRefresh > 0 && !d.passive)
+		if d.vlan != nil {
+			d.Info("discovering hosts on VLAN %s.", d.vlan)
+		}
+
+		if d.blocklist != nil {
+			if err := d.startBlocklist(); err != nil {
+				d.Error("could not start blocklist monitoring: %s", err)
+			}
+		}
+
 		for d.Running() {
 			if table, err := network.ArpUpdate(iface); err != nil {
-				log.Error("%s", err)
+				d.Error("%s", err)
 			} else {
 				d.runDiff(table)
 			}
+			if d.arpRefresh > 0 && !d.passive {
+				d.refreshArp()
+			}
+			if d.ssdp && !d.passive {
+				d.ssdpTick()
+			}
+			if d.blocklist != nil {
+				d.blocklist.reloadIfChanged()
+			}
 			time.Sleep(every)
 		}
 	})
 }
 
 func (d *Discovery) Stop() error {
-	return d.SetRunning(false, nil)
+	return d.SetRunning(false, func() {
+		d.Session.Lan.SetResolve(true)
+		d.Session.Queue.SetVLANFilter(nil)
+		d.Session.Queue.SetClassify(false)
+		d.Session.Queue.SetARPReplies(false)
+		d.stopBlocklist()
+	})
 }
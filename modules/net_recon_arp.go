@@ -0,0 +1,102 @@
+package modules
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/bettercap/bettercap/core"
+	"github.com/bettercap/bettercap/network"
+)
+
+// neighborStatus correlates an entry of the OS neighbor table with what
+// bettercap knows about its address.
+func (d *Discovery) neighborStatus(n network.Neighbor) (*network.Endpoint, string) {
+	if n.State == network.NeighborIncomplete {
+		return nil, core.Dim("-")
+	}
+
+	var e *network.Endpoint
+	if n.IpAddress == d.Session.Gateway.IpAddress {
+		e = d.Session.Gateway
+	} else {
+		e = d.Session.Lan.GetByIp(n.IpAddress)
+	}
+
+	if e == nil {
+		return nil, core.Yellow("unknown")
+	} else if e.HwAddress != n.HwAddress {
+		// either the host changed its card or somebody is spoofing it
+		return e, core.Red(fmt.Sprintf("mac mismatch (%s)", e.HwAddress))
+	} else if e.Unconfirmed {
+		return e, core.Dim("unconfirmed")
+	}
+	return e, core.Green("known")
+}
+
+func (d *Discovery) ArpDump() error {
+	neighbors, err := network.Neighbors(d.Session.Interface.Name())
+	if err != nil {
+		return err
+	} else if len(neighbors) == 0 {
+		fmt.Printf("\nThe neighbor table of %s is empty.\n\n", d.Session.Interface.Name())
+		return nil
+	}
+
+	rows := make([][]string, 0, len(neighbors))
+	for _, n := range neighbors {
+		e, status := d.neighborStatus(n)
+
+		name, vendor := "", network.ManufLookup(n.HwAddress)
+		if e != nil {
+			if e == d.Session.Gateway {
+				name = "gateway"
+			} else if e.Alias != "" {
+				name = core.Green(e.Alias)
+			} else if e.Hostname != "" {
+				name = core.Yellow(e.Hostname)
+			}
+		}
+
+		rows = append(rows, []string{
+			n.IpAddress,
+			n.HwAddress,
+			n.State,
+			name,
+			core.Dim(vendor),
+			status,
+		})
+	}
+
+	fmt.Println()
+	core.AsTable(os.Stdout, []string{"IP", "MAC", "State", "Name", "Vendor", "Bettercap"}, rows)
+	fmt.Println()
+
+	d.Session.Refresh()
+
+	return nil
+}
+
+// ArpImport adds the complete entries of the OS neighbor table to the hosts
+// list, they're marked as unconfirmed until they're seen on the wire.
+func (d *Discovery) ArpImport() error {
+	neighbors, err := network.Neighbors(d.Session.Interface.Name())
+	if err != nil {
+		return err
+	}
+
+	imported := 0
+	for _, n := range neighbors {
+		if n.State == network.NeighborIncomplete {
+			continue
+		} else if d.Session.Lan.AddIfNew(n.IpAddress, n.HwAddress) != nil {
+			// already known
+			continue
+		} else if e, found := d.Session.Lan.Get(n.HwAddress); found {
+			e.Unconfirmed = true
+			imported++
+		}
+	}
+
+	d.Info("imported %d hosts from the neighbor table of %s.", imported, d.Session.Interface.Name())
+	return nil
+}
@@ -0,0 +1,266 @@
+package modules
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bettercap/bettercap/core"
+	"github.com/bettercap/bettercap/log"
+	"github.com/bettercap/bettercap/network"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcap"
+)
+
+type BlocklistHit struct {
+	Address     string            `json:"address"`
+	Endpoint    *network.Endpoint `json:"endpoint"`
+	Destination string            `json:"destination"`
+	Indicator   string            `json:"indicator"`
+}
+
+// blocklist is a set of IP addresses, CIDRs and domains loaded from a file,
+// domains also match all of their subdomains.
+type blocklist struct {
+	sync.Mutex
+	fileName string
+	modTime  time.Time
+	nets     []*net.IPNet
+	domains  map[string]bool
+}
+
+func newBlocklist(fileName string) (error, *blocklist) {
+	b := &blocklist{fileName: fileName}
+	if err := b.load(); err != nil {
+		return err, nil
+	}
+	return nil, b
+}
+
+func (b *blocklist) load() error {
+	info, err := os.Stat(b.fileName)
+	if err != nil {
+		return err
+	}
+
+	fp, err := os.Open(b.fileName)
+	if err != nil {
+		return err
+	}
+	defer fp.Close()
+
+	nets := make([]*net.IPNet, 0)
+	domains := make(map[string]bool)
+
+	scanner := bufio.NewScanner(fp)
+	for scanner.Scan() {
+		line := core.Trim(scanner.Text())
+		if idx := strings.IndexByte(line, '#'); idx != -1 {
+			line = core.Trim(line[:idx])
+		}
+		if line == "" {
+			continue
+		}
+
+		if strings.Contains(line, "/") {
+			if _, ipnet, err := net.ParseCIDR(line); err != nil {
+				log.Warning("skipping invalid blocklist entry '%s': %s", line, err)
+			} else {
+				nets = append(nets, ipnet)
+			}
+		} else if ip := net.ParseIP(line); ip != nil {
+			bits := net.IPv6len * 8
+			if ip4 := ip.To4(); ip4 != nil {
+				ip = ip4
+				bits = net.IPv4len * 8
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+		} else {
+			line = strings.ToLower(strings.TrimPrefix(strings.TrimSuffix(line, "."), "*."))
+			domains[line] = true
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	b.Lock()
+	defer b.Unlock()
+
+	b.modTime = info.ModTime()
+	b.nets = nets
+	b.domains = domains
+
+	log.Info("loaded %d addresses and %d domains from blocklist %s", len(nets), len(domains), b.fileName)
+
+	return nil
+}
+
+// reloadIfChanged reloads the file if it's been modified since the last load.
+func (b *blocklist) reloadIfChanged() {
+	info, err := os.Stat(b.fileName)
+	if err != nil {
+		return
+	}
+
+	b.Lock()
+	changed := !info.ModTime().Equal(b.modTime)
+	b.Unlock()
+
+	if changed {
+		if err := b.load(); err != nil {
+			log.Error("error reloading blocklist %s: %s", b.fileName, err)
+		}
+	}
+}
+
+func (b *blocklist) MatchIP(ip net.IP) (string, bool) {
+	b.Lock()
+	defer b.Unlock()
+
+	for _, ipnet := range b.nets {
+		if ipnet.Contains(ip) {
+			return ipnet.String(), true
+		}
+	}
+	return "", false
+}
+
+func (b *blocklist) MatchDomain(name string) (string, bool) {
+	b.Lock()
+	defer b.Unlock()
+
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	for name != "" {
+		if b.domains[name] {
+			return name, true
+		}
+
+		idx := strings.IndexByte(name, '.')
+		if idx == -1 {
+			break
+		}
+		name = name[idx+1:]
+	}
+	return "", false
+}
+
+func (d *Discovery) onBlocklistHit(address string, destination string, indicator string) {
+	key := address + "|" + indicator
+
+	d.blocklistLock.Lock()
+	if d.blocklistHits[key] {
+		d.blocklistLock.Unlock()
+		return
+	}
+	d.blocklistHits[key] = true
+	d.blocklistLock.Unlock()
+
+	e := d.Session.Lan.GetByIp(address)
+	if e != nil {
+		e.Suspicious = true
+		hits := indicator
+		if prev, ok := e.Meta.Get("blocklist:hits").(string); ok && prev != "" {
+			hits = prev + ", " + indicator
+		}
+		e.Meta.Set("blocklist:hits", hits)
+	}
+
+	d.Session.Events.Add("net.recon.blocklist.hit", BlocklistHit{
+		Address:     address,
+		Endpoint:    e,
+		Destination: destination,
+		Indicator:   indicator,
+	})
+}
+
+func (d *Discovery) onBlocklistPacket(pkt gopacket.Packet) {
+	ip4, ok := pkt.Layer(layers.LayerTypeIPv4).(*layers.IPv4)
+	if !ok {
+		return
+	}
+
+	iface := d.Session.Interface
+	// only look at what hosts on the lan are talking to
+	if ip4.SrcIP.Equal(iface.IP) || !iface.Net.Contains(ip4.SrcIP) {
+		return
+	}
+
+	src := ip4.SrcIP.String()
+
+	if !iface.Net.Contains(ip4.DstIP) {
+		if indicator, found := d.blocklist.MatchIP(ip4.DstIP); found {
+			d.onBlocklistHit(src, ip4.DstIP.String(), indicator)
+		}
+	}
+
+	if _, _, dns, ok := parseDNSQuery(pkt); ok {
+		for _, q := range dns.Questions {
+			name := string(q.Name)
+			if indicator, found := d.blocklist.MatchDomain(name); found {
+				d.onBlocklistHit(src, name, indicator)
+			}
+		}
+	}
+}
+
+func (d *Discovery) startBlocklist() error {
+	var err error
+
+	if d.blocklistHandle, err = pcap.OpenLive(d.Session.Interface.Name(), 65536, true, pcap.BlockForever); err != nil {
+		return err
+	} else if err = d.blocklistHandle.SetBPFFilter("ip"); err != nil {
+		d.blocklistHandle.Close()
+		return err
+	}
+
+	src := gopacket.NewPacketSource(d.blocklistHandle, d.blocklistHandle.LinkType())
+	d.blocklistChan = src.Packets()
+
+	go func() {
+		for packet := range d.blocklistChan {
+			if !d.Running() {
+				break
+			} else if packet != nil {
+				d.onBlocklistPacket(packet)
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (d *Discovery) stopBlocklist() {
+	if d.blocklistHandle != nil {
+		d.blocklistChan <- nil
+		d.blocklistHandle.Close()
+		d.blocklistHandle = nil
+	}
+}
+
+func (d *Discovery) configureBlocklist() error {
+	err, fileName := d.StringParam("net.recon.blocklist")
+	if err != nil {
+		return err
+	} else if fileName, err = core.ExpandPath(fileName); err != nil {
+		return err
+	}
+
+	d.blocklist = nil
+	d.blocklistHits = make(map[string]bool)
+
+	if fileName != "" {
+		if err, d.blocklist = newBlocklist(fileName); err != nil {
+			return fmt.Errorf("error loading blocklist %s: %s", fileName, err)
+		}
+	}
+
+	return nil
+}
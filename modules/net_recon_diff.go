@@ -0,0 +1,101 @@
+package modules
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/bettercap/bettercap/core"
+	"github.com/bettercap/bettercap/network"
+)
+
+// what we remember of each endpoint between two net.show calls
+type endpointSnapshot struct {
+	IpAddress string
+	HwAddress string
+	Hostname  string
+	Vendor    string
+}
+
+func newEndpointSnapshot(e *network.Endpoint) endpointSnapshot {
+	name := e.Hostname
+	if e.Alias != "" {
+		name = e.Alias
+	}
+
+	return endpointSnapshot{
+		IpAddress: e.IpAddress,
+		HwAddress: e.HwAddress,
+		Hostname:  name,
+		Vendor:    e.Vendor,
+	}
+}
+
+func (d *Discovery) takeSnapshot() map[string]endpointSnapshot {
+	snapshot := make(map[string]endpointSnapshot)
+	for _, e := range d.Session.Lan.List() {
+		snapshot[e.HwAddress] = newEndpointSnapshot(e)
+	}
+	return snapshot
+}
+
+func snapshotChanges(prev, curr endpointSnapshot) []string {
+	changes := make([]string, 0)
+	if prev.IpAddress != curr.IpAddress {
+		changes = append(changes, fmt.Sprintf("ip %s → %s", core.Dim(prev.IpAddress), curr.IpAddress))
+	}
+	if prev.Hostname != curr.Hostname {
+		changes = append(changes, fmt.Sprintf("name %s → %s", core.Dim(prev.Hostname), curr.Hostname))
+	}
+	if prev.Vendor != curr.Vendor {
+		changes = append(changes, fmt.Sprintf("vendor %s → %s", core.Dim(prev.Vendor), curr.Vendor))
+	}
+	return changes
+}
+
+func (d *Discovery) ShowDiff() error {
+	curr := d.takeSnapshot()
+	prev := d.lastShown
+	d.lastShown = curr
+
+	if prev == nil {
+		prev = make(map[string]endpointSnapshot)
+	}
+
+	macs := make([]string, 0)
+	for mac := range curr {
+		macs = append(macs, mac)
+	}
+	for mac := range prev {
+		if _, found := curr[mac]; !found {
+			macs = append(macs, mac)
+		}
+	}
+	sort.Strings(macs)
+
+	rows := make([][]string, 0)
+	for _, mac := range macs {
+		was, existed := prev[mac]
+		now, exists := curr[mac]
+
+		if !existed {
+			rows = append(rows, []string{core.Green("+"), now.IpAddress, now.HwAddress, core.Yellow(now.Hostname), core.Dim(now.Vendor), core.Green("new")})
+		} else if !exists {
+			rows = append(rows, []string{core.Red("-"), core.Dim(was.IpAddress), core.Dim(was.HwAddress), core.Dim(was.Hostname), core.Dim(was.Vendor), core.Red("removed")})
+		} else if changes := snapshotChanges(was, now); len(changes) > 0 {
+			rows = append(rows, []string{core.Yellow("~"), now.IpAddress, now.HwAddress, core.Yellow(now.Hostname), core.Dim(now.Vendor), strings.Join(changes, ", ")})
+		}
+	}
+
+	if len(rows) == 0 {
+		fmt.Printf("\nNo changes since the last net.show.\n\n")
+	} else {
+		core.AsTable(os.Stdout, []string{"", "IP", "MAC", "Name", "Vendor", "Changes"}, rows)
+		fmt.Println()
+	}
+
+	d.Session.Refresh()
+
+	return nil
+}
@@ -0,0 +1,231 @@
+package modules
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bettercap/bettercap/core"
+	"github.com/bettercap/bettercap/network"
+	"github.com/bettercap/bettercap/packets"
+
+	"github.com/dustin/go-humanize"
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+const defaultInspectPorts = "21,22,23,25,53,80,110,135,139,143,443,445,554,1883,3306,3389,5000,5900,8000,8080,8443,9100"
+
+// InspectReport is the payload of net.inspect events.
+type InspectReport struct {
+	Endpoint  *network.Endpoint `json:"endpoint"`
+	OpenPorts []int             `json:"open_ports"`
+	MDNSName  string            `json:"mdns_name"`
+	UPNPName  string            `json:"upnp_name"`
+	Sent      uint64            `json:"sent"`
+	Received  uint64            `json:"received"`
+}
+
+func (d *Discovery) findInspectTarget(what string) *network.Endpoint {
+	if ip := net.ParseIP(what); ip != nil {
+		for _, e := range []*network.Endpoint{d.Session.Interface, d.Session.Gateway} {
+			if e.IpAddress == what {
+				return e
+			}
+		}
+		return d.Session.Lan.GetByIp(what)
+	}
+
+	mac := network.NormalizeMac(what)
+	for _, e := range []*network.Endpoint{d.Session.Interface, d.Session.Gateway} {
+		if e.HwAddress == mac {
+			return e
+		}
+	}
+	if e, found := d.Session.Lan.Get(mac); found {
+		return e
+	}
+	return nil
+}
+
+// quickScan sends a SYN packet to each port and collects the SYN+ACK
+// replies until the timeout expires.
+func (d *Discovery) quickScan(e *network.Endpoint, ports []int, timeout time.Duration) []int {
+	lock := sync.Mutex{}
+	open := make(map[int]bool)
+
+	if e == d.Session.Interface {
+		d.Warning("skipping port scan of the local interface.")
+		return []int{}
+	}
+
+	sub := d.Session.Queue.Subscribe(func(pkt gopacket.Packet) {
+		// syn.scan shares the source port, its replies are not ours
+		if d.Session.IsOn("syn.scan") {
+			return
+		}
+
+		ip, ok := pkt.Layer(layers.LayerTypeIPv4).(*layers.IPv4)
+		if !ok || !ip.SrcIP.Equal(e.IP) {
+			return
+		}
+
+		tcp, ok := pkt.Layer(layers.LayerTypeTCP).(*layers.TCP)
+		if ok && tcp.DstPort == synSourcePort && tcp.SYN && tcp.ACK {
+			lock.Lock()
+			open[int(tcp.SrcPort)] = true
+			lock.Unlock()
+		}
+	})
+	defer d.Session.Queue.Unsubscribe(sub)
+
+	deadline := time.Now().Add(timeout)
+	for _, port := range ports {
+		if time.Now().After(deadline) {
+			d.Warning("net.inspect timeout reached while scanning %s.", e.IpAddress)
+			break
+		} else if d.Session.IsOn("syn.scan") {
+			d.Warning("syn.scan started, stopping the port scan of %s.", e.IpAddress)
+			break
+		}
+
+		err, raw := packets.NewTCPSyn(d.Session.Interface.IP, d.Session.Interface.HW, e.IP, e.HW, synSourcePort, port)
+		if err != nil {
+			d.Error("Error creating SYN packet: %s", err)
+		} else if err := d.Send(raw); err != nil {
+			d.Error("Error sending SYN packet: %s", err)
+		}
+	}
+
+	// wait for the replies, but never more than the timeout
+	wait := time.Until(deadline)
+	if wait > 2*time.Second {
+		wait = 2 * time.Second
+	}
+	if wait > 0 {
+		time.Sleep(wait)
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	list := make([]int, 0, len(open))
+	for port := range open {
+		list = append(list, port)
+	}
+	sort.Ints(list)
+
+	for _, port := range list {
+		ports := e.Meta.GetIntsWith("tcp-ports", port, true)
+		e.Meta.SetInts("tcp-ports", ports)
+	}
+
+	return list
+}
+
+func (d *Discovery) Inspect(what string) error {
+	var portList string
+	var timeout int
+	var err error
+
+	if err, portList = d.StringParam("net.inspect.ports"); err != nil {
+		return err
+	} else if err, timeout = d.IntParam("net.inspect.timeout"); err != nil {
+		return err
+	}
+
+	e := d.findInspectTarget(what)
+	if e == nil {
+		return fmt.Errorf("Could not find endpoint %s", what)
+	} else if d.Session.IsOn("syn.scan") {
+		return fmt.Errorf("syn.scan is running, wait for it to finish before inspecting %s", what)
+	}
+
+	ports := make([]int, 0)
+	for _, p := range core.CommaSplit(portList) {
+		if port, err := strconv.Atoi(p); err != nil || port < 1 || port > 65535 {
+			return fmt.Errorf("Invalid port '%s' in net.inspect.ports", p)
+		} else {
+			ports = append(ports, port)
+		}
+	}
+
+	report := InspectReport{
+		Endpoint: e,
+	}
+
+	if d.Session.ReconPassive() {
+		d.Warning("net.recon.passive is enabled, only showing the open ports already known.")
+		report.OpenPorts = e.Meta.GetInts("tcp-ports")
+	} else {
+		d.Info("inspecting %s (%d ports, at most %ds) ...", core.Bold(e.IpAddress), len(ports), timeout)
+		report.OpenPorts = d.quickScan(e, ports, time.Duration(timeout)*time.Second)
+	}
+
+	if name, ok := e.Meta.Get("mdns:hostname").(string); ok {
+		report.MDNSName = name
+	}
+	if name, ok := e.Meta.Get("upnp:friendlyName").(string); ok && name != "" {
+		report.UPNPName = name
+	} else if server, ok := e.Meta.Get("upnp:Server").(string); ok {
+		report.UPNPName = server
+	}
+
+	d.Session.Queue.RLock()
+	if traffic, found := d.Session.Queue.Traffic[e.IpAddress]; found {
+		report.Sent = traffic.Sent
+		report.Received = traffic.Received
+	}
+	d.Session.Queue.RUnlock()
+
+	d.showInspectReport(report)
+	d.Session.Events.Add("net.inspect", report)
+
+	return nil
+}
+
+func (d *Discovery) showInspectReport(r InspectReport) {
+	e := r.Endpoint
+	orDash := func(s string) string {
+		if s == "" {
+			return core.Dim("-")
+		}
+		return s
+	}
+
+	ports := make([]string, 0, len(r.OpenPorts))
+	for _, port := range r.OpenPorts {
+		ports = append(ports, strconv.Itoa(port))
+	}
+
+	guess := ""
+	if e.OSGuess != nil {
+		guess = e.OSGuess.String()
+	}
+
+	rows := [][]string{
+		{"IP", e.IpAddress},
+		{"MAC", e.HwAddress},
+		{"Vendor", orDash(e.Vendor)},
+		{"Hostname", orDash(e.Hostname)},
+		{"Alias", orDash(e.Alias)},
+		{"mDNS", orDash(r.MDNSName)},
+		{"UPnP", orDash(r.UPNPName)},
+		{"OS", orDash(guess)},
+		{"Open Ports", orDash(strings.Join(ports, ", "))},
+		{"Traffic", fmt.Sprintf("%s %s / %s %s", core.Red("↑"), humanize.Bytes(r.Sent), core.Green("↓"), humanize.Bytes(r.Received))},
+		{"First Seen", e.FirstSeen.Format("15:04:05")},
+		{"Last Seen", e.LastSeen.Format("15:04:05")},
+	}
+
+	fmt.Println()
+	core.AsTable(os.Stdout, []string{"Property", "Value"}, rows)
+	fmt.Println()
+
+	d.Session.Refresh()
+}
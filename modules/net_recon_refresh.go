@@ -0,0 +1,93 @@
+package modules
+
+import (
+	"net"
+	"time"
+
+	"github.com/bettercap/bettercap/network"
+	"github.com/bettercap/bettercap/packets"
+)
+
+// maximum number of ARP requests net.recon.arp.refresh sends every second
+const arpRefreshBatch = 16
+
+// arpRefreshCandidates returns the addresses worth probing: the hosts which
+// haven't been seen on the wire yet and the ones the operating system
+// couldn't resolve, the latter with a nil MAC address.
+func (d *Discovery) arpRefreshCandidates() map[string]net.HardwareAddr {
+	candidates := make(map[string]net.HardwareAddr)
+
+	d.Session.Lan.EachHost(func(mac string, e *network.Endpoint) {
+		if e.Unconfirmed && e.VLAN() == 0 {
+			candidates[e.IpAddress] = e.HW
+		}
+	})
+
+	if neighbors, err := network.Neighbors(d.Session.Interface.Name()); err != nil {
+		d.Debug("could not read the neighbor table: %s", err)
+	} else {
+		for _, n := range neighbors {
+			if _, found := candidates[n.IpAddress]; !found && n.State == network.NeighborIncomplete {
+				if ip := net.ParseIP(n.IpAddress); ip != nil && d.Session.Interface.Net.Contains(ip) {
+					candidates[n.IpAddress] = nil
+				}
+			}
+		}
+	}
+
+	delete(candidates, d.Session.Interface.IpAddress)
+	delete(candidates, d.Session.Gateway.IpAddress)
+
+	return candidates
+}
+
+// refreshArp sends an ARP request to each candidate which hasn't been probed
+// in the last net.recon.arp.refresh seconds, the replies go through the
+// packet queue and complete the hosts list like any other activity.
+func (d *Discovery) refreshArp() {
+	if d.Session.Paused() {
+		return
+	}
+
+	period := time.Duration(d.arpRefresh) * time.Second
+	candidates := d.arpRefreshCandidates()
+
+	// forget the hosts which don't need to be probed anymore
+	for ip := range d.arpProbed {
+		if _, found := candidates[ip]; !found {
+			delete(d.arpProbed, ip)
+		}
+	}
+
+	sent := 0
+	for ip, hw := range candidates {
+		if sent >= arpRefreshBatch {
+			break
+		} else if last, found := d.arpProbed[ip]; found && time.Since(last) < period {
+			continue
+		}
+
+		var err error
+		var raw []byte
+
+		to := net.ParseIP(ip)
+		if hw != nil {
+			err, raw = packets.NewARPUnicastRequest(d.Session.Interface.IP, d.Session.Interface.HW, to, hw)
+		} else {
+			// the address has never been resolved, so the request can
+			// only be broadcast
+			err, raw = packets.NewARPRequest(d.Session.Interface.IP, d.Session.Interface.HW, to)
+		}
+
+		if err != nil {
+			d.Error("error creating ARP request for %s: %s", ip, err)
+		} else if err = d.Send(raw); err != nil {
+			d.Error("error sending ARP request to %s: %s", ip, err)
+		} else {
+			d.Debug("sent ARP request to %s", ip)
+		}
+
+		d.arpProbed[ip] = time.Now()
+		sent++
+	}
+}
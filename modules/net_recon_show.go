@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/bettercap/bettercap/core"
@@ -46,6 +47,11 @@ func (d *Discovery) getRow(e *network.Endpoint, withMeta bool) [][]string {
 		mac = core.Bold(mac)
 	}
 
+	if e.Suspicious {
+		// talked to something on the blocklist
+		addr = core.Red(addr)
+	}
+
 	name := ""
 	if e == d.Session.Interface {
 		name = e.Name()
@@ -57,6 +63,11 @@ func (d *Discovery) getRow(e *network.Endpoint, withMeta bool) [][]string {
 		name = core.Yellow(e.Hostname)
 	}
 
+	if e.Unconfirmed {
+		// imported from the neighbor table and not seen on the wire yet
+		name = strings.TrimSpace(name + " " + core.Dim("(unconfirmed)"))
+	}
+
 	var traffic *packets.Traffic
 	var found bool
 	if traffic, found = d.Session.Queue.Traffic[e.IpAddress]; !found {
@@ -172,6 +183,11 @@ func (d *Discovery) Show(by string, addr string) error {
 
 	core.AsTable(os.Stdout, colNames, rows)
 
+	// the full table is the new baseline for net.show.diff
+	if addr == "" {
+		d.lastShown = d.takeSnapshot()
+	}
+
 	d.Session.Queue.Stats.RLock()
 	fmt.Printf("\n%s %s / %s %s / %d pkts / %d errs\n\n",
 		core.Red("↑"),
@@ -186,3 +202,43 @@ func (d *Discovery) Show(by string, addr string) error {
 
 	return nil
 }
+
+func (d *Discovery) ShowMulticast() error {
+	members := make(map[string][]string)
+	for _, t := range d.Session.Lan.List() {
+		for _, group := range t.Groups.List() {
+			members[group] = append(members[group], t.IpAddress)
+		}
+	}
+
+	if len(members) == 0 {
+		fmt.Printf("\nNo multicast group memberships detected yet.\n\n")
+		return nil
+	}
+
+	groups := make([]string, 0, len(members))
+	for group := range members {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+
+	rows := make([][]string, 0)
+	for _, group := range groups {
+		hosts := members[group]
+		sort.Strings(hosts)
+
+		name := group
+		if desc := network.GroupName(group); desc != "" {
+			name = fmt.Sprintf("%s %s", group, core.Dim(desc))
+		}
+
+		rows = append(rows, []string{name, fmt.Sprintf("%d", len(hosts)), strings.Join(hosts, ", ")})
+	}
+
+	core.AsTable(os.Stdout, []string{"Group", "Members", "Hosts"}, rows)
+	fmt.Println()
+
+	d.Session.Refresh()
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package modules
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/bettercap/bettercap/core"
+	"github.com/bettercap/bettercap/network"
+	"github.com/bettercap/bettercap/packets"
+)
+
+const (
+	ssdpSearchPeriod   = 30 * time.Second
+	ssdpFetchTimeout   = 5 * time.Second
+	ssdpMaxDescription = 1024 * 1024
+)
+
+func (d *Discovery) ssdpTick() {
+	if time.Since(d.lastSearch) >= ssdpSearchPeriod {
+		d.lastSearch = time.Now()
+		sendUPNPDiscovery(d.Session)
+	}
+
+	d.Session.Lan.EachHost(func(mac string, e *network.Endpoint) {
+		location, ok := e.Meta.Get("upnp:Location").(string)
+		if !ok || location == "" {
+			return
+		}
+
+		d.ssdpLock.Lock()
+		defer d.ssdpLock.Unlock()
+
+		// only one attempt per host
+		if d.ssdpSeen[mac] {
+			return
+		}
+		d.ssdpSeen[mac] = true
+
+		if !d.ssdpFetch {
+			d.Session.Events.Add("net.recon.upnp", e)
+			return
+		}
+
+		core.Workers.Submit(func() {
+			d.fetchUPNPDescription(e, location)
+			d.Session.Events.Add("net.recon.upnp", e)
+		})
+	})
+}
+
+func (d *Discovery) fetchUPNPDescription(e *network.Endpoint, location string) {
+	// never let a device make us connect somewhere else
+	if u, err := url.Parse(location); err != nil {
+		d.Debug("invalid UPnP location %s: %s", location, err)
+		return
+	} else if u.Hostname() != e.IpAddress {
+		d.Debug("skipping UPnP location %s for %s", location, e.IpAddress)
+		return
+	}
+
+	client := http.Client{Timeout: ssdpFetchTimeout}
+	res, err := client.Get(location)
+	if err != nil {
+		d.Debug("could not fetch UPnP description from %s: %s", location, err)
+		return
+	}
+	defer res.Body.Close()
+
+	raw, err := ioutil.ReadAll(io.LimitReader(res.Body, ssdpMaxDescription))
+	if err != nil {
+		d.Debug("could not read UPnP description from %s: %s", location, err)
+		return
+	}
+
+	err, device := packets.UPNPParseDescription(raw)
+	if err != nil {
+		d.Debug("could not parse UPnP description from %s: %s", location, err)
+		return
+	}
+
+	meta := map[string]string{
+		"upnp:friendlyName": device.FriendlyName,
+		"upnp:manufacturer": device.Manufacturer,
+		"upnp:modelName":    device.ModelName,
+		"upnp:modelNumber":  device.ModelNumber,
+	}
+	for k, v := range meta {
+		if v = core.Trim(v); v != "" {
+			e.Meta.Set(k, v)
+		}
+	}
+
+	if e.Hostname == "" {
+		e.Hostname = core.Trim(device.FriendlyName)
+	}
+}
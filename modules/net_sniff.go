@@ -2,25 +2,32 @@ package modules
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
+	"github.com/bettercap/bettercap/log"
+	"github.com/bettercap/bettercap/packets"
 	"github.com/bettercap/bettercap/session"
 
 	"github.com/google/gopacket"
 	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcap"
 )
 
 type Sniffer struct {
 	session.SessionModule
-	Stats         *SnifferStats
-	Ctx           *SnifferContext
-	pktSourceChan chan gopacket.Packet
+	Stats          *SnifferStats
+	Top            *SnifferTop
+	Ctx            *SnifferContext
+	lock           *sync.Mutex
+	pktSourceChans map[string]chan gopacket.Packet
 }
 
 func NewSniffer(s *session.Session) *Sniffer {
 	sniff := &Sniffer{
 		SessionModule: session.NewSessionModule("net.sniff", s),
 		Stats:         nil,
+		lock:          &sync.Mutex{},
 	}
 
 	sniff.AddParam(session.NewBoolParameter("net.sniff.verbose",
@@ -41,15 +48,41 @@ func NewSniffer(s *session.Session) *Sniffer {
 		"",
 		"If set, only packets matching this regular expression will be considered."))
 
+	sniff.AddParam(session.NewStringParameter("net.sniff.vlan",
+		"",
+		"",
+		"If set, comma separated list of 802.1Q VLAN IDs to sniff, 0 for untagged frames and OUTER.INNER for QinQ ones (a single ID matches the inner tag), the parsers see the frames without their tags."))
+
 	sniff.AddParam(session.NewStringParameter("net.sniff.output",
 		"",
 		"",
 		"If set, the sniffer will write captured packets to this file."))
 
+	sniff.AddParam(session.NewStringParameter("net.sniff.interface",
+		"",
+		"",
+		"Comma separated list of interfaces to sniff on at the same time, empty for the session interface."))
+
 	sniff.AddParam(session.NewStringParameter("net.sniff.source",
 		"",
 		"",
-		"If set, the sniffer will read from this pcap file instead of the current interface."))
+		"If set, the sniffer will read from this pcap file instead of the current interface, running every parser and filter on it as if live, and stop at the end of the file."))
+
+	sniff.AddParam(session.NewBoolParameter("net.sniff.reassemble",
+		"false",
+		"If true, TCP segments will be reassembled so that TLS client hellos and HTTP messages spanning several packets can be parsed."))
+
+	sniff.AddParam(session.NewIntParameter("net.sniff.reassemble.max",
+		"65536",
+		"Maximum number of bytes buffered for each TCP flow when net.sniff.reassemble is true."))
+
+	sniff.AddParam(session.NewIntParameter("net.sniff.reassemble.flows",
+		"1024",
+		"Maximum number of TCP flows being reassembled at the same time, the oldest one will be parsed as it is when the limit is reached."))
+
+	sniff.AddParam(session.NewIntParameter("net.sniff.reassemble.timeout",
+		"30",
+		"Number of seconds after which an idle TCP flow being reassembled will be parsed as it is."))
 
 	sniff.AddHandler(session.NewModuleHandler("net.sniff stats", "",
 		"Print sniffer session configuration and statistics.",
@@ -63,6 +96,37 @@ func NewSniffer(s *session.Session) *Sniffer {
 			return sniff.Stats.Print()
 		}))
 
+	sniff.AddParam(session.NewIntParameter("net.sniff.top.limit",
+		"10",
+		"Maximum number of entries shown for each table of net.sniff.top, 0 for no limit."))
+
+	sniff.AddHandler(session.NewModuleHandler("net.sniff.top", "",
+		"Print the top talkers, protocols and destination ports seen so far (sort by packets).",
+		func(args []string) error {
+			return sniff.ShowTop("packets")
+		}))
+
+	sniff.AddHandler(session.NewModuleHandler("net.sniff.top by bytes", "",
+		"Print the top talkers, protocols and destination ports seen so far (sort by bytes).",
+		func(args []string) error {
+			return sniff.ShowTop("bytes")
+		}))
+
+	sniff.AddHandler(session.NewModuleHandler("net.sniff.top reset", "",
+		"Clear the net.sniff.top counters.",
+		func(args []string) error {
+			if sniff.Top != nil {
+				sniff.Top.Reset()
+			}
+			return nil
+		}))
+
+	sniff.AddHandler(session.NewModuleHandler("net.sniff.reconfigure", "",
+		"Apply the net.sniff.filter, net.sniff.output, net.sniff.regexp, net.sniff.vlan, net.sniff.verbose and net.sniff.local parameters to the running sniffer without restarting it.",
+		func(args []string) error {
+			return sniff.Reconfigure()
+		}))
+
 	sniff.AddHandler(session.NewModuleHandler("net.sniff on", "",
 		"Start network sniffer in background.",
 		func(args []string) error {
@@ -102,7 +166,7 @@ func (s Sniffer) isLocalPacket(packet gopacket.Packet) bool {
 }
 
 func (s *Sniffer) onPacketMatched(pkt gopacket.Packet) {
-	if mainParser(pkt, s.Ctx.Verbose) {
+	if mainParser(pkt, s.Ctx.Verbose, s.Ctx.Streams) {
 		s.Stats.NumDumped++
 	}
 }
@@ -123,56 +187,116 @@ func (s *Sniffer) Configure() error {
 	return nil
 }
 
-func (s *Sniffer) Start() error {
-	if err := s.Configure(); err != nil {
-		return err
+func (s *Sniffer) onPacket(packet gopacket.Packet) {
+	// packets from every interface share the same stats and output
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := time.Now()
+	if s.Ctx.Source != "" {
+		// when reading a capture file what matters is when it was captured
+		now = packet.Metadata().Timestamp
+	}
+	if s.Stats.FirstPacket.IsZero() {
+		s.Stats.FirstPacket = now
 	}
+	s.Stats.LastPacket = now
 
-	return s.SetRunning(true, func() {
-		s.Stats = NewSnifferStats()
+	// the capture file gets the frames as they were, tags included
+	tagged := packet
+	packet, tags := packets.VLANStrip(packet)
+	if s.Ctx.VLAN != nil && !s.Ctx.VLAN.Match(tags) {
+		return
+	}
 
-		src := gopacket.NewPacketSource(s.Ctx.Handle, s.Ctx.Handle.LinkType())
-		s.pktSourceChan = src.Packets()
-		for packet := range s.pktSourceChan {
-			if !s.Running() {
-				break
-			}
+	isLocal := s.isLocalPacket(packet)
+	if isLocal {
+		s.Stats.NumLocal++
+	}
 
-			now := time.Now()
-			if s.Stats.FirstPacket.IsZero() {
-				s.Stats.FirstPacket = now
-			}
-			s.Stats.LastPacket = now
+	if s.Ctx.DumpLocal || !isLocal {
+		data := packet.Data()
+		if s.Ctx.Compiled == nil || s.Ctx.Compiled.Match(data) {
+			s.Stats.NumMatched++
+			s.Top.Track(packet)
+
+			s.onPacketMatched(packet)
 
-			isLocal := s.isLocalPacket(packet)
-			if isLocal {
-				s.Stats.NumLocal++
+			if s.Ctx.OutputWriter != nil {
+				s.Ctx.OutputWriter.WritePacket(tagged.Metadata().CaptureInfo, tagged.Data())
+				s.Stats.NumWrote++
 			}
+		}
+	}
+}
 
-			if s.Ctx.DumpLocal || !isLocal {
-				data := packet.Data()
-				if s.Ctx.Compiled == nil || s.Ctx.Compiled.Match(data) {
-					s.Stats.NumMatched++
+func (s *Sniffer) readPackets(name string, handle *pcap.Handle) {
+	src := gopacket.NewPacketSource(handle, handle.LinkType())
+	pktSourceChan := src.Packets()
 
-					s.onPacketMatched(packet)
+	s.lock.Lock()
+	s.pktSourceChans[name] = pktSourceChan
+	s.lock.Unlock()
 
-					if s.Ctx.OutputWriter != nil {
-						s.Ctx.OutputWriter.WritePacket(packet.Metadata().CaptureInfo, data)
-						s.Stats.NumWrote++
-					}
-				}
-			}
+	for packet := range pktSourceChan {
+		if !s.Running() {
+			break
+		} else if packet == nil {
+			continue
+		}
+
+		s.FrameReceived()
+		s.onPacket(packet)
+	}
+
+	s.lock.Lock()
+	delete(s.pktSourceChans, name)
+	s.lock.Unlock()
+
+	if s.Running() && len(s.Ctx.Interfaces) > 1 {
+		log.Warning("stopped sniffing on %s, the other interfaces will keep going.", name)
+	}
+}
+
+func (s *Sniffer) Start() error {
+	if err := s.Configure(); err != nil {
+		return err
+	}
+
+	return s.SetRunning(true, func() {
+		s.Stats = NewSnifferStats()
+		s.Top = NewSnifferTop()
+		s.pktSourceChans = make(map[string]chan gopacket.Packet)
+
+		// every other interface is read in background, the first one in here
+		for i := 1; i < len(s.Ctx.Handles); i++ {
+			go s.readPackets(s.Ctx.Interfaces[i], s.Ctx.Handles[i])
 		}
 
-		s.pktSourceChan = nil
+		s.readPackets(s.Ctx.Interfaces[0], s.Ctx.Handles[0])
+
+		// a capture file has been read till the end, there's nothing
+		// left to sniff
+		if s.Running() && s.Ctx.Source != "" {
+			log.Info("finished reading %s.", s.Ctx.Source)
+			s.Stop()
+			s.Stats.Print()
+		}
 	})
 }
 
 func (s *Sniffer) Stop() error {
 	return s.SetRunning(false, func() {
-		if s.pktSourceChan != nil {
-			s.pktSourceChan <- nil
+		s.lock.Lock()
+		for _, pktSourceChan := range s.pktSourceChans {
+			pktSourceChan <- nil
+		}
+		if s.Ctx.Streams != nil {
+			for _, stream := range s.Ctx.Streams.Flush() {
+				tcpAppParser(stream.ip, stream.pkt, stream.Payload())
+			}
 		}
+		s.lock.Unlock()
 		s.Ctx.Close()
 	})
 }
@@ -1,11 +1,15 @@
 package modules
 
 import (
+	"fmt"
 	"os"
 	"regexp"
+	"strings"
+	"time"
 
 	"github.com/bettercap/bettercap/core"
 	"github.com/bettercap/bettercap/log"
+	"github.com/bettercap/bettercap/packets"
 	"github.com/bettercap/bettercap/session"
 
 	"github.com/google/gopacket/pcap"
@@ -14,15 +18,19 @@ import (
 
 type SnifferContext struct {
 	Handle       *pcap.Handle
+	Handles      []*pcap.Handle
+	Interfaces   []string
 	Source       string
 	DumpLocal    bool
 	Verbose      bool
 	Filter       string
 	Expression   string
 	Compiled     *regexp.Regexp
+	VLAN         *packets.VLANFilter
 	Output       string
 	OutputFile   *os.File
 	OutputWriter *pcapgo.Writer
+	Streams      *tcpStreams
 }
 
 func (s *Sniffer) GetContext() (error, *SnifferContext) {
@@ -35,15 +43,39 @@ func (s *Sniffer) GetContext() (error, *SnifferContext) {
 	}
 
 	if ctx.Source == "" {
-		if ctx.Handle, err = pcap.OpenLive(s.Session.Interface.Name(), 65536, true, pcap.BlockForever); err != nil {
+		var ifaces []string
+		if err, ifaces = s.ListParam("net.sniff.interface"); err != nil {
 			return err, ctx
+		} else if len(ifaces) == 0 {
+			ifaces = []string{s.Session.Interface.Name()}
+		}
+
+		for _, name := range ifaces {
+			if handle, err := pcap.OpenLive(name, 65536, true, pcap.BlockForever); err != nil {
+				if len(ifaces) == 1 {
+					return err, ctx
+				}
+				log.Error("could not sniff on %s: %s", name, err)
+			} else {
+				ctx.Handles = append(ctx.Handles, handle)
+				ctx.Interfaces = append(ctx.Interfaces, name)
+			}
+		}
+
+		if len(ctx.Handles) == 0 {
+			return fmt.Errorf("could not sniff on any of %s", strings.Join(ifaces, ", ")), ctx
 		}
 	} else {
-		if ctx.Handle, err = pcap.OpenOffline(ctx.Source); err != nil {
+		handle, err := pcap.OpenOffline(ctx.Source)
+		if err != nil {
 			return err, ctx
 		}
+		ctx.Handles = append(ctx.Handles, handle)
+		ctx.Interfaces = append(ctx.Interfaces, ctx.Source)
 	}
 
+	ctx.Handle = ctx.Handles[0]
+
 	if err, ctx.Verbose = s.BoolParam("net.sniff.verbose"); err != nil {
 		return err, ctx
 	}
@@ -55,12 +87,19 @@ func (s *Sniffer) GetContext() (error, *SnifferContext) {
 	if err, ctx.Filter = s.StringParam("net.sniff.filter"); err != nil {
 		return err, ctx
 	} else if ctx.Filter != "" {
-		err = ctx.Handle.SetBPFFilter(ctx.Filter)
-		if err != nil {
-			return err, ctx
+		for _, handle := range ctx.Handles {
+			if err = handle.SetBPFFilter(ctx.Filter); err != nil {
+				return err, ctx
+			}
 		}
 	}
 
+	if err, vlan := s.StringParam("net.sniff.vlan"); err != nil {
+		return err, ctx
+	} else if err, ctx.VLAN = packets.ParseVLANFilter(vlan); err != nil {
+		return err, ctx
+	}
+
 	if err, ctx.Expression = s.StringParam("net.sniff.regexp"); err != nil {
 		return err, ctx
 	} else if ctx.Expression != "" {
@@ -69,6 +108,22 @@ func (s *Sniffer) GetContext() (error, *SnifferContext) {
 		}
 	}
 
+	if err, reassemble := s.BoolParam("net.sniff.reassemble"); err != nil {
+		return err, ctx
+	} else if reassemble {
+		var maxSize, maxFlows, timeout int
+		if err, maxSize = s.IntParam("net.sniff.reassemble.max"); err != nil {
+			return err, ctx
+		} else if err, maxFlows = s.IntParam("net.sniff.reassemble.flows"); err != nil {
+			return err, ctx
+		} else if err, timeout = s.IntParam("net.sniff.reassemble.timeout"); err != nil {
+			return err, ctx
+		} else if maxSize <= 0 || maxFlows <= 0 || timeout <= 0 {
+			return fmt.Errorf("net.sniff.reassemble.max, net.sniff.reassemble.flows and net.sniff.reassemble.timeout must be greater than 0"), ctx
+		}
+		ctx.Streams = newTCPStreams(maxSize, maxFlows, time.Duration(timeout)*time.Second)
+	}
+
 	if err, ctx.Output = s.StringParam("net.sniff.output"); err != nil {
 		return err, ctx
 	} else if ctx.Output != "" {
@@ -78,6 +133,12 @@ func (s *Sniffer) GetContext() (error, *SnifferContext) {
 
 		ctx.OutputWriter = pcapgo.NewWriter(ctx.OutputFile)
 		ctx.OutputWriter.WriteFileHeader(65536, ctx.Handle.LinkType())
+
+		for i, handle := range ctx.Handles[1:] {
+			if handle.LinkType() != ctx.Handle.LinkType() {
+				log.Warning("%s has a different link type than %s, its packets won't be readable from %s.", ctx.Interfaces[i+1], ctx.Interfaces[0], ctx.Output)
+			}
+		}
 	}
 
 	return nil, ctx
@@ -86,6 +147,8 @@ func (s *Sniffer) GetContext() (error, *SnifferContext) {
 func NewSnifferContext() *SnifferContext {
 	return &SnifferContext{
 		Handle:       nil,
+		Handles:      make([]*pcap.Handle, 0),
+		Interfaces:   make([]string, 0),
 		DumpLocal:    false,
 		Verbose:      false,
 		Filter:       "",
@@ -94,6 +157,7 @@ func NewSnifferContext() *SnifferContext {
 		Output:       "",
 		OutputFile:   nil,
 		OutputWriter: nil,
+		Streams:      nil,
 	}
 }
 
@@ -107,18 +171,24 @@ var (
 )
 
 func (c *SnifferContext) Log(sess *session.Session) {
+	log.Info("Interfaces         : %s", core.Yellow(strings.Join(c.Interfaces, ", ")))
 	log.Info("Skip local packets : %s", yn[c.DumpLocal])
 	log.Info("Verbose            : %s", yn[c.Verbose])
 	log.Info("BPF Filter         : '%s'", core.Yellow(c.Filter))
 	log.Info("Regular expression : '%s'", core.Yellow(c.Expression))
+	if c.VLAN != nil {
+		log.Info("VLAN               : %s", core.Yellow(c.VLAN.String()))
+	}
 	log.Info("File output        : '%s'", core.Yellow(c.Output))
+	log.Info("TCP reassembly     : %s", yn[c.Streams != nil])
 }
 
 func (c *SnifferContext) Close() {
-	if c.Handle != nil {
-		c.Handle.Close()
-		c.Handle = nil
+	for _, handle := range c.Handles {
+		handle.Close()
 	}
+	c.Handles = nil
+	c.Handle = nil
 
 	if c.OutputFile != nil {
 		c.OutputFile.Close()
@@ -11,35 +11,60 @@ import (
 	"github.com/google/gopacket/layers"
 )
 
-func tcpParser(ip *layers.IPv4, pkt gopacket.Packet, verbose bool) {
-	tcp := pkt.Layer(layers.LayerTypeTCP).(*layers.TCP)
-
+func tcpAppParser(ip *layers.IPv4, pkt gopacket.Packet, tcp *layers.TCP) bool {
 	if sniParser(ip, pkt, tcp) {
-		return
+		return true
 	} else if ntlmParser(ip, pkt, tcp) {
-		return
+		return true
 	} else if httpParser(ip, pkt, tcp) {
+		return true
+	}
+	return false
+}
+
+func tcpParser(ip *layers.IPv4, pkt gopacket.Packet, verbose bool, streams *tcpStreams) {
+	tcp := pkt.Layer(layers.LayerTypeTCP).(*layers.TCP)
+
+	if streams != nil {
+		ready, consumed := streams.Feed(ip, tcp, pkt)
+		for _, stream := range ready {
+			tcpAppParser(stream.ip, stream.pkt, stream.Payload())
+		}
+
+		if consumed {
+			if verbose {
+				tcpVerboseEvent(ip, pkt, tcp)
+			}
+			return
+		}
+	}
+
+	if tcpAppParser(ip, pkt, tcp) {
 		return
 	} else if verbose {
-		NewSnifferEvent(
-			pkt.Metadata().Timestamp,
-			"tcp",
-			fmt.Sprintf("%s:%s", ip.SrcIP, vPort(tcp.SrcPort)),
-			fmt.Sprintf("%s:%s", ip.DstIP, vPort(tcp.DstPort)),
-			SniffData{
-				"Size": len(ip.Payload),
-			},
-			"%s %s:%s > %s:%s %s",
-			core.W(core.BG_LBLUE+core.FG_BLACK, "tcp"),
-			vIP(ip.SrcIP),
-			vPort(tcp.SrcPort),
-			vIP(ip.DstIP),
-			vPort(tcp.DstPort),
-			core.Dim(fmt.Sprintf("%d bytes", len(ip.Payload))),
-		).Push()
+		tcpVerboseEvent(ip, pkt, tcp)
 	}
 }
 
+func tcpVerboseEvent(ip *layers.IPv4, pkt gopacket.Packet, tcp *layers.TCP) {
+	NewSnifferEvent(
+		pkt.Metadata().Timestamp,
+		"tcp",
+		fmt.Sprintf("%s:%s", ip.SrcIP, vPort(tcp.SrcPort)),
+		fmt.Sprintf("%s:%s", ip.DstIP, vPort(tcp.DstPort)),
+		SniffData{
+			"Size": len(ip.Payload),
+		},
+		"%s %s:%s > %s:%s %s",
+		core.W(core.BG_LBLUE+core.FG_BLACK, "tcp"),
+		vIP(ip.SrcIP),
+		vPort(tcp.SrcPort),
+		vIP(ip.DstIP),
+		vPort(tcp.DstPort),
+		core.Dim(fmt.Sprintf("%d bytes", len(ip.Payload))),
+	).Push()
+}
+
 func udpParser(ip *layers.IPv4, pkt gopacket.Packet, verbose bool) {
 	udp := pkt.Layer(layers.LayerTypeUDP).(*layers.UDP)
 
@@ -90,7 +115,7 @@ func unkParser(ip *layers.IPv4, pkt gopacket.Packet, verbose bool) {
 	}
 }
 
-func mainParser(pkt gopacket.Packet, verbose bool) bool {
+func mainParser(pkt gopacket.Packet, verbose bool, streams *tcpStreams) bool {
 	// simple networking sniffing mode?
 	nlayer := pkt.NetworkLayer()
 	if nlayer != nil {
@@ -110,7 +135,7 @@ func mainParser(pkt gopacket.Packet, verbose bool) bool {
 		}
 
 		if tlayer.LayerType() == layers.LayerTypeTCP {
-			tcpParser(ip, pkt, verbose)
+			tcpParser(ip, pkt, verbose, streams)
 		} else if tlayer.LayerType() == layers.LayerTypeUDP {
 			udpParser(ip, pkt, verbose)
 		} else {
@@ -0,0 +1,219 @@
+package modules
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+const tcpStreamsSweepPeriod = time.Second
+
+var httpStreamPrefixes = []string{
+	"GET ", "POST ", "PUT ", "HEAD ", "DELETE ", "OPTIONS ", "PATCH ", "CONNECT ", "HTTP/1.",
+}
+
+// tcpStream is an application layer message (a TLS record or an HTTP
+// request/response) being rebuilt from several TCP segments.
+type tcpStream struct {
+	ip       *layers.IPv4
+	tcp      *layers.TCP
+	pkt      gopacket.Packet
+	nextSeq  uint32
+	data     []byte
+	lastSeen time.Time
+}
+
+// Payload returns a copy of the first segment TCP layer with the reassembled
+// data as its payload, so that the parsers can be used as they are.
+func (s *tcpStream) Payload() *layers.TCP {
+	tcp := *s.tcp
+	tcp.Payload = s.data
+	return &tcp
+}
+
+// tcpStreams does just enough TCP reassembly for the sniffer parsers: only
+// flows starting with something they can parse are buffered, in order
+// segments are appended until the message is complete, out of order ones
+// cause what's buffered to be parsed as it is.
+type tcpStreams struct {
+	maxSize   int
+	maxFlows  int
+	timeout   time.Duration
+	flows     map[string]*tcpStream
+	lastSweep time.Time
+}
+
+func newTCPStreams(maxSize int, maxFlows int, timeout time.Duration) *tcpStreams {
+	return &tcpStreams{
+		maxSize:   maxSize,
+		maxFlows:  maxFlows,
+		timeout:   timeout,
+		flows:     make(map[string]*tcpStream),
+		lastSweep: time.Now(),
+	}
+}
+
+func tcpStreamKey(ip *layers.IPv4, tcp *layers.TCP) string {
+	return ip.SrcIP.String() + ":" + strconv.Itoa(int(tcp.SrcPort)) + ">" + ip.DstIP.String() + ":" + strconv.Itoa(int(tcp.DstPort))
+}
+
+func isStreamStart(data []byte) bool {
+	if len(data) >= 2 && data[0] == 0x16 && data[1] == 0x03 {
+		return true
+	}
+
+	for _, prefix := range httpStreamPrefixes {
+		if bytes.HasPrefix(data, []byte(prefix)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isStreamComplete tells if data contains a whole TLS record or HTTP message.
+func isStreamComplete(data []byte) bool {
+	if data[0] == 0x16 {
+		if len(data) < 5 {
+			return false
+		}
+		return len(data) >= 5+(int(data[3])<<8|int(data[4]))
+	}
+
+	end := bytes.Index(data, []byte("\r\n\r\n"))
+	if end == -1 {
+		return false
+	}
+
+	body := data[end+4:]
+	for _, line := range strings.Split(string(data[:end]), "\r\n") {
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		name := strings.ToLower(strings.TrimSpace(parts[0]))
+		value := strings.ToLower(strings.TrimSpace(parts[1]))
+		if name == "content-length" {
+			if size, err := strconv.Atoi(value); err == nil {
+				return len(body) >= size
+			}
+		} else if name == "transfer-encoding" && strings.Contains(value, "chunked") {
+			return bytes.HasSuffix(body, []byte("0\r\n\r\n"))
+		}
+	}
+
+	return true
+}
+
+func (t *tcpStreams) start(key string, ip *layers.IPv4, tcp *layers.TCP, pkt gopacket.Packet) (ready []*tcpStream) {
+	if len(t.flows) >= t.maxFlows {
+		var oldest *tcpStream
+		oldestKey := ""
+		for k, stream := range t.flows {
+			if oldest == nil || stream.lastSeen.Before(oldest.lastSeen) {
+				oldest, oldestKey = stream, k
+			}
+		}
+		delete(t.flows, oldestKey)
+		ready = append(ready, oldest)
+	}
+
+	t.flows[key] = &tcpStream{
+		ip:       ip,
+		tcp:      tcp,
+		pkt:      pkt,
+		nextSeq:  tcp.Seq + uint32(len(tcp.Payload)),
+		data:     append([]byte(nil), tcp.Payload...),
+		lastSeen: time.Now(),
+	}
+
+	return
+}
+
+// sweep removes the flows that have been idle for longer than the timeout.
+func (t *tcpStreams) sweep(now time.Time) (ready []*tcpStream) {
+	if now.Sub(t.lastSweep) < tcpStreamsSweepPeriod {
+		return
+	}
+	t.lastSweep = now
+
+	for key, stream := range t.flows {
+		if now.Sub(stream.lastSeen) > t.timeout {
+			delete(t.flows, key)
+			ready = append(ready, stream)
+		}
+	}
+	return
+}
+
+// Feed gives a segment to the reassembler, it returns the streams which are
+// ready to be parsed and whether the segment has been consumed, if not it
+// should be parsed on its own.
+func (t *tcpStreams) Feed(ip *layers.IPv4, tcp *layers.TCP, pkt gopacket.Packet) (ready []*tcpStream, consumed bool) {
+	now := time.Now()
+	ready = t.sweep(now)
+
+	key := tcpStreamKey(ip, tcp)
+	size := len(tcp.Payload)
+	stream, found := t.flows[key]
+
+	if found {
+		if tcp.FIN || tcp.RST {
+			delete(t.flows, key)
+			ready = append(ready, stream)
+			return ready, size == 0
+		} else if size == 0 {
+			return ready, false
+		}
+
+		diff := int32(tcp.Seq - stream.nextSeq)
+		if diff < 0 {
+			// retransmission, keep whatever is new
+			if overlap := int(-diff); overlap < size {
+				stream.data = append(stream.data, tcp.Payload[overlap:]...)
+				stream.nextSeq += uint32(size - overlap)
+			}
+		} else if diff == 0 {
+			stream.data = append(stream.data, tcp.Payload...)
+			stream.nextSeq += uint32(size)
+		} else {
+			// we missed something, parse what we have and start over
+			delete(t.flows, key)
+			ready = append(ready, stream)
+			found = false
+		}
+
+		if found {
+			stream.lastSeen = now
+			if len(stream.data) >= t.maxSize || isStreamComplete(stream.data) {
+				if len(stream.data) > t.maxSize {
+					stream.data = stream.data[:t.maxSize]
+				}
+				delete(t.flows, key)
+				ready = append(ready, stream)
+			}
+			return ready, true
+		}
+	}
+
+	if size == 0 || size >= t.maxSize || tcp.FIN || tcp.RST || !isStreamStart(tcp.Payload) || isStreamComplete(tcp.Payload) {
+		return ready, false
+	}
+
+	return append(ready, t.start(key, ip, tcp, pkt)...), true
+}
+
+// Flush returns every stream still being reassembled and resets the state.
+func (t *tcpStreams) Flush() []*tcpStream {
+	ready := make([]*tcpStream, 0, len(t.flows))
+	for _, stream := range t.flows {
+		ready = append(ready, stream)
+	}
+	t.flows = make(map[string]*tcpStream)
+	return ready
+}
@@ -0,0 +1,142 @@
+package modules
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket/layers"
+)
+
+type tcpSegment struct {
+	seq      uint32
+	payload  string
+	fin      bool
+	ready    int
+	consumed bool
+	// data of the last ready stream, if any
+	data string
+}
+
+func testStreamIP() *layers.IPv4 {
+	return &layers.IPv4{
+		SrcIP: net.ParseIP("192.168.1.2"),
+		DstIP: net.ParseIP("192.168.1.1"),
+	}
+}
+
+func testStreamSegment(port uint16, seq uint32, payload string, fin bool) *layers.TCP {
+	tcp := &layers.TCP{
+		SrcPort: layers.TCPPort(port),
+		DstPort: 80,
+		Seq:     seq,
+		FIN:     fin,
+	}
+	tcp.Payload = []byte(payload)
+	return tcp
+}
+
+func TestTCPStreamsFeed(t *testing.T) {
+	request := "GET / HTTP/1.1\r\n"
+	host := "Host: a\r\n\r\n"
+
+	cases := []struct {
+		name     string
+		maxSize  int
+		segments []tcpSegment
+	}{
+		{"in order", 1024, []tcpSegment{
+			{1000, request, false, 0, true, ""},
+			{1016, "Host: a\r\n", false, 0, true, ""},
+			{1025, "\r\n", false, 1, true, request + host},
+		}},
+		{"retransmitted", 1024, []tcpSegment{
+			{1000, request, false, 0, true, ""},
+			{1000, request, false, 0, true, ""},
+			{1006, "HTTP/1.1\r\nHost: a\r\n", false, 0, true, ""},
+			{1025, "\r\n", false, 1, true, request + host},
+		}},
+		{"out of order", 1024, []tcpSegment{
+			{1000, request, false, 0, true, ""},
+			{1100, host, false, 1, false, request},
+		}},
+		{"out of order new message", 1024, []tcpSegment{
+			{1000, request, false, 0, true, ""},
+			{1100, request, false, 1, true, request},
+			{1116, host, false, 1, true, request + host},
+		}},
+		{"over max size", 24, []tcpSegment{
+			{1000, request, false, 0, true, ""},
+			{1016, "Host: aaaaaaaaaa\r\n", false, 1, true, (request + "Host: aaaaaaaaaa\r\n")[:24]},
+		}},
+		{"first segment over max size", 16, []tcpSegment{
+			{1000, request, false, 0, false, ""},
+			{1016, host, false, 0, false, ""},
+		}},
+		{"complete in one segment", 1024, []tcpSegment{
+			{1000, request + host, false, 0, false, ""},
+		}},
+		{"not a message start", 1024, []tcpSegment{
+			{1000, "hello world", false, 0, false, ""},
+		}},
+		{"closed", 1024, []tcpSegment{
+			{1000, request, false, 0, true, ""},
+			{1016, "", true, 1, true, request},
+			{1017, host, false, 0, false, ""},
+		}},
+	}
+
+	for _, tc := range cases {
+		streams := newTCPStreams(tc.maxSize, 16, time.Minute)
+		ip := testStreamIP()
+
+		for i, seg := range tc.segments {
+			ready, consumed := streams.Feed(ip, testStreamSegment(1234, seg.seq, seg.payload, seg.fin), nil)
+			if len(ready) != seg.ready {
+				t.Fatalf("%s: segment %d: expected %d ready streams, got %d", tc.name, i, seg.ready, len(ready))
+			} else if consumed != seg.consumed {
+				t.Fatalf("%s: segment %d: expected consumed to be %v", tc.name, i, seg.consumed)
+			} else if seg.ready > 0 {
+				if data := string(ready[len(ready)-1].data); data != seg.data {
+					t.Fatalf("%s: segment %d: expected '%q', got '%q'", tc.name, i, seg.data, data)
+				}
+			}
+		}
+	}
+}
+
+func TestTCPStreamsEviction(t *testing.T) {
+	streams := newTCPStreams(1024, 2, time.Minute)
+	ip := testStreamIP()
+	request := "GET / HTTP/1.1\r\n"
+
+	for _, port := range []uint16{1, 2} {
+		if ready, consumed := streams.Feed(ip, testStreamSegment(port, 1000, request, false), nil); len(ready) != 0 || !consumed {
+			t.Fatalf("port %d: expected the segment to start a stream", port)
+		}
+	}
+
+	// make the flow from port 2 the least recently seen one
+	for _, stream := range streams.flows {
+		if stream.tcp.SrcPort == 2 {
+			stream.lastSeen = stream.lastSeen.Add(-time.Second)
+		}
+	}
+
+	ready, consumed := streams.Feed(ip, testStreamSegment(3, 1000, request, false), nil)
+	if !consumed {
+		t.Fatal("expected the segment to start a stream")
+	} else if len(ready) != 1 {
+		t.Fatalf("expected 1 evicted stream, got %d", len(ready))
+	} else if ready[0].tcp.SrcPort != 2 {
+		t.Fatalf("expected the stream from port 2 to be evicted, got %d", ready[0].tcp.SrcPort)
+	} else if len(streams.flows) != 2 {
+		t.Fatalf("expected 2 flows, got %d", len(streams.flows))
+	}
+
+	if flushed := streams.Flush(); len(flushed) != 2 {
+		t.Fatalf("expected 2 flushed streams, got %d", len(flushed))
+	} else if len(streams.flows) != 0 {
+		t.Fatal("expected no flows after a flush")
+	}
+}
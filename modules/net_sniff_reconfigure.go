@@ -0,0 +1,151 @@
+package modules
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/bettercap/bettercap/core"
+	"github.com/bettercap/bettercap/log"
+	"github.com/bettercap/bettercap/packets"
+
+	"github.com/google/gopacket/pcapgo"
+)
+
+// Reconfigure applies the current net.sniff.* parameters to the running
+// sniffer, the ones that can't change without reopening the capture handles
+// are only reported.
+func (s *Sniffer) Reconfigure() error {
+	if !s.Running() || s.Ctx == nil {
+		return fmt.Errorf("net.sniff is not running, set the parameters and start it.")
+	}
+
+	var err error
+	var verbose, local bool
+	var filter, expression, output, source, vlan string
+	var ifaces []string
+
+	if err, verbose = s.BoolParam("net.sniff.verbose"); err != nil {
+		return err
+	} else if err, local = s.BoolParam("net.sniff.local"); err != nil {
+		return err
+	} else if err, filter = s.StringParam("net.sniff.filter"); err != nil {
+		return err
+	} else if err, expression = s.StringParam("net.sniff.regexp"); err != nil {
+		return err
+	} else if err, output = s.StringParam("net.sniff.output"); err != nil {
+		return err
+	} else if err, vlan = s.StringParam("net.sniff.vlan"); err != nil {
+		return err
+	} else if err, source = s.StringParam("net.sniff.source"); err != nil {
+		return err
+	} else if err, ifaces = s.ListParam("net.sniff.interface"); err != nil {
+		return err
+	}
+
+	// validate what we can before touching anything
+	var compiled *regexp.Regexp
+	if expression != "" {
+		if compiled, err = regexp.Compile(expression); err != nil {
+			return err
+		}
+	}
+
+	err, vlanFilter := packets.ParseVLANFilter(vlan)
+	if err != nil {
+		return err
+	}
+
+	// hold the packets processing while we swap things around
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	ctx := s.Ctx
+	changed := []string{}
+	restart := []string{}
+
+	if filter != ctx.Filter {
+		for i, handle := range ctx.Handles {
+			if err = handle.SetBPFFilter(filter); err != nil {
+				// put back the previous filter on the handles we already changed
+				for _, prev := range ctx.Handles[:i] {
+					prev.SetBPFFilter(ctx.Filter)
+				}
+				return fmt.Errorf("could not set BPF filter '%s' on %s: %s", filter, ctx.Interfaces[i], err)
+			}
+		}
+		ctx.Filter = filter
+		changed = append(changed, "net.sniff.filter")
+	}
+
+	if output != ctx.Output {
+		if output != "" {
+			file, err := os.Create(output)
+			if err != nil {
+				return err
+			}
+
+			writer := pcapgo.NewWriter(file)
+			if err = writer.WriteFileHeader(65536, ctx.Handle.LinkType()); err != nil {
+				file.Close()
+				return err
+			}
+
+			if ctx.OutputFile != nil {
+				ctx.OutputFile.Close()
+			}
+			ctx.OutputFile, ctx.OutputWriter = file, writer
+		} else if ctx.OutputFile != nil {
+			ctx.OutputFile.Close()
+			ctx.OutputFile, ctx.OutputWriter = nil, nil
+		}
+
+		log.Info("sniffer output rotated from '%s' to '%s'.", ctx.Output, output)
+		ctx.Output = output
+		changed = append(changed, "net.sniff.output")
+	}
+
+	if expression != ctx.Expression {
+		ctx.Expression, ctx.Compiled = expression, compiled
+		changed = append(changed, "net.sniff.regexp")
+	}
+
+	if vlanFilter.String() != ctx.VLAN.String() {
+		ctx.VLAN = vlanFilter
+		changed = append(changed, "net.sniff.vlan")
+	}
+
+	if verbose != ctx.Verbose {
+		ctx.Verbose = verbose
+		changed = append(changed, "net.sniff.verbose")
+	}
+
+	if local != ctx.DumpLocal {
+		ctx.DumpLocal = local
+		changed = append(changed, "net.sniff.local")
+	}
+
+	if source != ctx.Source {
+		restart = append(restart, "net.sniff.source")
+	} else if source == "" {
+		if len(ifaces) == 0 {
+			ifaces = []string{s.Session.Interface.Name()}
+		}
+		if strings.Join(ifaces, ",") != strings.Join(ctx.Interfaces, ",") {
+			restart = append(restart, "net.sniff.interface")
+		}
+	}
+
+	if len(changed) == 0 {
+		log.Info("nothing to reconfigure.")
+	} else {
+		log.Info("applied %s.", core.Bold(strings.Join(changed, ", ")))
+	}
+
+	if len(restart) > 0 {
+		log.Warning("%s can't be changed while sniffing, restart net.sniff to apply it.", strings.Join(restart, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,169 @@
+package modules
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/bettercap/bettercap/core"
+
+	"github.com/dustin/go-humanize"
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+type TopCounter struct {
+	Name    string `json:"name"`
+	Packets uint64 `json:"packets"`
+	Bytes   uint64 `json:"bytes"`
+}
+
+type TopReport struct {
+	Talkers   []TopCounter `json:"talkers"`
+	Protocols []TopCounter `json:"protocols"`
+	Ports     []TopCounter `json:"ports"`
+}
+
+type topBucket map[string]*TopCounter
+
+func (b topBucket) track(name string, size uint64) {
+	if c, found := b[name]; found {
+		c.Packets++
+		c.Bytes += size
+	} else {
+		b[name] = &TopCounter{Name: name, Packets: 1, Bytes: size}
+	}
+}
+
+func (b topBucket) top(byBytes bool, limit int) []TopCounter {
+	list := make([]TopCounter, 0, len(b))
+	for _, c := range b {
+		list = append(list, *c)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if byBytes && list[i].Bytes != list[j].Bytes {
+			return list[i].Bytes > list[j].Bytes
+		} else if !byBytes && list[i].Packets != list[j].Packets {
+			return list[i].Packets > list[j].Packets
+		}
+		return list[i].Name < list[j].Name
+	})
+
+	if limit > 0 && len(list) > limit {
+		list = list[:limit]
+	}
+	return list
+}
+
+// SnifferTop keeps per talker, protocol and destination port counters
+// of the packets seen by the sniffer.
+type SnifferTop struct {
+	sync.Mutex
+	talkers   topBucket
+	protocols topBucket
+	ports     topBucket
+}
+
+func NewSnifferTop() *SnifferTop {
+	t := &SnifferTop{}
+	t.Reset()
+	return t
+}
+
+func (t *SnifferTop) Reset() {
+	t.Lock()
+	defer t.Unlock()
+
+	t.talkers = make(topBucket)
+	t.protocols = make(topBucket)
+	t.ports = make(topBucket)
+}
+
+func (t *SnifferTop) Track(pkt gopacket.Packet) {
+	size := uint64(len(pkt.Data()))
+
+	src := ""
+	if ip4, ok := pkt.Layer(layers.LayerTypeIPv4).(*layers.IPv4); ok {
+		src = ip4.SrcIP.String()
+	} else if ip6, ok := pkt.Layer(layers.LayerTypeIPv6).(*layers.IPv6); ok {
+		src = ip6.SrcIP.String()
+	}
+
+	// only count the topmost decoded protocol, so every packet is
+	// accounted for exactly once
+	proto := ""
+	for _, layer := range pkt.Layers() {
+		if lt := layer.LayerType(); lt != gopacket.LayerTypeDecodeFailure && lt != gopacket.LayerTypePayload {
+			proto = lt.String()
+		}
+	}
+
+	port := ""
+	if tcp, ok := pkt.Layer(layers.LayerTypeTCP).(*layers.TCP); ok {
+		port = fmt.Sprintf("tcp/%d", tcp.DstPort)
+	} else if udp, ok := pkt.Layer(layers.LayerTypeUDP).(*layers.UDP); ok {
+		port = fmt.Sprintf("udp/%d", udp.DstPort)
+	}
+
+	t.Lock()
+	defer t.Unlock()
+
+	if src != "" {
+		t.talkers.track(src, size)
+	}
+	if proto != "" {
+		t.protocols.track(proto, size)
+	}
+	if port != "" {
+		t.ports.track(port, size)
+	}
+}
+
+func (t *SnifferTop) Report(byBytes bool, limit int) TopReport {
+	t.Lock()
+	defer t.Unlock()
+
+	return TopReport{
+		Talkers:   t.talkers.top(byBytes, limit),
+		Protocols: t.protocols.top(byBytes, limit),
+		Ports:     t.ports.top(byBytes, limit),
+	}
+}
+
+func printTopTable(title string, counters []TopCounter) {
+	fmt.Printf("\n%s\n\n", core.Bold(title))
+	if len(counters) == 0 {
+		fmt.Printf("  %s\n", core.Dim("nothing yet"))
+		return
+	}
+
+	rows := make([][]string, 0, len(counters))
+	for _, c := range counters {
+		rows = append(rows, []string{c.Name, fmt.Sprintf("%d", c.Packets), humanize.Bytes(c.Bytes)})
+	}
+	core.AsTable(os.Stdout, []string{"Name", "Packets", "Bytes"}, rows)
+}
+
+func (s *Sniffer) ShowTop(by string) error {
+	if s.Top == nil {
+		return fmt.Errorf("No stats yet.")
+	}
+
+	err, limit := s.IntParam("net.sniff.top.limit")
+	if err != nil {
+		return err
+	}
+
+	report := s.Top.Report(by == "bytes", limit)
+
+	printTopTable("Top Talkers", report.Talkers)
+	printTopTable("Top Protocols", report.Protocols)
+	printTopTable("Top Destination Ports", report.Ports)
+	fmt.Println()
+
+	s.Session.Refresh()
+
+	return nil
+}
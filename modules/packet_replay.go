@@ -0,0 +1,237 @@
+package modules
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/bettercap/bettercap/core"
+	"github.com/bettercap/bettercap/log"
+	"github.com/bettercap/bettercap/session"
+
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcap"
+)
+
+const replayWaitStep = 100 * time.Millisecond
+
+type PacketReplay struct {
+	session.SessionModule
+	realtime  bool
+	waitGroup *sync.WaitGroup
+}
+
+func NewPacketReplay(s *session.Session) *PacketReplay {
+	r := &PacketReplay{
+		SessionModule: session.NewSessionModule("packet.replay", s),
+		realtime:      true,
+		waitGroup:     &sync.WaitGroup{},
+	}
+
+	r.AddParam(session.NewStringParameter("packet.record.output",
+		"~/bettercap-injected.pcap",
+		"",
+		"File where every frame injected by the session will be written while recording."))
+
+	r.AddParam(session.NewBoolParameter("packet.replay.realtime",
+		"true",
+		"If true, frames will be replayed with their recorded timing, otherwise as fast as possible."))
+
+	r.AddHandler(session.NewModuleHandler("packet.record on", "",
+		"Start recording every injected frame (arp, dns, deauth, ...) to packet.record.output.",
+		func(args []string) error {
+			return r.startRecording()
+		}))
+
+	r.AddHandler(session.NewModuleHandler("packet.record off", "",
+		"Stop recording injected frames.",
+		func(args []string) error {
+			return r.stopRecording()
+		}))
+
+	r.AddHandler(session.NewModuleHandler("packet.replay off", "",
+		"Stop replaying frames.",
+		func(args []string) error {
+			return r.Stop()
+		}))
+
+	r.AddHandler(session.NewModuleHandler("packet.replay FILE", `packet\.replay ([^\s]+)`,
+		"Inject again every frame of the given pcap file.",
+		func(args []string) error {
+			return r.replay(args[0])
+		}))
+
+	return r
+}
+
+func (r *PacketReplay) Name() string {
+	return "packet.replay"
+}
+
+func (r *PacketReplay) Description() string {
+	return "Record injected frames to a pcap file and replay them later."
+}
+
+func (r *PacketReplay) Author() string {
+	return "Simone Margaritelli <evilsocket@protonmail.com>"
+}
+
+func (r *PacketReplay) Configure() (err error) {
+	err, r.realtime = r.BoolParam("packet.replay.realtime")
+	return
+}
+
+func (r *PacketReplay) Start() error {
+	return fmt.Errorf("use packet.replay FILE to start replaying a capture")
+}
+
+func (r *PacketReplay) Stop() error {
+	return r.SetRunning(false, func() {
+		r.waitGroup.Wait()
+	})
+}
+
+func (r *PacketReplay) startRecording() error {
+	err, output := r.StringParam("packet.record.output")
+	if err != nil {
+		return err
+	} else if output, err = core.ExpandPath(output); err != nil {
+		return err
+	} else if err = r.Session.Queue.StartRecording(output); err != nil {
+		return err
+	}
+
+	log.Info("recording injected frames to %s ...", core.Bold(output))
+	return nil
+}
+
+func (r *PacketReplay) stopRecording() error {
+	err, recorder := r.Session.Queue.StopRecording()
+	if recorder != nil {
+		log.Info("recorded %d frames to %s (%d skipped).", recorder.Frames, core.Bold(recorder.FileName), recorder.Skipped)
+	}
+	return err
+}
+
+// maxFrameSize returns the biggest frame we can inject on the interface
+// given its MTU, 0 means we can't tell and we won't check.
+func (r *PacketReplay) maxFrameSize(linkType layers.LinkType) int {
+	if linkType != layers.LinkTypeEthernet {
+		return 0
+	} else if iface, err := net.InterfaceByName(r.Session.Interface.Name()); err == nil && iface.MTU > 0 {
+		// ethernet header + 802.1Q tag
+		return iface.MTU + 14 + 4
+	}
+	return 0
+}
+
+// wait sleeps for the recorded delay between two frames in short steps,
+// so that a long gap in the capture doesn't keep packet.replay off (and
+// Stop) waiting, it returns false if the module has been stopped.
+func (r *PacketReplay) wait(delay time.Duration) bool {
+	for deadline := time.Now().Add(delay); r.Running(); {
+		left := deadline.Sub(time.Now())
+		if left <= 0 {
+			return true
+		} else if left > replayWaitStep {
+			left = replayWaitStep
+		}
+		time.Sleep(left)
+	}
+	return false
+}
+
+func (r *PacketReplay) replay(fileName string) error {
+	if r.Running() {
+		return session.ErrAlreadyStarted
+	} else if err := r.Configure(); err != nil {
+		return err
+	}
+
+	fileName, err := core.ExpandPath(fileName)
+	if err != nil {
+		return err
+	}
+
+	input, err := pcap.OpenOffline(fileName)
+	if err != nil {
+		return err
+	}
+
+	output, err := pcap.OpenLive(r.Session.Interface.Name(), 65536, true, pcap.BlockForever)
+	if err != nil {
+		input.Close()
+		return err
+	}
+
+	if input.LinkType() != output.LinkType() {
+		input.Close()
+		output.Close()
+		return fmt.Errorf("can't replay %s frames on %s, interface link type is %s",
+			input.LinkType(),
+			r.Session.Interface.Name(),
+			output.LinkType())
+	}
+
+	r.waitGroup.Add(1)
+	if err := r.SetRunning(true, func() {
+		defer r.SetRunning(false, nil)
+		defer r.waitGroup.Done()
+
+		defer input.Close()
+		defer output.Close()
+
+		maxSize := r.maxFrameSize(input.LinkType())
+		sent := 0
+		skipped := 0
+		prev := time.Time{}
+
+		log.Info("replaying frames from %s ...", core.Bold(fileName))
+
+		for r.Running() {
+			data, ci, err := input.ReadPacketData()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				log.Error("error while reading %s: %s", fileName, err)
+				break
+			}
+
+			if r.realtime && !prev.IsZero() {
+				if delay := ci.Timestamp.Sub(prev); delay > 0 && !r.wait(delay) {
+					break
+				}
+			}
+			prev = ci.Timestamp
+
+			if maxSize > 0 && len(data) > maxSize {
+				log.Warning("skipping frame of %d bytes, interface MTU allows up to %d.", len(data), maxSize)
+				skipped++
+				continue
+			}
+
+			if r.Session.Queue.DryRun(output.LinkType(), data) {
+				sent++
+			} else if err := output.WritePacketData(data); err != nil {
+				log.Error("could not replay frame: %s", err)
+				r.Session.Queue.TrackError()
+				skipped++
+			} else {
+				r.FrameSent()
+				r.Session.Queue.TrackSent(uint64(len(data)))
+				sent++
+			}
+		}
+
+		log.Info("replayed %d frames from %s (%d skipped).", sent, fileName, skipped)
+	}); err != nil {
+		r.waitGroup.Done()
+		input.Close()
+		output.Close()
+		return err
+	}
+
+	return nil
+}
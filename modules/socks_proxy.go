@@ -0,0 +1,374 @@
+package modules
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bettercap/bettercap/log"
+	"github.com/bettercap/bettercap/session"
+)
+
+const (
+	socksVersion        = 0x05
+	socksAuthVersion    = 0x01
+	socksMethodNoAuth   = 0x00
+	socksMethodUserPass = 0x02
+	socksMethodNone     = 0xff
+	socksCmdConnect     = 0x01
+	socksAddrIPv4       = 0x01
+	socksAddrDomain     = 0x03
+	socksAddrIPv6       = 0x04
+
+	socksReplySuccess        = 0x00
+	socksReplyFailure        = 0x01
+	socksReplyUnreachable    = 0x04
+	socksReplyRefused        = 0x05
+	socksReplyCmdUnsupported = 0x07
+	socksReplyAddrUnsupport  = 0x08
+
+	socksHandshakeTimeout = 10 * time.Second
+)
+
+type SocksConnection struct {
+	Client      string `json:"client"`
+	Username    string `json:"username"`
+	Destination string `json:"destination"`
+}
+
+type SocksProxy struct {
+	session.SessionModule
+	localAddr *net.TCPAddr
+	listener  *net.TCPListener
+	username  string
+	password  string
+	events    bool
+	dialer    *net.Dialer
+}
+
+func NewSocksProxy(s *session.Session) *SocksProxy {
+	p := &SocksProxy{
+		SessionModule: session.NewSessionModule("socks.proxy", s),
+	}
+
+	p.AddParam(session.NewStringParameter("socks.proxy.address",
+		"127.0.0.1",
+		session.IPv4Validator,
+		"Address to bind the SOCKS5 proxy to."))
+
+	p.AddParam(session.NewIntParameter("socks.proxy.port",
+		"1080",
+		"Port to bind the SOCKS5 proxy to."))
+
+	p.AddParam(session.NewStringParameter("socks.proxy.username",
+		"",
+		"",
+		"SOCKS5 authentication username, if empty together with the password authentication is disabled."))
+
+	p.AddParam(session.NewStringParameter("socks.proxy.password",
+		"",
+		"",
+		"SOCKS5 authentication password."))
+
+	p.AddParam(session.NewBoolParameter("socks.proxy.events",
+		"false",
+		"If true, a socks.proxy.connect event will be emitted for every connection with its destination."))
+
+	p.AddHandler(session.NewModuleHandler("socks.proxy on", "",
+		"Start the SOCKS5 proxy.",
+		func(args []string) error {
+			return p.Start()
+		}))
+
+	p.AddHandler(session.NewModuleHandler("socks.proxy off", "",
+		"Stop the SOCKS5 proxy.",
+		func(args []string) error {
+			return p.Stop()
+		}))
+
+	return p
+}
+
+func (p *SocksProxy) Name() string {
+	return "socks.proxy"
+}
+
+func (p *SocksProxy) Description() string {
+	return "A SOCKS5 proxy that forwards connections through the bettercap interface."
+}
+
+func (p *SocksProxy) Author() string {
+	return "Simone Margaritelli <evilsocket@protonmail.com>"
+}
+
+func (p *SocksProxy) Configure() error {
+	var err error
+	var address string
+	var port int
+
+	if p.Running() {
+		return session.ErrAlreadyStarted
+	} else if err, address = p.StringParam("socks.proxy.address"); err != nil {
+		return err
+	} else if err, port = p.IntParam("socks.proxy.port"); err != nil {
+		return err
+	} else if err, p.username = p.StringParam("socks.proxy.username"); err != nil {
+		return err
+	} else if err, p.password = p.StringParam("socks.proxy.password"); err != nil {
+		return err
+	} else if err, p.events = p.BoolParam("socks.proxy.events"); err != nil {
+		return err
+	} else if p.localAddr, err = net.ResolveTCPAddr("tcp", fmt.Sprintf("%s:%d", address, port)); err != nil {
+		return err
+	} else if p.listener, err = net.ListenTCP("tcp", p.localAddr); err != nil {
+		return err
+	}
+
+	// outgoing connections always leave from the bettercap interface
+	p.dialer = &net.Dialer{
+		Timeout:   socksHandshakeTimeout,
+		LocalAddr: &net.TCPAddr{IP: p.Session.Interface.IP},
+	}
+
+	if p.username == "" || p.password == "" {
+		log.Warning("socks.proxy.username and/or socks.proxy.password parameters are empty, authentication is disabled.")
+	}
+
+	return nil
+}
+
+func (p *SocksProxy) authRequired() bool {
+	return p.username != "" && p.password != ""
+}
+
+// negotiate performs the SOCKS5 method selection and, if required, the
+// username/password authentication, returning the authenticated user.
+func (p *SocksProxy) negotiate(c net.Conn) (error, string) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(c, header); err != nil {
+		return err, ""
+	} else if header[0] != socksVersion {
+		return fmt.Errorf("unsupported SOCKS version %d", header[0]), ""
+	}
+
+	methods := make([]byte, header[1])
+	if _, err := io.ReadFull(c, methods); err != nil {
+		return err, ""
+	}
+
+	wanted := byte(socksMethodNoAuth)
+	if p.authRequired() {
+		wanted = socksMethodUserPass
+	}
+
+	found := false
+	for _, m := range methods {
+		if m == wanted {
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		c.Write([]byte{socksVersion, socksMethodNone})
+		return fmt.Errorf("no acceptable authentication method"), ""
+	} else if _, err := c.Write([]byte{socksVersion, wanted}); err != nil {
+		return err, ""
+	} else if wanted == socksMethodNoAuth {
+		return nil, ""
+	}
+
+	// RFC 1929 username/password sub negotiation
+	if _, err := io.ReadFull(c, header); err != nil {
+		return err, ""
+	} else if header[0] != socksAuthVersion {
+		return fmt.Errorf("unsupported authentication version %d", header[0]), ""
+	}
+
+	user := make([]byte, header[1])
+	if _, err := io.ReadFull(c, user); err != nil {
+		return err, ""
+	}
+
+	size := make([]byte, 1)
+	if _, err := io.ReadFull(c, size); err != nil {
+		return err, ""
+	}
+
+	pass := make([]byte, size[0])
+	if _, err := io.ReadFull(c, pass); err != nil {
+		return err, ""
+	}
+
+	if subtle.ConstantTimeCompare(user, []byte(p.username)) != 1 || subtle.ConstantTimeCompare(pass, []byte(p.password)) != 1 {
+		c.Write([]byte{socksAuthVersion, 0x01})
+		return fmt.Errorf("authentication failed for user '%s'", string(user)), ""
+	}
+
+	_, err := c.Write([]byte{socksAuthVersion, 0x00})
+	return err, string(user)
+}
+
+// readRequest reads the client request and returns the destination address.
+func (p *SocksProxy) readRequest(c net.Conn) (error, string, byte) {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(c, header); err != nil {
+		return err, "", socksReplyFailure
+	} else if header[0] != socksVersion {
+		return fmt.Errorf("unsupported SOCKS version %d", header[0]), "", socksReplyFailure
+	} else if header[1] != socksCmdConnect {
+		return fmt.Errorf("unsupported command %d", header[1]), "", socksReplyCmdUnsupported
+	}
+
+	host := ""
+	switch header[3] {
+	case socksAddrIPv4, socksAddrIPv6:
+		size := net.IPv4len
+		if header[3] == socksAddrIPv6 {
+			size = net.IPv6len
+		}
+		ip := make([]byte, size)
+		if _, err := io.ReadFull(c, ip); err != nil {
+			return err, "", socksReplyFailure
+		}
+		host = net.IP(ip).String()
+
+	case socksAddrDomain:
+		size := make([]byte, 1)
+		if _, err := io.ReadFull(c, size); err != nil {
+			return err, "", socksReplyFailure
+		}
+		domain := make([]byte, size[0])
+		if _, err := io.ReadFull(c, domain); err != nil {
+			return err, "", socksReplyFailure
+		}
+		host = string(domain)
+
+	default:
+		return fmt.Errorf("unsupported address type %d", header[3]), "", socksReplyAddrUnsupport
+	}
+
+	port := make([]byte, 2)
+	if _, err := io.ReadFull(c, port); err != nil {
+		return err, "", socksReplyFailure
+	}
+
+	return nil, net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port)))), socksReplySuccess
+}
+
+func (p *SocksProxy) reply(c net.Conn, code byte, bound net.Addr) error {
+	ip := net.IPv4zero.To4()
+	port := 0
+	if addr, ok := bound.(*net.TCPAddr); ok && addr != nil {
+		if ip4 := addr.IP.To4(); ip4 != nil {
+			ip = ip4
+		}
+		port = addr.Port
+	}
+
+	msg := []byte{socksVersion, code, 0x00, socksAddrIPv4}
+	msg = append(msg, ip...)
+	msg = append(msg, byte(port>>8), byte(port&0xff))
+
+	_, err := c.Write(msg)
+	return err
+}
+
+func (p *SocksProxy) handleConnection(c *net.TCPConn) {
+	defer c.Close()
+
+	client := c.RemoteAddr().String()
+	c.SetDeadline(time.Now().Add(socksHandshakeTimeout))
+
+	err, user := p.negotiate(c)
+	if err != nil {
+		log.Warning("SOCKS5 negotiation with %s failed: %s", client, err)
+		return
+	}
+
+	err, destination, code := p.readRequest(c)
+	if err != nil {
+		log.Warning("invalid SOCKS5 request from %s: %s", client, err)
+		p.reply(c, code, nil)
+		return
+	}
+
+	remote, err := p.dialer.Dial("tcp", destination)
+	if err != nil {
+		log.Warning("SOCKS5 connection from %s to %s failed: %s", client, destination, err)
+		code = socksReplyUnreachable
+		if strings.Contains(err.Error(), "connection refused") {
+			code = socksReplyRefused
+		}
+		p.reply(c, code, nil)
+		return
+	}
+	defer remote.Close()
+
+	if err = p.reply(c, socksReplySuccess, remote.LocalAddr()); err != nil {
+		return
+	}
+	c.SetDeadline(time.Time{})
+
+	log.Debug("SOCKS5 %s -> %s", client, destination)
+	if p.events {
+		p.Session.Events.Add("socks.proxy.connect", SocksConnection{
+			Client:      client,
+			Username:    user,
+			Destination: destination,
+		})
+	}
+
+	wg := sync.WaitGroup{}
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+		io.Copy(remote, c)
+		if tcp, ok := remote.(*net.TCPConn); ok {
+			tcp.CloseWrite()
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+		io.Copy(c, remote)
+		c.CloseWrite()
+	}()
+
+	wg.Wait()
+}
+
+func (p *SocksProxy) Start() error {
+	if err := p.Configure(); err != nil {
+		return err
+	}
+
+	return p.SetRunning(true, func() {
+		log.Info("SOCKS5 proxy started on %s", p.localAddr.String())
+
+		for p.Running() {
+			conn, err := p.listener.AcceptTCP()
+			if err != nil {
+				if p.Running() {
+					log.Warning("Error while accepting SOCKS5 connection: %s", err)
+				}
+				continue
+			}
+
+			go p.handleConnection(conn)
+		}
+	})
+}
+
+func (p *SocksProxy) Stop() error {
+	return p.SetRunning(false, func() {
+		p.listener.Close()
+	})
+}
@@ -2,6 +2,7 @@ package modules
 
 import (
 	"fmt"
+	"math/rand"
 	"net"
 	"strconv"
 	"sync"
@@ -21,12 +22,41 @@ import (
 
 const synSourcePort = 666
 
+type synProbe struct {
+	address string
+	port    int
+}
+
+// synProbeState tracks how many times a port without a reply has been
+// probed, it's kept until the port answers or the scan is over, so that
+// late and duplicate replies are not counted again.
+type synProbeState struct {
+	ip       net.IP
+	mac      net.HardwareAddr
+	attempts int
+}
+
 type SynScanner struct {
 	session.SessionModule
-	addresses []net.IP
-	startPort int
-	endPort   int
-	waitGroup *sync.WaitGroup
+	addresses  []net.IP
+	startPort  int
+	endPort    int
+	output     string
+	format     string
+	writer     *synScanWriter
+	writerLock *sync.Mutex
+	stealth    bool
+	delay      int
+	jitter     int
+	randomize  bool
+	retries    int
+	retryWait  int
+	decoys     []net.IP
+	probes     map[synProbe]*synProbeState
+	counters   SynScanSummary
+	launched   int
+	probesLock *sync.Mutex
+	waitGroup  *sync.WaitGroup
 }
 
 func NewSynScanner(s *session.Session) *SynScanner {
@@ -35,9 +65,51 @@ func NewSynScanner(s *session.Session) *SynScanner {
 		addresses:     make([]net.IP, 0),
 		startPort:     0,
 		endPort:       0,
+		probes:        make(map[synProbe]*synProbeState),
+		probesLock:    &sync.Mutex{},
+		writerLock:    &sync.Mutex{},
 		waitGroup:     &sync.WaitGroup{},
 	}
 
+	ss.AddParam(session.NewStringParameter("syn.scan.output",
+		"",
+		"",
+		"If not empty, open ports will be appended to this file as soon as they're found."))
+
+	ss.AddParam(session.NewStringParameter("syn.scan.format",
+		"json",
+		"^(json|csv)$",
+		"Format of syn.scan.output, either json (one object per line) or csv."))
+
+	ss.AddParam(session.NewBoolParameter("syn.scan.stealth",
+		"false",
+		"If true, wait syn.scan.delay milliseconds plus a random jitter between each SYN packet, this makes the scan much slower but harder to detect."))
+
+	ss.AddParam(session.NewIntParameter("syn.scan.delay",
+		"500",
+		"Milliseconds to wait between each SYN packet in stealth mode."))
+
+	ss.AddParam(session.NewIntParameter("syn.scan.jitter",
+		"250",
+		"Maximum random milliseconds to add to syn.scan.delay in stealth mode."))
+
+	ss.AddParam(session.NewBoolParameter("syn.scan.randomize",
+		"false",
+		"If true, scan the ports in random order."))
+
+	ss.AddParam(session.NewIntParameter("syn.scan.retries",
+		"0",
+		"How many more times ports that didn't answer with either a SYN+ACK or a RST are probed before being considered filtered, useful on lossy links."))
+
+	ss.AddParam(session.NewIntParameter("syn.scan.retries.wait",
+		"1000",
+		"Milliseconds to wait for replies before probing again the ports that didn't answer."))
+
+	ss.AddParam(session.NewStringParameter("syn.scan.decoys",
+		"",
+		"",
+		"Comma separated list of spoofed source addresses each SYN packet is also sent from, our own address is mixed in at a random position so that replies are still received."))
+
 	ss.AddHandler(session.NewModuleHandler("syn.scan IP-RANGE [START-PORT] [END-PORT]", "syn.scan ([^\\s]+) ?(\\d+)?([\\s\\d]*)?",
 		"Perform a syn port scanning against an IP address within the provided ports range.",
 		func(args []string) error {
@@ -50,8 +122,14 @@ func NewSynScanner(s *session.Session) *SynScanner {
 				return fmt.Errorf("Error while parsing IP range '%s': %s", args[0], err)
 			}
 
+			// a large range would take a while to expand, check it before
+			if err := ss.Session.CheckTargets("syn.scan", network.RangeSize(iprange.AddressRangeList{*list})); err != nil {
+				return err
+			}
+			addresses := list.Expand()
+
 			argc := len(args)
-			ss.addresses = list.Expand()
+			ss.addresses = addresses
 			ss.startPort = 1
 			ss.endPort = 65535
 
@@ -76,9 +154,25 @@ func NewSynScanner(s *session.Session) *SynScanner {
 				return fmt.Errorf("END-PORT is greater than START-PORT")
 			}
 
+			if err := ss.Configure(); err != nil {
+				return err
+			}
+
 			return ss.synScan()
 		}))
 
+	ss.AddHandler(session.NewModuleHandler("syn.scan.import FILE", `^syn\.scan\.import\s+(.+)$`,
+		"Import the hosts, open ports, services and OS guesses of an nmap XML report (nmap -oX), merging them with the known endpoints.",
+		func(args []string) error {
+			return ss.Import(args[0])
+		}))
+
+	ss.AddHandler(session.NewModuleHandler("syn.scan.diff OLD NEW", `^syn\.scan\.diff\s+([^\s]+)\s+([^\s]+)$`,
+		"Compare two syn.scan.output files and show which ports have been opened or closed on each host.",
+		func(args []string) error {
+			return ss.scanDiff(args[0], args[1])
+		}))
+
 	return ss
 }
 
@@ -94,7 +188,47 @@ func (s *SynScanner) Author() string {
 	return "Simone Margaritelli <evilsocket@protonmail.com>"
 }
 
-func (s *SynScanner) Configure() error {
+func (s *SynScanner) Configure() (err error) {
+	if err, s.output = s.StringParam("syn.scan.output"); err != nil {
+		return err
+	} else if s.output != "" {
+		if s.output, err = core.ExpandPath(s.output); err != nil {
+			return err
+		}
+	}
+
+	if err, s.format = s.StringParam("syn.scan.format"); err != nil {
+		return err
+	} else if err, s.stealth = s.BoolParam("syn.scan.stealth"); err != nil {
+		return err
+	} else if err, s.delay = s.IntParam("syn.scan.delay"); err != nil {
+		return err
+	} else if err, s.jitter = s.IntParam("syn.scan.jitter"); err != nil {
+		return err
+	} else if err, s.randomize = s.BoolParam("syn.scan.randomize"); err != nil {
+		return err
+	} else if err, s.retries = s.IntParam("syn.scan.retries"); err != nil {
+		return err
+	} else if err, s.retryWait = s.IntParam("syn.scan.retries.wait"); err != nil {
+		return err
+	}
+
+	if err, decoys := s.ListParam("syn.scan.decoys"); err != nil {
+		return err
+	} else if err, s.decoys = parseDecoys(decoys, s.Session.Interface.IP); err != nil {
+		return err
+	}
+
+	if s.delay < 0 {
+		return fmt.Errorf("syn.scan.delay can't be negative")
+	} else if s.jitter < 0 {
+		return fmt.Errorf("syn.scan.jitter can't be negative")
+	} else if s.retries < 0 {
+		return fmt.Errorf("syn.scan.retries can't be negative")
+	} else if s.retryWait < 0 {
+		return fmt.Errorf("syn.scan.retries.wait can't be negative")
+	}
+
 	return nil
 }
 
@@ -129,35 +263,227 @@ func (s *SynScanner) onPacket(pkt gopacket.Packet) {
 		return
 	}
 
-	if s.inRange(ip.SrcIP) && tcp.DstPort == synSourcePort && tcp.SYN && tcp.ACK {
-		from := ip.SrcIP.String()
-		port := int(tcp.SrcPort)
+	if !s.inRange(ip.SrcIP) || tcp.DstPort != synSourcePort {
+		return
+	}
+
+	from := ip.SrcIP.String()
+	port := int(tcp.SrcPort)
+	isOpen := tcp.SYN && tcp.ACK
+
+	if !isOpen && !tcp.RST {
+		return
+	} else if !s.onReply(from, port, isOpen) || !isOpen {
+		// not one of ours, already answered or closed
+		return
+	}
+
+	host := s.hostFor(from)
+	if host != nil {
+		ports := host.Meta.GetIntsWith("tcp-ports", port, true)
+		host.Meta.SetInts("tcp-ports", ports)
+	}
+
+	event := NewSynScanEvent(from, host, port)
+	s.writeResult(event)
+	event.Push()
+}
+
+// writeResult appends the open port to syn.scan.output, if any.
+func (s *SynScanner) writeResult(event SynScanEvent) {
+	s.writerLock.Lock()
+	defer s.writerLock.Unlock()
+
+	if s.writer != nil {
+		if err := s.writer.Write(event); err != nil {
+			log.Error("error while writing to %s: %s", s.output, err)
+		}
+	}
+}
+
+func (s *SynScanner) openWriter() error {
+	s.writerLock.Lock()
+	defer s.writerLock.Unlock()
 
-		var host *network.Endpoint
-		if ip.SrcIP.Equal(s.Session.Interface.IP) {
-			host = s.Session.Interface
-		} else if ip.SrcIP.Equal(s.Session.Gateway.IP) {
-			host = s.Session.Gateway
+	s.writer = nil
+	if s.output != "" {
+		var err error
+		if err, s.writer = newSynScanWriter(s.output, s.format); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SynScanner) closeWriter() {
+	s.writerLock.Lock()
+	defer s.writerLock.Unlock()
+
+	if s.writer != nil {
+		if err := s.writer.Close(); err != nil {
+			log.Error("error while closing %s: %s", s.output, err)
+		}
+		s.writer = nil
+	}
+}
+
+// onReply counts the reply and returns true if it has to be reported, only
+// the first reply of each probe is.
+func (s *SynScanner) onReply(address string, port int, open bool) bool {
+	s.probesLock.Lock()
+	defer s.probesLock.Unlock()
+
+	probe := synProbe{address, port}
+	state, found := s.probes[probe]
+	if !found {
+		// already answered or given up on
+		return false
+	}
+	delete(s.probes, probe)
+
+	if state.attempts > 1 {
+		s.counters.Retried++
+	}
+
+	if open {
+		s.counters.Open++
+	} else {
+		s.counters.Closed++
+	}
+	return true
+}
+
+// unanswered returns the tracked probes that didn't get any reply yet.
+func (s *SynScanner) unanswered() []synProbe {
+	s.probesLock.Lock()
+	defer s.probesLock.Unlock()
+
+	pending := make([]synProbe, 0, len(s.probes))
+	for probe := range s.probes {
+		pending = append(pending, probe)
+	}
+	return pending
+}
+
+// track starts keeping the state of a new probe, until it's answered or
+// the scan is over.
+func (s *SynScanner) track(probe synProbe, ip net.IP, mac net.HardwareAddr) {
+	s.probesLock.Lock()
+	defer s.probesLock.Unlock()
+
+	s.launched++
+	s.probes[probe] = &synProbeState{ip: ip, mac: mac}
+}
+
+// retry probes again a port that didn't answer yet.
+func (s *SynScanner) retry(probe synProbe) {
+	s.probesLock.Lock()
+	state, found := s.probes[probe]
+	s.probesLock.Unlock()
+
+	if found {
+		s.sendProbe(probe, state.ip, state.mac)
+	}
+}
+
+func (s *SynScanner) sendProbe(probe synProbe, ip net.IP, mac net.HardwareAddr) {
+	s.probesLock.Lock()
+	s.counters.Probes++
+	if state, found := s.probes[probe]; found {
+		state.attempts++
+	}
+	s.probesLock.Unlock()
+
+	for _, from := range s.sources() {
+		err, raw := packets.NewTCPSyn(from, s.Session.Interface.HW, ip, mac, synSourcePort, probe.port)
+		if err != nil {
+			log.Error("Error creating SYN packet: %s", err)
+			return
+		}
+
+		if err := s.Send(raw); err != nil {
+			log.Error("Error sending SYN packet: %s", err)
 		} else {
-			host = s.Session.Lan.GetByIp(from)
+			log.Debug("Sent %d bytes of SYN packet from %s to %s for port %d", len(raw), from, probe.address, probe.port)
 		}
+	}
+}
 
-		if host != nil {
-			ports := host.Meta.GetIntsWith("tcp-ports", port, true)
-			host.Meta.SetInts("tcp-ports", ports)
+// summary returns the scan counters, the probes still without a reply
+// are considered timed out and forgotten.
+func (s *SynScanner) summary() SynScanSummary {
+	s.probesLock.Lock()
+	defer s.probesLock.Unlock()
+
+	sum := s.counters
+	sum.Addresses = len(s.addresses)
+	sum.Ports = s.endPort - s.startPort + 1
+	sum.Retries = s.retries
+	sum.Filtered = s.launched - sum.Open - sum.Closed
+
+	s.probes = make(map[synProbe]*synProbeState)
+	return sum
+}
+
+// ports returns the list of ports to scan, shuffled if syn.scan.randomize is true.
+func (s *SynScanner) ports() []int {
+	nports := s.endPort - s.startPort + 1
+	ports := make([]int, nports)
+	for i := range ports {
+		ports[i] = s.startPort + i
+	}
+
+	if s.randomize {
+		for i, j := range rand.Perm(nports) {
+			ports[i], ports[j] = ports[j], ports[i]
 		}
+	}
 
-		NewSynScanEvent(from, host, port).Push()
+	return ports
+}
+
+// wait sleeps between two SYN packets when in stealth mode.
+func (s *SynScanner) wait() {
+	if !s.stealth {
+		return
+	}
+
+	pause := s.delay
+	if s.jitter > 0 {
+		pause += rand.Intn(s.jitter + 1)
 	}
+	time.Sleep(time.Duration(pause) * time.Millisecond)
+}
+
+func (s *SynScanner) warnStealth(nprobes int) {
+	// on average every packet waits delay + jitter/2
+	avg := time.Duration(s.delay)*time.Millisecond + time.Duration(s.jitter)*time.Millisecond/2
+	if avg <= 0 {
+		log.Warning("stealth mode enabled but both syn.scan.delay and syn.scan.jitter are 0.")
+		return
+	}
+
+	rate := float64(time.Second) / float64(avg)
+	eta := time.Duration(nprobes) * avg
+	log.Warning("stealth mode: sending ~%.2f packets per second, %d packets will take about %s.",
+		rate, nprobes, core.Bold(eta.String()))
 }
 
 func (s *SynScanner) synScan() error {
+	if err := s.openWriter(); err != nil {
+		return err
+	}
+
 	s.SetRunning(true, func() {
 		defer s.SetRunning(false, nil)
 
 		s.waitGroup.Add(1)
 		defer s.waitGroup.Done()
 
+		if s.output != "" {
+			log.Info("writing open ports to %s (%s) ...", core.Bold(s.output), s.format)
+		}
+
 		naddrs := len(s.addresses)
 		plural := "es"
 		if naddrs == 1 {
@@ -170,42 +496,79 @@ func (s *SynScanner) synScan() error {
 			log.Info("SYN scanning %d address%s on port %d ...", naddrs, plural, s.startPort)
 		}
 
-		// set the collector
+		ports := s.ports()
+		if s.stealth {
+			s.warnStealth(naddrs * len(ports))
+		}
+		if len(s.decoys) > 0 {
+			s.warnDecoys()
+		}
+
+		s.probesLock.Lock()
+		s.probes = make(map[synProbe]*synProbeState)
+		s.counters = SynScanSummary{}
+		s.launched = 0
+		s.probesLock.Unlock()
+
+		// set the collector, the output is only closed once it's removed and
+		// no late reply can be written anymore
 		s.Session.Queue.OnPacket(s.onPacket)
-		defer s.Session.Queue.OnPacket(nil)
+		defer func() {
+			s.Session.Queue.OnPacket(nil)
+			s.closeWriter()
+		}()
 
 		// start sending SYN packets and wait
 		for _, address := range s.addresses {
 			if !s.Running() {
 				break
 			}
-			mac, err := findMAC(s.Session, address, true)
+			mac, err := findMAC(&s.SessionModule, address, true)
 			if err != nil {
 				log.Debug("Could not get MAC for %s: %s", address.String(), err)
 				continue
 			}
 
-			for dstPort := s.startPort; dstPort < s.endPort+1; dstPort++ {
-				if !s.Running() {
+			for _, dstPort := range ports {
+				if !s.WaitIfPaused() {
 					break
 				}
 
-				err, raw := packets.NewTCPSyn(s.Session.Interface.IP, s.Session.Interface.HW, address, mac, synSourcePort, dstPort)
-				if err != nil {
-					log.Error("Error creating SYN packet: %s", err)
-					continue
-				}
+				s.wait()
+
+				probe := synProbe{address.String(), dstPort}
+				s.track(probe, address, mac)
+				s.sendProbe(probe, address, mac)
+			}
+		}
 
-				if err := s.Session.Queue.Send(raw); err != nil {
-					log.Error("Error sending SYN packet: %s", err)
-				} else {
-					log.Debug("Sent %d bytes of SYN packet to %s for port %d", len(raw), address.String(), dstPort)
+		// probe again whatever didn't answer
+		for retry := 1; retry <= s.retries && s.Running(); retry++ {
+			time.Sleep(time.Duration(s.retryWait) * time.Millisecond)
+
+			pending := s.unanswered()
+			if len(pending) == 0 {
+				break
+			}
+
+			log.Debug("probing again %d ports without reply (%d/%d) ...", len(pending), retry, s.retries)
+			for _, probe := range pending {
+				if !s.WaitIfPaused() {
+					break
 				}
+
+				s.wait()
+				s.retry(probe)
 			}
 		}
 
 		nports := s.endPort - s.startPort + 1
 		time.Sleep(time.Duration(nports*500) * time.Millisecond)
+
+		sum := s.summary()
+		log.Info("SYN scan done: %d open, %d closed and %d filtered ports, %d answered only after a retry (%d probes sent).",
+			sum.Open, sum.Closed, sum.Filtered, sum.Retried, sum.Probes)
+		sum.Push()
 	})
 
 	return nil
@@ -0,0 +1,59 @@
+package modules
+
+import (
+	"fmt"
+	"math/rand"
+	"net"
+	"strings"
+
+	"github.com/bettercap/bettercap/core"
+	"github.com/bettercap/bettercap/log"
+)
+
+// parseDecoys parses the syn.scan.decoys addresses, our own address can't
+// be one of them since it's always mixed in.
+func parseDecoys(list []string, self net.IP) (error, []net.IP) {
+	decoys := make([]net.IP, 0)
+	seen := make(map[string]bool)
+
+	for _, entry := range list {
+		ip := net.ParseIP(entry).To4()
+		if ip == nil {
+			return fmt.Errorf("'%s' is not a valid IPv4 decoy address", entry), nil
+		} else if ip.Equal(self) {
+			return fmt.Errorf("%s is our own address, it doesn't need to be in syn.scan.decoys", entry), nil
+		} else if !ip.IsGlobalUnicast() {
+			return fmt.Errorf("%s can't be used as a decoy address", entry), nil
+		} else if !seen[ip.String()] {
+			seen[ip.String()] = true
+			decoys = append(decoys, ip)
+		}
+	}
+
+	return nil, decoys
+}
+
+// sources returns the source addresses of the SYN packets of a probe, the
+// decoys and our own address at a random position among them.
+func (s *SynScanner) sources() []net.IP {
+	sources := make([]net.IP, len(s.decoys)+1)
+	copy(sources, s.decoys)
+	sources[len(s.decoys)] = s.Session.Interface.IP
+
+	for i := len(sources) - 1; i > 0; i-- {
+		j := rand.Intn(i + 1)
+		sources[i], sources[j] = sources[j], sources[i]
+	}
+	return sources
+}
+
+func (s *SynScanner) warnDecoys() {
+	addrs := make([]string, len(s.decoys))
+	for i, ip := range s.decoys {
+		addrs[i] = ip.String()
+	}
+
+	log.Warning("every SYN packet will also be sent from %d decoy addresses (%s), they only work if the network between here and the targets doesn't drop spoofed sources.",
+		len(s.decoys), core.Bold(strings.Join(addrs, ", ")))
+	log.Warning("decoy packets still carry the MAC address of %s, hosts on the same LAN can tell them apart.", s.Session.Interface.Name())
+}
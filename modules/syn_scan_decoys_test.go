@@ -0,0 +1,65 @@
+package modules
+
+import (
+	"net"
+	"testing"
+
+	"github.com/bettercap/bettercap/network"
+	"github.com/bettercap/bettercap/session"
+)
+
+func TestParseDecoys(t *testing.T) {
+	self := net.ParseIP("192.168.1.10").To4()
+
+	err, decoys := parseDecoys([]string{"10.0.0.1", "10.0.0.2", "10.0.0.1"}, self)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(decoys) != 2 || !decoys[0].Equal(net.ParseIP("10.0.0.1")) || !decoys[1].Equal(net.ParseIP("10.0.0.2")) {
+		t.Fatalf("unexpected decoys %v", decoys)
+	}
+
+	for _, bad := range []string{"foo", "::1", "192.168.1.10", "127.0.0.1", "0.0.0.0", "255.255.255.255"} {
+		if err, _ := parseDecoys([]string{bad}, self); err == nil {
+			t.Fatalf("expected an error for decoy '%s'", bad)
+		}
+	}
+}
+
+func TestSynScannerSources(t *testing.T) {
+	self := net.ParseIP("192.168.1.10").To4()
+	_, decoys := parseDecoys([]string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}, self)
+
+	s := &SynScanner{
+		SessionModule: session.SessionModule{
+			Session: &session.Session{Interface: &network.Endpoint{IP: self}},
+		},
+		decoys: decoys,
+	}
+
+	for i := 0; i < 16; i++ {
+		sources := s.sources()
+		if len(sources) != len(decoys)+1 {
+			t.Fatalf("expected %d sources, got %d", len(decoys)+1, len(sources))
+		}
+
+		seen := make(map[string]bool)
+		for _, ip := range sources {
+			seen[ip.String()] = true
+		}
+		for _, ip := range append(decoys, self) {
+			if !seen[ip.String()] {
+				t.Fatalf("expected %s to be one of the sources %v", ip, sources)
+			}
+		}
+	}
+
+	// the order of the decoys is never changed
+	if decoys[0].String() != "10.0.0.1" || decoys[2].String() != "10.0.0.3" {
+		t.Fatalf("sources() changed the decoys list: %v", decoys)
+	}
+
+	s.decoys = nil
+	if sources := s.sources(); len(sources) != 1 || !sources[0].Equal(self) {
+		t.Fatalf("expected only our own address without decoys, got %v", sources)
+	}
+}
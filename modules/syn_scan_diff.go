@@ -0,0 +1,244 @@
+package modules
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bettercap/bettercap/core"
+)
+
+// SynScanHostDiff lists the ports of a host which have been opened or
+// closed between two scans, Status is "new" or "gone" if the host only
+// appears in one of them.
+type SynScanHostDiff struct {
+	Address  string `json:"address"`
+	MAC      string `json:"mac"`
+	Hostname string `json:"hostname"`
+	Status   string `json:"status"`
+	Opened   []int  `json:"opened"`
+	Closed   []int  `json:"closed"`
+}
+
+// SynScanDiff is the payload of syn.scan.diff events.
+type SynScanDiff struct {
+	Old   string            `json:"old"`
+	New   string            `json:"new"`
+	Hosts []SynScanHostDiff `json:"hosts"`
+}
+
+type synScanHost struct {
+	MAC      string
+	Hostname string
+	Ports    map[int]bool
+}
+
+func addSynScanResult(hosts map[string]*synScanHost, r SynScanResult) {
+	if r.Address == "" || (r.State != "" && r.State != "open") {
+		return
+	}
+
+	h, found := hosts[r.Address]
+	if !found {
+		h = &synScanHost{Ports: make(map[int]bool)}
+		hosts[r.Address] = h
+	}
+	if r.MAC != "" {
+		h.MAC = r.MAC
+	}
+	if r.Hostname != "" {
+		h.Hostname = r.Hostname
+	}
+	h.Ports[r.Port] = true
+}
+
+// loadSynScanResults reads a syn.scan.output file in either format, the
+// json one having an object per line.
+func loadSynScanResults(fileName string) (error, map[string]*synScanHost) {
+	fileName, err := core.ExpandPath(fileName)
+	if err != nil {
+		return err, nil
+	}
+
+	raw, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return err, nil
+	}
+
+	hosts := make(map[string]*synScanHost)
+	if trimmed := bytes.TrimSpace(raw); len(trimmed) == 0 {
+		return nil, hosts
+	} else if trimmed[0] == '{' {
+		scanner := bufio.NewScanner(bytes.NewReader(trimmed))
+		for line := 1; scanner.Scan(); line++ {
+			if text := core.Trim(scanner.Text()); text != "" {
+				var r SynScanResult
+				if err := json.Unmarshal([]byte(text), &r); err != nil {
+					return fmt.Errorf("%s:%d: %s", fileName, line, err), nil
+				}
+				addSynScanResult(hosts, r)
+			}
+		}
+		return scanner.Err(), hosts
+	}
+
+	reader := csv.NewReader(bytes.NewReader(raw))
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("%s: %s", fileName, err), nil
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(core.Trim(name))] = i
+	}
+	if _, found := columns["address"]; !found {
+		return fmt.Errorf("%s is neither a json nor a csv syn.scan.output file", fileName), nil
+	} else if _, found = columns["port"]; !found {
+		return fmt.Errorf("%s is neither a json nor a csv syn.scan.output file", fileName), nil
+	}
+
+	field := func(record []string, name string) string {
+		if i, found := columns[name]; found && i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("%s: %s", fileName, err), nil
+		}
+
+		port, err := strconv.Atoi(field(record, "port"))
+		if err != nil {
+			return fmt.Errorf("%s: invalid port '%s'", fileName, field(record, "port")), nil
+		}
+
+		addSynScanResult(hosts, SynScanResult{
+			Address:  field(record, "address"),
+			MAC:      field(record, "mac"),
+			Hostname: field(record, "hostname"),
+			Port:     port,
+			State:    field(record, "state"),
+		})
+	}
+
+	return nil, hosts
+}
+
+func portsMissingFrom(ports map[int]bool, other map[int]bool) []int {
+	missing := make([]int, 0)
+	for port := range ports {
+		if !other[port] {
+			missing = append(missing, port)
+		}
+	}
+	sort.Ints(missing)
+	return missing
+}
+
+func diffSynScans(oldHosts, newHosts map[string]*synScanHost) []SynScanHostDiff {
+	addresses := make([]string, 0)
+	for address := range oldHosts {
+		addresses = append(addresses, address)
+	}
+	for address := range newHosts {
+		if _, found := oldHosts[address]; !found {
+			addresses = append(addresses, address)
+		}
+	}
+	sort.Strings(addresses)
+
+	none := make(map[int]bool)
+	diffs := make([]SynScanHostDiff, 0)
+	for _, address := range addresses {
+		before, inOld := oldHosts[address]
+		after, inNew := newHosts[address]
+
+		diff := SynScanHostDiff{Address: address, Status: "changed"}
+		if !inOld {
+			before = &synScanHost{Ports: none}
+			diff.Status = "new"
+		} else if !inNew {
+			after = &synScanHost{MAC: before.MAC, Hostname: before.Hostname, Ports: none}
+			diff.Status = "gone"
+		}
+
+		diff.MAC = after.MAC
+		diff.Hostname = after.Hostname
+		diff.Opened = portsMissingFrom(after.Ports, before.Ports)
+		diff.Closed = portsMissingFrom(before.Ports, after.Ports)
+
+		if len(diff.Opened) > 0 || len(diff.Closed) > 0 {
+			diffs = append(diffs, diff)
+		}
+	}
+
+	return diffs
+}
+
+func joinPorts(ports []int, colorize func(string) string) string {
+	if len(ports) == 0 {
+		return ""
+	}
+	list := make([]string, len(ports))
+	for i, port := range ports {
+		list[i] = strconv.Itoa(port)
+	}
+	return colorize(strings.Join(list, ", "))
+}
+
+func (s *SynScanner) scanDiff(oldFile string, newFile string) error {
+	err, oldHosts := loadSynScanResults(oldFile)
+	if err != nil {
+		return err
+	}
+
+	err, newHosts := loadSynScanResults(newFile)
+	if err != nil {
+		return err
+	}
+
+	diff := SynScanDiff{
+		Old:   oldFile,
+		New:   newFile,
+		Hosts: diffSynScans(oldHosts, newHosts),
+	}
+
+	if len(diff.Hosts) == 0 {
+		fmt.Println()
+		fmt.Printf("No open ports changed between %s and %s.\n", oldFile, newFile)
+		fmt.Println()
+	} else {
+		rows := make([][]string, 0)
+		for _, h := range diff.Hosts {
+			rows = append(rows, []string{
+				h.Address,
+				core.Dim(h.MAC),
+				h.Hostname,
+				h.Status,
+				joinPorts(h.Opened, core.Red),
+				joinPorts(h.Closed, core.Green),
+			})
+		}
+
+		fmt.Println()
+		core.AsTable(os.Stdout, []string{"Address", "MAC", "Hostname", "Status", "Opened", "Closed"}, rows)
+		fmt.Println()
+	}
+
+	s.Session.Events.Add("syn.scan.diff", diff)
+	return nil
+}
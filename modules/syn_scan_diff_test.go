@@ -0,0 +1,85 @@
+package modules
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeSynScanFile(t *testing.T, dir string, name string, data string) string {
+	fileName := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(fileName, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return fileName
+}
+
+func TestLoadSynScanResults(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bettercap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := []string{
+		writeSynScanFile(t, dir, "scan.json", `{"address":"10.0.0.1","mac":"aa:bb:cc:dd:ee:ff","hostname":"nas","port":22,"protocol":"tcp","state":"open"}
+{"address":"10.0.0.1","port":80,"protocol":"tcp","state":"open"}
+
+{"address":"10.0.0.2","port":443,"protocol":"tcp","state":"closed"}
+`),
+		writeSynScanFile(t, dir, "scan.csv", `address,mac,hostname,port,protocol,state
+10.0.0.1,aa:bb:cc:dd:ee:ff,nas,22,tcp,open
+10.0.0.1,,,80,tcp,open
+10.0.0.2,,,443,tcp,closed
+`),
+	}
+
+	for _, fileName := range files {
+		err, hosts := loadSynScanResults(fileName)
+		if err != nil {
+			t.Fatalf("%s: %s", fileName, err)
+		} else if len(hosts) != 1 {
+			t.Fatalf("%s: expected 1 host, got %d", fileName, len(hosts))
+		}
+
+		h := hosts["10.0.0.1"]
+		if h == nil || h.MAC != "aa:bb:cc:dd:ee:ff" || h.Hostname != "nas" {
+			t.Fatalf("%s: unexpected host %+v", fileName, h)
+		} else if !reflect.DeepEqual(h.Ports, map[int]bool{22: true, 80: true}) {
+			t.Fatalf("%s: unexpected ports %v", fileName, h.Ports)
+		}
+	}
+
+	if err, _ := loadSynScanResults(writeSynScanFile(t, dir, "bad.csv", "foo,bar\n1,2\n")); err == nil {
+		t.Fatal("expected an error for a csv file without address and port columns")
+	} else if err, _ := loadSynScanResults(writeSynScanFile(t, dir, "bad.json", "{\"address\":\n")); err == nil {
+		t.Fatal("expected an error for an invalid json line")
+	} else if err, hosts := loadSynScanResults(writeSynScanFile(t, dir, "empty.json", "\n")); err != nil || len(hosts) != 0 {
+		t.Fatalf("expected no hosts and no error for an empty file, got %v and %v", hosts, err)
+	}
+}
+
+func TestDiffSynScans(t *testing.T) {
+	oldHosts := map[string]*synScanHost{
+		"10.0.0.1": {MAC: "aa:aa:aa:aa:aa:aa", Ports: map[int]bool{22: true, 80: true}},
+		"10.0.0.2": {MAC: "bb:bb:bb:bb:bb:bb", Ports: map[int]bool{443: true}},
+		"10.0.0.3": {Ports: map[int]bool{53: true}},
+	}
+	newHosts := map[string]*synScanHost{
+		"10.0.0.1": {MAC: "aa:aa:aa:aa:aa:aa", Ports: map[int]bool{22: true, 8080: true}},
+		"10.0.0.3": {Ports: map[int]bool{53: true}},
+		"10.0.0.4": {Hostname: "printer", Ports: map[int]bool{9100: true, 631: true}},
+	}
+
+	exp := []SynScanHostDiff{
+		{Address: "10.0.0.1", MAC: "aa:aa:aa:aa:aa:aa", Status: "changed", Opened: []int{8080}, Closed: []int{80}},
+		{Address: "10.0.0.2", MAC: "bb:bb:bb:bb:bb:bb", Status: "gone", Opened: []int{}, Closed: []int{443}},
+		{Address: "10.0.0.4", Hostname: "printer", Status: "new", Opened: []int{631, 9100}, Closed: []int{}},
+	}
+
+	if got := diffSynScans(oldHosts, newHosts); !reflect.DeepEqual(got, exp) {
+		t.Fatalf("expected %+v, got %+v", exp, got)
+	}
+}
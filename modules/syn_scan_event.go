@@ -23,3 +23,18 @@ func (e SynScanEvent) Push() {
 	session.I.Events.Add("syn.scan", e)
 	session.I.Refresh()
 }
+
+type SynScanSummary struct {
+	Addresses int `json:"addresses"`
+	Ports     int `json:"ports"`
+	Retries   int `json:"retries"`
+	Probes    int `json:"probes"`
+	Open      int `json:"open"`
+	Closed    int `json:"closed"`
+	Filtered  int `json:"filtered"`
+	Retried   int `json:"retried"`
+}
+
+func (s SynScanSummary) Push() {
+	session.I.Events.Add("syn.scan.summary", s)
+}
@@ -0,0 +1,194 @@
+package modules
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/bettercap/bettercap/core"
+	"github.com/bettercap/bettercap/log"
+	"github.com/bettercap/bettercap/network"
+)
+
+// the subset of the nmap -oX output we care about
+type nmapRun struct {
+	Hosts []nmapHost `xml:"host"`
+}
+
+type nmapHost struct {
+	Status struct {
+		State string `xml:"state,attr"`
+	} `xml:"status"`
+	Addresses []struct {
+		Addr     string `xml:"addr,attr"`
+		AddrType string `xml:"addrtype,attr"`
+	} `xml:"address"`
+	Hostnames []struct {
+		Name string `xml:"name,attr"`
+	} `xml:"hostnames>hostname"`
+	Ports     []nmapPort `xml:"ports>port"`
+	OSMatches []struct {
+		Name     string `xml:"name,attr"`
+		Accuracy int    `xml:"accuracy,attr"`
+	} `xml:"os>osmatch"`
+}
+
+type nmapPort struct {
+	Protocol string `xml:"protocol,attr"`
+	Port     int    `xml:"portid,attr"`
+	State    struct {
+		State string `xml:"state,attr"`
+	} `xml:"state"`
+	Service struct {
+		Name    string `xml:"name,attr"`
+		Product string `xml:"product,attr"`
+		Version string `xml:"version,attr"`
+	} `xml:"service"`
+}
+
+func (h nmapHost) address(kind string) string {
+	for _, a := range h.Addresses {
+		if a.AddrType == kind {
+			return a.Addr
+		}
+	}
+	return ""
+}
+
+func (p nmapPort) service() string {
+	parts := make([]string, 0, 3)
+	for _, part := range []string{p.Service.Name, p.Service.Product, p.Service.Version} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// hostFor returns the endpoint with the given address, if we know about it.
+func (s *SynScanner) hostFor(address string) *network.Endpoint {
+	if address == s.Session.Interface.IpAddress {
+		return s.Session.Interface
+	} else if address == s.Session.Gateway.IpAddress {
+		return s.Session.Gateway
+	}
+	return s.Session.Lan.GetByIp(address)
+}
+
+func (h nmapHost) openPorts() []nmapPort {
+	open := make([]nmapPort, 0)
+	for _, port := range h.Ports {
+		if port.State.State == "open" && (port.Protocol == "tcp" || port.Protocol == "udp") {
+			open = append(open, port)
+		}
+	}
+	return open
+}
+
+// merge copies the open ports, the services, the hostname and the best OS
+// guess of the nmap results to the endpoint, returns true if the endpoint
+// didn't have an OS guess before.
+func (h nmapHost) merge(host *network.Endpoint) bool {
+	for _, port := range h.openPorts() {
+		key := port.Protocol + "-ports"
+		host.Meta.SetInts(key, host.Meta.GetIntsWith(key, port.Port, true))
+		if service := port.service(); service != "" {
+			host.Meta.Set("nmap:"+port.Protocol+":"+strconv.Itoa(port.Port), service)
+		}
+	}
+
+	if host.Hostname == "" && len(h.Hostnames) > 0 {
+		host.Hostname = h.Hostnames[0].Name
+	}
+
+	// nmap sorts the matches by accuracy
+	if len(h.OSMatches) > 0 {
+		match := h.OSMatches[0]
+		if host.OSGuess == nil {
+			host.OSGuess = &network.OSGuess{Name: match.Name, Confidence: match.Accuracy}
+			return true
+		} else if match.Accuracy > host.OSGuess.Confidence {
+			host.OSGuess = &network.OSGuess{Name: match.Name, Confidence: match.Accuracy}
+		}
+	}
+
+	return false
+}
+
+func parseNmapReport(raw []byte) ([]nmapHost, error) {
+	var run nmapRun
+	if err := xml.Unmarshal(raw, &run); err != nil {
+		return nil, err
+	}
+
+	hosts := make([]nmapHost, 0, len(run.Hosts))
+	for _, h := range run.Hosts {
+		if h.Status.State == "up" && h.address("ipv4") != "" {
+			hosts = append(hosts, h)
+		}
+	}
+	return hosts, nil
+}
+
+// importHost merges the nmap results of a single host with the endpoint,
+// which is added to the LAN if needed, and returns how many open ports were
+// found.
+func (s *SynScanner) importHost(h nmapHost) int {
+	address := h.address("ipv4")
+	host := s.hostFor(address)
+	if host == nil {
+		// nmap only reports the hardware address when running as root
+		mac := h.address("mac")
+		if mac == "" {
+			mac, _ = network.ArpLookup(s.Session.Interface.Name(), address, false)
+		}
+		if mac != "" && s.Session.Lan.AddIfNew(address, mac) == nil {
+			host, _ = s.Session.Lan.Get(network.NormalizeMac(mac))
+		}
+	}
+
+	open := h.openPorts()
+	if host == nil {
+		log.Debug("%s is not on the LAN, its %d open ports are only reported as events", address, len(open))
+	} else if h.merge(host) {
+		s.Session.Events.Add("net.recon.os", host)
+	}
+
+	for _, port := range open {
+		if port.Protocol == "tcp" {
+			NewSynScanEvent(address, host, port.Port).Push()
+		}
+	}
+
+	return len(open)
+}
+
+// Import seeds the session with the hosts, open ports, services and OS
+// guesses of the nmap XML report in fileName.
+func (s *SynScanner) Import(fileName string) error {
+	fileName, err := core.ExpandPath(fileName)
+	if err != nil {
+		return err
+	}
+
+	raw, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return err
+	}
+
+	hosts, err := parseNmapReport(raw)
+	if err != nil {
+		return fmt.Errorf("can't parse %s: %s", fileName, err)
+	}
+
+	ports := 0
+	for _, h := range hosts {
+		ports += s.importHost(h)
+	}
+
+	log.Info("imported %d hosts and %d open ports from %s.", len(hosts), ports, fileName)
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package modules
+
+import (
+	"testing"
+
+	"github.com/bettercap/bettercap/network"
+)
+
+const nmapReport = `<?xml version="1.0" encoding="UTF-8"?>
+<nmaprun scanner="nmap">
+  <host>
+    <status state="up"/>
+    <address addr="192.168.1.10" addrtype="ipv4"/>
+    <address addr="AA:BB:CC:DD:EE:FF" addrtype="mac"/>
+    <hostnames><hostname name="nas.lan"/></hostnames>
+    <ports>
+      <port protocol="tcp" portid="22">
+        <state state="open"/>
+        <service name="ssh" product="OpenSSH" version="7.4"/>
+      </port>
+      <port protocol="tcp" portid="23"><state state="closed"/></port>
+      <port protocol="udp" portid="53">
+        <state state="open"/>
+        <service name="domain"/>
+      </port>
+      <port protocol="sctp" portid="80"><state state="open"/></port>
+    </ports>
+    <os>
+      <osmatch name="Linux 4.X" accuracy="95"/>
+      <osmatch name="Linux 3.X" accuracy="90"/>
+    </os>
+  </host>
+  <host>
+    <status state="up"/>
+    <address addr="10.0.0.1" addrtype="ipv4"/>
+    <ports>
+      <port protocol="tcp" portid="443"><state state="open"/></port>
+    </ports>
+  </host>
+  <host>
+    <status state="down"/>
+    <address addr="192.168.1.11" addrtype="ipv4"/>
+  </host>
+  <host>
+    <status state="up"/>
+    <address addr="fe80::1" addrtype="ipv6"/>
+  </host>
+</nmaprun>`
+
+func TestParseNmapReport(t *testing.T) {
+	hosts, err := parseNmapReport([]byte(nmapReport))
+	if err != nil {
+		t.Fatal(err)
+	} else if len(hosts) != 2 {
+		t.Fatalf("expected 2 hosts, got %d", len(hosts))
+	}
+
+	h := hosts[0]
+	if ip := h.address("ipv4"); ip != "192.168.1.10" {
+		t.Fatalf("unexpected address %s", ip)
+	} else if mac := h.address("mac"); mac != "AA:BB:CC:DD:EE:FF" {
+		t.Fatalf("unexpected hardware address %s", mac)
+	}
+
+	// the routed host has no hardware address, its ports must not be lost
+	if mac := hosts[1].address("mac"); mac != "" {
+		t.Fatalf("unexpected hardware address %s", mac)
+	} else if open := hosts[1].openPorts(); len(open) != 1 || open[0].Port != 443 {
+		t.Fatalf("unexpected open ports %v", open)
+	}
+
+	open := h.openPorts()
+	if len(open) != 2 {
+		t.Fatalf("expected 2 open ports, got %v", open)
+	} else if service := open[0].service(); service != "ssh OpenSSH 7.4" {
+		t.Fatalf("unexpected service '%s'", service)
+	} else if service := open[1].service(); service != "domain" {
+		t.Fatalf("unexpected service '%s'", service)
+	}
+
+	if _, err := parseNmapReport([]byte("<nmaprun>")); err == nil {
+		t.Fatal("expected an error for a truncated report")
+	}
+}
+
+func TestNmapHostMerge(t *testing.T) {
+	hosts, err := parseNmapReport([]byte(nmapReport))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	host := network.NewEndpointNoResolve("192.168.1.10", "aa:bb:cc:dd:ee:ff", "", 0)
+	host.Meta.Set("tcp-ports", "80")
+
+	if !hosts[0].merge(host) {
+		t.Fatal("expected a new OS guess")
+	} else if host.OSGuess.Name != "Linux 4.X" || host.OSGuess.Confidence != 95 {
+		t.Fatalf("unexpected OS guess %+v", host.OSGuess)
+	} else if host.Hostname != "nas.lan" {
+		t.Fatalf("unexpected hostname '%s'", host.Hostname)
+	}
+
+	if ports := host.Meta.Get("tcp-ports"); ports != "22,80" {
+		t.Fatalf("unexpected tcp ports %v", ports)
+	} else if ports := host.Meta.Get("udp-ports"); ports != "53" {
+		t.Fatalf("unexpected udp ports %v", ports)
+	} else if service := host.Meta.Get("nmap:tcp:22"); service != "ssh OpenSSH 7.4" {
+		t.Fatalf("unexpected service %v", service)
+	}
+
+	// a better guess replaces the previous one but is not a new one
+	host.OSGuess = &network.OSGuess{Name: "Linux", Confidence: 50}
+	if hosts[0].merge(host) {
+		t.Fatal("expected the OS guess to be updated, not added")
+	} else if host.OSGuess.Confidence != 95 {
+		t.Fatalf("expected the more accurate guess, got %+v", host.OSGuess)
+	}
+}
@@ -0,0 +1,104 @@
+package modules
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+)
+
+type SynScanResult struct {
+	Address  string `json:"address"`
+	MAC      string `json:"mac"`
+	Hostname string `json:"hostname"`
+	Port     int    `json:"port"`
+	Protocol string `json:"protocol"`
+	State    string `json:"state"`
+}
+
+var synScanCSVHeader = []string{"address", "mac", "hostname", "port", "protocol", "state"}
+
+// synScanWriter appends every open port to the output file as soon as it's
+// found, so that an interrupted scan still leaves partial results.
+type synScanWriter struct {
+	sync.Mutex
+	format string
+	file   *os.File
+	csv    *csv.Writer
+	seen   map[string]bool
+}
+
+func newSynScanWriter(fileName string, format string) (error, *synScanWriter) {
+	file, err := os.OpenFile(fileName, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err, nil
+	}
+
+	w := &synScanWriter{
+		format: format,
+		file:   file,
+		seen:   make(map[string]bool),
+	}
+
+	if format == "csv" {
+		w.csv = csv.NewWriter(file)
+		if stat, err := file.Stat(); err == nil && stat.Size() == 0 {
+			w.csv.Write(synScanCSVHeader)
+			w.csv.Flush()
+		}
+	}
+
+	return nil, w
+}
+
+func (w *synScanWriter) Write(e SynScanEvent) error {
+	w.Lock()
+	defer w.Unlock()
+
+	// the same port can be reported more than once by SYN+ACK retransmissions
+	key := fmt.Sprintf("%s:%d", e.Address, e.Port)
+	if w.seen[key] {
+		return nil
+	}
+	w.seen[key] = true
+
+	result := SynScanResult{
+		Address:  e.Address,
+		Port:     e.Port,
+		Protocol: "tcp",
+		State:    "open",
+	}
+	if e.Host != nil {
+		result.MAC = e.Host.HwAddress
+		result.Hostname = e.Host.Hostname
+	}
+
+	if w.format == "csv" {
+		w.csv.Write([]string{
+			result.Address,
+			result.MAC,
+			result.Hostname,
+			strconv.Itoa(result.Port),
+			result.Protocol,
+			result.State,
+		})
+		w.csv.Flush()
+		return w.csv.Error()
+	}
+
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	_, err = w.file.Write(append(raw, '\n'))
+	return err
+}
+
+func (w *synScanWriter) Close() error {
+	w.Lock()
+	defer w.Unlock()
+
+	return w.file.Close()
+}
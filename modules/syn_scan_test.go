@@ -0,0 +1,48 @@
+package modules
+
+import (
+	"net"
+	"sync"
+	"testing"
+)
+
+func TestSynScannerReplies(t *testing.T) {
+	for _, retries := range []int{0, 2} {
+		s := &SynScanner{
+			addresses:  []net.IP{net.ParseIP("192.168.1.10")},
+			startPort:  21,
+			endPort:    23,
+			retries:    retries,
+			probes:     make(map[synProbe]*synProbeState),
+			probesLock: &sync.Mutex{},
+		}
+
+		for port := 21; port <= 23; port++ {
+			s.track(synProbe{"192.168.1.10", port}, nil, nil)
+		}
+
+		if !s.onReply("192.168.1.10", 21, true) {
+			t.Fatalf("retries=%d: expected the first reply to be reported", retries)
+		} else if s.onReply("192.168.1.10", 21, true) {
+			t.Fatalf("retries=%d: expected a duplicate reply not to be reported", retries)
+		} else if !s.onReply("192.168.1.10", 22, false) {
+			t.Fatalf("retries=%d: expected the RST to be counted", retries)
+		} else if s.onReply("192.168.1.10", 22, false) || s.onReply("192.168.1.10", 80, true) {
+			t.Fatalf("retries=%d: expected only the probed ports to be counted", retries)
+		}
+
+		if pending := s.unanswered(); len(pending) != 1 || pending[0].port != 23 {
+			t.Fatalf("retries=%d: unexpected pending probes %v", retries, pending)
+		}
+
+		sum := s.summary()
+		if sum.Open != 1 || sum.Closed != 1 || sum.Filtered != 1 || sum.Ports != 3 || sum.Addresses != 1 {
+			t.Fatalf("retries=%d: unexpected summary %+v", retries, sum)
+		}
+
+		// late replies once the scan is over
+		if s.onReply("192.168.1.10", 23, true) {
+			t.Fatalf("retries=%d: expected a reply after the summary not to be reported", retries)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package modules
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/bettercap/bettercap/session"
+)
+
+const trackPollPeriod = 250 * time.Millisecond
+
+// TrackEvent is the payload of track.hot and track.cold events.
+type TrackEvent struct {
+	Module    string  `json:"module"`
+	Address   string  `json:"address"`
+	RSSI      float64 `json:"rssi"`
+	Threshold int     `json:"threshold"`
+}
+
+// trackSampler returns the last RSSI reading of the tracked device and when
+// it was taken, found is false if the device is not (or no longer) visible.
+type trackSampler func() (rssi int, seen time.Time, found bool)
+
+// rssiTracker follows the signal of a single device, the readings are
+// smoothed with a moving average so that the device doesn't flap between
+// hot and cold when its RSSI hovers around the threshold.
+type rssiTracker struct {
+	module    string
+	address   string
+	threshold int
+	bell      bool
+	window    []int
+	next      int
+	filled    bool
+	lastSeen  time.Time
+	hot       bool
+	quit      chan bool
+}
+
+func newRSSITracker(module string, address string, threshold int, window int, bell bool) *rssiTracker {
+	if window < 1 {
+		window = 1
+	}
+	return &rssiTracker{
+		module:    module,
+		address:   address,
+		threshold: threshold,
+		bell:      bell,
+		window:    make([]int, window),
+		quit:      make(chan bool),
+	}
+}
+
+// add stores a reading and returns the current average.
+func (t *rssiTracker) add(rssi int) float64 {
+	t.window[t.next] = rssi
+	t.next = (t.next + 1) % len(t.window)
+	if t.next == 0 {
+		t.filled = true
+	}
+
+	size := t.next
+	if t.filled {
+		size = len(t.window)
+	}
+
+	sum := 0
+	for _, v := range t.window[:size] {
+		sum += v
+	}
+	return float64(sum) / float64(size)
+}
+
+func (t *rssiTracker) emit(s *session.Session, tag string, avg float64) {
+	if t.bell {
+		fmt.Fprint(os.Stdout, "\a")
+	}
+
+	s.Events.Add(tag, TrackEvent{
+		Module:    t.module,
+		Address:   t.address,
+		RSSI:      avg,
+		Threshold: t.threshold,
+	})
+}
+
+func (t *rssiTracker) update(s *session.Session, rssi int, seen time.Time) {
+	// only count new readings, the same one would be polled many times
+	if !seen.After(t.lastSeen) {
+		return
+	}
+	t.lastSeen = seen
+
+	avg := t.add(rssi)
+	if !t.hot && avg >= float64(t.threshold) {
+		t.hot = true
+		t.emit(s, "track.hot", avg)
+	} else if t.hot && avg < float64(t.threshold) {
+		t.hot = false
+		t.emit(s, "track.cold", avg)
+	}
+}
+
+func (t *rssiTracker) Run(s *session.Session, sample trackSampler) {
+	ticker := time.NewTicker(trackPollPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.quit:
+			return
+		case <-ticker.C:
+			if rssi, seen, found := sample(); found {
+				t.update(s, rssi, seen)
+			}
+		}
+	}
+}
+
+func (t *rssiTracker) Stop() {
+	close(t.quit)
+}
+
+func addTrackParams(m *session.SessionModule, prefix string) {
+	m.AddParam(session.NewIntParameter(prefix+".track.threshold",
+		"-50",
+		"RSSI in dBm above which the device tracked with "+prefix+".track is considered hot."))
+
+	m.AddParam(session.NewIntParameter(prefix+".track.window",
+		"5",
+		"Number of RSSI readings of the tracked device to average before comparing them with "+prefix+".track.threshold."))
+
+	m.AddParam(session.NewBoolParameter(prefix+".track.bell",
+		"false",
+		"If true, ring the terminal bell when the tracked device gets hot or cold."))
+}
+
+// newTracker creates a tracker for address with the prefix.track.*
+// parameters of the module.
+func newTracker(m *session.SessionModule, prefix string, address string) (error, *rssiTracker) {
+	var err error
+	var threshold, window int
+	var bell bool
+
+	if err, threshold = m.IntParam(prefix + ".track.threshold"); err != nil {
+		return err, nil
+	} else if err, window = m.IntParam(prefix + ".track.window"); err != nil {
+		return err, nil
+	} else if err, bell = m.BoolParam(prefix + ".track.bell"); err != nil {
+		return err, nil
+	}
+
+	return nil, newRSSITracker(prefix, address, threshold, window, bell)
+}
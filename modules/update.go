@@ -1,8 +1,8 @@
 package modules
 
 import (
-	"context"
 	"math"
+	"net/http"
 	"strconv"
 	"strings"
 
@@ -15,21 +15,40 @@ import (
 
 type UpdateModule struct {
 	session.SessionModule
-	client *github.Client
+	client   *github.Client
+	http     *http.Client
+	attempts int
 }
 
 func NewUpdateModule(s *session.Session) *UpdateModule {
 	u := &UpdateModule{
 		SessionModule: session.NewSessionModule("update", s),
 		client:        github.NewClient(nil),
+		http:          http.DefaultClient,
+		attempts:      3,
 	}
 
+	u.AddParam(session.NewIntParameter("update.attempts",
+		"3",
+		"How many times to try fetching the release info and downloading the update before giving up."))
+
+	u.AddParam(session.NewStringParameter("update.pins",
+		"",
+		"",
+		"Comma separated list of SHA256 hashes (hex or base64) of the certificate public keys to accept while talking to GitHub, every host involved in the download must match one of them, empty to disable pinning."))
+
 	u.AddHandler(session.NewModuleHandler("update.check on", "",
 		"Check latest available stable version and compare it with the one being used.",
 		func(args []string) error {
 			return u.Start()
 		}))
 
+	u.AddHandler(session.NewModuleHandler("update.install", "",
+		"Download the latest stable release for this platform and replace the current binary with it.",
+		func(args []string) error {
+			return u.install()
+		}))
+
 	return u
 }
 
@@ -38,14 +57,32 @@ func (u *UpdateModule) Name() string {
 }
 
 func (u *UpdateModule) Description() string {
-	return "A module to check for bettercap's updates."
+	return "A module to check for and install bettercap's updates."
 }
 
 func (u *UpdateModule) Author() string {
 	return "Simone Margaritelli <evilsocket@protonmail.com>"
 }
 
-func (u *UpdateModule) Configure() error {
+func (u *UpdateModule) Configure() (err error) {
+	var pinList string
+	var pins [][]byte
+
+	if err, u.attempts = u.IntParam("update.attempts"); err != nil {
+		return err
+	} else if u.attempts < 1 {
+		u.attempts = 1
+	}
+
+	if err, pinList = u.StringParam("update.pins"); err != nil {
+		return err
+	} else if err, pins = core.ParsePins(pinList); err != nil {
+		return err
+	}
+
+	u.http = core.PinnedClient(pins)
+	u.client = github.NewClient(u.http)
+
 	return nil
 }
 
@@ -77,13 +114,16 @@ func (u *UpdateModule) versionToNum(ver string) float64 {
 }
 
 func (u *UpdateModule) Start() error {
+	if err := u.Configure(); err != nil {
+		return err
+	}
+
 	return u.SetRunning(true, func() {
 		defer u.SetRunning(false, nil)
 
 		log.Info("checking latest stable release ...")
 
-		if releases, _, err := u.client.Repositories.ListReleases(context.Background(), "bettercap", "bettercap", nil); err == nil {
-			latest := releases[0]
+		if err, latest := u.latestRelease(); err == nil {
 			if u.versionToNum(core.Version) < u.versionToNum(*latest.TagName) {
 				u.Session.Events.Add("update.available", latest)
 			} else {
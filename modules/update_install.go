@@ -0,0 +1,297 @@
+package modules
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/bettercap/bettercap/core"
+	"github.com/bettercap/bettercap/log"
+
+	"github.com/google/go-github/github"
+)
+
+const updateBackoff = 2 * time.Second
+
+type UpdateProgress struct {
+	Asset      string `json:"asset"`
+	Downloaded int64  `json:"downloaded"`
+	Total      int64  `json:"total"`
+	Percent    int    `json:"percent"`
+}
+
+type updateProgressWriter struct {
+	u    *UpdateModule
+	name string
+	done int64
+	size int64
+	last int
+}
+
+// Write keeps track of the downloaded bytes and raises an update.progress
+// event every 10% so that slow downloads still give some feedback.
+func (w *updateProgressWriter) Write(p []byte) (int, error) {
+	w.done += int64(len(p))
+	if w.size > 0 {
+		if percent := int(w.done * 100 / w.size); percent >= w.last+10 || (percent == 100 && w.last != 100) {
+			w.last = percent
+			w.u.Session.Events.Add("update.progress", UpdateProgress{
+				Asset:      w.name,
+				Downloaded: w.done,
+				Total:      w.size,
+				Percent:    percent,
+			})
+		}
+	}
+	return len(p), nil
+}
+
+// withRetry runs cb up to update.attempts times, doubling the delay
+// between each attempt.
+func (u *UpdateModule) withRetry(what string, cb func() error) (err error) {
+	delay := updateBackoff
+	for attempt := 1; attempt <= u.attempts; attempt++ {
+		if err = cb(); err == nil {
+			return nil
+		} else if attempt < u.attempts {
+			log.Warning("%s failed (attempt %d of %d): %s, retrying in %s ...", what, attempt, u.attempts, err, delay)
+			time.Sleep(delay)
+			delay *= 2
+		}
+	}
+	return
+}
+
+func (u *UpdateModule) latestRelease() (error, *github.RepositoryRelease) {
+	var releases []*github.RepositoryRelease
+
+	err := u.withRetry("fetching releases", func() (err error) {
+		releases, _, err = u.client.Repositories.ListReleases(context.Background(), "bettercap", "bettercap", nil)
+		return
+	})
+
+	if err != nil {
+		return err, nil
+	} else if len(releases) == 0 {
+		return fmt.Errorf("no releases found"), nil
+	}
+	return nil, releases[0]
+}
+
+func (u *UpdateModule) findAssets(release *github.RepositoryRelease) (asset *github.ReleaseAsset, checksums *github.ReleaseAsset) {
+	platform := fmt.Sprintf("_%s_%s", runtime.GOOS, runtime.GOARCH)
+	candidates := make([]*github.ReleaseAsset, 0)
+
+	for i := range release.Assets {
+		candidate := &release.Assets[i]
+		name := strings.ToLower(candidate.GetName())
+		if strings.Contains(name, "checksum") || strings.HasSuffix(name, ".sha256") {
+			candidates = append(candidates, candidate)
+		} else if asset == nil && strings.Contains(name, platform) && strings.HasSuffix(name, ".zip") {
+			asset = candidate
+		}
+	}
+
+	// prefer the checksum file of this very asset over a global one
+	for _, candidate := range candidates {
+		if checksums == nil || (asset != nil && strings.HasPrefix(candidate.GetName(), asset.GetName())) {
+			checksums = candidate
+		}
+	}
+	return
+}
+
+func (u *UpdateModule) fetch(url string, out io.Writer) error {
+	resp, err := u.http.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	_, err = io.Copy(out, resp.Body)
+	return err
+}
+
+func (u *UpdateModule) download(asset *github.ReleaseAsset, fileName string) error {
+	name := asset.GetName()
+
+	return u.withRetry("downloading "+name, func() error {
+		out, err := os.Create(fileName)
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+
+		progress := &updateProgressWriter{
+			u:    u,
+			name: name,
+			size: int64(asset.GetSize()),
+		}
+
+		return u.fetch(asset.GetBrowserDownloadURL(), io.MultiWriter(out, progress))
+	})
+}
+
+// expectedChecksum looks for the sha256 of the asset in a checksums file
+// either made of "<hash>  <file name>" lines or of the hash only.
+func (u *UpdateModule) expectedChecksum(checksums *github.ReleaseAsset, assetName string) (error, string) {
+	buf := bytes.Buffer{}
+	if err := u.withRetry("downloading "+checksums.GetName(), func() error {
+		buf.Reset()
+		return u.fetch(checksums.GetBrowserDownloadURL(), &buf)
+	}); err != nil {
+		return err, ""
+	}
+
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 1 && strings.HasPrefix(strings.ToLower(checksums.GetName()), strings.ToLower(assetName)) {
+			return nil, strings.ToLower(fields[0])
+		} else if len(fields) >= 2 && strings.TrimPrefix(fields[1], "*") == assetName {
+			return nil, strings.ToLower(fields[0])
+		}
+	}
+
+	return fmt.Errorf("no checksum found for %s in %s", assetName, checksums.GetName()), ""
+}
+
+func (u *UpdateModule) verify(asset *github.ReleaseAsset, checksums *github.ReleaseAsset, fileName string) error {
+	stat, err := os.Stat(fileName)
+	if err != nil {
+		return err
+	} else if expected := int64(asset.GetSize()); stat.Size() != expected {
+		return fmt.Errorf("downloaded %d bytes but %s is %d bytes", stat.Size(), asset.GetName(), expected)
+	}
+
+	if checksums == nil {
+		log.Warning("no checksums published for this release, only the size of %s has been verified.", asset.GetName())
+		return nil
+	}
+
+	err, expected := u.expectedChecksum(checksums, asset.GetName())
+	if err != nil {
+		return err
+	}
+
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return err
+	}
+
+	hash := sha256.Sum256(data)
+	if got := hex.EncodeToString(hash[:]); got != expected {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", asset.GetName(), expected, got)
+	}
+
+	return nil
+}
+
+// replaceBinary copies the new binary next to the current one and then
+// renames it, so the existing executable is never left half written.
+func (u *UpdateModule) replaceBinary(newBinary string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	} else if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return err
+	}
+
+	data, err := ioutil.ReadFile(newBinary)
+	if err != nil {
+		return err
+	}
+
+	tmp := exe + ".update"
+	if err = ioutil.WriteFile(tmp, data, 0755); err != nil {
+		return err
+	} else if err = os.Rename(tmp, exe); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	return nil
+}
+
+func (u *UpdateModule) installRelease(release *github.RepositoryRelease) error {
+	asset, checksums := u.findAssets(release)
+	if asset == nil {
+		return fmt.Errorf("release %s has no build for %s/%s", release.GetTagName(), runtime.GOOS, runtime.GOARCH)
+	}
+
+	tmpDir, err := ioutil.TempDir("", "bettercap-update")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	archive := filepath.Join(tmpDir, asset.GetName())
+
+	log.Info("downloading %s ...", core.Bold(asset.GetName()))
+
+	if err = u.download(asset, archive); err != nil {
+		return err
+	} else if err = u.verify(asset, checksums, archive); err != nil {
+		return err
+	}
+
+	files, err := core.Unzip(archive, filepath.Join(tmpDir, "release"))
+	if err != nil {
+		return err
+	}
+
+	binName := "bettercap"
+	if runtime.GOOS == "windows" {
+		binName += ".exe"
+	}
+
+	for _, file := range files {
+		if filepath.Base(file) == binName {
+			return u.replaceBinary(file)
+		}
+	}
+
+	return fmt.Errorf("%s not found in %s", binName, asset.GetName())
+}
+
+func (u *UpdateModule) install() error {
+	if err := u.Configure(); err != nil {
+		return err
+	}
+
+	return u.SetRunning(true, func() {
+		defer u.SetRunning(false, nil)
+
+		log.Info("checking latest stable release ...")
+
+		err, latest := u.latestRelease()
+		if err != nil {
+			log.Error("error while fetching latest release info from GitHub: %s", err)
+			return
+		} else if u.versionToNum(core.Version) >= u.versionToNum(latest.GetTagName()) {
+			log.Info("you are running %s which is the latest stable version.", core.Bold(core.Version))
+			return
+		}
+
+		if err = u.installRelease(latest); err != nil {
+			log.Error("could not update to %s, current binary left untouched: %s", latest.GetTagName(), err)
+		} else {
+			log.Info("updated to %s, restart bettercap to use it.", core.Bold(latest.GetTagName()))
+		}
+	})
+}
@@ -11,7 +11,30 @@ import (
 	"github.com/bettercap/bettercap/session"
 )
 
-func findMAC(s *session.Session, ip net.IP, probe bool) (net.HardwareAddr, error) {
+// parseSourceMAC parses the value of a *.srcmac parameter, an empty
+// value means the interface hardware address should be used.
+func parseSourceMAC(value string) (net.HardwareAddr, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	hw, err := net.ParseMAC(network.NormalizeMac(value))
+	if err != nil {
+		return nil, fmt.Errorf("Invalid source MAC '%s': %s", value, err)
+	} else if len(hw) != 6 {
+		return nil, fmt.Errorf("Invalid source MAC '%s': not an ethernet address", value)
+	} else if hw[0]&0x01 != 0 {
+		return nil, fmt.Errorf("Invalid source MAC '%s': can't be a multicast address", value)
+	}
+
+	log.Warning("Using spoofed source MAC %s, note that some drivers will ignore it and inject frames with the real address.", hw)
+
+	return hw, nil
+}
+
+func findMAC(m *session.SessionModule, ip net.IP, probe bool) (net.HardwareAddr, error) {
+	s := m.Session
+
 	var mac string
 	var hw net.HardwareAddr
 	var err error
@@ -25,7 +48,7 @@ func findMAC(s *session.Session, ip net.IP, probe bool) (net.HardwareAddr, error
 		if err, probe := packets.NewUDPProbe(from, from_hw, ip, 139); err != nil {
 			log.Error("Error while creating UDP probe packet for %s: %s", ip.String(), err)
 		} else {
-			s.Queue.Send(probe)
+			m.Send(probe)
 		}
 
 		time.Sleep(500 * time.Millisecond)
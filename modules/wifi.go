@@ -8,7 +8,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/bettercap/bettercap/core"
 	"github.com/bettercap/bettercap/log"
 	"github.com/bettercap/bettercap/network"
 	"github.com/bettercap/bettercap/packets"
@@ -22,21 +21,34 @@ import (
 type WiFiModule struct {
 	session.SessionModule
 
-	handle              *pcap.Handle
-	source              string
-	channel             int
-	hopPeriod           time.Duration
-	frequencies         []int
-	ap                  *network.AccessPoint
-	stickChan           int
-	skipBroken          bool
-	pktSourceChan       chan gopacket.Packet
-	pktSourceChanClosed bool
-	apRunning           bool
-	apConfig            packets.Dot11ApConfig
-	writes              *sync.WaitGroup
-	reads               *sync.WaitGroup
-	chanLock            *sync.Mutex
+	handle        *pcap.Handle
+	captures      []*wifiCapture
+	source        string
+	channel       int
+	hopPeriod     time.Duration
+	frequencies   []int
+	ap            *network.AccessPoint
+	stickChan     int
+	lockedChan    int
+	lockedLock    *sync.Mutex
+	skipBroken    bool
+	rates         bool
+	apRunning     bool
+	apConfig      packets.Dot11ApConfig
+	writes        *sync.WaitGroup
+	reads         *sync.WaitGroup
+	chanLock      *sync.Mutex
+	followLock    *sync.Mutex
+	follow        *wifiFollow
+	clientsAlert  int
+	crowded       map[string]bool
+	Channels      *WiFiChannels
+	DeauthTargets *WiFiDeauthTargets
+	tracker       *rssiTracker
+	probing       bool
+	roundRobin    bool
+	handshakes    *wifiHandshakes
+	radio         wifiRadio
 }
 
 func NewWiFiModule(s *session.Session) *WiFiModule {
@@ -51,6 +63,12 @@ func NewWiFiModule(s *session.Session) *WiFiModule {
 		writes:        &sync.WaitGroup{},
 		reads:         &sync.WaitGroup{},
 		chanLock:      &sync.Mutex{},
+		followLock:    &sync.Mutex{},
+		lockedLock:    &sync.Mutex{},
+		crowded:       make(map[string]bool),
+		Channels:      NewWiFiChannels(),
+		DeauthTargets: NewWiFiDeauthTargets(),
+		handshakes:    newWiFiHandshakes(),
 	}
 
 	w.AddHandler(session.NewModuleHandler("wifi.recon on", "",
@@ -84,7 +102,7 @@ func NewWiFiModule(s *session.Session) *WiFiModule {
 		func(args []string) (err error) {
 			w.ap = nil
 			w.stickChan = 0
-			w.frequencies, err = network.GetSupportedFrequencies(w.Session.Interface.Name())
+			w.frequencies, err = network.GetSupportedFrequencies(w.mainInterface())
 			return err
 		}))
 
@@ -98,6 +116,75 @@ func NewWiFiModule(s *session.Session) *WiFiModule {
 			return w.startDeauth(bssid)
 		}))
 
+	w.AddParam(session.NewIntParameter("wifi.deauth.batch",
+		"1",
+		"How many deauth frames to write back to back before pausing, higher values are faster on adapters that can keep up, the attack falls back to 1 if the driver fails to queue them."))
+
+	w.AddParam(session.NewIntParameter("wifi.deauth.reason",
+		"7",
+		"802.11 reason code (1-66) to set in the deauthentication and disassociation frames, some clients and access points only react to specific ones."))
+
+	w.AddParam(session.NewStringParameter("wifi.deauth.type",
+		"deauth",
+		"^(deauth|disassoc|both)$",
+		"Type of frames the deauth attack will send: deauth for deauthentication, disassoc for disassociation or both to alternate them."))
+
+	w.AddParam(session.NewStringParameter("wifi.deauth.srcmac",
+		"",
+		`^$|^[a-fA-F0-9]{2}(:[a-fA-F0-9]{2}){5}$`,
+		"If not empty, use this MAC address (or '"+session.ParamRandomMAC+"') as the transmitter address of deauth frames instead of the spoofed AP and client ones, clients might ignore frames not coming from their AP."))
+
+	w.AddParam(session.NewBoolParameter("wifi.deauth.coalesce",
+		"true",
+		"If true, a client listed under more than one access point is only deauthenticated from the one it was last seen on."))
+
+	w.AddHandler(session.NewModuleHandler("wifi.deauth.targets", "",
+		"Show the clients currently targeted by the deauth attacks and how many frames were sent to each one.",
+		func(args []string) error {
+			return w.ShowDeauthTargets()
+		}))
+
+	w.AddHandler(session.NewModuleHandler("wifi.deauth.roundrobin on", "",
+		"Deauth the clients of every access point on the locked channel in turn, one burst each, the ones with a captured handshake having the lowest priority.",
+		func(args []string) error {
+			return w.startRoundRobin()
+		}))
+
+	w.AddHandler(session.NewModuleHandler("wifi.deauth.roundrobin off", "",
+		"Stop wifi.deauth.roundrobin.",
+		func(args []string) error {
+			return w.stopRoundRobin()
+		}))
+
+	w.AddParam(session.NewIntParameter("wifi.deauth.roundrobin.period",
+		"1000",
+		"Milliseconds wifi.deauth.roundrobin waits after each burst, to give the clients the time to reconnect."))
+
+	w.AddHandler(session.NewModuleHandler("wifi.probe BSSID", `wifi\.probe\s+((?:[0-9A-Fa-f]{2}[:-]){5}(?:[0-9A-Fa-f]{2}))`,
+		"Reveal the SSID of the hidden access point BSSID by sending it probe requests for each name in wifi.probe.wordlist on its channel, until it answers one.",
+		func(args []string) error {
+			bssid, err := net.ParseMAC(args[0])
+			if err != nil {
+				return err
+			}
+			return w.startProbe(bssid)
+		}))
+
+	w.AddHandler(session.NewModuleHandler("wifi.probe off", "",
+		"Stop the wifi.probe running for a hidden access point.",
+		func(args []string) error {
+			return w.stopProbe()
+		}))
+
+	w.AddParam(session.NewStringParameter("wifi.probe.wordlist",
+		"",
+		"",
+		"File with one SSID per line wifi.probe will try against a hidden access point."))
+
+	w.AddParam(session.NewIntParameter("wifi.probe.rate",
+		"10",
+		"How many probe requests per second wifi.probe will send."))
+
 	w.AddHandler(session.NewModuleHandler("wifi.ap", "",
 		"Inject fake management beacons in order to create a rogue access point.",
 		func(args []string) error {
@@ -126,12 +213,52 @@ func NewWiFiModule(s *session.Session) *WiFiModule {
 		"true",
 		"If true, the fake access point will use WPA2, otherwise it'll result as an open AP."))
 
+	w.AddParam(session.NewStringParameter("wifi.region",
+		"",
+		`^$|^[a-zA-Z0-9]{2}$`,
+		"If not empty, the ISO 3166 country code (or 00 for the world) of the regulatory domain to set when wifi.recon starts, the previous one is restored when it stops."))
+
+	w.AddParam(session.NewIntParameter("wifi.txpower",
+		"0",
+		"If greater than 0, the TX power in dBm to set on the WiFi interfaces when wifi.recon starts, the drivers pick it again when it stops."))
+
+	w.AddParam(session.NewIntParameter("wifi.ap.clients.alert",
+		"0",
+		"If greater than 0, emit a wifi.ap.crowded event when an access point has more clients than this, the event fires again only after the clients count drops to 80% of it."))
+
 	w.AddHandler(session.NewModuleHandler("wifi.show", "",
 		"Show current wireless stations list (default sorting by essid).",
 		func(args []string) error {
 			return w.Show("rssi")
 		}))
 
+	w.AddParam(session.NewStringParameter("wifi.graph.output",
+		"~/bettercap-wifi.dot",
+		"",
+		"File wifi.graph will save the Graphviz DOT description of the access points and their clients to, if its extension is .svg it will be rendered with the dot executable."))
+
+	w.AddHandler(session.NewModuleHandler("wifi.graph", "",
+		"Save the access points and their clients as a Graphviz graph to wifi.graph.output.",
+		func(args []string) error {
+			if err, output := w.StringParam("wifi.graph.output"); err != nil {
+				return err
+			} else {
+				return writeGraph(output, wifiGraph(w.Session))
+			}
+		}))
+
+	w.AddHandler(session.NewModuleHandler("wifi.channels", "",
+		"Show per channel utilization, access points and clients.",
+		func(args []string) error {
+			return w.ShowChannels()
+		}))
+
+	w.AddHandler(session.NewModuleHandler("wifi.hop.recommend", "",
+		"Suggest the busiest channel to stick on given the utilization measured so far.",
+		func(args []string) error {
+			return w.RecommendChannel()
+		}))
+
 	w.AddHandler(session.NewModuleHandler("wifi.recon.channel", `wifi\.recon\.channel[\s]+([0-9]+(?:[, ]+[0-9]+)*|clear)`,
 		"WiFi channels (comma separated) or 'clear' for channel hopping.",
 		func(args []string) error {
@@ -149,7 +276,7 @@ func NewWiFiModule(s *session.Session) *WiFiModule {
 				}
 			} else {
 				// No channels setted, retrieve frequencies supported by the card
-				if frequencies, err := network.GetSupportedFrequencies(w.Session.Interface.Name()); err != nil {
+				if frequencies, err := network.GetSupportedFrequencies(w.mainInterface()); err != nil {
 					return err
 				} else {
 					newfrequencies = frequencies
@@ -161,6 +288,67 @@ func NewWiFiModule(s *session.Session) *WiFiModule {
 			return nil
 		}))
 
+	w.AddHandler(session.NewModuleHandler("wifi.channel.lock CHANNEL", `wifi\.channel\.lock\s+([0-9]+)`,
+		"Disable channel hopping and lock every capture interface on CHANNEL, the channel is periodically verified and set again if the driver moved away from it.",
+		func(args []string) error {
+			channel, err := strconv.Atoi(args[0])
+			if err != nil {
+				return err
+			}
+			if w.following() != nil {
+				w.Unfollow()
+			}
+			return w.LockChannel(channel)
+		}))
+
+	w.AddHandler(session.NewModuleHandler("wifi.channel.unlock", "",
+		"Remove the channel lock and resume channel hopping.",
+		func(args []string) error {
+			if w.following() != nil {
+				w.Unfollow()
+			}
+			return w.UnlockChannel()
+		}))
+
+	w.AddHandler(session.NewModuleHandler("wifi.follow BSSID", `wifi\.follow ((?:[0-9A-Fa-f]{2}[:-]){5}(?:[0-9A-Fa-f]{2}))`,
+		"Lock the channel of the access point BSSID and follow it when it announces a channel switch or shows up on another channel.",
+		func(args []string) error {
+			return w.Follow(args[0])
+		}))
+
+	w.AddHandler(session.NewModuleHandler("wifi.follow off", "",
+		"Stop following the access point, the channel stays locked on its last one.",
+		func(args []string) error {
+			return w.Unfollow()
+		}))
+
+	w.AddParam(session.NewIntParameter("wifi.follow.timeout",
+		"10",
+		"Number of seconds without beacons from the access point followed with wifi.follow after which the channel is unlocked to find it again."))
+
+	w.AddParam(session.NewIntParameter("wifi.channel.lock.period",
+		"1000",
+		"How often in milliseconds to verify that the interfaces are still on the channel set with wifi.channel.lock."))
+
+	w.AddHandler(session.NewModuleHandler("wifi.track MAC", `wifi\.track\s+((?:[0-9A-Fa-f]{2}[:-]){5}(?:[0-9A-Fa-f]{2}))`,
+		"Track the signal of the access point or client station MAC, a track.hot event is emitted when its average RSSI goes above wifi.track.threshold and a track.cold one when it goes back below.",
+		func(args []string) error {
+			return w.Track(args[0])
+		}))
+
+	w.AddHandler(session.NewModuleHandler("wifi.track off", "",
+		"Stop tracking the signal of the station set with wifi.track.",
+		func(args []string) error {
+			return w.StopTracking()
+		}))
+
+	addTrackParams(&w.SessionModule, "wifi")
+
+	w.AddParam(session.NewStringParameter("wifi.interface",
+		"",
+		"",
+		"Comma separated list of monitor mode interfaces to capture from, each one with its own channel hopper, the first one is also used for injection, empty for the session interface."))
+
 	w.AddParam(session.NewStringParameter("wifi.source.file",
 		"",
 		"",
@@ -170,10 +358,47 @@ func NewWiFiModule(s *session.Session) *WiFiModule {
 		"250",
 		"If channel hopping is enabled (empty wifi.recon.channel), this is the time in milliseconds the algorithm will hop on every channel (it'll be doubled if both 2.4 and 5.0 bands are available)."))
 
+	w.AddParam(session.NewBoolParameter("wifi.recon.management",
+		"false",
+		"If true, only capture beacons, probe requests and probe responses to lower the CPU usage, access points are still discovered but data frames are skipped."))
+
+	w.AddParam(session.NewBoolParameter("wifi.recon.management.eapol",
+		"true",
+		"If true and wifi.recon.management is enabled, also capture EAPOL frames so that EAP identities and handshakes are still parsed."))
+
+	w.AddParam(session.NewStringParameter("wifi.handshakes.file",
+		"",
+		"",
+		"If not empty, the captured WPA handshakes and PMKIDs will be appended to this pcap file, along with a beacon of their access point, to be cracked with aircrack-ng."))
+
+	w.AddParam(session.NewStringParameter("wifi.handshakes.hc22000",
+		"",
+		"",
+		"If not empty, the captured WPA handshakes and PMKIDs will be appended to this file in the hashcat 22000 format."))
+
+	w.AddParam(session.NewBoolParameter("wifi.handshakes.force",
+		"false",
+		"If true, the handshakes of clients already captured are captured and saved again every time they reconnect."))
+
+	w.AddHandler(session.NewModuleHandler("wifi.recap BSSID", `wifi\.recap ((?:[0-9A-Fa-f]{2}[:-]){5}(?:[0-9A-Fa-f]{2}))`,
+		"Forget the handshakes captured for the access point BSSID so that they are captured and saved again.",
+		func(args []string) error {
+			bssid, err := net.ParseMAC(args[0])
+			if err != nil {
+				return err
+			}
+			log.Info("forgot %d captured handshakes of %s.", w.handshakes.Reset(bssid.String()), bssid)
+			return nil
+		}))
+
 	w.AddParam(session.NewBoolParameter("wifi.skip-broken",
 		"true",
 		"If true, dot11 packets with an invalid checksum will be skipped."))
 
+	w.AddParam(session.NewBoolParameter("wifi.rates",
+		"true",
+		"If true, keep track of the data rates and MCS indexes of the frames each station sends and show the dominant one with wifi.show."))
+
 	return w
 }
 
@@ -197,48 +422,26 @@ func (w *WiFiModule) Configure() error {
 		return err
 	}
 
-	if w.source != "" {
-		if w.handle, err = pcap.OpenOffline(w.source); err != nil {
-			return err
-		}
-	} else {
-		ihandle, err := pcap.NewInactiveHandle(w.Session.Interface.Name())
-		if err != nil {
-			return err
-		}
-		defer ihandle.CleanUp()
-
-		if err = ihandle.SetRFMon(true); err != nil {
-			return fmt.Errorf("Error while setting interface %s in monitor mode: %s", core.Bold(w.Session.Interface.Name()), err)
-		} else if err = ihandle.SetSnapLen(65536); err != nil {
-			return err
-		} else if err = ihandle.SetTimeout(pcap.BlockForever); err != nil {
-			return err
-		} else if w.handle, err = ihandle.Activate(); err != nil {
-			return err
-		}
+	if err = w.openCaptures(); err != nil {
+		return err
 	}
 
 	if err, w.skipBroken = w.BoolParam("wifi.skip-broken"); err != nil {
 		return err
+	} else if err, w.rates = w.BoolParam("wifi.rates"); err != nil {
+		return err
 	} else if err, hopPeriod = w.IntParam("wifi.hop.period"); err != nil {
 		return err
+	} else if err, w.clientsAlert = w.IntParam("wifi.ap.clients.alert"); err != nil {
+		return err
 	}
 
 	w.hopPeriod = time.Duration(hopPeriod) * time.Millisecond
 
 	if w.source == "" {
-		// No channels setted, retrieve frequencies supported by the card
+		// No channels setted, use the frequencies supported by the card
 		if len(w.frequencies) == 0 {
-			if w.frequencies, err = network.GetSupportedFrequencies(w.Session.Interface.Name()); err != nil {
-				return err
-			}
-
-			// we need to start somewhere, this is just to check if
-			// this OS supports switching channel programmatically.
-			if err = network.SetInterfaceChannel(w.Session.Interface.Name(), 1); err != nil {
-				return err
-			}
+			w.frequencies = w.captures[0].frequencies
 			log.Info("WiFi recon active with channel hopping.")
 		}
 	}
@@ -273,48 +476,55 @@ func (w *WiFiModule) updateStats(dot11 *layers.Dot11, packet gopacket.Packet) {
 }
 
 func (w *WiFiModule) Start() error {
+	if err, source := w.StringParam("wifi.source.file"); err != nil {
+		return err
+	} else if source == "" {
+		if err := w.applyRegion(); err != nil {
+			return fmt.Errorf("could not set the regulatory domain: %s", err)
+		}
+	}
+
 	if err := w.Configure(); err != nil {
+		w.restoreRadio()
 		return err
+	} else if w.source == "" {
+		if err := w.applyTxPower(); err != nil {
+			w.closeCaptures()
+			w.restoreRadio()
+			return err
+		}
 	}
 
+	w.Channels.Reset()
+
 	w.SetRunning(true, func() {
-		// start channel hopper if needed
+		// start channel hoppers if needed
 		if w.channel == 0 && w.source == "" {
-			go w.channelHopper()
+			for _, c := range w.captures {
+				go w.channelHopper(c)
+			}
 		}
 
-		// start the pruner
-		go w.stationPruner()
-
-		w.reads.Add(1)
-		defer w.reads.Done()
-
-		src := gopacket.NewPacketSource(w.handle, w.handle.LinkType())
-		w.pktSourceChan = src.Packets()
-		for packet := range w.pktSourceChan {
-			if !w.Running() {
-				break
-			} else if packet == nil {
-				continue
-			}
+		if w.lockedChannel() != 0 && w.source == "" {
+			w.startChannelLock()
+		}
 
-			w.Session.Queue.TrackPacket(uint64(len(packet.Data())))
+		if f := w.following(); f != nil && w.source == "" {
+			w.reads.Add(1)
+			go w.followWatcher(f)
+		}
 
-			// perform initial dot11 parsing and layers validation
-			if ok, radiotap, dot11 := packets.Dot11Parse(packet); ok {
-				// check FCS checksum
-				if w.skipBroken && !dot11.ChecksumValid() {
-					log.Debug("Skipping dot11 packet with invalid checksum.")
-					continue
-				}
+		// start the pruner
+		go w.stationPruner()
 
-				w.discoverProbes(radiotap, dot11, packet)
-				w.discoverAccessPoints(radiotap, dot11, packet)
-				w.discoverClients(radiotap, dot11, packet)
-				w.updateStats(dot11, packet)
-			}
+		// every other capture reads in background, the main one in here
+		for _, c := range w.captures[1:] {
+			w.reads.Add(1)
+			go w.readPackets(c)
 		}
-		w.pktSourceChanClosed = true
+
+		w.reads.Add(1)
+		w.readPackets(w.captures[0])
 	})
 
 	return nil
@@ -324,14 +534,16 @@ func (w *WiFiModule) Stop() error {
 	return w.SetRunning(false, func() {
 		// wait any pending write operation
 		w.writes.Wait()
-		// signal the main for loop we want to exit
-		if !w.pktSourceChanClosed {
-			w.pktSourceChan <- nil
+		// signal the read loops we want to exit
+		for _, c := range w.captures {
+			if !c.closed && c.pktSourceChan != nil {
+				c.pktSourceChan <- nil
+			}
 		}
-		// close the pcap handle to make the main for exit
-		w.handle.Close()
-		// close the pcap handle to make the main for exit
-		// wait for the loop to exit.
+		// close the pcap handles to make the loops exit
+		w.closeCaptures()
+		// wait for the loops to exit.
 		w.reads.Wait()
+		w.restoreRadio()
 	})
 }
@@ -54,7 +54,7 @@ func (w *WiFiModule) startAp() error {
 			w.apConfig.Channel,
 			enc)
 
-		for seqn := uint16(0); w.Running(); seqn++ {
+		for seqn := uint16(0); w.WaitIfPaused(); seqn++ {
 			w.writes.Add(1)
 			defer w.writes.Done()
 
@@ -0,0 +1,254 @@
+package modules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bettercap/bettercap/core"
+	"github.com/bettercap/bettercap/log"
+	"github.com/bettercap/bettercap/network"
+	"github.com/bettercap/bettercap/packets"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/pcap"
+)
+
+// wifiCapture is a monitor mode capture handle, the first one is also
+// used for packet injection while every other one is only used for
+// recon and has its own channel hopper.
+type wifiCapture struct {
+	name          string
+	handle        *pcap.Handle
+	frequencies   []int
+	pktSourceChan chan gopacket.Packet
+	closed        bool
+}
+
+func (c *wifiCapture) supports(frequency int) bool {
+	for _, f := range c.frequencies {
+		if f == frequency {
+			return true
+		}
+	}
+	return false
+}
+
+// interfaces returns the list of interfaces set with wifi.interface,
+// or the session one if empty.
+func (w *WiFiModule) interfaces() (error, []string) {
+	err, ifaces := w.ListParam("wifi.interface")
+	if err != nil {
+		return err, nil
+	} else if len(ifaces) == 0 {
+		ifaces = []string{w.Session.Interface.Name()}
+	}
+	return nil, ifaces
+}
+
+// mainInterface returns the name of the interface used for injection.
+func (w *WiFiModule) mainInterface() string {
+	if len(w.captures) > 0 && w.source == "" {
+		return w.captures[0].name
+	} else if err, ifaces := w.interfaces(); err == nil {
+		return ifaces[0]
+	}
+	return w.Session.Interface.Name()
+}
+
+// multiCapture returns true if more than one interface is capturing.
+func (w *WiFiModule) multiCapture() bool {
+	return len(w.captures) > 1
+}
+
+func (w *WiFiModule) isMainCapture(c *wifiCapture) bool {
+	return len(w.captures) > 0 && w.captures[0] == c
+}
+
+func openWiFiCapture(name string) (*wifiCapture, error) {
+	c := &wifiCapture{name: name}
+
+	ihandle, err := pcap.NewInactiveHandle(name)
+	if err != nil {
+		return nil, err
+	}
+	defer ihandle.CleanUp()
+
+	if err = ihandle.SetRFMon(true); err != nil {
+		return nil, fmt.Errorf("Error while setting interface %s in monitor mode: %s", core.Bold(name), err)
+	} else if err = ihandle.SetSnapLen(65536); err != nil {
+		return nil, err
+	} else if err = ihandle.SetTimeout(pcap.BlockForever); err != nil {
+		return nil, err
+	} else if c.handle, err = ihandle.Activate(); err != nil {
+		return nil, err
+	}
+
+	if c.frequencies, err = network.GetSupportedFrequencies(name); err != nil {
+		c.handle.Close()
+		return nil, err
+	}
+
+	// we need to start somewhere, this is just to check if
+	// this OS supports switching channel programmatically.
+	if err = network.SetInterfaceChannel(name, 1); err != nil {
+		c.handle.Close()
+		return nil, err
+	}
+
+	return c, nil
+}
+
+const (
+	wifiManagementFilter = "type mgt subtype beacon or type mgt subtype probe-resp or type mgt subtype probe-req"
+	// EAPOL frames, needed to parse the EAP identities and the handshakes
+	wifiEAPOLFilter = "ether proto 0x888e"
+)
+
+// captureFilter returns the BPF filter for the capture handles, empty
+// unless wifi.recon.management is true.
+func (w *WiFiModule) captureFilter() (error, string) {
+	if err, management := w.BoolParam("wifi.recon.management"); err != nil || !management {
+		return err, ""
+	} else if err, eapol := w.BoolParam("wifi.recon.management.eapol"); err != nil {
+		return err, ""
+	} else if eapol {
+		return nil, fmt.Sprintf("(%s) or (%s)", wifiManagementFilter, wifiEAPOLFilter)
+	}
+	return nil, wifiManagementFilter
+}
+
+func (w *WiFiModule) setCaptureFilter() error {
+	err, filter := w.captureFilter()
+	if err != nil || filter == "" {
+		return err
+	}
+
+	for _, c := range w.captures {
+		if err := c.handle.SetBPFFilter(filter); err != nil {
+			return fmt.Errorf("could not set the management frames filter on %s: %s", c.name, err)
+		}
+	}
+
+	log.Info("only capturing management frames, clients will only be discovered from their probes.")
+	return nil
+}
+
+func (w *WiFiModule) openCaptures() error {
+	w.captures = make([]*wifiCapture, 0)
+
+	if w.source != "" {
+		handle, err := pcap.OpenOffline(w.source)
+		if err != nil {
+			return err
+		}
+		w.captures = append(w.captures, &wifiCapture{name: w.source, handle: handle})
+	} else {
+		err, ifaces := w.interfaces()
+		if err != nil {
+			return err
+		}
+
+		for _, name := range ifaces {
+			if c, err := openWiFiCapture(name); err != nil {
+				if len(ifaces) == 1 {
+					return err
+				}
+				log.Error("could not start WiFi capture on %s: %s", name, err)
+			} else {
+				w.captures = append(w.captures, c)
+			}
+		}
+
+		if len(w.captures) == 0 {
+			return fmt.Errorf("could not start WiFi capture on any of %s", strings.Join(ifaces, ", "))
+		}
+	}
+
+	w.handle = w.captures[0].handle
+
+	if err := w.setCaptureFilter(); err != nil {
+		w.closeCaptures()
+		return err
+	}
+	return nil
+}
+
+func (w *WiFiModule) closeCaptures() {
+	for _, c := range w.captures {
+		c.handle.Close()
+	}
+}
+
+// hopFrequencies returns the frequencies the capture should hop on, the
+// main one follows wifi.recon.channel while the others only use the
+// selected channels they support, or all of theirs if none.
+func (w *WiFiModule) hopFrequencies(c *wifiCapture) []int {
+	if w.isMainCapture(c) {
+		return w.frequencies
+	}
+
+	frequencies := make([]int, 0)
+	for _, f := range w.frequencies {
+		if c.supports(f) {
+			frequencies = append(frequencies, f)
+		}
+	}
+
+	if len(frequencies) == 0 {
+		return c.frequencies
+	}
+	return frequencies
+}
+
+// isMonitored returns true if any of the captures is hopping on frequency.
+func (w *WiFiModule) isMonitored(frequency int) bool {
+	for _, c := range w.captures {
+		for _, f := range w.hopFrequencies(c) {
+			if f == frequency {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (w *WiFiModule) readPackets(c *wifiCapture) {
+	defer w.reads.Done()
+
+	src := gopacket.NewPacketSource(c.handle, c.handle.LinkType())
+	c.pktSourceChan = src.Packets()
+	for packet := range c.pktSourceChan {
+		if !w.Running() {
+			break
+		} else if packet == nil {
+			continue
+		}
+
+		w.FrameReceived()
+		w.Session.Queue.TrackPacket(uint64(len(packet.Data())))
+
+		// perform initial dot11 parsing and layers validation
+		if ok, radiotap, dot11 := packets.Dot11Parse(packet); ok {
+			// check FCS checksum
+			if w.skipBroken && !dot11.ChecksumValid() {
+				log.Debug("Skipping dot11 packet with invalid checksum.")
+				continue
+			}
+
+			w.Channels.TrackFrame(radiotap, len(packet.Data()))
+			w.discoverProbes(radiotap, dot11, packet)
+			w.discoverAccessPoints(c, radiotap, dot11, packet)
+			w.discoverClients(c, radiotap, dot11, packet)
+			w.discoverIdentities(radiotap, dot11, packet)
+			w.discoverHandshakes(dot11, packet)
+			w.updateStats(dot11, packet)
+			w.trackRates(radiotap, dot11)
+			w.followAccessPoint(dot11, packet)
+		}
+	}
+	c.closed = true
+
+	if w.Running() && w.source == "" {
+		log.Warning("WiFi capture on %s stopped, the other interfaces will keep capturing.", c.name)
+	}
+}
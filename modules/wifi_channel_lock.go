@@ -0,0 +1,128 @@
+package modules
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bettercap/bettercap/core"
+	"github.com/bettercap/bettercap/log"
+	"github.com/bettercap/bettercap/network"
+)
+
+// setLockedChannel moves every capture interface supporting it to the locked
+// channel, if force is true the channel is set even if we think they're
+// already on it.
+func (w *WiFiModule) setLockedChannel(channel int, force bool) {
+	freq := network.Dot11Chan2Freq(channel)
+	for _, c := range w.captures {
+		if !c.supports(freq) {
+			log.Warning("%s does not support channel %d, it won't be locked.", c.name, channel)
+			continue
+		}
+
+		isMain := w.isMainCapture(c)
+		if isMain {
+			w.chanLock.Lock()
+		}
+
+		var err error
+		if force {
+			err = network.ForceInterfaceChannel(c.name, channel)
+		} else {
+			err = network.SetInterfaceChannel(c.name, channel)
+		}
+
+		if isMain {
+			w.chanLock.Unlock()
+		}
+
+		if err != nil {
+			log.Warning("error while locking %s to channel %d: %s", c.name, channel, err)
+		}
+	}
+}
+
+// verifyLockedChannel makes sure the drivers didn't silently move the
+// interfaces away from the locked channel.
+func (w *WiFiModule) verifyLockedChannel(channel int) {
+	freq := network.Dot11Chan2Freq(channel)
+	for _, c := range w.captures {
+		if !c.supports(freq) {
+			continue
+		}
+
+		if curr, err := network.GetInterfaceChannel(c.name); err != nil {
+			log.Debug("could not read the current channel of %s: %s", c.name, err)
+		} else if curr != channel {
+			log.Warning("%s is on channel %d instead of the locked channel %d, setting it again.", c.name, curr, channel)
+			w.setLockedChannel(channel, true)
+			return
+		}
+	}
+}
+
+func (w *WiFiModule) channelLockVerifier(channel int) {
+	err, period := w.IntParam("wifi.channel.lock.period")
+	if err != nil || period <= 0 {
+		period = 1000
+	}
+
+	defer w.reads.Done()
+
+	for w.Running() && w.lockedChannel() == channel {
+		time.Sleep(time.Duration(period) * time.Millisecond)
+		if w.Running() && w.lockedChannel() == channel {
+			w.verifyLockedChannel(channel)
+		}
+	}
+}
+
+// startChannelLock applies the locked channel and starts re-verifying it.
+func (w *WiFiModule) startChannelLock() {
+	channel := w.lockedChannel()
+	log.Info("channel hopping disabled, locked on channel %s.", core.Bold(fmt.Sprintf("%d", channel)))
+	w.setLockedChannel(channel, true)
+	w.reads.Add(1)
+	go w.channelLockVerifier(channel)
+}
+
+// lockedChannel returns the channel locked with wifi.channel.lock or by
+// wifi.follow, 0 if hopping.
+func (w *WiFiModule) lockedChannel() int {
+	w.lockedLock.Lock()
+	defer w.lockedLock.Unlock()
+	return w.lockedChan
+}
+
+func (w *WiFiModule) LockChannel(channel int) error {
+	if w.source != "" {
+		return fmt.Errorf("can't lock the channel while reading from %s", w.source)
+	} else if network.Dot11Chan2Freq(channel) == 0 {
+		return fmt.Errorf("invalid channel %d", channel)
+	}
+
+	w.lockedLock.Lock()
+	w.lockedChan = channel
+	w.lockedLock.Unlock()
+
+	if w.Running() {
+		w.startChannelLock()
+	}
+
+	return nil
+}
+
+func (w *WiFiModule) UnlockChannel() error {
+	w.lockedLock.Lock()
+	channel := w.lockedChan
+	w.lockedChan = 0
+	w.lockedLock.Unlock()
+
+	if channel == 0 {
+		return fmt.Errorf("the channel is not locked")
+	}
+
+	log.Info("channel %d unlocked, channel hopping resumed.", channel)
+
+	return nil
+}
@@ -0,0 +1,214 @@
+package modules
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bettercap/bettercap/core"
+	"github.com/bettercap/bettercap/network"
+
+	"github.com/dustin/go-humanize"
+	"github.com/google/gopacket/layers"
+)
+
+type ChannelUsage struct {
+	Channel     int     `json:"channel"`
+	Frequency   int     `json:"frequency"`
+	Frames      uint64  `json:"frames"`
+	Bytes       uint64  `json:"bytes"`
+	Airtime     float64 `json:"airtime"`
+	Dwell       float64 `json:"dwell"`
+	Utilization float64 `json:"utilization"`
+	APs         int     `json:"aps"`
+	Clients     int     `json:"clients"`
+}
+
+type channelCounter struct {
+	frames  uint64
+	bytes   uint64
+	airtime time.Duration
+	dwell   time.Duration
+}
+
+// WiFiChannels keeps per frequency frame counters and an estimate of the
+// airtime used by them, compared with the time the hoppers spent there.
+type WiFiChannels struct {
+	sync.Mutex
+	counters map[int]*channelCounter
+}
+
+func NewWiFiChannels() *WiFiChannels {
+	c := &WiFiChannels{}
+	c.Reset()
+	return c
+}
+
+func (c *WiFiChannels) Reset() {
+	c.Lock()
+	defer c.Unlock()
+	c.counters = make(map[int]*channelCounter)
+}
+
+func (c *WiFiChannels) counter(frequency int) *channelCounter {
+	counter, found := c.counters[frequency]
+	if !found {
+		counter = &channelCounter{}
+		c.counters[frequency] = counter
+	}
+	return counter
+}
+
+// TrackFrame accounts a frame of the given size on the channel reported by
+// its radiotap header, the airtime is estimated from its data rate.
+func (c *WiFiChannels) TrackFrame(radiotap *layers.RadioTap, size int) {
+	frequency := int(radiotap.ChannelFrequency)
+	if frequency == 0 {
+		return
+	}
+
+	// the rate is in 500Kbps units, assume the slowest one if missing
+	bitsPerSecond := float64(radiotap.Rate) * 500000
+	if bitsPerSecond == 0 {
+		bitsPerSecond = 1000000
+	}
+	airtime := time.Duration(float64(size*8) / bitsPerSecond * float64(time.Second))
+
+	c.Lock()
+	defer c.Unlock()
+
+	counter := c.counter(frequency)
+	counter.frames++
+	counter.bytes += uint64(size)
+	counter.airtime += airtime
+}
+
+// TrackDwell accounts the time a channel hopper spent on frequency.
+func (c *WiFiChannels) TrackDwell(frequency int, dwell time.Duration) {
+	c.Lock()
+	defer c.Unlock()
+	c.counter(frequency).dwell += dwell
+}
+
+// Report returns the usage of every channel with some traffic or access
+// points on it, sorted by channel.
+func (c *WiFiChannels) Report(aps []*network.AccessPoint) []ChannelUsage {
+	usage := make(map[int]*ChannelUsage)
+	get := func(frequency int) *ChannelUsage {
+		u, found := usage[frequency]
+		if !found {
+			u = &ChannelUsage{
+				Channel:   network.Dot11Freq2Chan(frequency),
+				Frequency: frequency,
+			}
+			usage[frequency] = u
+		}
+		return u
+	}
+
+	c.Lock()
+	for frequency, counter := range c.counters {
+		u := get(frequency)
+		u.Frames = counter.frames
+		u.Bytes = counter.bytes
+		u.Airtime = counter.airtime.Seconds()
+		u.Dwell = counter.dwell.Seconds()
+		if counter.dwell > 0 {
+			u.Utilization = 100 * float64(counter.airtime) / float64(counter.dwell)
+			if u.Utilization > 100 {
+				u.Utilization = 100
+			}
+		}
+	}
+	c.Unlock()
+
+	for _, ap := range aps {
+		u := get(ap.Frequency)
+		u.APs++
+		u.Clients += ap.NumClients()
+	}
+
+	list := make([]ChannelUsage, 0, len(usage))
+	for _, u := range usage {
+		list = append(list, *u)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Frequency < list[j].Frequency
+	})
+
+	return list
+}
+
+// busiestChannel returns the channel with the highest utilization, or with
+// most frames if no dwell time has been tracked (i.e. reading from a file).
+func busiestChannel(list []ChannelUsage) (ChannelUsage, bool) {
+	var best ChannelUsage
+	found := false
+	for _, u := range list {
+		if u.Frames == 0 {
+			continue
+		} else if !found || u.Utilization > best.Utilization || (u.Utilization == best.Utilization && u.Frames > best.Frames) {
+			best = u
+			found = true
+		}
+	}
+	return best, found
+}
+
+func (w *WiFiModule) ShowChannels() error {
+	list := w.Channels.Report(w.Session.WiFi.List())
+	if len(list) == 0 {
+		return fmt.Errorf("No channel stats yet.")
+	}
+
+	rows := make([][]string, 0, len(list))
+	for _, u := range list {
+		util := core.Dim("-")
+		if u.Dwell > 0 {
+			util = fmt.Sprintf("%.1f%%", u.Utilization)
+			if u.Utilization >= 50 {
+				util = core.Red(util)
+			} else if u.Utilization >= 20 {
+				util = core.Yellow(util)
+			}
+		}
+
+		rows = append(rows, []string{
+			fmt.Sprintf("%d", u.Channel),
+			fmt.Sprintf("%d", u.Frequency),
+			fmt.Sprintf("%d", u.Frames),
+			humanize.Bytes(u.Bytes),
+			util,
+			fmt.Sprintf("%d", u.APs),
+			fmt.Sprintf("%d", u.Clients),
+		})
+	}
+
+	fmt.Println()
+	core.AsTable(os.Stdout, []string{"Channel", "Freq", "Frames", "Bytes", "Utilization", "APs", "Clients"}, rows)
+	fmt.Println()
+
+	w.Session.Refresh()
+
+	return nil
+}
+
+func (w *WiFiModule) RecommendChannel() error {
+	best, found := busiestChannel(w.Channels.Report(w.Session.WiFi.List()))
+	if !found {
+		return fmt.Errorf("No channel stats yet.")
+	}
+
+	fmt.Printf("\nThe busiest channel is %s (%.1f%% utilization, %d frames, %d access points, %d clients), use 'wifi.recon.channel %d' to stick on it.\n\n",
+		core.Bold(fmt.Sprintf("%d", best.Channel)),
+		best.Utilization,
+		best.Frames,
+		best.APs,
+		best.Clients,
+		best.Channel)
+
+	return nil
+}
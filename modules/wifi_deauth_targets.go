@@ -0,0 +1,202 @@
+package modules
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bettercap/bettercap/core"
+	"github.com/bettercap/bettercap/network"
+
+	"github.com/dustin/go-humanize"
+)
+
+// deauthFlow is a client to deauthenticate from an access point.
+type deauthFlow struct {
+	Ap     *network.AccessPoint
+	Client *network.Station
+}
+
+// coalesceDeauthFlows keeps a single flow per client, the one with the access
+// point the client was seen on last, since a client that roamed is still
+// listed with its previous access point for a while.
+func coalesceDeauthFlows(flows []deauthFlow) []deauthFlow {
+	latest := make(map[string]int)
+	coalesced := make([]deauthFlow, 0, len(flows))
+	for _, flow := range flows {
+		mac := flow.Client.HwAddress
+		if idx, found := latest[mac]; !found {
+			latest[mac] = len(coalesced)
+			coalesced = append(coalesced, flow)
+		} else if flow.Client.LastSeen.After(coalesced[idx].Client.LastSeen) {
+			coalesced[idx] = flow
+		}
+	}
+	return coalesced
+}
+
+// WiFiDeauthTarget is a client currently being deauthenticated.
+type WiFiDeauthTarget struct {
+	Client  string    `json:"client"`
+	Vendor  string    `json:"vendor"`
+	AP      string    `json:"ap"`
+	ESSID   string    `json:"essid"`
+	Channel int       `json:"channel"`
+	Frames  int       `json:"frames"`
+	Since   time.Time `json:"since"`
+	Updated time.Time `json:"updated"`
+
+	// how many running attacks are targeting the client
+	refs int
+}
+
+// WiFiDeauthTargets is the set of clients targeted by wifi.deauth and
+// wifi.deauth.roundrobin, each one counted once whatever the number of
+// attacks or access points it is targeted through.
+type WiFiDeauthTargets struct {
+	sync.Mutex
+	targets map[string]*WiFiDeauthTarget
+}
+
+func NewWiFiDeauthTargets() *WiFiDeauthTargets {
+	return &WiFiDeauthTargets{
+		targets: make(map[string]*WiFiDeauthTarget),
+	}
+}
+
+// Add puts the client of flow in the set, or takes one more reference to it
+// if it is already there, and returns true if it wasn't.
+func (t *WiFiDeauthTargets) Add(flow deauthFlow) bool {
+	t.Lock()
+	defer t.Unlock()
+
+	mac := flow.Client.HwAddress
+	if target, found := t.targets[mac]; found {
+		target.refs++
+		return false
+	}
+
+	now := time.Now()
+	t.targets[mac] = &WiFiDeauthTarget{
+		Client:  mac,
+		Vendor:  flow.Client.Vendor,
+		AP:      flow.Ap.BSSID(),
+		ESSID:   flow.Ap.ESSID(),
+		Channel: flow.Ap.Channel(),
+		Since:   now,
+		Updated: now,
+		refs:    1,
+	}
+	return true
+}
+
+// Sent accounts the frames sent to deauthenticate client from ap.
+func (t *WiFiDeauthTargets) Sent(ap *network.AccessPoint, client string, frames int) {
+	t.Lock()
+	defer t.Unlock()
+
+	if target, found := t.targets[client]; found {
+		target.Frames += frames
+		target.Updated = time.Now()
+		// the client might have roamed since it was added
+		target.AP = ap.BSSID()
+		target.ESSID = ap.ESSID()
+		target.Channel = ap.Channel()
+	}
+}
+
+// Remove drops a reference to client and returns true if it left the set.
+func (t *WiFiDeauthTargets) Remove(client string) bool {
+	t.Lock()
+	defer t.Unlock()
+
+	if target, found := t.targets[client]; found {
+		if target.refs--; target.refs <= 0 {
+			delete(t.targets, client)
+			return true
+		}
+	}
+	return false
+}
+
+// List returns a copy of the targets sorted by channel and client.
+func (t *WiFiDeauthTargets) List() []WiFiDeauthTarget {
+	t.Lock()
+	defer t.Unlock()
+
+	list := make([]WiFiDeauthTarget, 0, len(t.targets))
+	for _, target := range t.targets {
+		list = append(list, *target)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Channel != list[j].Channel {
+			return list[i].Channel < list[j].Channel
+		}
+		return list[i].Client < list[j].Client
+	})
+
+	return list
+}
+
+// addDeauthTargets adds the clients of flows to the active set, raising a
+// wifi.deauth.targets event if it changed.
+func (w *WiFiModule) addDeauthTargets(flows []deauthFlow) {
+	changed := false
+	for _, flow := range flows {
+		if w.DeauthTargets.Add(flow) {
+			changed = true
+		}
+	}
+	if changed {
+		w.Session.Events.Add("wifi.deauth.targets", w.DeauthTargets.List())
+	}
+}
+
+func (w *WiFiModule) removeDeauthTargets(flows []deauthFlow) {
+	changed := false
+	for _, flow := range flows {
+		if w.DeauthTargets.Remove(flow.Client.HwAddress) {
+			changed = true
+		}
+	}
+	if changed {
+		w.Session.Events.Add("wifi.deauth.targets", w.DeauthTargets.List())
+	}
+}
+
+func (w *WiFiModule) ShowDeauthTargets() error {
+	list := w.DeauthTargets.List()
+	if len(list) == 0 {
+		return fmt.Errorf("No deauth attack is running.")
+	}
+
+	rows := make([][]string, 0, len(list))
+	total := 0
+	for _, t := range list {
+		client := t.Client
+		if t.Vendor != "" {
+			client = fmt.Sprintf("%s (%s)", t.Client, t.Vendor)
+		}
+
+		rows = append(rows, []string{
+			client,
+			fmt.Sprintf("%s (%s)", core.Bold(t.ESSID), t.AP),
+			fmt.Sprintf("%d", t.Channel),
+			fmt.Sprintf("%d", t.Frames),
+			humanize.Time(t.Since),
+			humanize.Time(t.Updated),
+		})
+		total += t.Frames
+	}
+
+	fmt.Println()
+	core.AsTable(os.Stdout, []string{"Client", "AP", "Channel", "Frames", "Since", "Last Burst"}, rows)
+	fmt.Printf("  %d targets, %d frames sent.\n\n", len(list), total)
+
+	w.Session.Refresh()
+
+	return nil
+}
@@ -0,0 +1,190 @@
+package modules
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/bettercap/bettercap/core"
+	"github.com/bettercap/bettercap/log"
+	"github.com/bettercap/bettercap/network"
+	"github.com/bettercap/bettercap/packets"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+// the default beacon interval, 100 TU
+const dot11BeaconInterval = 100 * 1024 * time.Microsecond
+
+// WiFiChannelChange is the payload of wifi.ap.channel.changed events.
+type WiFiChannelChange struct {
+	BSSID  string `json:"bssid"`
+	ESSID  string `json:"essid"`
+	From   int    `json:"from"`
+	To     int    `json:"to"`
+	Reason string `json:"reason"`
+}
+
+// wifiFollow is the access point wifi.follow keeps the channel locked on.
+type wifiFollow struct {
+	sync.Mutex
+	bssid   net.HardwareAddr
+	lost    bool
+	pending int
+}
+
+func (w *WiFiModule) following() *wifiFollow {
+	w.followLock.Lock()
+	defer w.followLock.Unlock()
+	return w.follow
+}
+
+// Follow locks the channel of the access point and moves the lock along with
+// it when it switches channel.
+func (w *WiFiModule) Follow(bssid string) error {
+	hw, err := net.ParseMAC(bssid)
+	if err != nil {
+		return err
+	}
+
+	ap, found := w.Session.WiFi.Get(hw.String())
+	if !found {
+		return fmt.Errorf("access point %s not found, run wifi.recon on first", bssid)
+	} else if err := w.LockChannel(ap.Channel()); err != nil {
+		return err
+	}
+
+	f := &wifiFollow{bssid: hw}
+	w.followLock.Lock()
+	w.follow = f
+	w.followLock.Unlock()
+
+	log.Info("following %s (%s) on channel %d.", core.Bold(ap.ESSID()), ap.BSSID(), ap.Channel())
+
+	if w.Running() {
+		w.reads.Add(1)
+		go w.followWatcher(f)
+	}
+
+	return nil
+}
+
+func (w *WiFiModule) Unfollow() error {
+	w.followLock.Lock()
+	f := w.follow
+	w.follow = nil
+	w.followLock.Unlock()
+
+	if f == nil {
+		return fmt.Errorf("not following any access point")
+	}
+
+	log.Info("not following %s anymore.", f.bssid)
+	return nil
+}
+
+// followTo moves the channel lock to the new channel of the followed ap.
+func (w *WiFiModule) followTo(f *wifiFollow, ap *network.AccessPoint, channel int, reason string) {
+	f.Lock()
+	wasLost := f.lost
+	f.lost = false
+	f.pending = 0
+	f.Unlock()
+
+	from := ap.Channel()
+	if channel == w.lockedChannel() && channel == from && !wasLost {
+		return
+	} else if err := w.LockChannel(channel); err != nil {
+		log.Warning("can't follow %s to channel %d: %s", ap.BSSID(), channel, err)
+		return
+	}
+
+	if channel != from {
+		ap.Frequency = network.Dot11Chan2Freq(channel)
+		log.Info("%s moved from channel %d to %d (%s), following it.", core.Bold(ap.ESSID()), from, channel, reason)
+		w.Session.Events.Add("wifi.ap.channel.changed", WiFiChannelChange{
+			BSSID:  ap.BSSID(),
+			ESSID:  ap.ESSID(),
+			From:   from,
+			To:     channel,
+			Reason: reason,
+		})
+	}
+}
+
+// followAccessPoint looks for the beacons of the followed ap announcing a
+// channel switch or advertising a channel other than the locked one.
+func (w *WiFiModule) followAccessPoint(dot11 *layers.Dot11, packet gopacket.Packet) {
+	f := w.following()
+	if f == nil || !bytes.Equal(dot11.Address3, f.bssid) {
+		return
+	} else if dot11.Type != layers.Dot11TypeMgmtBeacon && dot11.Type != layers.Dot11TypeMgmtProbeResp {
+		return
+	}
+
+	ap, found := w.Session.WiFi.Get(f.bssid.String())
+	if !found {
+		return
+	}
+
+	locked := w.lockedChannel()
+	if found, channel, count := packets.Dot11ParseChannelSwitch(packet); found && channel != locked && network.Dot11Chan2Freq(channel) != 0 {
+		f.Lock()
+		scheduled := f.pending == channel
+		f.pending = channel
+		f.Unlock()
+
+		if !scheduled {
+			// keep capturing on the current channel until the switch
+			log.Debug("%s announced a switch to channel %d in %d beacons.", ap.BSSID(), channel, count)
+			time.AfterFunc(time.Duration(count)*dot11BeaconInterval, func() {
+				if w.following() == f {
+					w.followTo(f, ap, channel, "csa")
+				}
+			})
+		}
+	} else if found, channel := packets.Dot11ParseDSSet(packet); found && channel != 0 {
+		f.Lock()
+		pending := f.pending
+		f.Unlock()
+
+		if pending == 0 && channel != locked {
+			w.followTo(f, ap, channel, "beacon")
+		}
+	}
+}
+
+// followWatcher unlocks the channel when the followed ap is gone for longer
+// than wifi.follow.timeout, so that hopping can find it again, the caller
+// must add it to the reads wait group.
+func (w *WiFiModule) followWatcher(f *wifiFollow) {
+	defer w.reads.Done()
+
+	err, timeout := w.IntParam("wifi.follow.timeout")
+	if err != nil || timeout <= 0 {
+		timeout = 10
+	}
+
+	for w.Running() && w.following() == f {
+		time.Sleep(time.Second)
+
+		ap, found := w.Session.WiFi.Get(f.bssid.String())
+		if !found || time.Since(ap.LastSeen) < time.Duration(timeout)*time.Second {
+			continue
+		}
+
+		f.Lock()
+		lost := f.lost
+		f.lost = true
+		f.pending = 0
+		f.Unlock()
+
+		if channel := w.lockedChannel(); !lost && channel != 0 {
+			log.Warning("%s not seen on channel %d for %ds, hopping to find it again.", ap.BSSID(), channel, timeout)
+			w.UnlockChannel()
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package modules
+
+import (
+	"net"
+	"sync"
+
+	"github.com/bettercap/bettercap/log"
+	"github.com/bettercap/bettercap/packets"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+// WiFiHandshake is the payload of wifi.client.handshake events.
+type WiFiHandshake struct {
+	AP     net.HardwareAddr `json:"ap"`
+	ESSID  string           `json:"essid"`
+	Client net.HardwareAddr `json:"client"`
+	PMKID  bool             `json:"pmkid"`
+}
+
+// wifiHandshake is what has been captured of the handshake of a client, the
+// frames are kept along with their fields to be saved to the pcap file.
+type wifiHandshake struct {
+	// the nonce of the access point matching m2, from m1 or m3
+	anonce      *packets.EAPOLKey
+	anonceFrame []byte
+	m1          *packets.EAPOLKey
+	m1Frame     []byte
+	m2          *packets.EAPOLKey
+	m2Frame     []byte
+	m3          *packets.EAPOLKey
+	m3Frame     []byte
+	pmkid       *packets.EAPOLKey
+	pmkidFrame  []byte
+	// the PMKID has been saved, the 4-way handshake is still collected
+	pmkidSaved bool
+	// the 4-way handshake has been saved
+	captured bool
+}
+
+// pair returns the message of the access point with the nonce m2 has been
+// computed from, if any: m1 has the same replay counter as m2 and m3 the
+// next one, m1 is preferred.
+func (h *wifiHandshake) pair() (*packets.EAPOLKey, []byte) {
+	if h.m2 == nil {
+		return nil, nil
+	} else if h.m1 != nil && h.m1.ReplayCounter == h.m2.ReplayCounter {
+		return h.m1, h.m1Frame
+	} else if h.m3 != nil && h.m3.ReplayCounter == h.m2.ReplayCounter+1 {
+		return h.m3, h.m3Frame
+	}
+	return nil, nil
+}
+
+func (h *wifiHandshake) saved() bool {
+	return h.captured || h.pmkidSaved
+}
+
+// wifiHandshakes keeps track of the handshakes between each access point
+// and its clients.
+type wifiHandshakes struct {
+	sync.Mutex
+	clients map[string]map[string]*wifiHandshake
+}
+
+func newWiFiHandshakes() *wifiHandshakes {
+	return &wifiHandshakes{
+		clients: make(map[string]map[string]*wifiHandshake),
+	}
+}
+
+// Add records the EAPOL-Key frame of a client and returns what has to be
+// saved, if anything: either the PMKID, the first time it's seen, or the
+// 4-way handshake once a matching pair of messages is captured. Clients
+// already captured are ignored unless force is true, in which case their
+// handshake starts over.
+func (h *wifiHandshakes) Add(bssid string, client string, key *packets.EAPOLKey, frame []byte, force bool) (wifiHandshake, bool) {
+	h.Lock()
+	defer h.Unlock()
+
+	clients, found := h.clients[bssid]
+	if !found {
+		clients = make(map[string]*wifiHandshake)
+		h.clients[bssid] = clients
+	}
+
+	hs, found := clients[client]
+	if !found {
+		hs = &wifiHandshake{}
+		clients[client] = hs
+	}
+
+	if hs.captured {
+		if !force {
+			return wifiHandshake{}, false
+		}
+		*hs = wifiHandshake{}
+	}
+
+	switch key.Message {
+	case 1:
+		hs.m1, hs.m1Frame = key, frame
+		if key.PMKID != nil && hs.pmkid == nil {
+			hs.pmkid, hs.pmkidFrame = key, frame
+		}
+	case 2:
+		hs.m2, hs.m2Frame = key, frame
+	case 3:
+		hs.m3, hs.m3Frame = key, frame
+	}
+
+	if anonce, anonceFrame := hs.pair(); anonce != nil {
+		hs.anonce, hs.anonceFrame = anonce, anonceFrame
+		hs.captured = true
+		return wifiHandshake{
+			anonce:      anonce,
+			anonceFrame: anonceFrame,
+			m2:          hs.m2,
+			m2Frame:     hs.m2Frame,
+		}, true
+	} else if hs.pmkid != nil && !hs.pmkidSaved {
+		hs.pmkidSaved = true
+		return wifiHandshake{
+			pmkid:      hs.pmkid,
+			pmkidFrame: hs.pmkidFrame,
+		}, true
+	}
+	return wifiHandshake{}, false
+}
+
+// Reset forgets what has been captured of the access point handshakes and
+// returns how many of its clients were captured.
+func (h *wifiHandshakes) Reset(bssid string) int {
+	h.Lock()
+	defer h.Unlock()
+
+	captured := 0
+	for _, hs := range h.clients[bssid] {
+		if hs.saved() {
+			captured++
+		}
+	}
+	delete(h.clients, bssid)
+	return captured
+}
+
+// Captured returns how many clients of the access point were captured.
+func (h *wifiHandshakes) Captured(bssid string) int {
+	h.Lock()
+	defer h.Unlock()
+
+	captured := 0
+	for _, hs := range h.clients[bssid] {
+		if hs.saved() {
+			captured++
+		}
+	}
+	return captured
+}
+
+func (w *WiFiModule) discoverHandshakes(dot11 *layers.Dot11, packet gopacket.Packet) {
+	key := packets.Dot11ParseEAPOLKeyFrame(packet, dot11)
+	if key == nil {
+		return
+	}
+
+	bssid, client, ok := eapAddresses(dot11)
+	if !ok {
+		return
+	}
+
+	ap, found := w.Session.WiFi.Get(bssid.String())
+	if !found {
+		return
+	}
+
+	_, force := w.BoolParam("wifi.handshakes.force")
+	hs, captured := w.handshakes.Add(ap.BSSID(), client.String(), key, packet.Data(), force)
+	if !captured {
+		return
+	}
+
+	if err := w.exportHandshake(ap, client, hs); err != nil {
+		log.Error("could not save the handshake of %s: %s", client, err)
+	}
+
+	w.Session.Events.Add("wifi.client.handshake", WiFiHandshake{
+		AP:     ap.HW,
+		ESSID:  ap.ESSID(),
+		Client: client,
+		PMKID:  hs.pmkid != nil && hs.m2 == nil,
+	})
+}
@@ -0,0 +1,177 @@
+package modules
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bettercap/bettercap/core"
+	"github.com/bettercap/bettercap/network"
+	"github.com/bettercap/bettercap/packets"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+)
+
+func appendToPcap(fileName string, frames [][]byte) error {
+	fp, err := os.OpenFile(fileName, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer fp.Close()
+
+	writer := pcapgo.NewWriter(fp)
+	if info, err := fp.Stat(); err != nil {
+		return err
+	} else if info.Size() == 0 {
+		if err = writer.WriteFileHeader(65536, layers.LinkTypeIEEE80211Radio); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	for _, frame := range frames {
+		info := gopacket.CaptureInfo{
+			Timestamp:     now,
+			CaptureLength: len(frame),
+			Length:        len(frame),
+		}
+		if err = writer.WritePacket(info, frame); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func appendLine(fileName string, line string) error {
+	fp, err := os.OpenFile(fileName, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer fp.Close()
+
+	_, err = fmt.Fprintln(fp, line)
+	return err
+}
+
+// WiFiHandshakeSidecar describes a handshake saved to wifi.handshakes.file,
+// so that cracking scripts can pick the right mode without parsing the pcap,
+// Type is either "4-way" or "pmkid".
+type WiFiHandshakeSidecar struct {
+	BSSID          string    `json:"bssid"`
+	ESSID          string    `json:"essid"`
+	Client         string    `json:"client"`
+	Encryption     string    `json:"encryption"`
+	Cipher         string    `json:"cipher"`
+	Authentication string    `json:"authentication"`
+	Type           string    `json:"type"`
+	Pcap           string    `json:"pcap"`
+	Captured       time.Time `json:"captured"`
+}
+
+func newHandshakeSidecar(ap *network.AccessPoint, client net.HardwareAddr, hs wifiHandshake, pcapFile string) WiFiHandshakeSidecar {
+	kind := "pmkid"
+	if hs.m2 != nil {
+		kind = "4-way"
+	}
+
+	return WiFiHandshakeSidecar{
+		BSSID:          ap.BSSID(),
+		ESSID:          ap.ESSID(),
+		Client:         client.String(),
+		Encryption:     ap.Encryption,
+		Cipher:         ap.Cipher,
+		Authentication: ap.Authentication,
+		Type:           kind,
+		Pcap:           filepath.Base(pcapFile),
+		Captured:       time.Now(),
+	}
+}
+
+// handshakeSidecarName returns the name of the sidecar of the handshake of
+// client with bssid, next to the pcap file.
+func handshakeSidecarName(pcapFile string, bssid string, client string) string {
+	base := strings.TrimSuffix(pcapFile, filepath.Ext(pcapFile))
+	return fmt.Sprintf("%s_%s_%s.json", base, strings.Replace(bssid, ":", "", -1), strings.Replace(client, ":", "", -1))
+}
+
+func writeHandshakeSidecar(pcapFile string, sidecar WiFiHandshakeSidecar) (error, string) {
+	raw, err := json.MarshalIndent(sidecar, "", "  ")
+	if err != nil {
+		return err, ""
+	}
+
+	fileName := handshakeSidecarName(pcapFile, sidecar.BSSID, sidecar.Client)
+	return ioutil.WriteFile(fileName, raw, 0644), fileName
+}
+
+// exportHandshake saves a captured handshake to wifi.handshakes.file, with a
+// beacon so that aircrack-ng knows the ESSID, and to wifi.handshakes.hc22000
+// in the hashcat 22000 format, the PMKID and the 4-way handshake of each
+// client are only saved once and are described by a json sidecar next to
+// the pcap file.
+func (w *WiFiModule) exportHandshake(ap *network.AccessPoint, client net.HardwareAddr, hs wifiHandshake) error {
+	err, pcapFile := w.StringParam("wifi.handshakes.file")
+	if err != nil {
+		return err
+	}
+
+	err, hashFile := w.StringParam("wifi.handshakes.hc22000")
+	if err != nil {
+		return err
+	}
+
+	if pcapFile != "" {
+		if pcapFile, err = core.ExpandPath(pcapFile); err != nil {
+			return err
+		}
+
+		err, beacon := packets.NewDot11Beacon(packets.Dot11ApConfig{
+			SSID:       ap.ESSID(),
+			BSSID:      ap.HW,
+			Channel:    ap.Channel(),
+			Encryption: true,
+		}, 0)
+		if err != nil {
+			return err
+		}
+
+		frames := [][]byte{beacon}
+		if hs.m2 != nil {
+			frames = append(frames, hs.anonceFrame, hs.m2Frame)
+		} else {
+			frames = append(frames, hs.pmkidFrame)
+		}
+
+		if err = appendToPcap(pcapFile, frames); err != nil {
+			return err
+		} else if err, _ = writeHandshakeSidecar(pcapFile, newHandshakeSidecar(ap, client, hs, pcapFile)); err != nil {
+			return err
+		}
+	}
+
+	if hashFile != "" {
+		if hashFile, err = core.ExpandPath(hashFile); err != nil {
+			return err
+		}
+
+		line := ""
+		if hs.m2 != nil {
+			line = packets.Hashcat22000EAPOL(hs.anonce, hs.m2, ap.HW, client, ap.ESSID())
+		} else {
+			line = packets.Hashcat22000PMKID(hs.pmkid.PMKID, ap.HW, client, ap.ESSID())
+		}
+
+		if err = appendLine(hashFile, line); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
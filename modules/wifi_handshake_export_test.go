@@ -0,0 +1,65 @@
+package modules
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bettercap/bettercap/network"
+	"github.com/bettercap/bettercap/packets"
+)
+
+func TestHandshakeSidecarName(t *testing.T) {
+	got := handshakeSidecarName("/tmp/hs/handshakes.pcap", "aa:bb:cc:dd:ee:ff", "11:22:33:44:55:66")
+	if exp := "/tmp/hs/handshakes_aabbccddeeff_112233445566.json"; got != exp {
+		t.Fatalf("expected '%s', got '%s'", exp, got)
+	}
+}
+
+func TestWriteHandshakeSidecar(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bettercap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ap := network.NewAccessPoint("test", "aa:bb:cc:dd:ee:ff", 2412, -40)
+	ap.Encryption = "WPA2/WPA3"
+	ap.Authentication = "PSK/SAE"
+	client, _ := net.ParseMAC("11:22:33:44:55:66")
+	pcapFile := filepath.Join(dir, "handshakes.pcap")
+
+	var units = []struct {
+		hs   wifiHandshake
+		kind string
+	}{
+		{wifiHandshake{anonce: &packets.EAPOLKey{Message: 1}, m2: &packets.EAPOLKey{Message: 2}}, "4-way"},
+		{wifiHandshake{pmkid: &packets.EAPOLKey{Message: 1, PMKID: make([]byte, 16)}}, "pmkid"},
+	}
+
+	for _, u := range units {
+		err, fileName := writeHandshakeSidecar(pcapFile, newHandshakeSidecar(ap, client, u.hs, pcapFile))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		raw, err := ioutil.ReadFile(fileName)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sidecar := WiFiHandshakeSidecar{}
+		if err = json.Unmarshal(raw, &sidecar); err != nil {
+			t.Fatal(err)
+		} else if sidecar.Type != u.kind {
+			t.Fatalf("expected a %s capture, got %s", u.kind, sidecar.Type)
+		} else if sidecar.BSSID != "aa:bb:cc:dd:ee:ff" || sidecar.ESSID != "test" || sidecar.Client != "11:22:33:44:55:66" {
+			t.Fatalf("unexpected sidecar %+v", sidecar)
+		} else if sidecar.Encryption != "WPA2/WPA3" || sidecar.Pcap != "handshakes.pcap" {
+			t.Fatalf("unexpected sidecar %+v", sidecar)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package modules
+
+import (
+	"testing"
+
+	"github.com/bettercap/bettercap/packets"
+)
+
+func TestWiFiHandshakesReset(t *testing.T) {
+	h := newWiFiHandshakes()
+	bssid, client := "aa:bb:cc:dd:ee:ff", "11:22:33:44:55:66"
+
+	h.Add(bssid, client, &packets.EAPOLKey{Message: 1}, nil, false)
+	if _, captured := h.Add(bssid, client, &packets.EAPOLKey{Message: 2}, nil, false); !captured {
+		t.Fatal("expected the handshake to be captured")
+	} else if n := h.Captured(bssid); n != 1 {
+		t.Fatalf("expected 1 captured client, got %d", n)
+	}
+
+	// already captured, the same frames are ignored
+	h.Add(bssid, client, &packets.EAPOLKey{Message: 1}, nil, false)
+	if _, captured := h.Add(bssid, client, &packets.EAPOLKey{Message: 2}, nil, false); captured {
+		t.Fatal("expected the captured handshake to be skipped")
+	}
+
+	if n := h.Reset(bssid); n != 1 {
+		t.Fatalf("expected 1 reset client, got %d", n)
+	} else if n := h.Captured(bssid); n != 0 {
+		t.Fatalf("expected no captured clients, got %d", n)
+	}
+
+	h.Add(bssid, client, &packets.EAPOLKey{Message: 1}, nil, false)
+	if _, captured := h.Add(bssid, client, &packets.EAPOLKey{Message: 2}, nil, false); !captured {
+		t.Fatal("expected the handshake to be captured again after a reset")
+	}
+}
+
+func TestWiFiHandshakesForce(t *testing.T) {
+	h := newWiFiHandshakes()
+	bssid, client := "aa:bb:cc:dd:ee:ff", "11:22:33:44:55:66"
+
+	for i := 0; i < 2; i++ {
+		h.Add(bssid, client, &packets.EAPOLKey{Message: 1}, nil, true)
+		if _, captured := h.Add(bssid, client, &packets.EAPOLKey{Message: 2}, nil, true); !captured {
+			t.Fatalf("expected handshake %d to be captured", i+1)
+		}
+	}
+}
+
+func TestWiFiHandshakesReplayCounter(t *testing.T) {
+	h := newWiFiHandshakes()
+	bssid, client := "aa:bb:cc:dd:ee:ff", "11:22:33:44:55:66"
+
+	// the second message answers a newer first message we missed
+	h.Add(bssid, client, &packets.EAPOLKey{Message: 1, ReplayCounter: 1}, nil, false)
+	if _, captured := h.Add(bssid, client, &packets.EAPOLKey{Message: 2, ReplayCounter: 2}, nil, false); captured {
+		t.Fatal("expected messages of different handshakes not to be paired")
+	}
+
+	// the third message follows the second one
+	if _, captured := h.Add(bssid, client, &packets.EAPOLKey{Message: 3, ReplayCounter: 4}, nil, false); captured {
+		t.Fatal("expected a third message of another handshake not to be paired")
+	}
+	hs, captured := h.Add(bssid, client, &packets.EAPOLKey{Message: 3, ReplayCounter: 3}, nil, false)
+	if !captured {
+		t.Fatal("expected the second and third messages to be paired")
+	} else if hs.anonce.Message != 3 || hs.m2.ReplayCounter != 2 {
+		t.Fatalf("unexpected pair %+v %+v", hs.anonce, hs.m2)
+	}
+
+	// the first message is preferred over the third one
+	other := "11:22:33:44:55:77"
+	h.Add(other, client, &packets.EAPOLKey{Message: 3, ReplayCounter: 6}, nil, false)
+	h.Add(other, client, &packets.EAPOLKey{Message: 1, ReplayCounter: 5}, nil, false)
+	if hs, captured = h.Add(other, client, &packets.EAPOLKey{Message: 2, ReplayCounter: 5}, nil, false); !captured {
+		t.Fatal("expected the handshake to be captured")
+	} else if hs.anonce.Message != 1 {
+		t.Fatalf("expected the nonce of the first message, got message %d", hs.anonce.Message)
+	}
+}
+
+func TestWiFiHandshakesPMKID(t *testing.T) {
+	h := newWiFiHandshakes()
+	bssid, client := "aa:bb:cc:dd:ee:ff", "11:22:33:44:55:66"
+
+	pmkid := make([]byte, 16)
+	hs, captured := h.Add(bssid, client, &packets.EAPOLKey{Message: 1, PMKID: pmkid}, nil, false)
+	if !captured || hs.pmkid == nil || hs.m2 != nil {
+		t.Fatalf("expected the PMKID to be captured, got %+v", hs)
+	} else if n := h.Captured(bssid); n != 1 {
+		t.Fatalf("expected 1 captured client, got %d", n)
+	}
+
+	// the PMKID is only saved once
+	if _, captured = h.Add(bssid, client, &packets.EAPOLKey{Message: 1, PMKID: pmkid}, nil, false); captured {
+		t.Fatal("expected the PMKID not to be saved again")
+	}
+
+	// and the 4-way handshake is still captured
+	hs, captured = h.Add(bssid, client, &packets.EAPOLKey{Message: 2}, nil, false)
+	if !captured || hs.anonce == nil || hs.m2 == nil || hs.pmkid != nil {
+		t.Fatalf("expected the 4-way handshake to be captured after the PMKID, got %+v", hs)
+	}
+}
@@ -14,7 +14,7 @@ func (w *WiFiModule) onChannel(channel int, cb func()) {
 	prev := w.stickChan
 	w.stickChan = channel
 
-	if err := network.SetInterfaceChannel(w.Session.Interface.Name(), channel); err != nil {
+	if err := network.SetInterfaceChannel(w.mainInterface(), channel); err != nil {
 		log.Warning("error while hopping to channel %d: %s", channel, err)
 	} else {
 		log.Debug("hopped on channel %d", channel)
@@ -25,39 +25,58 @@ func (w *WiFiModule) onChannel(channel int, cb func()) {
 	w.stickChan = prev
 }
 
-func (w *WiFiModule) channelHopper() {
+func (w *WiFiModule) channelHopper(c *wifiCapture) {
 	w.reads.Add(1)
 	defer w.reads.Done()
 
-	log.Info("channel hopper started.")
+	isMain := w.isMainCapture(c)
+
+	log.Info("channel hopper started on %s.", c.name)
 
 	for w.Running() {
 		delay := w.hopPeriod
+		frequencies := w.hopFrequencies(c)
 		// if we have both 2.4 and 5ghz capabilities, we have
 		// more channels, therefore we need to increase the time
 		// we hop on each one otherwise me lose information
-		if len(w.frequencies) > 14 {
+		if len(frequencies) > 14 {
 			delay = delay * 2
 		}
 
-		frequencies := w.frequencies
 		for _, frequency := range frequencies {
+			// wifi.channel.lock is taking care of the channel
+			if locked := w.lockedChannel(); locked != 0 {
+				time.Sleep(delay)
+				w.Channels.TrackDwell(network.Dot11Chan2Freq(locked), delay)
+				if !w.Running() {
+					return
+				}
+				continue
+			}
+
 			channel := network.Dot11Freq2Chan(frequency)
 			// stick to the access point channel as long as it's selected
-			// or as long as we're deauthing on it
-			if w.stickChan != 0 {
-				channel = w.stickChan
+			// or as long as we're deauthing on it, other interfaces only
+			// do it if they support that channel
+			if stick := w.stickChan; stick != 0 && (isMain || c.supports(network.Dot11Chan2Freq(stick))) {
+				channel = stick
 			}
 
-			log.Debug("hopping on channel %d", channel)
+			log.Debug("hopping on channel %d (%s)", channel, c.name)
 
-			w.chanLock.Lock()
-			if err := network.SetInterfaceChannel(w.Session.Interface.Name(), channel); err != nil {
-				log.Warning("error while hopping to channel %d: %s", channel, err)
+			// only the main interface is shared with injection
+			if isMain {
+				w.chanLock.Lock()
+			}
+			if err := network.SetInterfaceChannel(c.name, channel); err != nil {
+				log.Warning("error while hopping %s to channel %d: %s", c.name, channel, err)
+			}
+			if isMain {
+				w.chanLock.Unlock()
 			}
-			w.chanLock.Unlock()
 
 			time.Sleep(delay)
+			w.Channels.TrackDwell(network.Dot11Chan2Freq(channel), delay)
 			if !w.Running() {
 				return
 			}
@@ -0,0 +1,99 @@
+package modules
+
+import (
+	"net"
+	"strings"
+
+	"github.com/bettercap/bettercap/packets"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+const (
+	eapIdentityMeta = "eap:identity"
+	eapMethodMeta   = "eap:method"
+)
+
+type WiFiClientIdentity struct {
+	Client       net.HardwareAddr
+	ClientVendor string
+	AP           net.HardwareAddr
+	ESSID        string
+	Identity     string
+	Method       string
+	Anonymous    bool
+}
+
+// isAnonymousIdentity tells if an EAP identity is only an outer one used to
+// route the request to the right realm (RFC 7542), the real one being sent
+// inside the tunnel.
+func isAnonymousIdentity(identity string) bool {
+	identity = strings.ToLower(strings.TrimSpace(identity))
+	return identity == "" || identity == "anonymous" || strings.HasPrefix(identity, "anonymous@") || strings.HasPrefix(identity, "@")
+}
+
+// eapAddresses returns the BSSID and the client address of a data frame.
+func eapAddresses(dot11 *layers.Dot11) (bssid net.HardwareAddr, client net.HardwareAddr, ok bool) {
+	if dot11.Flags.ToDS() && !dot11.Flags.FromDS() {
+		return dot11.Address1, dot11.Address2, true
+	} else if dot11.Flags.FromDS() && !dot11.Flags.ToDS() {
+		return dot11.Address2, dot11.Address1, true
+	}
+	return nil, nil, false
+}
+
+func (w *WiFiModule) discoverIdentities(radiotap *layers.RadioTap, dot11 *layers.Dot11, packet gopacket.Packet) {
+	ok, eap := packets.Dot11ParseEAP(packet, dot11)
+	if !ok {
+		return
+	}
+
+	bssid, client, ok := eapAddresses(dot11)
+	if !ok {
+		return
+	}
+
+	ap, found := w.Session.WiFi.Get(bssid.String())
+	if !found {
+		return
+	}
+
+	station, found := ap.Get(client.String())
+	if !found {
+		station = ap.AddClient(client.String(), int(radiotap.ChannelFrequency), radiotap.DBMAntennaSignal)
+	}
+
+	identity, _ := station.Meta.GetOr(eapIdentityMeta, "").(string)
+	method, _ := station.Meta.GetOr(eapMethodMeta, "").(string)
+	changed := false
+
+	if eap.Code == layers.EAPCodeResponse && eap.Type == layers.EAPTypeIdentity {
+		// don't replace a real identity with an anonymous outer one
+		if got := strings.TrimRight(string(eap.TypeData), "\x00"); got != identity && (identity == "" || !isAnonymousIdentity(got)) {
+			identity = got
+			changed = true
+			station.Meta.Set(eapIdentityMeta, identity)
+		}
+	} else if (eap.Code == layers.EAPCodeRequest || eap.Code == layers.EAPCodeResponse) && eap.Type > layers.EAPTypeNACK {
+		// the first method request from the server (or the one the client
+		// answered with) is the one being negotiated
+		if got := packets.EAPMethodName(eap.Type); got != method {
+			method = got
+			changed = identity != ""
+			station.Meta.Set(eapMethodMeta, method)
+		}
+	}
+
+	if changed {
+		w.Session.Events.Add("wifi.client.identity", WiFiClientIdentity{
+			Client:       client,
+			ClientVendor: station.Vendor,
+			AP:           ap.HW,
+			ESSID:        ap.ESSID(),
+			Identity:     identity,
+			Method:       method,
+			Anonymous:    isAnonymousIdentity(identity),
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package modules
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bettercap/bettercap/core"
+	"github.com/bettercap/bettercap/log"
+	"github.com/bettercap/bettercap/network"
+	"github.com/bettercap/bettercap/packets"
+	"github.com/bettercap/bettercap/session"
+)
+
+// how long to wait for a probe response after the last request
+const probeGracePeriod = 2 * time.Second
+
+// loadProbeWordlist returns the SSIDs in fileName, skipping the ones longer
+// than the 32 bytes an SSID can be.
+func loadProbeWordlist(fileName string) (error, []string) {
+	fileName, err := core.ExpandPath(fileName)
+	if err != nil {
+		return err, nil
+	}
+
+	fp, err := os.Open(fileName)
+	if err != nil {
+		return err, nil
+	}
+	defer fp.Close()
+
+	names := make([]string, 0)
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(fp)
+	for scanner.Scan() {
+		name := strings.TrimRight(scanner.Text(), "\r")
+		if name != "" && len(name) <= 32 && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		return err, nil
+	} else if len(names) == 0 {
+		return fmt.Errorf("%s does not contain any SSID", fileName), nil
+	}
+
+	return nil, names
+}
+
+func (w *WiFiModule) startProbe(bssid net.HardwareAddr) error {
+	var err error
+	var wordlist string
+	var rate int
+	var names []string
+
+	if !w.Running() {
+		return fmt.Errorf("Module wifi.probe requires module wifi.recon to be activated.")
+	} else if w.probing {
+		return session.ErrAlreadyStarted
+	} else if err, wordlist = w.StringParam("wifi.probe.wordlist"); err != nil {
+		return err
+	} else if wordlist == "" {
+		return fmt.Errorf("wifi.probe.wordlist is empty")
+	} else if err, rate = w.IntParam("wifi.probe.rate"); err != nil {
+		return err
+	} else if rate < 1 {
+		return fmt.Errorf("wifi.probe.rate must be greater than 0")
+	} else if err, names = loadProbeWordlist(wordlist); err != nil {
+		return err
+	}
+
+	ap, found := w.Session.WiFi.Get(bssid.String())
+	if !found {
+		return fmt.Errorf("Could not find station with BSSID %s", bssid)
+	} else if ap.ESSID() != network.HiddenESSID {
+		return fmt.Errorf("%s is not hidden, its SSID is %s", bssid, ap.ESSID())
+	}
+
+	w.probing = true
+	w.writes.Add(1)
+	go func() {
+		defer w.writes.Done()
+		defer func() {
+			w.probing = false
+		}()
+
+		period := time.Second / time.Duration(rate)
+		// the responses are only seen if the adapter stays on the same
+		// channel of the access point
+		w.onChannel(ap.Channel(), func() {
+			log.Info("probing hidden access point %s (channel %d) with %d names from %s ...", bssid, ap.Channel(), len(names), wordlist)
+
+			sent := 0
+			revealed := func() bool {
+				return ap.ESSID() != network.HiddenESSID
+			}
+
+			for seq, name := range names {
+				if !w.probing || !w.WaitIfPaused() || revealed() {
+					break
+				}
+
+				if err, pkt := packets.NewDot11ProbeRequest(w.Session.Interface.HW, bssid, name, uint16(seq)); err != nil {
+					log.Error("could not create probe request: %s", err)
+				} else if err = w.writePacket(pkt); err != nil {
+					log.Error("could not inject probe request: %s", err)
+				} else {
+					sent++
+				}
+
+				time.Sleep(period)
+			}
+
+			for waited := time.Duration(0); waited < probeGracePeriod && !revealed() && w.probing && w.Running(); waited += period {
+				time.Sleep(period)
+			}
+
+			if revealed() {
+				log.Info("hidden access point %s is %s (%d probes sent).", bssid, core.Bold(ap.ESSID()), sent)
+			} else {
+				log.Info("no answer from %s after %d probes.", bssid, sent)
+			}
+		})
+	}()
+
+	return nil
+}
+
+func (w *WiFiModule) stopProbe() error {
+	if !w.probing {
+		return session.ErrAlreadyStopped
+	}
+	w.probing = false
+	return nil
+}
@@ -0,0 +1,125 @@
+package modules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bettercap/bettercap/core"
+	"github.com/bettercap/bettercap/log"
+	"github.com/bettercap/bettercap/network"
+)
+
+// wifiRadio remembers what wifi.region and wifi.txpower changed, so that
+// it can be undone when the module stops.
+type wifiRadio struct {
+	// previous regulatory domain, empty if it wasn't changed
+	region string
+	// TX power of the interfaces before it was set, 0 if it couldn't be read
+	txPower map[string]float64
+}
+
+// applyRegion sets the regulatory domain before the captures are opened,
+// since it decides which frequencies they support.
+func (w *WiFiModule) applyRegion() error {
+	err, region := w.StringParam("wifi.region")
+	if err != nil || region == "" {
+		return err
+	}
+	region = strings.ToUpper(region)
+
+	prev, err := network.GetRegulatoryDomain()
+	if err != nil {
+		return err
+	} else if prev == region {
+		return nil
+	} else if err = network.SetRegulatoryDomain(region); err != nil {
+		return err
+	}
+
+	w.radio.region = prev
+
+	// self managed drivers ignore the requested domain and keep their own
+	if curr, err := network.GetRegulatoryDomain(); err == nil && curr != region {
+		log.Warning("regulatory domain set to %s but the kernel reports %s, the driver might enforce its own.", region, curr)
+	} else {
+		log.Info("regulatory domain set to %s (was %s).", core.Bold(region), prev)
+	}
+
+	return nil
+}
+
+// applyTxPower sets the TX power of every capture interface, warning if it
+// is more than the regulatory domain allows or if the driver clamped it.
+func (w *WiFiModule) applyTxPower() error {
+	err, power := w.IntParam("wifi.txpower")
+	if err != nil || power <= 0 {
+		return err
+	}
+
+	for _, c := range w.captures {
+		if powers, err := network.GetMaxTxPower(c.name); err != nil {
+			log.Debug("could not read the allowed TX power of %s: %s", c.name, err)
+		} else {
+			highest := 0.0
+			for _, p := range powers {
+				if p > highest {
+					highest = p
+				}
+			}
+			if highest > 0 && float64(power) > highest {
+				log.Warning("%s allows at most %.0f dBm in the current regulatory domain, %d dBm will likely be clamped.", c.name, highest, power)
+			}
+		}
+
+		prev, err := network.GetInterfaceTxPower(c.name)
+		if err != nil {
+			log.Debug("could not read the TX power of %s: %s", c.name, err)
+			prev = 0
+		}
+
+		if err := network.SetInterfaceTxPower(c.name, power); err != nil {
+			log.Warning("could not set the TX power of %s to %d dBm: %s", c.name, power, err)
+			continue
+		}
+
+		if w.radio.txPower == nil {
+			w.radio.txPower = make(map[string]float64)
+		}
+		// keep the original power if it was already changed
+		if _, found := w.radio.txPower[c.name]; !found {
+			w.radio.txPower[c.name] = prev
+		}
+
+		if curr, err := network.GetInterfaceTxPower(c.name); err == nil && curr+0.5 < float64(power) {
+			log.Warning("%s clamped the TX power to %.0f dBm instead of %d dBm.", c.name, curr, power)
+		} else {
+			log.Info("TX power of %s set to %s.", c.name, core.Bold(fmt.Sprintf("%d dBm", power)))
+		}
+	}
+
+	return nil
+}
+
+// restoreRadio sets the previous TX power and regulatory domain back, if
+// the TX power couldn't be read before changing it the driver picks it.
+func (w *WiFiModule) restoreRadio() {
+	for name, prev := range w.radio.txPower {
+		if prev > 0 {
+			if err := network.SetInterfaceTxPower(name, int(prev+0.5)); err != nil {
+				log.Warning("could not restore the TX power of %s to %.0f dBm: %s", name, prev, err)
+			}
+		} else if err := network.ResetInterfaceTxPower(name); err != nil {
+			log.Warning("could not restore the TX power of %s: %s", name, err)
+		}
+	}
+	w.radio.txPower = nil
+
+	if w.radio.region != "" {
+		if err := network.SetRegulatoryDomain(w.radio.region); err != nil {
+			log.Warning("could not restore the regulatory domain %s: %s", w.radio.region, err)
+		} else {
+			log.Info("regulatory domain restored to %s.", w.radio.region)
+		}
+		w.radio.region = ""
+	}
+}
@@ -0,0 +1,47 @@
+package modules
+
+import (
+	"fmt"
+
+	"github.com/bettercap/bettercap/network"
+	"github.com/bettercap/bettercap/packets"
+
+	"github.com/google/gopacket/layers"
+)
+
+// transmitter returns the station which sent a data frame, either an
+// access point or one of its clients.
+func (w *WiFiModule) transmitter(dot11 *layers.Dot11) (*network.Station, bool) {
+	src := dot11.Address2.String()
+	if ap, found := w.Session.WiFi.Get(src); found {
+		return ap.Station, true
+	}
+
+	bssid, client, ok := eapAddresses(dot11)
+	if !ok || client.String() != src {
+		return nil, false
+	} else if ap, found := w.Session.WiFi.Get(bssid.String()); found {
+		return ap.Get(src)
+	}
+	return nil, false
+}
+
+// trackRates accounts the data rate of each data frame to the station which
+// sent it.
+func (w *WiFiModule) trackRates(radiotap *layers.RadioTap, dot11 *layers.Dot11) {
+	if !w.rates || dot11.Type.MainType() != layers.Dot11TypeData {
+		return
+	} else if rate, ok := packets.Dot11RateLabel(radiotap); !ok {
+		return
+	} else if station, found := w.transmitter(dot11); found {
+		station.Rates.Add(rate)
+	}
+}
+
+// rateColumn is the dominant rate of station as shown by wifi.show.
+func rateColumn(station *network.Station) string {
+	if rate, share := station.Rates.Dominant(); rate != "" {
+		return fmt.Sprintf("%s (%.0f%%)", rate, share*100)
+	}
+	return ""
+}
@@ -35,6 +35,7 @@ func (w *WiFiModule) stationPruner() {
 			if sinceLastSeen > maxStationTTL {
 				log.Debug("Station %s not seen in %s, removing.", ap.BSSID(), sinceLastSeen)
 				w.Session.WiFi.Remove(ap.BSSID())
+				delete(w.crowded, ap.BSSID())
 				continue
 			}
 			// loop every AP client
@@ -45,12 +46,29 @@ func (w *WiFiModule) stationPruner() {
 					ap.RemoveClient(c.BSSID())
 				}
 			}
+			w.checkCrowded(ap)
 		}
 		time.Sleep(1 * time.Second)
 	}
 }
 
-func (w *WiFiModule) discoverAccessPoints(radiotap *layers.RadioTap, dot11 *layers.Dot11, packet gopacket.Packet) {
+func (w *WiFiModule) checkCrowded(ap *network.AccessPoint) {
+	if w.clientsAlert <= 0 {
+		return
+	}
+
+	bssid := ap.BSSID()
+	clients := ap.NumClients()
+	if !w.crowded[bssid] && clients > w.clientsAlert {
+		w.crowded[bssid] = true
+		w.Session.Events.Add("wifi.ap.crowded", ap)
+	} else if w.crowded[bssid] && clients <= w.clientsAlert*8/10 {
+		// some hysteresis so we don't flap around the threshold
+		delete(w.crowded, bssid)
+	}
+}
+
+func (w *WiFiModule) discoverAccessPoints(c *wifiCapture, radiotap *layers.RadioTap, dot11 *layers.Dot11, packet gopacket.Packet) {
 	// search for Dot11InformationElementIDSSID
 	if ok, ssid := packets.Dot11ParseIDSSID(packet); ok {
 		from := dot11.Address3
@@ -70,7 +88,18 @@ func (w *WiFiModule) discoverAccessPoints(radiotap *layers.RadioTap, dot11 *laye
 				frequency = int(radiotap.ChannelFrequency)
 			}
 
+			hidden := false
+			if ap, found := w.Session.WiFi.Get(bssid); found {
+				hidden = ap.ESSID() == network.HiddenESSID
+			}
+
 			w.Session.WiFi.AddIfNew(ssid, bssid, frequency, radiotap.DBMAntennaSignal)
+			if ap, found := w.Session.WiFi.Get(bssid); found {
+				ap.Interface = c.name
+				if hidden && ap.ESSID() != network.HiddenESSID {
+					w.Session.Events.Add("wifi.ap.uncloaked", ap)
+				}
+			}
 		}
 	}
 }
@@ -104,16 +133,18 @@ func (w *WiFiModule) discoverProbes(radiotap *layers.RadioTap, dot11 *layers.Dot
 		return
 	}
 
-	w.Session.Events.Add("wifi.client.probe", WiFiProbe{
+	// clients keep probing for the same networks several times a second
+	ssid := string(req.Contents[2 : 2+size])
+	w.Session.Events.Coalesce("wifi.client.probe", dot11.Address2.String()+"/"+ssid, WiFiProbe{
 		FromAddr:   dot11.Address2,
 		FromVendor: network.ManufLookup(dot11.Address2.String()),
 		FromAlias:  w.Session.Lan.GetAlias(dot11.Address2.String()),
-		SSID:       string(req.Contents[2 : 2+size]),
+		SSID:       ssid,
 		RSSI:       radiotap.DBMAntennaSignal,
 	})
 }
 
-func (w *WiFiModule) discoverClients(radiotap *layers.RadioTap, dot11 *layers.Dot11, packet gopacket.Packet) {
+func (w *WiFiModule) discoverClients(c *wifiCapture, radiotap *layers.RadioTap, dot11 *layers.Dot11, packet gopacket.Packet) {
 	w.Session.WiFi.EachAccessPoint(func(bssid string, ap *network.AccessPoint) {
 		// packet going to this specific BSSID?
 		if packets.Dot11IsDataFor(dot11, ap.HW) {
@@ -39,7 +39,7 @@ func (w *WiFiModule) getRow(station *network.Station) ([]string, bool) {
 	}
 
 	ssid := station.ESSID()
-	if ssid == "<hidden>" {
+	if ssid == network.HiddenESSID {
 		ssid = core.Dim(ssid)
 	}
 
@@ -63,18 +63,13 @@ func (w *WiFiModule) getRow(station *network.Station) ([]string, bool) {
 	}
 
 	if w.source == "" {
-		for _, frequencies := range w.frequencies {
-			if frequencies == station.Frequency {
-				include = true
-				break
-			}
-		}
+		include = w.isMonitored(station.Frequency)
 	} else {
 		include = true
 	}
 
 	if w.isApSelected() {
-		return []string{
+		row := []string{
 			fmt.Sprintf("%d dBm", station.RSSI),
 			bssid,
 			/* station.Vendor, */
@@ -82,7 +77,14 @@ func (w *WiFiModule) getRow(station *network.Station) ([]string, bool) {
 			sent,
 			recvd,
 			seen,
-		}, include
+		}
+		if w.rates {
+			row = append(row, rateColumn(station))
+		}
+		if w.multiCapture() {
+			row = append(row, station.Interface)
+		}
+		return row, include
 	} else {
 		// this is ugly, but necessary in order to have this
 		// method handle both access point and clients
@@ -94,7 +96,7 @@ func (w *WiFiModule) getRow(station *network.Station) ([]string, bool) {
 			}
 		}
 
-		return []string{
+		row := []string{
 			fmt.Sprintf("%d dBm", station.RSSI),
 			bssid,
 			ssid,
@@ -105,7 +107,14 @@ func (w *WiFiModule) getRow(station *network.Station) ([]string, bool) {
 			sent,
 			recvd,
 			seen,
-		}, include
+		}
+		if w.rates {
+			row = append(row, rateColumn(station))
+		}
+		if w.multiCapture() {
+			row = append(row, station.Interface)
+		}
+		return row, include
 	}
 }
 
@@ -153,6 +162,13 @@ func (w *WiFiModule) Show(by string) error {
 		}
 	}
 
+	if w.rates {
+		columns = append(columns, "Rate")
+	}
+	if w.multiCapture() {
+		columns = append(columns, "Interface")
+	}
+
 	if nrows > 0 {
 		core.AsTable(os.Stdout, columns, rows)
 	}
@@ -0,0 +1,55 @@
+package modules
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bettercap/bettercap/log"
+	"github.com/bettercap/bettercap/network"
+)
+
+// stationSignal returns the last RSSI of an access point or client station.
+func (w *WiFiModule) stationSignal(mac string) (int, time.Time, bool) {
+	if ap, found := w.Session.WiFi.Get(mac); found {
+		return int(ap.RSSI), ap.LastSeen, true
+	} else if client, found := w.Session.WiFi.GetClient(mac); found {
+		return int(client.RSSI), client.LastSeen, true
+	}
+	return 0, time.Time{}, false
+}
+
+func (w *WiFiModule) Track(mac string) error {
+	mac = network.NormalizeMac(mac)
+	err, tracker := newTracker(&w.SessionModule, "wifi", mac)
+	if err != nil {
+		return err
+	}
+
+	if w.tracker != nil {
+		w.tracker.Stop()
+	}
+	w.tracker = tracker
+
+	if _, _, found := w.stationSignal(mac); !found {
+		log.Warning("%s has not been seen yet, it will be tracked as soon as it shows up.", mac)
+	}
+	log.Info("tracking %s, hot above %d dBm.", mac, tracker.threshold)
+
+	go tracker.Run(w.Session, func() (int, time.Time, bool) {
+		return w.stationSignal(mac)
+	})
+
+	return nil
+}
+
+func (w *WiFiModule) StopTracking() error {
+	if w.tracker == nil {
+		return fmt.Errorf("no station is being tracked")
+	}
+
+	log.Info("stopped tracking %s.", w.tracker.address)
+	w.tracker.Stop()
+	w.tracker = nil
+
+	return nil
+}
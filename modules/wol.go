@@ -116,7 +116,7 @@ func (w *WOL) wolETH(mac string) error {
 	}
 
 	raw = append(raw, payload...)
-	return w.Session.Queue.Send(raw)
+	return w.Send(raw)
 }
 
 func (w *WOL) wolUDP(mac string) error {
@@ -153,5 +153,5 @@ func (w *WOL) wolUDP(mac string) error {
 	}
 
 	raw = append(raw, payload...)
-	return w.Session.Queue.Send(raw)
+	return w.Send(raw)
 }
@@ -0,0 +1,111 @@
+package network
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	NeighborIncomplete = "incomplete"
+	NeighborComplete   = "complete"
+	NeighborPermanent  = "permanent"
+
+	// ATF_COM and ATF_PERM from linux/if_arp.h
+	arpFlagComplete  = 0x2
+	arpFlagPermanent = 0x4
+)
+
+// the kernel ARP table, only available on Linux
+var ProcArpFile = "/proc/net/arp"
+
+// Neighbor is an entry of the ARP table of the operating system.
+type Neighbor struct {
+	IpAddress string `json:"ipv4"`
+	HwAddress string `json:"mac"`
+	Interface string `json:"interface"`
+	State     string `json:"state"`
+}
+
+// ParseProcArp parses the contents of /proc/net/arp, filtering the entries
+// of iface if it's not empty.
+func ParseProcArp(data string, iface string) ([]Neighbor, error) {
+	lines := strings.Split(strings.TrimSpace(data), "\n")
+	if len(lines) == 0 || !strings.HasPrefix(lines[0], "IP address") {
+		return nil, fmt.Errorf("unexpected ARP table header")
+	}
+
+	neighbors := make([]Neighbor, 0)
+	for _, line := range lines[1:] {
+		fields := strings.Fields(line)
+		if len(fields) < 6 {
+			continue
+		} else if iface != "" && fields[5] != iface {
+			continue
+		}
+
+		flags, err := strconv.ParseUint(strings.TrimPrefix(fields[2], "0x"), 16, 32)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected flags '%s' for %s", fields[2], fields[0])
+		}
+
+		state := NeighborIncomplete
+		if flags&arpFlagPermanent != 0 {
+			state = NeighborPermanent
+		} else if flags&arpFlagComplete != 0 {
+			state = NeighborComplete
+		}
+
+		neighbors = append(neighbors, Neighbor{
+			IpAddress: fields[0],
+			HwAddress: NormalizeMac(fields[3]),
+			Interface: fields[5],
+			State:     state,
+		})
+	}
+
+	return neighbors, nil
+}
+
+func neighborOrder(n Neighbor) uint32 {
+	if ip := net.ParseIP(n.IpAddress).To4(); ip != nil {
+		return ip2int(ip)
+	}
+	return 0
+}
+
+// Neighbors returns the ARP table of the operating system for iface, read
+// from the kernel if possible or from the output of the ArpCmd otherwise.
+func Neighbors(iface string) ([]Neighbor, error) {
+	var neighbors []Neighbor
+
+	if data, err := ioutil.ReadFile(ProcArpFile); err == nil {
+		if neighbors, err = ParseProcArp(string(data), iface); err != nil {
+			return nil, err
+		}
+	} else {
+		table, err := ArpUpdate(iface)
+		if err != nil {
+			return nil, err
+		}
+
+		neighbors = make([]Neighbor, 0, len(table))
+		for ip, mac := range table {
+			neighbors = append(neighbors, Neighbor{
+				IpAddress: ip,
+				HwAddress: NormalizeMac(mac),
+				Interface: iface,
+				State:     NeighborComplete,
+			})
+		}
+	}
+
+	sort.Slice(neighbors, func(i, j int) bool {
+		return neighborOrder(neighbors[i]) < neighborOrder(neighbors[j])
+	})
+
+	return neighbors, nil
+}
@@ -0,0 +1,40 @@
+package network
+
+import (
+	"testing"
+)
+
+const exampleProcArp = `IP address       HW type     Flags       HW address            Mask     Device
+192.168.1.1      0x1         0x2         AA:BB:CC:DD:EE:01     *        eth0
+192.168.1.20     0x1         0x0         00:00:00:00:00:00     *        eth0
+192.168.1.30     0x1         0x6         aa:bb:cc:dd:ee:03     *        eth0
+10.0.0.5         0x1         0x2         aa:bb:cc:dd:ee:04     *        wlan0
+`
+
+func TestParseProcArp(t *testing.T) {
+	neighbors, err := ParseProcArp(exampleProcArp, "eth0")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	} else if len(neighbors) != 3 {
+		t.Fatalf("expected 3 neighbors on eth0, got %+v", neighbors)
+	}
+
+	expected := []Neighbor{
+		{"192.168.1.1", "aa:bb:cc:dd:ee:01", "eth0", NeighborComplete},
+		{"192.168.1.20", "00:00:00:00:00:00", "eth0", NeighborIncomplete},
+		{"192.168.1.30", "aa:bb:cc:dd:ee:03", "eth0", NeighborPermanent},
+	}
+	for i, n := range neighbors {
+		if n != expected[i] {
+			t.Fatalf("expected %+v, got %+v", expected[i], n)
+		}
+	}
+
+	if all, err := ParseProcArp(exampleProcArp, ""); err != nil || len(all) != 4 {
+		t.Fatalf("expected 4 neighbors, got %+v (%v)", all, err)
+	}
+
+	if _, err := ParseProcArp("garbage", ""); err == nil {
+		t.Fatal("expected an error for an invalid table")
+	}
+}
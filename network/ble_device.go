@@ -11,38 +11,47 @@ import (
 )
 
 type BLEDevice struct {
+	FirstSeen     time.Time
 	LastSeen      time.Time
 	Vendor        string
 	RSSI          int
 	Device        gatt.Peripheral
 	Advertisement *gatt.Advertisement
+	Meta          *Meta
 }
 
 type bleDeviceJSON struct {
-	LastSeen time.Time `json:"last_seen"`
-	Name     string    `json:"name"`
-	MAC      string    `json:"mac"`
-	Vendor   string    `json:"vendor"`
-	RSSI     int       `json:"rssi"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+	Name      string    `json:"name"`
+	MAC       string    `json:"mac"`
+	Vendor    string    `json:"vendor"`
+	RSSI      int       `json:"rssi"`
+	Meta      *Meta     `json:"meta"`
 }
 
 func NewBLEDevice(p gatt.Peripheral, a *gatt.Advertisement, rssi int) *BLEDevice {
+	now := time.Now()
 	return &BLEDevice{
-		LastSeen:      time.Now(),
+		FirstSeen:     now,
+		LastSeen:      now,
 		Device:        p,
 		Vendor:        ManufLookup(NormalizeMac(p.ID())),
 		Advertisement: a,
 		RSSI:          rssi,
+		Meta:          NewMeta(),
 	}
 }
 
 func (d *BLEDevice) MarshalJSON() ([]byte, error) {
 	doc := bleDeviceJSON{
-		LastSeen: d.LastSeen,
-		Name:     d.Device.Name(),
-		MAC:      d.Device.ID(),
-		Vendor:   d.Vendor,
-		RSSI:     d.RSSI,
+		FirstSeen: d.FirstSeen,
+		LastSeen:  d.LastSeen,
+		Name:      d.Device.Name(),
+		MAC:       d.Device.ID(),
+		Vendor:    d.Vendor,
+		RSSI:      d.RSSI,
+		Meta:      d.Meta,
 	}
 
 	return json.Marshal(doc)
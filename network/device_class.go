@@ -0,0 +1,258 @@
+package network
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	DevicePhone   = "phone"
+	DeviceLaptop  = "laptop"
+	DeviceIoT     = "iot"
+	DevicePrinter = "printer"
+
+	dhcpFingerprintMeta = "dhcp:fingerprint"
+	userAgentMeta       = "http:useragent"
+	mdnsServicesMeta    = "mdns:services"
+)
+
+// DeviceSignals are the passively observed hints used to classify a device:
+// the DHCP option 55 (parameter request list) of its requests, the
+// User-Agent of its plain text HTTP requests and the mDNS service types it
+// announces.
+type DeviceSignals struct {
+	DHCPFingerprint string
+	UserAgent       string
+	MDNSServices    []string
+}
+
+// DeviceClass is a purely heuristic guess of the kind of device, Reasons
+// lists which signals agreed on it.
+type DeviceClass struct {
+	Category   string   `json:"category"`
+	Confidence int      `json:"confidence"`
+	Reasons    []string `json:"reasons"`
+}
+
+type deviceHint struct {
+	category   string
+	confidence int
+	reason     string
+}
+
+// well known option 55 lists of the default DHCP clients
+var dhcpFingerprints = map[string]deviceHint{
+	"1,121,3,6,15,119,252":                        {DevicePhone, 70, "iOS DHCP client"},
+	"1,121,3,6,15,114,119,252":                    {DevicePhone, 70, "iOS DHCP client"},
+	"1,3,6,15,26,28,51,58,59":                     {DevicePhone, 60, "Android DHCP client"},
+	"1,3,6,15,26,28,51,58,59,43":                  {DevicePhone, 70, "Android DHCP client"},
+	"1,3,6,15,26,28,51,58,59,43,114":              {DevicePhone, 70, "Android DHCP client"},
+	"1,121,3,6,15,119,252,95,44,46":               {DeviceLaptop, 70, "macOS DHCP client"},
+	"1,121,3,6,15,114,119,252,95,44,46":           {DeviceLaptop, 70, "macOS DHCP client"},
+	"1,3,6,15,31,33,43,44,46,47,119,121,249,252":  {DeviceLaptop, 70, "Windows DHCP client"},
+	"1,3,6,15,31,33,43,44,46,47,121,249,252":      {DeviceLaptop, 70, "Windows DHCP client"},
+	"1,28,2,3,15,6,119,12,44,47,26,121,42":        {DeviceLaptop, 50, "Linux dhclient"},
+	"1,2,6,12,15,26,28,121,3,33,40,41,42,119,249": {DeviceLaptop, 50, "NetworkManager DHCP client"},
+	"1,3,6,12,15,28,42":                           {DeviceIoT, 40, "embedded DHCP client"},
+	"1,3,28,6":                                    {DeviceIoT, 50, "embedded DHCP client"},
+	"1,3,6,15,28,33":                              {DeviceIoT, 40, "embedded DHCP client"},
+}
+
+// mDNS service types, in order of specificity
+var mdnsServiceHints = []struct {
+	service string
+	hint    deviceHint
+}{
+	{"_ipp._tcp", deviceHint{DevicePrinter, 90, "announces _ipp._tcp"}},
+	{"_ipps._tcp", deviceHint{DevicePrinter, 90, "announces _ipps._tcp"}},
+	{"_printer._tcp", deviceHint{DevicePrinter, 90, "announces _printer._tcp"}},
+	{"_pdl-datastream._tcp", deviceHint{DevicePrinter, 90, "announces _pdl-datastream._tcp"}},
+	{"_uscan._tcp", deviceHint{DevicePrinter, 70, "announces _uscan._tcp"}},
+	{"_scanner._tcp", deviceHint{DevicePrinter, 60, "announces _scanner._tcp"}},
+	{"_apple-mobdev2._tcp", deviceHint{DevicePhone, 70, "announces _apple-mobdev2._tcp"}},
+	{"_googlecast._tcp", deviceHint{DeviceIoT, 80, "announces _googlecast._tcp"}},
+	{"_hap._tcp", deviceHint{DeviceIoT, 80, "announces _hap._tcp"}},
+	{"_hue._tcp", deviceHint{DeviceIoT, 80, "announces _hue._tcp"}},
+	{"_sonos._tcp", deviceHint{DeviceIoT, 80, "announces _sonos._tcp"}},
+	{"_spotify-connect._tcp", deviceHint{DeviceIoT, 60, "announces _spotify-connect._tcp"}},
+	{"_amzn-wplay._tcp", deviceHint{DeviceIoT, 70, "announces _amzn-wplay._tcp"}},
+	{"_airplay._tcp", deviceHint{DeviceIoT, 40, "announces _airplay._tcp"}},
+	{"_raop._tcp", deviceHint{DeviceIoT, 30, "announces _raop._tcp"}},
+	{"_workstation._tcp", deviceHint{DeviceLaptop, 60, "announces _workstation._tcp"}},
+	{"_smb._tcp", deviceHint{DeviceLaptop, 40, "announces _smb._tcp"}},
+	{"_afpovertcp._tcp", deviceHint{DeviceLaptop, 40, "announces _afpovertcp._tcp"}},
+	{"_ssh._tcp", deviceHint{DeviceLaptop, 30, "announces _ssh._tcp"}},
+}
+
+// User-Agent tokens, the first match wins
+var userAgentHints = []struct {
+	token string
+	hint  deviceHint
+}{
+	{"smart-tv", deviceHint{DeviceIoT, 80, "smart TV User-Agent"}},
+	{"smarttv", deviceHint{DeviceIoT, 80, "smart TV User-Agent"}},
+	{"tizen", deviceHint{DeviceIoT, 70, "Tizen User-Agent"}},
+	{"webos", deviceHint{DeviceIoT, 70, "webOS User-Agent"}},
+	{"roku", deviceHint{DeviceIoT, 80, "Roku User-Agent"}},
+	{"crkey", deviceHint{DeviceIoT, 80, "Chromecast User-Agent"}},
+	{"appletv", deviceHint{DeviceIoT, 80, "Apple TV User-Agent"}},
+	{"playstation", deviceHint{DeviceIoT, 70, "game console User-Agent"}},
+	{"xbox", deviceHint{DeviceIoT, 70, "game console User-Agent"}},
+	{"iphone", deviceHint{DevicePhone, 80, "iPhone User-Agent"}},
+	{"windows phone", deviceHint{DevicePhone, 80, "Windows Phone User-Agent"}},
+	{"mobile", deviceHint{DevicePhone, 70, "mobile User-Agent"}},
+	{"android", deviceHint{DevicePhone, 50, "Android User-Agent"}},
+	{"okhttp", deviceHint{DevicePhone, 40, "Android app User-Agent"}},
+	{"cfnetwork", deviceHint{DevicePhone, 30, "Apple app User-Agent"}},
+	{"windows nt", deviceHint{DeviceLaptop, 70, "Windows User-Agent"}},
+	{"macintosh", deviceHint{DeviceLaptop, 70, "macOS User-Agent"}},
+	{"cros", deviceHint{DeviceLaptop, 70, "ChromeOS User-Agent"}},
+	{"x11", deviceHint{DeviceLaptop, 60, "Linux desktop User-Agent"}},
+}
+
+func dhcpHint(fingerprint string) (deviceHint, bool) {
+	hint, found := dhcpFingerprints[fingerprint]
+	return hint, found
+}
+
+func userAgentHint(ua string) (deviceHint, bool) {
+	ua = strings.ToLower(ua)
+	for _, h := range userAgentHints {
+		if strings.Contains(ua, h.token) {
+			return h.hint, true
+		}
+	}
+	return deviceHint{}, false
+}
+
+func mdnsHints(services []string) []deviceHint {
+	hints := make([]deviceHint, 0)
+	for _, h := range mdnsServiceHints {
+		for _, service := range services {
+			if service == h.service {
+				hints = append(hints, h.hint)
+				break
+			}
+		}
+	}
+	return hints
+}
+
+// ClassifyDevice combines the signals into a device category, agreeing
+// signals raise the confidence while those pointing somewhere else lower it,
+// nil is returned if no signal is conclusive.
+func ClassifyDevice(sig DeviceSignals) *DeviceClass {
+	hints := make([]deviceHint, 0)
+	if hint, found := dhcpHint(sig.DHCPFingerprint); found {
+		hints = append(hints, hint)
+	}
+	if hint, found := userAgentHint(sig.UserAgent); found {
+		hints = append(hints, hint)
+	}
+	hints = append(hints, mdnsHints(sig.MDNSServices)...)
+
+	if len(hints) == 0 {
+		return nil
+	}
+
+	// combine the hints of each category as independent probabilities
+	miss := make(map[string]float64)
+	reasons := make(map[string][]string)
+	for _, hint := range hints {
+		if _, found := miss[hint.category]; !found {
+			miss[hint.category] = 1.0
+		}
+		miss[hint.category] *= 1.0 - float64(hint.confidence)/100.0
+		reasons[hint.category] = append(reasons[hint.category], hint.reason)
+	}
+
+	categories := make([]string, 0)
+	for category := range miss {
+		categories = append(categories, category)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		if miss[categories[i]] == miss[categories[j]] {
+			return categories[i] < categories[j]
+		}
+		return miss[categories[i]] < miss[categories[j]]
+	})
+
+	best := categories[0]
+	confidence := 1.0 - miss[best]
+	if len(categories) > 1 {
+		confidence -= (1.0 - miss[categories[1]]) / 2
+	}
+
+	class := &DeviceClass{
+		Category:   best,
+		Confidence: int(confidence * 100),
+		Reasons:    reasons[best],
+	}
+	if class.Confidence <= 0 {
+		return nil
+	} else if class.Confidence > 99 {
+		class.Confidence = 99
+	}
+	return class
+}
+
+func (c *DeviceClass) String() string {
+	return fmt.Sprintf("%s (guess, %d%%)", c.Category, c.Confidence)
+}
+
+// AddDeviceSignals stores sig in the endpoint meta, merging the mDNS
+// services with the ones already known, and returns true if anything new
+// was learned.
+func (t *Endpoint) AddDeviceSignals(sig DeviceSignals) bool {
+	changed := false
+	if sig.DHCPFingerprint != "" && t.Meta.Get(dhcpFingerprintMeta) != sig.DHCPFingerprint {
+		t.Meta.Set(dhcpFingerprintMeta, sig.DHCPFingerprint)
+		changed = true
+	}
+
+	if sig.UserAgent != "" && t.Meta.Get(userAgentMeta) != sig.UserAgent {
+		t.Meta.Set(userAgentMeta, sig.UserAgent)
+		changed = true
+	}
+
+	if len(sig.MDNSServices) > 0 {
+		services := t.DeviceSignals().MDNSServices
+		for _, service := range sig.MDNSServices {
+			if !stringsContain(services, service) {
+				services = append(services, service)
+				changed = true
+			}
+		}
+		if changed {
+			sort.Strings(services)
+			t.Meta.Set(mdnsServicesMeta, strings.Join(services, ","))
+		}
+	}
+
+	return changed
+}
+
+// DeviceSignals returns the signals stored in the endpoint meta.
+func (t *Endpoint) DeviceSignals() DeviceSignals {
+	sig := DeviceSignals{MDNSServices: make([]string, 0)}
+	sig.DHCPFingerprint, _ = t.Meta.Get(dhcpFingerprintMeta).(string)
+	sig.UserAgent, _ = t.Meta.Get(userAgentMeta).(string)
+	if services, _ := t.Meta.Get(mdnsServicesMeta).(string); services != "" {
+		sig.MDNSServices = strings.Split(services, ",")
+	}
+	return sig
+}
+
+func (sig DeviceSignals) Empty() bool {
+	return sig.DHCPFingerprint == "" && sig.UserAgent == "" && len(sig.MDNSServices) == 0
+}
+
+func stringsContain(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
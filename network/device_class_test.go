@@ -0,0 +1,69 @@
+package network
+
+import (
+	"testing"
+)
+
+func TestClassifyDevice(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Signals  DeviceSignals
+		Category string
+	}{
+		{"iOS DHCP", DeviceSignals{DHCPFingerprint: "1,121,3,6,15,119,252"}, DevicePhone},
+		{"Windows UA", DeviceSignals{UserAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64)"}, DeviceLaptop},
+		{"Android UA", DeviceSignals{UserAgent: "Mozilla/5.0 (Linux; Android 10; SM-G973F) Mobile Safari/537.36"}, DevicePhone},
+		{"printer mDNS", DeviceSignals{MDNSServices: []string{"_http._tcp", "_ipp._tcp"}}, DevicePrinter},
+		{"chromecast mDNS", DeviceSignals{MDNSServices: []string{"_googlecast._tcp"}}, DeviceIoT},
+	}
+
+	for _, c := range cases {
+		class := ClassifyDevice(c.Signals)
+		if class == nil {
+			t.Fatalf("%s: expected %s, got nothing", c.Name, c.Category)
+		} else if class.Category != c.Category {
+			t.Fatalf("%s: expected %s, got %s", c.Name, c.Category, class.Category)
+		}
+	}
+
+	if class := ClassifyDevice(DeviceSignals{UserAgent: "curl/7.58.0"}); class != nil {
+		t.Fatalf("expected no class for an inconclusive signal, got %+v", class)
+	}
+}
+
+func TestClassifyDeviceConfidence(t *testing.T) {
+	single := ClassifyDevice(DeviceSignals{DHCPFingerprint: "1,121,3,6,15,119,252"})
+	agreeing := ClassifyDevice(DeviceSignals{
+		DHCPFingerprint: "1,121,3,6,15,119,252",
+		UserAgent:       "Mozilla/5.0 (iPhone; CPU iPhone OS 12_0 like Mac OS X)",
+	})
+	conflicting := ClassifyDevice(DeviceSignals{
+		DHCPFingerprint: "1,121,3,6,15,119,252",
+		MDNSServices:    []string{"_workstation._tcp"},
+	})
+
+	if agreeing.Confidence <= single.Confidence {
+		t.Fatalf("agreeing signals should raise the confidence: %d <= %d", agreeing.Confidence, single.Confidence)
+	} else if len(agreeing.Reasons) != 2 {
+		t.Fatalf("expected two reasons, got %v", agreeing.Reasons)
+	} else if conflicting.Category != DevicePhone || conflicting.Confidence >= single.Confidence {
+		t.Fatalf("conflicting signals should lower the confidence: %+v", conflicting)
+	}
+}
+
+func TestEndpointDeviceSignals(t *testing.T) {
+	e := NewEndpointNoResolve("192.168.1.2", "aa:bb:cc:dd:ee:ff", "", 24)
+
+	if !e.AddDeviceSignals(DeviceSignals{MDNSServices: []string{"_ipp._tcp"}}) {
+		t.Fatal("expected the first signals to be new")
+	} else if !e.AddDeviceSignals(DeviceSignals{DHCPFingerprint: "1,3,6", MDNSServices: []string{"_http._tcp"}}) {
+		t.Fatal("expected the fingerprint and service to be new")
+	} else if e.AddDeviceSignals(DeviceSignals{DHCPFingerprint: "1,3,6", MDNSServices: []string{"_ipp._tcp"}}) {
+		t.Fatal("expected known signals not to be new")
+	}
+
+	sig := e.DeviceSignals()
+	if sig.DHCPFingerprint != "1,3,6" || len(sig.MDNSServices) != 2 || sig.MDNSServices[0] != "_http._tcp" || sig.MDNSServices[1] != "_ipp._tcp" {
+		t.Fatalf("unexpected signals %+v", sig)
+	}
+}
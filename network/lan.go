@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net"
+	"strconv"
 	"strings"
 	"sync"
 )
@@ -23,6 +24,15 @@ type LAN struct {
 	aliases *Aliases
 	newCb   EndpointNewCallback
 	lostCb  EndpointLostCallback
+	// if false the hostnames of new hosts are not resolved
+	resolve bool
+}
+
+// EndpointIPChanged is the payload of endpoint.ip.changed events.
+type EndpointIPChanged struct {
+	Endpoint *Endpoint `json:"endpoint"`
+	Old      string    `json:"old"`
+	New      string    `json:"new"`
 }
 
 type lanJSON struct {
@@ -43,6 +53,7 @@ func NewLAN(iface, gateway *Endpoint, newcb EndpointNewCallback, lostcb Endpoint
 		aliases: aliases,
 		newCb:   newcb,
 		lostCb:  lostcb,
+		resolve: true,
 	}
 }
 
@@ -126,6 +137,12 @@ func (lan *LAN) Remove(ip, mac string) {
 }
 
 func (lan *LAN) shouldIgnore(ip, mac string) bool {
+	return lan.shouldIgnoreIn(ip, mac, 0)
+}
+
+// shouldIgnoreIn is shouldIgnore for hosts on the given VLAN, whose subnet
+// is not the one of the interface.
+func (lan *LAN) shouldIgnoreIn(ip, mac string, vlan uint16) bool {
 	// skip our own address
 	if ip == lan.iface.IpAddress || mac == lan.iface.HwAddress {
 		return true
@@ -144,6 +161,9 @@ func (lan *LAN) shouldIgnore(ip, mac string) bool {
 	}
 	// skip everything which is not in our subnet (multicast noise)
 	addr := net.ParseIP(ip)
+	if vlan != 0 {
+		return addr == nil || !addr.IsGlobalUnicast()
+	}
 	return !lan.iface.Net.Contains(addr)
 }
 
@@ -182,13 +202,51 @@ func (lan *LAN) GetByIp(ip string) *Endpoint {
 	return nil
 }
 
+// Rebind moves the endpoint of mac to ip, the previous address is returned
+// if it changed. The endpoint is replaced by a copy with the new address,
+// so that whoever is still using the previous one doesn't see it change.
+func (lan *LAN) Rebind(ip, mac string, vlan uint16) (*Endpoint, string) {
+	lan.Lock()
+	defer lan.Unlock()
+
+	mac = NormalizeMac(mac)
+	if lan.shouldIgnoreIn(ip, mac, vlan) {
+		return nil, ""
+	} else if e, found := lan.hosts[mac]; found && e.IpAddress != ip {
+		moved := *e
+		moved.SetIP(ip)
+		// the name was the one of the previous address
+		moved.Hostname = ""
+		if lan.resolve {
+			moved.resolve()
+		}
+
+		lan.hosts[mac] = &moved
+		return &moved, e.IpAddress
+	}
+	return nil, ""
+}
+
+// SetResolve enables or disables the reverse DNS lookup of new hosts.
+func (lan *LAN) SetResolve(enabled bool) {
+	lan.Lock()
+	defer lan.Unlock()
+	lan.resolve = enabled
+}
+
 func (lan *LAN) AddIfNew(ip, mac string) *Endpoint {
+	return lan.AddIfNewIn(ip, mac, 0)
+}
+
+// AddIfNewIn is AddIfNew for hosts seen on a tagged VLAN, which can be on
+// any subnet, the ID of the VLAN is saved in their metadata.
+func (lan *LAN) AddIfNewIn(ip, mac string, vlan uint16) *Endpoint {
 	lan.Lock()
 	defer lan.Unlock()
 
 	mac = NormalizeMac(mac)
 
-	if lan.shouldIgnore(ip, mac) {
+	if lan.shouldIgnoreIn(ip, mac, vlan) {
 		return nil
 	} else if t, found := lan.hosts[mac]; found {
 		if lan.ttl[mac] < LANDefaultttl {
@@ -197,7 +255,16 @@ func (lan *LAN) AddIfNew(ip, mac string) *Endpoint {
 		return t
 	}
 
-	e := NewEndpointWithAlias(ip, mac, lan.aliases.Get(mac))
+	var e *Endpoint
+	if lan.resolve {
+		e = NewEndpointWithAlias(ip, mac, lan.aliases.Get(mac))
+	} else {
+		e = NewEndpointNoResolve(ip, mac, "", 0)
+		e.Alias = lan.aliases.Get(mac)
+	}
+	if vlan != 0 {
+		e.Meta.Set(vlanMeta, strconv.Itoa(int(vlan)))
+	}
 
 	lan.hosts[mac] = e
 	lan.ttl[mac] = LANDefaultttl
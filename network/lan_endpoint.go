@@ -13,6 +13,9 @@ import (
 
 type OnHostResolvedCallback func(e *Endpoint)
 
+// meta key of the VLAN ID of hosts discovered on a tagged VLAN
+const vlanMeta = "vlan"
+
 type Endpoint struct {
 	Index            int                    `json:"-"`
 	IP               net.IP                 `json:"-"`
@@ -31,6 +34,11 @@ type Endpoint struct {
 	FirstSeen        time.Time              `json:"first_seen"`
 	LastSeen         time.Time              `json:"last_seen"`
 	Meta             *Meta                  `json:"meta"`
+	OSGuess          *OSGuess               `json:"os_guess"`
+	DeviceClass      *DeviceClass           `json:"device_class"`
+	Groups           *MulticastGroups       `json:"multicast_groups"`
+	Suspicious       bool                   `json:"suspicious"`
+	Unconfirmed      bool                   `json:"unconfirmed"`
 }
 
 func NewEndpointNoResolve(ip, mac, name string, bits uint32) *Endpoint {
@@ -52,6 +60,7 @@ func NewEndpointNoResolve(ip, mac, name string, bits uint32) *Endpoint {
 		FirstSeen:        now,
 		LastSeen:         now,
 		Meta:             NewMeta(),
+		Groups:           NewMulticastGroups(),
 	}
 
 	e.SetIP(ip)
@@ -62,17 +71,21 @@ func NewEndpointNoResolve(ip, mac, name string, bits uint32) *Endpoint {
 
 func NewEndpoint(ip, mac string) *Endpoint {
 	e := NewEndpointNoResolve(ip, mac, "", 0)
-	// start resolver goroutine
-	go func() {
-		if names, err := net.LookupAddr(e.IpAddress); err == nil && len(names) > 0 {
-			e.Hostname = names[0]
-			if e.ResolvedCallback != nil {
-				e.ResolvedCallback(e)
+	e.resolve()
+	return e
+}
+
+// resolve starts the reverse DNS lookup job of the endpoint address.
+func (t *Endpoint) resolve() {
+	ip := t.IpAddress
+	core.Workers.Submit(func() {
+		if names, err := net.LookupAddr(ip); err == nil && len(names) > 0 {
+			t.Hostname = names[0]
+			if t.ResolvedCallback != nil {
+				t.ResolvedCallback(t)
 			}
 		}
-	}()
-
-	return e
+	})
 }
 
 func NewEndpointWithAlias(ip, mac, alias string) *Endpoint {
@@ -155,6 +168,17 @@ func (t *Endpoint) String() string {
 	return fmt.Sprintf("%s%s ( %s ) - %s", ipPart, t.HwAddress, t.Vendor, core.Bold(t.Hostname))
 }
 
+// VLAN returns the ID of the tagged VLAN the endpoint has been discovered
+// on, or 0 if it's on the untagged network of the interface.
+func (t *Endpoint) VLAN() uint16 {
+	if v, ok := t.Meta.Get(vlanMeta).(string); ok {
+		if id, err := strconv.ParseUint(v, 10, 16); err == nil {
+			return uint16(id)
+		}
+	}
+	return 0
+}
+
 func (t *Endpoint) OnMeta(meta map[string]string) {
 	host := ""
 	for k, v := range meta {
@@ -198,3 +198,59 @@ func TestShouldIgnore(t *testing.T) {
 		t.Fatalf("expected '%v', got '%v'", exp, got)
 	}
 }
+
+func TestAddIfNewIn(t *testing.T) {
+	exampleLAN := buildExampleLAN()
+
+	// hosts on a tagged VLAN are not on the subnet of the interface
+	if exampleLAN.AddIfNewIn("198.51.100.7", "aa:bb:cc:00:11:22", 0); exampleLAN.Has("198.51.100.7") {
+		t.Fatal("added an address outside of the interface subnet")
+	}
+
+	exampleLAN.AddIfNewIn("198.51.100.7", "aa:bb:cc:00:11:22", 20)
+	if e, found := exampleLAN.Get("aa:bb:cc:00:11:22"); !found {
+		t.Fatal("host on VLAN 20 not added")
+	} else if e.VLAN() != 20 {
+		t.Fatalf("expected VLAN 20, got %d", e.VLAN())
+	}
+
+	if exampleLAN.AddIfNewIn("224.0.0.251", "aa:bb:cc:00:11:33", 20); exampleLAN.Has("224.0.0.251") {
+		t.Fatal("added a multicast address")
+	}
+}
+
+func TestRebind(t *testing.T) {
+	exampleLAN := buildExampleLAN()
+	exampleLAN.AddIfNewIn("198.51.100.7", "aa:bb:cc:00:11:22", 20)
+
+	if e, old := exampleLAN.Rebind("198.51.100.7", "aa:bb:cc:00:11:22", 20); e != nil || old != "" {
+		t.Fatalf("the address didn't change, got '%v' '%s'", e, old)
+	} else if e, old := exampleLAN.Rebind("198.51.100.8", "aa:bb:cc:00:11:33", 20); e != nil || old != "" {
+		t.Fatalf("unknown hosts can't be rebound, got '%v' '%s'", e, old)
+	}
+
+	prev, _ := exampleLAN.Get("aa:bb:cc:00:11:22")
+	prev.Hostname = "old.lan"
+
+	e, old := exampleLAN.Rebind("198.51.100.8", "AA:BB:CC:00:11:22", 20)
+	if e == nil || old != "198.51.100.7" {
+		t.Fatalf("expected the old address 198.51.100.7, got '%v' '%s'", e, old)
+	} else if e.IpAddress != "198.51.100.8" || !exampleLAN.Has("198.51.100.8") {
+		t.Fatalf("expected the new address 198.51.100.8, got '%s'", e.IpAddress)
+	} else if exampleLAN.Has("198.51.100.7") {
+		t.Fatal("the old address is still in the LAN")
+	} else if len(exampleLAN.List()) != 1 {
+		t.Fatalf("expected a single host, got %d", len(exampleLAN.List()))
+	} else if e.Hostname == "old.lan" {
+		t.Fatal("the name of the old address was kept")
+	} else if e.VLAN() != 20 {
+		t.Fatalf("expected VLAN 20, got %d", e.VLAN())
+	}
+
+	// the endpoint in use before doesn't change
+	if prev == e || prev.IpAddress != "198.51.100.7" {
+		t.Fatalf("the previous endpoint was changed to '%s'", prev.IpAddress)
+	} else if found, _ := exampleLAN.Get("aa:bb:cc:00:11:22"); found != e {
+		t.Fatal("the LAN doesn't have the rebound endpoint")
+	}
+}
@@ -38,6 +38,12 @@ func (m *Meta) Set(name string, value interface{}) {
 	m.m[name] = value
 }
 
+func (m *Meta) Delete(name string) {
+	m.Lock()
+	defer m.Unlock()
+	delete(m.m, name)
+}
+
 func (m *Meta) Get(name string) interface{} {
 	m.Lock()
 	defer m.Unlock()
@@ -62,6 +68,18 @@ func (m *Meta) GetIntsWith(name string, with int, sorted bool) []int {
 	return core.UniqueInts(ints, sorted)
 }
 
+// GetInts returns the sorted list of integers saved with SetInts.
+func (m *Meta) GetInts(name string) []int {
+	ints := make([]int, 0)
+	for _, s := range strings.Split(m.Get(name).(string), ",") {
+		if n, err := strconv.Atoi(s); err == nil {
+			ints = append(ints, n)
+		}
+	}
+
+	return core.UniqueInts(ints, true)
+}
+
 func (m *Meta) SetInts(name string, ints []int) {
 	list := make([]string, len(ints))
 	for i, n := range ints {
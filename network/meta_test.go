@@ -32,6 +32,17 @@ func TestMetaSet(t *testing.T) {
 	}
 }
 
+func TestMetaDelete(t *testing.T) {
+	example := buildExampleMeta()
+	example.Set("picat", "<3")
+	example.Delete("picat")
+	if _, found := example.m["picat"]; found {
+		t.Error("unable to delete meta data from struct")
+	}
+	// deleting a missing name is fine
+	example.Delete("picat")
+}
+
 // TODO document what this does, not too clear,
 // at least for me today lolololol
 func TestMetaGetIntsWith(t *testing.T) {
@@ -46,6 +57,20 @@ func TestMetaGetIntsWith(t *testing.T) {
 	}
 }
 
+func TestMetaGetInts(t *testing.T) {
+	example := buildExampleMeta()
+	if got := example.GetInts("picat"); len(got) != 0 {
+		t.Fatalf("expected no ints, got '%v'", got)
+	}
+
+	example.m["picat"] = "80,22,80"
+	exp := []int{22, 80}
+	got := example.GetInts("picat")
+	if len(exp) != len(got) || exp[0] != got[0] || exp[1] != got[1] {
+		t.Fatalf("expected '%v', got '%v'", exp, got)
+	}
+}
+
 func TestMetaSetInts(t *testing.T) {
 	example := buildExampleMeta()
 	example.SetInts("picat", []int{0, 1})
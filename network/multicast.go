@@ -0,0 +1,82 @@
+package network
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+)
+
+var wellKnownGroups = map[string]string{
+	"224.0.0.1":       "all hosts",
+	"224.0.0.2":       "all routers",
+	"224.0.0.22":      "IGMPv3",
+	"224.0.0.251":     "mDNS",
+	"224.0.0.252":     "LLMNR",
+	"239.255.255.250": "SSDP",
+}
+
+// GroupName returns a description of well known multicast groups.
+func GroupName(group string) string {
+	if name, found := wellKnownGroups[group]; found {
+		return name
+	}
+	return ""
+}
+
+// MulticastGroups holds the multicast groups an endpoint is a member of.
+type MulticastGroups struct {
+	sync.RWMutex
+	groups map[string]bool
+}
+
+// MulticastJoin is the payload of net.recon.igmp events.
+type MulticastJoin struct {
+	Endpoint *Endpoint `json:"endpoint"`
+	Group    string    `json:"group"`
+}
+
+func NewMulticastGroups() *MulticastGroups {
+	return &MulticastGroups{
+		groups: make(map[string]bool),
+	}
+}
+
+func (m *MulticastGroups) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.List())
+}
+
+// Add returns true if the endpoint was not already a member of the group.
+func (m *MulticastGroups) Add(group string) bool {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.groups[group] {
+		return false
+	}
+	m.groups[group] = true
+	return true
+}
+
+func (m *MulticastGroups) Remove(group string) {
+	m.Lock()
+	defer m.Unlock()
+	delete(m.groups, group)
+}
+
+func (m *MulticastGroups) List() []string {
+	m.RLock()
+	defer m.RUnlock()
+
+	list := make([]string, 0, len(m.groups))
+	for group := range m.groups {
+		list = append(list, group)
+	}
+	sort.Strings(list)
+	return list
+}
+
+func (m *MulticastGroups) Empty() bool {
+	m.RLock()
+	defer m.RUnlock()
+	return len(m.groups) == 0
+}
@@ -118,6 +118,27 @@ func ParseTargets(targets string, aliasMap *Aliases) (ips []net.IP, macs []net.H
 	return
 }
 
+// RangeSize returns how many addresses list.Expand() would return at most,
+// without expanding it, overlapping ranges are counted more than once.
+func RangeSize(list iprange.AddressRangeList) int {
+	total := 0
+	for _, r := range list {
+		min, max := r.Min.To4(), r.Max.To4()
+		if min == nil || max == nil {
+			continue
+		}
+
+		size := 1
+		for i := 0; i < net.IPv4len; i++ {
+			if max[i] >= min[i] {
+				size *= int(max[i]-min[i]) + 1
+			}
+		}
+		total += size
+	}
+	return total
+}
+
 func buildEndpointFromInterface(iface net.Interface) (*Endpoint, error) {
 	addrs, err := iface.Addrs()
 	if err != nil {
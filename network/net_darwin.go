@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net"
 	"regexp"
+	"strconv"
 
 	"github.com/bettercap/bettercap/core"
 )
@@ -37,8 +38,51 @@ func SetInterfaceChannel(iface string, channel int) error {
 	return err
 }
 
+var airPortChannelParser = regexp.MustCompile(`(?m)^\s*channel:\s*([0-9]+)`)
+
+func ForceInterfaceChannel(iface string, channel int) error {
+	return SetInterfaceChannel(iface, channel)
+}
+
+func GetInterfaceChannel(iface string) (int, error) {
+	out, err := core.Exec(airPortPath, []string{iface, "-I"})
+	if err != nil {
+		return 0, err
+	}
+
+	matches := airPortChannelParser.FindStringSubmatch(out)
+	if len(matches) != 2 {
+		return 0, fmt.Errorf("could not find the current channel of %s", iface)
+	}
+	return strconv.Atoi(matches[1])
+}
+
 //! TODO Get the list of the available frequencies supported by the network card
 func GetSupportedFrequencies(iface string) ([]int, error) {
 	freqs := []int{2412, 2417, 2422, 2427, 2432, 2437, 2442, 2447, 2452, 2457, 2462, 2467, 2472, 2484}
 	return freqs, nil
 }
+
+func GetRegulatoryDomain() (string, error) {
+	return "", fmt.Errorf("macOS does not support setting the WiFi regulatory domain.")
+}
+
+func SetRegulatoryDomain(country string) error {
+	return fmt.Errorf("macOS does not support setting the WiFi regulatory domain.")
+}
+
+func GetInterfaceTxPower(iface string) (float64, error) {
+	return 0, fmt.Errorf("macOS does not support setting the WiFi TX power.")
+}
+
+func SetInterfaceTxPower(iface string, dbm int) error {
+	return fmt.Errorf("macOS does not support setting the WiFi TX power.")
+}
+
+func ResetInterfaceTxPower(iface string) error {
+	return fmt.Errorf("macOS does not support setting the WiFi TX power.")
+}
+
+func GetMaxTxPower(iface string) (map[int]float64, error) {
+	return nil, fmt.Errorf("macOS does not support setting the WiFi TX power.")
+}
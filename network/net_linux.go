@@ -18,6 +18,11 @@ var IPv4RouteTokens = 4
 var IPv4RouteCmd = "ip"
 var IPv4RouteCmdOpts = []string{"route"}
 var WiFiFreqParser = regexp.MustCompile(`^\s+Channel.([0-9]+)\s+:\s+([0-9\.]+)\s+GHz.*$`)
+var WiFiCurrFreqParser = regexp.MustCompile(`Current Frequency[:=]\s*([0-9\.]+)\s+GHz`)
+var WiFiRegionParser = regexp.MustCompile(`(?m)^country\s+([0-9A-Z]{2}):`)
+var WiFiTxPowerParser = regexp.MustCompile(`(?m)^\s*txpower\s+([0-9\.]+)\s+dBm`)
+var WiFiPhyParser = regexp.MustCompile(`(?m)^\s*wiphy\s+([0-9]+)`)
+var WiFiMaxTxPowerParser = regexp.MustCompile(`^\s*\*\s+([0-9\.]+)\s+MHz\s+\[[0-9]+\]\s+\(([0-9\.]+)\s+dBm\)`)
 
 var currChannels = make(map[string]int)
 var currChannelLock = sync.Mutex{}
@@ -59,6 +64,41 @@ func SetInterfaceChannel(iface string, channel int) error {
 	return nil
 }
 
+// ForceInterfaceChannel sets the channel even if we think the interface is
+// already on it, in case the driver moved it somewhere else.
+func ForceInterfaceChannel(iface string, channel int) error {
+	currChannelLock.Lock()
+	delete(currChannels, iface)
+	currChannelLock.Unlock()
+
+	return SetInterfaceChannel(iface, channel)
+}
+
+func processInterfaceChannel(output string, err error) (int, error) {
+	if err != nil {
+		return 0, err
+	}
+
+	matches := WiFiCurrFreqParser.FindStringSubmatch(output)
+	if len(matches) != 2 {
+		return 0, fmt.Errorf("could not find the current frequency")
+	}
+
+	freq, err := strconv.ParseFloat(matches[1], 64)
+	if err != nil {
+		return 0, err
+	}
+
+	return Dot11Freq2Chan(int(freq*1000 + 0.5)), nil
+}
+
+// GetInterfaceChannel returns the channel the driver reports the interface
+// to be on.
+func GetInterfaceChannel(iface string) (int, error) {
+	out, err := core.Exec("iwlist", []string{iface, "freq"})
+	return processInterfaceChannel(out, err)
+}
+
 func processSupportedFrequencies(output string, err error) ([]int, error) {
 	freqs := make([]int, 0)
 	if err != nil {
@@ -82,3 +122,101 @@ func GetSupportedFrequencies(iface string) ([]int, error) {
 	out, err := core.Exec("iwlist", []string{iface, "freq"})
 	return processSupportedFrequencies(out, err)
 }
+
+func processRegulatoryDomain(output string, err error) (string, error) {
+	if err != nil {
+		return "", err
+	}
+
+	// the first one is the global domain, the self managed phys follow
+	matches := WiFiRegionParser.FindStringSubmatch(output)
+	if len(matches) != 2 {
+		return "", fmt.Errorf("could not find the regulatory domain")
+	}
+	return matches[1], nil
+}
+
+// GetRegulatoryDomain returns the country code of the global regulatory
+// domain, 00 being the world one.
+func GetRegulatoryDomain() (string, error) {
+	out, err := core.Exec("iw", []string{"reg", "get"})
+	return processRegulatoryDomain(out, err)
+}
+
+func SetRegulatoryDomain(country string) error {
+	_, err := core.Exec("iw", []string{"reg", "set", country})
+	return err
+}
+
+func processInterfaceTxPower(output string, err error) (float64, error) {
+	if err != nil {
+		return 0, err
+	}
+
+	matches := WiFiTxPowerParser.FindStringSubmatch(output)
+	if len(matches) != 2 {
+		return 0, fmt.Errorf("could not find the TX power")
+	}
+	return strconv.ParseFloat(matches[1], 64)
+}
+
+// GetInterfaceTxPower returns the TX power in dBm the driver reports the
+// interface to be using.
+func GetInterfaceTxPower(iface string) (float64, error) {
+	out, err := core.Exec("iw", []string{"dev", iface, "info"})
+	return processInterfaceTxPower(out, err)
+}
+
+func SetInterfaceTxPower(iface string, dbm int) error {
+	// iw wants mBm
+	_, err := core.Exec("iw", []string{"dev", iface, "set", "txpower", "fixed", fmt.Sprintf("%d", dbm*100)})
+	return err
+}
+
+// ResetInterfaceTxPower lets the driver pick the TX power again.
+func ResetInterfaceTxPower(iface string) error {
+	_, err := core.Exec("iw", []string{"dev", iface, "set", "txpower", "auto"})
+	return err
+}
+
+func processMaxTxPower(output string, err error) (map[int]float64, error) {
+	powers := make(map[int]float64)
+	if err != nil {
+		return powers, err
+	}
+
+	// disabled frequencies have no power
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		matches := WiFiMaxTxPowerParser.FindStringSubmatch(scanner.Text())
+		if len(matches) != 3 {
+			continue
+		}
+
+		freq, err := strconv.ParseFloat(matches[1], 64)
+		if err != nil {
+			continue
+		}
+		if power, err := strconv.ParseFloat(matches[2], 64); err == nil {
+			powers[int(freq)] = power
+		}
+	}
+	return powers, nil
+}
+
+// GetMaxTxPower returns the highest TX power in dBm allowed on each enabled
+// frequency of the interface, given the current regulatory domain.
+func GetMaxTxPower(iface string) (map[int]float64, error) {
+	out, err := core.Exec("iw", []string{"dev", iface, "info"})
+	if err != nil {
+		return nil, err
+	}
+
+	matches := WiFiPhyParser.FindStringSubmatch(out)
+	if len(matches) != 2 {
+		return nil, fmt.Errorf("could not find the phy of %s", iface)
+	}
+
+	out, err = core.Exec("iw", []string{"phy", "phy" + matches[1], "info"})
+	return processMaxTxPower(out, err)
+}
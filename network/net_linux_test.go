@@ -57,3 +57,120 @@ func TestProcessSupportedFrequencies(t *testing.T) {
 		})
 	}
 }
+
+func TestProcessInterfaceChannel(t *testing.T) {
+	cases := []struct {
+		Name            string
+		InputString     string
+		InputError      error
+		ExpectedChannel int
+		ExpectedError   bool
+	}{
+		{
+			"Returns the channel of the current frequency",
+			`wlan1     11 channels in total; available frequencies :
+			Channel 01 : 2.412 GHz
+			Channel 11 : 2.462 GHz
+			Current Frequency:2.437 GHz (Channel 6)`,
+			nil,
+			6,
+			false,
+		},
+		{
+			"Handles 5GHz frequencies without the channel number",
+			`wlan1     Current Frequency=5.18 GHz`,
+			nil,
+			36,
+			false,
+		},
+		{
+			"Returns an error if the current frequency is missing",
+			`wlan1     no frequency information.`,
+			nil,
+			0,
+			true,
+		},
+		{
+			"Returns an error if iwlist failed",
+			"Doesn't matter",
+			errors.New("iwlist must have failed"),
+			0,
+			true,
+		},
+	}
+	for _, test := range cases {
+		t.Run(test.Name, func(t *testing.T) {
+			channel, err := processInterfaceChannel(test.InputString, test.InputError)
+			if err != nil && !test.ExpectedError {
+				t.Errorf("unexpected error: %s", err)
+			}
+			if err == nil && test.ExpectedError {
+				t.Error("expected error, but got none")
+			}
+			if channel != test.ExpectedChannel {
+				t.Errorf("got %d, want %d", channel, test.ExpectedChannel)
+			}
+		})
+	}
+}
+
+func TestProcessRegulatoryDomain(t *testing.T) {
+	output := `global
+country DE: DFS-ETSI
+	(2400 - 2483 @ 40), (N/A, 20), (N/A)
+
+phy#0 (self-managed)
+country US: DFS-UNSET
+	(2402 - 2472 @ 40), (6, 22), (N/A), AUTO-BW, NO-HT40MINUS, NO-80MHZ, NO-160MHZ`
+
+	if region, err := processRegulatoryDomain(output, nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	} else if region != "DE" {
+		t.Fatalf("expected the global domain DE, got %s", region)
+	}
+
+	if _, err := processRegulatoryDomain("", nil); err == nil {
+		t.Fatal("expected an error without a domain")
+	} else if _, err := processRegulatoryDomain(output, errors.New("iw must have failed")); err == nil {
+		t.Fatal("expected the iw error")
+	}
+}
+
+func TestProcessInterfaceTxPower(t *testing.T) {
+	output := `Interface wlan0
+	ifindex 3
+	wdev 0x1
+	addr 00:11:22:33:44:55
+	type monitor
+	wiphy 1
+	channel 6 (2437 MHz), width: 20 MHz (no HT), center1: 2437 MHz
+	txpower 22.00 dBm`
+
+	if power, err := processInterfaceTxPower(output, nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	} else if power != 22 {
+		t.Fatalf("expected 22 dBm, got %f", power)
+	}
+
+	if matches := WiFiPhyParser.FindStringSubmatch(output); len(matches) != 2 || matches[1] != "1" {
+		t.Fatalf("unexpected phy %v", matches)
+	}
+}
+
+func TestProcessMaxTxPower(t *testing.T) {
+	output := `		Frequencies:
+			* 2412 MHz [1] (20.0 dBm)
+			* 2467 MHz [12] (disabled)
+			* 5260 MHz [52] (23.0 dBm) (no IR, radar detection)
+			* 5785.0 MHz [157] (13.0 dBm)`
+
+	powers, err := processMaxTxPower(output, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := map[int]float64{2412: 20, 5260: 23, 5785: 13}
+	if !reflect.DeepEqual(powers, expected) {
+		t.Fatalf("expected %v, got %v", expected, powers)
+	}
+}
@@ -0,0 +1,66 @@
+package network
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+// IfaceCounters are the totals the kernel keeps for an interface.
+type IfaceCounters struct {
+	RxBytes   uint64 `json:"rx_bytes"`
+	RxPackets uint64 `json:"rx_packets"`
+	TxBytes   uint64 `json:"tx_bytes"`
+	TxPackets uint64 `json:"tx_packets"`
+}
+
+var NetDevFile = "/proc/net/dev"
+
+// ParseNetDev parses the contents of /proc/net/dev and returns the counters
+// of iface.
+func ParseNetDev(data string, iface string) (*IfaceCounters, error) {
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) != iface {
+			continue
+		}
+
+		// receive: bytes packets errs drop fifo frame compressed multicast
+		// transmit: bytes packets errs drop fifo colls carrier compressed
+		fields := strings.Fields(parts[1])
+		if len(fields) < 10 {
+			return nil, fmt.Errorf("unexpected %s line for %s: %s", NetDevFile, iface, scanner.Text())
+		}
+
+		values := make([]uint64, 0)
+		for _, idx := range []int{0, 1, 8, 9} {
+			v, err := strconv.ParseUint(fields[idx], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("unexpected %s value for %s: %s", NetDevFile, iface, err)
+			}
+			values = append(values, v)
+		}
+
+		return &IfaceCounters{
+			RxBytes:   values[0],
+			RxPackets: values[1],
+			TxBytes:   values[2],
+			TxPackets: values[3],
+		}, nil
+	}
+
+	return nil, fmt.Errorf("interface %s not found in %s", iface, NetDevFile)
+}
+
+// GetInterfaceCounters returns the kernel counters of iface, it only works
+// where /proc/net/dev is available.
+func GetInterfaceCounters(iface string) (*IfaceCounters, error) {
+	raw, err := ioutil.ReadFile(NetDevFile)
+	if err != nil {
+		return nil, err
+	}
+	return ParseNetDev(string(raw), iface)
+}
@@ -0,0 +1,40 @@
+package network
+
+import (
+	"testing"
+)
+
+const netDevSample = `Inter-|   Receive                                                |  Transmit
+ face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
+    lo:  123456     789    0    0    0     0          0         0   123456     789    0    0    0     0       0          0
+  eth0: 98765432  65432    0   12    0     0          0       321 12345678  23456    0    0    0     0       0          0
+wlan0mon:1000 10 0 0 0 0 0 0 0 0 0 0 0 0 0 0
+`
+
+func TestParseNetDev(t *testing.T) {
+	c, err := ParseNetDev(netDevSample, "eth0")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	exp := IfaceCounters{RxBytes: 98765432, RxPackets: 65432, TxBytes: 12345678, TxPackets: 23456}
+	if *c != exp {
+		t.Fatalf("expected %+v, got %+v", exp, *c)
+	}
+
+	// no space between the name and the counters
+	if c, err = ParseNetDev(netDevSample, "wlan0mon"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	} else if c.RxBytes != 1000 || c.RxPackets != 10 || c.TxBytes != 0 {
+		t.Fatalf("unexpected counters %+v", *c)
+	}
+}
+
+func TestParseNetDevErrors(t *testing.T) {
+	if _, err := ParseNetDev(netDevSample, "eth1"); err == nil {
+		t.Fatal("expected an error for a missing interface")
+	} else if _, err := ParseNetDev("eth0: 1 2 3\n", "eth0"); err == nil {
+		t.Fatal("expected an error for a truncated line")
+	} else if _, err := ParseNetDev("eth0: 1 x 0 0 0 0 0 0 1 1\n", "eth0"); err == nil {
+		t.Fatal("expected an error for a non numeric counter")
+	}
+}
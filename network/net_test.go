@@ -3,6 +3,8 @@ package network
 import (
 	"net"
 	"testing"
+
+	"github.com/malfunkt/iprange"
 )
 
 func TestIsZeroMac(t *testing.T) {
@@ -84,6 +86,29 @@ func TestParseTargets(t *testing.T) {
 	}
 }
 
+func TestRangeSize(t *testing.T) {
+	var units = []struct {
+		targets string
+		exp     int
+	}{
+		{"192.168.1.1", 1},
+		{"192.168.1.0/24", 256},
+		{"10.0.0.0/8", 16777216},
+		{"192.168.1.1-10", 10},
+		{"192.168.1-2.1-10", 20},
+		{"192.168.1.1, 192.168.1.0/30", 5},
+	}
+
+	for _, u := range units {
+		list, err := iprange.ParseList(u.targets)
+		if err != nil {
+			t.Fatal(err)
+		} else if got := RangeSize(list); got != u.exp {
+			t.Fatalf("expected %d addresses for '%s', got %d", u.exp, u.targets, got)
+		}
+	}
+}
+
 func TestBuildEndpointFromInterface(t *testing.T) {
 	ifaces, err := net.Interfaces()
 	if err != nil {
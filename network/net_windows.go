@@ -63,7 +63,39 @@ func SetInterfaceChannel(iface string, channel int) error {
 	return fmt.Errorf("Windows does not support WiFi channel hopping.")
 }
 
+func ForceInterfaceChannel(iface string, channel int) error {
+	return SetInterfaceChannel(iface, channel)
+}
+
+func GetInterfaceChannel(iface string) (int, error) {
+	return 0, fmt.Errorf("Windows does not support WiFi channel hopping.")
+}
+
 func GetSupportedFrequencies(iface string) ([]int, error) {
 	freqs := make([]int, 0)
 	return freqs, fmt.Errorf("Windows does not support WiFi channel hopping.")
 }
+
+func GetRegulatoryDomain() (string, error) {
+	return "", fmt.Errorf("Windows does not support setting the WiFi regulatory domain.")
+}
+
+func SetRegulatoryDomain(country string) error {
+	return fmt.Errorf("Windows does not support setting the WiFi regulatory domain.")
+}
+
+func GetInterfaceTxPower(iface string) (float64, error) {
+	return 0, fmt.Errorf("Windows does not support setting the WiFi TX power.")
+}
+
+func SetInterfaceTxPower(iface string, dbm int) error {
+	return fmt.Errorf("Windows does not support setting the WiFi TX power.")
+}
+
+func ResetInterfaceTxPower(iface string) error {
+	return fmt.Errorf("Windows does not support setting the WiFi TX power.")
+}
+
+func GetMaxTxPower(iface string) (map[int]float64, error) {
+	return nil, fmt.Errorf("Windows does not support setting the WiFi TX power.")
+}
@@ -0,0 +1,69 @@
+package network
+
+import (
+	"fmt"
+)
+
+// OSGuess is a p0f-like, purely heuristic, guess of the operating system
+// of an endpoint given the TTL and TCP window size of its SYN packets.
+type OSGuess struct {
+	Name       string `json:"name"`
+	Confidence int    `json:"confidence"`
+	TTL        uint8  `json:"ttl"`
+	Window     uint16 `json:"window"`
+}
+
+// initialTTL rounds the observed TTL up to the most likely default one.
+func initialTTL(ttl uint8) uint8 {
+	if ttl <= 32 {
+		return 32
+	} else if ttl <= 64 {
+		return 64
+	} else if ttl <= 128 {
+		return 128
+	}
+	return 255
+}
+
+func GuessOS(ttl uint8, window uint16) *OSGuess {
+	guess := &OSGuess{
+		TTL:    ttl,
+		Window: window,
+	}
+
+	switch initialTTL(ttl) {
+	case 32:
+		guess.Name, guess.Confidence = "Windows 9x / embedded", 30
+	case 64:
+		switch window {
+		case 5720, 5840, 14600, 29200, 64240:
+			guess.Name, guess.Confidence = "Linux", 70
+		case 65535:
+			guess.Name, guess.Confidence = "macOS / iOS / BSD", 60
+		default:
+			guess.Name, guess.Confidence = "Linux / Unix", 40
+		}
+	case 128:
+		switch window {
+		case 8192, 64240, 65535:
+			guess.Name, guess.Confidence = "Windows", 70
+		default:
+			guess.Name, guess.Confidence = "Windows", 50
+		}
+	default:
+		switch window {
+		case 4128:
+			guess.Name, guess.Confidence = "Cisco IOS", 70
+		case 8760:
+			guess.Name, guess.Confidence = "Solaris", 50
+		default:
+			guess.Name, guess.Confidence = "network device / Solaris", 30
+		}
+	}
+
+	return guess
+}
+
+func (g *OSGuess) String() string {
+	return fmt.Sprintf("%s (guess, %d%%)", g.Name, g.Confidence)
+}
@@ -0,0 +1,28 @@
+package network
+
+import (
+	"testing"
+)
+
+func TestGuessOS(t *testing.T) {
+	var units = []struct {
+		ttl    uint8
+		window uint16
+		exp    string
+	}{
+		{64, 29200, "Linux"},
+		{57, 65535, "macOS / iOS / BSD"},
+		{128, 8192, "Windows"},
+		{120, 1234, "Windows"},
+		{255, 4128, "Cisco IOS"},
+	}
+
+	for _, u := range units {
+		got := GuessOS(u.ttl, u.window)
+		if got.Name != u.exp {
+			t.Fatalf("expected '%s' for ttl=%d window=%d, got '%s'", u.exp, u.ttl, u.window, got.Name)
+		} else if got.TTL != u.ttl || got.Window != u.window {
+			t.Fatalf("expected fingerprint to be preserved, got %+v", got)
+		}
+	}
+}
@@ -110,6 +110,9 @@ func (w *WiFi) Remove(mac string) {
 	}
 }
 
+// the name access points hiding their SSID are shown with
+const HiddenESSID = "<hidden>"
+
 // when iface is in monitor mode, error
 // correction on macOS is crap and we
 // get non printable characters .... (ref #61)
@@ -130,8 +133,9 @@ func (w *WiFi) AddIfNew(ssid, mac string, frequency int, rssi int8) *AccessPoint
 	if ap, found := w.aps[mac]; found {
 		ap.LastSeen = time.Now()
 		ap.RSSI = rssi
-		// always get the cleanest one
-		if !isBogusMacESSID(ssid) {
+		// always get the cleanest one, and don't forget the name of a
+		// hidden network once it's been revealed
+		if !isBogusMacESSID(ssid) && (ssid != HiddenESSID || ap.Hostname == HiddenESSID) {
 			ap.Hostname = ssid
 		}
 		return ap
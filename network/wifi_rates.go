@@ -0,0 +1,69 @@
+package network
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// WiFiRates is the histogram of the data rates (or MCS indexes) of the
+// frames sent by a station.
+type WiFiRates struct {
+	sync.RWMutex
+	frames map[string]uint64
+}
+
+type wifiRatesJSON struct {
+	Dominant string            `json:"dominant"`
+	Frames   map[string]uint64 `json:"frames"`
+}
+
+func NewWiFiRates() *WiFiRates {
+	return &WiFiRates{
+		frames: make(map[string]uint64),
+	}
+}
+
+func (r *WiFiRates) MarshalJSON() ([]byte, error) {
+	dominant, _ := r.Dominant()
+	return json.Marshal(wifiRatesJSON{
+		Dominant: dominant,
+		Frames:   r.Frames(),
+	})
+}
+
+func (r *WiFiRates) Add(rate string) {
+	r.Lock()
+	defer r.Unlock()
+	r.frames[rate]++
+}
+
+// Dominant returns the rate most frames were sent at and its share of the
+// frames, ties are broken by name to have stable results.
+func (r *WiFiRates) Dominant() (rate string, share float64) {
+	r.RLock()
+	defer r.RUnlock()
+
+	total, most := uint64(0), uint64(0)
+	for name, frames := range r.frames {
+		total += frames
+		if frames > most || (frames == most && name < rate) {
+			rate, most = name, frames
+		}
+	}
+
+	if total == 0 {
+		return "", 0
+	}
+	return rate, float64(most) / float64(total)
+}
+
+func (r *WiFiRates) Frames() map[string]uint64 {
+	r.RLock()
+	defer r.RUnlock()
+
+	frames := make(map[string]uint64, len(r.frames))
+	for name, n := range r.frames {
+		frames[name] = n
+	}
+	return frames
+}
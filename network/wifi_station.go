@@ -6,13 +6,15 @@ import (
 
 type Station struct {
 	*Endpoint
-	Frequency      int    `json:"frequency"`
-	RSSI           int8   `json:"rssi"`
-	Sent           uint64 `json:"sent"`
-	Received       uint64 `json:"received"`
-	Encryption     string `json:"encryption"`
-	Cipher         string `json:"cipher"`
-	Authentication string `json:"authentication"`
+	Frequency      int        `json:"frequency"`
+	RSSI           int8       `json:"rssi"`
+	Sent           uint64     `json:"sent"`
+	Received       uint64     `json:"received"`
+	Encryption     string     `json:"encryption"`
+	Cipher         string     `json:"cipher"`
+	Authentication string     `json:"authentication"`
+	Interface      string     `json:"interface"`
+	Rates          *WiFiRates `json:"rates"`
 }
 
 func cleanESSID(essid string) string {
@@ -33,6 +35,7 @@ func NewStation(essid, bssid string, frequency int, rssi int8) *Station {
 		Endpoint:  NewEndpointNoResolve(MonitorModeAddress, bssid, cleanESSID(essid), 0),
 		Frequency: frequency,
 		RSSI:      rssi,
+		Rates:     NewWiFiRates(),
 	}
 }
 
@@ -138,3 +138,38 @@ func TestWiFiClear(t *testing.T) {
 		t.Error("unable to clear known access point for wifi struct")
 	}
 }
+
+func TestWiFiAddIfNewHidden(t *testing.T) {
+	exampleWiFi := buildExampleWiFi()
+	exampleWiFi.AddIfNew(HiddenESSID, "ff:ff:ff:ff:ff:f1", 2472, int8(0))
+	exampleWiFi.AddIfNew("my_wifi", "ff:ff:ff:ff:ff:f1", 2472, int8(0))
+	exampleWiFi.AddIfNew(HiddenESSID, "ff:ff:ff:ff:ff:f1", 2472, int8(0))
+
+	ap, _ := exampleWiFi.Get("ff:ff:ff:ff:ff:f1")
+	if ap.ESSID() != "my_wifi" {
+		t.Fatalf("expected 'my_wifi', got '%s'", ap.ESSID())
+	}
+}
+
+func TestWiFiRates(t *testing.T) {
+	rates := NewWiFiRates()
+	if rate, share := rates.Dominant(); rate != "" || share != 0 {
+		t.Fatalf("expected no dominant rate, got %s (%f)", rate, share)
+	}
+
+	for _, rate := range []string{"1 Mbps", "HT MCS 7", "HT MCS 7", "1 Mbps", "HT MCS 7", "6 Mbps"} {
+		rates.Add(rate)
+	}
+
+	if rate, share := rates.Dominant(); rate != "HT MCS 7" || share != 0.5 {
+		t.Fatalf("expected HT MCS 7 (0.5), got %s (%f)", rate, share)
+	} else if frames := rates.Frames(); len(frames) != 3 || frames["1 Mbps"] != 2 {
+		t.Fatalf("unexpected histogram %v", frames)
+	}
+
+	// ties are broken by name
+	rates.Add("1 Mbps")
+	if rate, _ := rates.Dominant(); rate != "1 Mbps" {
+		t.Fatalf("expected 1 Mbps, got %s", rate)
+	}
+}
@@ -3,6 +3,7 @@ package packets
 import (
 	"net"
 
+	"github.com/google/gopacket"
 	"github.com/google/gopacket/layers"
 )
 
@@ -35,6 +36,26 @@ func NewARPRequest(from net.IP, from_hw net.HardwareAddr, to net.IP) (error, []b
 	return Serialize(&eth, &arp)
 }
 
+// NewARPUnicastRequest creates an ARP request sent straight to to_hw instead
+// of being broadcast, a host answering it is still alive at that address.
+func NewARPUnicastRequest(from net.IP, from_hw net.HardwareAddr, to net.IP, to_hw net.HardwareAddr) (error, []byte) {
+	eth, arp := NewARPTo(from, from_hw, to, to_hw, layers.ARPRequest)
+	// the target hardware address of a request is always unknown
+	arp.DstHwAddress = []byte{0, 0, 0, 0, 0, 0}
+	return Serialize(&eth, &arp)
+}
+
+// ARPGetReplyTo returns the sender address of pkt if it's an ARP reply to ip.
+func ARPGetReplyTo(pkt gopacket.Packet, ip net.IP) net.IP {
+	if larp := pkt.Layer(layers.LayerTypeARP); larp != nil {
+		arp := larp.(*layers.ARP)
+		if arp.Operation == layers.ARPReply && len(arp.SourceProtAddress) == net.IPv4len && ip.Equal(net.IP(arp.DstProtAddress)) {
+			return net.IP(arp.SourceProtAddress)
+		}
+	}
+	return nil
+}
+
 func NewARPReply(from net.IP, from_hw net.HardwareAddr, to net.IP, to_hw net.HardwareAddr) (error, []byte) {
 	eth, arp := NewARPTo(from, from_hw, to, to_hw, layers.ARPReply)
 	return Serialize(&eth, &arp)
@@ -4,6 +4,9 @@ import (
 	"net"
 	"reflect"
 	"testing"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
 )
 
 func TestNewARPTo(t *testing.T) {
@@ -96,3 +99,45 @@ func TestNewARPReply(t *testing.T) {
 		t.Error("unable to serialize new arp request packet")
 	}
 }
+
+func TestNewARPUnicastRequest(t *testing.T) {
+	from := net.IP{10, 0, 0, 1}
+	from_hw, _ := net.ParseMAC("01:23:45:67:89:ab")
+	to := net.IP{10, 0, 0, 2}
+	to_hw, _ := net.ParseMAC("aa:bb:cc:dd:ee:ff")
+
+	err, raw := NewARPUnicastRequest(from, from_hw, to, to_hw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pkt := gopacket.NewPacket(raw, layers.LayerTypeEthernet, gopacket.Default)
+	eth := pkt.Layer(layers.LayerTypeEthernet).(*layers.Ethernet)
+	arp := pkt.Layer(layers.LayerTypeARP).(*layers.ARP)
+	if eth.DstMAC.String() != to_hw.String() {
+		t.Fatalf("expected the frame to be sent to %s, got %s", to_hw, eth.DstMAC)
+	} else if arp.Operation != layers.ARPRequest || !net.IP(arp.DstProtAddress).Equal(to) {
+		t.Fatalf("unexpected arp layer %+v", arp)
+	}
+}
+
+func TestARPGetReplyTo(t *testing.T) {
+	me := net.IP{10, 0, 0, 1}
+	me_hw, _ := net.ParseMAC("01:23:45:67:89:ab")
+	other := net.IP{10, 0, 0, 2}
+	other_hw, _ := net.ParseMAC("aa:bb:cc:dd:ee:ff")
+
+	_, reply := NewARPReply(other, other_hw, me, me_hw)
+	pkt := gopacket.NewPacket(reply, layers.LayerTypeEthernet, gopacket.Default)
+	if from := ARPGetReplyTo(pkt, me); !from.Equal(other) {
+		t.Fatalf("expected %s, got %s", other, from)
+	} else if from = ARPGetReplyTo(pkt, other); from != nil {
+		t.Fatalf("a reply to somebody else should be ignored, got %s", from)
+	}
+
+	_, request := NewARPRequest(other, other_hw, me)
+	pkt = gopacket.NewPacket(request, layers.LayerTypeEthernet, gopacket.Default)
+	if from := ARPGetReplyTo(pkt, me); from != nil {
+		t.Fatalf("a request should be ignored, got %s", from)
+	}
+}
@@ -0,0 +1,73 @@
+package packets
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+func describeDNS(dns *layers.DNS) string {
+	if !dns.QR {
+		names := []string{}
+		for _, q := range dns.Questions {
+			names = append(names, string(q.Name))
+		}
+		return fmt.Sprintf("DNS query %s", strings.Join(names, ", "))
+	}
+
+	answers := []string{}
+	for _, a := range dns.Answers {
+		if a.IP != nil {
+			answers = append(answers, fmt.Sprintf("%s %s %s", a.Name, a.Type, a.IP))
+		} else {
+			answers = append(answers, fmt.Sprintf("%s %s", a.Name, a.Type))
+		}
+	}
+	return fmt.Sprintf("DNS answer %s", strings.Join(answers, ", "))
+}
+
+// Describe returns a short, human readable summary of a raw frame, it is
+// used to show what would have been sent in dry run mode.
+func Describe(linkType layers.LinkType, raw []byte) string {
+	pkt := gopacket.NewPacket(raw, linkType, gopacket.Default)
+	parts := []string{}
+
+	for _, l := range pkt.Layers() {
+		switch layer := l.(type) {
+		case *layers.RadioTap, *layers.Dot11InformationElement, *gopacket.Payload, *gopacket.DecodeFailure:
+			continue
+		case *layers.Ethernet:
+			parts = append(parts, fmt.Sprintf("Ethernet %s > %s", layer.SrcMAC, layer.DstMAC))
+		case *layers.ARP:
+			if layer.Operation == layers.ARPReply {
+				parts = append(parts, fmt.Sprintf("ARP reply %s is-at %s",
+					net.IP(layer.SourceProtAddress), net.HardwareAddr(layer.SourceHwAddress)))
+			} else {
+				parts = append(parts, fmt.Sprintf("ARP who-has %s tell %s",
+					net.IP(layer.DstProtAddress), net.IP(layer.SourceProtAddress)))
+			}
+		case *layers.IPv4:
+			parts = append(parts, fmt.Sprintf("IPv4 %s > %s", layer.SrcIP, layer.DstIP))
+		case *layers.IPv6:
+			parts = append(parts, fmt.Sprintf("IPv6 %s > %s", layer.SrcIP, layer.DstIP))
+		case *layers.UDP:
+			parts = append(parts, fmt.Sprintf("UDP %d > %d", layer.SrcPort, layer.DstPort))
+		case *layers.TCP:
+			parts = append(parts, fmt.Sprintf("TCP %d > %d", layer.SrcPort, layer.DstPort))
+		case *layers.DNS:
+			parts = append(parts, describeDNS(layer))
+		case *layers.Dot11:
+			parts = append(parts, fmt.Sprintf("802.11 %s %s > %s (bssid %s)", layer.Type, layer.Address2, layer.Address1, layer.Address3))
+		default:
+			parts = append(parts, l.LayerType().String())
+		}
+	}
+
+	if len(parts) == 0 {
+		return fmt.Sprintf("%d bytes", len(raw))
+	}
+	return strings.Join(parts, ", ")
+}
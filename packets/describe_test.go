@@ -0,0 +1,38 @@
+package packets
+
+import (
+	"net"
+	"testing"
+
+	"github.com/google/gopacket/layers"
+)
+
+func TestDescribeARPReply(t *testing.T) {
+	from := net.ParseIP("192.168.1.1")
+	fromHW, _ := net.ParseMAC("aa:bb:cc:dd:ee:ff")
+	to := net.ParseIP("192.168.1.2")
+	toHW, _ := net.ParseMAC("00:11:22:33:44:55")
+
+	_, raw := NewARPReply(from, fromHW, to, toHW)
+	exp := "Ethernet aa:bb:cc:dd:ee:ff > 00:11:22:33:44:55, ARP reply 192.168.1.1 is-at aa:bb:cc:dd:ee:ff"
+	if got := Describe(layers.LinkTypeEthernet, raw); got != exp {
+		t.Fatalf("expected '%s', got '%s'", exp, got)
+	}
+}
+
+func TestDescribeDot11Deauth(t *testing.T) {
+	client, _ := net.ParseMAC("aa:bb:cc:dd:ee:ff")
+	bssid, _ := net.ParseMAC("00:11:22:33:44:55")
+
+	_, raw := NewDot11Deauth(bssid, client, bssid, 0)
+	exp := "802.11 MgmtDeauthentication aa:bb:cc:dd:ee:ff > 00:11:22:33:44:55 (bssid 00:11:22:33:44:55), Dot11MgmtDeauthentication"
+	if got := Describe(layers.LinkTypeIEEE80211Radio, raw); got != exp {
+		t.Fatalf("expected '%s', got '%s'", exp, got)
+	}
+}
+
+func TestDescribeEmpty(t *testing.T) {
+	if got := Describe(layers.LinkTypeEthernet, []byte{}); got != "0 bytes" {
+		t.Fatalf("expected '0 bytes', got '%s'", got)
+	}
+}
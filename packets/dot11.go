@@ -2,6 +2,8 @@ package packets
 
 import (
 	"bytes"
+	"encoding/binary"
+	"fmt"
 	"net"
 
 	"github.com/bettercap/bettercap/network"
@@ -81,7 +83,29 @@ func NewDot11Beacon(conf Dot11ApConfig, seq uint16) (error, []byte) {
 	return Serialize(stack...)
 }
 
+// NewDot11ProbeRequest creates a probe request for ssid directed to bssid,
+// an access point hiding its SSID still answers it if the name matches.
+func NewDot11ProbeRequest(from net.HardwareAddr, bssid net.HardwareAddr, ssid string, seq uint16) (error, []byte) {
+	return Serialize(
+		&layers.RadioTap{},
+		&layers.Dot11{
+			Address1:       bssid,
+			Address2:       from,
+			Address3:       bssid,
+			Type:           layers.Dot11TypeMgmtProbeReq,
+			SequenceNumber: seq,
+		},
+		// the body of a probe request only has information elements
+		Dot11Info(layers.Dot11InformationElementIDSSID, []byte(ssid)),
+		Dot11Info(layers.Dot11InformationElementIDRates, supportedRates),
+	)
+}
+
 func NewDot11Deauth(a1 net.HardwareAddr, a2 net.HardwareAddr, a3 net.HardwareAddr, seq uint16) (error, []byte) {
+	return NewDot11DeauthWithReason(a1, a2, a3, seq, layers.Dot11ReasonClass2FromNonAuth)
+}
+
+func NewDot11DeauthWithReason(a1 net.HardwareAddr, a2 net.HardwareAddr, a3 net.HardwareAddr, seq uint16, reason layers.Dot11Reason) (error, []byte) {
 	return Serialize(
 		&layers.RadioTap{},
 		&layers.Dot11{
@@ -92,7 +116,23 @@ func NewDot11Deauth(a1 net.HardwareAddr, a2 net.HardwareAddr, a3 net.HardwareAdd
 			SequenceNumber: seq,
 		},
 		&layers.Dot11MgmtDeauthentication{
-			Reason: layers.Dot11ReasonClass2FromNonAuth,
+			Reason: reason,
+		},
+	)
+}
+
+func NewDot11Disassoc(a1 net.HardwareAddr, a2 net.HardwareAddr, a3 net.HardwareAddr, seq uint16, reason layers.Dot11Reason) (error, []byte) {
+	return Serialize(
+		&layers.RadioTap{},
+		&layers.Dot11{
+			Address1:       a1,
+			Address2:       a2,
+			Address3:       a3,
+			Type:           layers.Dot11TypeMgmtDisassociation,
+			SequenceNumber: seq,
+		},
+		&layers.Dot11MgmtDisassociation{
+			Reason: reason,
 		},
 	)
 }
@@ -126,8 +166,10 @@ func Dot11ParseIDSSID(packet gopacket.Packet) (bool, string) {
 		if layer.LayerType() == layers.LayerTypeDot11InformationElement {
 			dot11info, ok := layer.(*layers.Dot11InformationElement)
 			if ok && dot11info.ID == layers.Dot11InformationElementIDSSID {
-				if len(dot11info.Info) == 0 {
-					return true, "<hidden>"
+				// hidden networks either send an empty SSID or one
+				// made of null bytes
+				if len(bytes.Trim(dot11info.Info, "\x00")) == 0 {
+					return true, network.HiddenESSID
 				}
 				return true, string(dot11info.Info)
 			}
@@ -161,9 +203,7 @@ func Dot11ParseEncryption(packet gopacket.Packet, dot11 *layers.Dot11) (bool, st
 						for i = 0; i < rsn.Pairwise.Count; i++ {
 							cipher = rsn.Pairwise.Suites[i].Type.String()
 						}
-						for i = 0; i < rsn.AuthKey.Count; i++ {
-							auth = rsn.AuthKey.Suites[i].Type.String()
-						}
+						enc, auth = rsn.Labels()
 					}
 				} else if enc == "" && info.ID == layers.Dot11InformationElementIDVendor && info.Length >= 8 && bytes.Equal(info.OUI, wpaSignatureBytes) && bytes.HasPrefix(info.Info, []byte{1, 0}) {
 					enc = "WPA"
@@ -189,6 +229,21 @@ func Dot11ParseEncryption(packet gopacket.Packet, dot11 *layers.Dot11) (bool, st
 
 }
 
+// Dot11RateLabel describes the rate a frame was sent at from its radiotap
+// header: the VHT or HT MCS index if any, the legacy data rate otherwise.
+func Dot11RateLabel(radiotap *layers.RadioTap) (string, bool) {
+	if radiotap.Present.VHT() && radiotap.VHT.MCSNSS[0].Present() {
+		mcsnss := radiotap.VHT.MCSNSS[0]
+		return fmt.Sprintf("VHT MCS %d NSS %d", mcsnss>>4, mcsnss&0x0f), true
+	} else if radiotap.Present.MCS() && radiotap.MCS.Known.MCSIndex() {
+		return fmt.Sprintf("HT MCS %d", radiotap.MCS.MCS), true
+	} else if radiotap.Present.Rate() && radiotap.Rate > 0 {
+		// in units of 500 Kbps
+		return fmt.Sprintf("%g Mbps", float64(radiotap.Rate)/2), true
+	}
+	return "", false
+}
+
 func Dot11IsDataFor(dot11 *layers.Dot11, station net.HardwareAddr) bool {
 	// only check data packets of connected stations
 	if dot11.Type.MainType() != layers.Dot11TypeData {
@@ -214,3 +269,120 @@ func Dot11ParseDSSet(packet gopacket.Packet) (bool, int) {
 
 	return found, channel
 }
+
+const (
+	Dot11InformationElementIDChannelSwitch    layers.Dot11InformationElementID = 37
+	Dot11InformationElementIDExtChannelSwitch layers.Dot11InformationElementID = 60
+)
+
+// Dot11ParseChannelSwitch returns the channel an access point announced it
+// is moving to and in how many beacons, if any.
+func Dot11ParseChannelSwitch(packet gopacket.Packet) (found bool, channel int, count int) {
+	for _, layer := range packet.Layers() {
+		info, ok := layer.(*layers.Dot11InformationElement)
+		if !ok {
+			continue
+		}
+
+		var err error
+		if info.ID == Dot11InformationElementIDChannelSwitch {
+			channel, count, err = Dot11InformationElementIDChannelSwitchDecode(info.Info)
+		} else if info.ID == Dot11InformationElementIDExtChannelSwitch {
+			channel, count, err = Dot11InformationElementIDExtChannelSwitchDecode(info.Info)
+		} else {
+			continue
+		}
+
+		if err == nil {
+			return true, channel, count
+		}
+	}
+
+	return false, 0, 0
+}
+
+var eapMethodNames = map[layers.EAPType]string{
+	layers.EAPTypeIdentity:     "Identity",
+	layers.EAPTypeNotification: "Notification",
+	layers.EAPTypeNACK:         "NAK",
+	// gopacket OTP and token card constants don't match the IANA registry
+	4:  "MD5",
+	5:  "OTP",
+	6:  "GTC",
+	13: "TLS",
+	17: "LEAP",
+	18: "SIM",
+	21: "TTLS",
+	23: "AKA",
+	25: "PEAP",
+	26: "MSCHAPv2",
+	43: "FAST",
+	47: "PSK",
+	50: "AKA'",
+	52: "PWD",
+}
+
+// EAPMethodName returns a human readable name of an EAP method type.
+func EAPMethodName(t layers.EAPType) string {
+	if name, found := eapMethodNames[t]; found {
+		return name
+	}
+	return fmt.Sprintf("type %d", t)
+}
+
+// key information bits of the EAPOL-Key frames (IEEE 802.11-2016 12.7.2)
+const (
+	eapolKeyPairwise = 0x0008
+	eapolKeyInstall  = 0x0040
+	eapolKeyAck      = 0x0080
+	eapolKeyMIC      = 0x0100
+	eapolKeySecure   = 0x0200
+)
+
+// Dot11ParseEAPOLKey returns which message (1 to 4) of the 4-way handshake
+// the packet is, or 0 if it's not part of one.
+func Dot11ParseEAPOLKey(packet gopacket.Packet, dot11 *layers.Dot11) int {
+	if dot11.Type.MainType() != layers.Dot11TypeData || dot11.Flags.WEP() {
+		return 0
+	}
+
+	eapol, ok := packet.Layer(layers.LayerTypeEAPOL).(*layers.EAPOL)
+	if !ok || eapol.Type != layers.EAPOLTypeKey || len(eapol.Payload) < 3 {
+		return 0
+	}
+
+	// the descriptor type is followed by the key information
+	info := binary.BigEndian.Uint16(eapol.Payload[1:3])
+	if info&eapolKeyPairwise == 0 {
+		// group key handshake
+		return 0
+	}
+
+	ack, mic := info&eapolKeyAck != 0, info&eapolKeyMIC != 0
+	switch {
+	case ack && !mic:
+		return 1
+	case ack && mic && info&eapolKeyInstall != 0:
+		return 3
+	case !ack && mic && info&eapolKeySecure == 0:
+		return 2
+	case !ack && mic:
+		return 4
+	}
+	return 0
+}
+
+// Dot11ParseEAP returns the EAP layer of an unencrypted 802.1X data frame.
+func Dot11ParseEAP(packet gopacket.Packet, dot11 *layers.Dot11) (bool, *layers.EAP) {
+	if dot11.Type.MainType() != layers.Dot11TypeData || dot11.Flags.WEP() {
+		return false, nil
+	}
+
+	eapLayer := packet.Layer(layers.LayerTypeEAP)
+	if eapLayer == nil {
+		return false, nil
+	}
+
+	eap, ok := eapLayer.(*layers.EAP)
+	return ok, eap
+}
@@ -1,6 +1,7 @@
 package packets
 
 import (
+	"github.com/bettercap/bettercap/network"
 	"github.com/google/gopacket"
 	"github.com/google/gopacket/layers"
 	"net"
@@ -133,6 +134,40 @@ func TestNewDot11Deauth(t *testing.T) {
 	}
 }
 
+func TestNewDot11DeauthWithReason(t *testing.T) {
+	mac, _ := net.ParseMAC("00:00:00:00:00:00")
+
+	err, raw := NewDot11DeauthWithReason(mac, mac, mac, 0, layers.Dot11ReasonDisasStLeaving)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pkt := gopacket.NewPacket(raw, layers.LayerTypeRadioTap, gopacket.Default)
+	deauth, ok := pkt.Layer(layers.LayerTypeDot11MgmtDeauthentication).(*layers.Dot11MgmtDeauthentication)
+	if !ok {
+		t.Fatal("expected a deauthentication layer")
+	} else if deauth.Reason != layers.Dot11ReasonDisasStLeaving {
+		t.Fatalf("expected reason %v, got %v", layers.Dot11ReasonDisasStLeaving, deauth.Reason)
+	}
+}
+
+func TestNewDot11Disassoc(t *testing.T) {
+	mac, _ := net.ParseMAC("00:00:00:00:00:00")
+
+	err, raw := NewDot11Disassoc(mac, mac, mac, 0, layers.Dot11ReasonInactivity)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pkt := gopacket.NewPacket(raw, layers.LayerTypeRadioTap, gopacket.Default)
+	disassoc, ok := pkt.Layer(layers.LayerTypeDot11MgmtDisassociation).(*layers.Dot11MgmtDisassociation)
+	if !ok {
+		t.Fatal("expected a disassociation layer")
+	} else if disassoc.Reason != layers.Dot11ReasonInactivity {
+		t.Fatalf("expected reason %v, got %v", layers.Dot11ReasonInactivity, disassoc.Reason)
+	}
+}
+
 func BuildDot11Packet() gopacket.Packet {
 	mac, _ := net.ParseMAC("00:00:00:00:00:00")
 	seq := uint16(0)
@@ -268,7 +303,172 @@ func TestDot11IsDataFor(t *testing.T) {
 	}
 }
 
+func TestDot11ParseEAP(t *testing.T) {
+	client, _ := net.ParseMAC("aa:bb:cc:dd:ee:ff")
+	bssid, _ := net.ParseMAC("00:11:22:33:44:55")
+	identity := []byte("anonymous@example.com")
+	_, bytes := Serialize(
+		&layers.RadioTap{},
+		&layers.Dot11{
+			Address1: bssid,
+			Address2: client,
+			Address3: bssid,
+			Type:     layers.Dot11TypeData,
+			Flags:    layers.Dot11FlagsToDS,
+		},
+		&layers.LLC{DSAP: 0xaa, SSAP: 0xaa, Control: 0x03},
+		&layers.SNAP{OrganizationalCode: []byte{0, 0, 0}, Type: layers.EthernetTypeEAPOL},
+		&layers.EAPOL{Version: 1, Type: layers.EAPOLTypeEAP, Length: uint16(5 + len(identity))},
+		&layers.EAP{
+			Code:     layers.EAPCodeResponse,
+			Id:       1,
+			Length:   uint16(5 + len(identity)),
+			Type:     layers.EAPTypeIdentity,
+			TypeData: identity,
+		},
+	)
+	packet := gopacket.NewPacket(bytes, layers.LayerTypeRadioTap, gopacket.Default)
+	_, _, dot11 := Dot11Parse(packet)
+	ok, eap := Dot11ParseEAP(packet, dot11)
+	if !ok {
+		t.Fatal("unable to parse the EAP layer")
+	} else if eap.Code != layers.EAPCodeResponse || eap.Type != layers.EAPTypeIdentity {
+		t.Fatalf("unexpected EAP code %d and type %d", eap.Code, eap.Type)
+	} else if string(eap.TypeData) != string(identity) {
+		t.Fatalf("expected identity '%s', got '%s'", identity, eap.TypeData)
+	}
+}
+
+func TestEAPMethodName(t *testing.T) {
+	var units = []struct {
+		typ layers.EAPType
+		exp string
+	}{
+		{25, "PEAP"},
+		{21, "TTLS"},
+		{13, "TLS"},
+		{6, "GTC"},
+		{200, "type 200"},
+	}
+	for _, u := range units {
+		if got := EAPMethodName(u.typ); got != u.exp {
+			t.Fatalf("expected '%s', got '%s'", u.exp, got)
+		}
+	}
+}
+
 // TODO: add Dot11ParseDSSet test. Not sure how to build proper
 // example packet to complete this test, for now. <3
 //func TestDot11ParseDSSet(t *testing.T) {
 //}
+
+func TestNewDot11ProbeRequest(t *testing.T) {
+	from, _ := net.ParseMAC("01:23:45:67:89:ab")
+	bssid, _ := net.ParseMAC("aa:bb:cc:dd:ee:ff")
+
+	err, raw := NewDot11ProbeRequest(from, bssid, "hidden_wifi", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	packet := gopacket.NewPacket(raw, layers.LayerTypeRadioTap, gopacket.Default)
+	if ok, _, dot11 := Dot11Parse(packet); !ok {
+		t.Fatal("unable to parse the probe request")
+	} else if dot11.Type != layers.Dot11TypeMgmtProbeReq || dot11.Address1.String() != bssid.String() {
+		t.Fatalf("unexpected dot11 layer %+v", dot11)
+	}
+
+	// the probe request layer doesn't decode its information elements
+	req := packet.Layer(layers.LayerTypeDot11MgmtProbeReq)
+	if req == nil {
+		t.Fatal("no probe request layer")
+	} else if data := req.LayerContents(); len(data) < 13 || data[0] != 0 || string(data[2:2+data[1]]) != "hidden_wifi" {
+		t.Fatalf("unexpected probe request contents %x", data)
+	}
+}
+
+func TestDot11ParseIDSSIDHidden(t *testing.T) {
+	conf := BuildDot11ApConfig()
+	conf.SSID = "\x00\x00\x00\x00"
+
+	_, raw := NewDot11Beacon(conf, 0)
+	packet := gopacket.NewPacket(raw, layers.LayerTypeRadioTap, gopacket.Default)
+	if ok, ssid := Dot11ParseIDSSID(packet); !ok || ssid != network.HiddenESSID {
+		t.Fatalf("expected '%s', got '%s'", network.HiddenESSID, ssid)
+	}
+}
+
+func TestDot11ParseEAPOLKey(t *testing.T) {
+	client, _ := net.ParseMAC("aa:bb:cc:dd:ee:ff")
+	bssid, _ := net.ParseMAC("00:11:22:33:44:55")
+
+	var units = []struct {
+		info uint16
+		exp  int
+	}{
+		{0x008a, 1},
+		{0x010a, 2},
+		{0x13ca, 3},
+		{0x030a, 4},
+		// group key message
+		{0x1382, 0},
+	}
+
+	for _, u := range units {
+		key := make([]byte, 95)
+		key[0] = 2 // RSN descriptor
+		key[1], key[2] = byte(u.info>>8), byte(u.info)
+
+		_, raw := Serialize(
+			&layers.RadioTap{},
+			&layers.Dot11{
+				Address1: client,
+				Address2: bssid,
+				Address3: bssid,
+				Type:     layers.Dot11TypeData,
+				Flags:    layers.Dot11FlagsFromDS,
+			},
+			&layers.LLC{DSAP: 0xaa, SSAP: 0xaa, Control: 0x03},
+			&layers.SNAP{OrganizationalCode: []byte{0, 0, 0}, Type: layers.EthernetTypeEAPOL},
+			&layers.EAPOL{Version: 2, Type: layers.EAPOLTypeKey, Length: uint16(len(key))},
+			gopacket.Payload(key),
+		)
+		packet := gopacket.NewPacket(raw, layers.LayerTypeRadioTap, gopacket.Default)
+		_, _, dot11 := Dot11Parse(packet)
+		if got := Dot11ParseEAPOLKey(packet, dot11); got != u.exp {
+			t.Fatalf("key information %04x: expected message %d, got %d", u.info, u.exp, got)
+		}
+	}
+}
+
+func TestDot11RateLabel(t *testing.T) {
+	var units = []struct {
+		radiotap layers.RadioTap
+		exp      string
+		ok       bool
+	}{
+		{layers.RadioTap{}, "", false},
+		{layers.RadioTap{Present: layers.RadioTapPresentRate, Rate: 11}, "5.5 Mbps", true},
+		{layers.RadioTap{Present: layers.RadioTapPresentRate, Rate: 108}, "54 Mbps", true},
+		{layers.RadioTap{
+			Present: layers.RadioTapPresentRate | layers.RadioTapPresentMCS,
+			Rate:    2,
+			MCS:     layers.RadioTapMCS{Known: layers.RadioTapMCSKnownMCSIndex, MCS: 7},
+		}, "HT MCS 7", true},
+		{layers.RadioTap{
+			Present: layers.RadioTapPresentMCS,
+			MCS:     layers.RadioTapMCS{MCS: 7},
+		}, "", false},
+		{layers.RadioTap{
+			Present: layers.RadioTapPresentVHT,
+			VHT:     layers.RadioTapVHT{MCSNSS: [4]layers.RadioTapVHTMCSNSS{0x92}},
+		}, "VHT MCS 9 NSS 2", true},
+	}
+
+	for _, u := range units {
+		got, ok := Dot11RateLabel(&u.radiotap)
+		if got != u.exp || ok != u.ok {
+			t.Fatalf("expected '%s' (%v), got '%s' (%v)", u.exp, u.ok, got, ok)
+		}
+	}
+}
@@ -35,8 +35,20 @@ func (a Dot11CipherType) String() string {
 type Dot11AuthType uint8
 
 const (
-	Dot11AuthMgt Dot11AuthType = 1
-	Dot11AuthPsk Dot11AuthType = 2
+	Dot11AuthMgt         Dot11AuthType = 1
+	Dot11AuthPsk         Dot11AuthType = 2
+	Dot11AuthFtMgt       Dot11AuthType = 3
+	Dot11AuthFtPsk       Dot11AuthType = 4
+	Dot11AuthMgtSha256   Dot11AuthType = 5
+	Dot11AuthPskSha256   Dot11AuthType = 6
+	Dot11AuthSae         Dot11AuthType = 8
+	Dot11AuthFtSae       Dot11AuthType = 9
+	Dot11AuthSuiteB      Dot11AuthType = 11
+	Dot11AuthSuiteB192   Dot11AuthType = 12
+	Dot11AuthFtSuiteB192 Dot11AuthType = 13
+	Dot11AuthOwe         Dot11AuthType = 18
+	Dot11AuthSaeExtKey   Dot11AuthType = 24
+	Dot11AuthFtSaeExtKey Dot11AuthType = 25
 )
 
 func (a Dot11AuthType) String() string {
@@ -45,11 +57,51 @@ func (a Dot11AuthType) String() string {
 		return "MGT"
 	case Dot11AuthPsk:
 		return "PSK"
+	case Dot11AuthFtMgt:
+		return "FT-MGT"
+	case Dot11AuthFtPsk:
+		return "FT-PSK"
+	case Dot11AuthMgtSha256:
+		return "MGT-SHA256"
+	case Dot11AuthPskSha256:
+		return "PSK-SHA256"
+	case Dot11AuthSae, Dot11AuthSaeExtKey:
+		return "SAE"
+	case Dot11AuthFtSae, Dot11AuthFtSaeExtKey:
+		return "FT-SAE"
+	case Dot11AuthSuiteB:
+		return "SUITE-B"
+	case Dot11AuthSuiteB192, Dot11AuthFtSuiteB192:
+		return "SUITE-B-192"
+	case Dot11AuthOwe:
+		return "OWE"
 	default:
 		return "UNK"
 	}
 }
 
+func (a Dot11AuthType) IsPSK() bool {
+	return a == Dot11AuthPsk || a == Dot11AuthFtPsk || a == Dot11AuthPskSha256
+}
+
+func (a Dot11AuthType) IsSAE() bool {
+	return a == Dot11AuthSae || a == Dot11AuthFtSae || a == Dot11AuthSaeExtKey || a == Dot11AuthFtSaeExtKey
+}
+
+func (a Dot11AuthType) IsMGT() bool {
+	return a == Dot11AuthMgt || a == Dot11AuthFtMgt
+}
+
+func (a Dot11AuthType) IsSuiteB() bool {
+	return a == Dot11AuthSuiteB || a == Dot11AuthSuiteB192 || a == Dot11AuthFtSuiteB192
+}
+
+// IsWPA3MGT tells if the suite is one of the WPA3-Enterprise ones, 802.1X
+// with SHA-256 or one of the Suite B ones.
+func (a Dot11AuthType) IsWPA3MGT() bool {
+	return a == Dot11AuthMgtSha256 || a.IsSuiteB()
+}
+
 type CipherSuite struct {
 	OUI  []byte // 3 bytes
 	Type Dot11CipherType
@@ -77,6 +129,49 @@ type RSNInfo struct {
 	AuthKey  AuthSuiteSelector
 }
 
+// Labels returns the encryption and authentication labels of the network
+// given its AKM suites, telling WPA2 from WPA3 and transition mode apart.
+func (rsn RSNInfo) Labels() (enc string, auth string) {
+	psk, sae, mgt, wpa3mgt, owe := false, false, false, false, false
+	for _, suite := range rsn.AuthKey.Suites {
+		switch {
+		case suite.Type.IsPSK():
+			psk = true
+		case suite.Type.IsSAE():
+			sae = true
+		case suite.Type.IsMGT():
+			mgt = true
+		case suite.Type.IsWPA3MGT():
+			wpa3mgt = true
+		case suite.Type == Dot11AuthOwe:
+			owe = true
+		}
+	}
+
+	switch {
+	case sae && psk:
+		return "WPA2/WPA3", "PSK/SAE"
+	case sae:
+		return "WPA3", "SAE"
+	case wpa3mgt && mgt:
+		return "WPA2/WPA3", "MGT"
+	case wpa3mgt:
+		return "WPA3", "MGT"
+	case owe:
+		return "OWE", "OWE"
+	case psk:
+		return "WPA2", "PSK"
+	case mgt:
+		return "WPA2", "MGT"
+	}
+
+	enc = "WPA2"
+	if n := len(rsn.AuthKey.Suites); n > 0 {
+		auth = rsn.AuthKey.Suites[n-1].Type.String()
+	}
+	return
+}
+
 type VendorInfo struct {
 	WPAVersion uint16
 	Multicast  CipherSuite
@@ -219,3 +314,25 @@ func Dot11InformationElementIDDSSetDecode(buf []byte) (channel int, err error) {
 
 	return
 }
+
+// Dot11InformationElementIDChannelSwitchDecode returns the channel and the
+// number of beacons before the switch announced by a CSA element.
+func Dot11InformationElementIDChannelSwitchDecode(buf []byte) (channel int, count int, err error) {
+	if err = canParse("CSA", buf, 3); err == nil {
+		channel = int(buf[1])
+		count = int(buf[2])
+	}
+
+	return
+}
+
+// Dot11InformationElementIDExtChannelSwitchDecode does the same for the
+// extended CSA element, which also announces the new operating class.
+func Dot11InformationElementIDExtChannelSwitchDecode(buf []byte) (channel int, count int, err error) {
+	if err = canParse("ECSA", buf, 4); err == nil {
+		channel = int(buf[2])
+		count = int(buf[3])
+	}
+
+	return
+}
@@ -168,6 +168,39 @@ func TestDot11RSNInfo(t *testing.T) {
 	}
 }
 
+func TestDot11RSNInfoLabels(t *testing.T) {
+	withSuites := func(types ...Dot11AuthType) RSNInfo {
+		rsn := RSNInfo{}
+		for _, t := range types {
+			rsn.AuthKey.Suites = append(rsn.AuthKey.Suites, AuthSuite{Type: t})
+		}
+		rsn.AuthKey.Count = uint16(len(types))
+		return rsn
+	}
+
+	var units = []struct {
+		rsn  RSNInfo
+		enc  string
+		auth string
+	}{
+		{withSuites(Dot11AuthPsk), "WPA2", "PSK"},
+		{withSuites(Dot11AuthMgt), "WPA2", "MGT"},
+		{withSuites(Dot11AuthSae), "WPA3", "SAE"},
+		{withSuites(Dot11AuthPsk, Dot11AuthSae), "WPA2/WPA3", "PSK/SAE"},
+		{withSuites(Dot11AuthSuiteB192), "WPA3", "MGT"},
+		{withSuites(Dot11AuthMgt, Dot11AuthSuiteB192), "WPA2/WPA3", "MGT"},
+		// WPA3-Enterprise, 802.1X with SHA-256
+		{withSuites(Dot11AuthMgtSha256), "WPA3", "MGT"},
+		{withSuites(Dot11AuthMgt, Dot11AuthMgtSha256), "WPA2/WPA3", "MGT"},
+	}
+	for _, u := range units {
+		enc, auth := u.rsn.Labels()
+		if enc != u.enc || auth != u.auth {
+			t.Fatalf("expected '%s (%s)', got '%s (%s)'", u.enc, u.auth, enc, auth)
+		}
+	}
+}
+
 func TestDot11VendorInfo(t *testing.T) {
 	version := uint16(1)
 	vendor := VendorInfo{
@@ -234,3 +267,21 @@ func TestDot11parseAuthkeySuite(t *testing.T) {
 
 // TODO: add test for Dot11InformationElementVendorInfoDecode
 // TODO: add test for Dot11InformationElementIDDSSetDecode
+
+func TestDot11InformationElementIDChannelSwitchDecode(t *testing.T) {
+	channel, count, err := Dot11InformationElementIDChannelSwitchDecode([]byte{1, 36, 5})
+	if err != nil || channel != 36 || count != 5 {
+		t.Fatalf("expected channel 36 in 5 beacons, got %d in %d (%v)", channel, count, err)
+	}
+
+	channel, count, err = Dot11InformationElementIDExtChannelSwitchDecode([]byte{0, 128, 149, 0})
+	if err != nil || channel != 149 || count != 0 {
+		t.Fatalf("expected channel 149 in 0 beacons, got %d in %d (%v)", channel, count, err)
+	}
+
+	if _, _, err = Dot11InformationElementIDChannelSwitchDecode([]byte{1, 36}); err == nil {
+		t.Fatal("expected a truncated CSA element to be rejected")
+	} else if _, _, err = Dot11InformationElementIDExtChannelSwitchDecode([]byte{0, 128, 149}); err == nil {
+		t.Fatal("expected a truncated ECSA element to be rejected")
+	}
+}
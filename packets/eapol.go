@@ -0,0 +1,121 @@
+package packets
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+// offsets of the EAPOL-Key fields from the start of the 802.1X header
+const (
+	eapolKeyReplayOffset  = 9
+	eapolKeyNonceOffset   = 17
+	eapolKeyMICOffset     = 81
+	eapolKeyDataLenOffset = 97
+	eapolKeyDataOffset    = 99
+)
+
+// RSN PMKID KDE header (IEEE 802.11-2016 12.7.2)
+var pmkidKDE = []byte{0xdd, 0x14, 0x00, 0x0f, 0xac, 0x04}
+
+// EAPOLKey has the fields of an EAPOL-Key frame needed to crack the key.
+type EAPOLKey struct {
+	// message of the 4-way handshake, 1 to 4
+	Message int
+	// increased by the access point for every new handshake and echoed by
+	// the client, used to tell which messages belong together
+	ReplayCounter uint64
+	Nonce         []byte
+	MIC           []byte
+	// the whole 802.1X frame
+	Frame []byte
+	// only found in the first message, if the access point sends it
+	PMKID []byte
+}
+
+// Dot11ParseEAPOLKeyFrame returns the fields of the EAPOL-Key frame in the
+// packet if it's one of the 4-way handshake messages.
+func Dot11ParseEAPOLKeyFrame(packet gopacket.Packet, dot11 *layers.Dot11) *EAPOLKey {
+	n := Dot11ParseEAPOLKey(packet, dot11)
+	if n == 0 {
+		return nil
+	}
+
+	eapol := packet.Layer(layers.LayerTypeEAPOL).(*layers.EAPOL)
+	frame := append(append([]byte{}, eapol.Contents...), eapol.Payload...)
+	if size := 4 + int(eapol.Length); size < len(frame) {
+		// strip the padding
+		frame = frame[:size]
+	}
+
+	if len(frame) < eapolKeyDataOffset {
+		return nil
+	}
+
+	key := &EAPOLKey{
+		Message:       n,
+		ReplayCounter: binary.BigEndian.Uint64(frame[eapolKeyReplayOffset:]),
+		Nonce:         frame[eapolKeyNonceOffset : eapolKeyNonceOffset+32],
+		MIC:           frame[eapolKeyMICOffset : eapolKeyMICOffset+16],
+		Frame:         frame,
+	}
+
+	if n == 1 {
+		dataLen := int(binary.BigEndian.Uint16(frame[eapolKeyDataLenOffset:]))
+		data := frame[eapolKeyDataOffset:]
+		if dataLen < len(data) {
+			data = data[:dataLen]
+		}
+
+		if idx := bytes.Index(data, pmkidKDE); idx >= 0 && len(data) >= idx+len(pmkidKDE)+16 {
+			pmkid := data[idx+len(pmkidKDE) : idx+len(pmkidKDE)+16]
+			if !bytes.Equal(pmkid, make([]byte, 16)) {
+				key.PMKID = pmkid
+			}
+		}
+	}
+
+	return key
+}
+
+func hashcatMAC(hw net.HardwareAddr) string {
+	return strings.Replace(hw.String(), ":", "", -1)
+}
+
+// Hashcat22000PMKID returns the hashcat 22000 line of a PMKID.
+func Hashcat22000PMKID(pmkid []byte, ap net.HardwareAddr, client net.HardwareAddr, essid string) string {
+	return fmt.Sprintf("WPA*01*%x*%s*%s*%s***",
+		pmkid,
+		hashcatMAC(ap),
+		hashcatMAC(client),
+		hex.EncodeToString([]byte(essid)))
+}
+
+// Hashcat22000EAPOL returns the hashcat 22000 line of a handshake, from the
+// nonce of the access point (first or third message) and the second message.
+func Hashcat22000EAPOL(anonce *EAPOLKey, m2 *EAPOLKey, ap net.HardwareAddr, client net.HardwareAddr, essid string) string {
+	// message pair 0 is M1+M2 and 2 is M2+M3
+	pair := 0
+	if anonce.Message == 3 {
+		pair = 2
+	}
+
+	// the MIC is zeroed before being computed
+	frame := append([]byte{}, m2.Frame...)
+	copy(frame[eapolKeyMICOffset:eapolKeyMICOffset+16], make([]byte, 16))
+
+	return fmt.Sprintf("WPA*02*%x*%s*%s*%s*%x*%x*%02x",
+		m2.MIC,
+		hashcatMAC(ap),
+		hashcatMAC(client),
+		hex.EncodeToString([]byte(essid)),
+		anonce.Nonce,
+		frame,
+		pair)
+}
@@ -0,0 +1,106 @@
+package packets
+
+import (
+	"encoding/binary"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+func newEAPOLKeyPacket(info uint16, fill byte, data []byte) (gopacket.Packet, *layers.Dot11) {
+	client, _ := net.ParseMAC("aa:bb:cc:dd:ee:ff")
+	bssid, _ := net.ParseMAC("00:11:22:33:44:55")
+
+	key := make([]byte, 95, 95+len(data))
+	key[0] = 2
+	key[1], key[2] = byte(info>>8), byte(info)
+	// replay counter
+	binary.BigEndian.PutUint64(key[5:], uint64(fill))
+	for i := 13; i < 45; i++ {
+		// nonce
+		key[i] = fill
+	}
+	for i := 77; i < 93; i++ {
+		// MIC
+		key[i] = fill + 1
+	}
+	key[93], key[94] = byte(len(data)>>8), byte(len(data))
+	key = append(key, data...)
+
+	_, raw := Serialize(
+		&layers.RadioTap{},
+		&layers.Dot11{
+			Address1: client,
+			Address2: bssid,
+			Address3: bssid,
+			Type:     layers.Dot11TypeData,
+			Flags:    layers.Dot11FlagsFromDS,
+		},
+		&layers.LLC{DSAP: 0xaa, SSAP: 0xaa, Control: 0x03},
+		&layers.SNAP{OrganizationalCode: []byte{0, 0, 0}, Type: layers.EthernetTypeEAPOL},
+		&layers.EAPOL{Version: 2, Type: layers.EAPOLTypeKey, Length: uint16(len(key))},
+		gopacket.Payload(key),
+	)
+	packet := gopacket.NewPacket(raw, layers.LayerTypeRadioTap, gopacket.Default)
+	_, _, dot11 := Dot11Parse(packet)
+	return packet, dot11
+}
+
+func TestDot11ParseEAPOLKeyFrame(t *testing.T) {
+	pmkid := []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+	packet, dot11 := newEAPOLKeyPacket(0x008a, 0xaa, append(append([]byte{}, pmkidKDE...), pmkid...))
+
+	key := Dot11ParseEAPOLKeyFrame(packet, dot11)
+	if key == nil {
+		t.Fatal("unable to parse the EAPOL-Key frame")
+	} else if key.Message != 1 {
+		t.Fatalf("expected message 1, got %d", key.Message)
+	} else if key.ReplayCounter != 0xaa {
+		t.Fatalf("unexpected replay counter %d", key.ReplayCounter)
+	} else if len(key.Nonce) != 32 || key.Nonce[0] != 0xaa || key.Nonce[31] != 0xaa {
+		t.Fatalf("unexpected nonce %x", key.Nonce)
+	} else if string(key.PMKID) != string(pmkid) {
+		t.Fatalf("expected PMKID %x, got %x", pmkid, key.PMKID)
+	} else if len(key.Frame) != 4+95+22 {
+		t.Fatalf("unexpected frame size %d", len(key.Frame))
+	}
+
+	packet, dot11 = newEAPOLKeyPacket(0x010a, 0xbb, nil)
+	if key = Dot11ParseEAPOLKeyFrame(packet, dot11); key == nil || key.Message != 2 || key.PMKID != nil {
+		t.Fatalf("unexpected second message %+v", key)
+	} else if key.MIC[0] != 0xbc || key.MIC[15] != 0xbc {
+		t.Fatalf("unexpected MIC %x", key.MIC)
+	}
+}
+
+func TestHashcat22000(t *testing.T) {
+	client, _ := net.ParseMAC("aa:bb:cc:dd:ee:ff")
+	bssid, _ := net.ParseMAC("00:11:22:33:44:55")
+
+	exp := "WPA*01*0102*001122334455*aabbccddeeff*74657374***"
+	if got := Hashcat22000PMKID([]byte{1, 2}, bssid, client, "test"); got != exp {
+		t.Fatalf("expected '%s', got '%s'", exp, got)
+	}
+
+	packet, dot11 := newEAPOLKeyPacket(0x008a, 0xaa, nil)
+	m1 := Dot11ParseEAPOLKeyFrame(packet, dot11)
+	packet, dot11 = newEAPOLKeyPacket(0x010a, 0xbb, nil)
+	m2 := Dot11ParseEAPOLKeyFrame(packet, dot11)
+
+	line := Hashcat22000EAPOL(m1, m2, bssid, client, "test")
+	parts := strings.Split(line, "*")
+	if len(parts) != 9 || parts[0] != "WPA" || parts[1] != "02" || parts[8] != "00" {
+		t.Fatalf("unexpected line '%s'", line)
+	} else if parts[2] != strings.Repeat("bc", 16) {
+		t.Fatalf("unexpected MIC %s", parts[2])
+	} else if parts[6] != strings.Repeat("aa", 32) {
+		t.Fatalf("unexpected nonce %s", parts[6])
+	} else if strings.Contains(parts[7], strings.Repeat("bc", 16)) {
+		t.Fatal("the MIC must be zeroed in the EAPOL frame")
+	} else if m2.MIC[0] != 0xbc {
+		t.Fatal("the original frame must not be changed")
+	}
+}
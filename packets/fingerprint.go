@@ -0,0 +1,78 @@
+package packets
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+var httpMethods = [][]byte{[]byte("GET "), []byte("POST "), []byte("HEAD "), []byte("PUT "), []byte("OPTIONS ")}
+
+// DHCPGetFingerprint returns the option 55 (parameter request list) of a
+// DHCP client request as a comma separated list of option codes, and the
+// address the client is asking for, if any.
+func DHCPGetFingerprint(pkt gopacket.Packet) (string, net.IP) {
+	ldhcp := pkt.Layer(layers.LayerTypeDHCPv4)
+	if ldhcp == nil {
+		return "", nil
+	}
+
+	dhcp := ldhcp.(*layers.DHCPv4)
+	if dhcp.Operation != layers.DHCPOpRequest {
+		return "", nil
+	}
+
+	fingerprint := ""
+	requested := net.IP(nil)
+	for _, opt := range dhcp.Options {
+		if opt.Type == layers.DHCPOptParamsRequest {
+			codes := make([]string, len(opt.Data))
+			for i, code := range opt.Data {
+				codes[i] = strconv.Itoa(int(code))
+			}
+			fingerprint = strings.Join(codes, ",")
+		} else if opt.Type == layers.DHCPOptRequestIP && len(opt.Data) == 4 {
+			requested = net.IP(opt.Data)
+		}
+	}
+
+	if requested == nil && !dhcp.ClientIP.IsUnspecified() {
+		requested = dhcp.ClientIP
+	}
+
+	return fingerprint, requested
+}
+
+// HTTPGetUserAgent returns the User-Agent of a plain text HTTP request.
+func HTTPGetUserAgent(pkt gopacket.Packet) string {
+	ltcp := pkt.Layer(layers.LayerTypeTCP)
+	if ltcp == nil {
+		return ""
+	}
+
+	payload := ltcp.(*layers.TCP).Payload
+	isRequest := false
+	for _, method := range httpMethods {
+		if bytes.HasPrefix(payload, method) {
+			isRequest = true
+			break
+		}
+	}
+	if !isRequest {
+		return ""
+	}
+
+	// same parsing as the net.sniff http parser, the headers might be
+	// split across segments so only complete ones are considered
+	req, err := http.ReadRequest(bufio.NewReader(bytes.NewReader(payload)))
+	if err != nil {
+		return ""
+	}
+	return req.UserAgent()
+}
@@ -0,0 +1,83 @@
+package packets
+
+import (
+	"net"
+	"testing"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+func TestDHCPGetFingerprint(t *testing.T) {
+	hw, _ := net.ParseMAC("aa:bb:cc:dd:ee:ff")
+	eth := layers.Ethernet{SrcMAC: hw, DstMAC: net.HardwareAddr{0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, EthernetType: layers.EthernetTypeIPv4}
+	ip4 := layers.IPv4{Version: 4, TTL: 64, Protocol: layers.IPProtocolUDP, SrcIP: net.IPv4zero, DstIP: net.IPv4bcast}
+	udp := layers.UDP{SrcPort: 68, DstPort: 67}
+	udp.SetNetworkLayerForChecksum(&ip4)
+	dhcp := layers.DHCPv4{
+		Operation:    layers.DHCPOpRequest,
+		HardwareType: layers.LinkTypeEthernet,
+		ClientHWAddr: hw,
+		Options: layers.DHCPOptions{
+			layers.NewDHCPOption(layers.DHCPOptMessageType, []byte{byte(layers.DHCPMsgTypeRequest)}),
+			layers.NewDHCPOption(layers.DHCPOptRequestIP, []byte{192, 168, 1, 23}),
+			layers.NewDHCPOption(layers.DHCPOptParamsRequest, []byte{1, 121, 3, 6, 15, 119, 252}),
+		},
+	}
+
+	err, raw := Serialize(&eth, &ip4, &udp, &dhcp)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	pkt := gopacket.NewPacket(raw, layers.LayerTypeEthernet, gopacket.Default)
+	fingerprint, requested := DHCPGetFingerprint(pkt)
+	if fingerprint != "1,121,3,6,15,119,252" {
+		t.Fatalf("unexpected fingerprint '%s'", fingerprint)
+	} else if !requested.Equal(net.IPv4(192, 168, 1, 23)) {
+		t.Fatalf("unexpected requested address %s", requested)
+	}
+}
+
+func newTCPPayloadPacket(payload string) gopacket.Packet {
+	ip4 := layers.IPv4{Version: 4, TTL: 64, Protocol: layers.IPProtocolTCP, SrcIP: net.IPv4(10, 0, 0, 2), DstIP: net.IPv4(10, 0, 0, 1)}
+	tcp := layers.TCP{SrcPort: 40000, DstPort: 80, PSH: true, ACK: true}
+	tcp.SetNetworkLayerForChecksum(&ip4)
+
+	_, raw := Serialize(&ip4, &tcp, gopacket.Payload([]byte(payload)))
+	return gopacket.NewPacket(raw, layers.LayerTypeIPv4, gopacket.Default)
+}
+
+func TestHTTPGetUserAgent(t *testing.T) {
+	pkt := newTCPPayloadPacket("GET / HTTP/1.1\r\nHost: example.com\r\nuser-agent:  Mozilla/5.0 (Macintosh)\r\n\r\n")
+	if ua := HTTPGetUserAgent(pkt); ua != "Mozilla/5.0 (Macintosh)" {
+		t.Fatalf("unexpected user agent '%s'", ua)
+	}
+
+	pkt = newTCPPayloadPacket("HTTP/1.1 200 OK\r\nUser-Agent: nope\r\n\r\n")
+	if ua := HTTPGetUserAgent(pkt); ua != "" {
+		t.Fatalf("expected no user agent in a response, got '%s'", ua)
+	}
+}
+
+func TestMDNSGetServices(t *testing.T) {
+	ip4 := layers.IPv4{Version: 4, TTL: 255, Protocol: layers.IPProtocolUDP, SrcIP: net.IPv4(10, 0, 0, 2), DstIP: MDNSDestIP}
+	udp := layers.UDP{SrcPort: MDNSPort, DstPort: MDNSPort}
+	udp.SetNetworkLayerForChecksum(&ip4)
+	dns := layers.DNS{
+		QR: true,
+		Answers: []layers.DNSResourceRecord{
+			{Name: []byte("_services._dns-sd._udp.local"), Type: layers.DNSTypePTR, Class: layers.DNSClassIN, PTR: []byte("_ipp._tcp.local")},
+			{Name: []byte("_ipp._tcp.local"), Type: layers.DNSTypePTR, Class: layers.DNSClassIN, PTR: []byte("Office Printer._ipp._tcp.local")},
+			{Name: []byte("_http._tcp.local"), Type: layers.DNSTypePTR, Class: layers.DNSClassIN, PTR: []byte("Office Printer._http._tcp.local")},
+		},
+	}
+
+	_, raw := Serialize(&ip4, &udp, &dns)
+	pkt := gopacket.NewPacket(raw, layers.LayerTypeIPv4, gopacket.Default)
+
+	services := MDNSGetServices(pkt)
+	if len(services) != 2 || services[0] != "_ipp._tcp" || services[1] != "_http._tcp" {
+		t.Fatalf("unexpected services %v", services)
+	}
+}
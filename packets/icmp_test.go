@@ -0,0 +1,38 @@
+package packets
+
+import (
+	"bytes"
+	"net"
+	"testing"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+func TestNewICMPEcho(t *testing.T) {
+	from := net.ParseIP("192.168.1.1").To4()
+	fromHW, _ := net.ParseMAC("aa:bb:cc:dd:ee:ff")
+	to := net.ParseIP("192.168.1.2").To4()
+	toHW, _ := net.ParseMAC("11:22:33:44:55:66")
+
+	err, raw := NewICMPEcho(from, fromHW, to, toHW, 1234, 7)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	pkt := gopacket.NewPacket(raw, layers.LayerTypeEthernet, gopacket.Default)
+	eth := pkt.Layer(layers.LayerTypeEthernet).(*layers.Ethernet)
+	ip4 := pkt.Layer(layers.LayerTypeIPv4).(*layers.IPv4)
+	icmp, ok := pkt.Layer(layers.LayerTypeICMPv4).(*layers.ICMPv4)
+	if !ok {
+		t.Fatal("no ICMPv4 layer")
+	}
+
+	if !bytes.Equal(eth.SrcMAC, fromHW) || !bytes.Equal(eth.DstMAC, toHW) {
+		t.Fatalf("unexpected ethernet addresses %s -> %s", eth.SrcMAC, eth.DstMAC)
+	} else if !ip4.SrcIP.Equal(from) || !ip4.DstIP.Equal(to) {
+		t.Fatalf("unexpected IP addresses %s -> %s", ip4.SrcIP, ip4.DstIP)
+	} else if icmp.TypeCode.Type() != layers.ICMPv4TypeEchoRequest || icmp.Id != 1234 || icmp.Seq != 7 {
+		t.Fatalf("unexpected ICMP layer %+v", icmp)
+	}
+}
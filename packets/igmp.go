@@ -0,0 +1,47 @@
+package packets
+
+import (
+	"net"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+// IGMPGetGroups parses IGMP membership reports and returns the multicast
+// groups the sender joined and left.
+func IGMPGetGroups(pkt gopacket.Packet) (joined []net.IP, left []net.IP) {
+	ligmp := pkt.Layer(layers.LayerTypeIGMP)
+	if ligmp == nil {
+		return
+	}
+
+	switch igmp := ligmp.(type) {
+	case *layers.IGMPv1or2:
+		if igmp.Type == layers.IGMPMembershipReportV1 || igmp.Type == layers.IGMPMembershipReportV2 {
+			joined = append(joined, igmp.GroupAddress)
+		} else if igmp.Type == layers.IGMPLeaveGroup {
+			left = append(left, igmp.GroupAddress)
+		}
+
+	case *layers.IGMP:
+		if igmp.Type != layers.IGMPMembershipReportV3 {
+			return
+		}
+
+		for _, record := range igmp.GroupRecords {
+			switch record.Type {
+			case layers.IGMPIsEx, layers.IGMPToEx, layers.IGMPAllow:
+				joined = append(joined, record.MulticastAddress)
+			case layers.IGMPIsIn, layers.IGMPToIn:
+				// including no sources means not receiving anything
+				if record.NumberOfSources == 0 {
+					left = append(left, record.MulticastAddress)
+				} else {
+					joined = append(joined, record.MulticastAddress)
+				}
+			}
+		}
+	}
+
+	return
+}
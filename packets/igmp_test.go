@@ -0,0 +1,63 @@
+package packets
+
+import (
+	"net"
+	"testing"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+func buildIGMPPacket(t *testing.T, igmp []byte) gopacket.Packet {
+	eth := layers.Ethernet{
+		SrcMAC:       net.HardwareAddr{0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff},
+		DstMAC:       net.HardwareAddr{0x01, 0x00, 0x5e, 0x00, 0x00, 0xfb},
+		EthernetType: layers.EthernetTypeIPv4,
+	}
+	ip4 := layers.IPv4{
+		Version:  4,
+		TTL:      1,
+		Protocol: layers.IPProtocolIGMP,
+		SrcIP:    net.ParseIP("192.168.1.10"),
+		DstIP:    net.ParseIP("224.0.0.251"),
+	}
+
+	err, raw := Serialize(&eth, &ip4, gopacket.Payload(igmp))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return gopacket.NewPacket(raw, layers.LayerTypeEthernet, gopacket.Default)
+}
+
+func TestIGMPGetGroups(t *testing.T) {
+	// IGMPv2 membership report for 224.0.0.251
+	pkt := buildIGMPPacket(t, []byte{0x16, 0x00, 0x00, 0x00, 224, 0, 0, 251})
+	joined, left := IGMPGetGroups(pkt)
+	if len(joined) != 1 || !joined[0].Equal(net.ParseIP("224.0.0.251")) {
+		t.Fatalf("expected 224.0.0.251 to be joined, got %v", joined)
+	} else if len(left) != 0 {
+		t.Fatalf("expected no groups left, got %v", left)
+	}
+
+	// IGMPv2 leave group for 239.255.255.250
+	pkt = buildIGMPPacket(t, []byte{0x17, 0x00, 0x00, 0x00, 239, 255, 255, 250})
+	joined, left = IGMPGetGroups(pkt)
+	if len(left) != 1 || !left[0].Equal(net.ParseIP("239.255.255.250")) {
+		t.Fatalf("expected 239.255.255.250 to be left, got %v", left)
+	} else if len(joined) != 0 {
+		t.Fatalf("expected no groups joined, got %v", joined)
+	}
+
+	// IGMPv3 report, exclude mode for 239.255.255.250, include none for 224.0.0.251
+	pkt = buildIGMPPacket(t, []byte{
+		0x22, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02,
+		0x02, 0x00, 0x00, 0x00, 239, 255, 255, 250,
+		0x03, 0x00, 0x00, 0x00, 224, 0, 0, 251,
+	})
+	joined, left = IGMPGetGroups(pkt)
+	if len(joined) != 1 || !joined[0].Equal(net.ParseIP("239.255.255.250")) {
+		t.Fatalf("expected 239.255.255.250 to be joined, got %v", joined)
+	} else if len(left) != 1 || !left[0].Equal(net.ParseIP("224.0.0.251")) {
+		t.Fatalf("expected 224.0.0.251 to be left, got %v", left)
+	}
+}
@@ -0,0 +1,12 @@
+package packets
+
+import (
+	"net"
+)
+
+const LLMNRPort = 5355
+
+var (
+	LLMNRDestMac = net.HardwareAddr{0x01, 0x00, 0x5e, 0x00, 0x00, 0xfc}
+	LLMNRDestIP  = net.ParseIP("224.0.0.252")
+)
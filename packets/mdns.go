@@ -17,48 +17,99 @@ var (
 	MDNSDestIP  = net.ParseIP("224.0.0.251")
 )
 
-func MDNSGetMeta(pkt gopacket.Packet) map[string]string {
-	meta := make(map[string]string)
-
+// mdnsDecode returns the mDNS message carried by the packet, if any.
+func mdnsDecode(pkt gopacket.Packet) (dns *layers.DNS) {
 	defer func() {
 		if r := recover(); r != nil {
-			meta = nil
+			dns = nil
 		}
 	}()
 
 	if ludp := pkt.Layer(layers.LayerTypeUDP); ludp != nil {
 		if udp := ludp.(*layers.UDP); udp != nil && udp.SrcPort == MDNSPort && udp.DstPort == MDNSPort {
-			dns := layers.DNS{}
+			dns = &layers.DNS{}
 			if err := dns.DecodeFromBytes(udp.Payload, gopacket.NilDecodeFeedback); err == nil {
-				answers := append(dns.Answers, dns.Additionals...)
-				answers = append(answers, dns.Authorities...)
-
-				for _, answer := range answers {
-					switch answer.Type {
-					case layers.DNSTypePTR:
-					case layers.DNSTypeA:
-					case layers.DNSTypeAAAA:
-						meta["mdns:hostname"] = string(answer.Name)
-
-					case layers.DNSTypeTXT:
-						for _, raw := range answer.TXTs {
-							if value := string(raw); strings.Contains(value, "=") {
-								parts := strings.SplitN(value, "=", 2)
-								meta["mdns:"+core.Trim(parts[0])] = core.Trim(parts[1])
-							}
-						}
+				return dns
+			}
+		}
+	}
+	return nil
+}
+
+func MDNSGetMeta(pkt gopacket.Packet) map[string]string {
+	meta := make(map[string]string)
+
+	if dns := mdnsDecode(pkt); dns != nil {
+		answers := append(dns.Answers, dns.Additionals...)
+		answers = append(answers, dns.Authorities...)
+
+		for _, answer := range answers {
+			switch answer.Type {
+			case layers.DNSTypePTR:
+			case layers.DNSTypeA:
+			case layers.DNSTypeAAAA:
+				meta["mdns:hostname"] = string(answer.Name)
+
+			case layers.DNSTypeTXT:
+				for _, raw := range answer.TXTs {
+					if value := string(raw); strings.Contains(value, "=") {
+						parts := strings.SplitN(value, "=", 2)
+						meta["mdns:"+core.Trim(parts[0])] = core.Trim(parts[1])
 					}
 				}
 			}
 		}
 	}
 
-	if meta != nil && len(meta) > 0 {
+	if len(meta) > 0 {
 		return meta
 	}
 	return nil
 }
 
+// MDNSGetServices returns the service types (like _ipp._tcp) announced in
+// the answers of an mDNS packet.
+func MDNSGetServices(pkt gopacket.Packet) []string {
+	// the known answers of queries are about other hosts
+	dns := mdnsDecode(pkt)
+	if dns == nil || !dns.QR {
+		return nil
+	}
+
+	services := make([]string, 0)
+	seen := make(map[string]bool)
+	add := func(name string) {
+		// _printer._tcp.local or instance._printer._tcp.local
+		name = strings.TrimSuffix(strings.ToLower(name), ".local")
+		parts := strings.Split(name, ".")
+		if n := len(parts); n >= 2 && strings.HasPrefix(parts[n-2], "_") && (parts[n-1] == "_tcp" || parts[n-1] == "_udp") {
+			service := parts[n-2] + "." + parts[n-1]
+			if !seen[service] {
+				seen[service] = true
+				services = append(services, service)
+			}
+		}
+	}
+
+	answers := append(dns.Answers, dns.Additionals...)
+	for _, answer := range answers {
+		if answer.Type == layers.DNSTypePTR {
+			if string(answer.Name) == "_services._dns-sd._udp.local" {
+				add(string(answer.PTR))
+			} else {
+				add(string(answer.Name))
+			}
+		} else if answer.Type == layers.DNSTypeSRV {
+			add(string(answer.Name))
+		}
+	}
+
+	if len(services) == 0 {
+		return nil
+	}
+	return services
+}
+
 func NewMDNSProbe(from net.IP, from_hw net.HardwareAddr) (error, []byte) {
 	eth := layers.Ethernet{
 		SrcMAC:       from_hw,
@@ -0,0 +1,153 @@
+package packets
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// MQTT 3.1.1 control packet types, only what is needed to publish QoS 0
+// messages is implemented.
+const (
+	MQTTConnect    = 1
+	MQTTConnAck    = 2
+	MQTTPublish    = 3
+	MQTTPingReq    = 12
+	MQTTPingResp   = 13
+	MQTTDisconnect = 14
+
+	mqttMaxRemaining = 268435455
+	mqttProtocolLvl  = 4
+	// the broker only sends us acknowledgements and answers to our pings,
+	// anything bigger than this is not worth buffering
+	mqttMaxRead = 64 * 1024
+)
+
+var mqttConnAckErrors = map[byte]string{
+	1: "unacceptable protocol version",
+	2: "identifier rejected",
+	3: "server unavailable",
+	4: "bad user name or password",
+	5: "not authorized",
+}
+
+func mqttString(s string) []byte {
+	b := make([]byte, 2, 2+len(s))
+	binary.BigEndian.PutUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
+
+// mqttPacket prepends the fixed header, with its variable length encoding
+// of the remaining length, to body.
+func mqttPacket(header byte, body []byte) (error, []byte) {
+	size := len(body)
+	if size > mqttMaxRemaining {
+		return fmt.Errorf("MQTT packet of %d bytes is too big", size), nil
+	}
+
+	raw := []byte{header}
+	for {
+		digit := byte(size % 128)
+		size /= 128
+		if size > 0 {
+			digit |= 0x80
+		}
+		raw = append(raw, digit)
+		if size == 0 {
+			break
+		}
+	}
+
+	return nil, append(raw, body...)
+}
+
+// NewMQTTConnect builds a clean session CONNECT, username and password are
+// only sent if not empty.
+func NewMQTTConnect(clientID string, username string, password string, keepAlive uint16) (error, []byte) {
+	flags := byte(0x02)
+	if username != "" {
+		flags |= 0x80
+	}
+	if password != "" {
+		flags |= 0x40
+	}
+
+	body := mqttString("MQTT")
+	body = append(body, mqttProtocolLvl, flags, byte(keepAlive>>8), byte(keepAlive))
+	body = append(body, mqttString(clientID)...)
+	if username != "" {
+		body = append(body, mqttString(username)...)
+	}
+	if password != "" {
+		body = append(body, mqttString(password)...)
+	}
+
+	return mqttPacket(MQTTConnect<<4, body)
+}
+
+// NewMQTTPublish builds a QoS 0 PUBLISH, which has no packet identifier.
+func NewMQTTPublish(topic string, payload []byte) (error, []byte) {
+	if topic == "" || len(topic) > 65535 {
+		return fmt.Errorf("invalid MQTT topic '%s'", topic), nil
+	}
+	return mqttPacket(MQTTPublish<<4, append(mqttString(topic), payload...))
+}
+
+func NewMQTTPingReq() []byte {
+	return []byte{MQTTPingReq << 4, 0x00}
+}
+
+func NewMQTTDisconnect() []byte {
+	return []byte{MQTTDisconnect << 4, 0x00}
+}
+
+// ReadMQTTPacket reads a control packet and returns its type and body,
+// packets bigger than 64KB are rejected before reading their body.
+func ReadMQTTPacket(r *bufio.Reader) (error, byte, []byte) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return err, 0, nil
+	}
+
+	size := 0
+	for mul := 1; ; mul *= 128 {
+		if mul > 128*128*128 {
+			return fmt.Errorf("malformed MQTT remaining length"), 0, nil
+		}
+
+		digit, err := r.ReadByte()
+		if err != nil {
+			return err, 0, nil
+		}
+		size += int(digit&0x7f) * mul
+		if digit&0x80 == 0 {
+			break
+		}
+	}
+
+	if size > mqttMaxRead {
+		return fmt.Errorf("MQTT packet of %d bytes is too big", size), 0, nil
+	}
+
+	body := make([]byte, size)
+	if _, err = io.ReadFull(r, body); err != nil {
+		return err, 0, nil
+	}
+
+	return nil, header >> 4, body
+}
+
+// MQTTConnAckError returns the reason why the broker refused the
+// connection, or nil if it was accepted.
+func MQTTConnAckError(body []byte) error {
+	if len(body) != 2 {
+		return fmt.Errorf("malformed MQTT CONNACK")
+	} else if code := body[1]; code != 0 {
+		if reason, found := mqttConnAckErrors[code]; found {
+			return fmt.Errorf("connection refused, %s", reason)
+		}
+		return fmt.Errorf("connection refused with code %d", code)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package packets
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestNewMQTTConnect(t *testing.T) {
+	err, raw := NewMQTTConnect("bc", "u", "p", 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []byte{
+		0x10, 0x14,
+		0x00, 0x04, 'M', 'Q', 'T', 'T', 0x04, 0xc2, 0x00, 0x3c,
+		0x00, 0x02, 'b', 'c',
+		0x00, 0x01, 'u',
+		0x00, 0x01, 'p',
+	}
+	if !bytes.Equal(raw, expected) {
+		t.Fatalf("expected %x, got %x", expected, raw)
+	}
+
+	if err, raw = NewMQTTConnect("bc", "", "", 60); err != nil {
+		t.Fatal(err)
+	} else if raw[9] != 0x02 {
+		t.Fatalf("expected only the clean session flag, got %x", raw[9])
+	}
+}
+
+func TestNewMQTTPublishLength(t *testing.T) {
+	payload := bytes.Repeat([]byte{'x'}, 200)
+	err, raw := NewMQTTPublish("a/b", payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 2 + 3 + 200 = 205 encoded on two bytes
+	if raw[0] != 0x30 || raw[1] != 0xcd || raw[2] != 0x01 {
+		t.Fatalf("unexpected fixed header %x", raw[:3])
+	}
+
+	err, kind, body := ReadMQTTPacket(bufio.NewReader(bytes.NewReader(raw)))
+	if err != nil {
+		t.Fatal(err)
+	} else if kind != MQTTPublish {
+		t.Fatalf("expected a PUBLISH, got %d", kind)
+	} else if len(body) != 205 || string(body[2:5]) != "a/b" {
+		t.Fatalf("unexpected body %x", body[:5])
+	}
+
+	if err, _ = NewMQTTPublish("", payload); err == nil {
+		t.Fatal("expected an error for an empty topic")
+	}
+}
+
+func TestReadMQTTPacketSize(t *testing.T) {
+	// 268435455 bytes announced, none sent
+	raw := []byte{0x30, 0xff, 0xff, 0xff, 0x7f}
+	if err, _, _ := ReadMQTTPacket(bufio.NewReader(bytes.NewReader(raw))); err == nil || !strings.Contains(err.Error(), "too big") {
+		t.Fatalf("expected the packet to be rejected, got %v", err)
+	}
+
+	raw = []byte{0x30, 0xff, 0xff, 0xff, 0xff, 0x01}
+	if err, _, _ := ReadMQTTPacket(bufio.NewReader(bytes.NewReader(raw))); err == nil {
+		t.Fatal("expected a malformed remaining length error")
+	}
+
+	raw = []byte{0xd0, 0x00}
+	if err, kind, body := ReadMQTTPacket(bufio.NewReader(bytes.NewReader(raw))); err != nil || kind != MQTTPingResp || len(body) != 0 {
+		t.Fatalf("unexpected PINGRESP %v %d %x", err, kind, body)
+	}
+}
+
+func TestMQTTConnAckError(t *testing.T) {
+	if err := MQTTConnAckError([]byte{0x00, 0x00}); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	} else if err = MQTTConnAckError([]byte{0x00, 0x05}); err == nil || err.Error() != "connection refused, not authorized" {
+		t.Fatalf("unexpected error %v", err)
+	} else if err = MQTTConnAckError([]byte{0x00}); err == nil {
+		t.Fatal("expected an error for a malformed CONNACK")
+	}
+}
@@ -1,6 +1,10 @@
 package packets
 
 import (
+	"encoding/binary"
+	"net"
+	"strings"
+
 	"github.com/bettercap/bettercap/core"
 
 	"github.com/google/gopacket"
@@ -10,6 +14,12 @@ import (
 const (
 	NBNSPort        = 137
 	NBNSMinRespSize = 73
+
+	nbnsHeaderSize  = 12
+	nbnsNameSize    = 34
+	nbnsTypeNB      = 0x0020
+	nbnsClassIN     = 0x0001
+	nbnsResponseTTL = 165
 )
 
 var (
@@ -34,3 +44,98 @@ func NBNSGetMeta(pkt gopacket.Packet) map[string]string {
 	}
 	return nil
 }
+
+// NBNSEncodeName returns the first level encoding (RFC 1001) of a NetBIOS
+// name with its suffix, as it appears in the packets.
+func NBNSEncodeName(name string, suffix byte) []byte {
+	raw := make([]byte, 16)
+	copy(raw, []byte(strings.ToUpper(name)+strings.Repeat(" ", 15)))
+	raw[15] = suffix
+
+	encoded := make([]byte, 0, nbnsNameSize)
+	encoded = append(encoded, 0x20)
+	for _, b := range raw {
+		encoded = append(encoded, 'A'+(b>>4), 'A'+(b&0x0f))
+	}
+	return append(encoded, 0x00)
+}
+
+// NBNSDecodeName decodes the first level encoding of a NetBIOS name, it
+// returns the name without its padding and the suffix byte.
+func NBNSDecodeName(encoded []byte) (name string, suffix byte, ok bool) {
+	if len(encoded) < nbnsNameSize || encoded[0] != 0x20 || encoded[33] != 0x00 {
+		return "", 0, false
+	}
+
+	raw := make([]byte, 16)
+	for i := range raw {
+		hi, lo := encoded[1+i*2]-'A', encoded[2+i*2]-'A'
+		if hi > 0x0f || lo > 0x0f {
+			return "", 0, false
+		}
+		raw[i] = hi<<4 | lo
+	}
+
+	return strings.TrimRight(string(raw[:15]), " "), raw[15], true
+}
+
+// NBNSQuery is a NetBIOS name query of a single NB record.
+type NBNSQuery struct {
+	ID      uint16
+	Name    string
+	Suffix  byte
+	encoded []byte
+}
+
+// NBNSParseQuery parses the payload of a NetBIOS name query.
+func NBNSParseQuery(payload []byte) (*NBNSQuery, bool) {
+	if len(payload) < nbnsHeaderSize+nbnsNameSize+4 {
+		return nil, false
+	}
+
+	flags := binary.BigEndian.Uint16(payload[2:4])
+	questions := binary.BigEndian.Uint16(payload[4:6])
+	// responses or anything but a query (registrations, releases, ...)
+	if flags&0x8000 != 0 || (flags>>11)&0x0f != 0 || questions != 1 {
+		return nil, false
+	}
+
+	encoded := payload[nbnsHeaderSize : nbnsHeaderSize+nbnsNameSize]
+	rest := payload[nbnsHeaderSize+nbnsNameSize:]
+	if binary.BigEndian.Uint16(rest[0:2]) != nbnsTypeNB || binary.BigEndian.Uint16(rest[2:4]) != nbnsClassIN {
+		return nil, false
+	}
+
+	name, suffix, ok := NBNSDecodeName(encoded)
+	if !ok {
+		return nil, false
+	}
+
+	return &NBNSQuery{
+		ID:      binary.BigEndian.Uint16(payload[0:2]),
+		Name:    name,
+		Suffix:  suffix,
+		encoded: append([]byte{}, encoded...),
+	}, true
+}
+
+// NewNBNSResponse builds the positive name query response resolving the
+// name of q to address.
+func NewNBNSResponse(q *NBNSQuery, address net.IP) []byte {
+	encoded := q.encoded
+	if encoded == nil {
+		encoded = NBNSEncodeName(q.Name, q.Suffix)
+	}
+
+	raw := make([]byte, 0, nbnsHeaderSize+nbnsNameSize+16)
+	raw = append(raw, byte(q.ID>>8), byte(q.ID))
+	// response, authoritative answer, recursion desired
+	raw = append(raw, 0x85, 0x00)
+	raw = append(raw, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00)
+	raw = append(raw, encoded...)
+	raw = append(raw, 0x00, nbnsTypeNB, 0x00, nbnsClassIN)
+	raw = append(raw, 0x00, 0x00, 0x00, nbnsResponseTTL)
+	// rdlength, the NB flags (unique name, B node) and the address
+	raw = append(raw, 0x00, 0x06, 0x00, 0x00)
+	return append(raw, address.To4()...)
+}
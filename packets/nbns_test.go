@@ -0,0 +1,73 @@
+package packets
+
+import (
+	"bytes"
+	"net"
+	"testing"
+)
+
+func TestNBNSEncodeName(t *testing.T) {
+	// RFC 1001 example, "FRED" padded with spaces
+	encoded := NBNSEncodeName("fred", 0x20)
+	expected := []byte("\x20EGFCEFEECACACACACACACACACACACACA\x00")
+	if !bytes.Equal(encoded, expected) {
+		t.Fatalf("expected %q, got %q", expected, encoded)
+	}
+
+	name, suffix, ok := NBNSDecodeName(encoded)
+	if !ok || name != "FRED" || suffix != 0x20 {
+		t.Fatalf("expected FRED<20>, got %q<%02x> (%v)", name, suffix, ok)
+	}
+}
+
+func TestNBNSDecodeNameInvalid(t *testing.T) {
+	bad := NBNSEncodeName("wpad", 0x00)
+	bad[5] = 'Z'
+	if _, _, ok := NBNSDecodeName(bad); ok {
+		t.Fatal("expected an invalid encoding to be rejected")
+	} else if _, _, ok := NBNSDecodeName(bad[:10]); ok {
+		t.Fatal("expected a short name to be rejected")
+	}
+}
+
+func nbnsQuery(id uint16, flags uint16, name string) []byte {
+	raw := []byte{byte(id >> 8), byte(id), byte(flags >> 8), byte(flags), 0, 1, 0, 0, 0, 0, 0, 0}
+	raw = append(raw, NBNSEncodeName(name, 0x00)...)
+	return append(raw, 0x00, 0x20, 0x00, 0x01)
+}
+
+func TestNBNSParseQuery(t *testing.T) {
+	q, ok := NBNSParseQuery(nbnsQuery(0x1234, 0x0110, "WPAD"))
+	if !ok {
+		t.Fatal("expected the query to be parsed")
+	} else if q.ID != 0x1234 || q.Name != "WPAD" || q.Suffix != 0x00 {
+		t.Fatalf("unexpected query %+v", q)
+	}
+
+	// a response to our own query
+	if _, ok := NBNSParseQuery(nbnsQuery(0x1234, 0x8500, "WPAD")); ok {
+		t.Fatal("expected a response not to be parsed as a query")
+	}
+	// a name registration
+	if _, ok := NBNSParseQuery(nbnsQuery(0x1234, 0x2910, "WPAD")); ok {
+		t.Fatal("expected a registration not to be parsed as a query")
+	}
+	if _, ok := NBNSParseQuery(NBNSRequest[:20]); ok {
+		t.Fatal("expected a truncated query not to be parsed")
+	}
+}
+
+func TestNewNBNSResponse(t *testing.T) {
+	q, _ := NBNSParseQuery(nbnsQuery(0xbeef, 0x0110, "FILESRV"))
+	raw := NewNBNSResponse(q, net.ParseIP("192.168.1.5"))
+
+	if len(raw) != 62 {
+		t.Fatalf("expected 62 bytes, got %d", len(raw))
+	} else if raw[0] != 0xbe || raw[1] != 0xef || raw[2] != 0x85 {
+		t.Fatalf("unexpected header %x", raw[:12])
+	} else if name, _, ok := NBNSDecodeName(raw[12:46]); !ok || name != "FILESRV" {
+		t.Fatalf("unexpected name %q", name)
+	} else if !net.IP(raw[58:]).Equal(net.ParseIP("192.168.1.5")) {
+		t.Fatalf("unexpected address %v", net.IP(raw[58:]))
+	}
+}
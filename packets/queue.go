@@ -16,7 +16,15 @@ type Activity struct {
 	IP     net.IP
 	MAC    net.HardwareAddr
 	Meta   map[string]string
+	OS     *network.OSGuess
+	Device network.DeviceSignals
+	VLAN   uint16
+	Joined []net.IP
+	Left   []net.IP
 	Source bool
+	// the host announced the address itself or a DHCP server assigned it,
+	// so it replaces the one known for its MAC
+	Rebind bool
 }
 
 type Traffic struct {
@@ -35,6 +43,10 @@ type Stats struct {
 
 type PacketCallback func(pkt gopacket.Packet)
 
+// DryRunCallback receives the frames that would have been sent while the
+// dry run mode is enabled.
+type DryRunCallback func(linkType layers.LinkType, raw []byte)
+
 type Queue struct {
 	sync.RWMutex
 
@@ -50,7 +62,16 @@ type Queue struct {
 	srcChannel chan gopacket.Packet
 	writes     *sync.WaitGroup
 	pktCb      PacketCallback
+	subs       map[int]PacketCallback
+	nextSub    int
+	dryRunCb   DryRunCallback
+	recorder   *Recorder
+	vlan       *VLANFilter
+	classify   bool
+	arpReplies bool
+	quit       chan bool
 	active     bool
+	failed     bool
 }
 
 func NewQueue(iface *network.Endpoint) (q *Queue, err error) {
@@ -61,8 +82,10 @@ func NewQueue(iface *network.Endpoint) (q *Queue, err error) {
 
 		writes: &sync.WaitGroup{},
 		iface:  iface,
+		quit:   make(chan bool),
 		active: !iface.IsMonitor(),
 		pktCb:  nil,
+		subs:   make(map[int]PacketCallback),
 	}
 
 	if q.active {
@@ -72,18 +95,187 @@ func NewQueue(iface *network.Endpoint) (q *Queue, err error) {
 
 		q.source = gopacket.NewPacketSource(q.handle, q.handle.LinkType())
 		q.srcChannel = q.source.Packets()
-		go q.worker()
+		go q.worker(q.quit, q.srcChannel)
 	}
 
 	return
 }
 
+// Reopen replaces the capture handle of a stopped or failed queue with a
+// new one on the same interface, the callbacks, the filters, the dry run
+// mode and the recording are kept.
+func (q *Queue) Reopen() error {
+	if q.iface.IsMonitor() {
+		return nil
+	}
+
+	handle, err := pcap.OpenLive(q.iface.Name(), 1024, true, pcap.BlockForever)
+	if err != nil {
+		return err
+	}
+
+	q.Lock()
+	defer q.Unlock()
+
+	if q.active {
+		q.writes.Wait()
+		close(q.quit)
+		q.handle.Close()
+	}
+
+	q.handle = handle
+	q.source = gopacket.NewPacketSource(handle, handle.LinkType())
+	q.srcChannel = q.source.Packets()
+	q.quit = make(chan bool)
+	q.active = true
+	q.failed = false
+	go q.worker(q.quit, q.srcChannel)
+
+	return nil
+}
+
 func (q *Queue) OnPacket(cb PacketCallback) {
 	q.Lock()
 	defer q.Unlock()
 	q.pktCb = cb
 }
 
+// Subscribe registers cb to be called for every captured packet alongside
+// the OnPacket callback and returns the id to pass to Unsubscribe, unlike
+// OnPacket it can be used by any number of modules at the same time.
+func (q *Queue) Subscribe(cb PacketCallback) int {
+	q.Lock()
+	defer q.Unlock()
+	if q.subs == nil {
+		q.subs = make(map[int]PacketCallback)
+	}
+	q.nextSub++
+	q.subs[q.nextSub] = cb
+	return q.nextSub
+}
+
+// Unsubscribe removes the callback registered with Subscribe, once it
+// returns the callback is not running and will not be called anymore.
+func (q *Queue) Unsubscribe(id int) {
+	q.Lock()
+	defer q.Unlock()
+	delete(q.subs, id)
+}
+
+// SetVLANFilter makes the hosts discovery only consider the frames selected
+// by f, or every frame if f is nil.
+func (q *Queue) SetVLANFilter(f *VLANFilter) {
+	q.Lock()
+	defer q.Unlock()
+	q.vlan = f
+}
+
+func (q *Queue) vlanFilter() *VLANFilter {
+	q.RLock()
+	defer q.RUnlock()
+	return q.vlan
+}
+
+// SetClassify enables or disables the collection of the DHCP, User-Agent
+// and mDNS signals used to classify devices.
+func (q *Queue) SetClassify(enabled bool) {
+	q.Lock()
+	defer q.Unlock()
+	q.classify = enabled
+}
+
+func (q *Queue) classifyEnabled() bool {
+	q.RLock()
+	defer q.RUnlock()
+	return q.classify
+}
+
+// SetARPReplies enables or disables the tracking of the hosts answering
+// our own ARP requests, like the net.recon.arp.refresh ones.
+func (q *Queue) SetARPReplies(enabled bool) {
+	q.Lock()
+	defer q.Unlock()
+	q.arpReplies = enabled
+}
+
+func (q *Queue) arpRepliesEnabled() bool {
+	q.RLock()
+	defer q.RUnlock()
+	return q.arpReplies
+}
+
+// OnDryRun enables the dry run mode if cb is not nil, in which case frames
+// are passed to cb instead of being sent, or disables it.
+func (q *Queue) OnDryRun(cb DryRunCallback) {
+	q.Lock()
+	defer q.Unlock()
+	q.dryRunCb = cb
+}
+
+// DryRun passes raw to the dry run callback and returns true if the dry run
+// mode is enabled, in which case the frame must not be sent.
+func (q *Queue) DryRun(linkType layers.LinkType, raw []byte) bool {
+	q.RLock()
+	cb := q.dryRunCb
+	q.RUnlock()
+
+	if cb == nil {
+		return false
+	}
+	cb(linkType, raw)
+	return true
+}
+
+func (q *Queue) StartRecording(fileName string) error {
+	q.Lock()
+	defer q.Unlock()
+
+	if q.recorder != nil {
+		return fmt.Errorf("Already recording transmitted frames to %s.", q.recorder.FileName)
+	}
+
+	err, recorder := NewRecorder(fileName)
+	if err != nil {
+		return err
+	}
+
+	q.recorder = recorder
+	return nil
+}
+
+func (q *Queue) StopRecording() (error, *Recorder) {
+	q.Lock()
+	defer q.Unlock()
+
+	if q.recorder == nil {
+		return fmt.Errorf("Not recording transmitted frames."), nil
+	}
+
+	recorder := q.recorder
+	q.recorder = nil
+	return recorder.Close(), recorder
+}
+
+func (q *Queue) IsRecording() bool {
+	q.RLock()
+	defer q.RUnlock()
+	return q.recorder != nil
+}
+
+// Record saves a frame that has been injected by some other handle than
+// the queue one (for instance the wifi module monitor interface).
+func (q *Queue) Record(linkType layers.LinkType, raw []byte) {
+	q.RLock()
+	defer q.RUnlock()
+	q.record(linkType, raw)
+}
+
+func (q *Queue) record(linkType layers.LinkType, raw []byte) {
+	if q.recorder != nil {
+		q.recorder.Record(linkType, raw)
+	}
+}
+
 func (q *Queue) onPacketCallback(pkt gopacket.Packet) {
 	q.RLock()
 	defer q.RUnlock()
@@ -91,6 +283,9 @@ func (q *Queue) onPacketCallback(pkt gopacket.Packet) {
 	if q.pktCb != nil {
 		q.pktCb(pkt)
 	}
+	for _, cb := range q.subs {
+		cb(pkt)
+	}
 }
 
 func (q *Queue) trackProtocols(pkt gopacket.Packet) {
@@ -113,15 +308,39 @@ func (q *Queue) trackProtocols(pkt gopacket.Packet) {
 	}
 }
 
-func (q *Queue) trackActivity(eth *layers.Ethernet, ip4 *layers.IPv4, address net.IP, meta map[string]string, pktSize uint64, isSent bool) {
-	// push to activity channel
-	q.Activities <- Activity{
-		IP:     address,
-		MAC:    eth.SrcMAC,
-		Meta:   meta,
-		Source: isSent,
+// getOSGuess fingerprints the sender by the TTL and window size of
+// the TCP SYN (or SYN+ACK) packets, which are set by its TCP/IP stack.
+func (q *Queue) getOSGuess(pkt gopacket.Packet, ip4 *layers.IPv4) *network.OSGuess {
+	if ltcp := pkt.Layer(layers.LayerTypeTCP); ltcp != nil {
+		if tcp := ltcp.(*layers.TCP); tcp.SYN {
+			return network.GuessOS(ip4.TTL, tcp.Window)
+		}
+	}
+	return nil
+}
+
+// getDeviceSignals collects what can be used to tell the kind of device
+// which sent the packet.
+func (q *Queue) getDeviceSignals(pkt gopacket.Packet) network.DeviceSignals {
+	if !q.classifyEnabled() {
+		return network.DeviceSignals{}
 	}
 
+	fingerprint, _ := DHCPGetFingerprint(pkt)
+	return network.DeviceSignals{
+		DHCPFingerprint: fingerprint,
+		UserAgent:       HTTPGetUserAgent(pkt),
+		MDNSServices:    MDNSGetServices(pkt),
+	}
+}
+
+func (q *Queue) trackActivity(eth *layers.Ethernet, ip4 *layers.IPv4, address net.IP, activity Activity, pktSize uint64, isSent bool) {
+	// push to activity channel
+	activity.IP = address
+	activity.MAC = eth.SrcMAC
+	activity.Source = isSent
+	q.Activities <- activity
+
 	q.Lock()
 	defer q.Unlock()
 
@@ -142,6 +361,27 @@ func (q *Queue) trackActivity(eth *layers.Ethernet, ip4 *layers.IPv4, address ne
 	}
 }
 
+// trackRebind reports the hosts taking a new address with a gratuitous ARP or
+// being assigned one by a DHCP server.
+func (q *Queue) trackRebind(pkt gopacket.Packet, vlan uint16) {
+	ip, hw := ARPGetGratuitous(pkt)
+	if ip == nil {
+		ip, hw = DHCPGetAck(pkt)
+	}
+
+	if ip == nil || ip.Equal(q.iface.IP) || (vlan == 0 && !q.iface.Net.Contains(ip)) {
+		return
+	}
+
+	q.Activities <- Activity{
+		IP:     ip,
+		MAC:    hw,
+		VLAN:   vlan,
+		Source: true,
+		Rebind: true,
+	}
+}
+
 func (q *Queue) TrackPacket(size uint64) {
 	q.Stats.Lock()
 	defer q.Stats.Unlock()
@@ -176,10 +416,42 @@ func (q *Queue) getPacketMeta(pkt gopacket.Packet) map[string]string {
 	return meta
 }
 
-func (q *Queue) worker() {
-	for pkt := range q.srcChannel {
-		if !q.active {
+// Active returns true if the queue has a capture handle to read from and
+// inject packets with.
+func (q *Queue) Active() bool {
+	q.RLock()
+	defer q.RUnlock()
+	return q.active
+}
+
+// Failed returns true if the capture source has been closed without the
+// queue being stopped, which means the interface went away or errored.
+func (q *Queue) Failed() bool {
+	q.RLock()
+	defer q.RUnlock()
+	return q.failed
+}
+
+// worker reads the packets of a single capture handle, quit and source
+// are the ones of that handle since Reopen replaces them.
+func (q *Queue) worker(quit chan bool, source chan gopacket.Packet) {
+	for {
+		var pkt gopacket.Packet
+		var ok bool
+
+		select {
+		case <-quit:
 			return
+		case pkt, ok = <-source:
+			if !ok {
+				q.Lock()
+				// only if the handle wasn't replaced in the meantime
+				if q.srcChannel == source {
+					q.failed = true
+				}
+				q.Unlock()
+				return
+			}
 		}
 
 		q.trackProtocols(pkt)
@@ -189,6 +461,41 @@ func (q *Queue) worker() {
 		q.TrackPacket(pktSize)
 		q.onPacketCallback(pkt)
 
+		pkt, tags := VLANStrip(pkt)
+		if f := q.vlanFilter(); f != nil && !f.Match(tags) {
+			continue
+		}
+
+		vlan := uint16(0)
+		if len(tags) > 0 {
+			vlan = tags[len(tags)-1]
+		}
+
+		q.trackRebind(pkt, vlan)
+
+		if vlan != 0 {
+			// the subnet of a tagged VLAN is unknown, but ARP is never
+			// routed so its senders are hosts on that VLAN
+			if leth, larp := pkt.Layer(layers.LayerTypeEthernet), pkt.Layer(layers.LayerTypeARP); leth != nil && larp != nil {
+				if arp := larp.(*layers.ARP); arp.AddrType == layers.LinkTypeEthernet && len(arp.SourceProtAddress) == net.IPv4len {
+					q.trackActivity(leth.(*layers.Ethernet), nil, net.IP(arp.SourceProtAddress), Activity{VLAN: vlan}, pktSize, true)
+				}
+				continue
+			}
+		}
+
+		// answers to our own ARP requests, like the net.recon.arp.refresh
+		// ones, are the only ARP traffic trusted to confirm a host, they're
+		// ignored unless something asked for them
+		if q.arpRepliesEnabled() {
+			if from := ARPGetReplyTo(pkt, q.iface.IP); from != nil && q.iface.Net.Contains(from) {
+				if leth := pkt.Layer(layers.LayerTypeEthernet); leth != nil {
+					q.trackActivity(leth.(*layers.Ethernet), nil, from, Activity{VLAN: vlan}, pktSize, true)
+				}
+				continue
+			}
+		}
+
 		// decode eth and ipv4 layers
 		leth := pkt.Layer(layers.LayerTypeEthernet)
 		lip4 := pkt.Layer(layers.LayerTypeIPv4)
@@ -204,16 +511,32 @@ func (q *Queue) worker() {
 			isFromMe := q.iface.IP.Equal(ip4.SrcIP)
 			isFromLAN := q.iface.Net.Contains(ip4.SrcIP)
 			if !isFromMe && isFromLAN {
-				meta := q.getPacketMeta(pkt)
-
-				q.trackActivity(eth, ip4, ip4.SrcIP, meta, pktSize, true)
+				activity := Activity{
+					Meta:   q.getPacketMeta(pkt),
+					OS:     q.getOSGuess(pkt, ip4),
+					Device: q.getDeviceSignals(pkt),
+					VLAN:   vlan,
+				}
+				activity.Joined, activity.Left = IGMPGetGroups(pkt)
+
+				q.trackActivity(eth, ip4, ip4.SrcIP, activity, pktSize, true)
+			} else if ip4.SrcIP.IsUnspecified() && q.classifyEnabled() {
+				// DHCP clients don't have an address yet, use the one
+				// they're asking for
+				if fingerprint, requested := DHCPGetFingerprint(pkt); fingerprint != "" && requested != nil && q.iface.Net.Contains(requested) {
+					activity := Activity{
+						Device: network.DeviceSignals{DHCPFingerprint: fingerprint},
+						VLAN:   vlan,
+					}
+					q.trackActivity(eth, ip4, requested, activity, pktSize, true)
+				}
 			}
 
 			// something going to someone on the LAN
 			isToMe := q.iface.IP.Equal(ip4.DstIP)
 			isToLAN := q.iface.Net.Contains(ip4.DstIP)
 			if !isToMe && isToLAN {
-				q.trackActivity(eth, ip4, ip4.DstIP, nil, pktSize, false)
+				q.trackActivity(eth, ip4, ip4.DstIP, Activity{}, pktSize, false)
 			}
 		}
 	}
@@ -225,6 +548,12 @@ func (q *Queue) Send(raw []byte) error {
 
 	if !q.active {
 		return fmt.Errorf("Packet queue is not active.")
+	} else if cb := q.dryRunCb; cb != nil {
+		// the callback might emit events, don't hold the lock while it runs
+		q.Unlock()
+		defer q.Lock()
+		cb(q.handle.LinkType(), raw)
+		return nil
 	}
 
 	q.writes.Add(1)
@@ -235,6 +564,7 @@ func (q *Queue) Send(raw []byte) error {
 		return err
 	} else {
 		q.TrackSent(uint64(len(raw)))
+		q.record(q.handle.LinkType(), raw)
 	}
 
 	return nil
@@ -249,7 +579,7 @@ func (q *Queue) Stop() {
 		q.writes.Wait()
 		// signal the main loop to exit and close the handle
 		q.active = false
-		q.srcChannel <- nil
+		close(q.quit)
 		q.handle.Close()
 	}
 }
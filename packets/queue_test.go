@@ -2,8 +2,14 @@ package packets
 
 import (
 	"net"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
+
+	"github.com/bettercap/bettercap/network"
+
+	"github.com/google/gopacket"
 )
 
 func TestQueueActivity(t *testing.T) {
@@ -63,3 +69,59 @@ func TestQueueStats(t *testing.T) {
 }
 
 // TODO: add tests for the rest of queue.go
+
+func TestQueueSubscribe(t *testing.T) {
+	q := &Queue{}
+	first, second := 0, 0
+
+	a := q.Subscribe(func(pkt gopacket.Packet) { first++ })
+	q.Subscribe(func(pkt gopacket.Packet) { second++ })
+
+	q.onPacketCallback(nil)
+	q.Unsubscribe(a)
+	q.onPacketCallback(nil)
+
+	if first != 1 {
+		t.Fatalf("expected the first subscriber to be called once, got %d", first)
+	} else if second != 2 {
+		t.Fatalf("expected the second subscriber to be called twice, got %d", second)
+	}
+}
+
+func TestQueueReopen(t *testing.T) {
+	iface := network.NewEndpointNoResolve("127.0.0.1", "00:00:00:00:00:00", "lo", 8)
+	q, err := NewQueue(iface)
+	if err != nil {
+		t.Skipf("can't capture on lo: %s", err)
+	}
+	defer q.Stop()
+
+	fileName := filepath.Join(os.TempDir(), "bettercap-queue-reopen.pcap")
+	defer os.Remove(fileName)
+	if err := q.StartRecording(fileName); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	q.Stop()
+	if q.Active() {
+		t.Fatal("expected the queue to be stopped")
+	}
+
+	if err := q.Reopen(); err != nil {
+		t.Skipf("can't reopen lo: %s", err)
+	}
+	if !q.Active() {
+		t.Fatal("expected the queue to be active after Reopen")
+	} else if q.Failed() {
+		t.Fatal("expected the queue not to be failed after Reopen")
+	} else if !q.IsRecording() {
+		t.Fatal("expected the recording to be kept after Reopen")
+	}
+
+	// reopening an active queue replaces its handle as well
+	if err := q.Reopen(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	} else if !q.Active() || q.Failed() {
+		t.Fatal("expected the queue to be active after a second Reopen")
+	}
+}
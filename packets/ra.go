@@ -0,0 +1,106 @@
+package packets
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+const (
+	ICMP6OptSourceAddress = 1
+	ICMP6OptPrefixInfo    = 3
+	ICMP6OptRDNSS         = 25
+
+	// managed and other configuration flags, so that clients ask DHCPv6
+	// for addresses and DNS servers, and high router preference (RFC 4191)
+	raFlagManaged    = 0x80
+	raFlagOther      = 0x40
+	raPreferenceHigh = 0x08
+
+	// on-link and autonomous, the prefix can be used for SLAAC
+	prefixFlagOnLink     = 0x80
+	prefixFlagAutonomous = 0x40
+)
+
+var (
+	IPv6AllNodes   = net.ParseIP("ff02::1")
+	IPv6AllNodesHW = net.HardwareAddr{0x33, 0x33, 0x00, 0x00, 0x00, 0x01}
+)
+
+// RouterAdvertisement describes what NewRouterAdvertisement announces, a
+// zero Lifetime tells the hosts we're not a default router anymore.
+type RouterAdvertisement struct {
+	Lifetime uint16
+	Prefix   *net.IPNet
+	DNS      net.IP
+}
+
+func icmp6Option(kind byte, body []byte) []byte {
+	// the option length is in units of 8 bytes, type and length included
+	size := (len(body) + 2 + 7) / 8 * 8
+	opt := make([]byte, size)
+	opt[0] = kind
+	opt[1] = byte(size / 8)
+	copy(opt[2:], body)
+	return opt
+}
+
+// NewRouterAdvertisement creates an ICMPv6 router advertisement for all the
+// nodes of the link, from is expected to be a link-local address.
+func NewRouterAdvertisement(from net.IP, from_hw net.HardwareAddr, ra RouterAdvertisement) (error, []byte) {
+	if from.To4() != nil || from.To16() == nil {
+		return fmt.Errorf("%s is not an IPv6 address", from), nil
+	}
+
+	eth := layers.Ethernet{
+		SrcMAC:       from_hw,
+		DstMAC:       IPv6AllNodesHW,
+		EthernetType: layers.EthernetTypeIPv6,
+	}
+	ip6 := layers.IPv6{
+		Version:    6,
+		NextHeader: layers.IPProtocolICMPv6,
+		// anything else is discarded by the receivers (RFC 4861)
+		HopLimit: 255,
+		SrcIP:    from,
+		DstIP:    IPv6AllNodes,
+	}
+
+	header := make([]byte, 4)
+	header[0] = 64
+	header[1] = raFlagManaged | raFlagOther | raPreferenceHigh
+	binary.BigEndian.PutUint16(header[2:], ra.Lifetime)
+
+	icmp6 := layers.ICMPv6{
+		TypeCode:  layers.CreateICMPv6TypeCode(layers.ICMPv6TypeRouterAdvertisement, 0),
+		TypeBytes: header,
+	}
+	icmp6.SetNetworkLayerForChecksum(&ip6)
+
+	// reachable time and retransmission timer left unspecified
+	body := make([]byte, 8)
+	body = append(body, icmp6Option(ICMP6OptSourceAddress, from_hw)...)
+
+	if ra.Prefix != nil {
+		bits, _ := ra.Prefix.Mask.Size()
+		prefix := make([]byte, 30)
+		prefix[0] = byte(bits)
+		prefix[1] = prefixFlagOnLink | prefixFlagAutonomous
+		binary.BigEndian.PutUint32(prefix[2:], uint32(ra.Lifetime))
+		binary.BigEndian.PutUint32(prefix[6:], uint32(ra.Lifetime))
+		copy(prefix[14:], ra.Prefix.IP.To16())
+		body = append(body, icmp6Option(ICMP6OptPrefixInfo, prefix)...)
+	}
+
+	if ra.DNS != nil {
+		rdnss := make([]byte, 6, 22)
+		binary.BigEndian.PutUint32(rdnss[2:], uint32(ra.Lifetime))
+		rdnss = append(rdnss, ra.DNS.To16()...)
+		body = append(body, icmp6Option(ICMP6OptRDNSS, rdnss)...)
+	}
+
+	return Serialize(&eth, &ip6, &icmp6, gopacket.Payload(body))
+}
@@ -0,0 +1,68 @@
+package packets
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"testing"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+func TestNewRouterAdvertisement(t *testing.T) {
+	from := net.ParseIP("fe80::1")
+	fromHW, _ := net.ParseMAC("aa:bb:cc:dd:ee:ff")
+	_, prefix, _ := net.ParseCIDR("fd00:bad::/64")
+	dns := net.ParseIP("fe80::1")
+
+	err, raw := NewRouterAdvertisement(from, fromHW, RouterAdvertisement{
+		Lifetime: 300,
+		Prefix:   prefix,
+		DNS:      dns,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	pkt := gopacket.NewPacket(raw, layers.LayerTypeEthernet, gopacket.Default)
+	ip6, ok := pkt.Layer(layers.LayerTypeIPv6).(*layers.IPv6)
+	if !ok {
+		t.Fatal("no IPv6 layer")
+	} else if ip6.HopLimit != 255 || !ip6.DstIP.Equal(IPv6AllNodes) {
+		t.Fatalf("unexpected IPv6 layer %+v", ip6)
+	}
+
+	icmp6, ok := pkt.Layer(layers.LayerTypeICMPv6).(*layers.ICMPv6)
+	if !ok {
+		t.Fatal("no ICMPv6 layer")
+	} else if icmp6.TypeCode.Type() != layers.ICMPv6TypeRouterAdvertisement {
+		t.Fatalf("unexpected ICMPv6 type %s", icmp6.TypeCode)
+	} else if lifetime := binary.BigEndian.Uint16(icmp6.TypeBytes[2:]); lifetime != 300 {
+		t.Fatalf("unexpected router lifetime %d", lifetime)
+	}
+
+	// walk the options after reachable time and retransmission timer
+	found := make(map[byte][]byte)
+	opts := icmp6.Payload[8:]
+	for len(opts) >= 8 {
+		size := int(opts[1]) * 8
+		if size == 0 || size > len(opts) {
+			t.Fatalf("invalid option length in %x", opts)
+		}
+		found[opts[0]] = opts[2:size]
+		opts = opts[size:]
+	}
+
+	if opt := found[ICMP6OptSourceAddress]; !bytes.Equal(opt, fromHW) {
+		t.Fatalf("unexpected source address option %x", opt)
+	} else if opt := found[ICMP6OptPrefixInfo]; len(opt) != 30 || opt[0] != 64 || !net.IP(opt[14:30]).Equal(prefix.IP) {
+		t.Fatalf("unexpected prefix option %x", opt)
+	} else if opt := found[ICMP6OptRDNSS]; len(opt) != 22 || !net.IP(opt[6:22]).Equal(dns) {
+		t.Fatalf("unexpected RDNSS option %x", opt)
+	}
+
+	if err, _ := NewRouterAdvertisement(net.ParseIP("10.0.0.1"), fromHW, RouterAdvertisement{}); err == nil {
+		t.Fatal("expected an error for an IPv4 source")
+	}
+}
@@ -0,0 +1,46 @@
+package packets
+
+import (
+	"net"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+// ARPGetGratuitous returns the address and the MAC a host is announcing with
+// a gratuitous ARP request or reply, if pkt is one.
+func ARPGetGratuitous(pkt gopacket.Packet) (net.IP, net.HardwareAddr) {
+	if larp := pkt.Layer(layers.LayerTypeARP); larp != nil {
+		arp := larp.(*layers.ARP)
+		if arp.AddrType != layers.LinkTypeEthernet || len(arp.SourceProtAddress) != net.IPv4len || len(arp.SourceHwAddress) != 6 {
+			return nil, nil
+		}
+
+		ip := net.IP(arp.SourceProtAddress)
+		if !ip.IsUnspecified() && ip.Equal(net.IP(arp.DstProtAddress)) {
+			return ip, net.HardwareAddr(arp.SourceHwAddress)
+		}
+	}
+	return nil, nil
+}
+
+// DHCPGetAck returns the address a DHCP server assigned and the MAC of the
+// client it assigned it to, if pkt is a DHCP ACK.
+func DHCPGetAck(pkt gopacket.Packet) (net.IP, net.HardwareAddr) {
+	ldhcp := pkt.Layer(layers.LayerTypeDHCPv4)
+	if ldhcp == nil {
+		return nil, nil
+	}
+
+	dhcp := ldhcp.(*layers.DHCPv4)
+	if dhcp.Operation != layers.DHCPOpReply || dhcp.YourClientIP == nil || dhcp.YourClientIP.IsUnspecified() || len(dhcp.ClientHWAddr) != 6 {
+		return nil, nil
+	}
+
+	for _, opt := range dhcp.Options {
+		if opt.Type == layers.DHCPOptMessageType && len(opt.Data) == 1 && layers.DHCPMsgType(opt.Data[0]) == layers.DHCPMsgTypeAck {
+			return dhcp.YourClientIP, dhcp.ClientHWAddr
+		}
+	}
+	return nil, nil
+}
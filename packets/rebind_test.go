@@ -0,0 +1,64 @@
+package packets
+
+import (
+	"net"
+	"testing"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+func TestARPGetGratuitous(t *testing.T) {
+	ip := net.IP{192, 168, 1, 23}
+	hw, _ := net.ParseMAC("aa:bb:cc:dd:ee:ff")
+
+	_, raw := NewARPRequest(ip, hw, ip)
+	pkt := gopacket.NewPacket(raw, layers.LayerTypeEthernet, gopacket.Default)
+	if got, gotHW := ARPGetGratuitous(pkt); !got.Equal(ip) || gotHW.String() != hw.String() {
+		t.Fatalf("expected %s %s, got %s %s", ip, hw, got, gotHW)
+	}
+
+	_, raw = NewARPRequest(ip, hw, net.IP{192, 168, 1, 1})
+	pkt = gopacket.NewPacket(raw, layers.LayerTypeEthernet, gopacket.Default)
+	if got, _ := ARPGetGratuitous(pkt); got != nil {
+		t.Fatalf("a regular request should be ignored, got %s", got)
+	}
+
+	// ARP probes, sent before an address is taken, have no sender address
+	_, raw = NewARPRequest(net.IPv4zero, hw, net.IPv4zero)
+	pkt = gopacket.NewPacket(raw, layers.LayerTypeEthernet, gopacket.Default)
+	if got, _ := ARPGetGratuitous(pkt); got != nil {
+		t.Fatalf("an ARP probe should be ignored, got %s", got)
+	}
+}
+
+func newDHCPReply(msgType layers.DHCPMsgType, yiaddr net.IP, client net.HardwareAddr) gopacket.Packet {
+	server, _ := net.ParseMAC("01:23:45:67:89:ab")
+	eth := layers.Ethernet{SrcMAC: server, DstMAC: client, EthernetType: layers.EthernetTypeIPv4}
+	ip4 := layers.IPv4{Version: 4, TTL: 64, Protocol: layers.IPProtocolUDP, SrcIP: net.IPv4(192, 168, 1, 1), DstIP: yiaddr}
+	udp := layers.UDP{SrcPort: 67, DstPort: 68}
+	udp.SetNetworkLayerForChecksum(&ip4)
+	dhcp := layers.DHCPv4{
+		Operation:    layers.DHCPOpReply,
+		HardwareType: layers.LinkTypeEthernet,
+		ClientHWAddr: client,
+		YourClientIP: yiaddr,
+		Options: layers.DHCPOptions{
+			layers.NewDHCPOption(layers.DHCPOptMessageType, []byte{byte(msgType)}),
+		},
+	}
+
+	_, raw := Serialize(&eth, &ip4, &udp, &dhcp)
+	return gopacket.NewPacket(raw, layers.LayerTypeEthernet, gopacket.Default)
+}
+
+func TestDHCPGetAck(t *testing.T) {
+	ip := net.IP{192, 168, 1, 23}
+	hw, _ := net.ParseMAC("aa:bb:cc:dd:ee:ff")
+
+	if got, gotHW := DHCPGetAck(newDHCPReply(layers.DHCPMsgTypeAck, ip, hw)); !got.Equal(ip) || gotHW.String() != hw.String() {
+		t.Fatalf("expected %s %s, got %s %s", ip, hw, got, gotHW)
+	} else if got, _ = DHCPGetAck(newDHCPReply(layers.DHCPMsgTypeOffer, ip, hw)); got != nil {
+		t.Fatalf("an offer should be ignored, got %s", got)
+	}
+}
@@ -0,0 +1,103 @@
+package packets
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+)
+
+const recorderSnapLen = 65536
+
+// Recorder writes every frame it receives to a pcap file, the link type
+// of the file is set by the first recorded frame and frames with a
+// different link type are discarded since pcap can't mix them.
+type Recorder struct {
+	sync.Mutex
+
+	FileName string
+	LinkType layers.LinkType
+	Frames   uint64
+	Skipped  uint64
+
+	file    *os.File
+	writer  *pcapgo.Writer
+	started bool
+}
+
+func NewRecorder(fileName string) (error, *Recorder) {
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err, nil
+	}
+
+	return nil, &Recorder{
+		FileName: fileName,
+		file:     file,
+		writer:   pcapgo.NewWriter(file),
+		started:  false,
+	}
+}
+
+func (r *Recorder) Record(linkType layers.LinkType, raw []byte) error {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.file == nil {
+		return fmt.Errorf("recorder for %s is closed", r.FileName)
+	}
+
+	if !r.started {
+		if err := r.writer.WriteFileHeader(recorderSnapLen, linkType); err != nil {
+			return err
+		}
+		r.LinkType = linkType
+		r.started = true
+	} else if linkType != r.LinkType {
+		r.Skipped++
+		return fmt.Errorf("can't record a %s frame into a %s capture", linkType, r.LinkType)
+	}
+
+	size := len(raw)
+	ci := gopacket.CaptureInfo{
+		Timestamp:     time.Now(),
+		CaptureLength: size,
+		Length:        size,
+	}
+
+	if err := r.writer.WritePacket(ci, raw); err != nil {
+		return err
+	}
+
+	r.Frames++
+	return nil
+}
+
+func (r *Recorder) Close() error {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.file == nil {
+		return nil
+	}
+
+	// nothing has been recorded, write the header anyway so that the file
+	// is a valid (empty) capture instead of a zero bytes one
+	if !r.started {
+		if err := r.writer.WriteFileHeader(recorderSnapLen, layers.LinkTypeEthernet); err != nil {
+			r.file.Close()
+			r.file = nil
+			return err
+		}
+		r.LinkType = layers.LinkTypeEthernet
+		r.started = true
+	}
+
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
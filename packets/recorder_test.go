@@ -0,0 +1,82 @@
+package packets
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+)
+
+func tempRecorder(t *testing.T) *Recorder {
+	file, err := ioutil.TempFile("", "bettercap-recorder")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	err, r := NewRecorder(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestRecorderEmpty(t *testing.T) {
+	r := tempRecorder(t)
+	defer os.Remove(r.FileName)
+
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(r.FileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	reader, err := pcapgo.NewReader(file)
+	if err != nil {
+		t.Fatalf("expected a valid capture, got %s", err)
+	} else if reader.LinkType() != layers.LinkTypeEthernet {
+		t.Fatalf("expected %s, got %s", layers.LinkTypeEthernet, reader.LinkType())
+	} else if _, _, err := reader.ReadPacketData(); err == nil {
+		t.Fatal("expected no frames")
+	}
+}
+
+func TestRecorderFrames(t *testing.T) {
+	r := tempRecorder(t)
+	defer os.Remove(r.FileName)
+
+	if err := r.Record(layers.LinkTypeEthernet, []byte{1, 2, 3}); err != nil {
+		t.Fatal(err)
+	} else if err := r.Record(layers.LinkTypeIEEE80211Radio, []byte{4, 5, 6}); err == nil {
+		t.Fatal("expected an error for a different link type")
+	} else if err := r.Close(); err != nil {
+		t.Fatal(err)
+	} else if err := r.Record(layers.LinkTypeEthernet, []byte{1, 2, 3}); err == nil {
+		t.Fatal("expected an error once closed")
+	}
+
+	if r.Frames != 1 || r.Skipped != 1 {
+		t.Fatalf("expected 1 frame and 1 skipped, got %d and %d", r.Frames, r.Skipped)
+	}
+
+	file, err := os.Open(r.FileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	reader, err := pcapgo.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	} else if data, _, err := reader.ReadPacketData(); err != nil {
+		t.Fatal(err)
+	} else if len(data) != 3 {
+		t.Fatalf("expected a 3 bytes frame, got %d", len(data))
+	}
+}
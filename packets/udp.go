@@ -1,6 +1,7 @@
 package packets
 
 import (
+	"github.com/google/gopacket"
 	"github.com/google/gopacket/layers"
 	"net"
 )
@@ -30,3 +31,23 @@ func NewUDPProbe(from net.IP, from_hw net.HardwareAddr, to net.IP, port int) (er
 
 	return Serialize(&eth, &ip4, &udp)
 }
+
+// NewUDPDatagram builds the IPv4 datagram a UDP socket would send, without
+// any link layer.
+func NewUDPDatagram(from net.IP, to net.IP, port int, payload []byte) (error, []byte) {
+	ip4 := layers.IPv4{
+		Protocol: layers.IPProtocolUDP,
+		Version:  4,
+		TTL:      64,
+		SrcIP:    from,
+		DstIP:    to,
+	}
+
+	udp := layers.UDP{
+		SrcPort: layers.UDPPort(12345),
+		DstPort: layers.UDPPort(port),
+	}
+	udp.SetNetworkLayerForChecksum(&ip4)
+
+	return Serialize(&ip4, &udp, gopacket.Payload(payload))
+}
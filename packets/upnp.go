@@ -3,6 +3,7 @@ package packets
 import (
 	"bufio"
 	"bytes"
+	"encoding/xml"
 	"fmt"
 	"net"
 	"net/http"
@@ -30,24 +31,50 @@ var (
 		"\r\n")
 )
 
+func upnpHeadersToMeta(headers http.Header) map[string]string {
+	meta := make(map[string]string)
+	for name, values := range headers {
+		if name != "Cache-Control" && len(values) > 0 {
+			if data := core.Trim(strings.Join(values, ", ")); data != "" {
+				meta["upnp:"+name] = data
+			}
+
+		}
+	}
+	return meta
+}
+
 func UPNPGetMeta(pkt gopacket.Packet) map[string]string {
 	if ludp := pkt.Layer(layers.LayerTypeUDP); ludp != nil {
 		if udp := ludp.(*layers.UDP); udp != nil && udp.SrcPort == UPNPPort && len(udp.Payload) > 0 {
 			request := &http.Request{}
 			reader := bufio.NewReader(bytes.NewReader(udp.Payload))
 			if response, err := http.ReadResponse(reader, request); err == nil {
-				meta := make(map[string]string)
-				for name, values := range response.Header {
-					if name != "Cache-Control" && len(values) > 0 {
-						if data := core.Trim(strings.Join(values, ", ")); data != "" {
-							meta["upnp:"+name] = data
-						}
-
-					}
-				}
-				return meta
+				return upnpHeadersToMeta(response.Header)
+			}
+
+			// not a M-SEARCH response, check for NOTIFY announcements
+			reader = bufio.NewReader(bytes.NewReader(udp.Payload))
+			if notify, err := http.ReadRequest(reader); err == nil && notify.Method == "NOTIFY" {
+				return upnpHeadersToMeta(notify.Header)
 			}
 		}
 	}
 	return nil
 }
+
+// UPNPDevice holds the fields we care about of a UPnP device description.
+type UPNPDevice struct {
+	FriendlyName string `xml:"device>friendlyName"`
+	Manufacturer string `xml:"device>manufacturer"`
+	ModelName    string `xml:"device>modelName"`
+	ModelNumber  string `xml:"device>modelNumber"`
+}
+
+func UPNPParseDescription(raw []byte) (error, *UPNPDevice) {
+	device := &UPNPDevice{}
+	if err := xml.Unmarshal(raw, device); err != nil {
+		return err, nil
+	}
+	return nil, device
+}
@@ -0,0 +1,34 @@
+package packets
+
+import (
+	"testing"
+)
+
+func TestUPNPParseDescription(t *testing.T) {
+	raw := []byte(`<?xml version="1.0"?>
+<root xmlns="urn:schemas-upnp-org:device-1-0">
+  <specVersion><major>1</major><minor>0</minor></specVersion>
+  <device>
+    <deviceType>urn:schemas-upnp-org:device:MediaRenderer:1</deviceType>
+    <friendlyName>Living Room TV</friendlyName>
+    <manufacturer>ACME</manufacturer>
+    <modelName>SmartTV 3000</modelName>
+    <modelNumber>ST3K</modelNumber>
+  </device>
+</root>`)
+
+	err, device := UPNPParseDescription(raw)
+	if err != nil {
+		t.Fatal(err)
+	} else if device.FriendlyName != "Living Room TV" {
+		t.Fatalf("unexpected friendly name '%s'", device.FriendlyName)
+	} else if device.Manufacturer != "ACME" {
+		t.Fatalf("unexpected manufacturer '%s'", device.Manufacturer)
+	} else if device.ModelName != "SmartTV 3000" || device.ModelNumber != "ST3K" {
+		t.Fatalf("unexpected model '%s' '%s'", device.ModelName, device.ModelNumber)
+	}
+
+	if err, _ := UPNPParseDescription([]byte("not xml")); err == nil {
+		t.Fatal("expected an error for invalid xml")
+	}
+}
@@ -0,0 +1,161 @@
+package packets
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+const vlanIDMask = 0x0fff
+
+func isVLANType(t uint16) bool {
+	// 802.1Q, 802.1ad and the pre-standard QinQ ethertype
+	return t == 0x8100 || t == 0x88a8 || t == 0x9100
+}
+
+// VLANGetTags returns the VLAN IDs an ethernet frame is tagged with, the
+// outer one first, or nil if the frame is untagged.
+func VLANGetTags(data []byte) []uint16 {
+	var tags []uint16
+	for off := 12; len(data) >= off+6 && isVLANType(binary.BigEndian.Uint16(data[off:])); off += 4 {
+		tags = append(tags, binary.BigEndian.Uint16(data[off+2:])&vlanIDMask)
+	}
+	return tags
+}
+
+// VLANStrip returns the packet without its VLAN tags, so that parsers
+// expecting the network layer right after the ethernet one can handle it,
+// and the tags it had, outer first.
+func VLANStrip(pkt gopacket.Packet) (gopacket.Packet, []uint16) {
+	data := pkt.Data()
+	tags := VLANGetTags(data)
+	if tags == nil || pkt.LinkLayer() == nil || pkt.LinkLayer().LayerType() != layers.LayerTypeEthernet {
+		return pkt, tags
+	}
+
+	off := 12 + 4*len(tags)
+	stripped := make([]byte, 0, len(data)-4*len(tags))
+	stripped = append(stripped, data[:12]...)
+	stripped = append(stripped, data[off:]...)
+
+	untagged := gopacket.NewPacket(stripped, layers.LayerTypeEthernet, gopacket.Default)
+	md := untagged.Metadata()
+	md.CaptureInfo = pkt.Metadata().CaptureInfo
+	md.CaptureLength = len(stripped)
+	md.Length -= len(data) - len(stripped)
+
+	return untagged, tags
+}
+
+// VLANFilter selects frames by their VLAN tags, it can match untagged
+// frames, the innermost tag (the VLAN the hosts are on, for QinQ frames the
+// outer tag is the one of the provider) or the whole outer.inner stack.
+type VLANFilter struct {
+	untagged bool
+	ids      map[uint16]bool
+	stacks   map[string]bool
+}
+
+func parseVLANID(s string) (uint16, error) {
+	id, err := strconv.ParseUint(strings.TrimSpace(s), 10, 16)
+	if err != nil || id < 1 || id > 4094 {
+		return 0, fmt.Errorf("'%s' is not a valid VLAN ID", s)
+	}
+	return uint16(id), nil
+}
+
+func vlanStack(tags []uint16) string {
+	parts := make([]string, len(tags))
+	for i, tag := range tags {
+		parts[i] = strconv.Itoa(int(tag))
+	}
+	return strings.Join(parts, ".")
+}
+
+// ParseVLANFilter parses a comma separated list of VLAN IDs, QinQ stacks
+// like 100.20 and 0 or untagged for frames without tags, it returns a nil
+// filter if the list is empty.
+func ParseVLANFilter(value string) (error, *VLANFilter) {
+	f := &VLANFilter{
+		ids:    make(map[uint16]bool),
+		stacks: make(map[string]bool),
+	}
+
+	empty := true
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		empty = false
+
+		if entry == "0" || entry == "untagged" {
+			f.untagged = true
+		} else if parts := strings.Split(entry, "."); len(parts) == 1 {
+			id, err := parseVLANID(entry)
+			if err != nil {
+				return err, nil
+			}
+			f.ids[id] = true
+		} else {
+			tags := make([]uint16, len(parts))
+			for i, part := range parts {
+				id, err := parseVLANID(part)
+				if err != nil {
+					return err, nil
+				}
+				tags[i] = id
+			}
+			f.stacks[vlanStack(tags)] = true
+		}
+	}
+
+	if empty {
+		return nil, nil
+	}
+	return nil, f
+}
+
+// Match returns true if a frame with the given tags, outer first, is
+// selected by the filter.
+func (f *VLANFilter) Match(tags []uint16) bool {
+	if len(tags) == 0 {
+		return f.untagged
+	}
+	return f.ids[tags[len(tags)-1]] || f.stacks[vlanStack(tags)]
+}
+
+// Untagged returns true if the filter selects frames without tags.
+func (f *VLANFilter) Untagged() bool {
+	return f.untagged
+}
+
+func (f *VLANFilter) String() string {
+	if f == nil {
+		return ""
+	}
+
+	entries := make([]string, 0)
+	if f.untagged {
+		entries = append(entries, "untagged")
+	}
+	ids := make([]int, 0, len(f.ids))
+	for id := range f.ids {
+		ids = append(ids, int(id))
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		entries = append(entries, strconv.Itoa(id))
+	}
+	stacks := make([]string, 0, len(f.stacks))
+	for stack := range f.stacks {
+		stacks = append(stacks, stack)
+	}
+	sort.Strings(stacks)
+	return strings.Join(append(entries, stacks...), ", ")
+}
@@ -0,0 +1,126 @@
+package packets
+
+import (
+	"bytes"
+	"net"
+	"testing"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+func buildTaggedFrame(t *testing.T, tags ...uint16) []byte {
+	src, _ := net.ParseMAC("aa:bb:cc:dd:ee:ff")
+	dst, _ := net.ParseMAC("11:22:33:44:55:66")
+
+	ip4 := &layers.IPv4{
+		Version:  4,
+		TTL:      64,
+		Protocol: layers.IPProtocolUDP,
+		SrcIP:    net.ParseIP("10.0.20.5").To4(),
+		DstIP:    net.ParseIP("10.0.20.1").To4(),
+	}
+	udp := &layers.UDP{SrcPort: 1234, DstPort: 53}
+	udp.SetNetworkLayerForChecksum(ip4)
+
+	eth := &layers.Ethernet{SrcMAC: src, DstMAC: dst, EthernetType: layers.EthernetTypeIPv4}
+	stack := []gopacket.SerializableLayer{eth}
+	for i, tag := range tags {
+		if i == 0 && len(tags) > 1 {
+			eth.EthernetType = layers.EthernetTypeQinQ
+		} else if i == 0 {
+			eth.EthernetType = layers.EthernetTypeDot1Q
+		}
+
+		next := layers.EthernetTypeIPv4
+		if i < len(tags)-1 {
+			next = layers.EthernetTypeDot1Q
+		}
+		stack = append(stack, &layers.Dot1Q{VLANIdentifier: tag, Type: next})
+	}
+	stack = append(stack, ip4, udp, gopacket.Payload([]byte("hello")))
+
+	err, raw := Serialize(stack...)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	return raw
+}
+
+func TestVLANGetTags(t *testing.T) {
+	if tags := VLANGetTags(buildTaggedFrame(t)); tags != nil {
+		t.Fatalf("expected no tags, got %v", tags)
+	} else if tags := VLANGetTags(buildTaggedFrame(t, 20)); len(tags) != 1 || tags[0] != 20 {
+		t.Fatalf("expected [20], got %v", tags)
+	} else if tags := VLANGetTags(buildTaggedFrame(t, 100, 20)); len(tags) != 2 || tags[0] != 100 || tags[1] != 20 {
+		t.Fatalf("expected [100 20], got %v", tags)
+	} else if tags := VLANGetTags([]byte{1, 2, 3}); tags != nil {
+		t.Fatalf("expected no tags for a truncated frame, got %v", tags)
+	}
+}
+
+func TestVLANStrip(t *testing.T) {
+	untagged := buildTaggedFrame(t)
+	tagged := gopacket.NewPacket(buildTaggedFrame(t, 100, 20), layers.LayerTypeEthernet, gopacket.Default)
+
+	pkt, tags := VLANStrip(tagged)
+	if len(tags) != 2 {
+		t.Fatalf("expected 2 tags, got %v", tags)
+	} else if !bytes.Equal(pkt.Data()[:14], untagged[:14]) {
+		t.Fatalf("unexpected stripped frame %x", pkt.Data())
+	} else if pkt.Layer(layers.LayerTypeDot1Q) != nil {
+		t.Fatal("the stripped frame still has a Dot1Q layer")
+	}
+
+	eth := pkt.Layer(layers.LayerTypeEthernet).(*layers.Ethernet)
+	if eth.EthernetType != layers.EthernetTypeIPv4 {
+		t.Fatalf("unexpected ethernet type %s", eth.EthernetType)
+	} else if udp := pkt.Layer(layers.LayerTypeUDP); udp == nil || string(udp.LayerPayload()) != "hello" {
+		t.Fatal("no UDP payload in the stripped frame")
+	}
+
+	plain := gopacket.NewPacket(untagged, layers.LayerTypeEthernet, gopacket.Default)
+	if pkt, tags := VLANStrip(plain); pkt != plain || tags != nil {
+		t.Fatal("untagged frames must be returned as they are")
+	}
+}
+
+func TestVLANFilter(t *testing.T) {
+	if err, f := ParseVLANFilter(" "); err != nil || f != nil {
+		t.Fatalf("expected no filter, got %v %v", err, f)
+	}
+
+	for _, bad := range []string{"abc", "4095", "0.20", "100.x"} {
+		if err, _ := ParseVLANFilter(bad); err == nil {
+			t.Fatalf("expected an error for '%s'", bad)
+		}
+	}
+
+	err, f := ParseVLANFilter("20, untagged, 100.30")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	cases := []struct {
+		tags     []uint16
+		expected bool
+	}{
+		{nil, true},
+		{[]uint16{20}, true},
+		{[]uint16{100, 20}, true},
+		{[]uint16{30}, false},
+		{[]uint16{100, 30}, true},
+		{[]uint16{200, 30}, false},
+		{[]uint16{20, 10}, false},
+	}
+
+	for _, c := range cases {
+		if got := f.Match(c.tags); got != c.expected {
+			t.Fatalf("expected %v for %v, got %v", c.expected, c.tags, got)
+		}
+	}
+
+	if s := f.String(); s != "untagged, 20, 100.30" {
+		t.Fatalf("unexpected string '%s'", s)
+	}
+}
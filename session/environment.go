@@ -99,6 +99,13 @@ func (env *Environment) Set(name, value string) string {
 	return old
 }
 
+func (env *Environment) Unset(name string) {
+	env.Lock()
+	defer env.Unlock()
+
+	delete(env.Data, name)
+}
+
 func (env *Environment) Get(name string) (bool, string) {
 	env.Lock()
 	defer env.Unlock()
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -11,9 +12,12 @@ import (
 )
 
 type Event struct {
-	Tag  string      `json:"tag"`
-	Time time.Time   `json:"time"`
-	Data interface{} `json:"data"`
+	Tag        string      `json:"tag"`
+	Time       time.Time   `json:"time"`
+	Data       interface{} `json:"data"`
+	Historical bool        `json:"historical,omitempty"`
+
+	priority EventPriority
 }
 
 type LogMessage struct {
@@ -23,12 +27,17 @@ type LogMessage struct {
 
 func NewEvent(tag string, data interface{}) Event {
 	return Event{
-		Tag:  tag,
-		Time: time.Now(),
-		Data: data,
+		Tag:      tag,
+		Time:     time.Now(),
+		Data:     data,
+		priority: TagPriority(tag, data),
 	}
 }
 
+func (e Event) Priority() EventPriority {
+	return e.priority
+}
+
 func (e Event) Label() string {
 	log := e.Data.(LogMessage)
 	label := core.LogLabels[log.Level]
@@ -36,13 +45,29 @@ func (e Event) Label() string {
 	return color + label + core.RESET
 }
 
+// EventStats is a summary of the state of the events buffer.
+type EventStats struct {
+	Buffered  int               `json:"buffered"`
+	Max       int               `json:"max"`
+	Dropped   uint64            `json:"dropped"`
+	DroppedBy map[string]uint64 `json:"dropped_by_priority"`
+}
+
 type EventPool struct {
 	sync.Mutex
 
 	debug     bool
 	silent    bool
+	max       int
+	dropped   uint64
+	droppedBy map[EventPriority]uint64
+	limiters  map[EventPriority]*eventLimiter
 	events    []Event
 	listeners []chan Event
+	sources   map[string][]string
+	muted     map[string]bool
+	coalesce  time.Duration
+	coalesced map[string]*coalescedEvent
 }
 
 func NewEventPool(debug bool, silent bool) *EventPool {
@@ -51,6 +76,11 @@ func NewEventPool(debug bool, silent bool) *EventPool {
 		silent:    silent,
 		events:    make([]Event, 0),
 		listeners: make([]chan Event, 0),
+		sources:   make(map[string][]string),
+		muted:     make(map[string]bool),
+		droppedBy: make(map[EventPriority]uint64),
+		limiters:  make(map[EventPriority]*eventLimiter),
+		coalesced: make(map[string]*coalescedEvent),
 	}
 }
 
@@ -95,18 +125,125 @@ func (p *EventPool) SetSilent(s bool) {
 	p.silent = s
 }
 
+// SetMax sets how many events are kept in the buffer, 0 for unlimited.
+func (p *EventPool) SetMax(max int) {
+	p.Lock()
+	defer p.Unlock()
+	p.max = max
+	p.trim()
+}
+
+// trim drops the oldest events if the buffer is full, starting from the
+// ones with the lowest priority, the caller must hold the lock.
+func (p *EventPool) trim() {
+	if p.max <= 0 || len(p.events) <= p.max {
+		return
+	}
+
+	// usually a single event is over, so this is done in place
+	for len(p.events) > p.max {
+		// the oldest events are at the end of the buffer
+		victim := len(p.events) - 1
+		for i := victim; i >= 0 && p.events[victim].priority > PriorityLow; i-- {
+			if p.events[i].priority < p.events[victim].priority {
+				victim = i
+			}
+		}
+
+		p.dropped++
+		p.droppedBy[p.events[victim].priority]++
+
+		last := len(p.events) - 1
+		copy(p.events[victim:], p.events[victim+1:])
+		p.events[last] = Event{}
+		p.events = p.events[:last]
+	}
+}
+
+func (p *EventPool) Stats() EventStats {
+	p.Lock()
+	defer p.Unlock()
+	droppedBy := make(map[string]uint64)
+	for priority := PriorityLow; priority <= PriorityCritical; priority++ {
+		droppedBy[priority.String()] = p.droppedBy[priority]
+	}
+
+	return EventStats{
+		Buffered:  len(p.events),
+		Max:       p.max,
+		Dropped:   p.dropped,
+		DroppedBy: droppedBy,
+	}
+}
+
 func (p *EventPool) SetDebug(d bool) {
 	p.Lock()
 	defer p.Unlock()
 	p.debug = d
 }
 
+// SetSources declares the tag prefixes of the events a module emits, by
+// default a module is expected to tag its events with its own name.
+func (p *EventPool) SetSources(module string, prefixes ...string) {
+	p.Lock()
+	defer p.Unlock()
+	p.sources[module] = prefixes
+}
+
+func (p *EventPool) Mute(module string) {
+	p.Lock()
+	defer p.Unlock()
+	p.muted[module] = true
+}
+
+func (p *EventPool) Unmute(module string) {
+	p.Lock()
+	defer p.Unlock()
+	delete(p.muted, module)
+}
+
+func (p *EventPool) IsMuted(module string) bool {
+	p.Lock()
+	defer p.Unlock()
+	return p.muted[module]
+}
+
+func (p *EventPool) isMutedTag(tag string) bool {
+	for module := range p.muted {
+		prefixes, found := p.sources[module]
+		if !found {
+			prefixes = []string{module}
+		}
+
+		for _, prefix := range prefixes {
+			if tag == prefix || strings.HasPrefix(tag, prefix+".") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (p *EventPool) Add(tag string, data interface{}) {
 	p.Lock()
 	defer p.Unlock()
 
-	e := NewEvent(tag, data)
+	if p.isMutedTag(tag) {
+		return
+	}
+
+	p.emit(NewEvent(tag, data))
+}
+
+// emit buffers the event and broadcasts it to every listener unless it's
+// over the rate of its priority, the caller must hold the lock.
+func (p *EventPool) emit(e Event) {
+	if p.shed(e.priority, e.Time) {
+		return
+	}
+
 	p.events = append([]Event{e}, p.events...)
+	p.trim()
 
 	// broadcast the event to every listener
 	for _, l := range p.listeners {
@@ -115,7 +252,13 @@ func (p *EventPool) Add(tag string, data interface{}) {
 }
 
 func (p *EventPool) Log(level int, format string, args ...interface{}) {
-	if level == core.DEBUG && !p.debug {
+	p.log(level, false, format, args...)
+}
+
+// log adds a sys.log event, if verbose is true debug messages are logged
+// even if the debug mode is disabled.
+func (p *EventPool) log(level int, verbose bool, format string, args ...interface{}) {
+	if level == core.DEBUG && !p.debug && !verbose {
 		return
 	} else if level < core.ERROR && p.silent {
 		return
@@ -140,13 +283,18 @@ func (p *EventPool) Clear() {
 	p.events = make([]Event, 0)
 }
 
+// Sorted returns a copy of the buffered events, from the oldest to the newest.
 func (p *EventPool) Sorted() []Event {
 	p.Lock()
 	defer p.Unlock()
 
-	sort.Slice(p.events, func(i, j int) bool {
-		return p.events[i].Time.Before(p.events[j].Time)
+	// the buffer itself must stay ordered from the newest to the oldest
+	// event, so that trim() drops the right ones
+	sorted := make([]Event, len(p.events))
+	copy(sorted, p.events)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Time.Before(sorted[j].Time)
 	})
 
-	return p.events
+	return sorted
 }
@@ -0,0 +1,94 @@
+package session
+
+import (
+	"time"
+)
+
+const (
+	EventsCoalesceVariable = "main.events.coalesce"
+	DefaultEventsCoalesce  = "5"
+)
+
+// coalescedEvent keeps track of the latest update received for a given key
+// since the last time an event was emitted for it, the entry is dropped once
+// a whole window goes by without updates.
+type coalescedEvent struct {
+	pending *Event
+	timer   *time.Timer
+}
+
+// SetCoalesce sets the window within which the updates passed to Coalesce
+// for the same key are merged, 0 to emit every update.
+func (p *EventPool) SetCoalesce(window time.Duration) {
+	p.Lock()
+	defer p.Unlock()
+	p.coalesce = window
+}
+
+// Coalesce emits at most one event per tag and key every coalescing window:
+// the first update is emitted right away and the following ones are merged,
+// only the latest one being emitted once the window expires.
+func (p *EventPool) Coalesce(tag string, key string, data interface{}) {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.isMutedTag(tag) {
+		return
+	}
+
+	e := NewEvent(tag, data)
+	if p.coalesce <= 0 {
+		p.emit(e)
+		return
+	}
+
+	id := tag + "/" + key
+	if c, found := p.coalesced[id]; found {
+		c.pending = &e
+		return
+	}
+
+	c := &coalescedEvent{}
+	c.timer = time.AfterFunc(p.coalesce, func() {
+		p.flushCoalesced(id, c)
+	})
+	p.coalesced[id] = c
+	p.emit(e)
+}
+
+func (p *EventPool) flushCoalesced(id string, c *coalescedEvent) {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.coalesced[id] != c {
+		// forgotten in the meantime
+		return
+	} else if c.pending == nil {
+		delete(p.coalesced, id)
+		return
+	}
+
+	e := *c.pending
+	e.Time = time.Now()
+	c.pending = nil
+	c.timer = time.AfterFunc(p.coalesce, func() {
+		p.flushCoalesced(id, c)
+	})
+
+	if !p.isMutedTag(e.Tag) {
+		p.emit(e)
+	}
+}
+
+// Forget drops the pending update of an event passed to Coalesce, the next
+// one for the same tag and key will be emitted right away.
+func (p *EventPool) Forget(tag string, key string) {
+	p.Lock()
+	defer p.Unlock()
+
+	id := tag + "/" + key
+	if c, found := p.coalesced[id]; found {
+		c.timer.Stop()
+		delete(p.coalesced, id)
+	}
+}
@@ -0,0 +1,298 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/bettercap/bettercap/core"
+)
+
+const (
+	EventFormatJSON = "json"
+	EventFormatCEF  = "cef"
+	EventFormatLEEF = "leef"
+
+	eventVendor  = "bettercap"
+	eventProduct = "bettercap"
+)
+
+// siemField is the name of a well known event field in CEF and LEEF.
+type siemField struct {
+	CEF  string
+	LEEF string
+}
+
+var (
+	// top level event data fields mapped to the SIEM dictionaries,
+	// everything else goes into custom extensions
+	siemFields = map[string]siemField{
+		"ipv4":       {"src", "src"},
+		"client_ip":  {"src", "src"},
+		"mac":        {"smac", "srcMAC"},
+		"client_mac": {"smac", "srcMAC"},
+		"hostname":   {"shost", "srcHostName"},
+		"protocol":   {"app", "proto"},
+		"message":    {"msg", "msg"},
+		"Message":    {"msg", "msg"},
+	}
+
+	// CEF severities (0-10) of the log levels
+	logSeverities = map[int]int{
+		core.DEBUG:     1,
+		core.INFO:      3,
+		core.IMPORTANT: 5,
+		core.WARNING:   6,
+		core.ERROR:     8,
+		core.FATAL:     10,
+	}
+
+	// CEF severities of events with a tag starting with these prefixes, the
+	// longest matching prefix wins and the other events have a severity of
+	// 3, the priorities of the events are derived from these as well
+	tagSeverities = map[string]int{
+		"module.panic":            8,
+		"net.recon.blocklist.hit": 8,
+		"net.sniff.krb5":          7,
+		"net.sniff.ntlm":          7,
+		"wifi.client.identity":    7,
+		"wifi.client.handshake":   7,
+		"http.server.captive":     7,
+		"arp.spoof.failed":        7,
+		"monitor.anomaly":         7,
+		"iface.":                  5,
+		"arp.spoof.":              5,
+		"mac.changed":             5,
+		"track.":                  5,
+		"update.available":        5,
+		"wifi.ap.crowded":         4,
+		"endpoint.":               2,
+		"net.sniff.":              2,
+		"net.recon.igmp":          2,
+		"wifi.ap.":                2,
+		"wifi.client.probe":       2,
+		"ble.device.":             2,
+		"sys.dryrun":              2,
+		"update.progress":         2,
+	}
+)
+
+// ValidEventFormat returns true if format is one of the formats supported
+// by Event.Format.
+func ValidEventFormat(format string) bool {
+	return format == EventFormatJSON || format == EventFormatCEF || format == EventFormatLEEF
+}
+
+// TagSeverity returns the CEF severity, from 0 to 10, of an event given its
+// tag and payload, log messages get the severity of their level.
+func TagSeverity(tag string, data interface{}) int {
+	if log, ok := data.(LogMessage); ok && tag == "sys.log" {
+		return logSeverities[log.Level]
+	}
+
+	severity, longest := 3, 0
+	for prefix, sev := range tagSeverities {
+		if strings.HasPrefix(tag, prefix) && len(prefix) > longest {
+			severity, longest = sev, len(prefix)
+		}
+	}
+	return severity
+}
+
+// Severity returns the CEF severity of the event, from 0 to 10.
+func (e Event) Severity() int {
+	return TagSeverity(e.Tag, e.Data)
+}
+
+// Format serializes the event as a single line of the given format.
+func (e Event) Format(format string) (error, string) {
+	switch format {
+	case EventFormatJSON:
+		raw, err := json.Marshal(e)
+		if err != nil {
+			return err, ""
+		}
+		return nil, string(raw)
+
+	case EventFormatCEF:
+		return e.toCEF()
+
+	case EventFormatLEEF:
+		return e.toLEEF()
+	}
+
+	return fmt.Errorf("unknown events format '%s'", format), ""
+}
+
+// flatten returns the event data as a map of field paths to string values.
+func (e Event) flatten() (error, map[string]string) {
+	fields := make(map[string]string)
+
+	raw, err := json.Marshal(e.Data)
+	if err != nil {
+		return err, nil
+	}
+
+	var data interface{}
+	if err = json.Unmarshal(raw, &data); err != nil {
+		return err, nil
+	}
+
+	if obj, ok := data.(map[string]interface{}); ok {
+		for key, value := range obj {
+			flattenValue(fields, key, value)
+		}
+	} else if data != nil {
+		flattenValue(fields, "message", data)
+	}
+
+	return nil, fields
+}
+
+func flattenValue(fields map[string]string, path string, value interface{}) {
+	switch v := value.(type) {
+	case nil:
+		return
+	case map[string]interface{}:
+		for key, sub := range v {
+			flattenValue(fields, path+"."+key, sub)
+		}
+	case []interface{}:
+		for i, sub := range v {
+			flattenValue(fields, fmt.Sprintf("%s.%d", path, i), sub)
+		}
+	case float64:
+		fields[path] = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		if s := fmt.Sprintf("%v", v); s != "" {
+			fields[path] = s
+		}
+	}
+}
+
+// extensionKey turns a field path into a custom extension key, for
+// instance meta.values.os becomes bcMetaValuesOs.
+func extensionKey(path string) string {
+	key := "bc"
+	upper := true
+	for _, c := range path {
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) {
+			upper = true
+			continue
+		} else if upper {
+			c = unicode.ToUpper(c)
+			upper = false
+		}
+		key += string(c)
+	}
+	return key
+}
+
+// extensions maps the flattened event data to SIEM fields, the ones
+// without a mapping get a custom extension key.
+func (e Event) extensions(cef bool) (error, [][2]string) {
+	err, fields := e.flatten()
+	if err != nil {
+		return err, nil
+	}
+
+	mapped := make(map[string]string)
+	custom := make(map[string]string)
+
+	for path, value := range fields {
+		if field, found := siemFields[path]; found {
+			key := field.LEEF
+			if cef {
+				key = field.CEF
+			}
+			if _, taken := mapped[key]; !taken {
+				mapped[key] = value
+				continue
+			}
+		} else if (path == "from" || path == "to") && net.ParseIP(value) != nil {
+			key := "src"
+			if path == "to" {
+				key = "dst"
+			}
+			if _, taken := mapped[key]; !taken {
+				mapped[key] = value
+				continue
+			}
+		}
+		custom[extensionKey(path)] = value
+	}
+
+	ext := make([][2]string, 0, len(mapped)+len(custom))
+	for _, m := range []map[string]string{mapped, custom} {
+		keys := make([]string, 0, len(m))
+		for key := range m {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			ext = append(ext, [2]string{key, m[key]})
+		}
+	}
+
+	return nil, ext
+}
+
+func cefHeaderEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\n", " ", "\r", " ").Replace(s)
+}
+
+func cefValueEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\n", `\n`, "\r", `\r`).Replace(s)
+}
+
+func (e Event) toCEF() (error, string) {
+	err, ext := e.extensions(true)
+	if err != nil {
+		return err, ""
+	}
+
+	parts := []string{fmt.Sprintf("rt=%d", e.Time.UnixNano()/1000000)}
+	for _, kv := range ext {
+		parts = append(parts, kv[0]+"="+cefValueEscape(kv[1]))
+	}
+
+	return nil, fmt.Sprintf("CEF:0|%s|%s|%s|%s|%s|%d|%s",
+		cefHeaderEscape(eventVendor),
+		cefHeaderEscape(eventProduct),
+		cefHeaderEscape(core.Version),
+		cefHeaderEscape(e.Tag),
+		cefHeaderEscape(strings.Replace(e.Tag, ".", " ", -1)),
+		e.Severity(),
+		strings.Join(parts, " "))
+}
+
+func leefEscape(s string) string {
+	return strings.NewReplacer("\t", " ", "\n", " ", "\r", " ", "|", `\|`).Replace(s)
+}
+
+func (e Event) toLEEF() (error, string) {
+	err, ext := e.extensions(false)
+	if err != nil {
+		return err, ""
+	}
+
+	parts := []string{
+		"devTime=" + e.Time.Format("Jan 02 2006 15:04:05.000 MST"),
+		fmt.Sprintf("sev=%d", e.Severity()),
+	}
+	for _, kv := range ext {
+		parts = append(parts, kv[0]+"="+leefEscape(kv[1]))
+	}
+
+	return nil, fmt.Sprintf("LEEF:1.0|%s|%s|%s|%s|%s",
+		leefEscape(eventVendor),
+		leefEscape(eventProduct),
+		leefEscape(core.Version),
+		leefEscape(e.Tag),
+		strings.Join(parts, "\t"))
+}
@@ -0,0 +1,92 @@
+package session
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Save writes every buffered event to fileName as JSON lines, oldest first.
+func (p *EventPool) Save(fileName string) (error, int) {
+	return p.SaveAs(fileName, EventFormatJSON)
+}
+
+// SaveAs writes every buffered event to fileName, one per line in the
+// given format, oldest first. Only JSON files can be loaded back.
+func (p *EventPool) SaveAs(fileName string, format string) (error, int) {
+	if !ValidEventFormat(format) {
+		return fmt.Errorf("unknown events format '%s'", format), 0
+	}
+
+	events := p.Sorted()
+
+	out, err := os.Create(fileName)
+	if err != nil {
+		return err, 0
+	}
+	defer out.Close()
+
+	writer := bufio.NewWriter(out)
+	saved := 0
+	for _, e := range events {
+		err, line := e.Format(format)
+		if err != nil {
+			// some event payloads can't be encoded, don't lose the others
+			continue
+		}
+
+		if _, err = writer.WriteString(line + "\n"); err != nil {
+			return err, saved
+		}
+		saved++
+	}
+
+	return writer.Flush(), saved
+}
+
+// Load reads events saved with Save and adds them to the buffer as historical
+// events: they're not broadcasted to the current listeners, so nothing waiting
+// for live events will fire, but new listeners will receive them.
+func (p *EventPool) Load(fileName string) (error, int) {
+	in, err := os.Open(fileName)
+	if err != nil {
+		return err, 0
+	}
+	defer in.Close()
+
+	loaded := make([]Event, 0)
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return fmt.Errorf("%s:%d: %s", fileName, line, err), 0
+		}
+
+		e.Historical = true
+		loaded = append(loaded, e)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err, 0
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	p.events = append(p.events, loaded...)
+	// keep the buffer ordered from the newest to the oldest event
+	sort.SliceStable(p.events, func(i, j int) bool {
+		return p.events[i].Time.After(p.events[j].Time)
+	})
+	p.trim()
+
+	return nil, len(loaded)
+}
@@ -0,0 +1,108 @@
+package session
+
+import (
+	"time"
+)
+
+const (
+	EventsRateLowVariable    = "events.rate.low"
+	EventsRateNormalVariable = "events.rate.normal"
+	EventsRateHighVariable   = "events.rate.high"
+)
+
+// default events per second accepted for each priority, 0 for unlimited
+var defaultEventsRates = map[EventPriority]string{
+	PriorityLow:    "200",
+	PriorityNormal: "1000",
+	PriorityHigh:   "0",
+}
+
+// EventPriority decides which events are shed first when the bus is busy:
+// each level but the critical one is rate limited, and once the buffer is
+// full the oldest events of the lowest priority are evicted first.
+type EventPriority int
+
+const (
+	PriorityLow EventPriority = iota
+	PriorityNormal
+	PriorityHigh
+	PriorityCritical
+)
+
+var priorityNames = map[EventPriority]string{
+	PriorityLow:      "low",
+	PriorityNormal:   "normal",
+	PriorityHigh:     "high",
+	PriorityCritical: "critical",
+}
+
+func (p EventPriority) String() string {
+	return priorityNames[p]
+}
+
+// TagPriority returns the priority of an event given its tag and payload,
+// derived from its severity.
+func TagPriority(tag string, data interface{}) EventPriority {
+	severity := TagSeverity(tag, data)
+	switch {
+	case severity >= 7:
+		return PriorityCritical
+	case severity >= 5:
+		return PriorityHigh
+	case severity >= 3:
+		return PriorityNormal
+	}
+	return PriorityLow
+}
+
+// eventLimiter is a token bucket allowing rate events per second, with
+// bursts of up to one second worth of events.
+type eventLimiter struct {
+	rate   int
+	tokens float64
+	last   time.Time
+}
+
+func (l *eventLimiter) allow(now time.Time) bool {
+	if l.rate <= 0 {
+		return true
+	}
+
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * float64(l.rate)
+	} else {
+		l.tokens = float64(l.rate)
+	}
+	if l.tokens > float64(l.rate) {
+		l.tokens = float64(l.rate)
+	}
+	l.last = now
+
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// SetRate sets how many events per second of the given priority are
+// accepted, 0 for unlimited, critical events are never rate limited.
+func (p *EventPool) SetRate(priority EventPriority, rate int) {
+	p.Lock()
+	defer p.Unlock()
+
+	if priority != PriorityCritical {
+		p.limiters[priority] = &eventLimiter{rate: rate}
+	}
+}
+
+// shed returns true if the event must be dropped because its priority is
+// over its rate, the caller must hold the lock.
+func (p *EventPool) shed(priority EventPriority, now time.Time) bool {
+	if l, found := p.limiters[priority]; found && !l.allow(now) {
+		p.dropped++
+		p.droppedBy[priority]++
+		return true
+	}
+	return false
+}
@@ -0,0 +1,245 @@
+package session
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventPoolMute(t *testing.T) {
+	p := NewEventPool(false, false)
+	p.SetSources("net.recon", "endpoint", "net.recon")
+
+	p.Mute("net.recon")
+	p.Mute("syn.scan")
+
+	p.Add("endpoint.new", nil)
+	p.Add("net.recon.os", nil)
+	p.Add("syn.scan", nil)
+	p.Add("wifi.ap.new", nil)
+	p.Add("syn.scanner", nil)
+
+	if n := len(p.events); n != 2 {
+		t.Fatalf("expected 2 events, got %d", n)
+	}
+
+	p.Unmute("net.recon")
+	p.Add("endpoint.lost", nil)
+
+	if n := len(p.events); n != 3 {
+		t.Fatalf("expected 3 events, got %d", n)
+	} else if p.IsMuted("net.recon") {
+		t.Fatal("expected net.recon to be unmuted")
+	} else if !p.IsMuted("syn.scan") {
+		t.Fatal("expected syn.scan to be muted")
+	}
+}
+
+func TestEventPoolSaveLoad(t *testing.T) {
+	file, err := ioutil.TempFile("", "bettercap-events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	fileName := file.Name()
+	defer os.Remove(fileName)
+
+	p := NewEventPool(false, false)
+	p.Add("endpoint.new", map[string]string{"mac": "aa:bb:cc:dd:ee:ff"})
+	time.Sleep(time.Millisecond)
+	p.Add("syn.scan", 80)
+
+	if err, n := p.Save(fileName); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if n != 2 {
+		t.Fatalf("expected 2 saved events, got %d", n)
+	}
+
+	loaded := NewEventPool(false, false)
+	if err, n := loaded.Load(fileName); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if n != 2 {
+		t.Fatalf("expected 2 loaded events, got %d", n)
+	}
+
+	events := loaded.Sorted()
+	if events[0].Tag != "endpoint.new" || events[1].Tag != "syn.scan" {
+		t.Fatalf("unexpected events order: %v", events)
+	}
+
+	for _, e := range events {
+		if !e.Historical {
+			t.Fatalf("expected event %s to be historical", e.Tag)
+		}
+	}
+}
+
+func TestEventPoolMax(t *testing.T) {
+	p := NewEventPool(false, false)
+	p.SetMax(3)
+
+	for i := 0; i < 5; i++ {
+		p.Add("test", i)
+		time.Sleep(time.Millisecond)
+	}
+
+	if stats := p.Stats(); stats.Buffered != 3 || stats.Dropped != 2 {
+		t.Fatalf("expected 3 buffered and 2 dropped events, got %+v", stats)
+	}
+
+	// the oldest events must be the ones that got dropped
+	sorted := p.Sorted()
+	if sorted[0].Data.(int) != 2 || sorted[2].Data.(int) != 4 {
+		t.Fatalf("unexpected events left in the buffer: %+v", sorted)
+	}
+
+	p.SetMax(1)
+	if stats := p.Stats(); stats.Buffered != 1 || stats.Dropped != 4 {
+		t.Fatalf("expected 1 buffered and 4 dropped events, got %+v", stats)
+	}
+}
+
+func TestEventFormat(t *testing.T) {
+	e := NewEvent("endpoint.new", map[string]interface{}{
+		"ipv4":     "192.168.1.2",
+		"mac":      "aa:bb:cc:dd:ee:ff",
+		"hostname": "foo|bar",
+		"meta":     map[string]interface{}{"os": "a=b"},
+	})
+
+	err, cef := e.Format(EventFormatCEF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"CEF:0|bettercap|bettercap|", "|endpoint.new|endpoint new|2|", "src=192.168.1.2", "smac=aa:bb:cc:dd:ee:ff", "shost=foo|bar", `bcMetaOs=a\=b`} {
+		if !strings.Contains(cef, expected) {
+			t.Fatalf("expected '%s' in '%s'", expected, cef)
+		}
+	}
+
+	err, leef := e.Format(EventFormatLEEF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"LEEF:1.0|bettercap|bettercap|", "|endpoint.new|", "\tsrc=192.168.1.2", "\tsrcMAC=aa:bb:cc:dd:ee:ff", "\tsev=2"} {
+		if !strings.Contains(leef, expected) {
+			t.Fatalf("expected '%s' in '%s'", expected, leef)
+		}
+	}
+
+	if err, _ := e.Format("xml"); err == nil {
+		t.Fatal("expected an error for an unknown format")
+	}
+}
+
+func TestTagPriority(t *testing.T) {
+	cases := []struct {
+		tag      string
+		data     interface{}
+		expected EventPriority
+	}{
+		{"endpoint.new", nil, PriorityLow},
+		{"net.sniff.dns", nil, PriorityLow},
+		{"net.sniff.ntlm", nil, PriorityCritical},
+		{"arp.spoof.confirmed", nil, PriorityHigh},
+		{"arp.spoof.failed", nil, PriorityCritical},
+		{"syn.scan", nil, PriorityNormal},
+		{"sys.log", LogMessage{Level: 0}, PriorityLow},
+		{"sys.log", LogMessage{Level: 5}, PriorityCritical},
+	}
+
+	for _, c := range cases {
+		if got := TagPriority(c.tag, c.data); got != c.expected {
+			t.Fatalf("expected %s to be %s, got %s", c.tag, c.expected, got)
+		}
+	}
+}
+
+func TestEventPoolMaxPriority(t *testing.T) {
+	p := NewEventPool(false, false)
+	p.SetMax(2)
+
+	p.Add("module.panic", 0)
+	p.Add("endpoint.new", 1)
+	p.Add("syn.scan", 2)
+	p.Add("endpoint.lost", 3)
+
+	// the low priority events go first, even if newer than the others
+	sorted := p.Sorted()
+	if len(sorted) != 2 || sorted[0].Data.(int) != 0 || sorted[1].Data.(int) != 2 {
+		t.Fatalf("unexpected events left in the buffer: %+v", sorted)
+	}
+
+	if stats := p.Stats(); stats.Dropped != 2 || stats.DroppedBy["low"] != 2 || stats.DroppedBy["critical"] != 0 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestEventPoolRate(t *testing.T) {
+	p := NewEventPool(false, false)
+	p.SetRate(PriorityLow, 5)
+	p.SetRate(PriorityCritical, 1)
+
+	for i := 0; i < 20; i++ {
+		p.Add("endpoint.new", i)
+		p.Add("module.panic", i)
+	}
+
+	stats := p.Stats()
+	if stats.DroppedBy["low"] != 15 {
+		t.Fatalf("expected 15 low priority events to be shed, got %+v", stats)
+	} else if stats.DroppedBy["critical"] != 0 || stats.Buffered != 25 {
+		t.Fatalf("critical events must never be rate limited, got %+v", stats)
+	}
+
+	p.SetRate(PriorityLow, 0)
+	p.Add("endpoint.new", 20)
+	if stats := p.Stats(); stats.Buffered != 26 {
+		t.Fatalf("expected the low priority limit to be lifted, got %+v", stats)
+	}
+}
+
+func TestEventPoolCoalesce(t *testing.T) {
+	p := NewEventPool(false, false)
+	p.SetCoalesce(50 * time.Millisecond)
+
+	for i := 0; i < 10; i++ {
+		p.Coalesce("wifi.client.probe", "aa:bb:cc:dd:ee:ff", i)
+	}
+	p.Coalesce("wifi.client.probe", "aa:bb:cc:dd:ee:00", 0)
+
+	// the first update of each key is emitted right away
+	if n := len(p.Sorted()); n != 2 {
+		t.Fatalf("expected 2 events, got %d", n)
+	}
+
+	time.Sleep(150 * time.Millisecond)
+
+	events := p.Sorted()
+	if n := len(events); n != 3 {
+		t.Fatalf("expected 3 events, got %d", n)
+	} else if last := events[2].Data.(int); last != 9 {
+		t.Fatalf("expected the latest update, got %d", last)
+	}
+
+	// the window is over, 10 is emitted and 11 would be merged
+	p.Coalesce("wifi.client.probe", "aa:bb:cc:dd:ee:ff", 10)
+	p.Coalesce("wifi.client.probe", "aa:bb:cc:dd:ee:ff", 11)
+	p.Forget("wifi.client.probe", "aa:bb:cc:dd:ee:ff")
+	p.Coalesce("wifi.client.probe", "aa:bb:cc:dd:ee:ff", 12)
+	time.Sleep(150 * time.Millisecond)
+
+	if events = p.Sorted(); len(events) != 5 || events[3].Data.(int) != 10 || events[4].Data.(int) != 12 {
+		t.Fatalf("expected the pending update to be dropped by Forget, got %v", events)
+	}
+
+	p.SetCoalesce(0)
+	p.Coalesce("wifi.client.probe", "aa:bb:cc:dd:ee:ff", 13)
+	p.Coalesce("wifi.client.probe", "aa:bb:cc:dd:ee:ff", 14)
+	if n := len(p.Sorted()); n != 7 {
+		t.Fatalf("expected 7 events, got %d", n)
+	}
+}
@@ -3,6 +3,7 @@ package session
 import (
 	"fmt"
 	"net"
+	"runtime/debug"
 	"strings"
 	"sync"
 	"time"
@@ -30,6 +31,13 @@ type JSONModule struct {
 	Running     bool                    `json:"running"`
 }
 
+// ModulePanic is the payload of module.panic events.
+type ModulePanic struct {
+	Module string `json:"module"`
+	Error  string `json:"error"`
+	Stack  string `json:"stack"`
+}
+
 type SessionModule struct {
 	Name       string        `json:"name"`
 	Session    *Session      `json:"-"`
@@ -38,6 +46,7 @@ type SessionModule struct {
 
 	handlers []ModuleHandler
 	params   map[string]*ModuleParam
+	counters *moduleCounters
 }
 
 func NewSessionModule(name string, s *Session) SessionModule {
@@ -49,11 +58,32 @@ func NewSessionModule(name string, s *Session) SessionModule {
 
 		handlers: make([]ModuleHandler, 0),
 		params:   make(map[string]*ModuleParam),
+		counters: &moduleCounters{},
 	}
 
+	m.AddHandler(NewModuleHandler(name+".events on", "",
+		"Push the events of this module to the session events (default).",
+		func(args []string) error {
+			s.Events.Unmute(name)
+			return nil
+		}))
+
+	m.AddHandler(NewModuleHandler(name+".events off", "",
+		"Stop pushing the events of this module to the session events, the module keeps working.",
+		func(args []string) error {
+			s.Events.Mute(name)
+			return nil
+		}))
+
 	return m
 }
 
+// EmitsEvents sets the tag prefixes of the events raised on behalf of
+// this module, so that they can be muted with MODULE.events off.
+func (m *SessionModule) EmitsEvents(prefixes ...string) {
+	m.Session.Events.SetSources(m.Name, prefixes...)
+}
+
 func (m *SessionModule) Handlers() []ModuleHandler {
 	return m.handlers
 }
@@ -164,7 +194,7 @@ func (m *SessionModule) SetRunning(running bool, cb func()) error {
 	if cb != nil {
 		if running {
 			// this is the worker, start async
-			go cb()
+			go m.runWorker(cb)
 		} else {
 			// stop callback, this is sync with a 10 seconds timeout
 			done := make(chan bool, 1)
@@ -184,3 +214,37 @@ func (m *SessionModule) SetRunning(running bool, cb func()) error {
 
 	return nil
 }
+
+// runWorker runs the module worker and, unless -no-recover was given,
+// turns a panic into a module.panic event and stops the module instead
+// of taking the whole session down with it.
+func (m *SessionModule) runWorker(cb func()) {
+	if !*m.Session.Options.NoRecover {
+		defer func() {
+			if r := recover(); r != nil {
+				stack := string(debug.Stack())
+
+				fmt.Printf("%s: module %s crashed: %v\n%s\n", core.Red(core.Bold("ERROR")), m.Name, r, stack)
+
+				m.Session.Events.Add("module.panic", ModulePanic{
+					Module: m.Name,
+					Error:  fmt.Sprintf("%v", r),
+					Stack:  stack,
+				})
+
+				if m.Running() {
+					// go through the module's own Stop so it can release its resources
+					if err, mod := m.Session.Module(m.Name); err == nil {
+						mod.Stop()
+					}
+					// in case Stop itself did not mark it as stopped
+					m.StatusLock.Lock()
+					m.Started = false
+					m.StatusLock.Unlock()
+				}
+			}
+		}()
+	}
+
+	withModuleLabel(m.Name, cb)
+}
@@ -0,0 +1,48 @@
+package session
+
+import (
+	"github.com/bettercap/bettercap/core"
+)
+
+// Debug, Info, Warning and Error log on behalf of the module, with
+// MODULE.quiet only its warnings and errors are logged while with
+// MODULE.verbose its debug messages are logged regardless of the debug mode.
+func (m *SessionModule) Debug(format string, args ...interface{}) {
+	m.log(core.DEBUG, format, args...)
+}
+
+func (m *SessionModule) Info(format string, args ...interface{}) {
+	m.log(core.INFO, format, args...)
+}
+
+func (m *SessionModule) Warning(format string, args ...interface{}) {
+	m.log(core.WARNING, format, args...)
+}
+
+func (m *SessionModule) Error(format string, args ...interface{}) {
+	m.log(core.ERROR, format, args...)
+}
+
+// AddLogParams registers MODULE.quiet and MODULE.verbose, only modules
+// logging with the helpers above call it.
+func (m *SessionModule) AddLogParams() {
+	m.AddParam(NewBoolParameter(m.Name+".quiet",
+		"false",
+		"If true, only the warnings and errors of this module are logged, its events are still pushed."))
+
+	m.AddParam(NewBoolParameter(m.Name+".verbose",
+		"false",
+		"If true, the debug messages of this module are logged even if the debug mode is disabled."))
+}
+
+func (m *SessionModule) flag(name string) bool {
+	found, v := m.Session.Env.Get(m.Name + "." + name)
+	return found && v == "true"
+}
+
+func (m *SessionModule) log(level int, format string, args ...interface{}) {
+	if level < core.WARNING && m.flag("quiet") {
+		return
+	}
+	m.Session.Events.log(level, level == core.DEBUG && m.flag("verbose"), format, args...)
+}
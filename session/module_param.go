@@ -98,6 +98,8 @@ func (p ModuleParam) Get(s *Session) (error, interface{}) {
 	} else if v == ParamRandomMAC {
 		hw := make([]byte, 6)
 		rand.Read(hw)
+		// unicast and locally administered
+		hw[0] = (hw[0] | 0x02) &^ 0x01
 		v = net.HardwareAddr(hw).String()
 	}
 	return p.Validate(v)
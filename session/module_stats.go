@@ -0,0 +1,197 @@
+package session
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/bettercap/bettercap/core"
+
+	"github.com/dustin/go-humanize"
+)
+
+// the goroutines started by a module worker or by one of its handlers carry
+// this profiler label, so they can be counted without any bookkeeping
+const moduleLabel = "module"
+
+// moduleCounters are shared by the copies of a SessionModule.
+type moduleCounters struct {
+	sent     uint64
+	received uint64
+}
+
+// ModuleStats is the resource usage of a single module, per module CPU time
+// and allocations are not available since the Go runtime only accounts
+// them for the whole process.
+type ModuleStats struct {
+	Name           string `json:"name"`
+	Running        bool   `json:"running"`
+	Goroutines     int    `json:"goroutines"`
+	FramesSent     uint64 `json:"frames_sent"`
+	FramesReceived uint64 `json:"frames_received"`
+}
+
+// ModulesStats is the payload of modules.stats events.
+type ModulesStats struct {
+	Goroutines int           `json:"goroutines"`
+	Untracked  int           `json:"untracked"`
+	HeapAlloc  uint64        `json:"heap_alloc"`
+	TotalAlloc uint64        `json:"total_alloc"`
+	Mallocs    uint64        `json:"mallocs"`
+	NumGC      uint32        `json:"num_gc"`
+	Modules    []ModuleStats `json:"modules"`
+}
+
+// withModuleLabel runs fn with the label of the module name, every goroutine
+// started by fn inherits it.
+func withModuleLabel(name string, fn func()) {
+	pprof.Do(context.Background(), pprof.Labels(moduleLabel, name), func(context.Context) {
+		fn()
+	})
+}
+
+// Send injects a raw frame on behalf of the module.
+func (m *SessionModule) Send(raw []byte) error {
+	m.FrameSent()
+	return m.Session.Queue.Send(raw)
+}
+
+// FrameSent accounts a frame written by the module to its own handle.
+func (m *SessionModule) FrameSent() {
+	atomic.AddUint64(&m.counters.sent, 1)
+}
+
+// FrameReceived accounts a frame read by the module from its own handle.
+func (m *SessionModule) FrameReceived() {
+	atomic.AddUint64(&m.counters.received, 1)
+}
+
+func (m *SessionModule) frameCounters() (sent uint64, received uint64) {
+	return atomic.LoadUint64(&m.counters.sent), atomic.LoadUint64(&m.counters.received)
+}
+
+// parseGoroutineLabels counts the goroutines of a debug=1 goroutine profile
+// by the value of the module label.
+func parseGoroutineLabels(r io.Reader) (total int, byModule map[string]int) {
+	byModule = make(map[string]int)
+	count := 0
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "# labels: ") {
+			labels := make(map[string]string)
+			if err := json.Unmarshal([]byte(line[len("# labels: "):]), &labels); err == nil {
+				if name, found := labels[moduleLabel]; found {
+					byModule[name] += count
+				}
+			}
+		} else if idx := strings.Index(line, " @ "); idx > 0 {
+			if n, err := strconv.Atoi(line[:idx]); err == nil {
+				count = n
+				total += n
+			}
+		}
+	}
+
+	return
+}
+
+// ModulesStats collects the resource usage of the modules, this is only done
+// when asked since both the goroutine profile and the memory statistics
+// briefly stop the world.
+func (s *Session) ModulesStats() ModulesStats {
+	buf := bytes.Buffer{}
+	pprof.Lookup("goroutine").WriteTo(&buf, 1)
+	total, goroutines := parseGoroutineLabels(&buf)
+
+	mem := runtime.MemStats{}
+	runtime.ReadMemStats(&mem)
+
+	stats := ModulesStats{
+		Goroutines: total,
+		Untracked:  total,
+		HeapAlloc:  mem.HeapAlloc,
+		TotalAlloc: mem.TotalAlloc,
+		Mallocs:    mem.Mallocs,
+		NumGC:      mem.NumGC,
+		Modules:    make([]ModuleStats, 0),
+	}
+
+	for _, m := range s.Modules {
+		mod := ModuleStats{
+			Name:       m.Name(),
+			Running:    m.Running(),
+			Goroutines: goroutines[m.Name()],
+		}
+		if c, ok := m.(interface {
+			frameCounters() (uint64, uint64)
+		}); ok {
+			mod.FramesSent, mod.FramesReceived = c.frameCounters()
+		}
+		stats.Untracked -= mod.Goroutines
+		stats.Modules = append(stats.Modules, mod)
+	}
+
+	sort.Slice(stats.Modules, func(i, j int) bool {
+		return stats.Modules[i].Name < stats.Modules[j].Name
+	})
+
+	return stats
+}
+
+func (s *Session) modulesStatsHandler(args []string, sess *Session) error {
+	stats := s.ModulesStats()
+
+	rows := make([][]string, 0)
+	for _, m := range stats.Modules {
+		// idle modules that never did anything are just noise
+		if !m.Running && m.Goroutines == 0 && m.FramesSent == 0 && m.FramesReceived == 0 {
+			continue
+		}
+
+		status := core.Dim("stopped")
+		if m.Running {
+			status = core.Green("running")
+		}
+
+		rows = append(rows, []string{
+			core.Bold(m.Name),
+			status,
+			fmt.Sprintf("%d", m.Goroutines),
+			humanize.Comma(int64(m.FramesSent)),
+			humanize.Comma(int64(m.FramesReceived)),
+		})
+	}
+
+	fmt.Println()
+	if len(rows) > 0 {
+		core.AsTable(os.Stdout, []string{"Module", "Status", "Goroutines", "Frames Sent", "Frames Received"}, rows)
+	} else {
+		fmt.Println(core.Dim("  no module is running."))
+		fmt.Println()
+	}
+
+	fmt.Printf("  %d goroutines (%d not started by modules), heap %s, %s allocated in total over %s allocations, %d GC cycles.\n\n",
+		stats.Goroutines,
+		stats.Untracked,
+		humanize.Bytes(stats.HeapAlloc),
+		humanize.Bytes(stats.TotalAlloc),
+		humanize.Comma(int64(stats.Mallocs)),
+		stats.NumGC)
+
+	s.Events.Add("modules.stats", stats)
+
+	return nil
+}
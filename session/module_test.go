@@ -0,0 +1,141 @@
+package session
+
+import (
+	"bytes"
+	"runtime/pprof"
+	"testing"
+	"time"
+
+	"github.com/bettercap/bettercap/core"
+)
+
+func TestSessionModulePanicRecovery(t *testing.T) {
+	debug, noRecover := false, false
+	env, _ := NewEnvironment("")
+	s := &Session{
+		Options: core.Options{Debug: &debug, NoRecover: &noRecover},
+		Events:  NewEventPool(false, true),
+		Env:     env,
+	}
+	m := NewSessionModule("test", s)
+
+	if err := m.SetRunning(true, func() { panic("boom") }); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for m.Running() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if m.Running() {
+		t.Fatal("expected the module to be stopped after a panic")
+	}
+
+	found := false
+	for _, e := range s.Events.Sorted() {
+		if e.Tag == "module.panic" {
+			found = true
+			if crash := e.Data.(ModulePanic); crash.Module != "test" || crash.Error != "boom" {
+				t.Fatalf("unexpected payload %+v", crash)
+			}
+		}
+	}
+	if !found {
+		t.Fatal("expected a module.panic event")
+	}
+}
+
+func TestSessionModuleLog(t *testing.T) {
+	env, _ := NewEnvironment("")
+	s := &Session{
+		Events: NewEventPool(false, false),
+		Env:    env,
+	}
+	m := NewSessionModule("test", s)
+	m.AddLogParams()
+
+	count := func() int {
+		n := 0
+		for _, e := range s.Events.Sorted() {
+			if e.Tag == "sys.log" {
+				n++
+			}
+		}
+		s.Events.Clear()
+		return n
+	}
+
+	m.Debug("debug")
+	m.Info("info")
+	m.Warning("warning")
+	if n := count(); n != 2 {
+		t.Fatalf("expected 2 messages by default, got %d", n)
+	}
+
+	env.Set("test.quiet", "true")
+	m.Debug("debug")
+	m.Info("info")
+	m.Warning("warning")
+	m.Error("error")
+	if n := count(); n != 2 {
+		t.Fatalf("expected only warnings and errors when quiet, got %d messages", n)
+	}
+
+	env.Set("test.quiet", "false")
+	env.Set("test.verbose", "true")
+	m.Debug("debug")
+	m.Info("info")
+	if n := count(); n != 2 {
+		t.Fatalf("expected debug messages when verbose, got %d messages", n)
+	}
+}
+
+func TestSessionModuleGoroutineLabels(t *testing.T) {
+	debug, noRecover := false, false
+	env, _ := NewEnvironment("")
+	s := &Session{
+		Options: core.Options{Debug: &debug, NoRecover: &noRecover},
+		Events:  NewEventPool(false, true),
+		Env:     env,
+	}
+	m := NewSessionModule("test", s)
+
+	quit := make(chan bool)
+	started := make(chan bool)
+	m.SetRunning(true, func() {
+		// children inherit the label of the worker
+		go func() { <-quit }()
+		started <- true
+		<-quit
+	})
+	<-started
+	defer close(quit)
+
+	buf := bytes.Buffer{}
+	pprof.Lookup("goroutine").WriteTo(&buf, 1)
+	total, byModule := parseGoroutineLabels(&buf)
+
+	if byModule["test"] != 2 {
+		t.Fatalf("expected 2 goroutines for the module, got %d", byModule["test"])
+	} else if total < 2 {
+		t.Fatalf("unexpected total of %d goroutines", total)
+	}
+}
+
+func TestSessionModuleFrameCounters(t *testing.T) {
+	env, _ := NewEnvironment("")
+	s := &Session{
+		Events: NewEventPool(false, false),
+		Env:    env,
+	}
+	m := NewSessionModule("test", s)
+	copied := m
+
+	m.FrameReceived()
+	copied.FrameReceived()
+
+	if _, received := m.frameCounters(); received != 2 {
+		t.Fatalf("expected 2 received frames, got %d", received)
+	}
+}
@@ -10,8 +10,10 @@ import (
 )
 
 const (
-	PromptVariable = "$"
-	DefaultPrompt  = "{by}{fw}{cidr} {fb}> {env.iface.ipv4} {reset} {bold}» {reset}"
+	PromptVariable = "main.prompt"
+	// the old prompt variable, setting it will set main.prompt instead
+	LegacyPromptVariable = "$"
+	DefaultPrompt        = "{by}{fw}{cidr} {fb}> {env.iface.ipv4} {reset} {bold}» {reset}"
 )
 
 var (
@@ -37,6 +39,33 @@ var (
 		"{cidr}": func(s *Session) string {
 			return s.Interface.CIDR()
 		},
+		"{iface}": func(s *Session) string {
+			return s.Interface.Name()
+		},
+		"{targets}": func(s *Session) string {
+			return fmt.Sprintf("%d", len(s.Lan.List()))
+		},
+		"{clients}": func(s *Session) string {
+			clients := 0
+			if s.WiFi != nil {
+				for _, ap := range s.WiFi.List() {
+					clients += ap.NumClients()
+				}
+			}
+			return fmt.Sprintf("%d", clients)
+		},
+		"{running-modules}": func(s *Session) string {
+			running := make([]string, 0)
+			for _, m := range s.Modules {
+				if m.Running() {
+					running = append(running, m.Name())
+				}
+			}
+			if len(running) == 0 {
+				return "-"
+			}
+			return strings.Join(running, ",")
+		},
 		"{net.sent}": func(s *Session) string {
 			return fmt.Sprintf("%d", s.Queue.Stats.Sent)
 		},
@@ -67,7 +96,7 @@ func NewPrompt() Prompt {
 
 func (p Prompt) Render(s *Session) string {
 	found, prompt := s.Env.Get(PromptVariable)
-	if !found {
+	if !found || prompt == "" {
 		prompt = DefaultPrompt
 	}
 
@@ -26,6 +26,11 @@ import (
 
 const (
 	HistoryFile = "~/bettercap.history"
+	// max number of background jobs running at once, 0 for unlimited
+	WorkersVariable = "main.workers.max"
+	// max number of events kept in the buffer, 0 for unlimited
+	EventsMaxVariable = "events.max"
+	DefaultEventsMax  = "4096"
 )
 
 var (
@@ -44,18 +49,19 @@ type UnknownCommandCallback func(cmd string) bool
 type ModuleList []Module
 
 type Session struct {
-	Options   core.Options      `json:"options"`
-	Interface *network.Endpoint `json:"interface"`
-	Gateway   *network.Endpoint `json:"gateway"`
-	Env       *Environment      `json:"env"`
-	Lan       *network.LAN      `json:"lan"`
-	WiFi      *network.WiFi     `json:"wifi"`
-	BLE       *network.BLE      `json:"ble"`
-	Queue     *packets.Queue    `json:"packets"`
-	StartedAt time.Time         `json:"started_at"`
-	Active    bool              `json:"active"`
-	GPS       nmea.GNGGA        `json:"gps"`
-	Modules   ModuleList        `json:"modules"`
+	Options    core.Options      `json:"options"`
+	Interface  *network.Endpoint `json:"interface"`
+	Gateway    *network.Endpoint `json:"gateway"`
+	Env        *Environment      `json:"env"`
+	Lan        *network.LAN      `json:"lan"`
+	WiFi       *network.WiFi     `json:"wifi"`
+	BLE        *network.BLE      `json:"ble"`
+	Queue      *packets.Queue    `json:"packets"`
+	StartedAt  time.Time         `json:"started_at"`
+	Active     bool              `json:"active"`
+	GPS        nmea.GNGGA        `json:"gps"`
+	Modules    ModuleList        `json:"modules"`
+	IfaceStats *IfaceStats       `json:"iface_stats"`
 
 	Input          *readline.Instance       `json:"-"`
 	Prompt         Prompt                   `json:"-"`
@@ -63,6 +69,8 @@ type Session struct {
 	Events         *EventPool               `json:"-"`
 	UnkCmdCallback UnknownCommandCallback   `json:"-"`
 	Firewall       firewall.FirewallManager `json:"-"`
+
+	paused int32
 }
 
 func (mm ModuleList) MarshalJSON() ([]byte, error) {
@@ -243,7 +251,12 @@ func (s *Session) Start() error {
 	s.StartedAt = time.Now()
 	s.Active = true
 
+	s.autorestore()
+
 	s.startNetMon()
+	s.startWatchdog()
+	s.startAutosave()
+	s.startIfaceStats()
 
 	if *s.Options.Debug {
 		s.Events.Add("session.started", nil)
@@ -315,12 +328,29 @@ func parseCapletCommand(line string) (is bool, caplet *caplets.Caplet, argv []st
 }
 
 func (s *Session) Run(line string) error {
+	return s.run(line, 0)
+}
+
+func (s *Session) run(line string, depth int) error {
 	line = core.TrimRight(line)
 	// remove extra spaces after the first command
 	// so that 'arp.spoof      on' is normalized
 	// to 'arp.spoof on' (fixes #178)
 	line = reCmdSpaceCleaner.ReplaceAllString(line, "$1 $2")
 
+	// is it a command alias?
+	if isAlias, cmds := s.expandAlias(line); isAlias {
+		if depth >= maxAliasDepth {
+			return fmt.Errorf("alias expansion of \"%s\" is too deep, is it recursive?", line)
+		}
+		for _, cmd := range cmds {
+			if err := s.run(cmd, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
 	// replace all {env.something} with their values
 	line, err := s.parseEnvTokens(line)
 	if err != nil {
@@ -338,7 +368,10 @@ func (s *Session) Run(line string) error {
 	for _, m := range s.Modules {
 		for _, h := range m.Handlers() {
 			if parsed, args := h.Parse(line); parsed {
-				return h.Exec(args)
+				withModuleLabel(m.Name(), func() {
+					err = h.Exec(args)
+				})
+				return err
 			}
 		}
 	}
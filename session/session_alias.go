@@ -0,0 +1,123 @@
+package session
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// command aliases are stored in the environment so that they
+	// are persisted in the env file together with the other variables
+	AliasPrefix = "alias."
+
+	maxAliasDepth = 10
+)
+
+var (
+	reAliasName = regexp.MustCompile(`^[a-zA-Z0-9_\-]+$`)
+	reAliasArg  = regexp.MustCompile(`\$(\d+|\*)`)
+)
+
+// isCoreCommand returns true if name is (or starts) one of the core
+// commands, so that an alias can't shadow it.
+func (s *Session) isCoreCommand(name string) bool {
+	for _, h := range s.CoreHandlers {
+		if parsed, _ := h.Parse(name); parsed {
+			return true
+		} else if fields := strings.Fields(h.Name); len(fields) > 0 && fields[0] == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Session) SetAlias(name, command string) error {
+	if !reAliasName.MatchString(name) {
+		return fmt.Errorf("invalid alias name '%s'", name)
+	} else if s.isCoreCommand(name) {
+		return fmt.Errorf("alias name '%s' is a core command", name)
+	}
+	s.Env.Set(AliasPrefix+name, command)
+	return nil
+}
+
+func (s *Session) DelAlias(name string) error {
+	if !s.Env.Has(AliasPrefix + name) {
+		return fmt.Errorf("alias '%s' not found", name)
+	}
+	s.Env.Unset(AliasPrefix + name)
+	return nil
+}
+
+func (s *Session) Aliases() map[string]string {
+	aliases := make(map[string]string)
+	for _, key := range s.Env.Sorted() {
+		if strings.HasPrefix(key, AliasPrefix) {
+			_, aliases[strings.TrimPrefix(key, AliasPrefix)] = s.Env.Get(key)
+		}
+	}
+	return aliases
+}
+
+// expandAlias returns the commands an alias expands to, with $1, $2, ...
+// replaced by the positional arguments and $* by all of them.
+func (s *Session) expandAlias(line string) (bool, []string) {
+	parts := strings.Fields(line)
+	if len(parts) == 0 || !reAliasName.MatchString(parts[0]) || s.isCoreCommand(parts[0]) {
+		return false, nil
+	}
+
+	found, command := s.Env.Get(AliasPrefix + parts[0])
+	if !found {
+		return false, nil
+	}
+
+	args := parts[1:]
+	command = reAliasArg.ReplaceAllStringFunc(command, func(m string) string {
+		if m == "$*" {
+			return strings.Join(args, " ")
+		} else if n, err := strconv.Atoi(m[1:]); err == nil && n > 0 && n <= len(args) {
+			return args[n-1]
+		}
+		return ""
+	})
+
+	return true, ParseCommands(command)
+}
+
+func (s *Session) aliasListHandler(args []string, sess *Session) error {
+	aliases := s.Aliases()
+	if len(aliases) == 0 {
+		fmt.Printf("\nNo aliases defined.\n\n")
+		return nil
+	}
+
+	names := make([]string, 0, len(aliases))
+	padding := 0
+	for name := range aliases {
+		names = append(names, name)
+		if len(name) > padding {
+			padding = len(name)
+		}
+	}
+	sort.Strings(names)
+
+	fmt.Println()
+	for _, name := range names {
+		fmt.Printf("  %"+strconv.Itoa(padding)+"s: '%s'\n", name, aliases[name])
+	}
+	fmt.Println()
+
+	return nil
+}
+
+func (s *Session) aliasCommandHandler(args []string, sess *Session) error {
+	return s.SetAlias(args[0], args[1])
+}
+
+func (s *Session) aliasDelHandler(args []string, sess *Session) error {
+	return s.DelAlias(args[0])
+}
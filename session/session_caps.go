@@ -0,0 +1,213 @@
+package session
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/bettercap/bettercap/core"
+)
+
+const (
+	capNetAdmin = 12
+	capNetRaw   = 13
+)
+
+// Capability is a single entry of the caps report.
+type Capability struct {
+	Name      string `json:"name"`
+	Available bool   `json:"available"`
+	Details   string `json:"details"`
+	Hint      string `json:"hint"`
+}
+
+// CapsReport is the payload of the caps event.
+type CapsReport struct {
+	OS           string       `json:"os"`
+	Interface    string       `json:"interface"`
+	Privileged   bool         `json:"privileged"`
+	Capabilities []Capability `json:"capabilities"`
+}
+
+// parseCapEff returns the effective capabilities set from the contents of
+// /proc/self/status.
+func parseCapEff(status string) (uint64, bool) {
+	for _, line := range strings.Split(status, "\n") {
+		if strings.HasPrefix(line, "CapEff:") {
+			caps, err := strconv.ParseUint(strings.TrimSpace(line[7:]), 16, 64)
+			return caps, err == nil
+		}
+	}
+	return 0, false
+}
+
+// hasNetCaps tells if the process can open raw sockets and configure the
+// interfaces, either because it's root or because it has been given the
+// CAP_NET_RAW and CAP_NET_ADMIN capabilities.
+func hasNetCaps() (raw bool, admin bool, details string) {
+	if os.Geteuid() == 0 {
+		return true, true, "running as root"
+	} else if runtime.GOOS != "linux" && runtime.GOOS != "android" {
+		return false, false, fmt.Sprintf("running as uid %d", os.Geteuid())
+	}
+
+	status, err := ioutil.ReadFile("/proc/self/status")
+	if err != nil {
+		return false, false, err.Error()
+	}
+
+	caps, found := parseCapEff(string(status))
+	if !found {
+		return false, false, "could not read the effective capabilities"
+	}
+
+	raw = caps&(1<<capNetRaw) != 0
+	admin = caps&(1<<capNetAdmin) != 0
+	return raw, admin, fmt.Sprintf("running as uid %d with capabilities %016x", os.Geteuid(), caps)
+}
+
+// monitorListed tells if the output of 'iw phy PHY info' lists monitor among
+// the supported interface modes, other sections mention it too.
+func monitorListed(info string) bool {
+	inModes := false
+	for _, line := range strings.Split(info, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "Supported interface modes:" {
+			inModes = true
+		} else if inModes && strings.HasPrefix(line, "* ") {
+			if strings.TrimSpace(line[2:]) == "monitor" {
+				return true
+			}
+		} else if inModes {
+			return false
+		}
+	}
+	return false
+}
+
+// monitorSupport tells if the wireless adapter behind iface supports the
+// monitor mode, without changing anything.
+func monitorSupport(iface string) (bool, string) {
+	if runtime.GOOS != "linux" && runtime.GOOS != "android" {
+		return false, fmt.Sprintf("can't be detected on %s", runtime.GOOS)
+	}
+
+	phy, err := ioutil.ReadFile(filepath.Join("/sys/class/net", iface, "phy80211", "name"))
+	if err != nil {
+		return false, fmt.Sprintf("%s is not a wireless interface", iface)
+	}
+
+	out, err := core.ExecSilent("iw", []string{"phy", core.Trim(string(phy)), "info"})
+	if err != nil {
+		return false, fmt.Sprintf("could not run iw: %s", err)
+	}
+
+	if monitorListed(out) {
+		return true, fmt.Sprintf("%s supports monitor mode", core.Trim(string(phy)))
+	}
+	return false, fmt.Sprintf("%s doesn't list monitor among its interface modes", core.Trim(string(phy)))
+}
+
+func bleAdapters() []string {
+	adapters := []string{}
+	if runtime.GOOS == "linux" || runtime.GOOS == "android" {
+		entries, _ := ioutil.ReadDir("/sys/class/bluetooth")
+		for _, entry := range entries {
+			if strings.HasPrefix(entry.Name(), "hci") {
+				adapters = append(adapters, entry.Name())
+			}
+		}
+	}
+	return adapters
+}
+
+// Capabilities checks what the current privileges and adapters allow to
+// do, it only reads the system state and never changes it.
+func (s *Session) Capabilities() CapsReport {
+	iface := s.Interface.Name()
+	raw, admin, privDetails := hasNetCaps()
+	report := CapsReport{
+		OS:           runtime.GOOS,
+		Interface:    iface,
+		Privileged:   raw && admin,
+		Capabilities: make([]Capability, 0),
+	}
+
+	add := func(name string, available bool, details string, hint string) {
+		if available {
+			hint = ""
+		}
+		report.Capabilities = append(report.Capabilities, Capability{name, available, details, hint})
+	}
+
+	add("privileges", raw && admin, privDetails,
+		"run as root or give the binary CAP_NET_RAW and CAP_NET_ADMIN (setcap cap_net_raw,cap_net_admin=eip bettercap)")
+
+	captureDetails := fmt.Sprintf("capture handle open on %s", iface)
+	if !s.Queue.Active() {
+		captureDetails = fmt.Sprintf("no capture handle on %s", iface)
+	} else if s.Queue.Failed() {
+		captureDetails = fmt.Sprintf("the capture on %s failed", iface)
+	}
+	add("raw sockets", raw && s.Queue.Active() && !s.Queue.Failed(), captureDetails,
+		"check the privileges and that the interface is up, monitor mode interfaces are only used by the wifi module")
+
+	monitor, monitorDetails := monitorSupport(iface)
+	if s.Interface.IsMonitor() {
+		monitor, monitorDetails = true, fmt.Sprintf("%s is already in monitor mode", iface)
+	}
+	add("monitor mode", monitor, monitorDetails,
+		"use a wireless adapter whose driver supports monitor mode to use the wifi module")
+
+	injection := monitor && admin
+	injectionDetails := "requires monitor mode and privileges"
+	if injection {
+		injectionDetails = "likely, it depends on the driver and can only be verified by sending frames"
+	}
+	add("injection", injection, injectionDetails,
+		"wifi.deauth and wifi.ap need an adapter supporting both monitor mode and injection")
+
+	adapters := bleAdapters()
+	bleDetails := "no bluetooth adapters found"
+	if len(adapters) > 0 {
+		bleDetails = strings.Join(adapters, ", ")
+	} else if runtime.GOOS != "linux" && runtime.GOOS != "android" {
+		bleDetails = fmt.Sprintf("ble.recon is not supported on %s", runtime.GOOS)
+	}
+	add("ble", len(adapters) > 0 && admin, bleDetails,
+		"ble.recon needs a HCI bluetooth adapter and privileges")
+
+	forwarding := s.Firewall != nil && s.Firewall.IsForwardingEnabled()
+	forwardingDetails := "disabled, it will be enabled by the spoofers"
+	if forwarding {
+		forwardingDetails = "enabled"
+	}
+	add("forwarding", forwarding || admin, forwardingDetails,
+		"the spoofers need privileges to enable packet forwarding, or traffic of the targets will be dropped")
+
+	return report
+}
+
+func (s *Session) capsHandler(args []string, sess *Session) error {
+	report := s.Capabilities()
+
+	rows := make([][]string, 0)
+	for _, c := range report.Capabilities {
+		status := core.Green("yes")
+		if !c.Available {
+			status = core.Red("no")
+		}
+		rows = append(rows, []string{c.Name, status, c.Details, core.Dim(c.Hint)})
+	}
+
+	fmt.Println()
+	core.AsTable(os.Stdout, []string{"Capability", "Available", "Details", "Hint"}, rows)
+	fmt.Println()
+
+	s.Events.Add("caps", report)
+	return nil
+}
@@ -0,0 +1,30 @@
+package session
+
+import "testing"
+
+func TestParseCapEff(t *testing.T) {
+	status := "Name:\tbettercap\nCapInh:\t0000000000000000\nCapPrm:\t0000000000003000\nCapEff:\t0000000000003000\n"
+	caps, found := parseCapEff(status)
+	if !found {
+		t.Fatal("expected CapEff to be found")
+	} else if caps&(1<<capNetRaw) == 0 || caps&(1<<capNetAdmin) == 0 {
+		t.Fatalf("expected CAP_NET_RAW and CAP_NET_ADMIN in %x", caps)
+	}
+
+	if _, found = parseCapEff("Name:\tbettercap\n"); found {
+		t.Fatal("expected CapEff not to be found")
+	}
+}
+
+func TestMonitorListed(t *testing.T) {
+	info := "Wiphy phy0\n\tSupported interface modes:\n\t\t * IBSS\n\t\t * managed\n\t\t * monitor\n\tBand 1:\n"
+	if !monitorListed(info) {
+		t.Fatal("expected monitor mode to be listed")
+	}
+
+	// monitor is mentioned by other sections too
+	info = "Wiphy phy0\n\tSupported interface modes:\n\t\t * managed\n\tsoftware interface modes (can always be added):\n\t\t * monitor\n"
+	if monitorListed(info) {
+		t.Fatal("expected monitor mode not to be listed")
+	}
+}
@@ -225,6 +225,10 @@ func (s *Session) setHandler(args []string, sess *Session) error {
 		value = ""
 	}
 
+	if key == LegacyPromptVariable {
+		key = PromptVariable
+	}
+
 	s.Env.Set(key, value)
 	return nil
 }
@@ -396,4 +400,80 @@ func (s *Session) registerCoreHandlers() {
 			return macs
 		})))
 
+	s.addHandler(NewCommandHandler("alias NAME COMMAND",
+		`^alias\s+([a-zA-Z0-9_\-]+)\s+(.+)$`,
+		"Define NAME as an alias for COMMAND (multiple commands can be quoted and separated by ;), $1, $2, ... and $* will be replaced by the alias arguments.",
+		s.aliasCommandHandler),
+		readline.PcItem("alias"))
+
+	s.addHandler(NewCommandHandler("alias.list",
+		"^alias\\.list$",
+		"List the command aliases.",
+		s.aliasListHandler),
+		readline.PcItem("alias.list"))
+
+	s.addHandler(NewCommandHandler("alias.del NAME",
+		"^alias\\.del\\s+([^\\s]+)$",
+		"Remove the command alias NAME.",
+		s.aliasDelHandler),
+		readline.PcItem("alias.del", readline.PcItemDynamic(func(prefix string) []string {
+			prefix = core.Trim(prefix[9:])
+			names := []string{""}
+			for name := range s.Aliases() {
+				if prefix == "" || strings.HasPrefix(name, prefix) {
+					names = append(names, name)
+				}
+			}
+			return names
+		})))
+
+	s.addHandler(NewCommandHandler("caps",
+		"^caps$",
+		"Check which privileges and adapter capabilities (raw sockets, monitor mode, injection, BLE) are available.",
+		s.capsHandler),
+		readline.PcItem("caps"))
+
+	s.addHandler(NewCommandHandler("modules.stats",
+		"^modules\\.stats$",
+		"Show the goroutines and the frames sent and received by each module, along with the memory usage of the session.",
+		s.modulesStatsHandler),
+		readline.PcItem("modules.stats"))
+
+	s.addHandler(NewCommandHandler("iface.stats",
+		"^iface\\.stats$",
+		"Show the RX/TX bandwidth and packet rate of the interface sampled every "+IfaceStatsIntervalVariable+" seconds.",
+		s.ifaceStatsHandler),
+		readline.PcItem("iface.stats"))
+
+	s.addHandler(NewCommandHandler("session.pause",
+		`^session\.pause$`,
+		"Suspend the transmissions of every running module supporting it (arp.spoof, dhcp6.spoof, syn.scan, wifi.deauth and wifi.ap) without stopping them.",
+		s.pauseHandler),
+		readline.PcItem("session.pause"))
+
+	s.addHandler(NewCommandHandler("session.resume",
+		`^session\.resume$`,
+		"Resume the transmissions suspended with session.pause.",
+		s.resumeHandler),
+		readline.PcItem("session.resume"))
+
+	s.addHandler(NewCommandHandler("session.save FILE",
+		`^session\.save\s+(.+)$`,
+		"Save the discovered hosts, access points and BLE devices to FILE.",
+		s.saveHandler),
+		readline.PcItem("session.save"))
+
+	s.addHandler(NewCommandHandler("session.load FILE",
+		`^session\.load\s+(.+)$`,
+		"Load the hosts and access points saved with session.save from FILE.",
+		s.loadHandler),
+		readline.PcItem("session.load", readline.PcItemDynamic(func(prefix string) []string {
+			prefix = core.Trim(prefix[12:])
+			if prefix == "" {
+				prefix = "."
+			}
+
+			files, _ := filepath.Glob(prefix + "*")
+			return files
+		})))
 }
@@ -0,0 +1,50 @@
+package session
+
+import (
+	"github.com/bettercap/bettercap/packets"
+
+	"github.com/google/gopacket/layers"
+)
+
+const DryRunVariable = "main.dryrun"
+
+// DryRunFrame is the payload of sys.dryrun events, a frame a module built
+// and would have sent if main.dryrun was false.
+type DryRunFrame struct {
+	Size    int    `json:"size"`
+	Summary string `json:"summary"`
+	Data    []byte `json:"data"`
+}
+
+func (s *Session) dryRunEnabled() bool {
+	found, v := s.Env.Get(DryRunVariable)
+	return found && v == "true"
+}
+
+func (s *Session) onDryRun(linkType layers.LinkType, raw []byte) {
+	s.Events.Add("sys.dryrun", DryRunFrame{
+		Size:    len(raw),
+		Summary: packets.Describe(linkType, raw),
+		Data:    append([]byte(nil), raw...),
+	})
+}
+
+func (s *Session) setDryRun(enabled bool) {
+	if enabled {
+		s.Queue.OnDryRun(s.onDryRun)
+	} else {
+		s.Queue.OnDryRun(nil)
+	}
+}
+
+func (s *Session) setupDryRun() {
+	dryRun := "false"
+	if found, v := s.Env.Get(DryRunVariable); found && v != "" {
+		dryRun = v
+	}
+
+	// the environment is locked while callbacks run, so this one can't log
+	s.Env.WithCallback(DryRunVariable, dryRun, func(newValue string) {
+		s.setDryRun(newValue == "true")
+	})
+}
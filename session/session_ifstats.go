@@ -0,0 +1,268 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/bettercap/bettercap/core"
+	"github.com/bettercap/bettercap/network"
+
+	"github.com/dustin/go-humanize"
+)
+
+const (
+	IfaceStatsIntervalVariable = "main.iface.stats.interval"
+	IfaceStatsSamplesVariable  = "main.iface.stats.samples"
+
+	ifaceStatsIdlePeriod = 1 * time.Second
+	ifaceStatsShown      = 10
+)
+
+var sparkTicks = []rune("▁▂▃▄▅▆▇█")
+
+// IfaceSample holds the interface counters at a given time and the rates
+// computed from the previous sample.
+type IfaceSample struct {
+	Time      time.Time `json:"time"`
+	RxBytes   uint64    `json:"rx_bytes"`
+	TxBytes   uint64    `json:"tx_bytes"`
+	RxPackets uint64    `json:"rx_packets"`
+	TxPackets uint64    `json:"tx_packets"`
+	RxRate    float64   `json:"rx_rate"`
+	TxRate    float64   `json:"tx_rate"`
+	RxPktRate float64   `json:"rx_pkt_rate"`
+	TxPktRate float64   `json:"tx_pkt_rate"`
+}
+
+// IfaceStats is the rolling series of samples of the capture interface,
+// Source is "kernel" when the counters come from /proc/net/dev and "pcap"
+// when they only account for what bettercap itself captured and sent.
+type IfaceStats struct {
+	sync.RWMutex
+
+	Interface string
+	Source    string
+	Samples   []IfaceSample
+	max       int
+}
+
+type ifaceStatsJSON struct {
+	Interface string        `json:"interface"`
+	Source    string        `json:"source"`
+	Samples   []IfaceSample `json:"samples"`
+}
+
+func NewIfaceStats(iface string, max int) *IfaceStats {
+	if max < 2 {
+		max = 2
+	}
+	return &IfaceStats{
+		Interface: iface,
+		Samples:   make([]IfaceSample, 0),
+		max:       max,
+	}
+}
+
+func (st *IfaceStats) MarshalJSON() ([]byte, error) {
+	st.RLock()
+	defer st.RUnlock()
+
+	return json.Marshal(ifaceStatsJSON{
+		Interface: st.Interface,
+		Source:    st.Source,
+		Samples:   st.Samples,
+	})
+}
+
+func counterRate(curr, prev uint64, elapsed float64) float64 {
+	// counters go back to zero if the interface is recreated
+	if curr < prev || elapsed <= 0 {
+		return 0
+	}
+	return float64(curr-prev) / elapsed
+}
+
+// Add appends a sample, computing its rates from the previous one, and drops
+// the oldest samples beyond the configured size.
+func (st *IfaceStats) Add(source string, at time.Time, c network.IfaceCounters) IfaceSample {
+	st.Lock()
+	defer st.Unlock()
+
+	sample := IfaceSample{
+		Time:      at,
+		RxBytes:   c.RxBytes,
+		TxBytes:   c.TxBytes,
+		RxPackets: c.RxPackets,
+		TxPackets: c.TxPackets,
+	}
+
+	// rates are meaningless across two different counter sources
+	if n := len(st.Samples); n > 0 && st.Source == source {
+		prev := st.Samples[n-1]
+		elapsed := at.Sub(prev.Time).Seconds()
+		sample.RxRate = counterRate(c.RxBytes, prev.RxBytes, elapsed)
+		sample.TxRate = counterRate(c.TxBytes, prev.TxBytes, elapsed)
+		sample.RxPktRate = counterRate(c.RxPackets, prev.RxPackets, elapsed)
+		sample.TxPktRate = counterRate(c.TxPackets, prev.TxPackets, elapsed)
+	} else {
+		st.Samples = st.Samples[:0]
+	}
+
+	st.Source = source
+	st.Samples = append(st.Samples, sample)
+	if over := len(st.Samples) - st.max; over > 0 {
+		st.Samples = st.Samples[over:]
+	}
+
+	return sample
+}
+
+// Resize changes the number of samples kept.
+func (st *IfaceStats) Resize(max int) {
+	st.Lock()
+	defer st.Unlock()
+
+	if max < 2 {
+		max = 2
+	}
+	st.max = max
+	if over := len(st.Samples) - st.max; over > 0 {
+		st.Samples = st.Samples[over:]
+	}
+}
+
+// List returns a copy of the samples, oldest first.
+func (st *IfaceStats) List() []IfaceSample {
+	st.RLock()
+	defer st.RUnlock()
+
+	list := make([]IfaceSample, len(st.Samples))
+	copy(list, st.Samples)
+	return list
+}
+
+// sparkline draws one tick per value, scaled on the largest one.
+func sparkline(values []float64) string {
+	max := 0.0
+	for _, v := range values {
+		if v > max {
+			max = v
+		}
+	}
+
+	line := make([]rune, len(values))
+	for i, v := range values {
+		idx := 0
+		if max > 0 {
+			idx = int(v / max * float64(len(sparkTicks)-1))
+		}
+		line[i] = sparkTicks[idx]
+	}
+	return string(line)
+}
+
+// ifaceCounters reads the kernel counters of the capture interface, falling
+// back to the traffic accounted by the packet queue.
+func (s *Session) ifaceCounters() (string, network.IfaceCounters) {
+	if c, err := network.GetInterfaceCounters(s.Interface.Name()); err == nil {
+		return "kernel", *c
+	}
+
+	c := network.IfaceCounters{}
+	if s.Queue != nil {
+		s.Queue.Stats.RLock()
+		c.RxBytes = s.Queue.Stats.Received
+		c.TxBytes = s.Queue.Stats.Sent
+		c.RxPackets = s.Queue.Stats.PktReceived
+		s.Queue.Stats.RUnlock()
+	}
+	return "pcap", c
+}
+
+func (s *Session) ifaceStatsConfig() (interval int, samples int) {
+	if err, v := s.Env.GetInt(IfaceStatsIntervalVariable); err == nil {
+		interval = v
+	}
+	samples = 60
+	if err, v := s.Env.GetInt(IfaceStatsSamplesVariable); err == nil && v > 0 {
+		samples = v
+	}
+	return
+}
+
+func (s *Session) startIfaceStats() {
+	_, samples := s.ifaceStatsConfig()
+	s.IfaceStats = NewIfaceStats(s.Interface.Name(), samples)
+
+	go func() {
+		for s.Active {
+			interval, samples := s.ifaceStatsConfig()
+			if interval <= 0 {
+				time.Sleep(ifaceStatsIdlePeriod)
+				continue
+			}
+
+			s.IfaceStats.Resize(samples)
+			source, counters := s.ifaceCounters()
+			s.IfaceStats.Add(source, time.Now(), counters)
+
+			time.Sleep(time.Duration(interval) * time.Second)
+		}
+	}()
+}
+
+func (s *Session) ifaceStatsHandler(args []string, sess *Session) error {
+	if s.IfaceStats == nil {
+		return fmt.Errorf("the interface statistics poller is not running")
+	}
+
+	samples := s.IfaceStats.List()
+	if len(samples) == 0 {
+		return fmt.Errorf("no samples yet, make sure %s is greater than 0", IfaceStatsIntervalVariable)
+	}
+
+	rx := make([]float64, 0)
+	tx := make([]float64, 0)
+	for _, sample := range samples[1:] {
+		rx = append(rx, sample.RxRate)
+		tx = append(tx, sample.TxRate)
+	}
+
+	shown := samples
+	if len(shown) > ifaceStatsShown {
+		shown = shown[len(shown)-ifaceStatsShown:]
+	}
+
+	rows := make([][]string, 0)
+	for _, sample := range shown {
+		rows = append(rows, []string{
+			sample.Time.Format("15:04:05"),
+			humanize.Bytes(sample.RxBytes),
+			humanize.Bytes(sample.TxBytes),
+			humanize.Bytes(uint64(sample.RxRate)) + "/s",
+			humanize.Bytes(uint64(sample.TxRate)) + "/s",
+			fmt.Sprintf("%.1f", sample.RxPktRate),
+			fmt.Sprintf("%.1f", sample.TxPktRate),
+		})
+	}
+
+	last := samples[len(samples)-1]
+	s.IfaceStats.RLock()
+	source := s.IfaceStats.Source
+	s.IfaceStats.RUnlock()
+
+	fmt.Println()
+	fmt.Printf("%s (%s counters)\n\n", core.Bold(s.IfaceStats.Interface), source)
+	fmt.Printf("  RX %s %s/s\n", core.Green(sparkline(rx)), humanize.Bytes(uint64(last.RxRate)))
+	fmt.Printf("  TX %s %s/s\n\n", core.Blue(sparkline(tx)), humanize.Bytes(uint64(last.TxRate)))
+	core.AsTable(os.Stdout, []string{"Time", "RX", "TX", "RX Rate", "TX Rate", "RX pkt/s", "TX pkt/s"}, rows)
+	if source != "kernel" {
+		fmt.Println(core.Dim("  only the packets handled by bettercap are counted, TX packets are not available."))
+	}
+	fmt.Println()
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bettercap/bettercap/network"
+)
+
+func TestIfaceStatsRates(t *testing.T) {
+	st := NewIfaceStats("eth0", 3)
+	now := time.Now()
+
+	first := st.Add("kernel", now, network.IfaceCounters{RxBytes: 1000, TxBytes: 500, RxPackets: 10, TxPackets: 5})
+	if first.RxRate != 0 || first.TxRate != 0 {
+		t.Fatalf("the first sample should have no rates, got %+v", first)
+	}
+
+	second := st.Add("kernel", now.Add(2*time.Second), network.IfaceCounters{RxBytes: 3000, TxBytes: 1500, RxPackets: 30, TxPackets: 9})
+	if second.RxRate != 1000 || second.TxRate != 500 || second.RxPktRate != 10 || second.TxPktRate != 2 {
+		t.Fatalf("unexpected rates %+v", second)
+	}
+
+	// counters reset when the interface is recreated
+	third := st.Add("kernel", now.Add(3*time.Second), network.IfaceCounters{RxBytes: 100})
+	if third.RxRate != 0 {
+		t.Fatalf("expected no rate after a counter reset, got %f", third.RxRate)
+	}
+}
+
+func TestIfaceStatsRolling(t *testing.T) {
+	st := NewIfaceStats("eth0", 3)
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		st.Add("kernel", now.Add(time.Duration(i)*time.Second), network.IfaceCounters{RxBytes: uint64(i)})
+	}
+
+	list := st.List()
+	if len(list) != 3 || list[0].RxBytes != 2 || list[2].RxBytes != 4 {
+		t.Fatalf("unexpected samples %+v", list)
+	}
+
+	st.Resize(2)
+	if list = st.List(); len(list) != 2 || list[0].RxBytes != 3 {
+		t.Fatalf("unexpected samples after resize %+v", list)
+	}
+
+	// a different source starts a new series
+	st.Add("pcap", now.Add(10*time.Second), network.IfaceCounters{RxBytes: 10})
+	if list = st.List(); len(list) != 1 || list[0].RxRate != 0 {
+		t.Fatalf("unexpected samples after a source change %+v", list)
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	if got := sparkline([]float64{0, 7, 14}); got != "▁▄█" {
+		t.Fatalf("unexpected sparkline %s", got)
+	} else if got := sparkline([]float64{0, 0}); got != "▁▁" {
+		t.Fatalf("unexpected sparkline %s", got)
+	}
+}
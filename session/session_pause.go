@@ -0,0 +1,67 @@
+package session
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/bettercap/bettercap/core"
+)
+
+// how often paused modules check if the session has been resumed
+const pausePollPeriod = 100 * time.Millisecond
+
+var (
+	ErrAlreadyPaused = errors.New("the session is already paused")
+	ErrNotPaused     = errors.New("the session is not paused")
+)
+
+// Paused returns true if session.pause has been called, modules sending
+// packets check it in their loops and hold their transmissions until the
+// session is resumed.
+func (s *Session) Paused() bool {
+	return atomic.LoadInt32(&s.paused) == 1
+}
+
+// Pause suspends the transmissions of every module honoring the flag at
+// once, their state is kept so that they continue where they left off.
+func (s *Session) Pause() error {
+	if !atomic.CompareAndSwapInt32(&s.paused, 0, 1) {
+		return ErrAlreadyPaused
+	}
+	s.Events.Add("session.paused", nil)
+	return nil
+}
+
+func (s *Session) Resume() error {
+	if !atomic.CompareAndSwapInt32(&s.paused, 1, 0) {
+		return ErrNotPaused
+	}
+	s.Events.Add("session.resumed", nil)
+	return nil
+}
+
+// WaitIfPaused blocks while the session is paused and the module is running,
+// it returns whether the module is still running.
+func (m *SessionModule) WaitIfPaused() bool {
+	for m.Session.Paused() && m.Running() {
+		time.Sleep(pausePollPeriod)
+	}
+	return m.Running()
+}
+
+func (s *Session) pauseHandler(args []string, sess *Session) error {
+	if err := s.Pause(); err != nil {
+		return err
+	}
+	s.Events.Log(core.INFO, "session paused, the running modules won't send anything until session.resume.")
+	return nil
+}
+
+func (s *Session) resumeHandler(args []string, sess *Session) error {
+	if err := s.Resume(); err != nil {
+		return err
+	}
+	s.Events.Log(core.INFO, "session resumed.")
+	return nil
+}
@@ -0,0 +1,52 @@
+package session
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSessionPause(t *testing.T) {
+	env, _ := NewEnvironment("")
+	s := &Session{
+		Events: NewEventPool(false, false),
+		Env:    env,
+	}
+
+	if err := s.Resume(); err != ErrNotPaused {
+		t.Fatalf("expected %v, got %v", ErrNotPaused, err)
+	} else if err = s.Pause(); err != nil {
+		t.Fatal(err)
+	} else if !s.Paused() {
+		t.Fatal("the session should be paused")
+	} else if err = s.Pause(); err != ErrAlreadyPaused {
+		t.Fatalf("expected %v, got %v", ErrAlreadyPaused, err)
+	}
+
+	m := NewSessionModule("test", s)
+	m.Started = true
+
+	done := make(chan bool)
+	go func() {
+		done <- m.WaitIfPaused()
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("WaitIfPaused returned while the session was paused")
+	case <-time.After(3 * pausePollPeriod):
+	}
+
+	if err := s.Resume(); err != nil {
+		t.Fatal(err)
+	} else if running := <-done; !running {
+		t.Fatal("WaitIfPaused should return true for a running module")
+	}
+
+	tags := make([]string, 0)
+	for _, e := range s.Events.Sorted() {
+		tags = append(tags, e.Tag)
+	}
+	if len(tags) != 2 || tags[0] != "session.paused" || tags[1] != "session.resumed" {
+		t.Fatalf("unexpected events %v", tags)
+	}
+}
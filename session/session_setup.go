@@ -4,12 +4,14 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
 	"github.com/bettercap/bettercap/caplets"
 	"github.com/bettercap/bettercap/core"
+	"github.com/bettercap/bettercap/network"
 
 	"github.com/bettercap/readline"
 )
@@ -92,9 +94,21 @@ func (s *Session) startNetMon() {
 				addr := event.IP.String()
 				mac := event.MAC.String()
 
-				existing := s.Lan.AddIfNew(addr, mac)
+				if event.Rebind {
+					if e, old := s.Lan.Rebind(addr, mac, event.VLAN); e != nil {
+						s.Events.Add("endpoint.ip.changed", network.EndpointIPChanged{
+							Endpoint: e,
+							Old:      old,
+							New:      addr,
+						})
+					}
+				}
+
+				existing := s.Lan.AddIfNewIn(addr, mac, event.VLAN)
 				if existing != nil {
 					existing.LastSeen = time.Now()
+					// imported from the neighbor table, now seen on the wire
+					existing.Unconfirmed = false
 				} else {
 					existing, _ = s.Lan.Get(mac)
 				}
@@ -102,11 +116,73 @@ func (s *Session) startNetMon() {
 				if existing != nil && event.Meta != nil {
 					existing.OnMeta(event.Meta)
 				}
+
+				if existing != nil {
+					for _, group := range event.Left {
+						existing.Groups.Remove(group.String())
+					}
+					for _, group := range event.Joined {
+						if existing.Groups.Add(group.String()) {
+							s.Events.Add("net.recon.igmp", network.MulticastJoin{
+								Endpoint: existing,
+								Group:    group.String(),
+							})
+						}
+					}
+				}
+
+				if existing != nil && event.OS != nil && s.osGuessEnabled() {
+					if existing.OSGuess == nil {
+						existing.OSGuess = event.OS
+						s.Events.Add("net.recon.os", existing)
+					} else if event.OS.Confidence > existing.OSGuess.Confidence {
+						existing.OSGuess = event.OS
+					}
+				}
+
+				if existing != nil && !event.Device.Empty() && s.classifyEnabled() && existing.AddDeviceSignals(event.Device) {
+					if class := network.ClassifyDevice(existing.DeviceSignals()); class != nil {
+						prev := existing.DeviceClass
+						existing.DeviceClass = class
+						if prev == nil || prev.Category != class.Category {
+							s.Events.Add("net.recon.classified", existing)
+						}
+					}
+				}
 			}
 		}
 	}()
 }
 
+func (s *Session) osGuessEnabled() bool {
+	found, v := s.Env.Get("net.recon.os.guess")
+	return found && v == "true"
+}
+
+func (s *Session) setupEventsRate(variable string, priority EventPriority) {
+	rate := defaultEventsRates[priority]
+	if found, v := s.Env.Get(variable); found && v != "" {
+		rate = v
+	}
+	s.Env.WithCallback(variable, rate, func(newValue string) {
+		if rate, err := strconv.Atoi(newValue); err == nil && rate >= 0 {
+			s.Events.SetRate(priority, rate)
+		}
+	})
+}
+
+func (s *Session) classifyEnabled() bool {
+	found, v := s.Env.Get("net.recon.classify")
+	return found && v == "true"
+}
+
+// ReconPassive returns true if net.recon.passive is enabled, in which case
+// the modules must not send anything to discover or resolve hosts.
+func (s *Session) ReconPassive() bool {
+	found, v := s.Env.Get("net.recon.passive")
+	return found && v == "true"
+}
+
 func (s *Session) setupSignals() {
 	c := make(chan os.Signal)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
@@ -129,7 +205,93 @@ func (s *Session) setupEnv() {
 	s.Env.Set("gateway.mac", s.Gateway.HwAddress)
 
 	if found, v := s.Env.Get(PromptVariable); !found || v == "" {
-		s.Env.Set(PromptVariable, DefaultPrompt)
+		// migrate the prompt from env files saved by older versions
+		if found, legacy := s.Env.Get(LegacyPromptVariable); found && legacy != "" {
+			s.Env.Set(PromptVariable, legacy)
+		} else {
+			s.Env.Set(PromptVariable, DefaultPrompt)
+		}
+	}
+	s.Env.Unset(LegacyPromptVariable)
+
+	maxWorkers := "0"
+	if found, v := s.Env.Get(WorkersVariable); found && v != "" {
+		maxWorkers = v
+	}
+	s.Env.WithCallback(WorkersVariable, maxWorkers, func(newValue string) {
+		if max, err := strconv.Atoi(newValue); err == nil {
+			core.Workers.SetMax(max)
+		}
+	})
+
+	maxEvents := DefaultEventsMax
+	if found, v := s.Env.Get(EventsMaxVariable); found && v != "" {
+		maxEvents = v
+	}
+	s.Env.WithCallback(EventsMaxVariable, maxEvents, func(newValue string) {
+		if max, err := strconv.Atoi(newValue); err == nil && max >= 0 {
+			s.Events.SetMax(max)
+		}
+	})
+
+	for variable, priority := range map[string]EventPriority{
+		EventsRateLowVariable:    PriorityLow,
+		EventsRateNormalVariable: PriorityNormal,
+		EventsRateHighVariable:   PriorityHigh,
+	} {
+		s.setupEventsRate(variable, priority)
+	}
+
+	coalesce := DefaultEventsCoalesce
+	if found, v := s.Env.Get(EventsCoalesceVariable); found && v != "" {
+		coalesce = v
+	}
+	s.Env.WithCallback(EventsCoalesceVariable, coalesce, func(newValue string) {
+		if secs, err := strconv.Atoi(newValue); err == nil && secs >= 0 {
+			s.Events.SetCoalesce(time.Duration(secs) * time.Second)
+		}
+	})
+
+	s.setupDryRun()
+
+	if found, v := s.Env.Get(WatchdogVariable); !found || v == "" {
+		s.Env.Set(WatchdogVariable, "false")
+	}
+
+	if found, v := s.Env.Get(WatchdogAttemptsVariable); !found || v == "" {
+		s.Env.Set(WatchdogAttemptsVariable, "5")
+	}
+
+	if found, v := s.Env.Get(AutosaveIntervalVariable); !found || v == "" {
+		s.Env.Set(AutosaveIntervalVariable, "0")
+	}
+
+	if found, v := s.Env.Get(AutosaveKeepVariable); !found || v == "" {
+		s.Env.Set(AutosaveKeepVariable, "5")
+	}
+
+	if found, v := s.Env.Get(AutosavePathVariable); !found || v == "" {
+		s.Env.Set(AutosavePathVariable, DefaultAutosavePath)
+	}
+
+	if found, v := s.Env.Get(AutorestoreVariable); !found || v == "" {
+		s.Env.Set(AutorestoreVariable, "false")
+	}
+
+	if found, v := s.Env.Get(TargetsMaxVariable); !found || v == "" {
+		s.Env.Set(TargetsMaxVariable, DefaultTargetsMax)
+	}
+
+	if found, v := s.Env.Get(TargetsOverrideVariable); !found || v == "" {
+		s.Env.Set(TargetsOverrideVariable, "false")
+	}
+
+	if found, v := s.Env.Get(IfaceStatsIntervalVariable); !found || v == "" {
+		s.Env.Set(IfaceStatsIntervalVariable, "1")
+	}
+
+	if found, v := s.Env.Get(IfaceStatsSamplesVariable); !found || v == "" {
+		s.Env.Set(IfaceStatsSamplesVariable, "60")
 	}
 
 	dbg := "false"
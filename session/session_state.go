@@ -0,0 +1,379 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/bettercap/bettercap/core"
+	"github.com/bettercap/bettercap/network"
+)
+
+const (
+	AutosaveIntervalVariable = "session.autosave.interval"
+	AutosaveKeepVariable     = "session.autosave.keep"
+	AutosavePathVariable     = "session.autosave.path"
+	AutorestoreVariable      = "session.autorestore"
+
+	DefaultAutosavePath = "~/bettercap-sessions"
+
+	autosavePrefix     = "bettercap-session-"
+	autosaveTimeFormat = "20060102-150405"
+	autosavePeriod     = 1 * time.Second
+)
+
+// sessionState is what session.save writes, a copy of the hosts, access
+// points and BLE devices tables taken when the snapshot started.
+type sessionState struct {
+	SavedAt time.Time           `json:"saved_at"`
+	Hosts   []*network.Endpoint `json:"hosts"`
+	APs     []stateAP           `json:"aps"`
+	BLE     json.RawMessage     `json:"ble"`
+}
+
+type stateAP struct {
+	*network.Station
+	Clients []*network.Station `json:"clients"`
+}
+
+// the loaded state only needs what can be put back into the tables, BLE
+// devices are not restored since they need a live peripheral handle.
+type loadedMeta struct {
+	Values map[string]interface{} `json:"values"`
+}
+
+type loadedHost struct {
+	IpAddress string     `json:"ipv4"`
+	HwAddress string     `json:"mac"`
+	Hostname  string     `json:"hostname"`
+	Alias     string     `json:"alias"`
+	Vendor    string     `json:"vendor"`
+	FirstSeen time.Time  `json:"first_seen"`
+	LastSeen  time.Time  `json:"last_seen"`
+	Meta      loadedMeta `json:"meta"`
+}
+
+type loadedStation struct {
+	loadedHost
+	Frequency      int    `json:"frequency"`
+	RSSI           int8   `json:"rssi"`
+	Encryption     string `json:"encryption"`
+	Cipher         string `json:"cipher"`
+	Authentication string `json:"authentication"`
+}
+
+type loadedAP struct {
+	loadedStation
+	Clients []loadedStation `json:"clients"`
+}
+
+type loadedState struct {
+	SavedAt time.Time    `json:"saved_at"`
+	Hosts   []loadedHost `json:"hosts"`
+	APs     []loadedAP   `json:"aps"`
+}
+
+func copyEndpoint(e *network.Endpoint) *network.Endpoint {
+	c := *e
+	c.Meta = network.NewMeta()
+	e.Meta.Each(func(name string, value interface{}) {
+		c.Meta.Set(name, value)
+	})
+	return &c
+}
+
+func copyStation(s *network.Station) *network.Station {
+	c := *s
+	c.Endpoint = copyEndpoint(s.Endpoint)
+	return &c
+}
+
+func (s *Session) copyState() (error, *sessionState) {
+	state := &sessionState{
+		SavedAt: time.Now(),
+		Hosts:   make([]*network.Endpoint, 0),
+		APs:     make([]stateAP, 0),
+		BLE:     json.RawMessage("null"),
+	}
+
+	if s.Lan != nil {
+		for _, e := range s.Lan.List() {
+			state.Hosts = append(state.Hosts, copyEndpoint(e))
+		}
+	}
+
+	if s.WiFi != nil {
+		for _, ap := range s.WiFi.List() {
+			sap := stateAP{
+				Station: copyStation(ap.Station),
+				Clients: make([]*network.Station, 0),
+			}
+			for _, client := range ap.Clients() {
+				sap.Clients = append(sap.Clients, copyStation(client))
+			}
+			state.APs = append(state.APs, sap)
+		}
+	}
+
+	if s.BLE != nil {
+		raw, err := json.Marshal(s.BLE)
+		if err != nil {
+			return err, nil
+		}
+		state.BLE = raw
+	}
+
+	return nil, state
+}
+
+func writeState(fileName string, state *sessionState) error {
+	raw, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file first so that a crash while saving doesn't
+	// leave a truncated snapshot behind
+	tmp := fileName + ".tmp"
+	if err = ioutil.WriteFile(tmp, raw, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, fileName)
+}
+
+// SaveState writes the hosts, access points and BLE devices to fileName.
+func (s *Session) SaveState(fileName string) (error, int) {
+	err, state := s.copyState()
+	if err != nil {
+		return err, 0
+	} else if err = writeState(fileName, state); err != nil {
+		return err, 0
+	}
+	return nil, len(state.Hosts) + len(state.APs)
+}
+
+func restoreEndpoint(e *network.Endpoint, h loadedHost) {
+	if h.Hostname != "" {
+		e.Hostname = h.Hostname
+	}
+	if h.Alias != "" && e.Alias == "" {
+		e.Alias = h.Alias
+	}
+	if h.Vendor != "" {
+		e.Vendor = h.Vendor
+	}
+	if !h.FirstSeen.IsZero() {
+		e.FirstSeen = h.FirstSeen
+	}
+	if h.LastSeen.After(e.LastSeen) {
+		e.LastSeen = h.LastSeen
+	}
+	for name, value := range h.Meta.Values {
+		e.Meta.Set(name, value)
+	}
+}
+
+func restoreStation(st *network.Station, l loadedStation) {
+	restoreEndpoint(st.Endpoint, l.loadedHost)
+	if l.Encryption != "" {
+		st.Encryption = l.Encryption
+		st.Cipher = l.Cipher
+		st.Authentication = l.Authentication
+	}
+}
+
+// LoadState adds to the current tables the hosts and access points saved in
+// fileName, entries which are already known are only updated.
+func (s *Session) LoadState(fileName string) (error, int) {
+	raw, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return err, 0
+	}
+
+	var state loadedState
+	if err = json.Unmarshal(raw, &state); err != nil {
+		return fmt.Errorf("error parsing %s: %s", fileName, err), 0
+	}
+
+	restored := 0
+
+	if s.Lan != nil {
+		for _, h := range state.Hosts {
+			if h.HwAddress == "" || h.IpAddress == "" {
+				continue
+			}
+
+			s.Lan.AddIfNew(h.IpAddress, h.HwAddress)
+			if e, found := s.Lan.Get(network.NormalizeMac(h.HwAddress)); found {
+				restoreEndpoint(e, h)
+				restored++
+			}
+		}
+	}
+
+	if s.WiFi != nil {
+		for _, a := range state.APs {
+			if a.HwAddress == "" {
+				continue
+			}
+
+			s.WiFi.AddIfNew(a.Hostname, a.HwAddress, a.Frequency, a.RSSI)
+			ap, found := s.WiFi.Get(network.NormalizeMac(a.HwAddress))
+			if !found {
+				continue
+			}
+
+			restoreStation(ap.Station, a.loadedStation)
+			for _, c := range a.Clients {
+				if c.HwAddress != "" {
+					restoreStation(ap.AddClient(c.HwAddress, c.Frequency, c.RSSI), c)
+				}
+			}
+			restored++
+		}
+	}
+
+	return nil, restored
+}
+
+func (s *Session) saveHandler(args []string, sess *Session) error {
+	fileName, err := core.ExpandPath(args[0])
+	if err != nil {
+		return err
+	}
+
+	err, n := s.SaveState(fileName)
+	if err != nil {
+		return err
+	}
+
+	s.Events.Log(core.INFO, "saved %d entries to %s.", n, core.Bold(fileName))
+	return nil
+}
+
+func (s *Session) loadHandler(args []string, sess *Session) error {
+	fileName, err := core.ExpandPath(args[0])
+	if err != nil {
+		return err
+	}
+
+	err, n := s.LoadState(fileName)
+	if err != nil {
+		return err
+	}
+
+	s.Events.Log(core.INFO, "restored %d entries from %s.", n, core.Bold(fileName))
+	return nil
+}
+
+func (s *Session) autosaveDir() (error, string) {
+	dir := DefaultAutosavePath
+	if found, v := s.Env.Get(AutosavePathVariable); found && v != "" {
+		dir = v
+	}
+
+	dir, err := core.ExpandPath(dir)
+	return err, dir
+}
+
+func (s *Session) autosaveKeep() int {
+	if found, v := s.Env.Get(AutosaveKeepVariable); found {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
+	}
+	return 5
+}
+
+// snapshots returns the autosaved files in dir, oldest first.
+func snapshots(dir string) []string {
+	files, _ := filepath.Glob(filepath.Join(dir, autosavePrefix+"*.json"))
+	sort.Strings(files)
+	return files
+}
+
+func (s *Session) autosave(state *sessionState) error {
+	err, dir := s.autosaveDir()
+	if err != nil {
+		return err
+	} else if err = os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	fileName := filepath.Join(dir, autosavePrefix+state.SavedAt.Format(autosaveTimeFormat)+".json")
+	if err = writeState(fileName, state); err != nil {
+		return err
+	}
+
+	files := snapshots(dir)
+	for keep := s.autosaveKeep(); len(files) > keep; files = files[1:] {
+		if err := os.Remove(files[0]); err != nil {
+			s.Events.Log(core.WARNING, "could not remove old snapshot %s: %s", files[0], err)
+		}
+	}
+
+	return nil
+}
+
+// autorestore loads the most recent autosaved snapshot if session.autorestore
+// is true.
+func (s *Session) autorestore() {
+	if found, v := s.Env.Get(AutorestoreVariable); !found || v != "true" {
+		return
+	}
+
+	err, dir := s.autosaveDir()
+	if err != nil {
+		s.Events.Log(core.WARNING, "could not restore session: %s", err)
+		return
+	}
+
+	files := snapshots(dir)
+	if len(files) == 0 {
+		s.Events.Log(core.INFO, "no session snapshot to restore in %s.", dir)
+		return
+	}
+
+	last := files[len(files)-1]
+	if err, n := s.LoadState(last); err != nil {
+		s.Events.Log(core.WARNING, "could not restore session from %s: %s", last, err)
+	} else {
+		s.Events.Log(core.INFO, "restored %d entries from %s.", n, last)
+	}
+}
+
+func (s *Session) startAutosave() {
+	go func() {
+		var busy int32
+		last := time.Now()
+
+		for s.Active {
+			time.Sleep(autosavePeriod)
+
+			err, interval := s.Env.GetInt(AutosaveIntervalVariable)
+			if !s.Active || err != nil || interval <= 0 || time.Since(last) < time.Duration(interval)*time.Second {
+				continue
+			} else if !atomic.CompareAndSwapInt32(&busy, 0, 1) {
+				// the previous snapshot is still being written
+				continue
+			}
+
+			last = time.Now()
+			go func() {
+				defer atomic.StoreInt32(&busy, 0)
+
+				if err, state := s.copyState(); err != nil {
+					s.Events.Log(core.WARNING, "could not take session snapshot: %s", err)
+				} else if err = s.autosave(state); err != nil {
+					s.Events.Log(core.WARNING, "could not save session snapshot: %s", err)
+				}
+			}()
+		}
+	}()
+}
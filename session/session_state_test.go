@@ -0,0 +1,100 @@
+package session
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bettercap/bettercap/network"
+)
+
+func buildStateSession() *Session {
+	env, _ := NewEnvironment("")
+	iface := network.NewEndpointNoResolve("192.168.1.10", "aa:bb:cc:dd:ee:01", "", 24)
+	gateway := network.NewEndpointNoResolve("192.168.1.1", "aa:bb:cc:dd:ee:02", "", 24)
+	return &Session{
+		Env:  env,
+		Lan:  network.NewLAN(iface, gateway, func(e *network.Endpoint) {}, func(e *network.Endpoint) {}),
+		WiFi: network.NewWiFi(iface, func(ap *network.AccessPoint) {}, func(ap *network.AccessPoint) {}),
+	}
+}
+
+func TestSaveLoadState(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bettercap-state")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	src := buildStateSession()
+	src.Lan.AddIfNew("192.168.1.20", "11:22:33:44:55:66")
+	host, _ := src.Lan.Get("11:22:33:44:55:66")
+	host.Hostname = "printer"
+	host.Meta.Set("mdns:model", "laserjet")
+
+	src.WiFi.AddIfNew("home", "66:55:44:33:22:11", 2437, -40)
+	ap, _ := src.WiFi.Get("66:55:44:33:22:11")
+	ap.Encryption = "WPA2"
+	ap.AddClient("12:34:56:78:9a:bc", 2437, -60)
+
+	fileName := filepath.Join(dir, "state.json")
+	if err, n := src.SaveState(fileName); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if n != 2 {
+		t.Fatalf("expected 2 saved entries, got %d", n)
+	}
+
+	dst := buildStateSession()
+	if err, n := dst.LoadState(fileName); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if n != 2 {
+		t.Fatalf("expected 2 restored entries, got %d", n)
+	}
+
+	if e, found := dst.Lan.Get("11:22:33:44:55:66"); !found {
+		t.Fatal("expected host to be restored")
+	} else if e.Hostname != "printer" {
+		t.Fatalf("expected hostname 'printer', got '%s'", e.Hostname)
+	} else if v := e.Meta.Get("mdns:model"); v != "laserjet" {
+		t.Fatalf("expected meta to be restored, got '%v'", v)
+	}
+
+	if a, found := dst.WiFi.Get("66:55:44:33:22:11"); !found {
+		t.Fatal("expected access point to be restored")
+	} else if a.ESSID() != "home" || a.Encryption != "WPA2" {
+		t.Fatalf("unexpected access point %s %s", a.ESSID(), a.Encryption)
+	} else if _, found := a.Get("12:34:56:78:9a:bc"); !found {
+		t.Fatal("expected client to be restored")
+	}
+}
+
+func TestAutosaveKeepsLastSnapshots(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bettercap-autosave")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := buildStateSession()
+	s.Env.Set(AutosavePathVariable, dir)
+	s.Env.Set(AutosaveKeepVariable, "2")
+
+	for _, name := range []string{"20180101-000000", "20180101-000001", "20180101-000002"} {
+		ioutil.WriteFile(filepath.Join(dir, autosavePrefix+name+".json"), []byte("{}"), 0644)
+	}
+
+	err, state := s.copyState()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err = s.autosave(state); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	files := snapshots(dir)
+	if len(files) != 2 {
+		t.Fatalf("expected 2 snapshots, got %v", files)
+	} else if last := files[1]; filepath.Base(last) != autosavePrefix+state.SavedAt.Format(autosaveTimeFormat)+".json" {
+		t.Fatalf("expected the new snapshot to be kept, got %v", files)
+	}
+}
@@ -0,0 +1,41 @@
+package session
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/bettercap/bettercap/network"
+)
+
+const (
+	TargetsMaxVariable      = "main.targets.max"
+	TargetsOverrideVariable = "main.targets.override"
+
+	DefaultTargetsMax = "1024"
+)
+
+// CheckTargets returns an error if module is about to act on more hosts
+// than main.targets.max, unless main.targets.override is true.
+func (s *Session) CheckTargets(module string, count int) error {
+	err, max := s.Env.GetInt(TargetsMaxVariable)
+	if err != nil || max <= 0 || count <= max {
+		return nil
+	} else if found, v := s.Env.Get(TargetsOverrideVariable); found && v == "true" {
+		return nil
+	}
+
+	return fmt.Errorf("%s would act on %d hosts, more than %s (%d), set %s to true to go ahead anyway.",
+		module, count, TargetsMaxVariable, max, TargetsOverrideVariable)
+}
+
+// ParseTargets parses the addresses, MACs and aliases in targets for module,
+// failing if they're more than CheckTargets allows.
+func (s *Session) ParseTargets(module string, targets string) ([]net.IP, []net.HardwareAddr, error) {
+	ips, macs, err := network.ParseTargets(targets, s.Lan.Aliases())
+	if err != nil {
+		return nil, nil, err
+	} else if err = s.CheckTargets(module, len(ips)+len(macs)); err != nil {
+		return nil, nil, err
+	}
+	return ips, macs, nil
+}
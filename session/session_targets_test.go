@@ -0,0 +1,28 @@
+package session
+
+import (
+	"testing"
+)
+
+func TestCheckTargets(t *testing.T) {
+	env, _ := NewEnvironment("")
+	s := &Session{Env: env}
+
+	env.Set(TargetsMaxVariable, "10")
+	if err := s.CheckTargets("arp.spoof", 10); err != nil {
+		t.Fatalf("unexpected error for 10 targets: %s", err)
+	} else if err = s.CheckTargets("arp.spoof", 11); err == nil {
+		t.Fatalf("expected an error for 11 targets")
+	}
+
+	env.Set(TargetsOverrideVariable, "true")
+	if err := s.CheckTargets("arp.spoof", 11); err != nil {
+		t.Fatalf("unexpected error with %s: %s", TargetsOverrideVariable, err)
+	}
+
+	env.Set(TargetsOverrideVariable, "false")
+	env.Set(TargetsMaxVariable, "0")
+	if err := s.CheckTargets("arp.spoof", 100000); err != nil {
+		t.Fatalf("a zero %s should disable the check, got %s", TargetsMaxVariable, err)
+	}
+}
@@ -36,3 +36,61 @@ func TestParseCommands(t *testing.T) {
 		}
 	})
 }
+
+func TestExpandAlias(t *testing.T) {
+	env, _ := NewEnvironment("")
+	s := &Session{Env: env}
+
+	if err := s.SetAlias("scan", "net.probe on; syn.scan $1 $2 $3; set x $*"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err := s.SetAlias("not;valid", "net.show"); err == nil {
+		t.Fatal("expected error for invalid alias name")
+	}
+
+	isAlias, cmds := s.expandAlias("scan 192.168.1.1 80")
+	if !isAlias {
+		t.Fatal("expected line to be expanded")
+	}
+
+	expected := []string{"net.probe on", "syn.scan 192.168.1.1 80", "set x 192.168.1.1 80"}
+	if len(cmds) != len(expected) {
+		t.Fatalf("expected %d commands, got %d: %v", len(expected), len(cmds), cmds)
+	}
+	for i, cmd := range expected {
+		if cmds[i] != cmd {
+			t.Fatalf("expected '%s' got '%s'", cmd, cmds[i])
+		}
+	}
+
+	if isAlias, _ := s.expandAlias("net.show"); isAlias {
+		t.Fatal("unexpected alias expansion")
+	}
+
+	if err := s.DelAlias("scan"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if isAlias, _ := s.expandAlias("scan 192.168.1.1"); isAlias {
+		t.Fatal("unexpected alias expansion after removal")
+	}
+}
+
+func TestSetAliasCoreCommand(t *testing.T) {
+	env, _ := NewEnvironment("")
+	s := &Session{Env: env}
+	s.registerCoreHandlers()
+
+	for _, name := range []string{"help", "q", "exit", "sleep", "set", "alias", "caps"} {
+		if err := s.SetAlias(name, "net.show"); err == nil {
+			t.Fatalf("expected error for alias named as the core command '%s'", name)
+		}
+	}
+
+	// an alias defined directly in the environment can't shadow them either
+	env.Set(AliasPrefix+"sleep", "net.show")
+	if isAlias, _ := s.expandAlias("sleep 5"); isAlias {
+		t.Fatal("unexpected alias expansion of a core command")
+	}
+
+	if err := s.SetAlias("scan", "net.probe on"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
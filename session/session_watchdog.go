@@ -0,0 +1,120 @@
+package session
+
+import (
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/bettercap/bettercap/core"
+)
+
+const (
+	WatchdogVariable         = "main.iface.watchdog"
+	WatchdogAttemptsVariable = "main.iface.watchdog.attempts"
+
+	watchdogPeriod  = 1 * time.Second
+	watchdogBackoff = 1 * time.Second
+)
+
+func (s *Session) watchdogEnabled() bool {
+	found, v := s.Env.Get(WatchdogVariable)
+	return found && v == "true"
+}
+
+func (s *Session) watchdogAttempts() int {
+	if found, v := s.Env.Get(WatchdogAttemptsVariable); found {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
+	}
+	return 5
+}
+
+// ifaceAlive returns false if the capture interface disappeared, went
+// down or if the packet queue lost its capture handle.
+func (s *Session) ifaceAlive() bool {
+	if iface, err := net.InterfaceByName(s.Interface.Name()); err != nil {
+		return false
+	} else if iface.Flags&net.FlagUp == 0 {
+		return false
+	}
+	return !s.Queue.Failed()
+}
+
+func (s *Session) stopRunningModules() []Module {
+	stopped := make([]Module, 0)
+	for _, m := range s.Modules {
+		if m.Running() {
+			if err := m.Stop(); err != nil {
+				s.Events.Log(core.WARNING, "error while stopping %s: %s", m.Name(), err)
+			}
+			stopped = append(stopped, m)
+		}
+	}
+	return stopped
+}
+
+func (s *Session) recoverInterface() bool {
+	attempts := s.watchdogAttempts()
+	delay := watchdogBackoff
+
+	for attempt := 1; attempt <= attempts && s.Active; attempt++ {
+		time.Sleep(delay)
+		delay *= 2
+
+		if iface, err := net.InterfaceByName(s.Interface.Name()); err != nil || iface.Flags&net.FlagUp == 0 {
+			s.Events.Log(core.WARNING, "interface %s still not available (attempt %d of %d).", s.Interface.Name(), attempt, attempts)
+			continue
+		}
+
+		// the queue is reopened in place, so that whoever is using it doesn't
+		// need to know and its callbacks, dry run mode and recording are kept
+		if err := s.Queue.Reopen(); err != nil {
+			s.Events.Log(core.WARNING, "could not reopen %s (attempt %d of %d): %s", s.Interface.Name(), attempt, attempts, err)
+			continue
+		}
+		return true
+	}
+
+	return false
+}
+
+// restartInterface stops the running modules and the queue, reopens the
+// capture and restarts the modules that were stopped.
+func (s *Session) restartInterface() bool {
+	stopped := s.stopRunningModules()
+	s.Queue.Stop()
+
+	if !s.recoverInterface() {
+		s.Events.Log(core.ERROR, "giving up on interface %s, modules have been stopped.", s.Interface.Name())
+		return false
+	}
+
+	for _, m := range stopped {
+		if err := m.Start(); err != nil {
+			s.Events.Log(core.WARNING, "could not restart %s: %s", m.Name(), err)
+		}
+	}
+
+	return true
+}
+
+func (s *Session) startWatchdog() {
+	go func() {
+		for s.Active {
+			time.Sleep(watchdogPeriod)
+
+			if !s.Active || !s.watchdogEnabled() || s.ifaceAlive() {
+				continue
+			}
+
+			s.Events.Add("iface.lost", s.Interface)
+
+			if !s.restartInterface() {
+				return
+			}
+
+			s.Events.Add("iface.recovered", s.Interface)
+		}
+	}()
+}
@@ -0,0 +1,70 @@
+package session
+
+import (
+	"testing"
+
+	"github.com/bettercap/bettercap/core"
+	"github.com/bettercap/bettercap/network"
+	"github.com/bettercap/bettercap/packets"
+)
+
+type watchdogModule struct {
+	SessionModule
+	starts int
+}
+
+func (m *watchdogModule) Name() string        { return m.SessionModule.Name }
+func (m *watchdogModule) Description() string { return "" }
+func (m *watchdogModule) Author() string      { return "" }
+
+func (m *watchdogModule) Start() error {
+	m.starts++
+	return m.SetRunning(true, nil)
+}
+
+func (m *watchdogModule) Stop() error {
+	return m.SetRunning(false, nil)
+}
+
+func TestSessionRestartInterface(t *testing.T) {
+	iface := network.NewEndpointNoResolve("127.0.0.1", "00:00:00:00:00:00", "lo", 8)
+	queue, err := packets.NewQueue(iface)
+	if err != nil {
+		t.Skipf("can't capture on lo: %s", err)
+	}
+	defer queue.Stop()
+
+	debug := false
+	env, _ := NewEnvironment("")
+	env.Set(WatchdogAttemptsVariable, "1")
+	s := &Session{
+		Options:   core.Options{Debug: &debug},
+		Interface: iface,
+		Queue:     queue,
+		Events:    NewEventPool(false, false),
+		Env:       env,
+		Active:    true,
+	}
+
+	running := &watchdogModule{SessionModule: NewSessionModule("running", s)}
+	idle := &watchdogModule{SessionModule: NewSessionModule("idle", s)}
+	s.Modules = ModuleList{running, idle}
+
+	if err := running.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !s.restartInterface() {
+		t.Skip("can't reopen lo")
+	}
+
+	if s.Queue != queue {
+		t.Fatal("expected the queue to be reopened in place")
+	} else if !queue.Active() || queue.Failed() {
+		t.Fatal("expected the queue to be active after the restart")
+	} else if !running.Running() || running.starts != 2 {
+		t.Fatalf("expected the running module to be restarted, started %d times", running.starts)
+	} else if idle.Running() || idle.starts != 0 {
+		t.Fatal("expected the idle module not to be started")
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"math/big"
 	"net"
+	"strings"
 	"time"
 
 	"github.com/bettercap/bettercap/log"
@@ -30,6 +31,32 @@ func getServerCertificate(host string, port int) *x509.Certificate {
 	return state.PeerCertificates[0]
 }
 
+// coversHost returns true if the names or addresses of the certificate
+// template include host, wildcards only match one label.
+func coversHost(template *x509.Certificate, host string) bool {
+	if ip := net.ParseIP(host); ip != nil {
+		for _, addr := range template.IPAddresses {
+			if addr.Equal(ip) {
+				return true
+			}
+		}
+		return false
+	}
+
+	host = strings.ToLower(host)
+	for _, name := range template.DNSNames {
+		name = strings.ToLower(name)
+		if name == host {
+			return true
+		} else if strings.HasPrefix(name, "*.") {
+			if dot := strings.IndexByte(host, '.'); dot > 0 && host[dot:] == name[1:] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func SignCertificateForHost(ca *tls.Certificate, host string, port int) (cert *tls.Certificate, err error) {
 	var x509ca *x509.Certificate
 	var template x509.Certificate
@@ -89,6 +116,16 @@ func SignCertificateForHost(ca *tls.Certificate, host string, port int) (cert *t
 		}
 	}
 
+	// the upstream certificate might be a default one for another name,
+	// browsers would reject it without the requested one as SAN
+	if !coversHost(&template, host) {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
 	var certpriv *rsa.PrivateKey
 	if certpriv, err = rsa.GenerateKey(rand.Reader, 1024); err != nil {
 		return